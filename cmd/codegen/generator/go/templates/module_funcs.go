@@ -41,13 +41,27 @@ func (ps *parseState) parseGoFunc(parentType *types.Named, fn *types.Func) (*fun
 	}
 
 	if v, ok := docPragmas["check"]; ok {
-		if v == nil {
+		switch v := v.(type) {
+		case nil:
 			spec.isCheck = true
-		} else {
-			spec.isCheck, ok = v.(bool)
-			if !ok {
-				return nil, fmt.Errorf("check pragma %q, must be a valid boolean", v)
+		case bool:
+			spec.isCheck = v
+		case map[string]any:
+			spec.isCheck = true
+			for name, arg := range v {
+				switch name {
+				case "retries":
+					retries, ok := arg.(float64)
+					if !ok || retries < 0 || retries != float64(int(retries)) {
+						return nil, fmt.Errorf("check pragma retries %v, must be a non-negative integer", arg)
+					}
+					spec.checkRetries = int(retries)
+				default:
+					return nil, fmt.Errorf("check pragma has unknown argument %q", name)
+				}
 			}
+		default:
+			return nil, fmt.Errorf("check pragma %q, must be a valid boolean", v)
 		}
 	}
 
@@ -164,9 +178,11 @@ type funcTypeSpec struct {
 	sourceMap   *sourceMap
 	cachePolicy string
 	isCheck     bool
-	isGenerator bool
-	isUp        bool
-	isAgent     bool
+	// checkRetries is how many times to retry the check, from +check(retries=N)
+	checkRetries int
+	isGenerator  bool
+	isUp         bool
+	isAgent      bool
 
 	argSpecs []paramSpec
 
@@ -223,7 +239,13 @@ func (spec *funcTypeSpec) TypeDefCode() (*Statement, error) {
 			),
 		)
 	}
-	if spec.isCheck {
+	if spec.isCheck && spec.checkRetries > 0 {
+		fnTypeDefCode = dotLine(fnTypeDefCode, "WithCheck").Call(
+			Id("dagger").Dot("FunctionWithCheckOpts").Values(
+				Id("Retries").Op(":").Lit(spec.checkRetries),
+			),
+		)
+	} else if spec.isCheck {
 		fnTypeDefCode = dotLine(fnTypeDefCode, "WithCheck").Call()
 	}
 	if spec.isGenerator {
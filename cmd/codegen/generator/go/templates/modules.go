@@ -1141,9 +1141,21 @@ func (ps *parseState) functionCallArgCode(t types.Type, access *Statement) (type
 
 var pragmaCommentRegexp = regexp.MustCompile(`[ \t]*\+[ \t]*(\S+?)(?:(=[ \t]*)|(?:\r?\n|$))`)
 
+// pragmaArgsCommentRegexp matches the pragmas taking arguments, like
+// +check(retries=2), on a line of their own.
+var pragmaArgsCommentRegexp = regexp.MustCompile(`(?m)^[ \t]*\+[ \t]*(check)\(([^)\r\n]*)\)[ \t]*(?:\r?\n|$)`)
+
 // parsePragmaComment parses a dagger "pragma", that is used to define additional metadata about a parameter.
+//
+// A pragma taking arguments, like +check(retries=2), has the map of its
+// arguments as value.
 func parsePragmaComment(comment string) (data map[string]any, rest string) {
 	data = map[string]any{}
+	for _, v := range pragmaArgsCommentRegexp.FindAllStringSubmatch(comment, -1) {
+		data[v[1]] = parsePragmaArgs(v[2])
+	}
+	comment = pragmaArgsCommentRegexp.ReplaceAllString(comment, "")
+
 	lastEnd := 0
 	for _, v := range pragmaCommentRegexp.FindAllStringSubmatchIndex(comment, -1) {
 		// Skip matches that start before we've finished processing
@@ -1198,6 +1210,28 @@ func parsePragmaComment(comment string) (data map[string]any, rest string) {
 	return data, rest
 }
 
+// parsePragmaArgs parses the comma-separated name=value arguments of a pragma,
+// decoding each value as json if it can be, or keeping it as a string.
+func parsePragmaArgs(args string) map[string]any {
+	data := map[string]any{}
+	for _, arg := range strings.Split(args, ",") {
+		arg = strings.TrimSpace(arg)
+		if arg == "" {
+			continue
+		}
+		name, valueStr, _ := strings.Cut(arg, "=")
+		name, valueStr = strings.TrimSpace(name), strings.TrimSpace(valueStr)
+		var value any
+		if valueStr != "" {
+			if err := json.Unmarshal([]byte(valueStr), &value); err != nil {
+				value = valueStr
+			}
+		}
+		data[name] = value
+	}
+	return data
+}
+
 func asInlineStruct(t types.Type) (*types.Struct, bool) {
 	switch t := t.(type) {
 	case *types.Pointer:
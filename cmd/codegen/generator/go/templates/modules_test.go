@@ -147,6 +147,73 @@ func TestParsePragmaComment(t *testing.T) {
 			},
 			rest: "line 1\r\nline 2\r\nline 3",
 		},
+		{
+			name:    "function pragmas",
+			comment: "Lint the code.\n+check\n+cache=\"session\"\n+generate=false",
+			expected: map[string]any{
+				"check":    nil,
+				"cache":    "session",
+				"generate": false,
+			},
+			rest: "Lint the code.\n",
+		},
+		{
+			name:    "argument pragmas",
+			comment: "The source.\n+optional\n+defaultPath=\"/\"\n+ignore=[\"node_modules\", \".git\"]",
+			expected: map[string]any{
+				"optional":    nil,
+				"defaultPath": "/",
+				"ignore":      []any{"node_modules", ".git"},
+			},
+			rest: "The source.\n",
+		},
+		{
+			name:    "check without args",
+			comment: "+check=false\n",
+			expected: map[string]any{
+				"check": false,
+			},
+			rest: "",
+		},
+		{
+			name:    "parentheses of other keys",
+			comment: "+foo(bar)\n+default=\"(x)\"",
+			expected: map[string]any{
+				"foo(bar)": nil,
+				"default":  "(x)",
+			},
+			rest: "",
+		},
+		{
+			name:    "key with args",
+			comment: "+check(retries=2, reason=\"slow network\", strict=true, quarantine)",
+			expected: map[string]any{
+				"check": map[string]any{
+					"retries":    2.0,
+					"reason":     "slow network",
+					"strict":     true,
+					"quarantine": nil,
+				},
+			},
+			rest: "",
+		},
+		{
+			name:    "interpolated key with args",
+			comment: "line 1\n+check(retries=1)\nline 2\n+cache=\"never\"\n",
+			expected: map[string]any{
+				"check": map[string]any{"retries": 1.0},
+				"cache": "never",
+			},
+			rest: "line 1\nline 2\n",
+		},
+		{
+			name:    "key with empty args",
+			comment: "+check()",
+			expected: map[string]any{
+				"check": map[string]any{},
+			},
+			rest: "",
+		},
 	}
 
 	for _, test := range tests {
@@ -392,7 +392,9 @@ func (c *entrypointFuncCtx) renderFunctionExpr(fn *TypedefFunction) string {
 	if fn.Deprecated != "" {
 		parts = append(parts, fmt.Sprintf(".withDeprecated({ reason: %s })", jsString(fn.Deprecated)))
 	}
-	if fn.IsCheck {
+	if fn.IsCheck && fn.CheckRetries > 0 {
+		parts = append(parts, fmt.Sprintf(".withCheck({ retries: %d })", fn.CheckRetries))
+	} else if fn.IsCheck {
 		parts = append(parts, ".withCheck()")
 	}
 	if fn.IsGenerator {
@@ -35,18 +35,19 @@ type TypedefConstructor struct {
 }
 
 type TypedefFunction struct {
-	Name        string             `json:"name"`
-	Alias       string             `json:"alias,omitempty"`
-	Cache       string             `json:"cache,omitempty"`
-	Description string             `json:"description"`
-	Deprecated  string             `json:"deprecated,omitempty"`
-	IsCheck     bool               `json:"isCheck"`
-	IsGenerator bool               `json:"isGenerator"`
-	IsUp        bool               `json:"isUp"`
-	IsAgent     bool               `json:"isAgent"`
-	Location    *TypedefLocation   `json:"location,omitempty"`
-	ReturnType  *TypedefType       `json:"returnType,omitempty"`
-	Arguments   []*TypedefArgument `json:"arguments"`
+	Name         string             `json:"name"`
+	Alias        string             `json:"alias,omitempty"`
+	Cache        string             `json:"cache,omitempty"`
+	Description  string             `json:"description"`
+	Deprecated   string             `json:"deprecated,omitempty"`
+	IsCheck      bool               `json:"isCheck"`
+	CheckRetries int                `json:"checkRetries,omitempty"`
+	IsGenerator  bool               `json:"isGenerator"`
+	IsUp         bool               `json:"isUp"`
+	IsAgent      bool               `json:"isAgent"`
+	Location     *TypedefLocation   `json:"location,omitempty"`
+	ReturnType   *TypedefType       `json:"returnType,omitempty"`
+	Arguments    []*TypedefArgument `json:"arguments"`
 }
 
 type TypedefArgument struct {
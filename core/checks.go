@@ -2,10 +2,16 @@ package core
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/dagql/call"
+	"github.com/dagger/dagger/util/checkreport"
+	"github.com/dagger/dagger/util/checkshard"
 	"github.com/dagger/dagger/util/parallel"
 	"github.com/vektah/gqlparser/v2/ast"
 )
@@ -21,6 +27,17 @@ type Check struct {
 	// IsGenerate indicates this check was derived from a +generate function.
 	// When true, the check passes if the generator produces an empty changeset.
 	IsGenerate bool
+
+	// Findings are the problems reported by a check function returning
+	// [CheckFinding!]!.
+	Findings []*CheckFinding
+	// Duration is how long the check took to run.
+	Duration time.Duration
+
+	// Attempts is how many times the check ran, counting retries.
+	Attempts int
+	// Flaky indicates the check failed at first but passed on a retry.
+	Flaky bool
 }
 
 type CheckGroup struct {
@@ -89,6 +106,26 @@ func (r *CheckGroup) List() []*Check {
 	return r.Checks
 }
 
+// Shard returns the group with only the checks in shard index of total,
+// counting from 1. Checks are split by count, or balanced by how long they
+// took before when given timings; see checkshard.Split.
+func (r *CheckGroup) Shard(index, total int, timings checkshard.Timings) (*CheckGroup, error) {
+	names := make([]string, 0, len(r.Checks))
+	for _, check := range r.Checks {
+		names = append(names, check.Name())
+	}
+	shard, err := checkshard.Shard(names, index, total, timings)
+	if err != nil {
+		return nil, err
+	}
+	r = r.Clone()
+	r.Checks = slices.DeleteFunc(r.Checks, func(check *Check) bool {
+		_, found := slices.BinarySearch(shard, check.Name())
+		return !found
+	})
+	return r, nil
+}
+
 // Run all the checks in the group
 func (r *CheckGroup) Run(ctx context.Context, failFast bool) (*CheckGroup, error) {
 	r = r.Clone()
@@ -103,30 +140,7 @@ func (r *CheckGroup) Run(ctx context.Context, failFast bool) (*CheckGroup, error
 
 	jobs := parallel.New().WithContextualTracer(true).WithFailFast(failFast)
 	for _, check := range r.Checks {
-		// Reset output fields, in case we're re-running
-		check.Completed = false
-		check.Passed = false
-		jobs = jobs.WithJob(check.Name(), func(ctx context.Context) error {
-			var err error
-			if check.IsGenerate {
-				err = check.Node.RunGeneratorAsCheck(ctx, nil, nil)
-			} else {
-				err = check.Node.RunCheck(ctx, nil, nil)
-			}
-			check.Completed = true
-			if err != nil {
-				check.Passed = false
-				errObj, errErr := NewErrorFromErr(ctx, err)
-				if errErr != nil {
-					return fmt.Errorf("create error from %w (%T): %w", err, err, errErr)
-				}
-				check.Error.Value = errObj
-				check.Error.Valid = true
-			} else {
-				check.Passed = true
-			}
-			return err
-		})
+		jobs = jobs.WithJob(check.Name(), check.run)
 	}
 	if err := jobs.Run(ctx); err != nil {
 		return nil, err
@@ -134,18 +148,66 @@ func (r *CheckGroup) Run(ctx context.Context, failFast bool) (*CheckGroup, error
 	return r, nil
 }
 
-func (r *CheckGroup) Report(ctx context.Context) (dagql.ObjectResult[*File], error) {
-	headers := []string{"check", "type", "description", "success"}
-	rows := [][]string{}
-	for _, check := range r.Checks {
-		rows = append(rows, []string{
-			check.Name(),
-			check.CheckType(),
-			check.Description(),
-			check.ResultEmoji(),
-		})
+type CheckReportFormat string
+
+var CheckReportFormats = dagql.NewEnum[CheckReportFormat]()
+
+var (
+	CheckReportFormatMarkdown = CheckReportFormats.Register("MARKDOWN",
+		"A markdown table of the checks and their results.")
+	CheckReportFormatJUnit = CheckReportFormats.Register("JUNIT",
+		"A JUnit XML report, with a test suite per check.")
+	CheckReportFormatSARIF = CheckReportFormats.Register("SARIF",
+		"A SARIF log of the findings reported by the checks.")
+)
+
+func (CheckReportFormat) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "CheckReportFormat",
+		NonNull:   true,
+	}
+}
+
+func (CheckReportFormat) TypeDescription() string {
+	return "The format of a check report."
+}
+
+func (CheckReportFormat) Decoder() dagql.InputDecoder {
+	return CheckReportFormats
+}
+
+func (f CheckReportFormat) ToLiteral() call.Literal {
+	return CheckReportFormats.Literal(f)
+}
+
+func (r *CheckGroup) Report(ctx context.Context, format CheckReportFormat) (dagql.ObjectResult[*File], error) {
+	var name, contents string
+	switch format {
+	case CheckReportFormatJUnit:
+		out, err := checkreport.JUnit(r.reportChecks())
+		if err != nil {
+			return dagql.ObjectResult[*File]{}, err
+		}
+		name, contents = "checks.xml", string(out)
+	case CheckReportFormatSARIF:
+		out, err := checkreport.SARIF(r.reportChecks())
+		if err != nil {
+			return dagql.ObjectResult[*File]{}, err
+		}
+		name, contents = "checks.sarif", string(out)
+	default:
+		headers := []string{"check", "type", "description", "success"}
+		rows := [][]string{}
+		for _, check := range r.Checks {
+			rows = append(rows, []string{
+				check.Name(),
+				check.CheckType(),
+				check.Description(),
+				check.ResultEmoji(),
+			})
+		}
+		name, contents = "checks.md", markdownTable(headers, rows...)
 	}
-	contents := markdownTable(headers, rows...)
 
 	srv, err := CurrentDagqlServer(ctx)
 	if err != nil {
@@ -157,7 +219,7 @@ func (r *CheckGroup) Report(ctx context.Context) (dagql.ObjectResult[*File], err
 		dagql.Selector{
 			Field: "file",
 			Args: []dagql.NamedInput{
-				{Name: "name", Value: dagql.String("checks.md")},
+				{Name: "name", Value: dagql.String(name)},
 				{Name: "contents", Value: dagql.String(contents)},
 			},
 		},
@@ -168,6 +230,29 @@ func (r *CheckGroup) Report(ctx context.Context) (dagql.ObjectResult[*File], err
 	return file, nil
 }
 
+// reportChecks converts the checks for rendering by the checkreport package.
+func (r *CheckGroup) reportChecks() []checkreport.Check {
+	checks := make([]checkreport.Check, 0, len(r.Checks))
+	for _, check := range r.Checks {
+		rc := checkreport.Check{
+			Name:      check.Name(),
+			Completed: check.Completed,
+			Passed:    check.Passed,
+			Flaky:     check.Flaky,
+			Attempts:  check.Attempts,
+			Duration:  check.Duration,
+		}
+		if check.Error.Valid {
+			rc.Error = check.Error.Value.Self().Message
+		}
+		for _, finding := range check.Findings {
+			rc.Findings = append(rc.Findings, finding.reportFinding())
+		}
+		checks = append(checks, rc)
+	}
+	return checks
+}
+
 func markdownTable(headers []string, rows ...[]string) string {
 	var sb strings.Builder
 	sb.WriteString("| " + strings.Join(headers, " | ") + " |\n")
@@ -214,6 +299,9 @@ func (*Check) Type() *ast.Type {
 
 func (c *Check) ResultEmoji() string {
 	if c.Completed {
+		if c.Flaky {
+			return "🟠"
+		}
 		if c.Passed {
 			return "🟢"
 		}
@@ -236,29 +324,174 @@ func (c *Check) CheckType() string {
 func (c *Check) Clone() *Check {
 	cp := *c
 	cp.Node = c.Node.Clone()
+	cp.Findings = slices.Clone(c.Findings)
 	return &cp
 }
 
 func (c *Check) Run(ctx context.Context) (*Check, error) {
 	c = c.Clone()
+	if err := c.run(ctx); err != nil && !c.Completed {
+		return nil, err
+	}
+	return c, nil
+}
+
+// run runs the check, retrying it as its policy allows, and records the
+// result. It returns the error failing the check, if any.
+func (c *Check) run(ctx context.Context) error {
+	// Reset output fields, in case we're re-running
+	c.Completed = false
+	c.Passed = false
+	c.Flaky = false
+	c.Findings = nil
+	c.Error = dagql.Nullable[dagql.ObjectResult[*Error]]{}
 
+	start := time.Now()
 	var err error
 	if c.IsGenerate {
-		err = c.Node.RunGeneratorAsCheck(ctx, nil, nil)
+		c.Attempts, err = c.Node.RunGeneratorAsCheck(ctx, nil, nil)
 	} else {
-		err = c.Node.RunCheck(ctx, nil, nil)
+		c.Findings, c.Attempts, err = c.Node.RunCheck(ctx, nil, nil)
 	}
-	c.Completed = true
+	c.Duration = time.Since(start)
 	if err != nil {
-		c.Passed = false
 		errObj, errErr := NewErrorFromErr(ctx, err)
 		if errErr != nil {
-			return nil, fmt.Errorf("create error from %w (%T): %w", err, err, errErr)
+			return fmt.Errorf("create error from %w (%T): %w", err, err, errErr)
 		}
 		c.Error.Value = errObj
 		c.Error.Valid = true
-	} else {
-		c.Passed = true
+		c.Completed = true
+		return err
 	}
-	return c, nil
+	c.Completed = true
+	c.Passed = true
+	c.Flaky = c.Attempts > 1
+	return nil
+}
+
+// Retries is how many times the check is retried when it fails.
+func (c *Check) Retries() int {
+	return c.Node.CheckRetries
+}
+
+// CheckFinding is a problem reported by a lint check, returned by check
+// functions as [CheckFinding!]!.
+type CheckFinding struct {
+	Message string            `field:"true" doc:"A description of the problem."`
+	Level   CheckFindingLevel `field:"true" doc:"How serious the problem is."`
+	Rule    string            `field:"true" doc:"The identifier of the rule that found the problem, if any."`
+	Path    string            `field:"true" doc:"The path of the file with the problem, relative to the root of the checked source, if any."`
+	Line    int               `field:"true" doc:"The line of the problem, starting at 1, or 0 if unknown."`
+	Column  int               `field:"true" doc:"The column of the problem, starting at 1, or 0 if unknown."`
+}
+
+var (
+	_ dagql.PersistedObject        = (*CheckFinding)(nil)
+	_ dagql.PersistedObjectDecoder = (*CheckFinding)(nil)
+)
+
+func (*CheckFinding) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "CheckFinding",
+		NonNull:   true,
+	}
+}
+
+func (*CheckFinding) TypeDescription() string {
+	return "A problem reported by a lint check."
+}
+
+func (f *CheckFinding) String() string {
+	var loc string
+	if f.Path != "" {
+		loc = f.Path
+		if f.Line > 0 {
+			loc += fmt.Sprintf(":%d", f.Line)
+			if f.Column > 0 {
+				loc += fmt.Sprintf(":%d", f.Column)
+			}
+		}
+		loc += ": "
+	}
+	if f.Rule != "" {
+		return fmt.Sprintf("%s%s (%s)", loc, f.Message, f.Rule)
+	}
+	return loc + f.Message
+}
+
+func (f *CheckFinding) reportFinding() checkreport.Finding {
+	return checkreport.Finding{
+		Message: f.Message,
+		Level:   strings.ToLower(string(f.Level)),
+		Rule:    f.Rule,
+		Path:    f.Path,
+		Line:    f.Line,
+		Column:  f.Column,
+	}
+}
+
+func (f *CheckFinding) EncodePersistedObject(context.Context, dagql.PersistedObjectCache) (dagql.PersistedObjectEncoding, error) {
+	if f == nil {
+		return dagql.PersistedObjectEncoding{}, fmt.Errorf("encode persisted check finding: nil check finding")
+	}
+	return encodePersistedObjectPayload(f)
+}
+
+func (*CheckFinding) DecodePersistedObject(_ context.Context, _ *dagql.Server, _ uint64, _ *dagql.ResultCall, payload json.RawMessage) (dagql.Typed, error) {
+	var f CheckFinding
+	if err := json.Unmarshal(payload, &f); err != nil {
+		return nil, fmt.Errorf("decode persisted check finding payload: %w", err)
+	}
+	return &f, nil
+}
+
+// checkFindingsError fails a check that reported error-level findings.
+func checkFindingsError(findings []*CheckFinding) error {
+	var errs []string
+	for _, f := range findings {
+		if f.Level == CheckFindingLevelError {
+			errs = append(errs, f.String())
+		}
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("1 error found:\n%s", errs[0])
+	default:
+		return fmt.Errorf("%d errors found:\n%s", len(errs), strings.Join(errs, "\n"))
+	}
+}
+
+type CheckFindingLevel string
+
+var CheckFindingLevels = dagql.NewEnum[CheckFindingLevel]()
+
+var (
+	CheckFindingLevelError = CheckFindingLevels.Register("ERROR",
+		"A problem that fails the check.")
+	CheckFindingLevelWarning = CheckFindingLevels.Register("WARNING",
+		"A problem that doesn't fail the check.")
+	CheckFindingLevelNote = CheckFindingLevels.Register("NOTE",
+		"A suggestion.")
+)
+
+func (CheckFindingLevel) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "CheckFindingLevel",
+		NonNull:   true,
+	}
+}
+
+func (CheckFindingLevel) TypeDescription() string {
+	return "How serious a check finding is."
+}
+
+func (CheckFindingLevel) Decoder() dagql.InputDecoder {
+	return CheckFindingLevels
+}
+
+func (l CheckFindingLevel) ToLiteral() call.Literal {
+	return CheckFindingLevels.Literal(l)
 }
@@ -0,0 +1,289 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dagger/dagger/core/workspace"
+	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/util/patternmatcher"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// CheckInput is a part of the workspace a check reads, as far as can be told
+// from its module without running it: the module's own source and that of its
+// local dependencies, the workspace and module config files, the default paths
+// of the arguments of the functions leading to the check, and the whole
+// workspace when one of them takes a Workspace.
+//
+// Filters a function applies to a directory at runtime are not known here;
+// only its +ignore patterns are, which is what Exclude holds.
+type CheckInput struct {
+	Path    string   `field:"true" doc:"The path the check reads, relative to the workspace root. A directory includes everything under it."`
+	Exclude []string `field:"true" doc:"Patterns of paths under the path that the check doesn't read, from the +ignore of its argument."`
+	Reason  string   `field:"true" doc:"Why the check reads the path."`
+}
+
+var (
+	_ dagql.PersistedObject        = (*CheckInput)(nil)
+	_ dagql.PersistedObjectDecoder = (*CheckInput)(nil)
+)
+
+func (*CheckInput) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "CheckInput",
+		NonNull:   true,
+	}
+}
+
+func (*CheckInput) TypeDescription() string {
+	return "A part of the workspace that a check reads."
+}
+
+// Matches reports whether a changed path, relative to the workspace root,
+// can affect the input. A changed directory, as listed in a changeset with a
+// trailing slash, affects the inputs under it too.
+func (in *CheckInput) Matches(changed string) bool {
+	isDir := strings.HasSuffix(changed, "/")
+	changed = filepath.Join("/", changed)
+	if isDir && pathWithin(in.Path, changed) {
+		return true
+	}
+	if !pathWithin(changed, in.Path) {
+		return false
+	}
+	rel, err := filepath.Rel(in.Path, changed)
+	if err != nil {
+		return false
+	}
+	if rel == "." || len(in.Exclude) == 0 {
+		return true
+	}
+	excluded, err := patternmatcher.MatchesOrParentMatches(rel, in.Exclude)
+	if err != nil {
+		// a pattern we can't evaluate can't rule the path out
+		return true
+	}
+	return !excluded
+}
+
+// pathWithin reports whether path is dir or is under it. Both are absolute.
+func pathWithin(path, dir string) bool {
+	return dir == "/" || path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+func (in *CheckInput) EncodePersistedObject(context.Context, dagql.PersistedObjectCache) (dagql.PersistedObjectEncoding, error) {
+	if in == nil {
+		return dagql.PersistedObjectEncoding{}, fmt.Errorf("encode persisted check input: nil check input")
+	}
+	return encodePersistedObjectPayload(in)
+}
+
+func (*CheckInput) DecodePersistedObject(_ context.Context, _ *dagql.Server, _ uint64, _ *dagql.ResultCall, payload json.RawMessage) (dagql.Typed, error) {
+	var in CheckInput
+	if err := json.Unmarshal(payload, &in); err != nil {
+		return nil, fmt.Errorf("decode persisted check input payload: %w", err)
+	}
+	return &in, nil
+}
+
+// Inputs returns the parts of the workspace the check reads.
+func (c *Check) Inputs() []*CheckInput {
+	var inputs []*CheckInput
+	add := func(in *CheckInput) {
+		for _, existing := range inputs {
+			if existing.Path == in.Path && slices.Equal(existing.Exclude, in.Exclude) {
+				return
+			}
+		}
+		inputs = append(inputs, in)
+	}
+
+	var src *ModuleSource
+	if mod := c.Node.OriginalModule.Self(); mod != nil && mod.ContextSource.Valid {
+		src = mod.ContextSource.Value.Self()
+	}
+	var sourceRootSubpath string
+	if src != nil {
+		sourceRootSubpath = src.SourceRootSubpath
+		if src.Kind == ModuleSourceKindLocal {
+			add(&CheckInput{
+				Path:   workspaceContextDirPath(sourceRootSubpath, "."),
+				Reason: "source of module " + c.Node.OriginalModule.Self().Name(),
+			})
+			for _, dep := range localDependencies(src) {
+				add(&CheckInput{
+					Path:   workspaceContextDirPath(dep.SourceRootSubpath, "."),
+					Reason: "source of dependency " + dep.ModuleName,
+				})
+			}
+		}
+	}
+	// which config files apply isn't known from the module, so any of them
+	// in the workspace can change what the check runs
+	add(&CheckInput{
+		Path:    "/",
+		Exclude: slices.Clone(configFileExclude),
+		Reason:  "workspace and module config",
+	})
+
+	for _, step := range c.Node.functionChain() {
+		for _, argRes := range step.fn.Args {
+			arg := argRes.Self()
+			if arg.IsWorkspace() {
+				add(&CheckInput{
+					Path:   "/",
+					Reason: fmt.Sprintf("argument %q of %s takes the workspace", arg.OriginalName, step.name),
+				})
+				continue
+			}
+			if arg.DefaultPath == "" || arg.TypeDef.Self().Kind != TypeDefKindObject {
+				continue
+			}
+			reason := fmt.Sprintf("default path of argument %q of %s", arg.OriginalName, step.name)
+			switch arg.TypeDef.Self().AsObject.Value.Self().Name {
+			case "Directory":
+				add(&CheckInput{
+					Path:    workspaceContextDirPath(sourceRootSubpath, arg.DefaultPath),
+					Exclude: slices.Clone(arg.Ignore),
+					Reason:  reason,
+				})
+			case "File":
+				add(&CheckInput{
+					Path:   workspaceContextDirPath(sourceRootSubpath, arg.DefaultPath),
+					Reason: reason,
+				})
+			case "GitRepository", "GitRef":
+				// any commit changes the repository
+				add(&CheckInput{
+					Path:   "/",
+					Reason: reason,
+				})
+			}
+		}
+	}
+	return inputs
+}
+
+// configFileExclude excludes everything from the workspace but the files that
+// configure workspaces and modules, and pin their versions.
+var configFileExclude = []string{
+	"**",
+	"!**/" + workspace.ConfigFileName,
+	"!**/" + workspace.LockFileName,
+	"!**/" + workspace.LegacyLockFilePath,
+	"!**/" + workspace.ModuleConfigFileName,
+	"!**/" + workspace.LegacyModuleConfigFileName,
+}
+
+// localDependencies returns the local module sources src depends on, directly
+// or not.
+func localDependencies(src *ModuleSource) []*ModuleSource {
+	var deps []*ModuleSource
+	seen := map[*ModuleSource]bool{src: true}
+	var walk func(*ModuleSource)
+	walk = func(src *ModuleSource) {
+		for _, depRes := range src.Dependencies {
+			dep := depRes.Self()
+			if dep == nil || dep.Kind != ModuleSourceKindLocal || seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			deps = append(deps, dep)
+			walk(dep)
+		}
+	}
+	walk(src)
+	return deps
+}
+
+// AffectedBy returns the changed paths, relative to the workspace root, that
+// can affect the check.
+func (c *Check) AffectedBy(changed []string) []string {
+	inputs := c.Inputs()
+	var affected []string
+	for _, path := range changed {
+		for _, in := range inputs {
+			if in.Matches(path) {
+				affected = append(affected, path)
+				break
+			}
+		}
+	}
+	return affected
+}
+
+// AffectedBy returns the group with only the checks that the changes can
+// affect.
+func (r *CheckGroup) AffectedBy(ctx context.Context, changes *Changeset) (*CheckGroup, error) {
+	changed, err := changes.changedPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r = r.Clone()
+	r.Checks = slices.DeleteFunc(r.Checks, func(check *Check) bool {
+		return len(check.AffectedBy(changed)) == 0
+	})
+	return r, nil
+}
+
+// changedPaths returns the paths the changeset adds, modifies or removes.
+func (ch *Changeset) changedPaths(ctx context.Context) ([]string, error) {
+	paths, err := ch.ComputePaths(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("compute changed paths: %w", err)
+	}
+	changed := make([]string, 0, len(paths.Added)+len(paths.Modified)+len(paths.Removed))
+	changed = append(changed, paths.Added...)
+	changed = append(changed, paths.Modified...)
+	changed = append(changed, paths.Removed...)
+	slices.Sort(changed)
+	return slices.Compact(changed), nil
+}
+
+// ChangedInputs returns the paths the changeset adds, modifies or removes
+// that can affect the check.
+func (c *Check) ChangedInputs(ctx context.Context, changes *Changeset) ([]string, error) {
+	changed, err := changes.changedPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.AffectedBy(changed), nil
+}
+
+// functionStep is a function called on the way to a node, along with how to
+// refer to it.
+type functionStep struct {
+	name string
+	fn   *Function
+}
+
+// functionChain returns the functions called to get to the node's value, from
+// the module constructor down to the node's own function.
+func (node *ModTreeNode) functionChain() []functionStep {
+	var chain []functionStep
+	for n := node; n != nil; n = n.Parent {
+		if n.Parent == nil || n.Parent.Module.Self() == nil {
+			// the root: its value is the module's main object
+			if objType := n.ObjectType(); objType != nil && objType.Constructor.Valid {
+				name := "the constructor"
+				if mod := n.OriginalModule.Self(); mod != nil {
+					name = "the constructor of module " + mod.Name()
+				}
+				chain = append(chain, functionStep{name: name, fn: objType.Constructor.Value.Self()})
+			}
+			break
+		}
+		if parentType := n.Parent.ObjectType(); parentType != nil {
+			if fn, ok := parentType.FunctionByName(n.Name); ok {
+				chain = append(chain, functionStep{name: n.PathString(), fn: fn})
+			}
+		}
+	}
+	slices.Reverse(chain)
+	return chain
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/util/checkreport"
+	"github.com/dagger/dagger/util/checkshard"
+)
+
+func TestCheckFindingsError(t *testing.T) {
+	require.NoError(t, checkFindingsError(nil))
+	require.NoError(t, checkFindingsError([]*CheckFinding{
+		{Message: "consider renaming", Level: CheckFindingLevelNote},
+		{Message: "unused variable", Level: CheckFindingLevelWarning},
+	}))
+
+	err := checkFindingsError([]*CheckFinding{
+		{Message: "unused variable", Level: CheckFindingLevelWarning},
+		{Message: "undefined: x", Level: CheckFindingLevelError, Path: "main.go", Line: 3, Column: 5},
+	})
+	require.EqualError(t, err, "1 error found:\nmain.go:3:5: undefined: x")
+
+	err = checkFindingsError([]*CheckFinding{
+		{Message: "missing license", Level: CheckFindingLevelError, Rule: "license"},
+		{Message: "undefined: x", Level: CheckFindingLevelError, Path: "main.go"},
+	})
+	require.EqualError(t, err, "2 errors found:\nmissing license (license)\nmain.go: undefined: x")
+}
+
+func TestCheckFindingReportFinding(t *testing.T) {
+	finding := &CheckFinding{
+		Message: "unused variable",
+		Level:   CheckFindingLevelWarning,
+		Rule:    "unused",
+		Path:    "main.go",
+		Line:    3,
+	}
+	require.Equal(t, checkreport.Finding{
+		Message: "unused variable",
+		Level:   checkreport.LevelWarning,
+		Rule:    "unused",
+		Path:    "main.go",
+		Line:    3,
+	}, finding.reportFinding())
+}
+
+func TestCheckInputMatches(t *testing.T) {
+	src := &CheckInput{Path: "/go/src", Exclude: []string{"testdata", "*.md", "!README.md"}}
+	require.True(t, src.Matches("go/src/main.go"))
+	require.True(t, src.Matches("go/src/pkg/util.go"))
+	require.True(t, src.Matches("go/src/README.md"))
+	require.False(t, src.Matches("go/src/CHANGES.md"))
+	require.False(t, src.Matches("go/src/testdata/in.txt"))
+	require.False(t, src.Matches("go/srcs/main.go"))
+	require.False(t, src.Matches("docs/index.md"))
+	// a removed or added directory holding the input
+	require.True(t, src.Matches("go/"))
+	require.False(t, src.Matches("go"))
+
+	onlySrc := &CheckInput{Path: "/", Exclude: []string{"*", "!src"}}
+	require.True(t, onlySrc.Matches("src/main.go"))
+	require.False(t, onlySrc.Matches("README.md"))
+
+	file := &CheckInput{Path: "/go.mod"}
+	require.True(t, file.Matches("go.mod"))
+	require.False(t, file.Matches("go.sum"))
+
+	workspace := &CheckInput{Path: "/"}
+	require.True(t, workspace.Matches("anything/at/all"))
+
+	config := &CheckInput{Path: "/", Exclude: configFileExclude}
+	require.True(t, config.Matches("dagger.toml"))
+	require.True(t, config.Matches("dagger.lock"))
+	require.True(t, config.Matches(".dagger/lock"))
+	require.True(t, config.Matches("tools/lint/dagger.json"))
+	require.True(t, config.Matches("tools/lint/dagger-module.toml"))
+	require.False(t, config.Matches("main.go"))
+	require.False(t, config.Matches("tools/lint/main.go"))
+	require.False(t, config.Matches("tools/dagger.toml.bak"))
+}
+
+func TestCheckInputsLocalDependencies(t *testing.T) {
+	shared := &ModuleSource{Kind: ModuleSourceKindLocal, ModuleName: "shared", SourceRootSubpath: "lib/shared"}
+	remote := &ModuleSource{Kind: ModuleSourceKindGit, ModuleName: "remote"}
+	util := &ModuleSource{Kind: ModuleSourceKindLocal, ModuleName: "util", SourceRootSubpath: "lib/util"}
+	util.Dependencies = moduleSourceResults(t, shared)
+	src := &ModuleSource{Kind: ModuleSourceKindLocal, ModuleName: "app", SourceRootSubpath: "app"}
+	src.Dependencies = moduleSourceResults(t, util, remote, shared)
+
+	require.Equal(t, []*ModuleSource{util, shared}, localDependencies(src))
+}
+
+func TestCheckGroupShard(t *testing.T) {
+	root := &ModTreeNode{}
+	group := &CheckGroup{}
+	for _, name := range []string{"lint", "test", "build", "unit"} {
+		group.Checks = append(group.Checks, &Check{Node: &ModTreeNode{Parent: root, Name: name}})
+	}
+	names := func(group *CheckGroup) []string {
+		var names []string
+		for _, check := range group.Checks {
+			names = append(names, check.Name())
+		}
+		return names
+	}
+
+	first, err := group.Shard(1, 2, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"test", "build"}, names(first))
+	second, err := group.Shard(2, 2, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"lint", "unit"}, names(second))
+	// the group itself is left alone
+	require.Len(t, group.Checks, 4)
+
+	balanced, err := group.Shard(1, 2, checkshard.Timings{
+		"lint":  time.Minute,
+		"test":  time.Minute,
+		"build": time.Minute,
+		"unit":  time.Hour,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"unit"}, names(balanced))
+
+	_, err = group.Shard(3, 2, nil)
+	require.ErrorContains(t, err, "between 1 and 2")
+}
+
+func moduleSourceResults(t *testing.T, srcs ...*ModuleSource) dagql.ObjectResultArray[*ModuleSource] {
+	t.Helper()
+	dag := newCoreDagqlServerForTest(t, &Query{})
+	dag.InstallObject(dagql.NewClass(dag, dagql.ClassOpts[*ModuleSource]{Typed: &ModuleSource{}}))
+	var results dagql.ObjectResultArray[*ModuleSource]
+	for _, src := range srcs {
+		res, err := dagql.NewObjectResultForCall(src, dag, &dagql.ResultCall{
+			Kind:        dagql.ResultCallKindSynthetic,
+			SyntheticOp: "checks_test_module_source_" + src.ModuleName,
+			Type:        dagql.NewResultCallType((&ModuleSource{}).Type()),
+		})
+		require.NoError(t, err)
+		results = append(results, res)
+	}
+	return results
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"crypto"
 	"encoding/json"
 	stderrors "errors"
 	"fmt"
@@ -17,6 +18,7 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/containerd/containerd/v2/core/content"
 	"github.com/containerd/containerd/v2/core/images"
@@ -28,10 +30,12 @@ import (
 	bkcache "github.com/dagger/dagger/engine/snapshots"
 	bkclient "github.com/dagger/dagger/internal/buildkit/client"
 	"github.com/dagger/dagger/internal/buildkit/client/llb"
+	"github.com/dagger/dagger/internal/buildkit/executor"
 	"github.com/dagger/dagger/internal/buildkit/frontend/dockerfile/dockerfile2llb"
 	dockerfileparser "github.com/dagger/dagger/internal/buildkit/frontend/dockerfile/parser"
 	"github.com/dagger/dagger/internal/buildkit/frontend/dockerfile/shell"
 	"github.com/dagger/dagger/internal/buildkit/frontend/dockerui"
+	"github.com/dagger/dagger/network"
 	"github.com/dagger/dagger/util/containerutil"
 	"github.com/dagger/dagger/util/hashutil"
 	"github.com/dagger/dagger/util/llbtodagger"
@@ -121,6 +125,20 @@ type Container struct {
 	// DefaultArgs have been explicitly set by the user
 	DefaultArgs bool
 
+	// The timeout applied to execs that don't set their own. Zero means no
+	// timeout.
+	DefaultExecTimeout time.Duration
+
+	// The cgroup resource limits applied to execs and services.
+	ResourceLimits executor.ResourceLimits
+
+	// The security profile applied to execs and services.
+	SecurityProfile executor.SecurityProfile
+
+	// The egress policy applied to execs and services. Egress is
+	// unrestricted if nil.
+	EgressPolicy *network.EgressPolicy
+
 	Lazy Lazy[*Container]
 }
 
@@ -302,6 +320,30 @@ type ContainerWithDefaultTerminalCmdLazy struct {
 	Opts   DefaultTerminalCmdOpts
 }
 
+type ContainerWithDefaultExecTimeoutLazy struct {
+	LazyState
+	Parent  dagql.ObjectResult[*Container]
+	Timeout time.Duration
+}
+
+type ContainerWithResourceLimitsLazy struct {
+	LazyState
+	Parent dagql.ObjectResult[*Container]
+	Limits executor.ResourceLimits
+}
+
+type ContainerWithSecurityProfileLazy struct {
+	LazyState
+	Parent  dagql.ObjectResult[*Container]
+	Profile executor.SecurityProfile
+}
+
+type ContainerWithEgressPolicyLazy struct {
+	LazyState
+	Parent dagql.ObjectResult[*Container]
+	Policy network.EgressPolicy
+}
+
 type ContainerRootFSLazy struct {
 	LazyState
 	Parent dagql.ObjectResult[*Container]
@@ -558,6 +600,10 @@ type persistedContainerPayload struct {
 	SystemEnvNames     []string                            `json:"systemEnvNames,omitempty"`
 	VolatileEnv        []string                            `json:"volatileEnv,omitempty"`
 	DefaultArgs        bool                                `json:"defaultArgs,omitempty"`
+	DefaultExecTimeout time.Duration                       `json:"defaultExecTimeout,omitempty"`
+	ResourceLimits     executor.ResourceLimits             `json:"resourceLimits,omitzero"`
+	SecurityProfile    executor.SecurityProfile            `json:"securityProfile,omitzero"`
+	EgressPolicy       *network.EgressPolicy               `json:"egressPolicy,omitempty"`
 	LazyJSON           json.RawMessage                     `json:"lazyJSON,omitempty"`
 }
 
@@ -716,6 +762,26 @@ type persistedContainerWithDefaultTerminalCmdLazy struct {
 	Opts           DefaultTerminalCmdOpts `json:"opts"`
 }
 
+type persistedContainerWithDefaultExecTimeoutLazy struct {
+	ParentResultID uint64        `json:"parentResultID"`
+	Timeout        time.Duration `json:"timeout,omitempty"`
+}
+
+type persistedContainerWithResourceLimitsLazy struct {
+	ParentResultID uint64                  `json:"parentResultID"`
+	Limits         executor.ResourceLimits `json:"limits"`
+}
+
+type persistedContainerWithSecurityProfileLazy struct {
+	ParentResultID uint64                   `json:"parentResultID"`
+	Profile        executor.SecurityProfile `json:"profile"`
+}
+
+type persistedContainerWithEgressPolicyLazy struct {
+	ParentResultID uint64               `json:"parentResultID"`
+	Policy         network.EgressPolicy `json:"policy"`
+}
+
 type persistedContainerFromLazy struct {
 	ParentResultID    uint64                           `json:"parentResultID"`
 	CanonicalRef      string                           `json:"canonicalRef"`
@@ -1076,6 +1142,10 @@ func materializeContainerStateFromParent(ctx context.Context, dst *Container, pa
 	dst.DefaultTerminalCmd = parent.Self().DefaultTerminalCmd
 	dst.SystemEnvNames = slices.Clone(parent.Self().SystemEnvNames)
 	dst.DefaultArgs = parent.Self().DefaultArgs
+	dst.DefaultExecTimeout = parent.Self().DefaultExecTimeout
+	dst.ResourceLimits = parent.Self().ResourceLimits
+	dst.SecurityProfile = parent.Self().SecurityProfile
+	dst.EgressPolicy = parent.Self().EgressPolicy
 	return nil
 }
 
@@ -1524,6 +1594,10 @@ func (container *Container) EncodePersistedObject(ctx context.Context, cache dag
 		SystemEnvNames:     slices.Clone(container.SystemEnvNames),
 		VolatileEnv:        slices.Clone(container.VolatileEnv),
 		DefaultArgs:        container.DefaultArgs,
+		DefaultExecTimeout: container.DefaultExecTimeout,
+		ResourceLimits:     container.ResourceLimits,
+		SecurityProfile:    container.SecurityProfile,
+		EgressPolicy:       container.EgressPolicy,
 	}
 	if container.Lazy != nil {
 		lazyJSON, err := container.Lazy.EncodePersisted(ctx, cache)
@@ -1777,6 +1851,10 @@ func (*Container) DecodePersistedObject(ctx context.Context, dag *dagql.Server,
 		SystemEnvNames:     slices.Clone(persisted.SystemEnvNames),
 		VolatileEnv:        slices.Clone(persisted.VolatileEnv),
 		DefaultArgs:        persisted.DefaultArgs,
+		DefaultExecTimeout: persisted.DefaultExecTimeout,
+		ResourceLimits:     persisted.ResourceLimits,
+		SecurityProfile:    persisted.SecurityProfile,
+		EgressPolicy:       persisted.EgressPolicy,
 	}
 	if persisted.Form != persistedContainerFormLazy {
 		return container, nil
@@ -3125,6 +3203,131 @@ func (lazy *ContainerWithDefaultTerminalCmdLazy) EncodePersisted(ctx context.Con
 	})
 }
 
+func (lazy *ContainerWithDefaultExecTimeoutLazy) Evaluate(ctx context.Context, container *Container) error {
+	return lazy.LazyState.Evaluate(ctx, "Container.withDefaultExecTimeout", func(ctx context.Context) error {
+		if err := materializeContainerStateFromParent(ctx, container, lazy.Parent); err != nil {
+			return err
+		}
+		container.DefaultExecTimeout = lazy.Timeout
+		container.Lazy = nil
+		return nil
+	})
+}
+
+func (lazy *ContainerWithDefaultExecTimeoutLazy) AttachDependencies(ctx context.Context, attach func(dagql.AnyResult) (dagql.AnyResult, error)) ([]dagql.AnyResult, error) {
+	parent, err := attachContainerResult(attach, lazy.Parent, "attach container withDefaultExecTimeout parent")
+	if err != nil {
+		return nil, err
+	}
+	lazy.Parent = parent
+	return []dagql.AnyResult{parent}, nil
+}
+
+func (lazy *ContainerWithDefaultExecTimeoutLazy) EncodePersisted(ctx context.Context, cache dagql.PersistedObjectCache) (json.RawMessage, error) {
+	parentID, err := encodePersistedObjectRef(cache, lazy.Parent, "container withDefaultExecTimeout parent")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(persistedContainerWithDefaultExecTimeoutLazy{
+		ParentResultID: parentID,
+		Timeout:        lazy.Timeout,
+	})
+}
+
+func (lazy *ContainerWithResourceLimitsLazy) Evaluate(ctx context.Context, container *Container) error {
+	return lazy.LazyState.Evaluate(ctx, "Container.withResourceLimits", func(ctx context.Context) error {
+		if err := materializeContainerStateFromParent(ctx, container, lazy.Parent); err != nil {
+			return err
+		}
+		container.ResourceLimits = lazy.Limits
+		container.Lazy = nil
+		return nil
+	})
+}
+
+func (lazy *ContainerWithResourceLimitsLazy) AttachDependencies(ctx context.Context, attach func(dagql.AnyResult) (dagql.AnyResult, error)) ([]dagql.AnyResult, error) {
+	parent, err := attachContainerResult(attach, lazy.Parent, "attach container withResourceLimits parent")
+	if err != nil {
+		return nil, err
+	}
+	lazy.Parent = parent
+	return []dagql.AnyResult{parent}, nil
+}
+
+func (lazy *ContainerWithResourceLimitsLazy) EncodePersisted(ctx context.Context, cache dagql.PersistedObjectCache) (json.RawMessage, error) {
+	parentID, err := encodePersistedObjectRef(cache, lazy.Parent, "container withResourceLimits parent")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(persistedContainerWithResourceLimitsLazy{
+		ParentResultID: parentID,
+		Limits:         lazy.Limits,
+	})
+}
+
+func (lazy *ContainerWithSecurityProfileLazy) Evaluate(ctx context.Context, container *Container) error {
+	return lazy.LazyState.Evaluate(ctx, "Container.withSecurityProfile", func(ctx context.Context) error {
+		if err := materializeContainerStateFromParent(ctx, container, lazy.Parent); err != nil {
+			return err
+		}
+		container.SecurityProfile = lazy.Profile
+		container.Lazy = nil
+		return nil
+	})
+}
+
+func (lazy *ContainerWithSecurityProfileLazy) AttachDependencies(ctx context.Context, attach func(dagql.AnyResult) (dagql.AnyResult, error)) ([]dagql.AnyResult, error) {
+	parent, err := attachContainerResult(attach, lazy.Parent, "attach container withSecurityProfile parent")
+	if err != nil {
+		return nil, err
+	}
+	lazy.Parent = parent
+	return []dagql.AnyResult{parent}, nil
+}
+
+func (lazy *ContainerWithSecurityProfileLazy) EncodePersisted(ctx context.Context, cache dagql.PersistedObjectCache) (json.RawMessage, error) {
+	parentID, err := encodePersistedObjectRef(cache, lazy.Parent, "container withSecurityProfile parent")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(persistedContainerWithSecurityProfileLazy{
+		ParentResultID: parentID,
+		Profile:        lazy.Profile,
+	})
+}
+
+func (lazy *ContainerWithEgressPolicyLazy) Evaluate(ctx context.Context, container *Container) error {
+	return lazy.LazyState.Evaluate(ctx, "Container.withEgressPolicy", func(ctx context.Context) error {
+		if err := materializeContainerStateFromParent(ctx, container, lazy.Parent); err != nil {
+			return err
+		}
+		policy := lazy.Policy
+		container.EgressPolicy = &policy
+		container.Lazy = nil
+		return nil
+	})
+}
+
+func (lazy *ContainerWithEgressPolicyLazy) AttachDependencies(ctx context.Context, attach func(dagql.AnyResult) (dagql.AnyResult, error)) ([]dagql.AnyResult, error) {
+	parent, err := attachContainerResult(attach, lazy.Parent, "attach container withEgressPolicy parent")
+	if err != nil {
+		return nil, err
+	}
+	lazy.Parent = parent
+	return []dagql.AnyResult{parent}, nil
+}
+
+func (lazy *ContainerWithEgressPolicyLazy) EncodePersisted(ctx context.Context, cache dagql.PersistedObjectCache) (json.RawMessage, error) {
+	parentID, err := encodePersistedObjectRef(cache, lazy.Parent, "container withEgressPolicy parent")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(persistedContainerWithEgressPolicyLazy{
+		ParentResultID: parentID,
+		Policy:         lazy.Policy,
+	})
+}
+
 func (lazy *ContainerRootFSLazy) Evaluate(ctx context.Context, dir *Directory) error {
 	return lazy.LazyState.Evaluate(ctx, "Container.rootfs", func(ctx context.Context) error {
 		cache, err := dagql.EngineCache(ctx)
@@ -4674,6 +4877,66 @@ func decodePersistedContainerLazy(
 			Opts:      persisted.Opts,
 		}
 		return nil
+	case "withDefaultExecTimeout":
+		var persisted persistedContainerWithDefaultExecTimeoutLazy
+		if err := json.Unmarshal(payload, &persisted); err != nil {
+			return fmt.Errorf("decode persisted container withDefaultExecTimeout lazy payload: %w", err)
+		}
+		parent, err := loadPersistedObjectResultByResultID[*Container](ctx, dag, persisted.ParentResultID, "container withDefaultExecTimeout parent")
+		if err != nil {
+			return err
+		}
+		container.Lazy = &ContainerWithDefaultExecTimeoutLazy{
+			LazyState: NewLazyState(),
+			Parent:    parent,
+			Timeout:   persisted.Timeout,
+		}
+		return nil
+	case "withResourceLimits":
+		var persisted persistedContainerWithResourceLimitsLazy
+		if err := json.Unmarshal(payload, &persisted); err != nil {
+			return fmt.Errorf("decode persisted container withResourceLimits lazy payload: %w", err)
+		}
+		parent, err := loadPersistedObjectResultByResultID[*Container](ctx, dag, persisted.ParentResultID, "container withResourceLimits parent")
+		if err != nil {
+			return err
+		}
+		container.Lazy = &ContainerWithResourceLimitsLazy{
+			LazyState: NewLazyState(),
+			Parent:    parent,
+			Limits:    persisted.Limits,
+		}
+		return nil
+	case "withSecurityProfile":
+		var persisted persistedContainerWithSecurityProfileLazy
+		if err := json.Unmarshal(payload, &persisted); err != nil {
+			return fmt.Errorf("decode persisted container withSecurityProfile lazy payload: %w", err)
+		}
+		parent, err := loadPersistedObjectResultByResultID[*Container](ctx, dag, persisted.ParentResultID, "container withSecurityProfile parent")
+		if err != nil {
+			return err
+		}
+		container.Lazy = &ContainerWithSecurityProfileLazy{
+			LazyState: NewLazyState(),
+			Parent:    parent,
+			Profile:   persisted.Profile,
+		}
+		return nil
+	case "withEgressPolicy":
+		var persisted persistedContainerWithEgressPolicyLazy
+		if err := json.Unmarshal(payload, &persisted); err != nil {
+			return fmt.Errorf("decode persisted container withEgressPolicy lazy payload: %w", err)
+		}
+		parent, err := loadPersistedObjectResultByResultID[*Container](ctx, dag, persisted.ParentResultID, "container withEgressPolicy parent")
+		if err != nil {
+			return err
+		}
+		container.Lazy = &ContainerWithEgressPolicyLazy{
+			LazyState: NewLazyState(),
+			Parent:    parent,
+			Policy:    persisted.Policy,
+		}
+		return nil
 	case "from":
 		var persisted persistedContainerFromLazy
 		if err := json.Unmarshal(payload, &persisted); err != nil {
@@ -6551,9 +6814,17 @@ func (container *Container) Publish(
 	mediaTypes ImageMediaTypes,
 	registryServices ServiceBindings,
 	registryTransport serverresolver.RegistryTransport,
+	attest ImageAttestationOpts,
+	signer crypto.Signer,
 ) (string, error) {
+	refName, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return "", err
+	}
+
 	variants := filterEmptyContainers(append([]*Container{container}, platformVariants...))
-	inputByPlatform, err := getVariantRefs(ctx, variants)
+	attest.Name = ref
+	inputByPlatform, err := getVariantRefs(ctx, variants, attest)
 	if err != nil {
 		return "", err
 	}
@@ -6577,9 +6848,10 @@ func (container *Container) Publish(
 		return "", err
 	}
 
-	refName, err := reference.ParseNormalizedNamed(ref)
-	if err != nil {
-		return "", err
+	if signer != nil {
+		if err := pushImageSignature(ctx, refName, resp.RootDesc, signer, network, registryTransport); err != nil {
+			return "", fmt.Errorf("push signature of %s: %w", ref, err)
+		}
 	}
 
 	withDig, err := reference.WithDigest(refName, resp.RootDesc.Digest)
@@ -6610,7 +6882,7 @@ func (container *Container) prepareContainerImage(
 	mediaTypes ImageMediaTypes,
 ) (*engineutil.PreparedContainerImage, error) {
 	variants := filterEmptyContainers([]*Container{container})
-	inputByPlatform, err := getVariantRefs(ctx, variants)
+	inputByPlatform, err := getVariantRefs(ctx, variants, ImageAttestationOpts{})
 	if err != nil {
 		return nil, err
 	}
@@ -6707,6 +6979,7 @@ type ExportOpts struct {
 	MediaTypes        ImageMediaTypes
 	Tar               bool
 	LeaseID           string
+	Attestations      ImageAttestationOpts
 }
 
 func useOCIMediaTypes(mediaTypes ImageMediaTypes) bool {
@@ -6735,7 +7008,7 @@ func filterEmptyContainers(containers []*Container) []*Container {
 	return l
 }
 
-func getVariantRefs(ctx context.Context, variants []*Container) (map[string]engineutil.ContainerExport, error) {
+func getVariantRefs(ctx context.Context, variants []*Container, attest ImageAttestationOpts) (map[string]engineutil.ContainerExport, error) {
 	inputByPlatform := map[string]engineutil.ContainerExport{}
 	var eg errgroup.Group
 	var mu sync.Mutex
@@ -6762,14 +7035,19 @@ func getVariantRefs(ctx context.Context, variants []*Container) (map[string]engi
 			if !ok || fsRef == nil {
 				return fmt.Errorf("get variant rootfs snapshot for platform %s: unset snapshot", platformKey)
 			}
+			attestations, err := imageAttestations(ctx, variant, fsRef, attest)
+			if err != nil {
+				return err
+			}
 
 			mu.Lock()
 			defer mu.Unlock()
 
 			inputByPlatform[platformKey] = engineutil.ContainerExport{
-				Ref:         fsRef,
-				Config:      variant.Config,
-				Annotations: variant.Annotations,
+				Ref:          fsRef,
+				Config:       variant.Config,
+				Annotations:  variant.Annotations,
+				Attestations: attestations,
 			}
 			return nil
 		})
@@ -6793,6 +7071,7 @@ func (container *Container) Export(ctx context.Context, opts ExportOpts) (*specs
 			opts.ForcedCompression,
 			opts.MediaTypes,
 			"container.tar",
+			opts.Attestations,
 		)
 		if err != nil {
 			return nil, err
@@ -6824,7 +7103,7 @@ func (container *Container) Export(ctx context.Context, opts ExportOpts) (*specs
 	}
 
 	variants := filterEmptyContainers(append([]*Container{container}, opts.PlatformVariants...))
-	inputByPlatform, err := getVariantRefs(ctx, variants)
+	inputByPlatform, err := getVariantRefs(ctx, variants, opts.Attestations)
 	if err != nil {
 		return nil, err
 	}
@@ -7014,6 +7293,10 @@ type ContainerAsServiceArgs struct {
 	// Skip the init process injected into containers by default so that the
 	// user's process is PID 1
 	NoInit bool `default:"false"`
+
+	// Command to exec in the service when a secret of its secret env
+	// variables expires, with the re-resolved secrets in its environment
+	SecretRefreshCommand []string `default:"[]"`
 }
 
 func (container *Container) AsService(ctx context.Context, containerRes dagql.ObjectResult[*Container], args ContainerAsServiceArgs) (*Service, error) {
@@ -7049,6 +7332,7 @@ func (container *Container) AsService(ctx context.Context, containerRes dagql.Ob
 		ExperimentalPrivilegedNesting: args.ExperimentalPrivilegedNesting,
 		InsecureRootCapabilities:      args.InsecureRootCapabilities,
 		NoInit:                        args.NoInit,
+		SecretRefreshCommand:          args.SecretRefreshCommand,
 	}, nil
 }
 
@@ -7316,6 +7600,9 @@ var (
 	ReturnAny = ReturnTypesEnum.Register("ANY",
 		`Any execution (exit codes 0-127 and 192-255)`,
 	)
+	ReturnTimeout = ReturnTypesEnum.RegisterView("TIMEOUT", AfterVersion("v1.0.0-0"),
+		`An execution killed for exceeding its timeout`,
+	)
 )
 
 func (expect ReturnTypes) Type() *ast.Type {
@@ -7364,6 +7651,9 @@ func (expect ReturnTypes) ReturnCodes() []int {
 			codes = append(codes, i)
 		}
 		return codes
+	case ReturnTimeout:
+		// only the timeout itself is a valid outcome
+		return []int{}
 	default:
 		return nil
 	}
@@ -0,0 +1,284 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/dagql/call"
+	"github.com/dagger/dagger/engine"
+	"github.com/dagger/dagger/engine/engineutil/imageexport"
+	bkcache "github.com/dagger/dagger/engine/snapshots"
+	"github.com/dagger/dagger/internal/buildkit/util/purl"
+	"github.com/dagger/dagger/util/sbom"
+	"github.com/distribution/reference"
+	slsa1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+	"github.com/opencontainers/go-digest"
+	packageurl "github.com/package-url/packageurl-go"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// ImageSBOMFormat is a GraphQL enum type.
+type ImageSBOMFormat string
+
+var ImageSBOMFormats = dagql.NewEnum[ImageSBOMFormat]()
+
+var (
+	SBOMFormatSPDX      = ImageSBOMFormats.Register("SPDX", "SPDX 2.3 JSON document.")
+	SBOMFormatCycloneDX = ImageSBOMFormats.Register("CYCLONEDX", "CycloneDX 1.5 JSON document.")
+)
+
+func (format ImageSBOMFormat) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "ImageSBOMFormat",
+		NonNull:   true,
+	}
+}
+
+func (format ImageSBOMFormat) TypeDescription() string {
+	return "Document format of a software bill of materials attached to an image."
+}
+
+func (format ImageSBOMFormat) Decoder() dagql.InputDecoder {
+	return ImageSBOMFormats
+}
+
+func (format ImageSBOMFormat) ToLiteral() call.Literal {
+	return ImageSBOMFormats.Literal(format)
+}
+
+func (format ImageSBOMFormat) sbomFormat() sbom.Format {
+	switch format {
+	case SBOMFormatCycloneDX:
+		return sbom.FormatCycloneDX
+	default:
+		return sbom.FormatSPDX
+	}
+}
+
+// ProvenanceBuildType identifies the SLSA build type of provenance
+// attestations attached to images; its parameters are the call that built
+// the container.
+const ProvenanceBuildType = "https://dagger.io/provenance/container@v1"
+
+// ImageAttestationOpts selects the in-toto attestations attached to each
+// platform variant of an exported image.
+type ImageAttestationOpts struct {
+	// SBOM is the format of the SBOM generated from the rootfs, or empty for
+	// none.
+	SBOM ImageSBOMFormat
+	// Provenance attaches a SLSA provenance statement describing the call
+	// that built the container.
+	Provenance bool
+
+	// Name of the exported image, if any.
+	Name string
+	// RecipeIDs of the exported containers, used to describe them in
+	// provenance.
+	RecipeIDs map[*Container]*call.ID
+}
+
+func (opts ImageAttestationOpts) enabled() bool {
+	return opts.SBOM != "" || opts.Provenance
+}
+
+// imageAttestations generates the attestations for a platform variant whose
+// rootfs is the given snapshot.
+func imageAttestations(
+	ctx context.Context,
+	variant *Container,
+	rootfs bkcache.ImmutableRef,
+	opts ImageAttestationOpts,
+) ([]imageexport.Attestation, error) {
+	if !opts.enabled() {
+		return nil, nil
+	}
+
+	recipeID := opts.RecipeIDs[variant]
+	if recipeID == nil {
+		return nil, fmt.Errorf("no call ID for %s image variant", variant.Platform.Format())
+	}
+	name := opts.Name
+	if name == "" {
+		name = "container"
+	}
+
+	var atts []imageexport.Attestation
+	if opts.SBOM != "" {
+		format := opts.SBOM.sbomFormat()
+		var inv *sbom.Inventory
+		err := MountRef(ctx, rootfs, func(root string, _ *mount.Mount) error {
+			if dir := variant.rootfsDir(); dir != "/" {
+				root = filepath.Join(root, dir)
+			}
+			var err error
+			inv, err = sbom.Scan(root)
+			return err
+		}, mountRefAsReadOnly)
+		if err != nil {
+			return nil, fmt.Errorf("scan %s rootfs for SBOM: %w", variant.Platform.Format(), err)
+		}
+		doc, err := inv.Encode(format, sbom.DocumentOpts{
+			Name:      name,
+			Namespace: name + "-" + recipeID.Digest().Encoded(),
+			Creator:   "dagger-" + engine.Version,
+			Created:   time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		atts = append(atts, imageexport.Attestation{
+			PredicateType: format.PredicateType(),
+			Predicate:     doc,
+		})
+	}
+	if opts.Provenance {
+		predicate, err := json.Marshal(provenancePredicate(ctx, variant, recipeID))
+		if err != nil {
+			return nil, fmt.Errorf("encode provenance: %w", err)
+		}
+		atts = append(atts, imageexport.Attestation{
+			PredicateType: slsa1.PredicateSLSAProvenance,
+			Predicate:     predicate,
+		})
+	}
+	return atts, nil
+}
+
+func (container *Container) rootfsDir() string {
+	if container.FS == nil {
+		return "/"
+	}
+	rootFS, ok := container.FS.Peek()
+	if !ok || rootFS == nil || rootFS.Dir == nil {
+		return "/"
+	}
+	dir, ok := rootFS.Dir.Peek()
+	if !ok || dir == "" {
+		return "/"
+	}
+	return dir
+}
+
+// provenancePredicate describes how a container was built as a SLSA v1
+// provenance predicate. The container's call ID is the full recipe, so it's
+// recorded as the external parameters, and the images, git repositories and
+// HTTP resources it pulled in become resolved dependencies.
+func provenancePredicate(ctx context.Context, container *Container, recipeID *call.ID) slsa1.ProvenancePredicate {
+	finished := time.Now().UTC()
+	predicate := slsa1.ProvenancePredicate{
+		BuildDefinition: slsa1.ProvenanceBuildDefinition{
+			BuildType: ProvenanceBuildType,
+			ExternalParameters: map[string]any{
+				"call":       recipeID.Path(),
+				"callDigest": recipeID.Digest().String(),
+			},
+			InternalParameters: map[string]any{
+				"platform": container.Platform.Format(),
+			},
+			ResolvedDependencies: provenanceDependencies(recipeID),
+		},
+		RunDetails: slsa1.ProvenanceRunDetails{
+			Builder: slsa1.Builder{
+				ID:      "https://dagger.io/engine",
+				Version: map[string]string{"dagger": engine.Version},
+			},
+			BuildMetadata: slsa1.BuildMetadata{
+				FinishedOn: &finished,
+			},
+		},
+	}
+	if clientMetadata, err := engine.ClientMetadataFromContext(ctx); err == nil {
+		predicate.RunDetails.BuildMetadata.InvocationID = clientMetadata.SessionID
+	}
+	return predicate
+}
+
+// provenanceDependencies walks a call ID for the external sources it pulls
+// in.
+func provenanceDependencies(id *call.ID) []slsa1.ResourceDescriptor {
+	var deps []slsa1.ResourceDescriptor
+	seen := map[digest.Digest]bool{}
+	var walkID func(*call.ID)
+	var walkLiteral func(call.Literal)
+	walkID = func(id *call.ID) {
+		if id == nil || id.IsHandle() || seen[id.Digest()] {
+			return
+		}
+		seen[id.Digest()] = true
+
+		if dep, ok := provenanceDependency(id); ok {
+			deps = append(deps, dep)
+		}
+		walkID(id.Receiver())
+		for _, arg := range id.Args() {
+			walkLiteral(arg.Value())
+		}
+	}
+	walkLiteral = func(lit call.Literal) {
+		switch lit := lit.(type) {
+		case *call.LiteralID:
+			walkID(lit.Value())
+		case *call.LiteralList:
+			for _, v := range lit.Values() {
+				walkLiteral(v)
+			}
+		case *call.LiteralObject:
+			for _, arg := range lit.Args() {
+				walkLiteral(arg.Value())
+			}
+		}
+	}
+	walkID(id)
+	return deps
+}
+
+func provenanceDependency(id *call.ID) (slsa1.ResourceDescriptor, bool) {
+	stringArg := func(name string) string {
+		arg := id.Arg(name)
+		if arg == nil {
+			return ""
+		}
+		lit, ok := arg.Value().(*call.LiteralString)
+		if !ok {
+			return ""
+		}
+		return lit.Value()
+	}
+
+	switch {
+	case id.Field() == "from" && id.Type().NamedType() == "Container":
+		address := stringArg("address")
+		if address == "" {
+			return slsa1.ResourceDescriptor{}, false
+		}
+		uri, err := purl.RefToPURL(packageurl.TypeDocker, address, nil)
+		if err != nil {
+			return slsa1.ResourceDescriptor{}, false
+		}
+		dep := slsa1.ResourceDescriptor{URI: uri}
+		if named, err := reference.ParseNormalizedNamed(address); err == nil {
+			if canonical, ok := named.(reference.Canonical); ok {
+				dep.Digest = map[string]string{canonical.Digest().Algorithm().String(): canonical.Digest().Encoded()}
+			}
+		}
+		return dep, true
+	case id.Field() == "git" && id.Receiver() == nil:
+		url := stringArg("url")
+		if url == "" {
+			return slsa1.ResourceDescriptor{}, false
+		}
+		return slsa1.ResourceDescriptor{URI: "git+" + url}, true
+	case id.Field() == "http" && id.Receiver() == nil:
+		url := stringArg("url")
+		if url == "" {
+			return slsa1.ResourceDescriptor{}, false
+		}
+		return slsa1.ResourceDescriptor{URI: url}, true
+	}
+	return slsa1.ResourceDescriptor{}, false
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/dagger/dagger/dagql/call"
+	slsa1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestProvenanceDependencies(t *testing.T) {
+	t.Parallel()
+
+	ctrType := &ast.Type{NamedType: "Container", NonNull: true}
+	dirType := &ast.Type{NamedType: "Directory", NonNull: true}
+	stringArg := func(name, value string) call.IDOpt {
+		return call.WithArgs(call.NewArgument(name, call.NewLiteralString(value), false))
+	}
+
+	base := call.New().Append(ctrType, "container").
+		Append(ctrType, "from", stringArg("address", "alpine:3.20@sha256:0a4eaa0eecf5f8c050e5bba433f58c052be7587ee8af3e8b3910ef9ab5fbe9f5"))
+	src := call.New().Append(&ast.Type{NamedType: "GitRepository", NonNull: true}, "git", stringArg("url", "https://github.com/dagger/dagger")).
+		Append(&ast.Type{NamedType: "GitRef", NonNull: true}, "head").
+		Append(dirType, "tree")
+	file := call.New().Append(&ast.Type{NamedType: "File", NonNull: true}, "http", stringArg("url", "https://example.com/tool.tar.gz"))
+	id := base.
+		Append(ctrType, "withDirectory", call.WithArgs(
+			call.NewArgument("path", call.NewLiteralString("/src"), false),
+			call.NewArgument("source", call.NewLiteralID(src), false),
+		)).
+		Append(ctrType, "withFiles", call.WithArgs(
+			call.NewArgument("path", call.NewLiteralString("/opt"), false),
+			call.NewArgument("sources", call.NewLiteralList(call.NewLiteralID(file), call.NewLiteralID(file)), false),
+		)).
+		Append(ctrType, "withExec", call.WithArgs(
+			call.NewArgument("args", call.NewLiteralList(call.NewLiteralString("from")), false),
+		))
+
+	require.Equal(t, []slsa1.ResourceDescriptor{
+		{
+			URI:    "pkg:docker/alpine@3.20?digest=sha256:0a4eaa0eecf5f8c050e5bba433f58c052be7587ee8af3e8b3910ef9ab5fbe9f5",
+			Digest: map[string]string{"sha256": "0a4eaa0eecf5f8c050e5bba433f58c052be7587ee8af3e8b3910ef9ab5fbe9f5"},
+		},
+		{URI: "git+https://github.com/dagger/dagger"},
+		{URI: "https://example.com/tool.tar.gz"},
+	}, provenanceDependencies(id))
+}
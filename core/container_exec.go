@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"math"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -17,9 +18,11 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	ctrdmount "github.com/containerd/containerd/v2/core/mount"
 	containerdfs "github.com/containerd/continuity/fs"
+	units "github.com/docker/go-units"
 
 	bkcache "github.com/dagger/dagger/engine/snapshots"
 	"github.com/dagger/dagger/internal/buildkit/executor"
@@ -85,6 +88,121 @@ type ContainerExecOpts struct {
 	// Skip the init process injected into containers by default so that the
 	// user's process is PID 1
 	NoInit bool `default:"false"`
+
+	// Maximum duration the command may run before it is killed, e.g. "5m".
+	// Overrides the container's default exec timeout.
+	Timeout string `default:""`
+}
+
+// ParseExecTimeout parses a withExec timeout argument. An empty string means
+// no timeout.
+func ParseExecTimeout(timeout string) (time.Duration, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: %w", timeout, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must not be negative", timeout)
+	}
+	return d, nil
+}
+
+// ParseResourceLimits parses withResourceLimits arguments. CPU is a number of
+// CPUs ("1.5") or millicpus ("500m"); memory is a size with an optional unit
+// suffix ("512m", "2GiB"). Empty values and zero leave a resource unlimited.
+func ParseResourceLimits(cpu, memory string, pids int) (executor.ResourceLimits, error) {
+	var limits executor.ResourceLimits
+	if cpu != "" {
+		var milliCPU float64
+		var err error
+		if millis, ok := strings.CutSuffix(cpu, "m"); ok {
+			milliCPU, err = strconv.ParseFloat(millis, 64)
+		} else {
+			milliCPU, err = strconv.ParseFloat(cpu, 64)
+			milliCPU *= 1000
+		}
+		if err != nil || milliCPU < 0 || math.IsInf(milliCPU, 0) || math.IsNaN(milliCPU) {
+			return limits, fmt.Errorf("invalid cpu limit %q: must be a number of CPUs (e.g. \"1.5\") or millicpus (e.g. \"500m\")", cpu)
+		}
+		if milliCPU > 0 && milliCPU < 10 {
+			return limits, fmt.Errorf("invalid cpu limit %q: must be at least 10m", cpu)
+		}
+		limits.MilliCPU = int64(milliCPU)
+	}
+	if memory != "" {
+		bytes, err := units.RAMInBytes(memory)
+		if err != nil {
+			return limits, fmt.Errorf("invalid memory limit %q: %w", memory, err)
+		}
+		if bytes < 0 {
+			return limits, fmt.Errorf("invalid memory limit %q: must not be negative", memory)
+		}
+		if bytes > 0 && bytes < minMemoryLimit {
+			return limits, fmt.Errorf("invalid memory limit %q: must be at least %s", memory, units.BytesSize(minMemoryLimit))
+		}
+		limits.MemoryBytes = bytes
+	}
+	if pids < 0 {
+		return limits, fmt.Errorf("invalid pids limit %d: must not be negative", pids)
+	}
+	limits.Pids = int64(pids)
+	return limits, nil
+}
+
+// ParseSecurityProfile parses withSecurityProfile arguments. Capabilities may
+// be given with or without the "CAP_" prefix, in any case.
+func ParseSecurityProfile(seccompProfile string, capAdd, capDrop []string, readOnlyRootfs, noNewPrivileges bool) (executor.SecurityProfile, error) {
+	profile := executor.SecurityProfile{
+		ReadOnlyRootfs:  readOnlyRootfs,
+		NoNewPrivileges: noNewPrivileges,
+	}
+	if seccompProfile != "" {
+		if err := engineutil.ValidateSeccompProfile(seccompProfile); err != nil {
+			return profile, err
+		}
+		profile.SeccompProfile = seccompProfile
+	}
+	for _, name := range capAdd {
+		capName, err := engineutil.NormalizeCapability(name)
+		if err != nil {
+			return profile, err
+		}
+		if capName == "ALL" {
+			return profile, errors.New(`invalid capability "ALL": adding all capabilities requires insecureRootCapabilities`)
+		}
+		profile.CapAdd = append(profile.CapAdd, capName)
+	}
+	for _, name := range capDrop {
+		capName, err := engineutil.NormalizeCapability(name)
+		if err != nil {
+			return profile, err
+		}
+		profile.CapDrop = append(profile.CapDrop, capName)
+	}
+	return profile, nil
+}
+
+// minMemoryLimit is the smallest memory limit accepted; anything lower can't
+// fit the container's init process.
+const minMemoryLimit = 6 * 1024 * 1024
+
+// ExecTimeout returns the timeout to apply to an exec with the given opts,
+// falling back to the container's default.
+func (container *Container) ExecTimeout(opts ContainerExecOpts) (time.Duration, error) {
+	timeout, err := ParseExecTimeout(opts.Timeout)
+	if err != nil {
+		return 0, err
+	}
+	if timeout == 0 {
+		timeout = container.DefaultExecTimeout
+	}
+	if opts.Expect == ReturnTimeout && timeout == 0 {
+		return 0, fmt.Errorf("expect %s requires a timeout", ReturnTimeout)
+	}
+	return timeout, nil
 }
 
 type ContainerExecState struct {
@@ -318,6 +436,10 @@ func (container *Container) metaSpec(ctx context.Context, opts ContainerExecOpts
 	if err != nil {
 		return nil, fmt.Errorf("get current query: %w", err)
 	}
+	clientMetadata, err := engine.ClientMetadataFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get client metadata: %w", err)
+	}
 
 	cfg := container.Config
 	args, err := container.command(opts)
@@ -334,8 +456,14 @@ func (container *Container) metaSpec(ctx context.Context, opts ContainerExecOpts
 		Env:                       slices.Clone(cfg.Env),
 		Cwd:                       cmp.Or(cfg.WorkingDir, "/"),
 		User:                      cfg.User,
+		ResourceLimits:            container.ResourceLimits,
+		SecurityProfile:           container.SecurityProfile,
+		EgressPolicies:            slices.Clone(clientMetadata.EgressPolicies),
 		RemoveMountStubsRecursive: true,
 	}
+	if container.EgressPolicy != nil {
+		metaSpec.EgressPolicies = append(metaSpec.EgressPolicies, *container.EgressPolicy)
+	}
 	if opts.InsecureRootCapabilities {
 		metaSpec.SecurityMode = pb.SecurityMode_INSECURE
 	}
@@ -503,18 +631,29 @@ func monitorServiceBindings(
 }
 
 func (container *Container) secretEnvValues(ctx context.Context) ([]string, error) {
+	env, _, err := container.secretEnvValuesWithExpiry(ctx)
+	return env, err
+}
+
+// secretEnvValuesWithExpiry is like secretEnvValues, but also returns the
+// earliest expiry of the secrets, or the zero time if none of them expire.
+func (container *Container) secretEnvValuesWithExpiry(ctx context.Context) ([]string, time.Time, error) {
 	env := make([]string, 0, len(container.Secrets))
+	var expiresAt time.Time
 	for _, secret := range container.Secrets {
 		if secret.EnvName == "" {
 			continue
 		}
-		plaintext, err := secret.Secret.Self().Plaintext(ctx)
+		plaintext, secretExpiresAt, err := secret.Secret.Self().PlaintextWithExpiry(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("secret env %q: %w", secret.EnvName, err)
+			return nil, time.Time{}, fmt.Errorf("secret env %q: %w", secret.EnvName, err)
 		}
 		env = append(env, secret.EnvName+"="+string(plaintext))
+		if !secretExpiresAt.IsZero() && (expiresAt.IsZero() || secretExpiresAt.Before(expiresAt)) {
+			expiresAt = secretExpiresAt
+		}
 	}
-	return env, nil
+	return env, expiresAt, nil
 }
 
 type execSecretMountConfig struct {
@@ -1317,6 +1456,22 @@ func (state *ContainerExecState) Evaluate(ctx context.Context, container *Contai
 		if err != nil {
 			return err
 		}
+		if state.ModuleContext.Self() != nil {
+			// the module's policy applies to its functions, and is inherited by
+			// the containers they run
+			modPolicy, err := query.ModuleEgressPolicy(ctx, state.ModuleContext.Self().Name())
+			if err != nil {
+				return err
+			}
+			if modPolicy != nil {
+				metaSpec.EgressPolicies = append(metaSpec.EgressPolicies, *modPolicy)
+			}
+		}
+		metaSpec.Timeout, err = container.ExecTimeout(opts)
+		if err != nil {
+			return err
+		}
+		metaSpec.ValidTimeout = opts.Expect == ReturnTimeout
 
 		engineClient, err := query.Engine(ctx)
 		if err != nil {
@@ -1906,6 +2061,10 @@ func (state *ContainerExecState) Evaluate(ctx context.Context, container *Contai
 				}
 				meta := *metaSpec
 				meta.Args = []string{"/bin/sh"}
+				// the debug terminal is interactive; don't kill it on the
+				// failed exec's timeout
+				meta.Timeout = 0
+				meta.ValidTimeout = false
 				if len(engineClient.InteractiveCommand) > 0 {
 					meta.Args = engineClient.InteractiveCommand
 				}
@@ -2137,6 +2296,9 @@ func (state *ContainerExecState) Evaluate(ctx context.Context, container *Contai
 				ClientVersion:         engine.Version,
 				SessionID:             clientMetadata.SessionID,
 				AllowedLLMModules:     slices.Clone(clientMetadata.AllowedLLMModules),
+				EgressPolicies:        slices.Clone(meta.EgressPolicies),
+				CacheTags:             slices.Clone(clientMetadata.CacheTags),
+				CacheModule:           clientMetadata.CacheModule,
 				UseRecipeIDsByDefault: execMD != nil && execMD.UseRecipeIDsByDefault,
 			}
 		}
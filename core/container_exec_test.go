@@ -1,9 +1,15 @@
 package core
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/internal/buildkit/executor"
 	"github.com/dagger/dagger/internal/buildkit/solver/pb"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/require"
 )
 
@@ -41,3 +47,125 @@ func TestExecNetModeConflict(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "cannot set both noNetwork and hostNetwork")
 }
+
+func TestExecTimeoutOverridesContainerDefault(t *testing.T) {
+	t.Parallel()
+
+	ctr := &Container{DefaultExecTimeout: time.Minute}
+
+	timeout, err := ctr.ExecTimeout(ContainerExecOpts{})
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, timeout)
+
+	timeout, err = ctr.ExecTimeout(ContainerExecOpts{Timeout: "5s"})
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Second, timeout)
+}
+
+func TestExecTimeoutInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := (&Container{}).ExecTimeout(ContainerExecOpts{Timeout: "soon"})
+	require.ErrorContains(t, err, `invalid timeout "soon"`)
+
+	_, err = (&Container{}).ExecTimeout(ContainerExecOpts{Timeout: "-1s"})
+	require.ErrorContains(t, err, "must not be negative")
+}
+
+func TestExecTimeoutExpectTimeoutRequiresTimeout(t *testing.T) {
+	t.Parallel()
+
+	_, err := (&Container{}).ExecTimeout(ContainerExecOpts{Expect: ReturnTimeout})
+	require.ErrorContains(t, err, "expect TIMEOUT requires a timeout")
+
+	timeout, err := (&Container{}).ExecTimeout(ContainerExecOpts{Expect: ReturnTimeout, Timeout: "1s"})
+	require.NoError(t, err)
+	require.Equal(t, time.Second, timeout)
+	require.Empty(t, ReturnTimeout.ReturnCodes())
+	require.NotNil(t, ReturnTimeout.ReturnCodes())
+}
+
+func TestParseResourceLimits(t *testing.T) {
+	t.Parallel()
+
+	limits, err := ParseResourceLimits("1.5", "512m", 64)
+	require.NoError(t, err)
+	require.Equal(t, executor.ResourceLimits{MilliCPU: 1500, MemoryBytes: 512 << 20, Pids: 64}, limits)
+
+	limits, err = ParseResourceLimits("250m", "2GiB", 0)
+	require.NoError(t, err)
+	require.Equal(t, executor.ResourceLimits{MilliCPU: 250, MemoryBytes: 2 << 30}, limits)
+
+	limits, err = ParseResourceLimits("", "", 0)
+	require.NoError(t, err)
+	require.True(t, limits.IsZero())
+}
+
+func TestParseResourceLimitsInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		cpu, memory string
+		pids        int
+	}{
+		{cpu: "lots"},
+		{cpu: "-1"},
+		{cpu: "1m"},
+		{memory: "big"},
+		{memory: "1k"},
+		{pids: -1},
+	} {
+		_, err := ParseResourceLimits(tc.cpu, tc.memory, tc.pids)
+		require.Error(t, err, "cpu=%q memory=%q pids=%d", tc.cpu, tc.memory, tc.pids)
+	}
+}
+
+func TestContainerPersistedResourceLimits(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cache, err := dagql.NewCache(ctx, "", nil, nil)
+	require.NoError(t, err)
+	ctx = dagql.ContextWithCache(ctx, cache)
+
+	container := NewContainer(Platform(specs.Platform{OS: "linux", Architecture: "amd64"}))
+	encoded, err := container.EncodePersistedObject(ctx, cache)
+	require.NoError(t, err)
+	require.NotContains(t, string(encoded.JSON), "resourceLimits")
+
+	container.ResourceLimits = executor.ResourceLimits{MemoryBytes: 512 << 20}
+	encoded, err = container.EncodePersistedObject(ctx, cache)
+	require.NoError(t, err)
+	var raw persistedContainerPayload
+	require.NoError(t, json.Unmarshal(encoded.JSON, &raw))
+	require.Equal(t, container.ResourceLimits, raw.ResourceLimits)
+}
+
+func TestParseSecurityProfile(t *testing.T) {
+	t.Parallel()
+
+	profile, err := ParseSecurityProfile("", []string{"net_admin"}, []string{"CAP_NET_RAW", "all"}, true, true)
+	require.NoError(t, err)
+	require.Equal(t, executor.SecurityProfile{
+		CapAdd:          []string{"CAP_NET_ADMIN"},
+		CapDrop:         []string{"CAP_NET_RAW", "ALL"},
+		ReadOnlyRootfs:  true,
+		NoNewPrivileges: true,
+	}, profile)
+
+	profile, err = ParseSecurityProfile("", nil, nil, false, false)
+	require.NoError(t, err)
+	require.True(t, profile.IsZero())
+
+	_, err = ParseSecurityProfile("", []string{"ALL"}, nil, false, false)
+	require.ErrorContains(t, err, "requires insecureRootCapabilities")
+
+	_, err = ParseSecurityProfile("", nil, []string{"net raw"}, false, false)
+	require.ErrorContains(t, err, "invalid capability")
+
+	_, err = ParseSecurityProfile(`{"syscalls": []}`, nil, nil, false, false)
+	require.ErrorContains(t, err, "missing defaultAction")
+
+	_, err = ParseSecurityProfile(`not json`, nil, nil, false, false)
+	require.ErrorContains(t, err, "invalid seccomp profile")
+}
@@ -308,6 +308,7 @@ func (container *Container) AsTarball(
 	forcedCompression ImageLayerCompression,
 	mediaTypes ImageMediaTypes,
 	filePath string,
+	attest ImageAttestationOpts,
 ) (f *File, rerr error) {
 	query, err := CurrentQuery(ctx)
 	if err != nil {
@@ -323,7 +324,7 @@ func (container *Container) AsTarball(
 	}
 
 	variants := filterEmptyContainers(append([]*Container{container}, platformVariants...))
-	inputByPlatform, err := getVariantRefs(ctx, variants)
+	inputByPlatform, err := getVariantRefs(ctx, variants, attest)
 	if err != nil {
 		return nil, err
 	}
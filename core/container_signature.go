@@ -0,0 +1,152 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/containerd/containerd/v2/core/content"
+	serverresolver "github.com/dagger/dagger/engine/server/resolver"
+	"github.com/dagger/dagger/internal/buildkit/util/contentutil"
+	"github.com/dagger/dagger/util/cosign"
+	"github.com/distribution/reference"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// maxSignatureManifests bounds how many signature manifests of an image are
+// checked against a verification policy.
+const maxSignatureManifests = 32
+
+// ImageSignaturePolicy is the signature an image pulled by Container.from
+// must carry.
+type ImageSignaturePolicy struct {
+	// Key the image must be signed with.
+	Key crypto.PublicKey
+}
+
+func (policy ImageSignaturePolicy) Enabled() bool {
+	return policy.Key != nil
+}
+
+// ParseImageSignaturePolicy builds a policy from a PEM encoded public key,
+// which may be empty. A signature can only be required along with the key
+// that must have made it: any signature, from anyone, proves nothing.
+func ParseImageSignaturePolicy(publicKey string, required bool) (ImageSignaturePolicy, error) {
+	var policy ImageSignaturePolicy
+	if publicKey == "" {
+		if required {
+			return policy, errors.New("requiring a signature needs the key that must have made it")
+		}
+		return policy, nil
+	}
+	key, err := cosign.LoadPublicKey([]byte(publicKey))
+	if err != nil {
+		return policy, fmt.Errorf("signature key: %w", err)
+	}
+	policy.Key = key
+	return policy, nil
+}
+
+// VerifyImageSignature checks that the image is signed according to policy.
+// Signatures are looked up as OCI referrers of the image's digest in its
+// repository, so verification only needs access to the registry the image is
+// pulled from.
+func VerifyImageSignature(
+	ctx context.Context,
+	ref reference.Canonical,
+	policy ImageSignaturePolicy,
+	network serverresolver.NetworkConfig,
+	registryTransport serverresolver.RegistryTransport,
+) error {
+	query, err := CurrentQuery(ctx)
+	if err != nil {
+		return err
+	}
+	rslvr, err := query.RegistryResolver(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get registry resolver: %w", err)
+	}
+	descs, provider, err := rslvr.Referrers(ctx, ref.String(), ref.Digest(), serverresolver.ReferrersOpts{
+		ArtifactType:      cosign.ArtifactTypeSignature,
+		Network:           network,
+		RegistryTransport: registryTransport,
+	})
+	if err != nil {
+		return fmt.Errorf("verify signature of %s: %w", ref, err)
+	}
+	if len(descs) == 0 {
+		return fmt.Errorf("verify signature of %s: image is not signed", ref)
+	}
+	if len(descs) > maxSignatureManifests {
+		descs = descs[:maxSignatureManifests]
+	}
+
+	readBlob := func(desc specs.Descriptor) ([]byte, error) {
+		return content.ReadBlob(ctx, provider, desc)
+	}
+	var errs []error
+	for _, desc := range descs {
+		manifestJSON, err := readBlob(desc)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("read signature manifest %s: %w", desc.Digest, err))
+			continue
+		}
+		if err := cosign.VerifyArtifact(manifestJSON, ref.Digest(), policy.Key, readBlob); err != nil {
+			errs = append(errs, fmt.Errorf("signature manifest %s: %w", desc.Digest, err))
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("verify signature of %s: no valid signature: %w", ref, errors.Join(errs...))
+}
+
+// pushImageSignature signs the published image's root manifest and pushes
+// the signature as a referrer of it.
+func pushImageSignature(
+	ctx context.Context,
+	ref reference.Named,
+	subject specs.Descriptor,
+	signer crypto.Signer,
+	network serverresolver.NetworkConfig,
+	registryTransport serverresolver.RegistryTransport,
+) error {
+	query, err := CurrentQuery(ctx)
+	if err != nil {
+		return err
+	}
+	rslvr, err := query.RegistryResolver(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get registry resolver: %w", err)
+	}
+
+	payload, err := cosign.NewPayload(ref.Name(), subject.Digest)
+	if err != nil {
+		return err
+	}
+	signature, err := cosign.Sign(signer, payload)
+	if err != nil {
+		return fmt.Errorf("sign image: %w", err)
+	}
+	artifact, err := cosign.SignatureArtifact(subject, payload, signature)
+	if err != nil {
+		return err
+	}
+	buf := contentutil.NewBuffer()
+	for dgst, dt := range artifact.Blobs {
+		if err := content.WriteBlob(ctx, buf, dgst.String(), bytes.NewReader(dt), specs.Descriptor{
+			Digest: dgst,
+			Size:   int64(len(dt)),
+		}); err != nil {
+			return err
+		}
+	}
+	return rslvr.PushReferrer(ctx, &serverresolver.PushedImage{
+		RootDesc: artifact.Manifest,
+		Provider: buf,
+	}, ref.String(), serverresolver.PushOpts{
+		RegistryTransport: registryTransport,
+		Network:           network,
+	})
+}
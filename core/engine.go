@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"time"
 
 	"github.com/vektah/gqlparser/v2/ast"
 )
@@ -36,6 +37,19 @@ type EngineCachePruneOptions struct {
 	TargetSpace          string
 	MaxEstimatedBytes    *int64
 	TargetEstimatedBytes *int64
+
+	// Modules, Fields and Tags narrow pruning to the entries produced by a
+	// function of one of the modules, by one of the fields, or by a client
+	// with one of the cache tags. OlderThan narrows it to the entries not used
+	// for at least that long. Entries must match all the given narrowings.
+	Modules   []string
+	Fields    []string
+	Tags      []string
+	OlderThan time.Duration
+
+	// DryRun reports the disk cache entries that would be pruned, without
+	// pruning anything.
+	DryRun bool
 }
 
 func (*EngineCache) Type() *ast.Type {
@@ -84,6 +98,10 @@ type EngineCacheEntry struct {
 	RecordType                string   `field:"true" doc:"The type of the cache record (e.g. regular, internal, frontend, source.local, source.git.checkout, exec.cachemount)."`
 	RecordTypes               []string `field:"true" doc:"The storage record types represented by this cache entry."`
 	DagqlCall                 string   `field:"true" doc:"The DagQL call that produced this cache entry."`
+
+	// Installed as version-gated fields in core/schema/engine.go.
+	Module string
+	Tags   []string
 }
 
 func (*EngineCacheEntry) Type() *ast.Type {
@@ -96,3 +114,39 @@ func (*EngineCacheEntry) Type() *ast.Type {
 func (*EngineCacheEntry) TypeDescription() string {
 	return "An individual cache entry in a cache entry set"
 }
+
+type EngineCacheMissExplanation struct {
+	Call    string `field:"true" doc:"The digest of the explained call."`
+	Nearest string `field:"true" doc:"The digest of the most similar call of the same field cached before it, or empty if there was none."`
+
+	DifferencesList []*EngineCacheMissDifference
+}
+
+func (*EngineCacheMissExplanation) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "EngineCacheMissExplanation",
+		NonNull:   true,
+	}
+}
+
+func (*EngineCacheMissExplanation) TypeDescription() string {
+	return "Why a call executed instead of reusing a cached result"
+}
+
+type EngineCacheMissDifference struct {
+	Call    string `field:"true" doc:"The call the input belongs to, as called this time."`
+	Input   string `field:"true" doc:"The name of the input: an argument, an implicit input suffixed with \" (implicit)\", or one of \"field\", \"receiver\", \"module\", \"view\", \"nth\" and \"content\"."`
+	Cached  string `field:"true" doc:"The input as the nearest cached call had it."`
+	Current string `field:"true" doc:"The input as the explained call had it."`
+}
+
+func (*EngineCacheMissDifference) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "EngineCacheMissDifference",
+		NonNull:   true,
+	}
+}
+
+func (*EngineCacheMissDifference) TypeDescription() string {
+	return "An input that differs between a call and the nearest cached one"
+}
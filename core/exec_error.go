@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dagger/dagger/dagql"
 	"github.com/dagger/dagger/engine/engineutil"
@@ -26,6 +27,13 @@ type ExecError struct {
 	ExitCode int
 	Stdout   string
 	Stderr   string
+	// Timeout is set when the process was killed for exceeding its timeout,
+	// in which case Stdout and Stderr hold the output produced until then.
+	Timeout time.Duration
+	// MemoryLimit is set when the process was OOM killed for exceeding its
+	// container's memory limit, along with the peak memory observed, in bytes.
+	MemoryLimit int64
+	PeakMemory  int64
 }
 
 func (e *ExecError) Error() string {
@@ -36,14 +44,35 @@ func (e *ExecError) Unwrap() error {
 	return e.Err
 }
 
+// TimedOut reports whether the process was killed for exceeding its timeout.
+func (e *ExecError) TimedOut() bool {
+	return e.Timeout > 0
+}
+
+// OOMKilled reports whether the process was killed for exceeding its
+// container's memory limit.
+func (e *ExecError) OOMKilled() bool {
+	return e.MemoryLimit > 0
+}
+
 func (e *ExecError) Extensions() map[string]any {
-	return map[string]any{
+	ext := map[string]any{
 		"_type":    "EXEC_ERROR",
 		"cmd":      e.Cmd,
 		"exitCode": e.ExitCode,
 		"stdout":   e.Stdout,
 		"stderr":   e.Stderr,
 	}
+	if e.TimedOut() {
+		ext["timedOut"] = true
+		ext["timeout"] = e.Timeout.String()
+	}
+	if e.OOMKilled() {
+		ext["oomKilled"] = true
+		ext["memoryLimit"] = e.MemoryLimit
+		ext["peakMemory"] = e.PeakMemory
+	}
+	return ext
 }
 
 func execErrorFromMetaRef(
@@ -78,6 +107,15 @@ func execErrorFromMetaRef(
 	if meta != nil {
 		execErr.Cmd = meta.Args
 	}
+	var timeoutErr *engineutil.ExecTimeoutError
+	if errors.As(cause, &timeoutErr) {
+		execErr.Timeout = timeoutErr.Timeout
+	}
+	var oomErr *engineutil.ExecOOMError
+	if errors.As(cause, &oomErr) {
+		execErr.MemoryLimit = oomErr.MemoryLimit
+		execErr.PeakMemory = oomErr.PeakMemory
+	}
 	return execErr, true, nil
 }
 
@@ -10,13 +10,17 @@ package core
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/md5"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/x509"
 	_ "embed"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"net"
@@ -3124,6 +3128,102 @@ func (ContainerSuite) TestPublishScratchRootFSHasNoLayers(ctx context.Context, t
 	require.Empty(t, manifest.Layers, "published scratch rootfs should not include an empty layer")
 }
 
+func (ContainerSuite) TestPublishSignedAndVerifyFrom(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	newKey := func() (privPEM, pubPEM string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		privDER, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+		require.NoError(t, err)
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})),
+			string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+	}
+	privKey, pubKey := newKey()
+	_, otherPubKey := newKey()
+
+	keyID, err := c.SetSecret("signing-key", privKey).ID(ctx)
+	require.NoError(t, err)
+
+	res, err := testutil.QueryWithClient[struct {
+		Container struct {
+			WithNewFile struct {
+				Publish string
+			}
+		}
+	}](c, t, `query Test($ref: String!, $key: SecretID!) {
+		container {
+			withNewFile(path: "/hello.txt", contents: "hello") {
+				publish(address: $ref, signingKey: $key)
+			}
+		}
+	}`, &testutil.QueryOptions{Variables: map[string]any{
+		"ref": registryRef("container-publish-signed"),
+		"key": keyID,
+	}})
+	require.NoError(t, err)
+	signedRef := res.Container.WithNewFile.Publish
+
+	unsignedRef, err := c.Container().
+		WithNewFile("/hello.txt", "hello").
+		Publish(ctx, registryRef("container-publish-unsigned"))
+	require.NoError(t, err)
+
+	from := func(ref string, args string, key string) (string, error) {
+		varDefs := "$ref: String!"
+		vars := map[string]any{"ref": ref}
+		if key != "" {
+			varDefs += ", $key: String!"
+			vars["key"] = key
+		}
+		res, err := testutil.QueryWithClient[struct {
+			Container struct {
+				From struct {
+					File struct {
+						Contents string
+					}
+				}
+			}
+		}](c, t, `query Test(`+varDefs+`) {
+			container {
+				from(address: $ref, `+args+`) {
+					file(path: "/hello.txt") {
+						contents
+					}
+				}
+			}
+		}`, &testutil.QueryOptions{Variables: vars})
+		return res.Container.From.File.Contents, err
+	}
+
+	t.Run("valid signature", func(ctx context.Context, t *testctx.T) {
+		contents, err := from(signedRef, "signatureKey: $key", pubKey)
+		require.NoError(t, err)
+		require.Equal(t, "hello", contents)
+
+		contents, err = from(signedRef, "signatureKey: $key, requireSignature: true", pubKey)
+		require.NoError(t, err)
+		require.Equal(t, "hello", contents)
+	})
+
+	t.Run("required without key", func(ctx context.Context, t *testctx.T) {
+		_, err := from(signedRef, "requireSignature: true", "")
+		require.ErrorContains(t, err, "requiring a signature needs the key")
+	})
+
+	t.Run("wrong key", func(ctx context.Context, t *testctx.T) {
+		_, err := from(signedRef, "signatureKey: $key", otherPubKey)
+		require.ErrorContains(t, err, "no valid signature")
+	})
+
+	t.Run("unsigned", func(ctx context.Context, t *testctx.T) {
+		_, err := from(unsignedRef, "signatureKey: $key", pubKey)
+		require.ErrorContains(t, err, "image is not signed")
+	})
+}
+
 func (ContainerSuite) TestAnnotations(ctx context.Context, t *testctx.T) {
 	build := func(c *dagger.Client, platform dagger.Platform) *dagger.Container {
 		return c.Container(dagger.ContainerOpts{Platform: platform}).
@@ -3456,6 +3556,90 @@ func (ContainerSuite) TestAsTarballCached(ctx context.Context, t *testctx.T) {
 	require.Equal(t, first, second)
 }
 
+func (ContainerSuite) TestAsTarballAttestations(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+	imagePath := filepath.Join(t.TempDir(), "image.tar")
+
+	_, err := testutil.QueryWithClient[struct {
+		Container struct {
+			From struct {
+				AsTarball struct {
+					Export string
+				}
+			}
+		}
+	}](c, t, `query Test($path: String!) {
+		container {
+			from(address: "`+alpineImage+`") {
+				asTarball(sbom: SPDX, provenance: true) {
+					export(path: $path)
+				}
+			}
+		}
+	}`, &testutil.QueryOptions{Variables: map[string]any{"path": imagePath}})
+	require.NoError(t, err)
+
+	var idx ocispecs.Index
+	require.NoError(t, json.Unmarshal(readTarFile(t, imagePath, "index.json"), &idx))
+	require.Len(t, idx.Manifests, 1)
+	require.NoError(t, json.Unmarshal(readTarFile(t, imagePath, "blobs/sha256/"+idx.Manifests[0].Digest.Encoded()), &idx))
+	require.Len(t, idx.Manifests, 2)
+
+	imageDesc, attDesc := idx.Manifests[0], idx.Manifests[1]
+	require.Equal(t, "attestation-manifest", attDesc.Annotations["vnd.docker.reference.type"])
+	require.Equal(t, imageDesc.Digest.String(), attDesc.Annotations["vnd.docker.reference.digest"])
+	require.Equal(t, "unknown", attDesc.Platform.OS)
+
+	var attManifest ocispecs.Manifest
+	require.NoError(t, json.Unmarshal(readTarFile(t, imagePath, "blobs/sha256/"+attDesc.Digest.Encoded()), &attManifest))
+	require.Len(t, attManifest.Layers, 2)
+
+	type statement struct {
+		PredicateType string `json:"predicateType"`
+		Subject       []struct {
+			Digest map[string]string `json:"digest"`
+		} `json:"subject"`
+		Predicate json.RawMessage `json:"predicate"`
+	}
+	predicates := map[string]json.RawMessage{}
+	for _, layer := range attManifest.Layers {
+		require.Equal(t, "application/vnd.in-toto+json", layer.MediaType)
+		var stmt statement
+		require.NoError(t, json.Unmarshal(readTarFile(t, imagePath, "blobs/sha256/"+layer.Digest.Encoded()), &stmt))
+		require.Len(t, stmt.Subject, 1)
+		require.Equal(t, imageDesc.Digest.Encoded(), stmt.Subject[0].Digest["sha256"])
+		predicates[stmt.PredicateType] = stmt.Predicate
+	}
+
+	require.Contains(t, predicates, "https://spdx.dev/Document")
+	var spdxDoc struct {
+		Packages []struct {
+			Name string `json:"name"`
+		} `json:"packages"`
+	}
+	require.NoError(t, json.Unmarshal(predicates["https://spdx.dev/Document"], &spdxDoc))
+	var pkgNames []string
+	for _, pkg := range spdxDoc.Packages {
+		pkgNames = append(pkgNames, pkg.Name)
+	}
+	require.Contains(t, pkgNames, "busybox")
+	require.Contains(t, pkgNames, "musl")
+
+	require.Contains(t, predicates, "https://slsa.dev/provenance/v1")
+	var provenance struct {
+		BuildDefinition struct {
+			BuildType            string `json:"buildType"`
+			ResolvedDependencies []struct {
+				URI string `json:"uri"`
+			} `json:"resolvedDependencies"`
+		} `json:"buildDefinition"`
+	}
+	require.NoError(t, json.Unmarshal(predicates["https://slsa.dev/provenance/v1"], &provenance))
+	require.Equal(t, "https://dagger.io/provenance/container@v1", provenance.BuildDefinition.BuildType)
+	require.Len(t, provenance.BuildDefinition.ResolvedDependencies, 1)
+	require.Contains(t, provenance.BuildDefinition.ResolvedDependencies[0].URI, "pkg:docker/alpine@")
+}
+
 func (ContainerSuite) TestImport(ctx context.Context, t *testctx.T) {
 	c := connect(ctx, t)
 
@@ -5324,6 +5508,432 @@ func (ContainerSuite) TestExecExpect(ctx context.Context, t *testctx.T) {
 	})
 }
 
+func (ContainerSuite) TestExecTimeout(ctx context.Context, t *testctx.T) {
+	type execResult struct {
+		Container struct {
+			From struct {
+				WithExec struct {
+					Stdout   string
+					ExitCode int
+				}
+			}
+		}
+	}
+
+	t.Run("kills the process and keeps partial output", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		_, err := testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withExec(args: ["sh", "-c", "echo started; sh -c 'sleep 600' & wait"], timeout: "2s") {
+						stdout
+					}
+				}
+			}
+		}`, nil)
+		requireErrOut(t, err, "process timed out after 2s")
+
+		var exErr *dagger.ExecError
+		require.ErrorAs(t, err, &exErr)
+		require.Equal(t, "started", exErr.Stdout)
+	})
+
+	t.Run("finishes within timeout", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		res, err := testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withExec(args: ["echo", "done"], timeout: "1m") {
+						stdout
+					}
+				}
+			}
+		}`, nil)
+		require.NoError(t, err)
+		require.Equal(t, "done\n", res.Container.From.WithExec.Stdout)
+	})
+
+	t.Run("container default", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		_, err := testutil.QueryWithClient[struct {
+			Container struct {
+				From struct {
+					WithDefaultExecTimeout struct {
+						WithExec struct {
+							Stdout string
+						}
+					}
+				}
+			}
+		}](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withDefaultExecTimeout(timeout: "2s") {
+						withExec(args: ["sleep", "600"]) {
+							stdout
+						}
+					}
+				}
+			}
+		}`, nil)
+		requireErrOut(t, err, "process timed out after 2s")
+	})
+
+	t.Run("expect timeout", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		res, err := testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withExec(args: ["sh", "-c", "echo started; sleep 600"], timeout: "2s", expect: TIMEOUT) {
+						stdout
+						exitCode
+					}
+				}
+			}
+		}`, nil)
+		require.NoError(t, err)
+		require.Equal(t, "started\n", res.Container.From.WithExec.Stdout)
+		require.Equal(t, 137, res.Container.From.WithExec.ExitCode)
+
+		_, err = testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withExec(args: ["true"], timeout: "1m", expect: TIMEOUT) {
+						exitCode
+					}
+				}
+			}
+		}`, nil)
+		requireErrOut(t, err, "exit code: 0")
+
+		_, err = testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withExec(args: ["true"], expect: TIMEOUT) {
+						exitCode
+					}
+				}
+			}
+		}`, nil)
+		requireErrOut(t, err, "expect TIMEOUT requires a timeout")
+	})
+}
+
+func (ContainerSuite) TestResourceLimits(ctx context.Context, t *testctx.T) {
+	type execResult struct {
+		Container struct {
+			From struct {
+				WithResourceLimits struct {
+					WithExec struct {
+						Stdout string
+					}
+				}
+			}
+		}
+	}
+
+	t.Run("applies limits to the cgroup", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		res, err := testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withResourceLimits(cpu: "500m", memory: "64m", pids: 32) {
+						withExec(args: ["cat", "/sys/fs/cgroup/cpu.max", "/sys/fs/cgroup/memory.max", "/sys/fs/cgroup/pids.max"]) {
+							stdout
+						}
+					}
+				}
+			}
+		}`, nil)
+		require.NoError(t, err)
+		require.Equal(t, "50000 100000\n67108864\n32\n", res.Container.From.WithResourceLimits.WithExec.Stdout)
+	})
+
+	t.Run("OOM kill reports peak memory", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		_, err := testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withResourceLimits(memory: "32m") {
+						withExec(args: ["sh", "-c", "head -c 256m /dev/zero | tail"]) {
+							stdout
+						}
+					}
+				}
+			}
+		}`, nil)
+		requireErrOut(t, err, "process killed after exceeding memory limit of 32MiB")
+
+		var exErr *dagger.ExecError
+		require.ErrorAs(t, err, &exErr)
+		require.Equal(t, 137, exErr.ExitCode)
+	})
+
+	t.Run("invalid limits", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		_, err := testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withResourceLimits(memory: "lots") {
+						withExec(args: ["true"]) {
+							stdout
+						}
+					}
+				}
+			}
+		}`, nil)
+		requireErrOut(t, err, `invalid memory limit "lots"`)
+	})
+}
+
+func (ContainerSuite) TestSecurityProfile(ctx context.Context, t *testctx.T) {
+	type execResult struct {
+		Container struct {
+			From struct {
+				WithSecurityProfile struct {
+					WithExec struct {
+						Stdout string
+					}
+				}
+			}
+		}
+	}
+
+	t.Run("capabilities and no new privileges", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		res, err := testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withSecurityProfile(capDrop: ["ALL"], capAdd: ["chown"], noNewPrivileges: true) {
+						withExec(args: ["sh", "-c", "grep -E '^(CapEff|NoNewPrivs):' /proc/self/status"]) {
+							stdout
+						}
+					}
+				}
+			}
+		}`, nil)
+		require.NoError(t, err)
+		require.Equal(t, "CapEff:\t0000000000000001\nNoNewPrivs:\t1\n", res.Container.From.WithSecurityProfile.WithExec.Stdout)
+	})
+
+	t.Run("read-only rootfs", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		_, err := testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withSecurityProfile(readOnlyRootfs: true) {
+						withExec(args: ["touch", "/foo"]) {
+							stdout
+						}
+					}
+				}
+			}
+		}`, nil)
+		requireErrOut(t, err, "Read-only file system")
+	})
+
+	t.Run("seccomp profile", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		profileID, err := c.Directory().
+			WithNewFile("seccomp.json", `{
+				"defaultAction": "SCMP_ACT_ALLOW",
+				"syscalls": [{"names": ["mkdir", "mkdirat"], "action": "SCMP_ACT_ERRNO"}]
+			}`).
+			File("seccomp.json").
+			ID(ctx)
+		require.NoError(t, err)
+
+		_, err = testutil.QueryWithClient[execResult](c, t,
+			`query Test($profile: ID!) {
+			container {
+				from(address: "`+alpineImage+`") {
+					withSecurityProfile(seccompProfile: $profile) {
+						withExec(args: ["mkdir", "/foo"]) {
+							stdout
+						}
+					}
+				}
+			}
+		}`, &testutil.QueryOptions{
+				Variables: map[string]any{"profile": profileID},
+			})
+		requireErrOut(t, err, "Operation not permitted")
+	})
+
+	t.Run("invalid profile", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		_, err := testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withSecurityProfile(capAdd: ["ALL"]) {
+						withExec(args: ["true"]) {
+							stdout
+						}
+					}
+				}
+			}
+		}`, nil)
+		requireErrOut(t, err, "adding all capabilities requires insecureRootCapabilities")
+	})
+}
+
+func (ContainerSuite) TestEgressPolicy(ctx context.Context, t *testctx.T) {
+	type execResult struct {
+		Container struct {
+			From struct {
+				WithEgressPolicy struct {
+					WithExec struct {
+						Stdout string
+					}
+				}
+			}
+		}
+	}
+	// a blocked TCP connection is reset right away
+	const probe = `nc -z -w 10 1.1.1.1 443 && echo reachable || echo blocked`
+
+	t.Run("deny all", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		res, err := testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withEgressPolicy {
+						withExec(args: ["sh", "-c", "`+probe+`"]) {
+							stdout
+						}
+					}
+				}
+			}
+		}`, nil)
+		require.NoError(t, err)
+		require.Equal(t, "blocked\n", res.Container.From.WithEgressPolicy.WithExec.Stdout)
+	})
+
+	t.Run("allow list", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		res, err := testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withEgressPolicy(allow: ["1.1.1.0/24"]) {
+						withExec(args: ["sh", "-c", "`+probe+`"]) {
+							stdout
+						}
+					}
+				}
+			}
+		}`, nil)
+		require.NoError(t, err)
+		require.Equal(t, "reachable\n", res.Container.From.WithEgressPolicy.WithExec.Stdout)
+	})
+
+	t.Run("allowed domains", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		// the addresses the container resolves the domain to are allowed,
+		// not the ones of other domains
+		const domainProbe = `nc -z -w 10 one.one.one.one 443 && echo reachable || echo blocked; nc -z -w 10 dns.google 443 && echo reachable || echo blocked`
+		res, err := testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withEgressPolicy(allow: ["one.one.one.one"]) {
+						withExec(args: ["sh", "-c", "`+domainProbe+`"]) {
+							stdout
+						}
+					}
+				}
+			}
+		}`, nil)
+		require.NoError(t, err)
+		require.Equal(t, "reachable\nblocked\n", res.Container.From.WithEgressPolicy.WithExec.Stdout)
+	})
+
+	t.Run("services stay reachable", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		svc, _ := httpService(ctx, t, c, "hello")
+		svcID, err := svc.ID(ctx)
+		require.NoError(t, err)
+
+		type serviceResult struct {
+			Container struct {
+				From struct {
+					WithServiceBinding struct {
+						WithEgressPolicy struct {
+							WithExec struct {
+								Stdout string
+							}
+						}
+					}
+				}
+			}
+		}
+		res, err := testutil.QueryWithClient[serviceResult](c, t,
+			`query Test($svc: ID!) {
+			container {
+				from(address: "`+alpineImage+`") {
+					withServiceBinding(alias: "www", service: $svc) {
+						withEgressPolicy {
+							withExec(args: ["wget", "-qO-", "http://www"]) {
+								stdout
+							}
+						}
+					}
+				}
+			}
+		}`, &testutil.QueryOptions{
+				Variables: map[string]any{"svc": svcID},
+			})
+		require.NoError(t, err)
+		require.Equal(t, "hello", res.Container.From.WithServiceBinding.WithEgressPolicy.WithExec.Stdout)
+	})
+
+	t.Run("invalid destination", func(ctx context.Context, t *testctx.T) {
+		c := connect(ctx, t)
+
+		_, err := testutil.QueryWithClient[execResult](c, t,
+			`{
+			container {
+				from(address: "`+alpineImage+`") {
+					withEgressPolicy(allow: ["https://github.com"]) {
+						withExec(args: ["true"]) {
+							stdout
+						}
+					}
+				}
+			}
+		}`, nil)
+		requireErrOut(t, err, "invalid egress")
+	})
+}
+
 func (ContainerSuite) TestEnvExpand(ctx context.Context, t *testctx.T) {
 	c := connect(ctx, t)
 
@@ -96,6 +96,76 @@ func (LLMSuite) TestParallelChangesetToolsMergeResults(ctx context.Context, t *t
 	require.Contains(t, out, "SECOND.txt")
 }
 
+// TestParallelSubagents locks in how LLM.parallel joins the sub-agents it
+// forks and loops: the workspace changes of those that succeeded are merged,
+// each one's reply or failure is reported back to the model, and the tokens
+// they consumed are added to the conversation's usage, within their share of
+// its budget.
+func (LLMSuite) TestParallelSubagents(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+	base := workspaceFixture(t, c, "workspace-tool-return")
+
+	// The recording only serves the first sub-agent: the second one's prompt
+	// diverges from it, so that one fails.
+	model := cannedReplayModel(ctx, t, c, c.LLM().
+		WithPrompt("make the first change").
+		WithResponse([]dagger.LLMContentBlockInput{
+			{Kind: dagger.LLMContentBlockKindToolCall, CallID: "call_1", ToolName: "addFirst"},
+		}, dagger.LLMWithResponseOpts{OutputTokens: 10}).
+		WithToolResult("call_1", "", false).
+		WithResponse([]dagger.LLMContentBlockInput{
+			{Kind: dagger.LLMContentBlockKindText, Text: "first change made"},
+		}, dagger.LLMWithResponseOpts{OutputTokens: 5}))
+
+	joined := fmt.Sprintf(
+		`llm --model="%s" | with-workspace --workspace $(current-workspace) | with-tools $(swapper) | parallel "make the first change" "make the second change"`,
+		model,
+	)
+
+	t.Run("the changes of the sub-agents that succeeded are merged", func(ctx context.Context, t *testctx.T) {
+		out, err := base.With(daggerShell(joined + ` | workspace | directory "/" | entries`)).Stdout(ctx)
+		require.NoError(t, err)
+		require.Contains(t, out, "FIRST.txt")
+		require.NotContains(t, out, "SECOND.txt")
+	})
+
+	t.Run("every sub-agent is reported back", func(ctx context.Context, t *testctx.T) {
+		out, err := base.With(daggerShell(joined + ` | has-pending`)).Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "true", strings.TrimSpace(out))
+
+		out, err = base.With(daggerShell(joined + ` | transcript`)).Stdout(ctx)
+		require.NoError(t, err)
+		require.Contains(t, out, "2 sub-agents ran in parallel")
+		require.Contains(t, out, "> first change made")
+		require.Contains(t, out, "Failed:")
+	})
+
+	t.Run("the sub-agents' token usage is counted", func(ctx context.Context, t *testctx.T) {
+		out, err := base.With(daggerShell(joined + ` | token-usage | output-tokens`)).Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "15", strings.TrimSpace(out))
+	})
+
+	t.Run("the sub-agents go over the budget", func(ctx context.Context, t *testctx.T) {
+		_, err := base.With(daggerShell(fmt.Sprintf(
+			`llm --model="%s" | with-budget --max-output-tokens 12 | with-workspace --workspace $(current-workspace) | with-tools $(swapper) | parallel "make the first change"`,
+			model,
+		))).Sync(ctx)
+		requireErrOut(t, err, "LLM budget exceeded: used 15 of 12 output tokens")
+	})
+
+	t.Run("the sub-agents split the budget", func(ctx context.Context, t *testctx.T) {
+		// each one would consume 15 tokens; 20 only leaves 10 to each, which
+		// stops them after their first step instead of going over together
+		_, err := base.With(daggerShell(fmt.Sprintf(
+			`llm --model="%s" | with-budget --max-output-tokens 20 | with-workspace --workspace $(current-workspace) | with-tools $(swapper) | parallel "make the first change" "make the first change"`,
+			model,
+		))).Sync(ctx)
+		requireErrOut(t, err, "LLM budget exceeded: used 10 of 10 output tokens")
+	})
+}
+
 // TestChangesetToolKeepsEmptyDirectories locks in that a Changeset-returning
 // tool's empty directories survive the engine's patch normalization
 // (core.normalizeChangesetToPatch). Git patches carry file content only, so
@@ -352,6 +352,48 @@ func (LLMSuite) TestStepLimit(ctx context.Context, t *testctx.T) {
 	requireErrOut(t, err, "reached step limit: 1")
 }
 
+// TestRecordReplay records a session to a cassette with DAGGER_LLM_RECORD,
+// then replays it with DAGGER_LLM_REPLAY, with no model configured at all.
+func (LLMSuite) TestRecordReplay(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	model := cannedReplayModel(ctx, t, c, c.LLM().
+		WithPrompt("tell me a joke").
+		WithResponse([]dagger.LLMContentBlockInput{
+			{Kind: dagger.LLMContentBlockKindText, Text: "Why did the container cross the road?"},
+		}))
+
+	recorded := daggerCliBase(t, c).
+		WithEnvVariable("DAGGER_LLM_RECORD", "cassette.json").
+		With(daggerShell(fmt.Sprintf(`llm --model=%q | with-prompt "tell me a joke" | last-reply`, model)))
+	out, err := recorded.Stdout(ctx)
+	require.NoError(t, err)
+	require.Contains(t, out, "Why did the container cross the road?")
+	cassette := recorded.File("cassette.json")
+	contents, err := cassette.Contents(ctx)
+	require.NoError(t, err)
+	require.Contains(t, contents, `"interactions"`)
+
+	replayed := daggerCliBase(t, c).
+		WithFile("cassette.json", cassette).
+		WithEnvVariable("DAGGER_LLM_REPLAY", "cassette.json")
+
+	t.Run("same session", func(ctx context.Context, t *testctx.T) {
+		out, err := replayed.
+			With(daggerShell(`llm | with-prompt "tell me a joke" | last-reply`)).
+			Stdout(ctx)
+		require.NoError(t, err)
+		require.Contains(t, out, "Why did the container cross the road?")
+	})
+
+	t.Run("diverging session", func(ctx context.Context, t *testctx.T) {
+		_, err := replayed.
+			With(daggerShell(`llm | with-prompt "tell me a story" | last-reply`)).
+			Sync(ctx)
+		requireErrOut(t, err, "no recorded request matches the conversation")
+	})
+}
+
 func (LLMSuite) TestAllowLLM(ctx context.Context, t *testctx.T) {
 	c := connect(ctx, t)
 
@@ -1,22 +1,25 @@
 package core
 
 // These tests cover `dagger mcp`, the Model Context Protocol server exposed by
-// the CLI over stdio. Under the object-tools scheme
+// the CLI over stdio or streamable HTTP. Under the object-tools scheme
 // (hack/designs/workspace-agents.md) the CLI binds each workspace module's
 // main object via LLM.withTools, so the module's eligible methods are served
 // directly as MCP tools alongside the builtins (ReadLogs, skills).
 
 import (
 	"context"
+	"net"
 	"os"
 	"os/exec"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dagger/testctx"
 	mcpclient "github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -67,6 +70,58 @@ func (MCPSuite) TestModuleWithPrivilegedStillExposesModuleMethods(ctx context.Co
 	require.Contains(t, callToolText(ctx, t, cli, "greeting", map[string]any{}), "hello from module")
 }
 
+func (MCPSuite) TestListenServesStreamableHTTP(ctx context.Context, t *testctx.T) {
+	modDir := initMCPTestModule(ctx, t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	cmd := hostDaggerCommand(ctx, t, modDir, "--progress=plain", "mcp", "--listen", addr)
+	require.NoError(t, cmd.Start())
+
+	// several clients share the same session
+	for range 2 {
+		cli := startMCPHTTPClient(ctx, t, "http://"+addr+"/mcp")
+		require.Contains(t, listToolNames(ctx, t, cli), "greeting")
+		require.Contains(t, callToolText(ctx, t, cli, "greeting", map[string]any{}), "hello from module")
+	}
+}
+
+func (MCPSuite) TestGettersServedAsResources(ctx context.Context, t *testctx.T) {
+	modDir := initMCPTestModule(ctx, t)
+	cli := startMCPClient(ctx, t, modDir)
+
+	list, err := cli.ListResources(ctx, mcp.ListResourcesRequest{})
+	require.NoError(t, err)
+	uris := make([]string, 0, len(list.Resources))
+	for _, res := range list.Resources {
+		uris = append(uris, res.URI)
+	}
+	require.Contains(t, uris, "dagger://Test/greeting")
+
+	res, err := cli.ReadResource(ctx, mcp.ReadResourceRequest{
+		Params: mcp.ReadResourceParams{URI: "dagger://Test/greeting"},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Contents, 1)
+	require.Equal(t, "hello from module", res.Contents[0].(mcp.TextResourceContents).Text)
+}
+
+func (MCPSuite) TestDocstringsServedAsPrompts(ctx context.Context, t *testctx.T) {
+	modDir := initMCPTestModule(ctx, t)
+	cli := startMCPClient(ctx, t, modDir)
+
+	res, err := cli.GetPrompt(ctx, mcp.GetPromptRequest{
+		Params: mcp.GetPromptParams{Name: "greeting"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Greeting says hello.", res.Description)
+	require.Len(t, res.Messages, 1)
+	require.Contains(t, res.Messages[0].Content.(mcp.TextContent).Text, "Use the greeting tool.")
+}
+
 func initMCPTestModule(ctx context.Context, t testing.TB) string {
 	t.Helper()
 
@@ -119,6 +174,36 @@ func startMCPClient(ctx context.Context, t testing.TB, workdir string, extraArgs
 	return cli
 }
 
+// startMCPHTTPClient connects to a `dagger mcp --listen` server, waiting for
+// it to be up.
+func startMCPHTTPClient(ctx context.Context, t testing.TB, endpoint string) *mcpclient.Client {
+	t.Helper()
+
+	var cli *mcpclient.Client
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		var err error
+		cli, err = mcpclient.NewStreamableHttpClient(endpoint)
+		require.NoError(c, err)
+		require.NoError(c, cli.Start(ctx))
+		_, err = cli.Initialize(ctx, mcp.InitializeRequest{
+			Params: mcp.InitializeParams{
+				ClientInfo: mcp.Implementation{
+					Name:    "dagger-integration-test",
+					Version: "1.0.0",
+				},
+			},
+		})
+		if err != nil {
+			_ = cli.Close()
+		}
+		require.NoError(c, err)
+	}, 5*time.Minute, time.Second)
+	t.Cleanup(func() {
+		_ = cli.Close()
+	})
+	return cli
+}
+
 // listToolNames lists the MCP server's tools via the MCP protocol. Tools are
 // the bound module objects' methods plus builtins — there is no discovery
 // indirection (the old ListMethods/SelectMethods tools are gone).
@@ -2,6 +2,7 @@ package main
 
 type Test struct{}
 
+// Greeting says hello.
 func (m *Test) Greeting() string {
 	return "hello from module"
 }
@@ -114,6 +114,18 @@ type LLM struct {
 	// specify a cap. Zero means no cap. Only set via the legacy
 	// llm(maxAPICalls:) argument, kept for pre-v1 module views.
 	maxSteps int
+
+	// budget, when set, stops the conversation once it has consumed too many
+	// tokens or dollars (see LLMBudget).
+	budget *LLMBudget
+
+	// outputSchema, when set, is the JSON Schema the model's final reply must
+	// match (see WithOutputSchema).
+	outputSchema JSON
+
+	// subagentTokenUsage is what the sub-agents run by Parallel consumed, which
+	// counts towards the conversation's token usage and budget.
+	subagentTokenUsage LLMTokenUsage
 }
 
 func (*LLM) TypeDescription() string {
@@ -172,6 +184,10 @@ type LLMCallOpts struct {
 	// CallDigest is this turn's LLM state digest, set on the live display spans
 	// the provider creates so the TUI can branch the conversation from them.
 	CallDigest string
+
+	// OutputSchema, when set, is the JSON Schema the model's reply must match,
+	// for providers to enforce with their native structured-output support.
+	OutputSchema map[string]any
 }
 
 // LLMResponse is the internal result returned by a provider's SendQuery.
@@ -608,6 +624,22 @@ type LLMRouter struct {
 	// (see LLM.Endpoint) must run through that client's session. Set by
 	// loadLLMRouter; nil when the router was built for a single client.
 	localClient *engine.ClientMetadata
+
+	// NamedProviders are the self-hosted endpoints configured by name, keyed
+	// by name.
+	NamedProviders map[string]*LLMNamedProvider
+
+	// RecordPath is a cassette on the client's host to record every
+	// interaction with a model to (DAGGER_LLM_RECORD).
+	RecordPath string
+	// recordClient is the client whose configuration supplied RecordPath,
+	// on whose host the cassette is written. Set by loadLLMRouter.
+	recordClient *engine.ClientMetadata
+
+	// ReplayPath is a cassette on the client's host to serve every model from
+	// instead of its provider (DAGGER_LLM_REPLAY).
+	ReplayPath string
+	replay     *llmCassette
 }
 
 func (r *LLMRouter) isAnthropicModel(model string) bool {
@@ -718,14 +750,11 @@ func (r *LLMRouter) routeLocalModel() (*LLMEndpoint, error) {
 		Key:      r.LocalAPIKey,
 		Provider: Local,
 	}
-	switch r.LocalAPICompat {
-	case "openai":
-		endpoint.Client = newOpenAIClient(endpoint, "", false)
-	case "anthropic":
-		endpoint.Client = newAnthropicClient(endpoint)
-	default:
-		return nil, fmt.Errorf("unsupported local API compatibility mode: %q (must be %q or %q)", r.LocalAPICompat, "openai", "anthropic")
+	client, err := newCompatClient(endpoint, r.LocalAPICompat)
+	if err != nil {
+		return nil, fmt.Errorf("local provider: %w", err)
 	}
+	endpoint.Client = client
 	return endpoint, nil
 }
 
@@ -753,6 +782,9 @@ func (r *LLMRouter) routeReplayModel(model string) (*LLMEndpoint, error) {
 
 // Return a default model, if configured
 func (r *LLMRouter) DefaultModel() string {
+	if r.replay != nil && len(r.replay.Interactions) > 0 {
+		return r.replay.Interactions[0].Model
+	}
 	if r.OpenAIModel != "" {
 		return r.OpenAIModel
 	}
@@ -770,6 +802,9 @@ func (r *LLMRouter) DefaultModel() string {
 	if r.LocalModel != "" {
 		return r.LocalModel
 	}
+	if model := r.defaultNamedModel(); model != "" {
+		return model
+	}
 	if r.OpenAIAPIKey != "" {
 		return modelDefaultOpenAI
 	}
@@ -805,6 +840,9 @@ func (r *LLMRouter) routeProvider(provider LLMProvider) (*LLMEndpoint, error) {
 	case Other:
 		return r.routeOtherModel(), nil
 	default:
+		if p := r.NamedProviders[string(provider)]; p != nil {
+			return r.routeNamedProvider(p)
+		}
 		return nil, fmt.Errorf("unknown LLM provider %q (expected one of %q, %q, %q, %q, %q, %q)",
 			provider, Anthropic, Google, Local, OpenAI, OpenAICodex, Other)
 	}
@@ -823,11 +861,28 @@ func (r *LLMRouter) Route(model, provider string) (*LLMEndpoint, error) {
 	var endpoint *LLMEndpoint
 	var err error
 	switch {
+	// NB: must come first — a replayed session reaches no provider at all.
+	case r.replay != nil:
+		endpoint = r.routeReplayCassette(model, provider)
+		model = endpoint.Model
 	case provider != "":
 		endpoint, err = r.routeProvider(LLMProvider(provider))
 		if err != nil {
 			return nil, err
 		}
+		// "<name>/<model>" names a named provider's model too
+		if r.NamedProviders[provider] != nil {
+			model = strings.TrimPrefix(model, provider+"/")
+		}
+	// NB: must precede the other matchers — a named provider may serve models
+	// named like any provider's, and "<name>/<model>" is an explicit choice.
+	case r.isNamedProviderModel(model):
+		p, bare, _ := r.namedProviderModel(model)
+		endpoint, err = r.routeNamedProvider(p)
+		if err != nil {
+			return nil, err
+		}
+		model = bare
 	// NB: must precede the prefix-based matchers — a local model may be named to
 	// look like any provider's (e.g. "gpt-oss"), so an exact configured-model
 	// match wins.
@@ -983,6 +1038,13 @@ func (r *LLMRouter) LoadConfig(ctx context.Context, getenv func(context.Context,
 		return save("LOCAL_API_KEY", &r.LocalAPIKey)
 	})
 
+	eg.Go(func() error {
+		return save("DAGGER_LLM_RECORD", &r.RecordPath)
+	})
+	eg.Go(func() error {
+		return save("DAGGER_LLM_REPLAY", &r.ReplayPath)
+	})
+
 	var openAIDisableStreaming string
 	eg.Go(func() error {
 		var err error
@@ -994,6 +1056,10 @@ func (r *LLMRouter) LoadConfig(ctx context.Context, getenv func(context.Context,
 		return false, err
 	}
 
+	if err := r.loadNamedProviders(ctx, getenv); err != nil {
+		return false, err
+	}
+
 	if openAIDisableStreaming != "" {
 		v, err := strconv.ParseBool(openAIDisableStreaming)
 		if err != nil {
@@ -1055,7 +1121,11 @@ func (r *LLMRouter) LoadClientConfig(ctx context.Context, srv *dagql.Server) (su
 			env = e
 		}
 	}
-	return r.LoadConfig(ctx, func(ctx context.Context, k string) (string, error) {
+	// The cassette to replay is on the host of the client that supplies its
+	// path, so it's read right away, through that client.
+	prevReplayPath := r.ReplayPath
+	r.ReplayPath = ""
+	suppliedLocal, err := r.LoadConfig(ctx, func(ctx context.Context, k string) (string, error) {
 		// First lookup in the .env file
 		if v, ok := env[k]; ok {
 			return loadSecret(ctx, v)
@@ -1067,6 +1137,21 @@ func (r *LLMRouter) LoadClientConfig(ctx context.Context, srv *dagql.Server) (su
 		}
 		return "", nil
 	})
+	if err != nil {
+		return false, err
+	}
+	if r.ReplayPath == "" {
+		r.ReplayPath = prevReplayPath
+	} else {
+		data, err := loadSecret(ctx, "file://"+r.ReplayPath)
+		if err != nil {
+			return false, fmt.Errorf("read LLM cassette %s: %w", r.ReplayPath, err)
+		}
+		if r.replay, err = decodeLLMCassette([]byte(data)); err != nil {
+			return false, err
+		}
+	}
+	return suppliedLocal, nil
 }
 
 func NewLLMRouter(ctx context.Context, srv *dagql.Server) (_ *LLMRouter, rerr error) {
@@ -1123,6 +1208,7 @@ func loadLLMRouter(ctx context.Context, query *Query) (_ *LLMRouter, rerr error)
 		if err != nil {
 			return err
 		}
+		recordPath := router.RecordPath
 		suppliedLocal, err := router.LoadClientConfig(clientCtx, srv)
 		if err != nil {
 			return err
@@ -1135,6 +1221,16 @@ func loadLLMRouter(ctx context.Context, query *Query) (_ *LLMRouter, rerr error)
 		if suppliedLocal {
 			router.localClient = client
 		}
+		// Likewise for the named providers this load supplied, and the
+		// cassette to record to.
+		for _, p := range router.NamedProviders {
+			if p.client == nil {
+				p.client = client
+			}
+		}
+		if router.RecordPath != recordPath {
+			router.recordClient = client
+		}
 		return nil
 	}
 	mainClient, err := query.MainClientCallerMetadata(ctx)
@@ -1284,16 +1380,15 @@ func (llm *LLM) Endpoint(ctx context.Context) (*LLMEndpoint, error) {
 		return nil, fmt.Errorf("no valid LLM endpoint configuration")
 	}
 
-	// A local endpoint is reachable from the client's host, not necessarily the
-	// engine (which may run in a container or on another host). Tunnel its
-	// traffic through the client's session, then rebuild the client so it
-	// dials through the tunnel.
-	if endpoint.Provider == Local {
-		// Tunnel through the session of the client that configured the local
+	// A local or named endpoint is reachable from the client's host, not
+	// necessarily the engine (which may run in a container or on another
+	// host). Tunnel its traffic through the client's session, then rebuild the
+	// client so it dials through the tunnel.
+	if tunnelClient, compat, ok := router.tunnel(endpoint.Provider); ok && router.replay == nil {
+		// Tunnel through the session of the client that configured the
 		// endpoint (the base URL is reachable from *its* host) — the calling
 		// client by default, the session's main client when the config was
 		// inherited from it.
-		tunnelClient := router.localClient
 		if tunnelClient == nil {
 			tunnelClient, err = query.NonModuleParentClientMetadata(ctx)
 			if err != nil {
@@ -1310,11 +1405,23 @@ func (llm *LLM) Endpoint(ctx context.Context) (*LLMEndpoint, error) {
 		if err := setupLocalTunnel(tunnelCtx, endpoint); err != nil {
 			return nil, fmt.Errorf("setup local LLM tunnel: %w", err)
 		}
-		switch router.LocalAPICompat {
-		case "openai":
-			endpoint.Client = newOpenAIClient(endpoint, "", false)
-		case "anthropic":
-			endpoint.Client = newAnthropicClient(endpoint)
+		endpoint.Client, err = newCompatClient(endpoint, compat)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if router.RecordPath != "" && router.replay == nil {
+		recordClient := router.recordClient
+		if recordClient == nil {
+			recordClient, err = query.NonModuleParentClientMetadata(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("record LLM: parent client metadata: %w", err)
+			}
+		}
+		endpoint.Client, err = newLLMCassetteRecorder(ctx, query, recordClient, router.RecordPath, endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("record LLM: %w", err)
 		}
 	}
 
@@ -1505,12 +1612,9 @@ func (llm *LLM) WithoutDefaultSystemPrompt() *LLM {
 }
 
 // Add an external MCP server to the LLM
-func (llm *LLM) WithMCPServer(name string, svc dagql.ObjectResult[*Service]) *LLM {
+func (llm *LLM) WithMCPServer(srv *MCPServerConfig) *LLM {
 	llm = llm.Clone()
-	llm.mcp = llm.mcp.WithMCPServer(&MCPServerConfig{
-		Name:    name,
-		Service: svc,
-	})
+	llm.mcp = llm.mcp.WithMCPServer(srv)
 	return llm
 }
 
@@ -1584,6 +1688,10 @@ func (llm *LLM) Step(ctx context.Context, inst dagql.ObjectResult[*LLM], maxToke
 	if err := llm.allowed(ctx); err != nil {
 		return inst, err
 	}
+	maxTokens, err := llm.budgetMaxTokens(ctx, maxTokens)
+	if err != nil {
+		return inst, err
+	}
 	return llm.step(ctx, inst, maxTokens)
 }
 
@@ -1637,6 +1745,26 @@ func (llm *LLM) step(ctx context.Context, inst dagql.ObjectResult[*LLM], maxToke
 		return inst, err
 	}
 	sels := []dagql.Selector{responseSel}
+	// Ask the model to fix a final reply that doesn't match the output schema,
+	// which leaves the turn pending for the loop to carry on.
+	retryPrompt, err := llm.checkOutput(res)
+	if err != nil {
+		for _, s := range res.DisplaySpans {
+			s.End()
+		}
+		return inst, err
+	}
+	if retryPrompt != "" {
+		sels = append(sels, dagql.Selector{
+			Field: "withPrompt",
+			Args: []dagql.NamedInput{
+				{
+					Name:  "prompt",
+					Value: dagql.NewString(retryPrompt),
+				},
+			},
+		})
+	}
 	// Extract tool calls from response content blocks for the MCP layer.
 	var toolCalls []*LLMToolCall
 	for _, block := range res.Content {
@@ -1778,8 +1906,9 @@ func (llm *LLM) sendQueryWithRetry(ctx context.Context, messages []*LLMMessage,
 		// so the TUI can branch from a span, and ends them (or the loop does for
 		// text/thinking spans, once tool results are applied).
 		res, sendErr = client.SendQuery(ctx, messages, tools, &LLMCallOpts{
-			MaxTokens:  maxTokens,
-			CallDigest: llmCallDigest,
+			MaxTokens:    maxTokens,
+			CallDigest:   llmCallDigest,
+			OutputSchema: llm.outputSchemaMap(),
 		})
 		if sendErr != nil {
 			var finished *ModelFinishedError
@@ -2331,18 +2460,72 @@ func (llm *LLM) recipeSelectors(ctx context.Context) ([]dagql.Selector, error) {
 		sels = append(sels, dagql.Selector{Field: "withoutDefaultSystemPrompt"})
 	}
 
+	if budget := llm.budget; budget != nil {
+		sels = append(sels, dagql.Selector{
+			Field: "withBudget",
+			Args: []dagql.NamedInput{
+				{Name: "maxInputTokens", Value: dagql.Opt(dagql.NewInt(budget.MaxInputTokens))},
+				{Name: "maxOutputTokens", Value: dagql.Opt(dagql.NewInt(budget.MaxOutputTokens))},
+				{Name: "maxCostUSD", Value: dagql.Opt(dagql.NewFloat(budget.MaxCostUSD))},
+			},
+		})
+	}
+
+	for _, policy := range llm.mcp.toolPolicies {
+		sels = append(sels, dagql.Selector{
+			Field: "withToolPolicy",
+			Args: []dagql.NamedInput{
+				{Name: "tool", Value: dagql.NewString(policy.Tool)},
+				{Name: "action", Value: dagql.NewString(policy.Action)},
+				{Name: "readOnly", Value: dagql.NewBoolean(policy.ReadOnly)},
+			},
+		})
+	}
+
+	if llm.outputSchema != nil {
+		sels = append(sels, dagql.Selector{
+			Field: "withOutputSchema",
+			Args: []dagql.NamedInput{
+				{Name: "schema", Value: dagql.Opt(llm.outputSchema)},
+			},
+		})
+	}
+
+	if llm.subagentTokenUsage.hasTokens() {
+		sels = append(sels, subagentTokenUsageSelector(llm.subagentTokenUsage))
+	}
+
 	for _, name := range slices.Sorted(maps.Keys(llm.mcp.mcpServers)) {
 		cfg := llm.mcp.mcpServers[name]
-		svcID, err := cfg.Service.ID()
-		if err != nil {
-			return nil, fmt.Errorf("mcp server %q service ID: %w", name, err)
+		args := []dagql.NamedInput{
+			{Name: "name", Value: dagql.NewString(name)},
+		}
+		if cfg.Service.Self() != nil {
+			svcID, err := cfg.Service.ID()
+			if err != nil {
+				return nil, fmt.Errorf("mcp server %q service ID: %w", name, err)
+			}
+			args = append(args, dagql.NamedInput{Name: "service", Value: dagql.NewID[*Service](svcID)})
+		}
+		if cfg.Port != 0 {
+			args = append(args,
+				dagql.NamedInput{Name: "port", Value: dagql.Opt(dagql.NewInt(cfg.Port))},
+				dagql.NamedInput{Name: "path", Value: dagql.NewString(cfg.Path)},
+			)
+		}
+		if cfg.URL != "" {
+			args = append(args, dagql.NamedInput{Name: "url", Value: dagql.Opt(dagql.NewString(cfg.URL))})
+		}
+		if cfg.AuthHeader.Self() != nil {
+			headerID, err := cfg.AuthHeader.ID()
+			if err != nil {
+				return nil, fmt.Errorf("mcp server %q auth header ID: %w", name, err)
+			}
+			args = append(args, dagql.NamedInput{Name: "httpAuthHeader", Value: dagql.Opt(dagql.NewID[*Secret](headerID))})
 		}
 		sels = append(sels, dagql.Selector{
 			Field: "withMCPServer",
-			Args: []dagql.NamedInput{
-				{Name: "name", Value: dagql.NewString(name)},
-				{Name: "service", Value: dagql.NewID[*Service](svcID)},
-			},
+			Args:  args,
 		})
 	}
 
@@ -2510,7 +2693,7 @@ func (*LLMVariable) DecodePersistedObject(ctx context.Context, dag *dagql.Server
 }
 
 func (llm *LLM) TokenUsage(ctx context.Context, dag *dagql.Server) (*LLMTokenUsage, error) {
-	var res LLMTokenUsage
+	res := llm.subagentTokenUsage
 	for _, msg := range llm.Messages {
 		if msg.TokenUsage == nil {
 			continue
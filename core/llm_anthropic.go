@@ -289,6 +289,13 @@ func (c *AnthropicClient) SendQuery(ctx context.Context, history []*LLMMessage,
 		}
 	}
 
+	// Constrain the reply to the output schema, if any.
+	if opts != nil && opts.OutputSchema != nil {
+		outputConfig.Format = anthropic.JSONOutputFormatParam{
+			Schema: opts.OutputSchema,
+		}
+	}
+
 	// Cap max_tokens to the context window's remaining space; the API rejects
 	// requests whose input tokens + max_tokens exceed it.
 	maxTokens = clampMaxTokensToContext(maxTokens, c.endpoint.ContextWindow, history, tools)
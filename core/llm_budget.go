@@ -0,0 +1,183 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dagger/dagger/core/modelcatalog"
+	"github.com/dagger/dagger/dagql"
+)
+
+// LLMBudget caps what a conversation may consume over all of its API calls,
+// as reported by LLM.tokenUsage. Zero leaves a dimension unlimited.
+type LLMBudget struct {
+	// MaxInputTokens caps the input tokens, cached or not.
+	MaxInputTokens int64
+	// MaxOutputTokens caps the output tokens.
+	MaxOutputTokens int64
+	// MaxCostUSD caps the cost of the API calls in US dollars, priced from
+	// the model catalog at the rates of the current model.
+	MaxCostUSD float64
+}
+
+func (budget LLMBudget) isZero() bool {
+	return budget == LLMBudget{}
+}
+
+// WithBudget sets the conversation's budget. A zero budget removes it.
+func (llm *LLM) WithBudget(budget LLMBudget) *LLM {
+	llm = llm.Clone()
+	if budget.isZero() {
+		llm.budget = nil
+	} else {
+		llm.budget = &budget
+	}
+	return llm
+}
+
+// LLMBudgetExceededError is returned when a step is attempted after the
+// conversation ran out of budget. No API call is made for the step.
+//
+// It supports GraphQL extension serialization via Extensions().
+type LLMBudgetExceededError struct {
+	Budget LLMBudget
+	// Limit is the exhausted dimension: "input tokens", "output tokens" or
+	// "cost".
+	Limit        string
+	InputTokens  int64
+	OutputTokens int64
+	CostUSD      float64
+	// Transcript is the conversation as of the last step that fit in the
+	// budget.
+	Transcript string
+}
+
+func (e *LLMBudgetExceededError) Error() string {
+	switch e.Limit {
+	case "cost":
+		return fmt.Sprintf("LLM budget exceeded: spent $%.4f of $%.4f", e.CostUSD, e.Budget.MaxCostUSD)
+	case "input tokens":
+		return fmt.Sprintf("LLM budget exceeded: used %d of %d input tokens", e.InputTokens, e.Budget.MaxInputTokens)
+	default:
+		return fmt.Sprintf("LLM budget exceeded: used %d of %d output tokens", e.OutputTokens, e.Budget.MaxOutputTokens)
+	}
+}
+
+var _ dagql.ExtendedError = (*LLMBudgetExceededError)(nil)
+
+func (e *LLMBudgetExceededError) Extensions() map[string]any {
+	return map[string]any{
+		"_type":        "LLM_BUDGET_EXCEEDED",
+		"limit":        e.Limit,
+		"inputTokens":  e.InputTokens,
+		"outputTokens": e.OutputTokens,
+		"costUSD":      e.CostUSD,
+		"transcript":   e.Transcript,
+	}
+}
+
+// budgetMaxTokens enforces the conversation's budget before a step: it fails
+// with an LLMBudgetExceededError once any limit is reached, and otherwise caps
+// the step's output tokens to what's left of the output token budget. A
+// single call can still go over the input token or cost limits, which can't be
+// known until the model replies, but the loop stops right after it.
+func (llm *LLM) budgetMaxTokens(ctx context.Context, maxTokens int) (int, error) {
+	budget := llm.budget
+	if budget == nil {
+		return maxTokens, nil
+	}
+	spent, err := llm.budgetSpent(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var limit string
+	switch {
+	case budget.MaxCostUSD > 0 && spent.costUSD >= budget.MaxCostUSD:
+		limit = "cost"
+	case budget.MaxInputTokens > 0 && spent.inputTokens >= budget.MaxInputTokens:
+		limit = "input tokens"
+	case budget.MaxOutputTokens > 0 && spent.outputTokens >= budget.MaxOutputTokens:
+		limit = "output tokens"
+	}
+	if limit != "" {
+		return 0, &LLMBudgetExceededError{
+			Budget:       *budget,
+			Limit:        limit,
+			InputTokens:  spent.inputTokens,
+			OutputTokens: spent.outputTokens,
+			CostUSD:      spent.costUSD,
+			Transcript:   strings.TrimSpace(llm.Transcript()),
+		}
+	}
+
+	if budget.MaxOutputTokens > 0 {
+		remaining := int(budget.MaxOutputTokens - spent.outputTokens)
+		if maxTokens <= 0 || maxTokens > remaining {
+			maxTokens = remaining
+		}
+	}
+	return maxTokens, nil
+}
+
+// llmBudgetSpent is what a conversation consumed, in the dimensions of its
+// budget.
+type llmBudgetSpent struct {
+	inputTokens  int64
+	outputTokens int64
+	costUSD      float64
+}
+
+// budgetSpent measures what the conversation consumed against its budget,
+// which must be set. The cost is only priced if the budget caps it.
+func (llm *LLM) budgetSpent(ctx context.Context) (llmBudgetSpent, error) {
+	usage, err := llm.TokenUsage(ctx, nil)
+	if err != nil {
+		return llmBudgetSpent{}, err
+	}
+	spent := llmBudgetSpent{
+		inputTokens:  usage.InputTokens + usage.CachedTokenReads + usage.CachedTokenWrites,
+		outputTokens: usage.OutputTokens,
+	}
+	if llm.budget.MaxCostUSD > 0 {
+		ep, err := llm.Endpoint(ctx)
+		if err != nil {
+			return llmBudgetSpent{}, err
+		}
+		if _, ok := lookupCatalogModel(ep.Provider, ep.Model); !ok {
+			return llmBudgetSpent{}, fmt.Errorf("cannot enforce a cost budget: no pricing known for model %q", ep.Model)
+		}
+		spent.costUSD = modelcatalog.Cost(string(ep.Provider), ep.Model,
+			usage.InputTokens, usage.OutputTokens, usage.CachedTokenReads, usage.CachedTokenWrites)
+	}
+	return spent, nil
+}
+
+// subagentBudget splits what's left of the conversation's budget evenly
+// between n sub-agents forked from it, so that together they can't consume
+// more than it: up to a step each over, as for a single conversation. Each
+// share is on top of what the conversation consumed before the fork, which
+// the sub-agents inherit. It returns nil if the conversation has no budget.
+func (llm *LLM) subagentBudget(ctx context.Context, n int) (*LLMBudget, error) {
+	budget := llm.budget
+	if budget == nil {
+		return nil, nil
+	}
+	spent, err := llm.budgetSpent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// a share can't be zero, which would leave the dimension unlimited
+	share := *budget
+	if budget.MaxInputTokens > 0 {
+		share.MaxInputTokens = max(1, spent.inputTokens+(budget.MaxInputTokens-spent.inputTokens)/int64(n))
+	}
+	if budget.MaxOutputTokens > 0 {
+		share.MaxOutputTokens = max(1, spent.outputTokens+(budget.MaxOutputTokens-spent.outputTokens)/int64(n))
+	}
+	if budget.MaxCostUSD > 0 {
+		share.MaxCostUSD = spent.costUSD + (budget.MaxCostUSD-spent.costUSD)/float64(n)
+	}
+	return &share, nil
+}
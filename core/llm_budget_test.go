@@ -0,0 +1,105 @@
+package core
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func budgetTestLLM(usage LLMTokenUsage) *LLM {
+	return &LLM{
+		Messages: []*LLMMessage{
+			{Role: LLMMessageRoleUser, Content: []*LLMContentBlock{{Kind: LLMContentText, Text: "hello"}}},
+			{
+				Role:       LLMMessageRoleAssistant,
+				Content:    []*LLMContentBlock{{Kind: LLMContentText, Text: "world"}},
+				TokenUsage: &usage,
+			},
+			{Role: LLMMessageRoleUser, Content: []*LLMContentBlock{{Kind: LLMContentText, Text: "again"}}},
+		},
+		endpoint: &LLMEndpoint{
+			Provider: "anthropic",
+			Model:    "claude-sonnet-4-5",
+		},
+		endpointMtx: &sync.Mutex{},
+	}
+}
+
+func TestLLMBudgetCapsOutputTokens(t *testing.T) {
+	llm := budgetTestLLM(LLMTokenUsage{InputTokens: 100, OutputTokens: 300})
+
+	maxTokens, err := llm.budgetMaxTokens(t.Context(), 0)
+	require.NoError(t, err)
+	require.Zero(t, maxTokens)
+
+	llm.budget = &LLMBudget{MaxOutputTokens: 1000}
+	maxTokens, err = llm.budgetMaxTokens(t.Context(), 0)
+	require.NoError(t, err)
+	require.Equal(t, 700, maxTokens)
+	maxTokens, err = llm.budgetMaxTokens(t.Context(), 500)
+	require.NoError(t, err)
+	require.Equal(t, 500, maxTokens)
+}
+
+func TestLLMBudgetExceeded(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		budget LLMBudget
+		limit  string
+	}{
+		{"input tokens", LLMBudget{MaxInputTokens: 1000}, "input tokens"},
+		{"output tokens", LLMBudget{MaxOutputTokens: 300}, "output tokens"},
+		{"cost", LLMBudget{MaxCostUSD: 0.005}, "cost"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// cached input counts against the input tokens
+			llm := budgetTestLLM(LLMTokenUsage{InputTokens: 200, CachedTokenReads: 800, OutputTokens: 300})
+			llm.budget = &tc.budget
+
+			_, err := llm.budgetMaxTokens(t.Context(), 0)
+			var budgetErr *LLMBudgetExceededError
+			require.ErrorAs(t, err, &budgetErr)
+			require.Equal(t, tc.limit, budgetErr.Limit)
+			require.Equal(t, int64(1000), budgetErr.InputTokens)
+			require.Contains(t, budgetErr.Transcript, "[User]: again")
+		})
+	}
+}
+
+func TestLLMBudgetUnpricedModel(t *testing.T) {
+	llm := budgetTestLLM(LLMTokenUsage{InputTokens: 1})
+	llm.endpoint = &LLMEndpoint{Provider: "local", Model: "qwen3"}
+	llm.budget = &LLMBudget{MaxCostUSD: 1}
+
+	_, err := llm.budgetMaxTokens(t.Context(), 0)
+	require.ErrorContains(t, err, "no pricing known")
+}
+
+func TestLLMSubagentBudget(t *testing.T) {
+	llm := budgetTestLLM(LLMTokenUsage{InputTokens: 100, OutputTokens: 300})
+	share, err := llm.subagentBudget(t.Context(), 3)
+	require.NoError(t, err)
+	require.Nil(t, share)
+
+	llm.budget = &LLMBudget{MaxInputTokens: 400, MaxOutputTokens: 1000}
+	share, err = llm.subagentBudget(t.Context(), 3)
+	require.NoError(t, err)
+	require.Equal(t, &LLMBudget{MaxInputTokens: 200, MaxOutputTokens: 533}, share)
+
+	// a sub-agent is stopped once it consumed its share, even though the
+	// conversation's budget isn't exhausted yet
+	fork := budgetTestLLM(LLMTokenUsage{InputTokens: 100, OutputTokens: 533})
+	fork.budget = share
+	_, err = fork.budgetMaxTokens(t.Context(), 0)
+	var budgetErr *LLMBudgetExceededError
+	require.ErrorAs(t, err, &budgetErr)
+	require.Equal(t, "output tokens", budgetErr.Limit)
+
+	// a share is never zero, which would lift the limit
+	llm = budgetTestLLM(LLMTokenUsage{})
+	llm.budget = &LLMBudget{MaxOutputTokens: 2}
+	share, err = llm.subagentBudget(t.Context(), 3)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), share.MaxOutputTokens)
+}
@@ -481,6 +481,11 @@ func (c *GenaiClient) SendQuery(ctx context.Context, history []*LLMMessage, tool
 	if opts != nil && opts.MaxTokens > 0 {
 		config.MaxOutputTokens = int32(opts.MaxTokens)
 	}
+	// Constrain the reply to the output schema, if any.
+	if opts != nil && opts.OutputSchema != nil {
+		config.ResponseMIMEType = "application/json"
+		config.ResponseJsonSchema = opts.OutputSchema
+	}
 	chat, err := c.client.Chats.Create(ctx, c.endpoint.Model, config, chatHistoryForGenai)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create chat: %w", err)
@@ -163,6 +163,20 @@ func (c *OpenAIClient) SendQuery(ctx context.Context, history []*LLMMessage, too
 		}
 	}
 
+	// Constrain the reply to the output schema, if any. Strict mode only
+	// supports a subset of JSON Schema, so the schema is left to guide the
+	// model and the reply is validated after the fact.
+	if opts != nil && opts.OutputSchema != nil {
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{
+				JSONSchema: openai.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   "output",
+					Schema: opts.OutputSchema,
+				},
+			},
+		}
+	}
+
 	if len(tools) > 0 {
 		var toolParams []openai.ChatCompletionToolParam
 		for _, tool := range tools {
@@ -133,6 +133,18 @@ func (c *OpenAICodexClient) SendQuery(ctx context.Context, history []*LLMMessage
 		params.ParallelToolCalls = param.NewOpt(true)
 	}
 
+	// Constrain the reply to the output schema, if any.
+	if opts != nil && opts.OutputSchema != nil {
+		params.Text = responses.ResponseTextConfigParam{
+			Format: responses.ResponseFormatTextConfigUnionParam{
+				OfJSONSchema: &responses.ResponseFormatTextJSONSchemaConfigParam{
+					Name:   "output",
+					Schema: opts.OutputSchema,
+				},
+			},
+		}
+	}
+
 	// Configure reasoning effort if specified
 	if effort := c.endpoint.ReasoningEffort; effort != "" && effort != "none" {
 		params.Reasoning = shared.ReasoningParam{
@@ -0,0 +1,284 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+
+	"github.com/dagger/dagger/dagql"
+)
+
+// An LLM can be asked to reply with a value matching a JSON Schema, given
+// directly or derived from a TypeDef. The schema is handed to each provider's
+// native structured-output support (OpenAI response_format, Anthropic
+// output_config.format, Gemini responseJsonSchema), and the final reply is
+// validated against it regardless: a reply that doesn't match is answered with
+// a prompt describing the mismatch, and the loop carries on, up to
+// llmOutputMaxRetries times.
+
+// llmOutputMaxRetries caps how many times in a row the model is asked to fix
+// a reply that doesn't match the output schema.
+const llmOutputMaxRetries = 3
+
+// llmOutputRetryPrefix starts the prompts asking the model to fix its reply,
+// by which they are counted.
+const llmOutputRetryPrefix = "Your reply does not match the required output schema: "
+
+// WithOutputSchema requires the model's final reply to be a JSON value
+// matching the given JSON Schema. An empty schema removes the requirement.
+func (llm *LLM) WithOutputSchema(schema JSON) (*LLM, error) {
+	llm = llm.Clone()
+	if len(schema) == 0 {
+		llm.outputSchema = nil
+		return llm, nil
+	}
+	if _, err := resolveOutputSchema(schema); err != nil {
+		return nil, err
+	}
+	llm.outputSchema = schema
+	return llm, nil
+}
+
+func resolveOutputSchema(schema JSON) (*jsonschema.Resolved, error) {
+	var s jsonschema.Schema
+	if err := json.Unmarshal(schema.Bytes(), &s); err != nil {
+		return nil, fmt.Errorf("invalid output schema: %w", err)
+	}
+	resolved, err := s.Resolve(nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output schema: %w", err)
+	}
+	return resolved, nil
+}
+
+// outputSchemaMap returns the output schema as sent to providers, or nil if
+// none is set.
+func (llm *LLM) outputSchemaMap() map[string]any {
+	if llm.outputSchema == nil {
+		return nil
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(llm.outputSchema.Bytes(), &schema); err != nil {
+		// validated by WithOutputSchema
+		return nil
+	}
+	return schema
+}
+
+// decodeOutput decodes a reply as JSON and validates it against the output
+// schema. A reply wrapped in a Markdown code fence, as models without native
+// structured output tend to send, is unwrapped first.
+func (llm *LLM) decodeOutput(reply string) ([]byte, error) {
+	resolved, err := resolveOutputSchema(llm.outputSchema)
+	if err != nil {
+		return nil, err
+	}
+	data := []byte(unfenceJSON(reply))
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("not valid JSON: %w", err)
+	}
+	if err := resolved.Validate(value); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func unfenceJSON(reply string) string {
+	reply = strings.TrimSpace(reply)
+	if body, ok := strings.CutPrefix(reply, "```"); ok {
+		if body, ok = strings.CutSuffix(body, "```"); ok {
+			body = strings.TrimPrefix(body, "json")
+			return strings.TrimSpace(body)
+		}
+	}
+	return reply
+}
+
+// Output returns the model's last reply as a JSON value matching the output
+// schema.
+func (llm *LLM) Output() (*JSONValue, error) {
+	if llm.outputSchema == nil {
+		return nil, errors.New("no output schema set; use withOutputSchema")
+	}
+	reply, ok := llm.LastReply()
+	if !ok {
+		return nil, errors.New("no reply from the model yet")
+	}
+	data, err := llm.decodeOutput(reply)
+	if err != nil {
+		return nil, fmt.Errorf("last reply does not match the output schema: %w", err)
+	}
+	return &JSONValue{Data: data}, nil
+}
+
+// checkOutput validates a final reply against the output schema. It returns
+// the prompt asking the model to fix a mismatch, or an LLMOutputMismatchError
+// once the model was asked too many times in a row.
+func (llm *LLM) checkOutput(res *LLMResponse) (string, error) {
+	if llm.outputSchema == nil {
+		return "", nil
+	}
+	for _, block := range res.Content {
+		if block.Kind == LLMContentToolCall {
+			// not done yet
+			return "", nil
+		}
+	}
+	reply := res.TextContent()
+	_, err := llm.decodeOutput(reply)
+	if err == nil {
+		return "", nil
+	}
+	if llm.outputRetries() >= llmOutputMaxRetries {
+		return "", &LLMOutputMismatchError{
+			Reply:    reply,
+			Mismatch: err.Error(),
+			Retries:  llmOutputMaxRetries,
+		}
+	}
+	return fmt.Sprintf("%s%s\n\nReply again with only a JSON value matching this schema:\n%s",
+		llmOutputRetryPrefix, err, llm.outputSchema), nil
+}
+
+// outputRetries counts the prompts asking the model to fix its reply sent
+// since the last prompt of the user.
+func (llm *LLM) outputRetries() int {
+	var retries int
+	for _, msg := range llm.Messages {
+		if msg.Role != LLMMessageRoleUser || msg.IsToolResult() {
+			continue
+		}
+		if strings.HasPrefix(msg.TextContent(), llmOutputRetryPrefix) {
+			retries++
+		} else {
+			retries = 0
+		}
+	}
+	return retries
+}
+
+// LLMOutputMismatchError is returned when the model keeps replying with a
+// value that doesn't match the output schema.
+//
+// It supports GraphQL extension serialization via Extensions().
+type LLMOutputMismatchError struct {
+	// Reply is the model's last reply.
+	Reply string
+	// Mismatch describes how the reply fails to match the schema.
+	Mismatch string
+	Retries  int
+}
+
+func (e *LLMOutputMismatchError) Error() string {
+	return fmt.Sprintf("LLM reply does not match the output schema after %d retries: %s", e.Retries, e.Mismatch)
+}
+
+var _ dagql.ExtendedError = (*LLMOutputMismatchError)(nil)
+
+func (e *LLMOutputMismatchError) Extensions() map[string]any {
+	return map[string]any{
+		"_type":    "LLM_OUTPUT_MISMATCH",
+		"reply":    e.Reply,
+		"mismatch": e.Mismatch,
+		"retries":  e.Retries,
+	}
+}
+
+// TypeDefJSONSchema returns a JSON Schema describing the values of a type,
+// for the LLM to reply with. Objects are described by their fields, as they
+// would be returned by a function; interfaces and inputs aren't supported.
+//
+// Objects referenced by name only, as created by withObject, are resolved
+// through mod, the module calling, if any.
+func TypeDefJSONSchema(typeDef *TypeDef, mod *Module) (map[string]any, error) {
+	return typeDefJSONSchema(typeDef, mod, map[string]bool{})
+}
+
+func typeDefJSONSchema(typeDef *TypeDef, mod *Module, visiting map[string]bool) (map[string]any, error) {
+	schema := map[string]any{}
+	switch typeDef.Kind {
+	case TypeDefKindString, TypeDefKindScalar:
+		schema["type"] = "string"
+	case TypeDefKindInteger:
+		schema["type"] = "integer"
+	case TypeDefKindFloat:
+		schema["type"] = "number"
+	case TypeDefKindBoolean:
+		schema["type"] = "boolean"
+	case TypeDefKindList:
+		if !typeDef.AsList.Valid {
+			return nil, errors.New("list type has no element type")
+		}
+		items, err := typeDefJSONSchema(typeDef.AsList.Value.Self().ElementTypeDef.Self(), mod, visiting)
+		if err != nil {
+			return nil, fmt.Errorf("list element: %w", err)
+		}
+		schema["type"] = "array"
+		schema["items"] = items
+	case TypeDefKindEnum:
+		if !typeDef.AsEnum.Valid {
+			return nil, errors.New("enum type has no members")
+		}
+		enum := typeDef.AsEnum.Value.Self()
+		var values []string
+		for _, member := range enum.Members {
+			values = append(values, member.Self().Name)
+		}
+		schema["type"] = "string"
+		schema["enum"] = values
+		if enum.Description != "" {
+			schema["description"] = enum.Description
+		}
+	case TypeDefKindObject:
+		if !typeDef.AsObject.Valid {
+			return nil, errors.New("object type has no definition")
+		}
+		obj := typeDef.AsObject.Value.Self()
+		if len(obj.Fields) == 0 && mod != nil {
+			if def, ok := mod.ObjectByName(obj.Name); ok {
+				obj = def
+			} else if def, ok := mod.ObjectByOriginalName(obj.Name); ok {
+				obj = def
+			}
+		}
+		if len(obj.Fields) == 0 {
+			return nil, fmt.Errorf("object %q has no fields to reply with", obj.Name)
+		}
+		if visiting[obj.Name] {
+			return nil, fmt.Errorf("object %q is recursive", obj.Name)
+		}
+		visiting[obj.Name] = true
+		defer delete(visiting, obj.Name)
+		properties := map[string]any{}
+		required := []string{}
+		for _, field := range obj.Fields {
+			field := field.Self()
+			fieldTypeDef := field.TypeDef.Self()
+			fieldSchema, err := typeDefJSONSchema(fieldTypeDef, mod, visiting)
+			if err != nil {
+				return nil, fmt.Errorf("field %q of %q: %w", field.Name, obj.Name, err)
+			}
+			if field.Description != "" {
+				fieldSchema["description"] = field.Description
+			}
+			properties[field.Name] = fieldSchema
+			if !fieldTypeDef.Optional {
+				required = append(required, field.Name)
+			}
+		}
+		schema["type"] = "object"
+		schema["properties"] = properties
+		schema["required"] = required
+		schema["additionalProperties"] = false
+		if obj.Description != "" {
+			schema["description"] = obj.Description
+		}
+	default:
+		return nil, fmt.Errorf("unsupported output type %s", typeDef.Kind)
+	}
+	return schema, nil
+}
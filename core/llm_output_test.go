@@ -0,0 +1,105 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/dagql"
+)
+
+func TestLLMOutputSchema(t *testing.T) {
+	llm := &LLM{mcp: &MCP{}}
+	_, err := llm.WithOutputSchema(JSON(`{"type": 42}`))
+	require.ErrorContains(t, err, "invalid output schema")
+
+	llm, err = llm.WithOutputSchema(JSON(`{
+		"type": "object",
+		"properties": {"name": {"type": "string"}, "stars": {"type": "integer"}},
+		"required": ["name", "stars"]
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, "object", llm.outputSchemaMap()["type"])
+
+	reply := func(text string) *LLMResponse {
+		return &LLMResponse{Content: []*LLMContentBlock{{Kind: LLMContentText, Text: text}}}
+	}
+
+	// a tool call isn't a final reply
+	retry, err := llm.checkOutput(&LLMResponse{Content: []*LLMContentBlock{
+		{Kind: LLMContentToolCall, CallID: "call_1", ToolName: "ls", Arguments: JSON(`{}`)},
+	}})
+	require.NoError(t, err)
+	require.Empty(t, retry)
+
+	retry, err = llm.checkOutput(reply("```json\n{\"name\": \"dagger\", \"stars\": 15000}\n```"))
+	require.NoError(t, err)
+	require.Empty(t, retry)
+
+	// a mismatch is sent back to the model, a few times at most
+	llm = llm.WithPrompt("describe the repo")
+	for range llmOutputMaxRetries {
+		retry, err = llm.checkOutput(reply(`{"name": "dagger"}`))
+		require.NoError(t, err)
+		require.Contains(t, retry, llmOutputRetryPrefix)
+		require.Contains(t, retry, "stars")
+		llm = llm.WithPrompt(retry)
+	}
+	_, err = llm.checkOutput(reply(`{"name": "dagger"}`))
+	var mismatch *LLMOutputMismatchError
+	require.True(t, errors.As(err, &mismatch))
+	require.Equal(t, `{"name": "dagger"}`, mismatch.Reply)
+	require.Equal(t, "LLM_OUTPUT_MISMATCH", mismatch.Extensions()["_type"])
+
+	// a new prompt starts over
+	llm = llm.WithPrompt("describe another repo")
+	retry, err = llm.checkOutput(reply("not JSON"))
+	require.NoError(t, err)
+	require.Contains(t, retry, "not valid JSON")
+
+	llm.Messages = append(llm.Messages, &LLMMessage{
+		Role:    LLMMessageRoleAssistant,
+		Content: []*LLMContentBlock{{Kind: LLMContentText, Text: `{"name": "buildkit", "stars": 9000}`}},
+	})
+	out, err := llm.Output()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name": "buildkit", "stars": 9000}`, string(out.Data))
+}
+
+func TestTypeDefJSONSchema(t *testing.T) {
+	schema, err := TypeDefJSONSchema(&TypeDef{Kind: TypeDefKindInteger}, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"type": "integer"}, schema)
+
+	_, err = TypeDefJSONSchema(&TypeDef{Kind: TypeDefKindInterface}, nil)
+	require.ErrorContains(t, err, "unsupported output type")
+
+	// objects referenced by name are resolved through the calling module
+	dag := newCoreDagqlServerForTest(t, &Query{})
+	installTypeDefTestClasses(dag)
+	stringType := newTypeDefDetachedResult(t, dag, "outputStringType", &TypeDef{Kind: TypeDefKindString})
+	nameField := newTypeDefDetachedResult(t, dag, "outputNameField", NewFieldTypeDef("name", stringType, "", nil))
+	repoDef := NewObjectTypeDef("Repo", "", nil)
+	repoDef.Fields = dagql.ObjectResultArray[*FieldTypeDef]{nameField}
+	repoDefRes := newTypeDefDetachedResult(t, dag, "outputRepoDef", repoDef)
+	mod := &Module{
+		ObjectDefs: dagql.ObjectResultArray[*TypeDef]{
+			newTypeDefDetachedResult(t, dag, "outputRepoTypeDef", (&TypeDef{}).WithObjectTypeDef(repoDefRes)),
+		},
+	}
+	ref := newTypeDefDetachedResult(t, dag, "outputRepoRef", NewObjectTypeDef("Repo", "", nil))
+	refTypeDef := (&TypeDef{}).WithObjectTypeDef(ref)
+
+	schema, err = TypeDefJSONSchema(refTypeDef, mod)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{"name": map[string]any{"type": "string"}},
+		"required":             []string{"name"},
+		"additionalProperties": false,
+	}, schema)
+
+	_, err = TypeDefJSONSchema(refTypeDef, nil)
+	require.ErrorContains(t, err, `object "Repo" has no fields`)
+}
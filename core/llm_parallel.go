@@ -0,0 +1,283 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sourcegraph/conc/pool"
+
+	"github.com/dagger/dagger/dagql"
+)
+
+// maxParallelSubagents caps how many sub-agents of LLM.Parallel loop at once.
+const maxParallelSubagents = 8
+
+// LLMSubagent is the outcome of a sub-agent run by LLM.Parallel.
+type LLMSubagent struct {
+	Prompt string
+	// LLM is the sub-agent's conversation once its loop ended, or as of its
+	// last step if it failed, and Reply its last reply, unless it failed.
+	LLM   dagql.ObjectResult[*LLM]
+	Reply string
+	Err   error
+}
+
+// Parallel forks the conversation into one sub-agent per prompt, loops them
+// concurrently, and joins their results back into the conversation: their
+// replies (or failures) are queued as a single prompt for the model to act
+// on, and the changes each made to the bound workspace are merged into it.
+//
+// Each sub-agent is its own fork, with its own copy of the workspace, and at
+// most maxParallelSubagents loop at once. Their calls run under this one, so
+// their failures show up in its telemetry, and the tokens they consumed,
+// failed or not, are added to its usage. What's left of its budget is split
+// evenly between them, so they can't go over it together. Parallel fails if
+// every sub-agent did, if their workspace changes conflict, or if they went
+// over the budget.
+func (llm *LLM) Parallel(ctx context.Context, inst dagql.ObjectResult[*LLM], prompts []string, maxSteps, maxTokens int) (dagql.ObjectResult[*LLM], error) {
+	if err := llm.allowed(ctx); err != nil {
+		return inst, err
+	}
+	if len(prompts) == 0 {
+		return inst, errors.New("no prompts given")
+	}
+	if llm.HasPending() {
+		return inst, errors.New("cannot fork sub-agents with a pending prompt or tool results; loop first")
+	}
+	if _, err := llm.budgetMaxTokens(ctx, maxTokens); err != nil {
+		return inst, err
+	}
+	srv, err := CurrentDagqlServer(ctx)
+	if err != nil {
+		return inst, err
+	}
+	budget, err := llm.subagentBudget(ctx, len(prompts))
+	if err != nil {
+		return inst, err
+	}
+
+	subagents := make([]*LLMSubagent, len(prompts))
+	subagentsPool := pool.New().WithMaxGoroutines(maxParallelSubagents)
+	for i, prompt := range prompts {
+		subagents[i] = &LLMSubagent{Prompt: prompt}
+		subagentsPool.Go(func() {
+			sub := subagents[i]
+			sels := []dagql.Selector{{
+				Field: "fork",
+				Args: []dagql.NamedInput{
+					{Name: "label", Value: dagql.NewString(fmt.Sprintf("parallel-%d", i+1))},
+				},
+			}}
+			if budget != nil {
+				sels = append(sels, dagql.Selector{
+					Field: "withBudget",
+					Args: []dagql.NamedInput{
+						{Name: "maxInputTokens", Value: dagql.Opt(dagql.NewInt(budget.MaxInputTokens))},
+						{Name: "maxOutputTokens", Value: dagql.Opt(dagql.NewInt(budget.MaxOutputTokens))},
+						{Name: "maxCostUSD", Value: dagql.Opt(dagql.NewFloat(budget.MaxCostUSD))},
+					},
+				})
+			}
+			sels = append(sels, dagql.Selector{
+				Field: "withPrompt",
+				Args: []dagql.NamedInput{
+					{Name: "prompt", Value: dagql.NewString(prompt)},
+				},
+			})
+			var forked dagql.ObjectResult[*LLM]
+			if sub.Err = srv.Select(ctx, inst, &forked, sels...); sub.Err != nil {
+				return
+			}
+			// loop directly rather than through a selector, which would drop
+			// the state of a failed loop along with the tokens it consumed
+			sub.LLM, sub.Err = forked.Self().Loop(ctx, forked, maxSteps, maxTokens)
+			if sub.Err == nil {
+				sub.Reply, _ = sub.LLM.Self().LastReply()
+			}
+		})
+	}
+	subagentsPool.Wait()
+
+	var errs []error
+	for i, sub := range subagents {
+		if sub.Err != nil {
+			errs = append(errs, fmt.Errorf("sub-agent %d: %w", i+1, sub.Err))
+		}
+	}
+	if len(errs) == len(subagents) {
+		return inst, errors.Join(errs...)
+	}
+
+	usage, err := llm.subagentsTokenUsage(ctx, subagents)
+	if err != nil {
+		return inst, err
+	}
+	sels := []dagql.Selector{
+		subagentTokenUsageSelector(usage),
+		{
+			Field: "withPrompt",
+			Args: []dagql.NamedInput{
+				{Name: "prompt", Value: dagql.NewString(subagentsReport(subagents))},
+			},
+		},
+	}
+	wsSel, ok, err := llm.mergeSubagentWorkspaces(ctx, srv, subagents)
+	if err != nil {
+		return inst, err
+	}
+	if ok {
+		sels = append(sels, wsSel)
+	}
+
+	var joined dagql.ObjectResult[*LLM]
+	if err := srv.Select(ctx, inst, &joined, sels...); err != nil {
+		return inst, err
+	}
+	if _, err := joined.Self().budgetMaxTokens(ctx, maxTokens); err != nil {
+		return inst, err
+	}
+	return joined, nil
+}
+
+// subagentsTokenUsage sums the tokens the sub-agents consumed, whether they
+// failed or not, on top of what this conversation had before they forked
+// from it, including what previous sub-agents consumed.
+func (llm *LLM) subagentsTokenUsage(ctx context.Context, subagents []*LLMSubagent) (LLMTokenUsage, error) {
+	base, err := llm.TokenUsage(ctx, nil)
+	if err != nil {
+		return LLMTokenUsage{}, err
+	}
+	usage := llm.subagentTokenUsage
+	for _, sub := range subagents {
+		if sub.LLM.Self() == nil {
+			// failed to fork, before consuming anything
+			continue
+		}
+		subUsage, err := sub.LLM.Self().TokenUsage(ctx, nil)
+		if err != nil {
+			return LLMTokenUsage{}, err
+		}
+		usage.InputTokens += subUsage.InputTokens - base.InputTokens
+		usage.OutputTokens += subUsage.OutputTokens - base.OutputTokens
+		usage.CachedTokenReads += subUsage.CachedTokenReads - base.CachedTokenReads
+		usage.CachedTokenWrites += subUsage.CachedTokenWrites - base.CachedTokenWrites
+		usage.TotalTokens += subUsage.TotalTokens - base.TotalTokens
+	}
+	return usage, nil
+}
+
+// WithSubagentTokenUsage sets what the sub-agents run by Parallel consumed.
+func (llm *LLM) WithSubagentTokenUsage(usage LLMTokenUsage) *LLM {
+	llm = llm.Clone()
+	llm.subagentTokenUsage = usage
+	return llm
+}
+
+func subagentTokenUsageSelector(usage LLMTokenUsage) dagql.Selector {
+	return dagql.Selector{
+		Field: "__withSubagentTokenUsage",
+		Args: []dagql.NamedInput{
+			{Name: "inputTokens", Value: dagql.NewInt(usage.InputTokens)},
+			{Name: "outputTokens", Value: dagql.NewInt(usage.OutputTokens)},
+			{Name: "cachedTokenReads", Value: dagql.NewInt(usage.CachedTokenReads)},
+			{Name: "cachedTokenWrites", Value: dagql.NewInt(usage.CachedTokenWrites)},
+			{Name: "totalTokens", Value: dagql.NewInt(usage.TotalTokens)},
+		},
+	}
+}
+
+// subagentsReport renders the prompt reporting the sub-agents' results back
+// to the model.
+func subagentsReport(subagents []*LLMSubagent) string {
+	var report strings.Builder
+	fmt.Fprintf(&report, "%d sub-agents ran in parallel, each from a fork of this conversation:\n", len(subagents))
+	for i, sub := range subagents {
+		fmt.Fprintf(&report, "\n## Sub-agent %d\n\nPrompt:\n%s\n\n", i+1, mdQuote(sub.Prompt))
+		if sub.Err != nil {
+			fmt.Fprintf(&report, "Failed:\n%s\n", mdQuote(sub.Err.Error()))
+			continue
+		}
+		fmt.Fprintf(&report, "Reply:\n%s\n", mdQuote(sub.Reply))
+	}
+	return report.String()
+}
+
+// mergeSubagentWorkspaces merges the changes the sub-agents made to the
+// bound workspace, returning the selector rebinding the merged workspace.
+// ok is false if there's no workspace bound, or none of them changed it.
+func (llm *LLM) mergeSubagentWorkspaces(ctx context.Context, srv *dagql.Server, subagents []*LLMSubagent) (sel dagql.Selector, ok bool, _ error) {
+	ws := llm.Workspace()
+	if ws.Self() == nil {
+		return sel, false, nil
+	}
+	wsID, err := ws.ID()
+	if err != nil {
+		return sel, false, err
+	}
+
+	var changes []dagql.ObjectResult[*Changeset]
+	for i, sub := range subagents {
+		if sub.Err != nil {
+			continue
+		}
+		subWs := sub.LLM.Self().Workspace()
+		subWsID, err := subWs.ID()
+		if err != nil {
+			return sel, false, err
+		}
+		if stableIDDigest(subWsID) == stableIDDigest(wsID) {
+			continue
+		}
+		// measure the changes from the workspace root, as withChanges applies
+		// them, rather than from the working directory
+		var changeset dagql.ObjectResult[*Changeset]
+		if err := srv.Select(ctx, subWs, &changeset, dagql.Selector{
+			Field: "withWorkdir",
+			Args: []dagql.NamedInput{
+				{Name: "path", Value: dagql.NewString(".")},
+			},
+		}, dagql.Selector{
+			Field: "changes",
+			Args: []dagql.NamedInput{
+				{Name: "from", Value: dagql.Opt(dagql.NewID[*Workspace](wsID))},
+			},
+		}); err != nil {
+			return sel, false, fmt.Errorf("sub-agent %d changes: %w", i+1, err)
+		}
+		changes = append(changes, changeset)
+	}
+	if len(changes) == 0 {
+		return sel, false, nil
+	}
+
+	merged, err := mergeChangesets(ctx, srv, changes)
+	if err != nil {
+		return sel, false, fmt.Errorf("merge sub-agent changes: %w", err)
+	}
+	mergedID, err := merged.ID()
+	if err != nil {
+		return sel, false, err
+	}
+
+	var mergedWs dagql.ObjectResult[*Workspace]
+	if err := srv.Select(ctx, ws, &mergedWs, dagql.Selector{
+		Field: "withChanges",
+		Args: []dagql.NamedInput{
+			{Name: "changes", Value: dagql.NewID[*Changeset](mergedID)},
+		},
+	}); err != nil {
+		return sel, false, fmt.Errorf("apply sub-agent changes: %w", err)
+	}
+	mergedWsID, err := mergedWs.ID()
+	if err != nil {
+		return sel, false, err
+	}
+	return dagql.Selector{
+		Field: "withWorkspace",
+		Args: []dagql.NamedInput{
+			{Name: "workspace", Value: dagql.NewID[*Workspace](mergedWsID)},
+		},
+	}, true, nil
+}
@@ -0,0 +1,34 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubagentsReport(t *testing.T) {
+	report := subagentsReport([]*LLMSubagent{
+		{Prompt: "review main.go", Reply: "LGTM\nship it"},
+		{Prompt: "review go.mod", Err: errors.New("reached step limit: 10")},
+	})
+	require.Equal(t, `2 sub-agents ran in parallel, each from a fork of this conversation:
+
+## Sub-agent 1
+
+Prompt:
+> review main.go
+
+Reply:
+> LGTM
+> ship it
+
+## Sub-agent 2
+
+Prompt:
+> review go.mod
+
+Failed:
+> reached step limit: 10
+`, report)
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/dagger/dagger/engine"
+	"github.com/dagger/dagger/util/llmenv"
+)
+
+// LLMNamedProvider is a self-hosted endpoint configured under a name of its
+// own (e.g. a vLLM or LiteLLM gateway, or Ollama) with `dagger llm
+// add-provider`. Like the local endpoint, it is reached from the client's host
+// through its session. Its models are selected as "<name>/<model>", or by an
+// exact match in Models.
+type LLMNamedProvider struct {
+	Name    string
+	BaseURL string
+	APIKey  string
+	// APICompat selects the wire protocol: "openai", "anthropic" or
+	// "ollama" (Ollama's OpenAI-compatible API).
+	APICompat string
+	// Models are the models served by the provider.
+	Models []string
+	// Model is the provider's default model.
+	Model string
+
+	// client is the client whose configuration supplied the provider, through
+	// whose session its traffic is tunneled. Set by loadLLMRouter.
+	client *engine.ClientMetadata
+}
+
+// ollamaDefaultBaseURL is the OpenAI-compatible API of a stock Ollama install.
+const ollamaDefaultBaseURL = "http://localhost:11434/v1"
+
+// loadNamedProviders loads the providers listed in DAGGER_LLM_PROVIDERS, each
+// replacing any provider of the same name loaded before.
+func (r *LLMRouter) loadNamedProviders(ctx context.Context, getenv func(context.Context, string) (string, error)) error {
+	names, err := getenv(ctx, llmenv.Providers)
+	if err != nil {
+		return fmt.Errorf("get %q: %w", llmenv.Providers, err)
+	}
+	for name := range strings.SplitSeq(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		switch LLMProvider(name) {
+		case OpenAI, OpenAICodex, Anthropic, Google, Meta, Mistral, DeepSeek, Local, Other:
+			return fmt.Errorf("LLM provider %q: the name is reserved for a builtin provider", name)
+		}
+		p := &LLMNamedProvider{Name: name}
+		var models string
+		for setting, dest := range map[string]*string{
+			"BASE_URL":   &p.BaseURL,
+			"API_KEY":    &p.APIKey,
+			"API_COMPAT": &p.APICompat,
+			"MODELS":     &models,
+			"MODEL":      &p.Model,
+		} {
+			key := llmenv.NamedProvider(name, setting)
+			if *dest, err = getenv(ctx, key); err != nil {
+				return fmt.Errorf("get %q: %w", key, err)
+			}
+		}
+		for model := range strings.SplitSeq(models, ",") {
+			if model = strings.TrimSpace(model); model != "" {
+				p.Models = append(p.Models, model)
+			}
+		}
+		if p.APICompat == "" {
+			p.APICompat = "openai"
+		}
+		if p.BaseURL == "" && p.APICompat == "ollama" {
+			p.BaseURL = ollamaDefaultBaseURL
+		}
+		if r.NamedProviders == nil {
+			r.NamedProviders = map[string]*LLMNamedProvider{}
+		}
+		r.NamedProviders[name] = p
+	}
+	return nil
+}
+
+// namedProviderModel finds the named provider serving a model, either given
+// as "<name>/<model>" or listed in a provider's models, and returns the model
+// name as the provider knows it.
+func (r *LLMRouter) namedProviderModel(model string) (*LLMNamedProvider, string, bool) {
+	if name, bare, ok := strings.Cut(model, "/"); ok {
+		if p := r.NamedProviders[name]; p != nil {
+			return p, bare, true
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(r.NamedProviders)) {
+		if p := r.NamedProviders[name]; slices.Contains(p.Models, model) {
+			return p, model, true
+		}
+	}
+	return nil, "", false
+}
+
+func (r *LLMRouter) isNamedProviderModel(model string) bool {
+	_, _, ok := r.namedProviderModel(model)
+	return ok
+}
+
+func (r *LLMRouter) routeNamedProvider(p *LLMNamedProvider) (*LLMEndpoint, error) {
+	endpoint := &LLMEndpoint{
+		BaseURL:  p.BaseURL,
+		Key:      p.APIKey,
+		Provider: LLMProvider(p.Name),
+	}
+	client, err := newCompatClient(endpoint, p.APICompat)
+	if err != nil {
+		return nil, fmt.Errorf("provider %q: %w", p.Name, err)
+	}
+	endpoint.Client = client
+	return endpoint, nil
+}
+
+// defaultNamedModel returns the default model of the first named provider
+// that has one, as "<name>/<model>".
+func (r *LLMRouter) defaultNamedModel() string {
+	for _, name := range slices.Sorted(maps.Keys(r.NamedProviders)) {
+		p := r.NamedProviders[name]
+		model := p.Model
+		if model == "" && len(p.Models) > 0 {
+			model = p.Models[0]
+		}
+		if model != "" {
+			return name + "/" + model
+		}
+	}
+	return ""
+}
+
+// tunnel returns how to reach an endpoint routed to a provider served from a
+// client's host: the client that configured it (nil for the calling client)
+// and the API compatibility mode to rebuild its client with. ok is false for
+// providers the engine reaches directly.
+func (r *LLMRouter) tunnel(provider LLMProvider) (client *engine.ClientMetadata, compat string, ok bool) {
+	if provider == Local {
+		return r.localClient, r.LocalAPICompat, true
+	}
+	if p := r.NamedProviders[string(provider)]; p != nil {
+		return p.client, p.APICompat, true
+	}
+	return nil, "", false
+}
+
+// newCompatClient returns a client speaking the given API compatibility mode
+// of a self-hosted endpoint.
+func newCompatClient(endpoint *LLMEndpoint, compat string) (LLMClient, error) {
+	switch compat {
+	case "openai", "ollama":
+		return newOpenAIClient(endpoint, "", false), nil
+	case "anthropic":
+		return newAnthropicClient(endpoint), nil
+	default:
+		return nil, fmt.Errorf("unsupported API compatibility mode: %q (must be %q, %q or %q)", compat, "openai", "anthropic", "ollama")
+	}
+}
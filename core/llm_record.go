@@ -0,0 +1,302 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/engine"
+	"github.com/dagger/dagger/util/scrub"
+	telemetry "github.com/dagger/otel-go"
+)
+
+// LLM sessions can be recorded to a cassette and replayed from it, the way
+// HTTP cassettes are used in tests. With DAGGER_LLM_RECORD=<path>, every
+// request sent to a model is written to the cassette along with its response,
+// tool calls and results included. With DAGGER_LLM_REPLAY=<path>, every model
+// is served from the cassette instead, without network access or API keys,
+// and a request that matches none of the recorded ones fails.
+
+// llmCassette is the file format of a recording.
+type llmCassette struct {
+	Interactions []llmInteraction `json:"interactions"`
+}
+
+// llmInteraction is a request sent to a model and its response.
+type llmInteraction struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	// Request is the conversation sent to the model, system prompt included.
+	Request  []replayMessage `json:"request"`
+	Response replayMessage   `json:"response"`
+}
+
+func decodeLLMCassette(data []byte) (*llmCassette, error) {
+	var cassette llmCassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("decode LLM cassette: %w", err)
+	}
+	return &cassette, nil
+}
+
+func encodeLLMRequest(history []*LLMMessage) []replayMessage {
+	req := make([]replayMessage, len(history))
+	for i, msg := range history {
+		// token usage is the provider's business, not part of the request
+		req[i] = encodeReplayMessage(msg.Role, msg.Content, nil)
+	}
+	return req
+}
+
+// stabilizeLLMRequest makes a request comparable across runs by scrubbing
+// volatile text, e.g. timestamps and temporary paths in tool results.
+func stabilizeLLMRequest(req []replayMessage) []replayMessage {
+	stable := make([]replayMessage, len(req))
+	for i, msg := range req {
+		msg.Content = append([]replayContentBlock(nil), msg.Content...)
+		for j := range msg.Content {
+			msg.Content[j].Text = scrub.Stabilize(msg.Content[j].Text)
+		}
+		msg.TokenUsage = replayTokenUsage{}
+		stable[i] = msg
+	}
+	return stable
+}
+
+// model returns the model recorded for a model name, which may carry the
+// provider prefix, and its provider.
+func (cassette *llmCassette) model(model string) (string, LLMProvider, bool) {
+	for _, it := range cassette.Interactions {
+		if model == it.Model || model == it.Provider+"/"+it.Model {
+			return it.Model, LLMProvider(it.Provider), true
+		}
+	}
+	return "", "", false
+}
+
+// routeReplayCassette routes any model to the cassette being replayed, as
+// the model and provider it was recorded with.
+func (r *LLMRouter) routeReplayCassette(model, provider string) *LLMEndpoint {
+	endpoint := &LLMEndpoint{
+		Provider: LLMProvider(provider),
+		Model:    model,
+		Client:   &LLMCassetteReplayer{cassette: r.replay},
+	}
+	if recorded, recordedProvider, ok := r.replay.model(model); ok {
+		endpoint.Model = recorded
+		endpoint.Provider = recordedProvider
+	}
+	if endpoint.Provider == "" {
+		endpoint.Provider = Other
+	}
+	return endpoint
+}
+
+// LLMCassetteRecorder sends requests to a model and records them, with their
+// responses, to a cassette.
+type LLMCassetteRecorder struct {
+	client   LLMClient
+	provider LLMProvider
+	model    string
+	writer   *llmCassetteWriter
+}
+
+func (c *LLMCassetteRecorder) IsRetryable(err error) bool {
+	return c.client.IsRetryable(err)
+}
+
+func (c *LLMCassetteRecorder) SendQuery(ctx context.Context, history []*LLMMessage, tools []LLMTool, opts *LLMCallOpts) (*LLMResponse, error) {
+	res, err := c.client.SendQuery(ctx, history, tools, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.writer.record(ctx, llmInteraction{
+		Provider: string(c.provider),
+		Model:    c.model,
+		Request:  encodeLLMRequest(history),
+		Response: encodeReplayMessage(LLMMessageRoleAssistant, res.Content, &res.TokenUsage),
+	}); err != nil {
+		return nil, fmt.Errorf("record LLM interaction: %w", err)
+	}
+	return res, nil
+}
+
+// llmCassetteWriter accumulates the interactions recorded to a cassette
+// during a session, to write the cassette once when the client shuts down.
+type llmCassetteWriter struct {
+	mu       sync.Mutex
+	clientID string
+	cassette llmCassette
+	dirty    bool
+	write    func(context.Context, []byte) error
+}
+
+func (w *llmCassetteWriter) record(_ context.Context, it llmInteraction) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cassette.Interactions = append(w.cassette.Interactions, it)
+	w.dirty = true
+	return nil
+}
+
+// flush writes the cassette, if anything was recorded since it was last
+// written.
+func (w *llmCassetteWriter) flush(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(w.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := w.write(ctx, data); err != nil {
+		return err
+	}
+	w.dirty = false
+	return nil
+}
+
+var (
+	// llmCassetteWriters are the cassettes being recorded, keyed by client
+	// and path, so that all the LLMs of a session record to the same one.
+	llmCassetteWriters   = map[string]*llmCassetteWriter{}
+	llmCassetteWritersMu sync.Mutex
+)
+
+// FlushLLMCassettes writes the cassettes recorded on the host of a client,
+// which is shutting down.
+func FlushLLMCassettes(ctx context.Context, clientID string) error {
+	llmCassetteWritersMu.Lock()
+	var writers []*llmCassetteWriter
+	for key, writer := range llmCassetteWriters {
+		if writer.clientID == clientID {
+			writers = append(writers, writer)
+			delete(llmCassetteWriters, key)
+		}
+	}
+	llmCassetteWritersMu.Unlock()
+
+	var errs error
+	for _, writer := range writers {
+		if err := writer.flush(ctx); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("record LLM cassette: %w", err))
+		}
+	}
+	return errs
+}
+
+// newLLMCassetteRecorder wraps the endpoint's client to record its
+// interactions to a cassette on the host of the given client, which is
+// started anew with the client's session and written when it shuts down.
+func newLLMCassetteRecorder(ctx context.Context, query *Query, client *engine.ClientMetadata, path string, endpoint *LLMEndpoint) (*LLMCassetteRecorder, error) {
+	sessionCtx, err := query.Server.SessionScopedContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := client.ClientID + "\x00" + path
+
+	llmCassetteWritersMu.Lock()
+	defer llmCassetteWritersMu.Unlock()
+	writer, ok := llmCassetteWriters[key]
+	if !ok {
+		writer = &llmCassetteWriter{
+			clientID: client.ClientID,
+			write: func(ctx context.Context, data []byte) (rerr error) {
+				ctx, span := Tracer(ctx).Start(ctx, "record LLM cassette", telemetry.Internal(), telemetry.Encapsulate())
+				defer telemetry.EndWithCause(span, &rerr)
+				clientCtx := engine.ContextWithClientMetadata(ctx, client)
+				srv, err := query.Server.Server(clientCtx)
+				if err != nil {
+					return err
+				}
+				var exported string
+				return srv.Select(clientCtx, srv.Root(), &exported,
+					dagql.Selector{Field: "directory"},
+					dagql.Selector{
+						Field: "withNewFile",
+						Args: []dagql.NamedInput{
+							{Name: "path", Value: dagql.NewString("cassette.json")},
+							{Name: "contents", Value: dagql.NewString(string(data))},
+						},
+					},
+					dagql.Selector{
+						Field: "file",
+						Args:  []dagql.NamedInput{{Name: "path", Value: dagql.NewString("cassette.json")}},
+					},
+					dagql.Selector{
+						Field: "export",
+						Args:  []dagql.NamedInput{{Name: "path", Value: dagql.NewString(path)}},
+					},
+				)
+			},
+		}
+		llmCassetteWriters[key] = writer
+		context.AfterFunc(sessionCtx, func() {
+			llmCassetteWritersMu.Lock()
+			delete(llmCassetteWriters, key)
+			llmCassetteWritersMu.Unlock()
+		})
+	}
+	return &LLMCassetteRecorder{
+		client:   endpoint.Client,
+		provider: endpoint.Provider,
+		model:    endpoint.Model,
+		writer:   writer,
+	}, nil
+}
+
+// LLMCassetteReplayer serves requests from the interactions recorded to a
+// cassette, failing when none was recorded for the conversation.
+type LLMCassetteReplayer struct {
+	cassette *llmCassette
+}
+
+func (*LLMCassetteReplayer) IsRetryable(error) bool {
+	return false
+}
+
+func (c *LLMCassetteReplayer) SendQuery(_ context.Context, history []*LLMMessage, _ []LLMTool, _ *LLMCallOpts) (*LLMResponse, error) {
+	req := stabilizeLLMRequest(encodeLLMRequest(history))
+
+	// find the recorded request the conversation is, or else the one it
+	// shares the longest history with, to report where they diverge
+	var closest []replayMessage
+	diverges := -1
+	for _, it := range c.cassette.Interactions {
+		recorded := stabilizeLLMRequest(it.Request)
+		if cmp.Equal(recorded, req) {
+			msg := it.Response.decode()
+			return &LLMResponse{
+				Content:    msg.Content,
+				TokenUsage: *msg.TokenUsage,
+			}, nil
+		}
+		i := 0
+		for i < len(recorded) && i < len(req) && cmp.Equal(recorded[i], req[i]) {
+			i++
+		}
+		if i > diverges {
+			closest, diverges = recorded, i
+		}
+	}
+	if closest == nil {
+		return nil, fmt.Errorf("LLM replay: no interactions recorded")
+	}
+	var recorded, sent any
+	if diverges < len(closest) {
+		recorded = closest[diverges]
+	}
+	if diverges < len(req) {
+		sent = req[diverges]
+	}
+	return nil, fmt.Errorf("LLM replay: no recorded request matches the conversation, which diverges from the closest one at message %d (-recorded +sent):\n%s",
+		diverges, strings.TrimSpace(cmp.Diff(recorded, sent)))
+}
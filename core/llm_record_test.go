@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLLMCassetteRecordReplay(t *testing.T) {
+	ctx := t.Context()
+	system := &LLMMessage{Role: LLMMessageRoleSystem, Content: []*LLMContentBlock{{Kind: LLMContentText, Text: "You are helpful."}}}
+	prompt := &LLMMessage{Role: LLMMessageRoleUser, Content: []*LLMContentBlock{{Kind: LLMContentText, Text: "list the files"}}}
+	call := &LLMMessage{
+		Role: LLMMessageRoleAssistant,
+		Content: []*LLMContentBlock{
+			{Kind: LLMContentToolCall, CallID: "call_1", ToolName: "ls", Arguments: JSON(`{"path":"."}`)},
+		},
+		TokenUsage: &LLMTokenUsage{InputTokens: 10, OutputTokens: 5},
+	}
+	result := &LLMMessage{Role: LLMMessageRoleUser, Content: []*LLMContentBlock{{Kind: LLMContentToolResult, CallID: "call_1", Text: "go.mod\nmain.go"}}}
+	reply := &LLMMessage{
+		Role:       LLMMessageRoleAssistant,
+		Content:    []*LLMContentBlock{{Kind: LLMContentText, Text: "There are two files."}},
+		TokenUsage: &LLMTokenUsage{InputTokens: 20, OutputTokens: 6},
+	}
+
+	// record a session against a canned model
+	var cassette []byte
+	var writes int
+	writer := &llmCassetteWriter{
+		clientID: "client",
+		write: func(_ context.Context, data []byte) error {
+			cassette = data
+			writes++
+			return nil
+		},
+	}
+	recorder := &LLMCassetteRecorder{
+		client:   newHistoryReplay([]*LLMMessage{prompt, call, result, reply}),
+		provider: Anthropic,
+		model:    "claude-sonnet-4-5",
+		writer:   writer,
+	}
+	_, err := recorder.SendQuery(ctx, []*LLMMessage{system, prompt}, nil, nil)
+	require.NoError(t, err)
+	_, err = recorder.SendQuery(ctx, []*LLMMessage{system, prompt, call, result}, nil, nil)
+	require.NoError(t, err)
+
+	// the cassette is written once, when the client shuts down
+	require.Zero(t, writes)
+	llmCassetteWritersMu.Lock()
+	llmCassetteWriters["client\x00cassette.json"] = writer
+	llmCassetteWritersMu.Unlock()
+	require.NoError(t, FlushLLMCassettes(ctx, "other"))
+	require.Zero(t, writes)
+	require.NoError(t, FlushLLMCassettes(ctx, "client"))
+	require.NoError(t, FlushLLMCassettes(ctx, "client"))
+	require.Equal(t, 1, writes)
+
+	decoded, err := decodeLLMCassette(cassette)
+	require.NoError(t, err)
+	require.Len(t, decoded.Interactions, 2)
+
+	// replay it through the router, without any provider configured
+	r := &LLMRouter{replay: decoded}
+	require.Equal(t, "claude-sonnet-4-5", r.DefaultModel())
+	ep, err := r.Route("", "")
+	require.NoError(t, err)
+	require.Equal(t, Anthropic, ep.Provider)
+	require.Equal(t, "claude-sonnet-4-5", ep.Model)
+
+	res, err := ep.Client.SendQuery(ctx, []*LLMMessage{system, prompt, call, result}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "There are two files.", res.TextContent())
+	require.Equal(t, int64(6), res.TokenUsage.OutputTokens)
+
+	res, err = ep.Client.SendQuery(ctx, []*LLMMessage{system, prompt}, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Content, 1)
+	require.Equal(t, "ls", res.Content[0].ToolName)
+	require.JSONEq(t, `{"path":"."}`, string(res.Content[0].Arguments))
+
+	// a conversation that diverges from the recording fails loudly
+	changed := &LLMMessage{Role: LLMMessageRoleUser, Content: []*LLMContentBlock{{Kind: LLMContentToolResult, CallID: "call_1", Text: "README.md"}}}
+	_, err = ep.Client.SendQuery(ctx, []*LLMMessage{system, prompt, call, changed}, nil, nil)
+	require.ErrorContains(t, err, "diverges from the closest one at message 3")
+	require.ErrorContains(t, err, "README.md")
+	require.False(t, ep.Client.IsRetryable(err))
+}
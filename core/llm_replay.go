@@ -11,25 +11,29 @@ import (
 
 // replayMessage mirrors the JSON shape of a conversation exported with the
 // v1 `messages` field (GraphQL's lowerCamel key spelling), which is the
-// recording format consumed by replay/ models.
+// recording format consumed by replay/ models and LLM cassettes.
 type replayMessage struct {
-	Role    string `json:"role"`
-	Content []struct {
-		Kind      string `json:"kind"`
-		Text      string `json:"text"`
-		CallID    string `json:"callId"`
-		ToolName  string `json:"toolName"`
-		Arguments string `json:"arguments"`
-		Errored   bool   `json:"errored"`
-		Signature string `json:"signature"`
-	} `json:"content"`
-	TokenUsage struct {
-		InputTokens       int64 `json:"inputTokens"`
-		OutputTokens      int64 `json:"outputTokens"`
-		CachedTokenReads  int64 `json:"cachedTokenReads"`
-		CachedTokenWrites int64 `json:"cachedTokenWrites"`
-		TotalTokens       int64 `json:"totalTokens"`
-	} `json:"tokenUsage"`
+	Role       string               `json:"role"`
+	Content    []replayContentBlock `json:"content"`
+	TokenUsage replayTokenUsage     `json:"tokenUsage"`
+}
+
+type replayContentBlock struct {
+	Kind      string `json:"kind"`
+	Text      string `json:"text,omitempty"`
+	CallID    string `json:"callId,omitempty"`
+	ToolName  string `json:"toolName,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+	Errored   bool   `json:"errored,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+type replayTokenUsage struct {
+	InputTokens       int64 `json:"inputTokens,omitempty"`
+	OutputTokens      int64 `json:"outputTokens,omitempty"`
+	CachedTokenReads  int64 `json:"cachedTokenReads,omitempty"`
+	CachedTokenWrites int64 `json:"cachedTokenWrites,omitempty"`
+	TotalTokens       int64 `json:"totalTokens,omitempty"`
 }
 
 // decodeReplayMessages parses a replay recording into message history.
@@ -40,32 +44,62 @@ func decodeReplayMessages(data []byte) ([]*LLMMessage, error) {
 	}
 	messages := make([]*LLMMessage, len(wire))
 	for i, m := range wire {
-		msg := &LLMMessage{
-			Role: LLMMessageRole(m.Role),
-			TokenUsage: &LLMTokenUsage{
-				InputTokens:       m.TokenUsage.InputTokens,
-				OutputTokens:      m.TokenUsage.OutputTokens,
-				CachedTokenReads:  m.TokenUsage.CachedTokenReads,
-				CachedTokenWrites: m.TokenUsage.CachedTokenWrites,
-				TotalTokens:       m.TokenUsage.TotalTokens,
-			},
-		}
-		for _, b := range m.Content {
-			msg.Content = append(msg.Content, &LLMContentBlock{
-				Kind:      LLMContentBlockKind(b.Kind),
-				Text:      b.Text,
-				CallID:    b.CallID,
-				ToolName:  b.ToolName,
-				Arguments: JSON(b.Arguments),
-				Errored:   b.Errored,
-				Signature: b.Signature,
-			})
-		}
-		messages[i] = msg
+		messages[i] = m.decode()
 	}
 	return messages, nil
 }
 
+func (m replayMessage) decode() *LLMMessage {
+	msg := &LLMMessage{
+		Role: LLMMessageRole(m.Role),
+		TokenUsage: &LLMTokenUsage{
+			InputTokens:       m.TokenUsage.InputTokens,
+			OutputTokens:      m.TokenUsage.OutputTokens,
+			CachedTokenReads:  m.TokenUsage.CachedTokenReads,
+			CachedTokenWrites: m.TokenUsage.CachedTokenWrites,
+			TotalTokens:       m.TokenUsage.TotalTokens,
+		},
+	}
+	for _, b := range m.Content {
+		msg.Content = append(msg.Content, &LLMContentBlock{
+			Kind:      LLMContentBlockKind(b.Kind),
+			Text:      b.Text,
+			CallID:    b.CallID,
+			ToolName:  b.ToolName,
+			Arguments: JSON(b.Arguments),
+			Errored:   b.Errored,
+			Signature: b.Signature,
+		})
+	}
+	return msg
+}
+
+// encodeReplayMessage renders a message in the recording format.
+func encodeReplayMessage(role LLMMessageRole, blocks []*LLMContentBlock, usage *LLMTokenUsage) replayMessage {
+	m := replayMessage{Role: string(role)}
+	for _, b := range blocks {
+		m.Content = append(m.Content, replayContentBlock{
+			Kind:      string(b.Kind),
+			Text:      b.Text,
+			CallID:    b.CallID,
+			ToolName:  b.ToolName,
+			Arguments: string(b.Arguments),
+			Errored:   b.Errored,
+			Signature: b.Signature,
+		})
+	}
+	if usage != nil {
+		m.TokenUsage = replayTokenUsage{
+			InputTokens:       usage.InputTokens,
+			OutputTokens:      usage.OutputTokens,
+			CachedTokenReads:  usage.CachedTokenReads,
+			CachedTokenWrites: usage.CachedTokenWrites,
+			TotalTokens:       usage.TotalTokens,
+		}
+	}
+	return m
+}
+
 type LLMReplayer struct {
 	messages []*LLMMessage
 }
@@ -75,6 +75,9 @@ func TestLlmConfig(t *testing.T) {
 		"env://LOCAL_MODEL":                   "local-model",
 		"env://LOCAL_API_COMPAT":              "openai",
 		"env://LOCAL_API_KEY":                 "local-api-key",
+		"env://DAGGER_LLM_PROVIDERS":          "",
+		"env://DAGGER_LLM_RECORD":             "",
+		"env://DAGGER_LLM_REPLAY":             "",
 	}
 
 	dagql.Fields[LLMTestQuery]{
@@ -610,3 +613,110 @@ GEMINI_MODEL=gemini-model`, nil
 	assert.Equal(t, "gemini-base-url", r.GeminiBaseURL)
 	assert.Equal(t, "gemini-model", r.GeminiModel)
 }
+
+func TestNamedProviderRouting(t *testing.T) {
+	r := &LLMRouter{
+		OpenAIAPIKey: "ok",
+		NamedProviders: map[string]*LLMNamedProvider{
+			"vllm": {
+				Name:      "vllm",
+				BaseURL:   "http://gpu-box:8000/v1",
+				APIKey:    "sk-vllm",
+				APICompat: "openai",
+				Models:    []string{"qwen3-coder", "gpt-oss-120b"},
+			},
+			"ollama": {
+				Name:      "ollama",
+				BaseURL:   ollamaDefaultBaseURL,
+				APICompat: "ollama",
+				Model:     "llama3.2",
+			},
+		},
+	}
+
+	// "<name>/<model>" targets the provider explicitly, and the provider gets
+	// the bare model name.
+	ep, err := r.Route("ollama/llama3.2", "")
+	require.NoError(t, err)
+	assert.Equal(t, LLMProvider("ollama"), ep.Provider)
+	assert.Equal(t, "llama3.2", ep.Model)
+	assert.Equal(t, ollamaDefaultBaseURL, ep.BaseURL)
+	assert.IsType(t, &OpenAIClient{}, ep.Client)
+
+	// A model listed by a provider routes there, even when named like
+	// another provider's.
+	ep, err = r.Route("gpt-oss-120b", "")
+	require.NoError(t, err)
+	assert.Equal(t, LLMProvider("vllm"), ep.Provider)
+	assert.Equal(t, "gpt-oss-120b", ep.Model)
+	assert.Equal(t, "sk-vllm", ep.Key)
+
+	// Unlisted models keep routing by name.
+	ep, err = r.Route("gpt-4o", "")
+	require.NoError(t, err)
+	assert.Equal(t, OpenAI, ep.Provider)
+
+	// An explicit provider may name a named provider.
+	ep, err = r.Route("vllm/some-model", "vllm")
+	require.NoError(t, err)
+	assert.Equal(t, LLMProvider("vllm"), ep.Provider)
+	assert.Equal(t, "some-model", ep.Model)
+
+	// A named provider's model is a configured default, behind the builtin
+	// providers' configured models but ahead of their fallbacks.
+	assert.Equal(t, "ollama/llama3.2", r.DefaultModel())
+	r.OpenAIModel = "gpt-5"
+	assert.Equal(t, "gpt-5", r.DefaultModel())
+
+	tunnelClient, compat, ok := r.tunnel("vllm")
+	assert.True(t, ok)
+	assert.Nil(t, tunnelClient)
+	assert.Equal(t, "openai", compat)
+	_, _, ok = r.tunnel(OpenAI)
+	assert.False(t, ok)
+
+	// An unsupported API compatibility mode is a routing error.
+	r.NamedProviders["vllm"].APICompat = "bogus"
+	_, err = r.Route("vllm/qwen3-coder", "")
+	assert.ErrorContains(t, err, `provider "vllm": unsupported API compatibility mode`)
+}
+
+func TestLoadNamedProviders(t *testing.T) {
+	r := new(LLMRouter)
+	_, err := r.LoadConfig(t.Context(), getenvFrom(map[string]string{
+		"DAGGER_LLM_PROVIDERS":                    "my-gateway, ollama",
+		"DAGGER_LLM_PROVIDER_MY_GATEWAY_BASE_URL": "https://llm.internal/v1",
+		"DAGGER_LLM_PROVIDER_MY_GATEWAY_API_KEY":  "sk-gateway",
+		"DAGGER_LLM_PROVIDER_MY_GATEWAY_MODELS":   "claude-sonnet-4-5, qwen3",
+		"DAGGER_LLM_PROVIDER_MY_GATEWAY_MODEL":    "qwen3",
+		"DAGGER_LLM_PROVIDER_OLLAMA_API_COMPAT":   "ollama",
+	}))
+	require.NoError(t, err)
+	require.Len(t, r.NamedProviders, 2)
+	assert.Equal(t, &LLMNamedProvider{
+		Name:      "my-gateway",
+		BaseURL:   "https://llm.internal/v1",
+		APIKey:    "sk-gateway",
+		APICompat: "openai",
+		Models:    []string{"claude-sonnet-4-5", "qwen3"},
+		Model:     "qwen3",
+	}, r.NamedProviders["my-gateway"])
+	// Ollama defaults to its stock local address.
+	assert.Equal(t, ollamaDefaultBaseURL, r.NamedProviders["ollama"].BaseURL)
+
+	// A later load replaces a provider of the same name.
+	_, err = r.LoadConfig(t.Context(), getenvFrom(map[string]string{
+		"DAGGER_LLM_PROVIDERS":                  "ollama",
+		"DAGGER_LLM_PROVIDER_OLLAMA_BASE_URL":   "http://studio:11434/v1",
+		"DAGGER_LLM_PROVIDER_OLLAMA_API_COMPAT": "ollama",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "http://studio:11434/v1", r.NamedProviders["ollama"].BaseURL)
+	assert.Equal(t, "https://llm.internal/v1", r.NamedProviders["my-gateway"].BaseURL)
+
+	// Builtin provider names are reserved.
+	_, err = new(LLMRouter).LoadConfig(t.Context(), getenvFrom(map[string]string{
+		"DAGGER_LLM_PROVIDERS": "openai",
+	}))
+	assert.ErrorContains(t, err, "reserved")
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Tool calls are approved by policies matched against the tool's name. There
+// are two sets of them: the [[llm.tools]] policies of the workspace, which
+// apply to every LLM of the session, including those created by modules, and
+// the LLM's own ones. In each set the last matching policy applies, and the
+// most restrictive of the two actions wins, so neither set can loosen what
+// the other denies or asks for. With no policy matching, a call is allowed.
+const (
+	// LLMToolAllow makes the call without asking.
+	LLMToolAllow = "allow"
+	// LLMToolAsk asks the user first, over the session's prompt channel. A
+	// client that can't prompt denies the call.
+	LLMToolAsk = "ask"
+	// LLMToolDeny refuses the call.
+	LLMToolDeny = "deny"
+)
+
+// LLMToolPolicy decides whether the model may call the tools it matches.
+type LLMToolPolicy struct {
+	// Tool is a glob pattern matched against the tool name, e.g. "withExec" or
+	// "publish*". Tools named after a bound object or MCP server match both
+	// with and without that prefix.
+	Tool string
+	// ReadOnly restricts the policy to read-only tools.
+	ReadOnly bool
+	// Action is LLMToolAllow, LLMToolAsk or LLMToolDeny.
+	Action string
+}
+
+func (policy LLMToolPolicy) Validate() error {
+	switch policy.Action {
+	case LLMToolAllow, LLMToolAsk, LLMToolDeny:
+	default:
+		return fmt.Errorf("invalid tool policy action %q: must be %q, %q or %q", policy.Action, LLMToolAllow, LLMToolAsk, LLMToolDeny)
+	}
+	if _, err := path.Match(policy.Tool, ""); err != nil {
+		return fmt.Errorf("invalid tool pattern %q: %w", policy.Tool, err)
+	}
+	return nil
+}
+
+func (policy LLMToolPolicy) matches(tool LLMTool) bool {
+	if policy.ReadOnly && !tool.ReadOnly {
+		return false
+	}
+	for _, name := range []string{tool.Name, bareToolName(tool)} {
+		if ok, _ := path.Match(policy.Tool, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// WithToolPolicy adds a policy approving the model's tool calls, which takes
+// precedence over the ones added before. Adding a policy again moves it last,
+// so applying the same policies to a conversation twice changes nothing.
+func (llm *LLM) WithToolPolicy(policy LLMToolPolicy) (*LLM, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	llm = llm.Clone()
+	llm.mcp = llm.mcp.WithToolPolicy(policy)
+	return llm, nil
+}
+
+func (m *MCP) WithToolPolicy(policy LLMToolPolicy) *MCP {
+	m = m.Clone()
+	m.toolPolicies = slices.DeleteFunc(m.toolPolicies, func(p LLMToolPolicy) bool {
+		return p == policy
+	})
+	m.toolPolicies = append(m.toolPolicies, policy)
+	return m
+}
+
+// toolAction returns the action of the last policy matching a tool.
+func (m *MCP) toolAction(tool LLMTool) string {
+	if action, ok := matchToolPolicies(m.toolPolicies, tool); ok {
+		return action
+	}
+	return LLMToolAllow
+}
+
+func matchToolPolicies(policies []LLMToolPolicy, tool LLMTool) (string, bool) {
+	for _, policy := range slices.Backward(policies) {
+		if policy.matches(tool) {
+			return policy.Action, true
+		}
+	}
+	return "", false
+}
+
+// workspaceToolAction returns the action of the last [[llm.tools]] policy of
+// the current workspace matching a tool, if any.
+func workspaceToolAction(ctx context.Context, tool LLMTool) (string, bool, error) {
+	query, err := CurrentQuery(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	ws, err := query.Server.CurrentWorkspace(ctx)
+	if err != nil {
+		if errors.Is(err, ErrNoCurrentWorkspace) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	action, ok := matchToolPolicies(ws.LLMToolPolicies(), tool)
+	return action, ok, nil
+}
+
+// toolActionRestrictiveness orders the actions from the least to the most
+// restrictive.
+var toolActionRestrictiveness = []string{LLMToolAllow, LLMToolAsk, LLMToolDeny}
+
+// mostRestrictiveToolAction returns whichever of two actions restricts calls
+// the most.
+func mostRestrictiveToolAction(a, b string) string {
+	if slices.Index(toolActionRestrictiveness, b) > slices.Index(toolActionRestrictiveness, a) {
+		return b
+	}
+	return a
+}
+
+// approveToolCall enforces the tool policies on a call, returning why it was
+// refused, if it was.
+func (m *MCP) approveToolCall(ctx context.Context, tool LLMTool, args []byte) (string, bool, error) {
+	action := m.toolAction(tool)
+	wsAction, ok, err := workspaceToolAction(ctx, tool)
+	if err != nil {
+		return "", false, err
+	}
+	if ok {
+		action = mostRestrictiveToolAction(action, wsAction)
+	}
+	switch action {
+	case LLMToolDeny:
+		return fmt.Sprintf("calling %s is not allowed by the tool policy", tool.Name), false, nil
+	case LLMToolAsk:
+		query, err := CurrentQuery(ctx)
+		if err != nil {
+			return "", false, err
+		}
+		bk, err := query.Engine(ctx)
+		if err != nil {
+			return "", false, err
+		}
+		if len(args) == 0 {
+			args = []byte("{}")
+		}
+		approved, err := bk.PromptToolCall(ctx, tool.Name, strings.TrimSpace(string(args)))
+		if err != nil {
+			return "", false, err
+		}
+		if !approved {
+			return fmt.Sprintf("the user did not allow calling %s", tool.Name), false, nil
+		}
+	}
+	return "", true, nil
+}
+
+// bareToolName returns a tool's name without the prefix of the MCP server or
+// bound object it comes from.
+func bareToolName(tool LLMTool) string {
+	name := tool.Name
+	if tool.Server != "" {
+		// External MCP tools may come prefixed `<server>_`; collision-namespaced
+		// object tools are prefixed `<gqlFieldName(server)>_` (their Server is
+		// the bound type name). Trim either.
+		name = strings.TrimPrefix(name, tool.Server+"_")
+		name = strings.TrimPrefix(name, gqlFieldName(tool.Server)+"_")
+	}
+	return name
+}
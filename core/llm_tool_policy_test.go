@@ -0,0 +1,65 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLLMToolPolicies(t *testing.T) {
+	withExec := LLMTool{Name: "Container_withExec", Server: "Container"}
+	stdout := LLMTool{Name: "stdout", ReadOnly: true}
+	publish := LLMTool{Name: "publish"}
+
+	m := newMCP()
+	require.Equal(t, LLMToolAllow, m.toolAction(withExec))
+
+	m = m.WithToolPolicy(LLMToolPolicy{Tool: "*", Action: LLMToolAsk}).
+		WithToolPolicy(LLMToolPolicy{Tool: "*", ReadOnly: true, Action: LLMToolAllow}).
+		WithToolPolicy(LLMToolPolicy{Tool: "publish*", Action: LLMToolDeny})
+	require.Equal(t, LLMToolAsk, m.toolAction(withExec))
+	require.Equal(t, LLMToolAllow, m.toolAction(stdout))
+	require.Equal(t, LLMToolDeny, m.toolAction(publish))
+
+	// adding a policy again moves it last
+	again := m.WithToolPolicy(LLMToolPolicy{Tool: "*", Action: LLMToolAsk})
+	require.Len(t, again.toolPolicies, 3)
+	require.Equal(t, LLMToolAsk, again.toolAction(publish))
+
+	// a prefixed tool matches its bare name too
+	m = m.WithToolPolicy(LLMToolPolicy{Tool: "withExec", Action: LLMToolDeny})
+	require.Equal(t, LLMToolDeny, m.toolAction(withExec))
+
+	ms := &mockServer{}
+	ctx := ContextWithQuery(t.Context(), &Query{Server: ms})
+	refusal, approved, err := m.approveToolCall(ctx, publish, nil)
+	require.NoError(t, err)
+	require.False(t, approved)
+	require.Equal(t, "calling publish is not allowed by the tool policy", refusal)
+
+	_, approved, err = m.approveToolCall(ctx, stdout, nil)
+	require.NoError(t, err)
+	require.True(t, approved)
+
+	// the workspace's policies can restrict what the LLM's own allow
+	ms.workspace = &Workspace{}
+	ms.workspace.SetLLMToolPolicies([]LLMToolPolicy{{Tool: "stdout", Action: LLMToolDeny}})
+	refusal, approved, err = m.approveToolCall(ctx, stdout, nil)
+	require.NoError(t, err)
+	require.False(t, approved)
+	require.Equal(t, "calling stdout is not allowed by the tool policy", refusal)
+
+	// but not allow what the LLM's own deny
+	ms.workspace.SetLLMToolPolicies([]LLMToolPolicy{{Tool: "publish", Action: LLMToolAllow}})
+	refusal, approved, err = m.approveToolCall(ctx, publish, nil)
+	require.NoError(t, err)
+	require.False(t, approved)
+	require.Equal(t, "calling publish is not allowed by the tool policy", refusal)
+
+	require.Equal(t, LLMToolDeny, mostRestrictiveToolAction(LLMToolAllow, LLMToolDeny))
+	require.Equal(t, LLMToolDeny, mostRestrictiveToolAction(LLMToolDeny, LLMToolAsk))
+	require.Equal(t, LLMToolAsk, mostRestrictiveToolAction(LLMToolAsk, LLMToolAllow))
+
+	require.ErrorContains(t, LLMToolPolicy{Tool: "*", Action: "maybe"}.Validate(), "invalid tool policy action")
+	require.ErrorContains(t, LLMToolPolicy{Tool: "[", Action: LLMToolDeny}.Validate(), "invalid tool pattern")
+}
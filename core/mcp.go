@@ -99,6 +99,9 @@ type MCP struct {
 	// the model through ListSkills/ReadSkill alongside the engine-embedded and
 	// workspace-discovered skills.
 	skillDirs []dagql.ObjectResult[*Directory]
+	// toolPolicies approve the model's tool calls, the last matching one
+	// applying (see LLMToolPolicy).
+	toolPolicies []LLMToolPolicy
 	// Configured MCP servers.
 	mcpServers map[string]*MCPServerConfig
 	// Persistent MCP sessions.
@@ -114,17 +117,43 @@ type MCPServerConfig struct {
 
 	// Command to run the MCP server
 	Service dagql.ObjectResult[*Service]
+
+	// Port of Service serving the streamable HTTP transport. The service is
+	// spoken to over stdio if unset.
+	Port int
+
+	// Path of the streamable HTTP endpoint of Service, when Port is set.
+	Path string
+
+	// URL of a remote MCP server serving the streamable HTTP transport, used
+	// instead of Service.
+	URL string
+
+	// Secret used to populate the Authorization header of HTTP requests
+	AuthHeader dagql.ObjectResult[*Secret]
 }
 
 func (srv *MCPServerConfig) Dial(ctx context.Context) (_ *mcp.ClientSession, rerr error) {
 	ctx, span := Tracer(ctx).Start(ctx, "start mcp server: "+srv.Name, telemetry.Reveal())
 	defer telemetry.EndWithCause(span, &rerr)
+	var transport mcp.Transport
+	if srv.URL != "" || srv.Port != 0 {
+		transport = &HTTPMCPTransport{
+			Service:    srv.Service,
+			Port:       srv.Port,
+			Path:       srv.Path,
+			URL:        srv.URL,
+			AuthHeader: srv.AuthHeader,
+		}
+	} else {
+		transport = &ServiceMCPTransport{
+			Service: srv.Service,
+		}
+	}
 	return mcp.NewClient(&mcp.Implementation{
 		Title:   "Dagger",
 		Version: engine.Version,
-	}, nil).Connect(ctx, &ServiceMCPTransport{
-		Service: srv.Service,
-	}, nil)
+	}, nil).Connect(ctx, transport, nil)
 }
 
 func newMCP() *MCP {
@@ -147,6 +176,7 @@ func (m *MCP) Clone() *MCP {
 	cp := *m
 	cp.boundTools = slices.Clone(cp.boundTools)
 	cp.skillDirs = slices.Clone(cp.skillDirs)
+	cp.toolPolicies = slices.Clone(cp.toolPolicies)
 	cp.mcpServers = maps.Clone(cp.mcpServers)
 	cp.mcpSessions = maps.Clone(cp.mcpSessions)
 	cp.returned = false
@@ -789,15 +819,8 @@ func (m *MCP) Call(ctx context.Context, tools []LLMTool, toolCall *LLMToolCall)
 			}
 		}
 	}
-	toolName := tool.Name
-	if tool.Server != "" {
-		// External MCP tools may come prefixed `<server>_`; collision-namespaced
-		// object tools are prefixed `<gqlFieldName(server)>_` (their Server is
-		// the bound type name). Trim either so the span shows the bare tool name
-		// alongside the server attribute.
-		toolName = strings.TrimPrefix(toolName, tool.Server+"_")
-		toolName = strings.TrimPrefix(toolName, gqlFieldName(tool.Server)+"_")
-	}
+	// Show the bare tool name alongside the server attribute.
+	toolName := bareToolName(*tool)
 	span := trace.SpanFromContext(ctx)
 	attrs := []attribute.KeyValue{
 		attribute.String(telemetry.LLMToolAttr, toolName),
@@ -831,6 +854,14 @@ func (m *MCP) Call(ctx context.Context, tools []LLMTool, toolCall *LLMToolCall)
 		fmt.Fprintln(stdio.Stdout, res)
 	}()
 
+	refusal, approved, err := m.approveToolCall(ctx, *tool, toolCall.Arguments)
+	if err != nil {
+		return toolErrorMessage(err), true
+	}
+	if !approved {
+		return refusal, true
+	}
+
 	toolCtx := ctx
 	if m.workspace.Self() != nil {
 		// Bind the LLM's Workspace so the tool's contextual (+defaultPath) and
@@ -984,6 +1015,11 @@ func (m *MCP) callBatchMCPServer(ctx context.Context, tools []LLMTool, toolCalls
 		return m.callBatchRegular(ctx, tools, toolCalls, toolCallDisplays)
 	}
 
+	if mcpSrv.Service.Self() == nil {
+		// Remote server - no workspace to sync
+		return m.callBatchRegular(ctx, tools, toolCalls, toolCallDisplays)
+	}
+
 	ctr := mcpSrv.Service.Self().Container
 	if ctr.Self() == nil || ctr.Self().Config.WorkingDir == "" || ctr.Self().Config.WorkingDir == "/" {
 		// No workspace syncing needed - execute normally
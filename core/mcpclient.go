@@ -6,6 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/dagger/dagger/dagql"
@@ -92,3 +96,99 @@ func (t *ServiceMCPConnection) Close() error {
 func (t *ServiceMCPConnection) SessionID() string {
 	return t.svc.ContainerID
 }
+
+// HTTPMCPTransport speaks the MCP streamable HTTP transport, either to a port
+// of a service or to a remote URL.
+type HTTPMCPTransport struct {
+	Service    dagql.ObjectResult[*Service]
+	Port       int
+	Path       string
+	URL        string
+	AuthHeader dagql.ObjectResult[*Secret]
+}
+
+var _ mcp.Transport = (*HTTPMCPTransport)(nil)
+
+func (t *HTTPMCPTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	httpTransport := http.DefaultTransport.(*http.Transport).Clone()
+	endpoint := t.URL
+	detach := func() {}
+	if t.Service.Self() != nil {
+		query, err := CurrentQuery(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current query: %w", err)
+		}
+		svcs, err := query.Services(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get services: %w", err)
+		}
+		bk, err := query.Engine(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get engine client: %w", err)
+		}
+		svc, err := svcs.StartResult(ctx, t.Service, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start service: %w", err)
+		}
+		detach = func() {
+			svcs.Detach(context.WithoutCancel(ctx), svc)
+		}
+		endpoint = (&url.URL{
+			Scheme: "http",
+			Host:   net.JoinHostPort(svc.Host, strconv.Itoa(t.Port)),
+			Path:   t.Path,
+		}).String()
+		// service hostnames are only resolved by the engine's dialer
+		httpTransport.DialContext = bk.Dialer.DialContext
+	}
+
+	var roundTripper http.RoundTripper = httpTransport
+	if t.AuthHeader.Self() != nil {
+		header, err := t.AuthHeader.Self().Plaintext(ctx)
+		if err != nil {
+			detach()
+			return nil, fmt.Errorf("failed to get auth header: %w", err)
+		}
+		roundTripper = &authHeaderRoundTripper{
+			header: string(header),
+			inner:  roundTripper,
+		}
+	}
+
+	conn, err := (&mcp.StreamableClientTransport{
+		Endpoint:   endpoint,
+		HTTPClient: &http.Client{Transport: roundTripper},
+	}).Connect(ctx)
+	if err != nil {
+		detach()
+		return nil, err
+	}
+	return &HTTPMCPConnection{
+		Connection: conn,
+		detach:     detach,
+	}, nil
+}
+
+type HTTPMCPConnection struct {
+	mcp.Connection
+	detach func()
+}
+
+var _ mcp.Connection = (*HTTPMCPConnection)(nil)
+
+func (t *HTTPMCPConnection) Close() error {
+	defer t.detach()
+	return t.Connection.Close()
+}
+
+// authHeaderRoundTripper sets the Authorization header of every request.
+type authHeaderRoundTripper struct {
+	header string
+	inner  http.RoundTripper
+}
+
+func (rt *authHeaderRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", rt.header)
+	return rt.inner.RoundTrip(req)
+}
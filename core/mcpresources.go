@@ -0,0 +1,270 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/dagger/dagger/dagql"
+)
+
+// Besides tools, `dagger mcp` publishes resources and prompts. Read-only
+// getters of the bound objects (config files, generated reports, directory
+// listings) are resources the client can browse, and skills and tool
+// docstrings are prompts the user can pick from.
+
+// mcpResourceScheme is the URI scheme of the resources published by `dagger
+// mcp`, e.g. dagger://MyModule/config.
+const mcpResourceScheme = "dagger"
+
+// LLMResource is a read-only getter of a bound object, published as an MCP
+// resource.
+type LLMResource struct {
+	URI         string
+	Name        string
+	Description string
+	MIMEType    string
+	// Read evaluates the getter against the current bound object.
+	Read func(context.Context) (string, error)
+}
+
+// LLMPrompt is a reusable prompt, published as an MCP prompt.
+type LLMPrompt struct {
+	Name        string
+	Description string
+	Args        []LLMPromptArg
+	// Render returns the text of the prompt for the given arguments.
+	Render func(context.Context, map[string]string) (string, error)
+}
+
+type LLMPromptArg struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// Resources returns the read-only getters of the bound objects: read-only
+// methods without required arguments returning a scalar, a list of strings, a
+// File (read as its contents) or a Directory (read as its entries).
+func (m *MCP) Resources(ctx context.Context) ([]LLMResource, error) {
+	srv, err := m.Server(ctx)
+	if err != nil {
+		return nil, err
+	}
+	toolsets, err := m.boundToolsets(srv)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []LLMResource
+	for _, ts := range toolsets {
+		for _, tool := range ts.tools {
+			if !objectResourceEligible(tool) {
+				continue
+			}
+			field := tool.Field
+			mimeType := "text/plain"
+			if field.Type.Elem != nil || !slices.Contains([]string{"String", "File", "Directory"}, field.Type.Name()) {
+				mimeType = "application/json"
+			}
+			resources = append(resources, LLMResource{
+				URI:         fmt.Sprintf("%s://%s/%s", mcpResourceScheme, ts.typeName, field.Name),
+				Name:        gqlFieldName(ts.typeName) + "." + field.Name,
+				Description: tool.Description,
+				MIMEType:    mimeType,
+				Read:        m.readObjectResource(srv, ts.typeName, field.Name),
+			})
+		}
+	}
+	return resources, nil
+}
+
+// objectResourceEligible reports whether a tool of a bound object is a
+// read-only getter that can be published as a resource.
+func objectResourceEligible(tool LLMTool) bool {
+	if !tool.ReadOnly || tool.Field == nil {
+		return false
+	}
+	field := tool.Field
+	for _, arg := range field.Arguments {
+		if isWorkspaceArg(arg) {
+			continue
+		}
+		if arg.Type.NonNull && arg.DefaultValue == nil {
+			return false
+		}
+	}
+	if elem := field.Type.Elem; elem != nil {
+		return elem.Elem == nil && elem.NamedType == "String"
+	}
+	switch field.Type.NamedType {
+	case "String", "Int", "Float", "Boolean", "JSON", "File", "Directory":
+		return true
+	default:
+		return false
+	}
+}
+
+func (m *MCP) readObjectResource(srv *dagql.Server, typeName, fieldName string) func(context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		m.mu.Lock()
+		ws := m.workspace
+		m.mu.Unlock()
+		if ws.Self() != nil {
+			// read the workspace as last reloaded, like tool calls
+			ctx = WorkspaceToContext(ctx, ws)
+		}
+		recv, ok, err := m.boundToolObject(ctx, srv, typeName)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", fmt.Errorf("no object of type %q is bound", typeName)
+		}
+		sel, err := buildObjectMethodSelector(ctx, srv, recv.ObjectType(), fieldName, map[string]any{})
+		if err != nil {
+			return "", err
+		}
+		var val dagql.AnyResult
+		if err := srv.Select(ctx, recv, &val, sel); err != nil {
+			return "", err
+		}
+		if val == nil {
+			return "", nil
+		}
+
+		switch val.Type().Name() {
+		case "File":
+			obj, ok := dagql.UnwrapAs[dagql.AnyObjectResult](val)
+			if !ok {
+				return "", fmt.Errorf("unexpected file result %T", val)
+			}
+			var contents string
+			err := srv.Select(ctx, obj, &contents, dagql.Selector{View: srv.View, Field: "contents"})
+			return contents, err
+		case "Directory":
+			obj, ok := dagql.UnwrapAs[dagql.AnyObjectResult](val)
+			if !ok {
+				return "", fmt.Errorf("unexpected directory result %T", val)
+			}
+			var entries []string
+			if err := srv.Select(ctx, obj, &entries, dagql.Selector{View: srv.View, Field: "entries"}); err != nil {
+				return "", err
+			}
+			return strings.Join(entries, "\n"), nil
+		}
+
+		result, err := m.sanitizeResult(val)
+		if err != nil {
+			return "", err
+		}
+		if str, ok := result.(string); ok {
+			return str, nil
+		}
+		b, err := json.Marshal(result)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+}
+
+// reloadWorkspace binds the workspace reloaded, for the files it reads from
+// the host to be read again.
+func (m *MCP) reloadWorkspace(ctx context.Context, srv *dagql.Server) error {
+	m.mu.Lock()
+	ws := m.workspace
+	m.mu.Unlock()
+	if ws.Self() == nil {
+		return nil
+	}
+	var reloaded dagql.ObjectResult[*Workspace]
+	if err := srv.Select(ctx, ws, &reloaded, dagql.Selector{View: srv.View, Field: "reloaded"}); err != nil {
+		return fmt.Errorf("reload workspace: %w", err)
+	}
+	m.mu.Lock()
+	m.workspace = reloaded
+	m.mu.Unlock()
+	return nil
+}
+
+// Prompts returns the skills, which render as their SKILL.md, and the
+// documented tools of the bound objects, which render as an instruction to
+// call the tool. A skill wins a name collision with a tool.
+func (m *MCP) Prompts(ctx context.Context) ([]LLMPrompt, error) {
+	srv, err := m.Server(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var prompts []LLMPrompt
+	seen := map[string]bool{}
+
+	sources := m.skillSources()
+	skills, err := listSkills(ctx, sources)
+	if err != nil {
+		return nil, err
+	}
+	for _, skill := range skills {
+		name := skill.Name
+		seen[name] = true
+		prompts = append(prompts, LLMPrompt{
+			Name:        name,
+			Description: skill.Description,
+			Render: func(ctx context.Context, _ map[string]string) (string, error) {
+				return readSkill(ctx, sources, name, "")
+			},
+		})
+	}
+
+	tools := NewLLMToolSet()
+	if err := m.loadObjectTools(ctx, srv, tools); err != nil {
+		return nil, err
+	}
+	for _, tool := range tools.Order {
+		if tool.Description == "" || seen[tool.Name] {
+			continue
+		}
+		seen[tool.Name] = true
+		prompts = append(prompts, toolPrompt(tool))
+	}
+	return prompts, nil
+}
+
+// toolPrompt derives a prompt from a tool's docstring, taking the tool's
+// arguments as prompt arguments.
+func toolPrompt(tool LLMTool) LLMPrompt {
+	var args []LLMPromptArg
+	properties, _ := tool.Schema["properties"].(map[string]any)
+	required, _ := tool.Schema["required"].([]string)
+	for _, name := range slices.Sorted(maps.Keys(properties)) {
+		desc, _ := properties[name].(map[string]any)["description"].(string)
+		args = append(args, LLMPromptArg{
+			Name:        name,
+			Description: desc,
+			Required:    slices.Contains(required, name),
+		})
+	}
+	return LLMPrompt{
+		Name:        tool.Name,
+		Description: strings.TrimSpace(strings.SplitN(tool.Description, "\n", 2)[0]),
+		Args:        args,
+		Render: func(_ context.Context, values map[string]string) (string, error) {
+			var prompt strings.Builder
+			fmt.Fprintf(&prompt, "Use the %s tool.\n\n%s\n", tool.Name, tool.Description)
+			var set []string
+			for _, arg := range args {
+				if v, ok := values[arg.Name]; ok {
+					set = append(set, fmt.Sprintf("- %s: %s", arg.Name, v))
+				}
+			}
+			if len(set) > 0 {
+				fmt.Fprintf(&prompt, "\nArguments:\n%s\n", strings.Join(set, "\n"))
+			}
+			return prompt.String(), nil
+		},
+	}
+}
@@ -1,19 +1,28 @@
 package core
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	stdlog "log"
+	"net"
+	"net/http"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/engine"
 	"github.com/dagger/dagger/internal/buildkit/util/bklog"
 	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/opencontainers/go-digest"
+	"golang.org/x/net/http2"
 )
 
 // mcpDefaultAny lets us skip the typed defaults
@@ -112,6 +121,73 @@ type mcpServer struct {
 	dag  *dagql.Server
 	env  *MCP
 	pipe io.ReadWriteCloser
+	// http serves the streamable HTTP transport over HTTP/2 on the pipe,
+	// instead of stdio, so that many clients can share the session.
+	http bool
+	// subs tracks the resources clients subscribed to, to notify them of
+	// updates.
+	subs *mcpSubscriptions
+	// workspaceChanged is signaled when files of the workspace changed on the
+	// host.
+	workspaceChanged chan struct{}
+}
+
+// mcpSubscriptions are the resources each client session subscribed to, with
+// the last known digest of their contents.
+type mcpSubscriptions struct {
+	mu       sync.Mutex
+	sessions map[string][]string
+	digests  map[string]digest.Digest
+}
+
+func newMCPSubscriptions() *mcpSubscriptions {
+	return &mcpSubscriptions{
+		sessions: map[string][]string{},
+		digests:  map[string]digest.Digest{},
+	}
+}
+
+func (r *mcpSubscriptions) subscribe(uri, sessionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !slices.Contains(r.sessions[uri], sessionID) {
+		r.sessions[uri] = append(r.sessions[uri], sessionID)
+	}
+}
+
+func (r *mcpSubscriptions) unsubscribe(uri, sessionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[uri] = slices.DeleteFunc(r.sessions[uri], func(id string) bool {
+		return id == sessionID
+	})
+	if len(r.sessions[uri]) == 0 {
+		delete(r.sessions, uri)
+		delete(r.digests, uri)
+	}
+}
+
+func (r *mcpSubscriptions) subscribed(uri string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.sessions[uri]) > 0
+}
+
+// observe records the digest of a subscribed resource's contents, returning
+// the sessions to notify if it changed since it was last read.
+func (r *mcpSubscriptions) observe(uri, contents string) []string {
+	dgst := digest.FromString(contents)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.sessions[uri]) == 0 {
+		return nil
+	}
+	prev, ok := r.digests[uri]
+	r.digests[uri] = dgst
+	if !ok || prev == dgst {
+		return nil
+	}
+	return slices.Clone(r.sessions[uri])
 }
 
 func (s mcpServer) genMcpToolHandler(tool LLMTool) mcpserver.ToolHandlerFunc {
@@ -121,6 +197,20 @@ func (s mcpServer) genMcpToolHandler(tool LLMTool) mcpserver.ToolHandlerFunc {
 			return nil, fmt.Errorf("[dagger] expected MCP request method \"tools/call\" but received %q", request.Method)
 		}
 
+		args, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("[dagger] could not JSON marshal arguments: %w", err)
+		}
+		refusal, approved, err := s.env.approveToolCall(ctx, tool, args)
+		if err != nil {
+			return nil, err
+		}
+		if !approved {
+			res := mcp.NewToolResultText(refusal)
+			res.IsError = true
+			return res, nil
+		}
+
 		result, err := tool.Call(ctx, request.Params.Arguments)
 		// TODO: differentiate user module's error from dagger error for better error message
 		if err != nil {
@@ -140,6 +230,13 @@ func (s mcpServer) genMcpToolHandler(tool LLMTool) mcpserver.ToolHandlerFunc {
 		if err := s.setTools(ctx); err != nil {
 			return nil, err
 		}
+		if !tool.ReadOnly {
+			// the call may have changed the bound objects
+			if err := s.setResources(ctx); err != nil {
+				return nil, err
+			}
+			s.notifySubscribers(ctx)
+		}
 
 		return mcp.NewToolResultText(text), nil
 	}
@@ -175,6 +272,270 @@ func (s mcpServer) setTools(ctx context.Context) error {
 	return nil
 }
 
+func (s mcpServer) setResources(ctx context.Context) error {
+	resources, err := s.env.Resources(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get resources: %w", err)
+	}
+	mcpResources := make([]mcpserver.ServerResource, 0, len(resources))
+	for _, res := range resources {
+		mcpResources = append(mcpResources, mcpserver.ServerResource{
+			Resource: mcp.NewResource(res.URI, res.Name,
+				mcp.WithResourceDescription(res.Description),
+				mcp.WithMIMEType(res.MIMEType)),
+			Handler: func(ctx context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				contents, err := res.Read(ctx)
+				if err != nil {
+					return nil, err
+				}
+				s.subs.observe(res.URI, contents)
+				return []mcp.ResourceContents{mcp.TextResourceContents{
+					URI:      res.URI,
+					MIMEType: res.MIMEType,
+					Text:     contents,
+				}}, nil
+			},
+		})
+	}
+	s.SetResources(mcpResources...)
+	return nil
+}
+
+// notifySubscribers re-reads the resources clients subscribed to, notifying
+// them of the ones whose contents changed.
+func (s mcpServer) notifySubscribers(ctx context.Context) {
+	resources, err := s.env.Resources(ctx)
+	if err != nil {
+		bklog.G(ctx).WithError(err).Debug("failed to get MCP resources")
+		return
+	}
+	for _, res := range resources {
+		if !s.subs.subscribed(res.URI) {
+			continue
+		}
+		contents, err := res.Read(ctx)
+		if err != nil {
+			bklog.G(ctx).WithError(err).Debugf("failed to read MCP resource %s", res.URI)
+			continue
+		}
+		for _, sessionID := range s.subs.observe(res.URI, contents) {
+			err := s.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{
+				"uri": res.URI,
+			})
+			if err != nil {
+				bklog.G(ctx).WithError(err).Debugf("failed to notify MCP session %s", sessionID)
+			}
+		}
+	}
+}
+
+// The resource subscription methods, which mcp-go doesn't implement.
+const (
+	mcpMethodSubscribe   = "resources/subscribe"
+	mcpMethodUnsubscribe = "resources/unsubscribe"
+)
+
+// mcpMessage is the part of a JSON-RPC message needed to handle the methods
+// mcp-go doesn't implement.
+type mcpMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params struct {
+		URI string `json:"uri"`
+	} `json:"params"`
+}
+
+// handleMessage handles the messages mcp-go doesn't implement: resource
+// subscriptions, and the notifications of workspace changes from `dagger mcp`.
+// It returns the response to send back, if any, and whether it handled the
+// message.
+func (s mcpServer) handleMessage(ctx context.Context, sessionID string, raw []byte) ([]byte, bool) {
+	var msg mcpMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return nil, false
+	}
+	switch msg.Method {
+	case mcpMethodSubscribe, mcpMethodUnsubscribe:
+		if msg.Params.URI == "" {
+			return mcpResponse(msg.ID, "error", map[string]any{
+				"code":    mcp.INVALID_PARAMS,
+				"message": "missing resource URI",
+			}), true
+		}
+		if msg.Method == mcpMethodUnsubscribe {
+			s.subs.unsubscribe(msg.Params.URI, sessionID)
+		} else {
+			s.subs.subscribe(msg.Params.URI, sessionID)
+			// read the resource now, to tell when it changes
+			go s.readSubscribed(context.WithoutCancel(ctx), msg.Params.URI)
+		}
+		return mcpResponse(msg.ID, "result", map[string]any{}), true
+	case engine.MCPWorkspaceChangedMethod:
+		select {
+		case s.workspaceChanged <- struct{}{}:
+		default:
+			// a reload is pending already
+		}
+		return nil, true
+	default:
+		return nil, false
+	}
+}
+
+func mcpResponse(id json.RawMessage, key string, value any) []byte {
+	b, _ := json.Marshal(map[string]any{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      id,
+		key:       value,
+	})
+	return b
+}
+
+func (s mcpServer) readSubscribed(ctx context.Context, uri string) {
+	resources, err := s.env.Resources(ctx)
+	if err != nil {
+		bklog.G(ctx).WithError(err).Debug("failed to get MCP resources")
+		return
+	}
+	for _, res := range resources {
+		if res.URI != uri {
+			continue
+		}
+		contents, err := res.Read(ctx)
+		if err != nil {
+			bklog.G(ctx).WithError(err).Debugf("failed to read MCP resource %s", res.URI)
+			return
+		}
+		s.subs.observe(res.URI, contents)
+	}
+}
+
+// watchWorkspace reloads the workspace whenever its files changed on the host,
+// notifying clients of the resources that changed with it.
+func (s mcpServer) watchWorkspace(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.workspaceChanged:
+		}
+		if err := s.env.reloadWorkspace(ctx, s.dag); err != nil {
+			bklog.G(ctx).WithError(err).Warn("failed to reload MCP workspace")
+			continue
+		}
+		if err := s.setResources(ctx); err != nil {
+			bklog.G(ctx).WithError(err).Warn("failed to update MCP resources")
+		}
+		s.notifySubscribers(ctx)
+	}
+}
+
+// interceptStdio passes the messages read from r through to the returned
+// reader, except those handled by handleMessage, whose responses are written
+// to w.
+func (s mcpServer) interceptStdio(ctx context.Context, r io.Reader, w io.Writer) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				if resp, ok := s.handleMessage(ctx, mcpStdioSessionID, line); ok {
+					if resp != nil {
+						if _, err := w.Write(append(resp, '\n')); err != nil {
+							pw.CloseWithError(err)
+							return
+						}
+					}
+				} else if _, err := pw.Write(line); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return pr
+}
+
+// mcpStdioSessionID is the ID of the single session of the stdio transport.
+const mcpStdioSessionID = "stdio"
+
+// interceptHTTP serves the requests handled by handleMessage, passing the
+// others through to next.
+func (s mcpServer) interceptHTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp, ok := s.handleMessage(r.Context(), r.Header.Get(mcpserver.HeaderKeySessionID), body)
+		if !ok {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+			return
+		}
+		if resp == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(resp)
+	})
+}
+
+// lockedWriter serializes writes, for the responses of handleMessage not to
+// interleave with the ones of mcp-go.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+func (s mcpServer) setPrompts(ctx context.Context) error {
+	prompts, err := s.env.Prompts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get prompts: %w", err)
+	}
+	mcpPrompts := make([]mcpserver.ServerPrompt, 0, len(prompts))
+	for _, prompt := range prompts {
+		opts := []mcp.PromptOption{mcp.WithPromptDescription(prompt.Description)}
+		for _, arg := range prompt.Args {
+			argOpts := []mcp.ArgumentOption{mcp.ArgumentDescription(arg.Description)}
+			if arg.Required {
+				argOpts = append(argOpts, mcp.RequiredArgument())
+			}
+			opts = append(opts, mcp.WithArgument(arg.Name, argOpts...))
+		}
+		mcpPrompts = append(mcpPrompts, mcpserver.ServerPrompt{
+			Prompt: mcp.NewPrompt(prompt.Name, opts...),
+			Handler: func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				text, err := prompt.Render(ctx, request.Params.Arguments)
+				if err != nil {
+					return nil, err
+				}
+				return mcp.NewGetPromptResult(prompt.Description, []mcp.PromptMessage{
+					mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+				}), nil
+			},
+		})
+	}
+	s.SetPrompts(mcpPrompts...)
+	return nil
+}
+
 func (s mcpServer) run(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -182,6 +543,18 @@ func (s mcpServer) run(ctx context.Context) error {
 	if err := s.setTools(ctx); err != nil {
 		return err
 	}
+	if err := s.setResources(ctx); err != nil {
+		return err
+	}
+	if err := s.setPrompts(ctx); err != nil {
+		return err
+	}
+
+	go s.watchWorkspace(ctx)
+
+	if s.http {
+		return s.serveHTTP(ctx)
+	}
 
 	errCh := make(chan error)
 
@@ -194,7 +567,8 @@ func (s mcpServer) run(ctx context.Context) error {
 	// Start MCP server in a goroutine
 	go func() {
 		defer close(errCh)
-		err := stdioSrv.Listen(ctx, s.pipe, s.pipe)
+		out := &lockedWriter{w: s.pipe}
+		err := stdioSrv.Listen(ctx, s.interceptStdio(ctx, s.pipe, out), out)
 		if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, io.EOF) {
 			select {
 			case <-ctx.Done():
@@ -211,7 +585,50 @@ func (s mcpServer) run(ctx context.Context) error {
 	}
 }
 
-func (llm *LLM) MCP(ctx context.Context, dag *dagql.Server) error {
+// serveHTTP serves the streamable HTTP transport to the client, which
+// multiplexes the requests of its own HTTP clients over the pipe.
+func (s mcpServer) serveHTTP(ctx context.Context) error {
+	httpSrv := mcpserver.NewStreamableHTTPServer(s.MCPServer,
+		mcpserver.WithStateful(true))
+
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		// request contexts derive from ctx, so that tools are called with the
+		// client's session
+		(&http2.Server{}).ServeConn(pipeConn{s.pipe}, &http2.ServeConnOpts{
+			Context: ctx,
+			Handler: s.interceptHTTP(httpSrv),
+		})
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-doneCh:
+		return nil
+	}
+}
+
+// pipeConn adapts a pipe to a net.Conn, without support for deadlines.
+type pipeConn struct {
+	io.ReadWriteCloser
+}
+
+var _ net.Conn = pipeConn{}
+
+func (pipeConn) LocalAddr() net.Addr              { return pipeAddr{} }
+func (pipeConn) RemoteAddr() net.Addr             { return pipeAddr{} }
+func (pipeConn) SetDeadline(time.Time) error      { return nil }
+func (pipeConn) SetReadDeadline(time.Time) error  { return nil }
+func (pipeConn) SetWriteDeadline(time.Time) error { return nil }
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "pipe" }
+
+func (llm *LLM) MCP(ctx context.Context, dag *dagql.Server, http bool) error {
 	// Under the object-tools scheme the LLM only acts through explicitly
 	// bound objects. `dagger mcp` exposes the workspace: when nothing was
 	// bound, bind each workspace module's main object so its methods are the
@@ -245,11 +662,17 @@ func (llm *LLM) MCP(ctx context.Context, dag *dagql.Server) error {
 	}
 
 	s := mcpServer{
-		mcpserver.NewMCPServer("Dagger", "0.0.1",
-			mcpserver.WithInstructions(instructions)),
-		dag,
-		llm.mcp,
-		rwc,
+		MCPServer: mcpserver.NewMCPServer("Dagger", "0.0.1",
+			mcpserver.WithInstructions(instructions),
+			mcpserver.WithResourceCapabilities(true, true),
+			mcpserver.WithPromptCapabilities(true)),
+		dag:  dag,
+		env:  llm.mcp,
+		pipe: rwc,
+		http: http,
+		subs: newMCPSubscriptions(),
+		// buffered, so that a change is never missed during a reload
+		workspaceChanged: make(chan struct{}, 1),
 	}
 
 	return s.run(ctx)
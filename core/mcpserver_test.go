@@ -0,0 +1,176 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagger/dagger/engine"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+	"golang.org/x/net/http2"
+)
+
+func TestMCPServeHTTP(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	srvConn, cliConn := net.Pipe()
+	s := mcpServer{
+		MCPServer: mcpserver.NewMCPServer("Dagger", "0.0.1"),
+		pipe:      srvConn,
+		http:      true,
+	}
+	s.AddTool(mcp.NewTool("greet"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("hello"), nil
+	})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.serveHTTP(ctx)
+	}()
+
+	tr, err := http2.ConfigureTransports(&http.Transport{})
+	require.NoError(t, err)
+	conn, err := tr.NewClientConn(cliConn)
+	require.NoError(t, err)
+	httpClient := &http.Client{Transport: conn}
+
+	// every client gets its own session over the same connection
+	sessions := map[string]struct{}{}
+	for range 2 {
+		cli, err := mcpclient.NewStreamableHttpClient("http://mcp/mcp",
+			transport.WithHTTPBasicClient(httpClient))
+		require.NoError(t, err)
+		require.NoError(t, cli.Start(ctx))
+		_, err = cli.Initialize(ctx, mcp.InitializeRequest{})
+		require.NoError(t, err)
+		sessions[cli.GetSessionId()] = struct{}{}
+
+		res, err := cli.CallTool(ctx, mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "greet"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "hello", res.Content[0].(mcp.TextContent).Text)
+	}
+	require.Len(t, sessions, 2)
+
+	cancel()
+	require.ErrorIs(t, <-errCh, context.Canceled)
+}
+
+func TestMCPToolPrompt(t *testing.T) {
+	prompt := toolPrompt(LLMTool{
+		Name:        "Greeter_greet",
+		Description: "Greet someone.\n\nSays hello politely.",
+		Schema: map[string]any{
+			"properties": map[string]any{
+				"name":     map[string]any{"type": "string", "description": "who to greet"},
+				"greeting": map[string]any{"type": "string"},
+			},
+			"required": []string{"name"},
+		},
+	})
+	require.Equal(t, "Greet someone.", prompt.Description)
+	require.Equal(t, []LLMPromptArg{
+		{Name: "greeting"},
+		{Name: "name", Description: "who to greet", Required: true},
+	}, prompt.Args)
+
+	text, err := prompt.Render(t.Context(), map[string]string{"name": "Alice"})
+	require.NoError(t, err)
+	require.Contains(t, text, "Use the Greeter_greet tool.")
+	require.Contains(t, text, "- name: Alice")
+	require.NotContains(t, text, "- greeting:")
+}
+
+func TestMCPSubscriptions(t *testing.T) {
+	subs := newMCPSubscriptions()
+	const uri = "dagger://Foo/bar"
+	require.False(t, subs.subscribed(uri))
+	require.Empty(t, subs.observe(uri, "a"))
+
+	subs.subscribe(uri, "s1")
+	subs.subscribe(uri, "s2")
+	require.True(t, subs.subscribed(uri))
+	require.Empty(t, subs.observe(uri, "a"))
+	require.Empty(t, subs.observe(uri, "a"))
+	require.Equal(t, []string{"s1", "s2"}, subs.observe(uri, "b"))
+
+	subs.unsubscribe(uri, "s1")
+	require.Equal(t, []string{"s2"}, subs.observe(uri, "c"))
+	subs.unsubscribe(uri, "s2")
+	require.False(t, subs.subscribed(uri))
+}
+
+func TestMCPHandleMessage(t *testing.T) {
+	s := mcpServer{
+		MCPServer:        mcpserver.NewMCPServer("Dagger", "0.0.1"),
+		subs:             newMCPSubscriptions(),
+		workspaceChanged: make(chan struct{}, 1),
+	}
+
+	// subscriptions are handled alongside the messages mcp-go handles
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"resources/unsubscribe","params":{"uri":"dagger://Foo/bar"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/unsubscribe","params":{}}`,
+		`{"jsonrpc":"2.0","method":"` + engine.MCPWorkspaceChangedMethod + `"}`,
+		`{"jsonrpc":"2.0","method":"` + engine.MCPWorkspaceChangedMethod + `"}`,
+	}, "\n") + "\n"
+	var out bytes.Buffer
+	passed, err := io.ReadAll(s.interceptStdio(t.Context(), strings.NewReader(in), &out))
+	require.NoError(t, err)
+	require.Equal(t, `{"jsonrpc":"2.0","id":2,"method":"ping"}`+"\n", string(passed))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{}}`, lines[0])
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":3,"error":{"code":-32602,"message":"missing resource URI"}}`, lines[1])
+
+	// changes are coalesced while a reload is pending
+	require.Len(t, s.workspaceChanged, 1)
+
+	// over HTTP, the session is the client's
+	s.subs.subscribe("dagger://Foo/bar", "s1")
+	handler := s.interceptHTTP(http.NotFoundHandler())
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(
+		`{"jsonrpc":"2.0","id":"a","method":"resources/unsubscribe","params":{"uri":"dagger://Foo/bar"}}`))
+	req.Header.Set(mcpserver.HeaderKeySessionID, "s1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":"a","result":{}}`, rec.Body.String())
+	require.False(t, s.subs.subscribed("dagger://Foo/bar"))
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestMCPResourceEligible(t *testing.T) {
+	field := &ast.FieldDefinition{Name: "config", Type: ast.NonNullNamedType("File", nil)}
+	require.True(t, objectResourceEligible(LLMTool{Field: field, ReadOnly: true}))
+	// getters with side effects aren't resources
+	require.False(t, objectResourceEligible(LLMTool{Field: field}))
+
+	field = &ast.FieldDefinition{Name: "container", Type: ast.NonNullNamedType("Container", nil)}
+	require.False(t, objectResourceEligible(LLMTool{Field: field, ReadOnly: true}))
+
+	field = &ast.FieldDefinition{
+		Name:      "lines",
+		Type:      ast.NonNullListType(ast.NonNullNamedType("String", nil), nil),
+		Arguments: ast.ArgumentDefinitionList{{Name: "pattern", Type: ast.NonNullNamedType("String", nil)}},
+	}
+	require.False(t, objectResourceEligible(LLMTool{Field: field, ReadOnly: true}))
+}
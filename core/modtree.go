@@ -8,11 +8,14 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 
 	doublestar "github.com/bmatcuk/doublestar/v4"
 	"github.com/dagger/dagger/dagql"
 	"github.com/dagger/dagger/dagql/call"
 	"github.com/dagger/dagger/engine"
+	"github.com/dagger/dagger/engine/slog"
+	"github.com/dagger/dagger/engine/telemetryattrs"
 	telemetry "github.com/dagger/otel-go"
 	"github.com/dagger/querybuilder"
 
@@ -36,6 +39,8 @@ type ModTreeNode struct {
 	IsGenerator    bool
 	IsUp           bool
 	IsAgent        bool
+	// CheckRetries is how many times to retry the check before failing it.
+	CheckRetries int
 }
 
 func (node *ModTreeNode) Path() ModTreePath {
@@ -112,39 +117,50 @@ func (node *ModTreeNode) Run(
 	return jobs.Run(ctx) // don't suppress the error. That can be handled by the top-level caller if necessary
 }
 
-func (node *ModTreeNode) RunCheck(ctx context.Context, include, exclude []string) error {
+// RunCheck runs the checks under the node, returning the findings they
+// reported and the most times one of them ran, counting retries. Findings of
+// checks scaled out to another engine are not returned, though their errors
+// still fail them.
+func (node *ModTreeNode) RunCheck(ctx context.Context, include, exclude []string) ([]*CheckFinding, int, error) {
 	return node.runAsCheck(ctx,
 		func(n *ModTreeNode) bool { return n.IsCheck },
 		func(n *ModTreeNode, ctx context.Context) (bool, error) {
 			return node.tryRunCheckScaleOut(ctx)
 		},
-		func(n *ModTreeNode, ctx context.Context) error {
+		func(n *ModTreeNode, ctx context.Context) ([]*CheckFinding, error) {
 			return n.runCheckLocally(ctx)
 		},
 		include, exclude)
 }
 
-func (node *ModTreeNode) RunGeneratorAsCheck(ctx context.Context, include, exclude []string) error {
-	return node.runAsCheck(ctx,
+func (node *ModTreeNode) RunGeneratorAsCheck(ctx context.Context, include, exclude []string) (int, error) {
+	_, attempts, err := node.runAsCheck(ctx,
 		func(n *ModTreeNode) bool { return n.IsGenerator },
 		func(n *ModTreeNode, ctx context.Context) (bool, error) {
 			return n.tryRunGeneratorAsCheckScaleOut(ctx)
 		},
-		func(n *ModTreeNode, ctx context.Context) error {
-			return n.runGeneratorAsCheckLocally(ctx)
+		func(n *ModTreeNode, ctx context.Context) ([]*CheckFinding, error) {
+			return nil, n.runGeneratorAsCheckLocally(ctx)
 		},
 		include, exclude)
+	return attempts, err
 }
 
-// runAsCheck runs a leaf node as a check, with telemetry span and optional scale-out.
+// runAsCheck runs a leaf node as a check, with telemetry span and optional
+// scale-out. A failing leaf is run again up to its CheckRetries times, each
+// attempt in its own span under the check's; only the findings of its last
+// attempt are returned.
 func (node *ModTreeNode) runAsCheck(
 	ctx context.Context,
 	isLeaf func(*ModTreeNode) bool,
 	tryScaleOut func(*ModTreeNode, context.Context) (bool, error),
-	runLocally func(*ModTreeNode, context.Context) error,
+	runLocally func(*ModTreeNode, context.Context) ([]*CheckFinding, error),
 	include, exclude []string,
-) error {
-	return node.Run(ctx,
+) ([]*CheckFinding, int, error) {
+	var mu sync.Mutex
+	var findings []*CheckFinding
+	maxAttempts := 1
+	err := node.Run(ctx,
 		isLeaf,
 		func(ctx context.Context, n *ModTreeNode, clientMD *engine.ClientMetadata) (rerr error) {
 			// Try scale-out if enabled (will be false for scaled-out sessions)
@@ -160,13 +176,52 @@ func (node *ModTreeNode) runAsCheck(
 					attribute.String(telemetry.CheckNameAttr, n.PathString()),
 				),
 			)
+			attempts := 0
 			defer func() {
-				span.SetAttributes(attribute.Bool(telemetry.CheckPassedAttr, rerr == nil))
+				span.SetAttributes(
+					attribute.Bool(telemetry.CheckPassedAttr, rerr == nil),
+					attribute.Int(telemetryattrs.CheckAttemptsAttr, attempts),
+				)
+				if rerr == nil && attempts > 1 {
+					span.SetAttributes(attribute.Bool(telemetryattrs.CheckFlakyAttr, true))
+				}
 				telemetry.EndWithCause(span, &rerr)
 			}()
-			return runLocally(n, ctx)
+			var found []*CheckFinding
+			for {
+				attempts++
+				found, rerr = n.runCheckAttempt(ctx, attempts, runLocally)
+				if rerr == nil || attempts > n.CheckRetries || ctx.Err() != nil {
+					break
+				}
+			}
+			mu.Lock()
+			findings = append(findings, found...)
+			maxAttempts = max(maxAttempts, attempts)
+			mu.Unlock()
+			return rerr
 		},
 		include, exclude)
+	return findings, maxAttempts, err
+}
+
+// runCheckAttempt runs a check once. Attempts of a check that may be retried
+// get a span of their own, so that the logs of each can be told apart.
+func (node *ModTreeNode) runCheckAttempt(
+	ctx context.Context,
+	attempt int,
+	runLocally func(*ModTreeNode, context.Context) ([]*CheckFinding, error),
+) (_ []*CheckFinding, rerr error) {
+	if node.CheckRetries == 0 {
+		return runLocally(node, ctx)
+	}
+	ctx, span := Tracer(ctx).Start(ctx, fmt.Sprintf("attempt %d of %d", attempt, node.CheckRetries+1),
+		trace.WithAttributes(
+			attribute.Int(telemetryattrs.CheckAttemptAttr, attempt),
+		),
+	)
+	defer telemetry.EndWithCause(span, &rerr)
+	return runLocally(node, ctx)
 }
 
 func (node *ModTreeNode) runGeneratorAsCheckLocally(ctx context.Context) error {
@@ -231,10 +286,24 @@ func (node *ModTreeNode) tryRunGeneratorAsCheckScaleOut(ctx context.Context) (_
 	return true, nil
 }
 
-func (node *ModTreeNode) runCheckLocally(ctx context.Context) error {
+func (node *ModTreeNode) runCheckLocally(ctx context.Context) ([]*CheckFinding, error) {
 	var status dagql.AnyResult
 	if err := node.DagqlValue(ctx, &status); err != nil {
-		return err
+		return nil, err
+	}
+	if list, ok := dagql.UnwrapAs[dagql.Enumerable](status); ok {
+		// A check returning [CheckFinding!]! fails if any of them is an error
+		var findings []*CheckFinding
+		for i := 1; i <= list.Len(); i++ {
+			item, err := list.Nth(i)
+			if err != nil {
+				return nil, err
+			}
+			if finding, ok := dagql.UnwrapAs[*CheckFinding](item); ok {
+				findings = append(findings, finding)
+			}
+		}
+		return findings, checkFindingsError(findings)
 	}
 	if obj, ok := dagql.UnwrapAs[dagql.AnyObjectResult](status); ok {
 		// If the check returns a syncable type, sync it
@@ -247,12 +316,12 @@ func (node *ModTreeNode) runCheckLocally(ctx context.Context) error {
 					&status,
 					dagql.Selector{Field: "sync"},
 				); err != nil {
-					return err
+					return nil, err
 				}
 			}
 		}
 	}
-	return nil
+	return nil, nil
 }
 
 func (node *ModTreeNode) tryRunCheckScaleOut(ctx context.Context) (_ bool, rerr error) {
@@ -343,6 +412,18 @@ func (node *ModTreeNode) RunUp(ctx context.Context, include, exclude []string, p
 // It contains everything needed to display status and clean up after ctx cancellation.
 type runUpStartResult struct {
 	ReadySpan trace.Span
+
+	services *Services
+	tunnel   *RunningService
+}
+
+// Stop stops the service's host tunnel, so its host ports are free again by
+// the time Stop returns, and ends its ready span.
+func (r *runUpStartResult) Stop(ctx context.Context) {
+	defer r.ReadySpan.End()
+	if err := r.services.StopRunning(context.WithoutCancel(ctx), r.tunnel, false); err != nil {
+		slog.Warn("failed to stop service host tunnel", "error", err)
+	}
 }
 
 // runUpLocally evaluates the +up function, creates a host tunnel, starts the
@@ -467,7 +548,11 @@ func (node *ModTreeNode) runUpLocally(ctx context.Context, parentSpan trace.Span
 		),
 	)
 
-	return &runUpStartResult{ReadySpan: readySpan}, nil
+	return &runUpStartResult{
+		ReadySpan: readySpan,
+		services:  svcs,
+		tunnel:    runningSvc,
+	}, nil
 }
 
 func (node *ModTreeNode) RunGenerator(ctx context.Context, include, exclude []string) (dagql.ObjectResult[*Changeset], error) {
@@ -1021,6 +1106,7 @@ func (node *ModTreeNode) Children(ctx context.Context) ([]*ModTreeNode, error) {
 				OriginalModule: node.OriginalModule,
 				Type:           fn.ReturnType,
 				IsCheck:        fn.IsCheck,
+				CheckRetries:   fn.CheckRetries,
 				IsGenerator:    fn.IsGenerator,
 				IsUp:           fn.IsUp,
 				IsAgent:        fn.IsAgent,
@@ -1116,6 +1202,7 @@ type persistedModTreeNode struct {
 	OriginalModuleResultID uint64 `json:"originalModuleResultID,omitempty"`
 	TypeResultID           uint64 `json:"typeResultID,omitempty"`
 	IsCheck                bool   `json:"isCheck,omitempty"`
+	CheckRetries           int    `json:"checkRetries,omitempty"`
 	IsGenerator            bool   `json:"isGenerator,omitempty"`
 	IsUp                   bool   `json:"isUp,omitempty"`
 	IsAgent                bool   `json:"isAgent,omitempty"`
@@ -1157,14 +1244,15 @@ func (enc *persistedModTreeEncoder) Add(node *ModTreeNode) (int, error) {
 	id := len(enc.tree.Nodes) + 1
 	enc.ids[node] = id
 	persisted := persistedModTreeNode{
-		ID:          id,
-		ParentID:    parentID,
-		Name:        node.Name,
-		Description: node.Description,
-		IsCheck:     node.IsCheck,
-		IsGenerator: node.IsGenerator,
-		IsUp:        node.IsUp,
-		IsAgent:     node.IsAgent,
+		ID:           id,
+		ParentID:     parentID,
+		Name:         node.Name,
+		Description:  node.Description,
+		IsCheck:      node.IsCheck,
+		CheckRetries: node.CheckRetries,
+		IsGenerator:  node.IsGenerator,
+		IsUp:         node.IsUp,
+		IsAgent:      node.IsAgent,
 	}
 	if node.Module.Self() != nil {
 		moduleID, err := encodePersistedObjectRef(enc.cache, node.Module, "mod tree module")
@@ -1204,12 +1292,13 @@ func decodePersistedModTree(ctx context.Context, dag *dagql.Server, tree persist
 		}
 
 		node := &ModTreeNode{
-			Name:        persisted.Name,
-			Description: persisted.Description,
-			IsCheck:     persisted.IsCheck,
-			IsGenerator: persisted.IsGenerator,
-			IsUp:        persisted.IsUp,
-			IsAgent:     persisted.IsAgent,
+			Name:         persisted.Name,
+			Description:  persisted.Description,
+			IsCheck:      persisted.IsCheck,
+			CheckRetries: persisted.CheckRetries,
+			IsGenerator:  persisted.IsGenerator,
+			IsUp:         persisted.IsUp,
+			IsAgent:      persisted.IsAgent,
 		}
 		if persisted.ModuleResultID != 0 {
 			module, err := loadPersistedObjectResultByResultID[*Module](ctx, dag, persisted.ModuleResultID, "mod tree module")
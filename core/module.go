@@ -15,6 +15,7 @@ import (
 	"github.com/dagger/dagger/core/modules"
 	"github.com/dagger/dagger/dagql"
 	"github.com/dagger/dagger/dagql/call"
+	"github.com/dagger/dagger/engine"
 	"github.com/dagger/dagger/engine/slog"
 )
 
@@ -2338,8 +2339,22 @@ func (mod *userMod) ResultCallModule(ctx context.Context) (*dagql.ResultCallModu
 		return nil, fmt.Errorf("module provenance: implementation-scoped module %q is not attached", self.Name())
 	}
 
-	src := self.Source.Value.Self()
-	var ref, pin string
+	ref, pin, err := moduleSourceProvenance(self.Source.Value.Self())
+	if err != nil {
+		return nil, err
+	}
+
+	return &dagql.ResultCallModule{
+		ResultRef: &dagql.ResultCallRef{ResultID: scopedID.EngineResultID()},
+		Name:      self.Name(),
+		Ref:       ref,
+		Pin:       pin,
+	}, nil
+}
+
+// moduleSourceProvenance returns the ref and, for git sources, the pinned
+// commit identifying a module source in the results it produced.
+func moduleSourceProvenance(src *ModuleSource) (ref, pin string, _ error) {
 	switch src.Kind {
 	case ModuleSourceKindLocal:
 		ref = filepath.Join(src.Local.ContextDirectoryPath, src.SourceRootSubpath)
@@ -2354,15 +2369,24 @@ func (mod *userMod) ResultCallModule(ctx context.Context) (*dagql.ResultCallModu
 		pin = src.Git.Commit
 	case ModuleSourceKindDir:
 	default:
-		return nil, fmt.Errorf("module provenance: unexpected module source kind %q", src.Kind)
+		return "", "", fmt.Errorf("module provenance: unexpected module source kind %q", src.Kind)
 	}
+	return ref, pin, nil
+}
 
-	return &dagql.ResultCallModule{
-		ResultRef: &dagql.ResultCallRef{ResultID: scopedID.EngineResultID()},
-		Name:      self.Name(),
-		Ref:       ref,
-		Pin:       pin,
-	}, nil
+// CacheModule returns how the module is identified in the cache entries
+// produced while its functions run.
+func (mod *Module) CacheModule() (*engine.CacheModule, error) {
+	cacheMod := &engine.CacheModule{Name: mod.Name()}
+	if !mod.Source.Valid {
+		return cacheMod, nil
+	}
+	var err error
+	cacheMod.Ref, cacheMod.Pin, err = moduleSourceProvenance(mod.Source.Value.Self())
+	if err != nil {
+		return nil, err
+	}
+	return cacheMod, nil
 }
 
 func (mod *userMod) ModuleResult() dagql.ObjectResult[*Module] {
@@ -21,6 +21,7 @@ import (
 	"github.com/dagger/dagger/engine/clientdb"
 	"github.com/dagger/dagger/engine/engineutil"
 	serverresolver "github.com/dagger/dagger/engine/server/resolver"
+	"github.com/dagger/dagger/network"
 	"google.golang.org/grpc"
 )
 
@@ -92,6 +93,10 @@ type Server interface {
 	// for operations like generate that may be exactly what repairs the module.
 	EnsureWorkspaceModules(ctx context.Context, include []string, bestEffort bool) (loadFailures []string, _ error)
 
+	// The egress policy set in the workspace config for the module of the
+	// given name, or nil if none is.
+	ModuleEgressPolicy(ctx context.Context, modName string) (*network.EgressPolicy, error)
+
 	// A snapshot of the current workspace lockfile. When requireWritable is
 	// true, returns ok=false for read-only workspace lock sources.
 	CurrentWorkspaceLock(ctx context.Context, requireWritable bool) (*workspacepkg.Lock, bool, error)
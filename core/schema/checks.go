@@ -5,6 +5,7 @@ import (
 
 	"github.com/dagger/dagger/core"
 	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/util/checkshard"
 )
 
 type checksSchema struct{}
@@ -12,6 +13,29 @@ type checksSchema struct{}
 var _ SchemaResolvers = &checksSchema{}
 
 func (s checksSchema) Install(srv *dagql.Server) {
+	srv.InstallObject(dagql.NewClass[*core.CheckFinding](srv).View(AfterVersion("v1.0.0-0")))
+	srv.InstallObject(dagql.NewClass[*core.CheckInput](srv).View(AfterVersion("v1.0.0-0")))
+	core.CheckReportFormats.Install(srv, AfterVersion("v1.0.0-0"))
+	core.CheckFindingLevels.Install(srv, AfterVersion("v1.0.0-0"))
+
+	dagql.Fields[*core.Query]{
+		dagql.Func("checkFinding", s.checkFinding).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("Create a finding, for a lint check to report a problem.",
+				"A check function returning a list of findings fails if any of them is an error.").
+			Args(
+				dagql.Arg("message").Doc("A description of the problem."),
+				dagql.Arg("level").Doc("How serious the problem is."),
+				dagql.Arg("rule").Doc("The identifier of the rule that found the problem."),
+				dagql.Arg("path").Doc("The path of the file with the problem, relative to the root of the checked source."),
+				dagql.Arg("line").Doc("The line of the problem, starting at 1."),
+				dagql.Arg("column").Doc("The column of the problem, starting at 1."),
+			),
+	}.Install(srv)
+
+	dagql.Fields[*core.CheckFinding]{}.Install(srv)
+	dagql.Fields[*core.CheckInput]{}.Install(srv)
+
 	dagql.Fields[*core.CheckGroup]{
 		dagql.Func("list", s.list).
 			Doc("Return a list of individual checks and their details"),
@@ -23,7 +47,30 @@ func (s checksSchema) Install(srv *dagql.Server) {
 			),
 
 		dagql.Func("report", s.report).
-			Doc("Generate a markdown report"),
+			Doc("Generate a report of the checks and their results").
+			Args(
+				dagql.Arg("format").Doc("The format of the report.").View(AfterVersion("v1.0.0-0")),
+			),
+
+		dagql.Func("affectedBy", s.affectedBy).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("Return only the checks that the given changes can affect, going by the inputs of each check.").
+			Args(
+				dagql.Arg("changes").Doc("The changes to the workspace, e.g. from WorkspaceGit.changesSince."),
+			),
+
+		dagql.Func("shard", s.shard).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("Return only the checks in one shard of the group, to split the checks across the jobs of a CI run.",
+				"The split is deterministic: every job computes the same one from the same checks and timings.").
+			Args(
+				dagql.Arg("index").Doc("The shard to return, counting from 1."),
+				dagql.Arg("total").Doc("The number of shards to split the checks into."),
+				dagql.Arg("timings").Doc(
+					"A JSON file of how long each check took before, as written by 'dagger check --shard-timings', to balance the shards by duration.",
+					"Without it, the shards get the same number of checks.",
+				),
+			),
 	}.Install(srv)
 
 	// Check methods
@@ -44,6 +91,27 @@ func (s checksSchema) Install(srv *dagql.Server) {
 			Doc("An emoji representing the result of the check"),
 		dagql.Func("run", s.runSingleCheck).
 			Doc("Execute the check"),
+		dagql.Func("findings", s.findings).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("The findings reported by the check, if its function returns a list of findings"),
+		dagql.Func("retries", s.retries).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("How many times the check is retried when it fails, before failing it"),
+		dagql.Func("attempts", s.attempts).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("How many times the check ran, counting retries, or 0 if it hasn't run"),
+		dagql.Func("flaky", s.flaky).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("Whether the check failed at first but passed on a retry"),
+		dagql.Func("inputs", s.inputs).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("The parts of the workspace the check reads, as far as can be told without running it"),
+		dagql.Func("changedInputs", s.changedInputs).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("The paths changed by the given changes that the check reads").
+			Args(
+				dagql.Arg("changes").Doc("The changes to the workspace, e.g. from WorkspaceGit.changesSince."),
+			),
 	}.Install(srv)
 }
 
@@ -71,6 +139,18 @@ func (s checksSchema) resultEmoji(_ context.Context, parent *core.Check, args st
 	return parent.ResultEmoji(), nil
 }
 
+func (s checksSchema) retries(_ context.Context, parent *core.Check, args struct{}) (int, error) {
+	return parent.Retries(), nil
+}
+
+func (s checksSchema) attempts(_ context.Context, parent *core.Check, args struct{}) (int, error) {
+	return parent.Attempts, nil
+}
+
+func (s checksSchema) flaky(_ context.Context, parent *core.Check, args struct{}) (bool, error) {
+	return parent.Flaky, nil
+}
+
 func (s checksSchema) list(_ context.Context, parent *core.CheckGroup, args struct{}) ([]*core.Check, error) {
 	return parent.List(), nil
 }
@@ -81,8 +161,95 @@ func (s checksSchema) run(ctx context.Context, parent *core.CheckGroup, args str
 	return parent.Run(ctx, args.FailFast.GetOr(false).Bool())
 }
 
-func (s checksSchema) report(ctx context.Context, parent *core.CheckGroup, args struct{}) (dagql.ObjectResult[*core.File], error) {
-	return parent.Report(ctx)
+func (s checksSchema) report(ctx context.Context, parent *core.CheckGroup, args struct {
+	Format core.CheckReportFormat `default:"MARKDOWN"`
+}) (dagql.ObjectResult[*core.File], error) {
+	return parent.Report(ctx, args.Format)
+}
+
+func (s checksSchema) affectedBy(ctx context.Context, parent *core.CheckGroup, args struct {
+	Changes dagql.ID[*core.Changeset]
+}) (*core.CheckGroup, error) {
+	changes, err := loadChangeset(ctx, args.Changes)
+	if err != nil {
+		return nil, err
+	}
+	return parent.AffectedBy(ctx, changes)
+}
+
+func (s checksSchema) shard(ctx context.Context, parent *core.CheckGroup, args struct {
+	Index   int
+	Total   int
+	Timings dagql.Optional[core.FileID]
+}) (*core.CheckGroup, error) {
+	var timings checkshard.Timings
+	if args.Timings.Valid {
+		srv, err := core.CurrentDagqlServer(ctx)
+		if err != nil {
+			return nil, err
+		}
+		file, err := args.Timings.Value.Load(ctx, srv)
+		if err != nil {
+			return nil, err
+		}
+		contents, err := file.Self().Contents(ctx, file, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		timings, err = checkshard.ParseTimings(contents)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return parent.Shard(args.Index, args.Total, timings)
+}
+
+func (s checksSchema) inputs(_ context.Context, parent *core.Check, args struct{}) ([]*core.CheckInput, error) {
+	return parent.Inputs(), nil
+}
+
+func (s checksSchema) changedInputs(ctx context.Context, parent *core.Check, args struct {
+	Changes dagql.ID[*core.Changeset]
+}) ([]string, error) {
+	changes, err := loadChangeset(ctx, args.Changes)
+	if err != nil {
+		return nil, err
+	}
+	return parent.ChangedInputs(ctx, changes)
+}
+
+func loadChangeset(ctx context.Context, id dagql.ID[*core.Changeset]) (*core.Changeset, error) {
+	srv, err := core.CurrentDagqlServer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := id.Load(ctx, srv)
+	if err != nil {
+		return nil, err
+	}
+	return changes.Self(), nil
+}
+
+func (s checksSchema) findings(_ context.Context, parent *core.Check, args struct{}) ([]*core.CheckFinding, error) {
+	return parent.Findings, nil
+}
+
+func (s checksSchema) checkFinding(_ context.Context, _ *core.Query, args struct {
+	Message string
+	Level   core.CheckFindingLevel `default:"ERROR"`
+	Rule    string                 `default:""`
+	Path    string                 `default:""`
+	Line    int                    `default:"0"`
+	Column  int                    `default:"0"`
+}) (*core.CheckFinding, error) {
+	return &core.CheckFinding{
+		Message: args.Message,
+		Level:   args.Level,
+		Rule:    args.Rule,
+		Path:    args.Path,
+		Line:    args.Line,
+		Column:  args.Column,
+	}, nil
 }
 
 func (s checksSchema) runSingleCheck(ctx context.Context, parent *core.Check, args struct{}) (*core.Check, error) {
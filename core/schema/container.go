@@ -3,6 +3,7 @@ package schema
 import (
 	"cmp"
 	"context"
+	"crypto"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/containerd/platforms"
 	"github.com/dagger/dagger/internal/buildkit/frontend/dockerfile/shell"
+	"github.com/dagger/dagger/util/cosign"
 	"github.com/dagger/dagger/util/hashutil"
 	telemetry "github.com/dagger/otel-go"
 	"github.com/distribution/reference"
@@ -27,11 +29,13 @@ import (
 	"github.com/dagger/dagger/core"
 	"github.com/dagger/dagger/core/workspace"
 	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/dagql/call"
 	"github.com/dagger/dagger/engine"
 	"github.com/dagger/dagger/engine/engineutil"
 	serverresolver "github.com/dagger/dagger/engine/server/resolver"
 	"github.com/dagger/dagger/engine/slog"
 	bkcache "github.com/dagger/dagger/engine/snapshots"
+	"github.com/dagger/dagger/network"
 )
 
 type containerSchema struct{}
@@ -81,6 +85,16 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 					View(AfterVersion("v1.0.0-0")),
 				dagql.Arg("insecureSkipTLSVerify").Doc(`Allow HTTPS registry communication without verifying the server certificate.`).
 					View(AfterVersion("v1.0.0-0")),
+				dagql.Arg("signatureKey").Doc(
+					`PEM-encoded public key the image must be signed with.`,
+					`Signatures are looked up as cosign signatures attached to the image
+					as OCI referrers, as pushed by "cosign sign" or Container.publish.`).
+					View(AfterVersion("v1.0.0-0")),
+				dagql.Arg("requireSignature").Doc(
+					`Fail if the image has no signature of its digest.`,
+					`Requires signatureKey, which already makes a signature by that key
+					mandatory.`).
+					View(AfterVersion("v1.0.0-0")),
 			),
 		dagql.NodeFunc("build", s.build).
 			View(BeforeVersion("v0.19.0")).
@@ -665,6 +679,49 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 					`Skip the automatic init process injected into containers by default.`,
 					`Only use this if you specifically need the command to be pid 1 in the container. Otherwise it may result in unexpected behavior. If you're not sure, you don't need this.`,
 				),
+				dagql.Arg("timeout").
+					View(AfterVersion("v1.0.0-0")).
+					Doc(`Kill the command and its child processes if it runs longer than this duration. Example: "5m"`,
+						`Defaults to the container's default exec timeout (see "withDefaultExecTimeout"). A timed out command fails with an exec error that includes the output produced so far, unless "expect" is TIMEOUT.`),
+			),
+
+		dagql.NodeFunc("withDefaultExecTimeout", s.withDefaultExecTimeout).
+			View(AfterVersion("v1.0.0-0")).
+			Doc(`Set the default timeout for commands executed with withExec.`).
+			Args(
+				dagql.Arg("timeout").Doc(`Maximum duration a command may run before it is killed. Example: "30m". An empty string or "0s" disables the default timeout.`),
+			),
+
+		dagql.NodeFunc("withResourceLimits", s.withResourceLimits).
+			View(AfterVersion("v1.0.0-0")).
+			Doc(`Limit the CPU, memory and process count available to commands and services run in this container.`,
+				`Replaces any limits set previously. A command that exceeds its memory limit is killed and fails with an exec error reporting the peak memory it used.`).
+			Args(
+				dagql.Arg("cpu").Doc(`CPU time available, as a number of CPUs (e.g. "1.5") or millicpus (e.g. "500m"). Unlimited if empty.`),
+				dagql.Arg("memory").Doc(`Maximum memory, in bytes or with a unit suffix (e.g. "512m", "2GiB"). Unlimited if empty.`),
+				dagql.Arg("pids").Doc(`Maximum number of processes running at once. Unlimited if zero.`),
+			),
+
+		dagql.NodeFunc("withSecurityProfile", s.withSecurityProfile).
+			View(AfterVersion("v1.0.0-0")).
+			Doc(`Restrict what commands and services run in this container are allowed to do.`,
+				`Replaces any profile set previously. The engine's default security profile still applies: it can be tightened, but not loosened, and a profile violating the engine's security policy makes commands fail.`).
+			Args(
+				dagql.Arg("seccompProfile").Doc(`A seccomp profile, in the JSON format used by Docker, layered on top of the default one: syscalls are only allowed if both allow them.`),
+				dagql.Arg("capAdd").Doc(`Capabilities to add to the default set (e.g. "NET_BIND_SERVICE" or "CAP_NET_BIND_SERVICE"). Capabilities outside of the default set, like "SYS_ADMIN", require the engine to allow insecure root capabilities.`),
+				dagql.Arg("capDrop").Doc(`Capabilities to drop from the default set (e.g. "NET_RAW"), or "ALL" to drop them all.`),
+				dagql.Arg("readOnlyRootfs").Doc(`Mount the root filesystem read-only. Mounted directories, files and caches stay writable.`),
+				dagql.Arg("noNewPrivileges").Doc(`Prevent processes from gaining privileges, e.g. through setuid binaries.`),
+			),
+
+		dagql.NodeFunc("withEgressPolicy", s.withEgressPolicy).
+			View(AfterVersion("v1.0.0-0")).
+			Doc(`Restrict the destinations commands and services run in this container can connect to.`,
+				`Anything not allowed is denied, so a policy without arguments denies all egress. Services bound to the container stay reachable.`,
+				`Replaces any policy set previously. The egress policies of the engine and of the module running the container still apply. Blocked connections are recorded as events of the command's span.`).
+			Args(
+				dagql.Arg("allow").Doc(`Domains, IP addresses and CIDRs that can be reached (e.g. "github.com", "10.0.0.0/8"). Domains are resolved when commands start.`),
+				dagql.Arg("allowRegistries").Doc(`Allow reaching well-known public container registries and the registries configured in the engine.`),
 			),
 
 		dagql.NodeFunc("stdout", s.stdout).
@@ -750,6 +807,24 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 					View(AfterVersion("v1.0.0-0")),
 				dagql.Arg("insecureSkipTLSVerify").Doc(`Allow HTTPS registry communication without verifying the server certificate.`).
 					View(AfterVersion("v1.0.0-0")),
+				dagql.Arg("sbom").Doc(
+					`Attach a software bill of materials for each platform, generated
+					from the package databases of its root filesystem, in the given format.`).
+					View(AfterVersion("v1.0.0-0")),
+				dagql.Arg("provenance").Doc(
+					`Attach a SLSA provenance attestation for each platform, describing
+					the calls that built it and the images, git repositories and HTTP
+					resources it was built from.`).
+					View(AfterVersion("v1.0.0-0")),
+				dagql.Arg("signingKey").Doc(
+					`PEM-encoded private key to sign the published image with.`,
+					`The signature is in the cosign format and is pushed as an OCI
+					referrer of the image, so "cosign verify" and Container.from can check
+					it. Encrypted keys generated by "cosign generate-key-pair" are supported.`).
+					View(AfterVersion("v1.0.0-0")),
+				dagql.Arg("signingKeyPassword").Doc(
+					`Password to decrypt the signing key with.`).
+					View(AfterVersion("v1.0.0-0")),
 			),
 
 		dagql.NodeFunc("platform", s.platform).
@@ -783,6 +858,15 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 				dagql.Arg("expand").Doc(
 					`Replace "${VAR}" or "$VAR" in the value of path according to the current `+
 						`environment variables defined in the container (e.g. "/$VAR/foo").`),
+				dagql.Arg("sbom").Doc(
+					`Attach a software bill of materials for each platform, generated
+					from the package databases of its root filesystem, in the given format.`).
+					View(AfterVersion("v1.0.0-0")),
+				dagql.Arg("provenance").Doc(
+					`Attach a SLSA provenance attestation for each platform, describing
+					the calls that built it and the images, git repositories and HTTP
+					resources it was built from.`).
+					View(AfterVersion("v1.0.0-0")),
 			),
 		dagql.NodeFunc("export", s.exportLegacy).
 			WithInput(dagql.PerCallInput).
@@ -829,6 +913,15 @@ func (s *containerSchema) Install(srv *dagql.Server) {
 					`Defaults to OCI, which is largely compatible with most recent
 					container runtimes, but Docker may be needed for older runtimes without
 					OCI support.`),
+				dagql.Arg("sbom").Doc(
+					`Attach a software bill of materials for each platform, generated
+					from the package databases of its root filesystem, in the given format.`).
+					View(AfterVersion("v1.0.0-0")),
+				dagql.Arg("provenance").Doc(
+					`Attach a SLSA provenance attestation for each platform, describing
+					the calls that built it and the images, git repositories and HTTP
+					resources it was built from.`).
+					View(AfterVersion("v1.0.0-0")),
 			),
 
 		dagql.NodeFunc("import", s.import_).
@@ -1030,7 +1123,9 @@ type containerFromArgs struct {
 	Address               string
 	RegistryService       dagql.Optional[core.ServiceID]
 	Protocol              dagql.Optional[core.RegistryProtocol]
-	InsecureSkipTLSVerify bool `name:"insecureSkipTLSVerify" default:"false"`
+	InsecureSkipTLSVerify bool   `name:"insecureSkipTLSVerify" default:"false"`
+	SignatureKey          string `default:""`
+	RequireSignature      bool   `default:"false"`
 }
 
 func registryTransportFromArgs(protocol dagql.Optional[core.RegistryProtocol], insecureSkipTLSVerify bool) (serverresolver.RegistryTransport, error) {
@@ -1119,6 +1214,10 @@ func (s *containerSchema) from(ctx context.Context, parent dagql.ObjectResult[*c
 	if err != nil {
 		return inst, err
 	}
+	signaturePolicy, err := core.ParseImageSignaturePolicy(args.SignatureKey, args.RequireSignature)
+	if err != nil {
+		return inst, err
+	}
 	platform := parent.Self().Platform
 	var registryServices core.ServiceBindings
 
@@ -1165,6 +1264,10 @@ func (s *containerSchema) from(ctx context.Context, parent dagql.ObjectResult[*c
 			SystemEnvNames:     slices.Clone(parent.Self().SystemEnvNames),
 			VolatileEnv:        slices.Clone(parent.Self().VolatileEnv),
 			DefaultArgs:        parent.Self().DefaultArgs,
+			DefaultExecTimeout: parent.Self().DefaultExecTimeout,
+			ResourceLimits:     parent.Self().ResourceLimits,
+			SecurityProfile:    parent.Self().SecurityProfile,
+			EgressPolicy:       parent.Self().EgressPolicy,
 		}
 
 		refStr := refName.String()
@@ -1174,6 +1277,12 @@ func (s *containerSchema) from(ctx context.Context, parent dagql.ObjectResult[*c
 		}
 		defer detach()
 
+		if signaturePolicy.Enabled() {
+			if err := core.VerifyImageSignature(ctx, refName, signaturePolicy, network, registryTransport); err != nil {
+				return inst, err
+			}
+		}
+
 		_, _, cfgBytes, err := rslvr.ResolveImageConfig(ctx, refStr, serverresolver.ResolveImageConfigOpts{
 			Platform:          ptr(platform.Spec()),
 			ResolveMode:       serverresolver.ResolveModeDefault,
@@ -1345,6 +1454,18 @@ func (s *containerSchema) from(ctx context.Context, parent dagql.ObjectResult[*c
 			Value: dagql.Boolean(true),
 		})
 	}
+	if args.SignatureKey != "" {
+		fromArgs = append(fromArgs, dagql.NamedInput{
+			Name:  "signatureKey",
+			Value: dagql.String(args.SignatureKey),
+		})
+	}
+	if args.RequireSignature {
+		fromArgs = append(fromArgs, dagql.NamedInput{
+			Name:  "requireSignature",
+			Value: dagql.Boolean(true),
+		})
+	}
 	err = srv.Select(ctx, parent, &inst,
 		dagql.Selector{
 			Field: "from",
@@ -1452,6 +1573,10 @@ func (s *containerSchema) withRootfs(ctx context.Context, parent dagql.ObjectRes
 		SystemEnvNames:     slices.Clone(parent.Self().SystemEnvNames),
 		VolatileEnv:        slices.Clone(parent.Self().VolatileEnv),
 		DefaultArgs:        parent.Self().DefaultArgs,
+		DefaultExecTimeout: parent.Self().DefaultExecTimeout,
+		ResourceLimits:     parent.Self().ResourceLimits,
+		SecurityProfile:    parent.Self().SecurityProfile,
+		EgressPolicy:       parent.Self().EgressPolicy,
 		Lazy: &core.ContainerWithRootFSLazy{
 			LazyState: core.NewLazyState(),
 			Parent:    parent,
@@ -1527,6 +1652,11 @@ func (s *containerSchema) withExec(ctx context.Context, parent dagql.ObjectResul
 		args.UseEntrypoint = !*args.SkipEntrypoint
 	}
 
+	// fail on a bad timeout now, rather than once the exec is evaluated
+	if _, err := parent.Self().ExecTimeout(args.ContainerExecOpts); err != nil {
+		return inst, err
+	}
+
 	var md *engineutil.ExecutionMetadata
 	if args.ExecMD.Self != nil {
 		md = args.ExecMD.Self
@@ -1705,6 +1835,10 @@ func (s *containerSchema) withSymlink(ctx context.Context, parent dagql.ObjectRe
 		SystemEnvNames:     slices.Clone(parent.Self().SystemEnvNames),
 		VolatileEnv:        slices.Clone(parent.Self().VolatileEnv),
 		DefaultArgs:        parent.Self().DefaultArgs,
+		DefaultExecTimeout: parent.Self().DefaultExecTimeout,
+		ResourceLimits:     parent.Self().ResourceLimits,
+		SecurityProfile:    parent.Self().SecurityProfile,
+		EgressPolicy:       parent.Self().EgressPolicy,
 		Lazy: &core.ContainerWithSymlinkLazy{
 			LazyState: core.NewLazyState(),
 			Parent:    parent,
@@ -2429,6 +2563,10 @@ func (s *containerSchema) withMountedDirectory(ctx context.Context, parent dagql
 		SystemEnvNames:     slices.Clone(parent.Self().SystemEnvNames),
 		VolatileEnv:        slices.Clone(parent.Self().VolatileEnv),
 		DefaultArgs:        parent.Self().DefaultArgs,
+		DefaultExecTimeout: parent.Self().DefaultExecTimeout,
+		ResourceLimits:     parent.Self().ResourceLimits,
+		SecurityProfile:    parent.Self().SecurityProfile,
+		EgressPolicy:       parent.Self().EgressPolicy,
 		Lazy: &core.ContainerWithMountedDirectoryLazy{
 			LazyState: core.NewLazyState(),
 			Parent:    parent,
@@ -2546,6 +2684,43 @@ type containerPublishArgs struct {
 	RegistryService       dagql.Optional[core.ServiceID]
 	Protocol              dagql.Optional[core.RegistryProtocol]
 	InsecureSkipTLSVerify bool `name:"insecureSkipTLSVerify" default:"false"`
+	ImageAttestationArgs
+	SigningKey         dagql.Optional[core.SecretID]
+	SigningKeyPassword dagql.Optional[core.SecretID]
+}
+
+// signer loads the key to sign the published image with, if any.
+func (args containerPublishArgs) signer(ctx context.Context, srv *dagql.Server) (crypto.Signer, error) {
+	if !args.SigningKey.Valid {
+		if args.SigningKeyPassword.Valid {
+			return nil, errors.New("signingKeyPassword requires signingKey")
+		}
+		return nil, nil
+	}
+	key, err := args.SigningKey.Value.Load(ctx, srv)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := key.Self().Plaintext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("signing key: %w", err)
+	}
+	var password []byte
+	if args.SigningKeyPassword.Valid {
+		secret, err := args.SigningKeyPassword.Value.Load(ctx, srv)
+		if err != nil {
+			return nil, err
+		}
+		password, err = secret.Self().Plaintext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("signing key password: %w", err)
+		}
+	}
+	signer, err := cosign.LoadPrivateKey(keyPEM, password)
+	if err != nil {
+		return nil, fmt.Errorf("signing key: %w", err)
+	}
+	return signer, nil
 }
 
 func (s *containerSchema) publish(ctx context.Context, parent dagql.ObjectResult[*core.Container], args containerPublishArgs) (dagql.String, error) {
@@ -2592,6 +2767,14 @@ func (s *containerSchema) publish(ctx context.Context, parent dagql.ObjectResult
 			variants = append(variants, variant.Self())
 		}
 	}
+	attest, err := args.ImageAttestationArgs.opts(ctx, parent, variantResults)
+	if err != nil {
+		return "", err
+	}
+	signer, err := args.signer(ctx, srv)
+	if err != nil {
+		return "", err
+	}
 	ref, err := parent.Self().Publish(
 		ctx,
 		args.Address.String(),
@@ -2600,6 +2783,8 @@ func (s *containerSchema) publish(ctx context.Context, parent dagql.ObjectResult
 		args.MediaTypes,
 		registryServices,
 		registryTransport,
+		attest,
+		signer,
 	)
 	if err != nil {
 		return "", err
@@ -3343,6 +3528,10 @@ func cloneContainerForSchemaChild(ctx context.Context, parent dagql.ObjectResult
 		SystemEnvNames:     slices.Clone(parent.Self().SystemEnvNames),
 		VolatileEnv:        slices.Clone(parent.Self().VolatileEnv),
 		DefaultArgs:        parent.Self().DefaultArgs,
+		DefaultExecTimeout: parent.Self().DefaultExecTimeout,
+		ResourceLimits:     parent.Self().ResourceLimits,
+		SecurityProfile:    parent.Self().SecurityProfile,
+		EgressPolicy:       parent.Self().EgressPolicy,
 	}
 	return ctr, parentPendingLazy, nil
 }
@@ -4007,6 +4196,7 @@ type containerExportArgs struct {
 	ForcedCompression dagql.Optional[core.ImageLayerCompression]
 	MediaTypes        core.ImageMediaTypes `default:"OCI"`
 	Expand            bool                 `default:"false"`
+	ImageAttestationArgs
 }
 
 func (s *containerSchema) export(ctx context.Context, parent dagql.ObjectResult[*core.Container], args containerExportArgs) (dagql.String, error) {
@@ -4047,6 +4237,10 @@ func (s *containerSchema) export(ctx context.Context, parent dagql.ObjectResult[
 	if err != nil {
 		return "", err
 	}
+	attest, err := args.ImageAttestationArgs.opts(ctx, parent, variantResults)
+	if err != nil {
+		return "", err
+	}
 
 	_, err = parent.Self().Export(
 		ctx,
@@ -4056,6 +4250,7 @@ func (s *containerSchema) export(ctx context.Context, parent dagql.ObjectResult[
 			ForcedCompression: args.ForcedCompression.Value,
 			MediaTypes:        args.MediaTypes,
 			Tar:               true,
+			Attestations:      attest,
 		},
 	)
 	if err != nil {
@@ -4084,6 +4279,36 @@ type containerAsTarballArgs struct {
 	PlatformVariants  []core.ContainerID `default:"[]"`
 	ForcedCompression dagql.Optional[core.ImageLayerCompression]
 	MediaTypes        core.ImageMediaTypes `default:"OCI"`
+	ImageAttestationArgs
+}
+
+type ImageAttestationArgs struct {
+	SBOM       dagql.Optional[core.ImageSBOMFormat] `name:"sbom"`
+	Provenance bool                                 `default:"false"`
+}
+
+// opts resolves the attestation args against the exported containers, whose
+// recipes are recorded in provenance.
+func (args ImageAttestationArgs) opts(ctx context.Context, parent dagql.ObjectResult[*core.Container], variants []dagql.ObjectResult[*core.Container]) (core.ImageAttestationOpts, error) {
+	opts := core.ImageAttestationOpts{
+		SBOM:       args.SBOM.Value,
+		Provenance: args.Provenance,
+	}
+	if !args.SBOM.Valid && !args.Provenance {
+		return opts, nil
+	}
+	opts.RecipeIDs = map[*core.Container]*call.ID{}
+	for _, ctr := range append([]dagql.ObjectResult[*core.Container]{parent}, variants...) {
+		if ctr.Self() == nil {
+			continue
+		}
+		id, err := ctr.RecipeID(ctx)
+		if err != nil {
+			return opts, fmt.Errorf("container recipe ID: %w", err)
+		}
+		opts.RecipeIDs[ctr.Self()] = id
+	}
+	return opts, nil
 }
 
 func (s *containerSchema) asTarball(
@@ -4124,10 +4349,15 @@ func (s *containerSchema) asTarball(
 		}
 	}
 
+	attest, err := args.ImageAttestationArgs.opts(ctx, parent, platformVariantResults)
+	if err != nil {
+		return inst, err
+	}
 	f, err := parent.Self().AsTarball(ctx, platformVariants,
 		args.ForcedCompression.Value,
 		args.MediaTypes,
 		"container.tar",
+		attest,
 	)
 	if err != nil {
 		return inst, err
@@ -4531,6 +4761,145 @@ func (s *containerSchema) withDefaultTerminalCmd(
 	return ctr, nil
 }
 
+type containerWithDefaultExecTimeoutArgs struct {
+	Timeout string
+}
+
+func (s *containerSchema) withDefaultExecTimeout(
+	ctx context.Context,
+	parent dagql.ObjectResult[*core.Container],
+	args containerWithDefaultExecTimeoutArgs,
+) (*core.Container, error) {
+	timeout, err := core.ParseExecTimeout(args.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	ctr, parentPendingLazy, err := cloneContainerForSchemaChild(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	ctr.DefaultExecTimeout = timeout
+	if parentPendingLazy {
+		ctr.Lazy = &core.ContainerWithDefaultExecTimeoutLazy{
+			LazyState: core.NewLazyState(),
+			Parent:    parent,
+			Timeout:   timeout,
+		}
+	}
+	return ctr, nil
+}
+
+type containerWithResourceLimitsArgs struct {
+	CPU    string `name:"cpu" default:""`
+	Memory string `default:""`
+	Pids   int    `default:"0"`
+}
+
+func (s *containerSchema) withResourceLimits(
+	ctx context.Context,
+	parent dagql.ObjectResult[*core.Container],
+	args containerWithResourceLimitsArgs,
+) (*core.Container, error) {
+	limits, err := core.ParseResourceLimits(args.CPU, args.Memory, args.Pids)
+	if err != nil {
+		return nil, err
+	}
+	ctr, parentPendingLazy, err := cloneContainerForSchemaChild(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	ctr.ResourceLimits = limits
+	if parentPendingLazy {
+		ctr.Lazy = &core.ContainerWithResourceLimitsLazy{
+			LazyState: core.NewLazyState(),
+			Parent:    parent,
+			Limits:    limits,
+		}
+	}
+	return ctr, nil
+}
+
+type containerWithSecurityProfileArgs struct {
+	SeccompProfile  dagql.Optional[core.FileID]
+	CapAdd          []string `default:"[]"`
+	CapDrop         []string `default:"[]"`
+	ReadOnlyRootfs  bool     `default:"false"`
+	NoNewPrivileges bool     `default:"false"`
+}
+
+func (s *containerSchema) withSecurityProfile(
+	ctx context.Context,
+	parent dagql.ObjectResult[*core.Container],
+	args containerWithSecurityProfileArgs,
+) (*core.Container, error) {
+	var seccompProfile string
+	if args.SeccompProfile.Valid {
+		srv, err := core.CurrentDagqlServer(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get server: %w", err)
+		}
+		file, err := args.SeccompProfile.Value.Load(ctx, srv)
+		if err != nil {
+			return nil, err
+		}
+		contents, err := file.Self().Contents(ctx, file, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("read seccomp profile: %w", err)
+		}
+		seccompProfile = string(contents)
+	}
+	profile, err := core.ParseSecurityProfile(seccompProfile, args.CapAdd, args.CapDrop, args.ReadOnlyRootfs, args.NoNewPrivileges)
+	if err != nil {
+		return nil, err
+	}
+	ctr, parentPendingLazy, err := cloneContainerForSchemaChild(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	ctr.SecurityProfile = profile
+	if parentPendingLazy {
+		ctr.Lazy = &core.ContainerWithSecurityProfileLazy{
+			LazyState: core.NewLazyState(),
+			Parent:    parent,
+			Profile:   profile,
+		}
+	}
+	return ctr, nil
+}
+
+type containerWithEgressPolicyArgs struct {
+	Allow           []string `default:"[]"`
+	AllowRegistries bool     `default:"false"`
+}
+
+func (s *containerSchema) withEgressPolicy(
+	ctx context.Context,
+	parent dagql.ObjectResult[*core.Container],
+	args containerWithEgressPolicyArgs,
+) (*core.Container, error) {
+	policy := network.EgressPolicy{
+		Allow:           args.Allow,
+		AllowRegistries: args.AllowRegistries,
+		Source:          "container",
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	ctr, parentPendingLazy, err := cloneContainerForSchemaChild(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	ctr.EgressPolicy = &policy
+	if parentPendingLazy {
+		ctr.Lazy = &core.ContainerWithEgressPolicyLazy{
+			LazyState: core.NewLazyState(),
+			Parent:    parent,
+			Policy:    policy,
+		}
+	}
+	return ctr, nil
+}
+
 type containerTerminalArgs struct {
 	core.TerminalArgs
 }
@@ -3,10 +3,12 @@ package schema
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/dagger/dagger/core"
 	"github.com/dagger/dagger/dagql"
 	"github.com/dagger/dagger/internal/buildkit/identity"
+	"github.com/opencontainers/go-digest"
 )
 
 type engineSchema struct{}
@@ -55,14 +57,49 @@ func (s *engineSchema) Install(srv *dagql.Server) {
 					Doc("Override the structural metadata estimate to target in absolute bytes. Explicit values must be positive and lower than the resolved maximum; the configured/default value is used when omitted.").
 					View(AfterVersion("v1.0.0-0")),
 			),
+		dagql.Func("pruneEntries", s.cachePruneEntries).
+			View(AfterVersion("v1.0.0-0")).
+			DoNotCache("Mutates mutable state").
+			Doc("Prune the releasable cache entries matching all of the given filters, or all releasable entries if none are given.",
+				"Returns the entries pruned, or with dryRun the entries that would be pruned, with the disk space they free.").
+			Args(
+				dagql.Arg("module").Doc("Only prune the results of function calls to one of these modules, named by name, source ref with or without version (e.g. \"github.com/dagger/jest\"), or pinned commit."),
+				dagql.Arg("field").Doc("Only prune the results of one of these fields, named with their type (e.g. \"Container.from\") or alone (e.g. \"from\")."),
+				dagql.Arg("tag").Doc("Only prune the entries produced by clients with one of these cache tags."),
+				dagql.Arg("olderThan").Doc("Only prune the entries not used for at least this long (e.g. \"72h\")."),
+				dagql.Arg("dryRun").Doc("Report the entries that would be pruned, without pruning them."),
+			),
+		dagql.Func("whyMiss", s.cacheWhyMiss).
+			View(AfterVersion("v1.0.0-0")).
+			DoNotCache("Explains the current state of the cache").
+			Doc("Explain why a call executed instead of reusing a cached result, by comparing it with the most similar call of the same field cached before it. The call must still be in the cache.").
+			Args(
+				dagql.Arg("digest").Doc("The digest of the call, as recorded on its span."),
+			),
+	}.Install(srv)
+
+	srv.InstallObject(dagql.NewClass[*core.EngineCacheMissExplanation](srv).View(AfterVersion("v1.0.0-0")))
+	srv.InstallObject(dagql.NewClass[*core.EngineCacheMissDifference](srv).View(AfterVersion("v1.0.0-0")))
+	dagql.Fields[*core.EngineCacheMissExplanation]{
+		dagql.Func("differences", s.cacheMissDifferences).
+			Doc("The inputs that differ from the nearest cached call. Differing objects are compared in turn, down to the inputs that diverged first. Empty when an identical call was cached but couldn't be reused, because it expired or needs secrets or sockets the client hasn't loaded."),
 	}.Install(srv)
 
+	dagql.Fields[*core.EngineCacheMissDifference]{}.Install(srv)
+
 	dagql.Fields[*core.EngineCacheEntrySet]{
 		dagql.Func("entries", s.cacheEntrySetEntries).
 			Doc("The list of individual cache entries in the set"),
 	}.Install(srv)
 
-	dagql.Fields[*core.EngineCacheEntry]{}.Install(srv)
+	dagql.Fields[*core.EngineCacheEntry]{
+		dagql.Func("module", s.cacheEntryModule).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("The source ref, or the name of a module without one, of the module whose function call produced this cache entry, if any."),
+		dagql.Func("tags", s.cacheEntryTags).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("The cache tags of the client that produced this cache entry."),
+	}.Install(srv)
 }
 
 func (s *engineSchema) engine(ctx context.Context, parent *core.Query, args struct{}) (*core.Engine, error) {
@@ -175,6 +212,45 @@ func (s *engineSchema) cachePrune(ctx context.Context, parent *core.EngineCache,
 	return void, nil
 }
 
+func (s *engineSchema) cachePruneEntries(ctx context.Context, parent *core.EngineCache, args struct {
+	Module    []string `default:"[]"`
+	Field     []string `default:"[]"`
+	Tag       []string `default:"[]"`
+	OlderThan string   `default:""`
+	DryRun    bool     `default:"false"`
+}) (*core.EngineCacheEntrySet, error) {
+	query, err := core.CurrentQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := query.RequireMainClient(ctx); err != nil {
+		return nil, err
+	}
+
+	var olderThan time.Duration
+	if args.OlderThan != "" {
+		olderThan, err = time.ParseDuration(args.OlderThan)
+		if err != nil {
+			return nil, fmt.Errorf("invalid olderThan duration %q: %w", args.OlderThan, err)
+		}
+		if olderThan < 0 {
+			return nil, fmt.Errorf("olderThan must not be negative, got %s", olderThan)
+		}
+	}
+
+	entrySet, err := query.PruneEngineLocalCacheEntries(ctx, core.EngineCachePruneOptions{
+		Modules:   args.Module,
+		Fields:    args.Field,
+		Tags:      args.Tag,
+		OlderThan: olderThan,
+		DryRun:    args.DryRun,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune cache entries: %w", err)
+	}
+	return entrySet, nil
+}
+
 func optionalInt64(value dagql.Optional[dagql.Int]) *int64 {
 	if !value.Valid {
 		return nil
@@ -183,6 +259,56 @@ func optionalInt64(value dagql.Optional[dagql.Int]) *int64 {
 	return &resolved
 }
 
+func (s *engineSchema) cacheWhyMiss(ctx context.Context, parent *core.EngineCache, args struct {
+	Digest string
+}) (*core.EngineCacheMissExplanation, error) {
+	query, err := core.CurrentQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := query.RequireMainClient(ctx); err != nil {
+		return nil, err
+	}
+	dig, err := digest.Parse(args.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid call digest %q: %w", args.Digest, err)
+	}
+	cache, err := dagql.EngineCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	explanation, err := cache.ExplainMiss(ctx, dig)
+	if err != nil {
+		return nil, err
+	}
+	diffs := make([]*core.EngineCacheMissDifference, 0, len(explanation.Differences))
+	for _, diff := range explanation.Differences {
+		diffs = append(diffs, &core.EngineCacheMissDifference{
+			Call:    diff.Call,
+			Input:   diff.Input,
+			Cached:  diff.Cached,
+			Current: diff.Current,
+		})
+	}
+	return &core.EngineCacheMissExplanation{
+		Call:            explanation.Call.String(),
+		Nearest:         explanation.Nearest.String(),
+		DifferencesList: diffs,
+	}, nil
+}
+
+func (s *engineSchema) cacheMissDifferences(ctx context.Context, parent *core.EngineCacheMissExplanation, args struct{}) (dagql.Array[*core.EngineCacheMissDifference], error) {
+	return parent.DifferencesList, nil
+}
+
+func (s *engineSchema) cacheEntryModule(ctx context.Context, parent *core.EngineCacheEntry, args struct{}) (dagql.String, error) {
+	return dagql.NewString(parent.Module), nil
+}
+
+func (s *engineSchema) cacheEntryTags(ctx context.Context, parent *core.EngineCacheEntry, args struct{}) (dagql.Array[dagql.String], error) {
+	return dagql.NewStringArray(parent.Tags...), nil
+}
+
 func (s *engineSchema) cacheEntrySetEntries(ctx context.Context, parent *core.EngineCacheEntrySet, args struct{}) (dagql.Array[*core.EngineCacheEntry], error) {
 	return parent.EntriesList, nil
 }
@@ -2,7 +2,10 @@ package schema
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 
 	"github.com/dagger/dagger/core"
 	"github.com/dagger/dagger/dagql"
@@ -77,19 +80,50 @@ func (s llmSchema) Install(srv *dagql.Server) {
 				dagql.Arg("effort").Doc(
 					`The reasoning effort, e.g. "low", "medium", or "high"; "none" disables reasoning. Supported levels are model-specific — some models also accept e.g. "minimal", "xhigh", or "max".`),
 			),
+		dagql.Func("withBudget", s.withBudget).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("Set a budget for the conversation, counted over all of its API calls as reported by tokenUsage. Once any limit is reached, step and loop fail with an LLM_BUDGET_EXCEEDED error carrying the final transcript, without calling the model again.").
+			Args(
+				dagql.Arg("maxInputTokens").Doc("Cap the input tokens, cached or not. Zero means no limit."),
+				dagql.Arg("maxOutputTokens").Doc("Cap the output tokens. Each step's output is also capped to what's left. Zero means no limit."),
+				dagql.Arg("maxCostUSD").Doc("Cap the cost in US dollars, priced from the model catalog at the rates of the current model. Zero means no limit."),
+			),
+		dagql.Func("withToolPolicy", s.withToolPolicy).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("Add a policy approving the model's tool calls. The last policy added that matches a tool applies, unless a [[llm.tools]] policy of the workspace matching it is more restrictive; with none matching, the call is allowed. A refused call is reported to the model as a failed tool call.").
+			Args(
+				dagql.Arg("tool").Doc(`A glob pattern matched against the tool name, e.g. "withExec" or "publish*". Tools named after a bound object or MCP server match both with and without that prefix.`),
+				dagql.Arg("action").Doc(`"allow" to make the call, "ask" to ask the user first (a client that can't prompt denies it), or "deny" to refuse it.`),
+				dagql.Arg("readOnly").Doc("Only apply the policy to read-only tools."),
+			),
+		dagql.Func("withOutputSchema", s.withOutputSchema).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("Require the model's final reply to be a JSON value matching a schema, enforced with the provider's native structured output where supported. A reply that doesn't match is sent back to the model to fix, a few times at most before step and loop fail with an LLM_OUTPUT_MISMATCH error. Read the reply with output.").
+			Args(
+				dagql.Arg("typeDef").Doc("The type of the value to reply with, e.g. an object whose fields the model fills in. Objects referenced by name are resolved through the calling module. Exclusive with schema."),
+				dagql.Arg("schema").Doc("A JSON Schema for the value to reply with. Exclusive with typeDef."),
+			),
+		dagql.Func("output", s.output).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("The model's most recent reply, decoded as a JSON value matching the output schema set with withOutputSchema."),
 		dagql.Func("withPrompt", s.withPrompt).
 			Doc("Queue a user prompt, to be sent to the model on the next step or loop.").
 			Args(
 				dagql.Arg("prompt").Doc("The prompt to send"),
 			),
-		dagql.Func("__mcp", func(ctx context.Context, self *core.LLM, _ struct{}) (dagql.Nullable[core.Void], error) {
+		dagql.Func("__mcp", func(ctx context.Context, self *core.LLM, args struct {
+			HTTP bool `name:"http" default:"false"`
+		}) (dagql.Nullable[core.Void], error) {
 			currentSrv, err := core.CurrentDagqlServer(ctx)
 			if err != nil {
 				return dagql.Null[core.Void](), err
 			}
-			return dagql.Null[core.Void](), self.MCP(ctx, currentSrv)
+			return dagql.Null[core.Void](), self.MCP(ctx, currentSrv, args.HTTP)
 		}).
-			Doc("instantiates an mcp server"),
+			Doc("instantiates an mcp server").
+			Args(
+				dagql.Arg("http").Doc("Serve the MCP streamable HTTP transport over HTTP/2, instead of stdio"),
+			),
 		dagql.Func("withPromptFile", s.withPromptFile).
 			Doc("Queue a file's contents as a user prompt, like withPrompt.").
 			Args(
@@ -139,12 +173,26 @@ func (s llmSchema) Install(srv *dagql.Server) {
 			),
 		dagql.Func("withoutDefaultSystemPrompt", s.withoutDefaultSystemPrompt).
 			Doc("Disable the default system prompt"),
-		dagql.Func("withMCPServer", s.withMCPServer).
+		dagql.Func("withMCPServer", s.withMCPServerStdio).
+			View(BeforeVersion("v1.0.0-0")).
 			Doc("Add an external MCP server to the LLM").
 			Args(
 				dagql.Arg("name").Doc("The name of the MCP server"),
 				dagql.Arg("service").Doc("The MCP service to run and communicate with over stdio"),
 			),
+		dagql.Func("withMCPServer", s.withMCPServer).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("Add an external MCP server to the LLM",
+				`The server is either a service spoken to over stdio, a port of a
+				service or a URL serving the MCP streamable HTTP transport.`).
+			Args(
+				dagql.Arg("name").Doc("The name of the MCP server"),
+				dagql.Arg("service").Doc("The MCP service to run and communicate with over stdio, unless port is set"),
+				dagql.Arg("port").Doc("Port of the service serving the MCP streamable HTTP transport"),
+				dagql.Arg("path").Doc("Path of the MCP streamable HTTP endpoint of the service"),
+				dagql.Arg("url").Doc("URL of a remote MCP server serving the streamable HTTP transport, instead of a service"),
+				dagql.Arg("httpAuthHeader").Doc("Secret used to populate the Authorization HTTP header"),
+			),
 		dagql.Func("withSkills", s.withSkills).
 			View(AfterVersion("v1.0.0-0")).
 			Doc("Install skills from a directory, adding them to the skills the model discovers with ListSkills and reads with ReadSkill. " +
@@ -201,6 +249,18 @@ func (s llmSchema) Install(srv *dagql.Server) {
 				dagql.Arg("maxTokens").Doc("Cap the model's output tokens for this step. Defaults to the model's maximum.").
 					View(AfterVersion("v1.0.0-0")),
 			),
+		dagql.NodeFunc("parallel", s.parallel).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("Run sub-agents concurrently, one per prompt, each looping in its own fork of the conversation with its own copy of the workspace. Their replies, or failures, are then queued as a single prompt for the model to act on, and the changes they made to the bound workspace are merged into it. At most 8 sub-agents loop at once. The tokens they consume, even those that failed, count towards this conversation's usage, and what's left of its budget is split evenly between them. Fails if every sub-agent failed, if their workspace changes conflict, or if they went over the budget.").
+			Args(
+				dagql.Arg("prompts").Doc("The prompt to send each sub-agent."),
+				dagql.Arg("maxSteps").Doc("Cap the number of steps of each sub-agent's loop."),
+				dagql.Arg("maxTokens").Doc("Cap the model's output tokens on each step of the sub-agents. Defaults to the model's maximum."),
+			),
+		// NOTE: this is internal-only (hidden from codegen via the __ prefix). It
+		// carries what the sub-agents of parallel consumed across save/load.
+		dagql.Func("__withSubagentTokenUsage", s.withSubagentTokenUsage).
+			Doc(`(Internal-only) Set the tokens consumed by the sub-agents run by parallel.`),
 		dagql.Func("hasPending", s.hasPending).
 			View(AfterVersion("v1.0.0-0")).
 			Doc("Report whether anything is queued to send to the model: an unsent prompt or unevaluated tool results. When true, another step will do work; when false, the turn is complete."),
@@ -318,6 +378,80 @@ func (s *llmSchema) withReasoningEffort(ctx context.Context, llm *core.LLM, args
 	return llm.WithReasoningEffort(args.Effort), nil
 }
 
+func (s *llmSchema) withBudget(ctx context.Context, llm *core.LLM, args struct {
+	MaxInputTokens  dagql.Optional[dagql.Int]   `name:"maxInputTokens"`
+	MaxOutputTokens dagql.Optional[dagql.Int]   `name:"maxOutputTokens"`
+	MaxCostUSD      dagql.Optional[dagql.Float] `name:"maxCostUSD"`
+}) (*core.LLM, error) {
+	budget := core.LLMBudget{
+		MaxInputTokens:  int64(args.MaxInputTokens.GetOr(0)),
+		MaxOutputTokens: int64(args.MaxOutputTokens.GetOr(0)),
+		MaxCostUSD:      float64(args.MaxCostUSD.GetOr(0)),
+	}
+	if budget.MaxInputTokens < 0 || budget.MaxOutputTokens < 0 || budget.MaxCostUSD < 0 {
+		return nil, errors.New("budget limits must not be negative")
+	}
+	return llm.WithBudget(budget), nil
+}
+
+func (s *llmSchema) withToolPolicy(ctx context.Context, llm *core.LLM, args struct {
+	Tool     string
+	Action   string
+	ReadOnly bool `default:"false"`
+}) (*core.LLM, error) {
+	return llm.WithToolPolicy(core.LLMToolPolicy{
+		Tool:     args.Tool,
+		Action:   args.Action,
+		ReadOnly: args.ReadOnly,
+	})
+}
+
+func (s *llmSchema) withOutputSchema(ctx context.Context, llm *core.LLM, args struct {
+	TypeDef dagql.Optional[core.TypeDefID]
+	Schema  dagql.Optional[core.JSON]
+}) (*core.LLM, error) {
+	switch {
+	case args.TypeDef.Valid && args.Schema.Valid:
+		return nil, errors.New("typeDef and schema are mutually exclusive")
+	case args.TypeDef.Valid:
+		srv, err := core.CurrentDagqlServer(ctx)
+		if err != nil {
+			return nil, err
+		}
+		typeDef, err := args.TypeDef.Value.Load(ctx, srv)
+		if err != nil {
+			return nil, fmt.Errorf("load output type: %w", err)
+		}
+		var mod *core.Module
+		query, err := core.CurrentQuery(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if current, err := query.CurrentModule(ctx); err == nil {
+			mod = current.Self()
+		} else if !errors.Is(err, core.ErrNoCurrentModule) {
+			return nil, err
+		}
+		schema, err := core.TypeDefJSONSchema(typeDef.Self(), mod)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(schema)
+		if err != nil {
+			return nil, err
+		}
+		return llm.WithOutputSchema(core.JSON(data))
+	case args.Schema.Valid:
+		return llm.WithOutputSchema(args.Schema.Value)
+	default:
+		return nil, errors.New("one of typeDef or schema is required")
+	}
+}
+
+func (s *llmSchema) output(ctx context.Context, llm *core.LLM, args struct{}) (*core.JSONValue, error) {
+	return llm.Output()
+}
+
 func (s *llmSchema) withPrompt(ctx context.Context, llm *core.LLM, args struct {
 	Prompt string
 }) (*core.LLM, error) {
@@ -393,7 +527,7 @@ func (s *llmSchema) withoutDefaultSystemPrompt(ctx context.Context, llm *core.LL
 	return llm.WithoutDefaultSystemPrompt(), nil
 }
 
-func (s *llmSchema) withMCPServer(ctx context.Context, llm *core.LLM, args struct {
+func (s *llmSchema) withMCPServerStdio(ctx context.Context, llm *core.LLM, args struct {
 	Name    string
 	Service core.ServiceID
 }) (*core.LLM, error) {
@@ -405,7 +539,64 @@ func (s *llmSchema) withMCPServer(ctx context.Context, llm *core.LLM, args struc
 	if err != nil {
 		return nil, err
 	}
-	return llm.WithMCPServer(args.Name, svc), nil
+	return llm.WithMCPServer(&core.MCPServerConfig{
+		Name:    args.Name,
+		Service: svc,
+	}), nil
+}
+
+func (s *llmSchema) withMCPServer(ctx context.Context, llm *core.LLM, args struct {
+	Name           string
+	Service        dagql.Optional[core.ServiceID]
+	Port           dagql.Optional[dagql.Int]
+	Path           string                        `default:"/mcp"`
+	URL            dagql.Optional[dagql.String]  `name:"url"`
+	HTTPAuthHeader dagql.Optional[core.SecretID] `name:"httpAuthHeader"`
+}) (*core.LLM, error) {
+	srv, err := core.CurrentDagqlServer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &core.MCPServerConfig{
+		Name: args.Name,
+		Path: args.Path,
+	}
+	switch {
+	case args.Service.Valid && args.URL.Valid:
+		return nil, errors.New("cannot set both service and url")
+	case args.Service.Valid:
+		cfg.Service, err = args.Service.Value.Load(ctx, srv)
+		if err != nil {
+			return nil, err
+		}
+		if args.Port.Valid {
+			cfg.Port = args.Port.Value.Int()
+		}
+	case args.URL.Valid:
+		if args.Port.Valid {
+			return nil, errors.New("port can only be set with service")
+		}
+		u, err := url.Parse(args.URL.Value.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid url: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("invalid url %q: scheme must be http or https", args.URL.Value)
+		}
+		cfg.URL = u.String()
+	default:
+		return nil, errors.New("either service or url must be set")
+	}
+	if args.HTTPAuthHeader.Valid {
+		if cfg.URL == "" && cfg.Port == 0 {
+			return nil, errors.New("httpAuthHeader requires port or url")
+		}
+		cfg.AuthHeader, err = args.HTTPAuthHeader.Value.Load(ctx, srv)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return llm.WithMCPServer(cfg), nil
 }
 
 func (s *llmSchema) withSkills(ctx context.Context, llm *core.LLM, args struct {
@@ -458,6 +649,30 @@ func (s *llmSchema) loop(ctx context.Context, parent dagql.ObjectResult[*core.LL
 	return parent.Self().Loop(ctx, parent, int(args.MaxSteps.Value), int(args.MaxTokens.Value))
 }
 
+func (s *llmSchema) parallel(ctx context.Context, parent dagql.ObjectResult[*core.LLM], args struct {
+	Prompts   []string
+	MaxSteps  dagql.Optional[dagql.Int] `name:"maxSteps"`
+	MaxTokens dagql.Optional[dagql.Int] `name:"maxTokens"`
+}) (dagql.ObjectResult[*core.LLM], error) {
+	return parent.Self().Parallel(ctx, parent, args.Prompts, int(args.MaxSteps.Value), int(args.MaxTokens.Value))
+}
+
+func (s *llmSchema) withSubagentTokenUsage(ctx context.Context, llm *core.LLM, args struct {
+	InputTokens       int64 `default:"0"`
+	OutputTokens      int64 `default:"0"`
+	CachedTokenReads  int64 `default:"0"`
+	CachedTokenWrites int64 `default:"0"`
+	TotalTokens       int64 `default:"0"`
+}) (*core.LLM, error) {
+	return llm.WithSubagentTokenUsage(core.LLMTokenUsage{
+		InputTokens:       args.InputTokens,
+		OutputTokens:      args.OutputTokens,
+		CachedTokenReads:  args.CachedTokenReads,
+		CachedTokenWrites: args.CachedTokenWrites,
+		TotalTokens:       args.TotalTokens,
+	}), nil
+}
+
 func (s *llmSchema) step(ctx context.Context, parent dagql.ObjectResult[*core.LLM], args struct {
 	MaxTokens dagql.Optional[dagql.Int] `name:"maxTokens"`
 }) (dagql.ObjectResult[*core.LLM], error) {
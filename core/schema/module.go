@@ -482,7 +482,13 @@ func (s *moduleSchema) Install(dag *dagql.Server) {
 			),
 
 		dagql.Func("withCheck", s.functionWithCheck).
-			Doc(`Returns the function with a flag indicating it's a check.`),
+			Doc(`Returns the function with a flag indicating it's a check.`).
+			Args(
+				dagql.Arg("retries").Doc(
+					`How many times to retry the check when it fails, before failing it.`,
+					`A check that passes on a retry is reported as flaky.`,
+				).View(AfterVersion("v1.0.0-0")),
+			),
 
 		dagql.Func("withGenerator", s.functionWithGenerator).
 			Doc(`Returns the function with a flag indicating it's a generator.`),
@@ -1578,8 +1584,13 @@ func (s *moduleSchema) functionWithDeprecated(ctx context.Context, fn *core.Func
 	return fn.WithDeprecated(args.Reason), nil
 }
 
-func (s *moduleSchema) functionWithCheck(ctx context.Context, fn *core.Function, args struct{}) (*core.Function, error) {
-	return fn.WithCheck(), nil
+func (s *moduleSchema) functionWithCheck(ctx context.Context, fn *core.Function, args struct {
+	Retries int `default:"0"`
+}) (*core.Function, error) {
+	if args.Retries < 0 {
+		return nil, fmt.Errorf("check retries must not be negative, got %d", args.Retries)
+	}
+	return fn.WithCheck(args.Retries), nil
 }
 
 func (s *moduleSchema) functionWithGenerator(ctx context.Context, fn *core.Function, args struct{}) (*core.Function, error) {
@@ -68,6 +68,7 @@ func (s *querySchema) Install(srv *dagql.Server) {
 	core.RegistryProtocols.Install(srv, AfterVersion("v1.0.0-0"))
 	core.ImageLayerCompressions.Install(srv)
 	core.ImageMediaTypesEnum.Install(srv)
+	core.ImageSBOMFormats.Install(srv, AfterVersion("v1.0.0-0"))
 	core.CacheSharingModes.Install(srv)
 	core.TypeDefKinds.Install(srv)
 	core.ModuleSourceKindEnum.Install(srv)
@@ -83,7 +83,7 @@ func (s *secretSchema) secret(
 	if err != nil {
 		return dagql.ObjectResult[*core.Secret]{}, fmt.Errorf("failed to get dagql cache: %w", err)
 	}
-	if _, _, err := secretprovider.ResolverForID(args.URI); err != nil {
+	if err := secretprovider.ValidateID(args.URI); err != nil {
 		return dagql.ObjectResult[*core.Secret]{}, err
 	}
 
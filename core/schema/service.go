@@ -47,6 +47,14 @@ func (s *serviceSchema) Install(srv *dagql.Server) {
 					`This should only be used if the user requires that their exec process be the
 					pid 1 process in the container. Otherwise it may result in unexpected behavior.`,
 				),
+				dagql.Arg("secretRefreshCommand").Doc(
+					`Command to execute in the service when a secret set with withSecretVariable
+					expires (e.g. ["nginx", "-s", "reload"]).`,
+					`Secrets expire when their provider reports a TTL, such as a Vault lease or
+					an AWS Secrets Manager rotation. They are then resolved again and passed to
+					this command in its environment. If empty, the service keeps running with
+					the expired values.`).
+					View(AfterVersion("v1.0.0-0")),
 			),
 
 		dagql.NodeFunc("up", s.containerUpLegacy).
@@ -90,6 +98,14 @@ func (s *serviceSchema) Install(srv *dagql.Server) {
 					`This should only be used if the user requires that their exec process be the
 					pid 1 process in the container. Otherwise it may result in unexpected behavior.`,
 				),
+				dagql.Arg("secretRefreshCommand").Doc(
+					`Command to execute in the service when a secret set with withSecretVariable
+					expires (e.g. ["nginx", "-s", "reload"]).`,
+					`Secrets expire when their provider reports a TTL, such as a Vault lease or
+					an AWS Secrets Manager rotation. They are then resolved again and passed to
+					this command in its environment. If empty, the service keeps running with
+					the expired values.`).
+					View(AfterVersion("v1.0.0-0")),
 			),
 	}.Install(srv)
 
@@ -347,6 +363,12 @@ func (s *serviceSchema) containerUp(ctx context.Context, ctr dagql.ObjectResult[
 			Value: dagql.Boolean(true),
 		})
 	}
+	if len(args.SecretRefreshCommand) > 0 {
+		inputs = append(inputs, dagql.NamedInput{
+			Name:  "secretRefreshCommand",
+			Value: dagql.ArrayInput[dagql.String](dagql.NewStringArray(args.SecretRefreshCommand...)),
+		})
+	}
 
 	var svc dagql.ObjectResult[*core.Service]
 	curCall := dagql.CurrentCall(ctx)
@@ -17,6 +17,7 @@ import (
 	serverresolver "github.com/dagger/dagger/engine/server/resolver"
 	bkcache "github.com/dagger/dagger/engine/snapshots"
 	"github.com/dagger/dagger/internal/buildkit/executor/oci"
+	"github.com/dagger/dagger/network"
 	"github.com/moby/locker"
 	"google.golang.org/grpc"
 )
@@ -55,6 +56,10 @@ func (s *currentTypeDefsTestServer) EnsureWorkspaceModules(context.Context, []st
 	return nil, nil
 }
 
+func (s *currentTypeDefsTestServer) ModuleEgressPolicy(context.Context, string) (*network.EgressPolicy, error) {
+	return nil, nil
+}
+
 func (s *currentTypeDefsTestServer) CurrentServedDeps(context.Context) (*core.SchemaBuilder, error) {
 	return s.deps, nil
 }
@@ -30,6 +30,10 @@ func (s upSchema) Install(srv *dagql.Server) {
 			Doc("The original module in which the service has been defined"),
 		dagql.Func("run", s.runSingleUp).
 			Doc("Execute the service function"),
+		dagql.Func("digest", s.digest).
+			View(AfterVersion("v1.0.0-0")).
+			Doc("A digest of the service and the host ports it is forwarded on.",
+				"It changes when the service's inputs change, and the running service is out of date."),
 	}.Install(srv)
 }
 
@@ -49,6 +53,14 @@ func (s upSchema) originalModule(_ context.Context, parent *core.Up, args struct
 	return parent.OriginalModule(), nil
 }
 
+func (s upSchema) digest(ctx context.Context, parent *core.Up, args struct{}) (string, error) {
+	dgst, err := parent.Digest(ctx)
+	if err != nil {
+		return "", err
+	}
+	return dgst.String(), nil
+}
+
 func (s upSchema) list(_ context.Context, parent *core.UpGroup, args struct{}) ([]*core.Up, error) {
 	return parent.List(), nil
 }
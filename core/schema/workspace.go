@@ -418,6 +418,13 @@ func (s *workspaceSchema) Install(srv *dagql.Server) {
 			Doc("The checked-out HEAD of this workspace."),
 		dagql.NodeFunc("uncommitted", s.workspaceGitUncommitted).
 			Doc("Uncommitted changes in this workspace, using the same rules as GitRepository.uncommitted."),
+		dagql.NodeFunc("changesSince", s.workspaceGitChangesSince).
+			Doc("The changes in this workspace since it diverged from the given ref, uncommitted changes included.",
+				"Like `git diff REF...`, this compares against the common ancestor of the ref and HEAD, so that changes made on the ref since don't count.",
+				"Files ignored by git are left out.").
+			Args(
+				dagql.Arg("ref").Doc("The ref to compare against, e.g. origin/main."),
+			),
 	}.Install(srv)
 
 	dagql.Fields[*core.WorkspaceModule]{
@@ -2568,6 +2575,91 @@ func (s *workspaceSchema) workspaceGitUncommitted(
 	return inst, nil
 }
 
+func (s *workspaceSchema) workspaceGitChangesSince(
+	ctx context.Context,
+	parent dagql.ObjectResult[*core.WorkspaceGit],
+	args struct {
+		Ref string
+	},
+) (dagql.ObjectResult[*core.Changeset], error) {
+	var inst dagql.ObjectResult[*core.Changeset]
+	srv, err := core.CurrentDagqlServer(ctx)
+	if err != nil {
+		return inst, err
+	}
+	ws := parent.Self().Workspace.Self()
+
+	// The current content of the workspace, without what git doesn't track.
+	var current dagql.ObjectResult[*core.Directory]
+	if _, ok := ws.SourceGitRef(); ok {
+		current, err = workspaceRootfs(ws)
+		if err != nil {
+			return inst, err
+		}
+	} else if err := srv.Select(ctx, parent, &current,
+		dagql.Selector{Field: "uncommitted"},
+		dagql.Selector{Field: "after"},
+		dagql.Selector{
+			Field: "filter",
+			Args: []dagql.NamedInput{
+				{Name: "exclude", Value: dagql.ArrayInput[dagql.String](dagql.NewStringArray(".git"))},
+				{Name: "gitignore", Value: dagql.NewBoolean(true)},
+			},
+		},
+	); err != nil {
+		return inst, fmt.Errorf("workspace content: %w", err)
+	}
+
+	var head dagql.ObjectResult[*core.GitRef]
+	if err := srv.Select(ctx, parent, &head, dagql.Selector{Field: "head"}); err != nil {
+		return inst, fmt.Errorf("workspace HEAD: %w", err)
+	}
+	headID, err := head.ID()
+	if err != nil {
+		return inst, err
+	}
+	repo, err := s.selectWorkspaceGitRepository(ctx, parent)
+	if err != nil {
+		return inst, err
+	}
+	var base dagql.ObjectResult[*core.Directory]
+	if err := srv.Select(ctx, repo, &base,
+		dagql.Selector{
+			Field: "ref",
+			Args: []dagql.NamedInput{
+				{Name: "name", Value: dagql.String(args.Ref)},
+			},
+		},
+		dagql.Selector{
+			Field: "commonAncestor",
+			Args: []dagql.NamedInput{
+				{Name: "other", Value: dagql.NewID[*core.GitRef](headID)},
+			},
+		},
+		dagql.Selector{
+			Field: "tree",
+			Args: []dagql.NamedInput{
+				{Name: "discardGitDir", Value: dagql.NewBoolean(true)},
+			},
+		},
+	); err != nil {
+		return inst, fmt.Errorf("tree of %q: %w", args.Ref, err)
+	}
+	baseID, err := base.ID()
+	if err != nil {
+		return inst, err
+	}
+	if err := srv.Select(ctx, current, &inst, dagql.Selector{
+		Field: "changes",
+		Args: []dagql.NamedInput{
+			{Name: "from", Value: dagql.NewID[*core.Directory](baseID)},
+		},
+	}); err != nil {
+		return inst, err
+	}
+	return inst, nil
+}
+
 func gitRefWorkspaceChanges(
 	ctx context.Context,
 	ws *core.Workspace,
@@ -3056,12 +3148,47 @@ func (s *workspaceSchema) checks(
 				return nil, err
 			}
 		}
+		if entry, ok := cfg.Modules[mod.Self().Name()]; ok && len(entry.Check.Retries) > 0 {
+			if err := applyWorkspaceCheckRetries(ctx, filtered, entry.Check.Retries); err != nil {
+				return nil, err
+			}
+		}
 		allChecks = append(allChecks, filtered...)
 	}
 
 	return &core.CheckGroup{Checks: allChecks, BoundWorkspace: parentResult}, nil
 }
 
+// applyWorkspaceCheckRetries sets the retries of the checks matching the
+// patterns of a module's check.retries config, overriding the retries their
+// functions declare. When several patterns match a check, the highest count
+// applies.
+func applyWorkspaceCheckRetries(ctx context.Context, checks []*core.Check, retries map[string]int) error {
+	for _, check := range checks {
+		configured := -1
+		for pattern, n := range retries {
+			match, err := matchWorkspaceInclude(ctx, check.Node, []string{pattern})
+			if err != nil {
+				return fmt.Errorf("check %q retries match: %w", check.Name(), err)
+			}
+			if !match {
+				match, err = matchSingleModuleInclude(ctx, check.Node, []string{pattern})
+				if err != nil {
+					return fmt.Errorf("check %q retries compat match: %w", check.Name(), err)
+				}
+			}
+			if match {
+				configured = max(configured, n)
+			}
+		}
+		if configured >= 0 {
+			check.Node = check.Node.Clone()
+			check.Node.CheckRetries = configured
+		}
+	}
+	return nil
+}
+
 type workspaceGeneratorModule struct {
 	mod          dagql.ObjectResult[*core.Module]
 	name         string
@@ -3317,6 +3444,10 @@ func (s *workspaceSchema) services(
 		}
 	}
 
+	for _, up := range allUps {
+		up.BoundWorkspace = parentResult
+	}
+
 	return &core.UpGroup{Ups: allUps, BoundWorkspace: parentResult}, nil
 }
 
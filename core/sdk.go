@@ -279,6 +279,10 @@ func (r *ContainerRuntime) Call(
 		DefaultTerminalCmd: ctr.Self().DefaultTerminalCmd,
 		SystemEnvNames:     slices.Clone(ctr.Self().SystemEnvNames),
 		DefaultArgs:        ctr.Self().DefaultArgs,
+		DefaultExecTimeout: ctr.Self().DefaultExecTimeout,
+		ResourceLimits:     ctr.Self().ResourceLimits,
+		SecurityProfile:    ctr.Self().SecurityProfile,
+		EgressPolicy:       ctr.Self().EgressPolicy,
 	}
 	execCtr.Config.ExposedPorts = maps.Clone(execCtr.Config.ExposedPorts)
 	execCtr.Config.Env = slices.Clone(execCtr.Config.Env)
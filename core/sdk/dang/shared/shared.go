@@ -108,6 +108,9 @@ func NewNestedClientMetadata(ctx context.Context) (*engine.ClientMetadata, *engi
 		ClientStableID:    identity.NewID(),
 		ClientVersion:     engine.Version,
 		AllowedLLMModules: slices.Clone(clientMetadata.AllowedLLMModules),
+		EgressPolicies:    slices.Clone(clientMetadata.EgressPolicies),
+		CacheTags:         slices.Clone(clientMetadata.CacheTags),
+		CacheModule:       clientMetadata.CacheModule,
 	}
 
 	return clientMetadata, nestedClientMetadata, nil
@@ -6,14 +6,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/dagger/dagger/dagql"
 	"github.com/dagger/dagger/engine"
+	"github.com/dagger/dagger/engine/client/secretprovider"
+	"github.com/dagger/dagger/engine/slog"
 	"github.com/dagger/dagger/internal/buildkit/session/secrets"
 	"github.com/dagger/dagger/util/hashutil"
 	"github.com/opencontainers/go-digest"
 	"github.com/vektah/gqlparser/v2/ast"
 	"golang.org/x/crypto/argon2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 // Secret is a content-addressed secret.
@@ -131,8 +136,16 @@ func (secret *Secret) URI(ctx context.Context) (string, error) {
 }
 
 func (secret *Secret) Plaintext(ctx context.Context) ([]byte, error) {
+	plaintext, _, err := secret.PlaintextWithExpiry(ctx)
+	return plaintext, err
+}
+
+// PlaintextWithExpiry is like Plaintext, but also returns when the plaintext
+// expires, as reported by the provider it was resolved from. The expiry is
+// zero if the plaintext doesn't expire.
+func (secret *Secret) PlaintextWithExpiry(ctx context.Context) ([]byte, time.Time, error) {
 	if secret == nil {
-		return nil, nil
+		return nil, time.Time{}, nil
 	}
 	if secret.Handle == "" {
 		return secret.plaintext(ctx)
@@ -140,111 +153,128 @@ func (secret *Secret) Plaintext(ctx context.Context) ([]byte, error) {
 
 	clientMetadata, err := engine.ClientMetadataFromContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("resolve session secret %q: current client metadata: %w", secret.Handle, err)
+		return nil, time.Time{}, fmt.Errorf("resolve session secret %q: current client metadata: %w", secret.Handle, err)
 	}
 	if clientMetadata.SessionID == "" {
-		return nil, fmt.Errorf("resolve session secret %q: empty session ID", secret.Handle)
+		return nil, time.Time{}, fmt.Errorf("resolve session secret %q: empty session ID", secret.Handle)
 	}
 	cache, err := dagql.EngineCache(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("resolve session secret %q: current dagql cache: %w", secret.Handle, err)
+		return nil, time.Time{}, fmt.Errorf("resolve session secret %q: current dagql cache: %w", secret.Handle, err)
 	}
 	// Session-wide in-flight dedupe can make this call run under a client whose
 	// attachables disconnect before other waiters finish. Try each same-session
 	// binding so another live client can still provide the secret.
 	candidates, err := cache.ResolveSessionResourceCandidates(ctx, clientMetadata.SessionID, clientMetadata.ClientID, secret.Handle)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
 
 	var errs error
 	for _, candidate := range candidates {
 		resolved, ok := candidate.Value.(*Secret)
 		if !ok {
-			return nil, fmt.Errorf("resolve session secret %q: bound value for client %q is %T", secret.Handle, candidate.ClientID, candidate.Value)
+			return nil, time.Time{}, fmt.Errorf("resolve session secret %q: bound value for client %q is %T", secret.Handle, candidate.ClientID, candidate.Value)
 		}
-		plaintext, retry, err := resolved.plaintextFromSessionResourceCandidate(ctx)
+		plaintext, expiresAt, retry, err := resolved.plaintextFromSessionResourceCandidate(ctx)
 		if err == nil {
-			return plaintext, nil
+			return plaintext, expiresAt, nil
 		}
 		if !retry {
-			return nil, err
+			return nil, time.Time{}, err
 		}
 		errs = errors.Join(errs, fmt.Errorf("client %q: %w", candidate.ClientID, err))
 	}
 
 	if errs != nil {
-		return nil, fmt.Errorf("resolve session secret %q: no available client binding: %w", secret.Handle, errs)
+		return nil, time.Time{}, fmt.Errorf("resolve session secret %q: no available client binding: %w", secret.Handle, errs)
 	}
-	return nil, fmt.Errorf("resolve session secret %q: no available client binding", secret.Handle)
+	return nil, time.Time{}, fmt.Errorf("resolve session secret %q: no available client binding", secret.Handle)
 }
 
-func (secret *Secret) plaintext(ctx context.Context) ([]byte, error) {
+func (secret *Secret) plaintext(ctx context.Context) ([]byte, time.Time, error) {
 	if secret == nil {
-		return nil, nil
+		return nil, time.Time{}, nil
 	}
 	if secret.URIVal == "" {
-		return append([]byte(nil), secret.PlaintextVal...), nil
+		return append([]byte(nil), secret.PlaintextVal...), time.Time{}, nil
 	}
 	if secret.SourceClientID == "" {
-		return nil, fmt.Errorf("secret %q: missing source client ID", secret.URIVal)
+		return nil, time.Time{}, fmt.Errorf("secret %q: missing source client ID", secret.URIVal)
 	}
 	query, err := CurrentQuery(ctx)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
 	conn, _, err := query.SpecificClientAttachableConn(ctx, secret.SourceClientID, SpecificClientAttachableConnOpts{})
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
+	var md metadata.MD
 	resp, err := secrets.NewSecretsClient(conn).GetSecret(ctx, &secrets.GetSecretRequest{
 		ID: secret.URIVal,
-	})
+	}, grpc.Header(&md))
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
-	return resp.Data, nil
+	return resp.Data, secretExpiry(ctx, md), nil
 }
 
-func (secret *Secret) plaintextFromSessionResourceCandidate(ctx context.Context) ([]byte, bool, error) {
+func (secret *Secret) plaintextFromSessionResourceCandidate(ctx context.Context) ([]byte, time.Time, bool, error) {
 	if secret == nil {
-		return nil, false, nil
+		return nil, time.Time{}, false, nil
 	}
 	if secret.URIVal == "" {
-		return append([]byte(nil), secret.PlaintextVal...), false, nil
+		return append([]byte(nil), secret.PlaintextVal...), time.Time{}, false, nil
 	}
 	if secret.SourceClientID == "" {
-		return nil, false, fmt.Errorf("secret %q: missing source client ID", secret.URIVal)
+		return nil, time.Time{}, false, fmt.Errorf("secret %q: missing source client ID", secret.URIVal)
 	}
 	query, err := CurrentQuery(ctx)
 	if err != nil {
-		return nil, false, err
+		return nil, time.Time{}, false, err
 	}
 	conn, ok, err := query.SpecificClientAttachableConn(ctx, secret.SourceClientID, SpecificClientAttachableConnOpts{
 		IfAvailable: true,
 	})
 	if err != nil {
-		return nil, false, err
+		return nil, time.Time{}, false, err
 	}
 	if !ok {
-		return nil, true, fmt.Errorf("no active session attachables for client %q", secret.SourceClientID)
+		return nil, time.Time{}, true, fmt.Errorf("no active session attachables for client %q", secret.SourceClientID)
 	}
+	var md metadata.MD
 	resp, err := secrets.NewSecretsClient(conn).GetSecret(ctx, &secrets.GetSecretRequest{
 		ID: secret.URIVal,
-	})
+	}, grpc.Header(&md))
 	if err != nil {
 		_, active, lookupErr := query.SpecificClientAttachableConn(ctx, secret.SourceClientID, SpecificClientAttachableConnOpts{
 			IfAvailable: true,
 		})
 		if lookupErr != nil {
-			return nil, false, lookupErr
+			return nil, time.Time{}, false, lookupErr
 		}
 		if !active || isRetryableSessionAttachableErr(ctx, err) {
-			return nil, true, err
+			return nil, time.Time{}, true, err
 		}
-		return nil, false, err
+		return nil, time.Time{}, false, err
+	}
+	return resp.Data, secretExpiry(ctx, md), false, nil
+}
+
+// secretExpiry returns the expiry reported by a client's secret provider, or
+// the zero time if it didn't report one.
+func secretExpiry(ctx context.Context, md metadata.MD) time.Time {
+	vals := md.Get(secretprovider.ExpiresAtHeader)
+	if len(vals) == 0 {
+		return time.Time{}
+	}
+	expiresAt, err := time.Parse(time.RFC3339, vals[0])
+	if err != nil {
+		slog.WarnContext(ctx, "ignoring invalid secret expiry", "expiresAt", vals[0], "error", err)
+		return time.Time{}
 	}
-	return resp.Data, false, nil
+	return expiresAt
 }
 
 func SecretHandleFromCacheKey(cacheKey string) dagql.SessionResourceHandle {
@@ -0,0 +1,26 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dagger/dagger/engine/client/secretprovider"
+)
+
+func TestSecretExpiry(t *testing.T) {
+	ctx := context.Background()
+
+	require.True(t, secretExpiry(ctx, nil).IsZero())
+	require.True(t, secretExpiry(ctx, metadata.Pairs("other", "value")).IsZero())
+
+	require.Equal(t,
+		time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		secretExpiry(ctx, metadata.Pairs(secretprovider.ExpiresAtHeader, "2030-01-02T03:04:05Z")).UTC())
+
+	// a provider reporting garbage doesn't make the secret expire
+	require.True(t, secretExpiry(ctx, metadata.Pairs(secretprovider.ExpiresAtHeader, "tomorrow")).IsZero())
+}
@@ -60,6 +60,10 @@ type Service struct {
 	ModuleContext                 dagql.ObjectResult[*Module]
 	ExecMeta                      *executor.Meta
 
+	// SecretRefreshCommand is exec'd in the service when one of its secret
+	// env variables expires, with the re-resolved values in its env.
+	SecretRefreshCommand []string
+
 	// TunnelUpstream is the service that this service is tunnelling to.
 	TunnelUpstream dagql.ObjectResult[*Service]
 	// TunnelPorts configures the port forwarding rules for the tunnel.
@@ -94,6 +98,7 @@ type persistedServicePayload struct {
 	ExecMD                        *engineutil.ExecutionMetadata `json:"execMD,omitempty"`
 	ModuleContextResultID         uint64                        `json:"moduleContextResultID,omitempty"`
 	ExecMeta                      *executor.Meta                `json:"execMeta,omitempty"`
+	SecretRefreshCommand          []string                      `json:"secretRefreshCommand,omitempty"`
 	TunnelUpstreamResultID        uint64                        `json:"tunnelUpstreamResultID,omitempty"`
 	TunnelPorts                   []PortForward                 `json:"tunnelPorts,omitempty"`
 	HostSockets                   []persistedServiceHostSocket  `json:"hostSockets,omitempty"`
@@ -126,6 +131,7 @@ func (svc *Service) EncodePersistedObject(ctx context.Context, cache dagql.Persi
 		NoInit:                        svc.NoInit,
 		ExecMD:                        svc.ExecMD,
 		ExecMeta:                      svc.ExecMeta,
+		SecretRefreshCommand:          slices.Clone(svc.SecretRefreshCommand),
 		TunnelPorts:                   slices.Clone(svc.TunnelPorts),
 		HostSockets:                   make([]persistedServiceHostSocket, 0, len(svc.HostSockets)),
 	}
@@ -204,6 +210,7 @@ func (*Service) DecodePersistedObject(ctx context.Context, dag *dagql.Server, _
 		ExecMD:                        persisted.ExecMD,
 		ModuleContext:                 moduleContext,
 		ExecMeta:                      persisted.ExecMeta,
+		SecretRefreshCommand:          slices.Clone(persisted.SecretRefreshCommand),
 		TunnelUpstream:                tunnelUpstream,
 		TunnelPorts:                   slices.Clone(persisted.TunnelPorts),
 		HostSockets:                   hostSockets,
@@ -294,6 +301,7 @@ func decodePersistedServiceBindings(ctx context.Context, dag *dagql.Server, owne
 func (svc *Service) Clone() *Service {
 	cp := *svc
 	cp.Args = slices.Clone(cp.Args)
+	cp.SecretRefreshCommand = slices.Clone(cp.SecretRefreshCommand)
 	cp.TunnelPorts = slices.Clone(cp.TunnelPorts)
 	cp.HostSockets = slices.Clone(cp.HostSockets)
 	return &cp
@@ -802,10 +810,11 @@ func (svc *Service) startContainer(
 		resize = convertResizeChannel(ctx, opts.IO.ResizeCh)
 	}
 
-	secretEnv, err := ctr.secretEnvValues(ctx)
+	secretEnv, secretsExpireAt, err := ctr.secretEnvValuesWithExpiry(ctx)
 	if err != nil {
 		return err
 	}
+	envWithoutSecrets := slices.Clone(meta.Env)
 	meta.Env = append(meta.Env, secretEnv...)
 
 	var nestedClientMetadata *engine.ClientMetadata
@@ -815,6 +824,9 @@ func (svc *Service) startContainer(
 			ClientVersion:     engine.Version,
 			SessionID:         clientMetadata.SessionID,
 			AllowedLLMModules: slices.Clone(clientMetadata.AllowedLLMModules),
+			EgressPolicies:    slices.Clone(meta.EgressPolicies),
+			CacheTags:         slices.Clone(clientMetadata.CacheTags),
+			CacheModule:       clientMetadata.CacheModule,
 		}
 	}
 
@@ -1048,6 +1060,15 @@ func (svc *Service) startContainer(
 			running.Wait = waitSvc
 			running.Exec = execSvc
 			running.ContainerID = svcID
+			if !secretsExpireAt.IsZero() {
+				refreshSecretEnv := func(ctx context.Context, secretEnv []string) error {
+					meta := *meta
+					meta.Args = svc.SecretRefreshCommand
+					meta.Env = append(slices.Clone(envWithoutSecrets), secretEnv...)
+					return bk.Exec(ctx, svcID, executor.ProcessInfo{Meta: meta})
+				}
+				go svc.refreshExpiredSecrets(context.WithoutCancel(ctx), ctr.secretEnvValuesWithExpiry, secretsExpireAt, exited, refreshSecretEnv)
+			}
 			if havePendingDependencyErr || dependencyErrCh != nil {
 				go propagateDependencyExitAfterSuppression(pendingDependencyErr, havePendingDependencyErr, dependencyErrCh)
 			}
@@ -1082,6 +1103,51 @@ func (svc *Service) startContainer(
 	}
 }
 
+// secretRefreshMinInterval bounds how often the secrets of a service are
+// resolved again, in case a provider keeps reporting an expiry in the past.
+var secretRefreshMinInterval = 10 * time.Second
+
+// refreshExpiredSecrets resolves the secret env variables of a service again
+// with resolve each time one of them expires, until the service exits, and
+// passes them to the service's secret refresh command.
+func (svc *Service) refreshExpiredSecrets(
+	ctx context.Context,
+	resolve func(context.Context) ([]string, time.Time, error),
+	expiresAt time.Time,
+	exited <-chan struct{},
+	refresh func(context.Context, []string) error,
+) {
+	for {
+		timer := time.NewTimer(max(time.Until(expiresAt), secretRefreshMinInterval))
+		select {
+		case <-exited:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if len(svc.SecretRefreshCommand) == 0 {
+			slog.WarnContext(ctx, "service secrets expired; set secretRefreshCommand to refresh them",
+				"expiredAt", expiresAt)
+			return
+		}
+
+		secretEnv, next, err := resolve(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to resolve expired service secrets", "err", err)
+			expiresAt = time.Now()
+			continue
+		}
+		if err := refresh(ctx, secretEnv); err != nil {
+			slog.WarnContext(ctx, "service secret refresh command failed", "err", err)
+		}
+		if next.IsZero() {
+			return
+		}
+		expiresAt = next
+	}
+}
+
 func convertResizeChannel(ctx context.Context, in <-chan bkgw.WinSize) <-chan executor.WinSize {
 	if in == nil {
 		return nil
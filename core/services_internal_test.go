@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -141,3 +142,76 @@ func TestStartWithKeySuppressesDependencyExitPropagationUntilRelease(t *testing.
 		}
 	}, time.Second, 10*time.Millisecond)
 }
+
+func TestRefreshExpiredSecrets(t *testing.T) {
+	minInterval := secretRefreshMinInterval
+	secretRefreshMinInterval = time.Millisecond
+	t.Cleanup(func() { secretRefreshMinInterval = minInterval })
+
+	// run waits for refreshExpiredSecrets to return, which it must do on its
+	// own in each case
+	run := func(t *testing.T, svc *Service, resolve func(context.Context) ([]string, time.Time, error), expiresAt time.Time, exited <-chan struct{}) [][]string {
+		t.Helper()
+		var refreshed [][]string
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			svc.refreshExpiredSecrets(t.Context(), resolve, expiresAt, exited, func(_ context.Context, env []string) error {
+				refreshed = append(refreshed, env)
+				return errors.New("refresh failures are only logged")
+			})
+		}()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatal("refreshExpiredSecrets didn't return")
+		}
+		return refreshed
+	}
+	svc := &Service{SecretRefreshCommand: []string{"reload"}}
+
+	t.Run("refreshes until the secrets stop expiring", func(t *testing.T) {
+		var resolves int
+		resolve := func(context.Context) ([]string, time.Time, error) {
+			resolves++
+			if resolves < 3 {
+				return []string{fmt.Sprintf("TOKEN=%d", resolves)}, time.Now().Add(time.Millisecond), nil
+			}
+			return []string{"TOKEN=final"}, time.Time{}, nil
+		}
+		refreshed := run(t, svc, resolve, time.Now(), nil)
+		require.Equal(t, [][]string{{"TOKEN=1"}, {"TOKEN=2"}, {"TOKEN=final"}}, refreshed)
+	})
+
+	t.Run("retries failed resolves", func(t *testing.T) {
+		var resolves int
+		resolve := func(context.Context) ([]string, time.Time, error) {
+			resolves++
+			if resolves == 1 {
+				return nil, time.Time{}, errors.New("vault is down")
+			}
+			return []string{"TOKEN=new"}, time.Time{}, nil
+		}
+		refreshed := run(t, svc, resolve, time.Now(), nil)
+		require.Equal(t, 2, resolves)
+		require.Equal(t, [][]string{{"TOKEN=new"}}, refreshed)
+	})
+
+	t.Run("stops once the service exits", func(t *testing.T) {
+		exited := make(chan struct{})
+		close(exited)
+		resolve := func(context.Context) ([]string, time.Time, error) {
+			t.Error("resolved the secrets of an exited service")
+			return nil, time.Time{}, nil
+		}
+		require.Empty(t, run(t, svc, resolve, time.Now().Add(time.Hour), exited))
+	})
+
+	t.Run("doesn't resolve without a refresh command", func(t *testing.T) {
+		resolve := func(context.Context) ([]string, time.Time, error) {
+			t.Error("resolved secrets with nothing to pass them to")
+			return nil, time.Time{}, nil
+		}
+		require.Empty(t, run(t, &Service{}, resolve, time.Now(), nil))
+	})
+}
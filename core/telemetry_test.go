@@ -17,6 +17,7 @@ import (
 	serverresolver "github.com/dagger/dagger/engine/server/resolver"
 	bkcache "github.com/dagger/dagger/engine/snapshots"
 	"github.com/dagger/dagger/internal/buildkit/executor/oci"
+	"github.com/dagger/dagger/network"
 	telemetry "github.com/dagger/otel-go"
 	"github.com/moby/locker"
 	"github.com/stretchr/testify/require"
@@ -46,6 +47,7 @@ type mockServer struct {
 	functionCall   *FunctionCall
 	clientMetadata *engine.ClientMetadata
 	attachables    map[string]*grpc.ClientConn
+	workspace      *Workspace
 }
 
 func (ms *mockServer) ServeHTTPToNestedClient(http.ResponseWriter, *http.Request, *engine.ClientMetadata, string, bool, dagql.AnyObjectResult, dagql.Typed) {
@@ -59,6 +61,10 @@ func (ms *mockServer) EnsureWorkspaceModules(context.Context, []string, bool) ([
 	return nil, nil
 }
 
+func (ms *mockServer) ModuleEgressPolicy(context.Context, string) (*network.EgressPolicy, error) {
+	return nil, nil
+}
+
 func (ms *mockServer) CurrentModule(_ context.Context) (dagql.ObjectResult[*Module], error) {
 	var zero dagql.ObjectResult[*Module]
 	if ms.moduleSource == nil {
@@ -121,7 +127,7 @@ func (ms *mockServer) SpecificClientMetadata(context.Context, string) (*engine.C
 }
 
 func (ms *mockServer) CurrentWorkspace(context.Context) (*Workspace, error) {
-	return nil, nil
+	return ms.workspace, nil
 }
 
 func (ms *mockServer) SpecificClientAttachableConn(_ context.Context, clientID string, opts SpecificClientAttachableConnOpts) (*grpc.ClientConn, bool, error) {
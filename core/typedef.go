@@ -39,6 +39,9 @@ type Function struct {
 	// IsCheck indicates whether this function is a check
 	IsCheck bool
 
+	// CheckRetries is how many times to retry the check before failing it
+	CheckRetries int
+
 	// IsGenerator indicates whether this function is a generator
 	IsGenerator bool
 
@@ -353,9 +356,10 @@ func (fn *Function) WithDeprecated(reason *string) *Function {
 	return fn
 }
 
-func (fn *Function) WithCheck() *Function {
+func (fn *Function) WithCheck(retries int) *Function {
 	fn = fn.Clone()
 	fn.IsCheck = true
+	fn.CheckRetries = retries
 	return fn
 }
 
@@ -2702,6 +2706,7 @@ type persistedFunction struct {
 	CachePolicy        FunctionCachePolicy `json:"cachePolicy,omitempty"`
 	CacheTTLSeconds    *int64              `json:"cacheTTLSeconds,omitempty"`
 	IsCheck            bool                `json:"isCheck,omitempty"`
+	CheckRetries       int                 `json:"checkRetries,omitempty"`
 	IsGenerator        bool                `json:"isGenerator,omitempty"`
 	IsUp               bool                `json:"isUp,omitempty"`
 	IsAgent            bool                `json:"isAgent,omitempty"`
@@ -2879,6 +2884,7 @@ func encodePersistedFunction(cache dagql.PersistedObjectCache, fn *Function) (*p
 		SourceModuleName:   fn.SourceModuleName,
 		CachePolicy:        fn.CachePolicy,
 		IsCheck:            fn.IsCheck,
+		CheckRetries:       fn.CheckRetries,
 		IsGenerator:        fn.IsGenerator,
 		IsUp:               fn.IsUp,
 		IsAgent:            fn.IsAgent,
@@ -2928,6 +2934,7 @@ func decodePersistedFunction(ctx context.Context, dag *dagql.Server, fn *persist
 		SourceModuleName:   fn.SourceModuleName,
 		CachePolicy:        fn.CachePolicy,
 		IsCheck:            fn.IsCheck,
+		CheckRetries:       fn.CheckRetries,
 		IsGenerator:        fn.IsGenerator,
 		IsUp:               fn.IsUp,
 		IsAgent:            fn.IsAgent,
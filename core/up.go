@@ -7,7 +7,9 @@ import (
 	"sync"
 
 	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/util/hashutil"
 	"github.com/dagger/dagger/util/parallel"
+	"github.com/opencontainers/go-digest"
 	"github.com/vektah/gqlparser/v2/ast"
 )
 
@@ -15,6 +17,11 @@ import (
 type Up struct {
 	Node         *ModTreeNode  `json:"node"`
 	PortMappings []PortForward `json:"portMappings,omitempty"`
+
+	// BoundWorkspace is the Workspace this service was rolled up from, threaded
+	// into the context by Run like UpGroup.BoundWorkspace. Transient (not
+	// persisted).
+	BoundWorkspace dagql.ObjectResult[*Workspace] `json:"-"`
 }
 
 type UpGroup struct {
@@ -104,9 +111,9 @@ func (ug *UpGroup) Run(ctx context.Context) (*UpGroup, error) {
 		})
 	}
 	if err := jobs.Run(ctx); err != nil {
-		// Clean up any ready spans from services that did start.
+		// Clean up any services that did start.
 		for _, r := range results {
-			r.ReadySpan.End()
+			r.Stop(ctx)
 		}
 		return nil, err
 	}
@@ -115,7 +122,7 @@ func (ug *UpGroup) Run(ctx context.Context) (*UpGroup, error) {
 	// cancellation (e.g. Ctrl+C).
 	<-ctx.Done()
 	for _, r := range results {
-		r.ReadySpan.End()
+		r.Stop(ctx)
 	}
 	return ug, nil
 }
@@ -245,14 +252,41 @@ func (u *Up) Clone() *Up {
 	return &cp
 }
 
-// Run starts the service returned by this up function and blocks until ctx is cancelled.
+// Run starts the service returned by this up function and blocks until ctx is
+// cancelled, then stops it, releasing its host ports.
 func (u *Up) Run(ctx context.Context) (*Up, error) {
 	u = u.Clone()
+	if u.BoundWorkspace.Self() != nil {
+		ctx = WorkspaceToContext(ctx, u.BoundWorkspace)
+	}
 	result, err := u.Node.RunUp(ctx, nil, nil, u.PortMappings)
 	if err != nil {
 		return u, err
 	}
-	defer result.ReadySpan.End()
 	<-ctx.Done()
+	result.Stop(ctx)
 	return u, nil
 }
+
+// Digest returns a digest of what running this up function would start: the
+// service it returns, and the host ports it is forwarded on. It is stable for
+// as long as the service's inputs don't change, so a caller can tell which of
+// its running services are out of date.
+func (u *Up) Digest(ctx context.Context) (digest.Digest, error) {
+	if u.BoundWorkspace.Self() != nil {
+		ctx = WorkspaceToContext(ctx, u.BoundWorkspace)
+	}
+	var svcResult dagql.ObjectResult[*Service]
+	if err := u.Node.DagqlValue(ctx, &svcResult); err != nil {
+		return "", err
+	}
+	svcDigest, err := svcResult.ContentPreferredDigest(ctx)
+	if err != nil {
+		return "", fmt.Errorf("service digest: %w", err)
+	}
+	inputs := []string{svcDigest.String()}
+	for _, pf := range u.PortMappings {
+		inputs = append(inputs, fmt.Sprintf("%d:%d/%s", pf.FrontendOrBackendPort(), pf.Backend, pf.Protocol))
+	}
+	return hashutil.HashStrings(inputs...), nil
+}
@@ -132,6 +132,10 @@ type Workspace struct {
 	// personal values that must not surface through GraphQL or IDs.
 	userConfigOverlay *workspacepkg.UserWorkspaceOverlay
 
+	// llmToolPolicies are the [[llm.tools]] policies of the workspace config,
+	// enforced on the tool calls of every LLM of the session. Internal only.
+	llmToolPolicies []LLMToolPolicy
+
 	Address    string `field:"true" doc:"Canonical Dagger address of the workspace location, or an opaque identity for synthetic workspaces."`
 	Cwd        string
 	ConfigFile string
@@ -489,6 +493,17 @@ func (ws *Workspace) SetUserConfigOverlay(overlay *workspacepkg.UserWorkspaceOve
 	ws.userConfigOverlay = overlay
 }
 
+func (ws *Workspace) LLMToolPolicies() []LLMToolPolicy {
+	if ws == nil {
+		return nil
+	}
+	return ws.llmToolPolicies
+}
+
+func (ws *Workspace) SetLLMToolPolicies(policies []LLMToolPolicy) {
+	ws.llmToolPolicies = policies
+}
+
 // MountsDir returns the read-only directory tree holding mounted content,
 // keyed by workspace-root-relative mount path, or false when the workspace has
 // no mounts.
@@ -2,15 +2,19 @@ package workspace
 
 import (
 	"fmt"
+	"maps"
 	"path"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"unicode/utf8"
 
 	toml "github.com/pelletier/go-toml"
+
+	"github.com/dagger/dagger/network"
 )
 
 // Config represents a parsed dagger.toml workspace configuration.
@@ -24,6 +28,105 @@ type Config struct {
 	CheckGenerated *bool                  `json:"check-generated,omitempty" toml:"check-generated,omitempty"`
 	Env            map[string]EnvOverlay  `json:"env,omitempty" toml:"env"`
 	Ports          map[string]PortMapping `json:"ports,omitempty" toml:"ports,omitempty"`
+	// LLM configures the LLM sessions of `dagger agent` and the shell's
+	// prompt mode.
+	LLM *LLMConfig `json:"llm,omitempty" toml:"llm,omitempty"`
+}
+
+// LLMConfig is the [llm] table.
+type LLMConfig struct {
+	Budget *LLMBudget `json:"budget,omitempty" toml:"budget,omitempty"`
+	// Tools are the [[llm.tools]] policies approving tool calls, which the
+	// engine enforces on every LLM of the session along with the ones set
+	// with LLM.withToolPolicy, the most restrictive winning.
+	Tools []LLMToolPolicy `json:"tools,omitempty" toml:"tools,omitempty"`
+}
+
+// LLMToolPolicy is an [[llm.tools]] entry: whether the model may call the
+// tools it matches. The last matching entry applies.
+type LLMToolPolicy struct {
+	// Tool is a glob pattern matched against the tool name.
+	Tool string `json:"tool" toml:"tool"`
+	// ReadOnly restricts the policy to read-only tools.
+	ReadOnly bool `json:"read-only,omitempty" toml:"read-only,omitempty"`
+	// Action is "allow", "ask" or "deny".
+	Action string `json:"action" toml:"action" jsonschema:"enum=allow,enum=ask,enum=deny"`
+}
+
+// Validate checks that the policy names a tool and a known action.
+func (policy LLMToolPolicy) Validate() error {
+	if policy.Tool == "" {
+		return fmt.Errorf("llm.tools: tool is required")
+	}
+	switch policy.Action {
+	case "allow", "ask", "deny":
+		return nil
+	default:
+		return fmt.Errorf("llm.tools: invalid action %q for %q: must be \"allow\", \"ask\" or \"deny\"", policy.Action, policy.Tool)
+	}
+}
+
+// LLMBudget is the [llm.budget] table: the limits applied to each session
+// with LLM.withBudget. Zero leaves a dimension unlimited.
+type LLMBudget struct {
+	// MaxInputTokens caps the input tokens, cached or not.
+	MaxInputTokens int64 `json:"max-input-tokens,omitempty" toml:"max-input-tokens,omitempty"`
+	// MaxOutputTokens caps the output tokens.
+	MaxOutputTokens int64 `json:"max-output-tokens,omitempty" toml:"max-output-tokens,omitempty"`
+	// MaxCostUSD caps the cost in US dollars, priced from the model catalog.
+	MaxCostUSD float64 `json:"max-cost-usd,omitempty" toml:"max-cost-usd,omitempty"`
+}
+
+// UnmarshalTOML decodes the table by hand, so that a whole number of dollars
+// like `max-cost-usd = 5` isn't rejected as an integer.
+func (budget *LLMBudget) UnmarshalTOML(v any) error {
+	table, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("llm.budget: expected a table, got %T", v)
+	}
+	for key, value := range table {
+		var err error
+		switch key {
+		case "max-input-tokens":
+			budget.MaxInputTokens, err = tomlInt(value)
+		case "max-output-tokens":
+			budget.MaxOutputTokens, err = tomlInt(value)
+		case "max-cost-usd":
+			switch value := value.(type) {
+			case int64:
+				budget.MaxCostUSD = float64(value)
+			case float64:
+				budget.MaxCostUSD = value
+			default:
+				err = fmt.Errorf("expected a number, got %T", value)
+			}
+		default:
+			err = fmt.Errorf("unknown key")
+		}
+		if err != nil {
+			return fmt.Errorf("llm.budget.%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func tomlInt(value any) (int64, error) {
+	i, ok := value.(int64)
+	if !ok {
+		return 0, fmt.Errorf("expected an integer, got %T", value)
+	}
+	return i, nil
+}
+
+// Validate checks that the limits aren't negative.
+func (budget *LLMBudget) Validate() error {
+	if budget == nil {
+		return nil
+	}
+	if budget.MaxInputTokens < 0 || budget.MaxOutputTokens < 0 || budget.MaxCostUSD < 0 {
+		return fmt.Errorf("llm.budget: limits must not be negative")
+	}
+	return nil
 }
 
 // PortMapping declares a host port that forwards to a workspace service.
@@ -44,7 +147,11 @@ type ModuleEntry struct {
 	LegacyDefaultPath bool           `json:"legacy-default-path,omitempty" toml:"legacy-default-path,omitempty"`
 	Up                ModuleSkip     `json:"up,omitempty" toml:"up,omitempty"`
 	Generate          ModuleSkip     `json:"generate,omitempty" toml:"generate,omitempty"`
-	Check             ModuleSkip     `json:"check,omitempty" toml:"check,omitempty"`
+	Check             ModuleCheck    `json:"check,omitempty" toml:"check,omitempty"`
+
+	// Egress restricts the destinations the module's functions, and the
+	// containers they run, can connect to. Egress is unrestricted if nil.
+	Egress *ModuleEgress `json:"egress,omitempty" toml:"egress,omitempty"`
 
 	// AsSDK is the SDK-role data for module entries that serve as SDKs in
 	// this workspace. Its presence (any populated sub-field) marks the
@@ -102,6 +209,45 @@ type ModuleSkip struct {
 	Skip []string `json:"skip,omitempty" toml:"skip,omitempty"`
 }
 
+// ModuleCheck carries the check settings of a module entry: the skip patterns,
+// and how many times to retry the checks matching a pattern before failing
+// them, overriding the retries their functions declare. When several patterns
+// match a check, the highest count applies.
+type ModuleCheck struct {
+	Skip    []string       `json:"skip,omitempty" toml:"skip,omitempty"`
+	Retries map[string]int `json:"retries,omitempty" toml:"retries,omitempty"`
+}
+
+// ModuleEgress is the egress policy of a module entry, serialized as
+// modules.<name>.egress.allow and modules.<name>.egress.registries. Anything
+// not allowed is denied, so an empty policy denies all egress.
+type ModuleEgress struct {
+	// Allow lists the domains, IP addresses and CIDRs that may be reached.
+	Allow []string `json:"allow,omitempty" toml:"allow,omitempty"`
+	// Registries allows reaching the container registries known to the
+	// engine.
+	Registries bool `json:"registries,omitempty" toml:"registries,omitempty"`
+}
+
+// Policy returns the egress policy applied to the module of the given name.
+func (egress *ModuleEgress) Policy(moduleName string) network.EgressPolicy {
+	return network.EgressPolicy{
+		Allow:           slices.Clone(egress.Allow),
+		AllowRegistries: egress.Registries,
+		Source:          "module " + moduleName,
+	}
+}
+
+func cloneModuleEgress(egress *ModuleEgress) *ModuleEgress {
+	if egress == nil {
+		return nil
+	}
+	return &ModuleEgress{
+		Allow:      append([]string(nil), egress.Allow...),
+		Registries: egress.Registries,
+	}
+}
+
 // EnvOverlay is a named workspace environment overlay.
 // It intentionally supports only a constrained subset of the root schema.
 type EnvOverlay struct {
@@ -192,6 +338,29 @@ func ParseConfig(data []byte) (*Config, error) {
 	if err := populateClientOptions(data, &cfg); err != nil {
 		return nil, err
 	}
+	if cfg.LLM != nil {
+		if err := cfg.LLM.Budget.Validate(); err != nil {
+			return nil, fmt.Errorf("parse dagger.toml: %w", err)
+		}
+		for _, policy := range cfg.LLM.Tools {
+			if err := policy.Validate(); err != nil {
+				return nil, fmt.Errorf("parse dagger.toml: %w", err)
+			}
+		}
+	}
+	for name, entry := range cfg.Modules {
+		for pattern, retries := range entry.Check.Retries {
+			if retries < 0 {
+				return nil, fmt.Errorf("parse dagger.toml: modules.%s.check.retries.%s: must not be negative", name, formatConfigPathSegment(pattern))
+			}
+		}
+		if entry.Egress == nil {
+			continue
+		}
+		if err := entry.Egress.Policy(name).Validate(); err != nil {
+			return nil, fmt.Errorf("parse dagger.toml: modules.%s.egress: %w", name, err)
+		}
+	}
 	return &cfg, nil
 }
 
@@ -424,14 +593,18 @@ func SerializeConfig(cfg *Config) []byte {
 		fmt.Fprintf(&b, "check-generated = %t\n\n", *cfg.CheckGenerated)
 	}
 
+	hasLLM := cfg.LLM != nil && (cfg.LLM.Budget != nil || len(cfg.LLM.Tools) > 0)
 	wroteModules := writeModuleEntries(&b, cfg.Modules)
-	if wroteModules && (len(cfg.Env) > 0 || len(cfg.Ports) > 0) {
+	if wroteModules && (len(cfg.Env) > 0 || len(cfg.Ports) > 0 || hasLLM) {
+		b.WriteString("\n")
+	}
+	if writeEnvEntries(&b, cfg.Env) && (len(cfg.Ports) > 0 || hasLLM) {
 		b.WriteString("\n")
 	}
-	if writeEnvEntries(&b, cfg.Env) && len(cfg.Ports) > 0 {
+	if writePortEntries(&b, cfg.Ports) && hasLLM {
 		b.WriteString("\n")
 	}
-	writePortEntries(&b, cfg.Ports)
+	writeLLMEntries(&b, cfg.LLM)
 
 	return []byte(b.String())
 }
@@ -460,7 +633,8 @@ func cloneConfig(cfg *Config) *Config {
 				LegacyDefaultPath: entry.LegacyDefaultPath,
 				Up:                ModuleSkip{Skip: append([]string(nil), entry.Up.Skip...)},
 				Generate:          ModuleSkip{Skip: append([]string(nil), entry.Generate.Skip...)},
-				Check:             ModuleSkip{Skip: append([]string(nil), entry.Check.Skip...)},
+				Check:             ModuleCheck{Skip: append([]string(nil), entry.Check.Skip...), Retries: maps.Clone(entry.Check.Retries)},
+				Egress:            cloneModuleEgress(entry.Egress),
 				AsSDK:             cloneModuleAsSDK(entry.AsSDK),
 			}
 		}
@@ -488,6 +662,14 @@ func cloneConfig(cfg *Config) *Config {
 			cloned.Ports[host] = pm
 		}
 	}
+	if cfg.LLM != nil {
+		cloned.LLM = &LLMConfig{}
+		if cfg.LLM.Budget != nil {
+			budget := *cfg.LLM.Budget
+			cloned.LLM.Budget = &budget
+		}
+		cloned.LLM.Tools = slices.Clone(cfg.LLM.Tools)
+	}
 	return cloned
 }
 
@@ -573,6 +755,16 @@ func writeModuleEntries(b *strings.Builder, modules map[string]ModuleEntry) bool
 		if len(entry.Check.Skip) > 0 {
 			fmt.Fprintf(b, "check.skip = %s\n", formatConfigValue(entry.Check.Skip))
 		}
+		for _, pattern := range slices.Sorted(maps.Keys(entry.Check.Retries)) {
+			fmt.Fprintf(b, "check.retries.%s = %d\n", formatConfigPathSegment(pattern), entry.Check.Retries[pattern])
+		}
+		if entry.Egress != nil {
+			// always written, since an empty allow list denies all egress
+			fmt.Fprintf(b, "egress.allow = %s\n", formatConfigValue(entry.Egress.Allow))
+			if entry.Egress.Registries {
+				b.WriteString("egress.registries = true\n")
+			}
+		}
 		writeConfigTable(b, modulePath+".settings", entry.Settings, true)
 		writeModuleAsSDK(b, modulePath, entry.AsSDK)
 	}
@@ -705,6 +897,37 @@ func writePortEntries(b *strings.Builder, ports map[string]PortMapping) bool {
 	return true
 }
 
+// writeLLMEntries renders the [llm.budget] table and the [[llm.tools]]
+// entries.
+func writeLLMEntries(b *strings.Builder, llm *LLMConfig) {
+	if llm == nil {
+		return
+	}
+	if budget := llm.Budget; budget != nil {
+		b.WriteString("[llm.budget]\n")
+		if budget.MaxInputTokens > 0 {
+			fmt.Fprintf(b, "max-input-tokens = %d\n", budget.MaxInputTokens)
+		}
+		if budget.MaxOutputTokens > 0 {
+			fmt.Fprintf(b, "max-output-tokens = %d\n", budget.MaxOutputTokens)
+		}
+		if budget.MaxCostUSD > 0 {
+			fmt.Fprintf(b, "max-cost-usd = %s\n", formatConfigValue(budget.MaxCostUSD))
+		}
+	}
+	for i, policy := range llm.Tools {
+		if i > 0 || llm.Budget != nil {
+			b.WriteString("\n")
+		}
+		b.WriteString("[[llm.tools]]\n")
+		fmt.Fprintf(b, "tool = %s\n", formatConfigValue(policy.Tool))
+		if policy.ReadOnly {
+			b.WriteString("read-only = true\n")
+		}
+		fmt.Fprintf(b, "action = %s\n", formatConfigValue(policy.Action))
+	}
+}
+
 func writeConfigTable(b *strings.Builder, tablePath string, config map[string]any, leadingBlankLine bool) {
 	if len(config) == 0 {
 		return
@@ -1239,7 +1462,26 @@ func setConfigValue(cfg *Config, parts []string, value any) error { //nolint:goc
 				entry.Settings = map[string]any{}
 			}
 			entry.Settings[parts[3]] = value
-		case "up", "generate", "check":
+		case "check":
+			if len(parts) == 5 && parts[3] == "retries" {
+				retries, ok := value.(int64)
+				if !ok || retries < 0 {
+					return fmt.Errorf("modules.%s.check.retries.%s must be a non-negative integer", moduleName, formatConfigPathSegment(parts[4]))
+				}
+				if entry.Check.Retries == nil {
+					entry.Check.Retries = map[string]int{}
+				}
+				entry.Check.Retries[parts[4]] = int(retries)
+				break
+			}
+			if len(parts) != 4 || parts[3] != "skip" {
+				return fmt.Errorf("invalid key %q; expected modules.%s.check.skip or modules.%s.check.retries.<pattern>", strings.Join(parts, "."), moduleName, moduleName)
+			}
+			entry.Check.Skip = []string{fmt.Sprint(value)}
+			if s, ok := value.([]string); ok {
+				entry.Check.Skip = append([]string(nil), s...)
+			}
+		case "up", "generate":
 			if len(parts) != 4 || parts[3] != "skip" {
 				return fmt.Errorf("invalid key %q; expected modules.%s.%s.skip", strings.Join(parts, "."), moduleName, parts[2])
 			}
@@ -1252,8 +1494,32 @@ func setConfigValue(cfg *Config, parts []string, value any) error { //nolint:goc
 				entry.Up.Skip = skip
 			case "generate":
 				entry.Generate.Skip = skip
-			case "check":
-				entry.Check.Skip = skip
+			}
+		case "egress":
+			if len(parts) != 4 {
+				return fmt.Errorf("invalid key %q; expected modules.%s.egress.allow or modules.%s.egress.registries", strings.Join(parts, "."), moduleName, moduleName)
+			}
+			if entry.Egress == nil {
+				entry.Egress = &ModuleEgress{}
+			}
+			switch parts[3] {
+			case "allow":
+				allow := []string{fmt.Sprint(value)}
+				if s, ok := value.([]string); ok {
+					allow = append([]string(nil), s...)
+				}
+				entry.Egress.Allow = allow
+			case "registries":
+				boolValue, ok := value.(bool)
+				if !ok {
+					return fmt.Errorf("modules.%s.egress.registries must be a boolean", moduleName)
+				}
+				entry.Egress.Registries = boolValue
+			default:
+				return fmt.Errorf("invalid key %q; expected modules.%s.egress.allow or modules.%s.egress.registries", strings.Join(parts, "."), moduleName, moduleName)
+			}
+			if err := entry.Egress.Policy(moduleName).Validate(); err != nil {
+				return err
 			}
 		case "as-sdk":
 			if len(parts) != 4 || parts[3] != "name" {
@@ -164,8 +164,26 @@ func configDocumentMap(cfg *Config) map[string]any {
 			if len(entry.Generate.Skip) > 0 {
 				module["generate"] = map[string]any{"skip": append([]string(nil), entry.Generate.Skip...)}
 			}
-			if len(entry.Check.Skip) > 0 {
-				module["check"] = map[string]any{"skip": append([]string(nil), entry.Check.Skip...)}
+			if len(entry.Check.Skip) > 0 || len(entry.Check.Retries) > 0 {
+				check := map[string]any{}
+				if len(entry.Check.Skip) > 0 {
+					check["skip"] = append([]string(nil), entry.Check.Skip...)
+				}
+				if len(entry.Check.Retries) > 0 {
+					retries := make(map[string]any, len(entry.Check.Retries))
+					for pattern, n := range entry.Check.Retries {
+						retries[pattern] = int64(n)
+					}
+					check["retries"] = retries
+				}
+				module["check"] = check
+			}
+			if entry.Egress != nil {
+				egress := map[string]any{"allow": append([]string{}, entry.Egress.Allow...)}
+				if entry.Egress.Registries {
+					egress["registries"] = true
+				}
+				module["egress"] = egress
 			}
 			modules[name] = module
 		}
@@ -224,6 +242,11 @@ func configRequiresQuotedPathSegments(cfg *Config) bool {
 		if pathSegmentUnsafeForDocumentUpdate(moduleName) || configMapRequiresQuotedPathSegments(module.Settings) {
 			return true
 		}
+		for pattern := range module.Check.Retries {
+			if pathSegmentUnsafeForDocumentUpdate(pattern) {
+				return true
+			}
+		}
 	}
 	for envName, env := range cfg.Env {
 		if pathSegmentUnsafeForDocumentUpdate(envName) {
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/dagger/dagger/network"
 	"github.com/stretchr/testify/require"
 )
 
@@ -174,6 +175,100 @@ greeting = "hey"
 	require.Equal(t, "hey", applied.Modules["greeter"].Settings["greeting"])
 }
 
+func TestLLMBudgetConfig(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`[modules.greeter]
+source = "modules/greeter"
+
+[llm.budget]
+max-output-tokens = 200000
+max-cost-usd = 5
+`)
+
+	cfg, err := ParseConfig(data)
+	require.NoError(t, err)
+	require.Equal(t, &LLMBudget{MaxOutputTokens: 200000, MaxCostUSD: 5}, cfg.LLM.Budget)
+
+	reparsed, err := ParseConfig(SerializeConfig(cfg))
+	require.NoError(t, err)
+	require.Equal(t, cfg.LLM, reparsed.LLM)
+
+	_, err = ParseConfig([]byte("[llm.budget]\nmax-cost-usd = -1.5\n"))
+	require.ErrorContains(t, err, "llm.budget: limits must not be negative")
+}
+
+func TestLLMToolPoliciesConfig(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`[llm.budget]
+max-cost-usd = 5
+
+[[llm.tools]]
+tool = "*"
+action = "ask"
+
+[[llm.tools]]
+tool = "*"
+read-only = true
+action = "allow"
+
+[[llm.tools]]
+tool = "publish*"
+action = "deny"
+`)
+
+	cfg, err := ParseConfig(data)
+	require.NoError(t, err)
+	require.Equal(t, []LLMToolPolicy{
+		{Tool: "*", Action: "ask"},
+		{Tool: "*", ReadOnly: true, Action: "allow"},
+		{Tool: "publish*", Action: "deny"},
+	}, cfg.LLM.Tools)
+	require.Equal(t, string(data), string(SerializeConfig(cfg)))
+
+	_, err = ParseConfig([]byte("[[llm.tools]]\ntool = \"withExec\"\naction = \"maybe\"\n"))
+	require.ErrorContains(t, err, `llm.tools: invalid action "maybe" for "withExec"`)
+}
+
+func TestModuleEgressConfig(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`[modules.greeter]
+source = "modules/greeter"
+egress.allow = ["github.com", "10.0.0.0/8"]
+egress.registries = true
+
+[modules.sandboxed]
+source = "modules/sandboxed"
+egress.allow = []
+`)
+
+	cfg, err := ParseConfig(data)
+	require.NoError(t, err)
+	require.Equal(t, &ModuleEgress{
+		Allow:      []string{"github.com", "10.0.0.0/8"},
+		Registries: true,
+	}, cfg.Modules["greeter"].Egress)
+	require.Equal(t, network.EgressPolicy{
+		Allow:           []string{"github.com", "10.0.0.0/8"},
+		AllowRegistries: true,
+		Source:          "module greeter",
+	}, cfg.Modules["greeter"].Egress.Policy("greeter"))
+	require.NotNil(t, cfg.Modules["sandboxed"].Egress)
+	require.Empty(t, cfg.Modules["sandboxed"].Egress.Allow)
+
+	// an empty allow list is kept, since it denies all egress
+	serialized := string(SerializeConfig(cfg))
+	require.Contains(t, serialized, "egress.allow = []\n")
+	reparsed, err := ParseConfig([]byte(serialized))
+	require.NoError(t, err)
+	require.Equal(t, cfg.Modules, reparsed.Modules)
+
+	_, err = ParseConfig([]byte("[modules.greeter]\nsource = \"x\"\negress.allow = [\"not a domain\"]\n"))
+	require.ErrorContains(t, err, "modules.greeter.egress")
+}
+
 func TestWorkspaceCheckGeneratedSetting(t *testing.T) {
 	t.Parallel()
 
@@ -539,6 +634,28 @@ func TestWriteConfigValue(t *testing.T) {
 		require.Equal(t, &ModuleAsSDK{Name: "go"}, cfg.Modules["greeter"].AsSDK)
 	})
 
+	t.Run("writes module check retries", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := WriteConfigValue(nil, "modules.greeter.check.skip", "slow-check")
+		require.NoError(t, err)
+		data, err = WriteConfigValue(data, "modules.greeter.check.retries.integration", "2")
+		require.NoError(t, err)
+		data, err = WriteConfigValue(data, `modules.greeter.check.retries."e2e:*"`, "1")
+		require.NoError(t, err)
+
+		require.Contains(t, string(data), `check.retries."e2e:*" = 1`)
+		cfg, err := ParseConfig(data)
+		require.NoError(t, err)
+		require.Equal(t, []string{"slow-check"}, cfg.Modules["greeter"].Check.Skip)
+		require.Equal(t, map[string]int{"integration": 2, "e2e:*": 1}, cfg.Modules["greeter"].Check.Retries)
+
+		_, err = WriteConfigValue(data, "modules.greeter.check.retries.integration", "-1")
+		require.EqualError(t, err, "modules.greeter.check.retries.integration must be a non-negative integer")
+		_, err = ParseConfig([]byte("[modules.greeter]\nsource = \"greeter\"\ncheck.retries.integration = -1\n"))
+		require.EqualError(t, err, "parse dagger.toml: modules.greeter.check.retries.integration: must not be negative")
+	})
+
 	t.Run("writes quoted path segments", func(t *testing.T) {
 		t.Parallel()
 
@@ -570,7 +687,7 @@ func TestWriteConfigValue(t *testing.T) {
 		require.EqualError(t, err, "cannot set \"modules.greeter\" directly; specify a field like modules.greeter.settings")
 
 		_, err = WriteConfigValue(nil, "modules.greeter.unknown", "value")
-		require.EqualError(t, err, "unknown config key \"modules.greeter.unknown\"; valid fields at this level: as-sdk, check, entrypoint, generate, legacy-default-path, pin, settings, source, up")
+		require.EqualError(t, err, "unknown config key \"modules.greeter.unknown\"; valid fields at this level: as-sdk, check, egress, entrypoint, generate, legacy-default-path, pin, settings, source, up")
 
 		_, err = WriteConfigValue(nil, "ignore.path", "value")
 		require.EqualError(t, err, "invalid key \"ignore.path\"; ignore does not have sub-keys")
@@ -59,6 +59,15 @@ type CacheUsageEntry struct {
 	CreatedTimeUnixNano       int64
 	MostRecentUseTimeUnixNano int64
 	ActivelyUsed              bool
+
+	// ModuleName, ModuleRef and ModulePin identify the module whose function
+	// call produced the entry, if any, or else the module whose function was
+	// running when it was produced, e.g. for a container layer.
+	ModuleName string
+	ModuleRef  string
+	ModulePin  string
+	// Tags are the cache tags of the client whose call produced the entry.
+	Tags []string
 }
 
 type CachePrunePolicy struct {
@@ -136,7 +145,10 @@ type persistedEdge struct {
 // persisted as result refs. Older snapshots may hold untracked scalar handle
 // strings whose referents were never retained (and whose IDs may have been
 // reused), so they are wiped rather than imported.
-const cachePersistenceSchemaVersion = "17"
+// 18: results carry the cache tags of the client that produced them.
+// 19: results carry the module whose function was running when they were
+// produced.
+const cachePersistenceSchemaVersion = "19"
 
 var ErrCacheRecursiveCall = fmt.Errorf("recursive call detected")
 var ErrPersistStateNotReady = errors.New("persist state not ready")
@@ -1560,6 +1572,8 @@ type sharedResult struct {
 	cacheUsageRecordTypeByID map[string]string
 	description              string
 	recordType               string
+	cacheTags                []string
+	cacheModule              *engine.CacheModule
 
 	// incomingOwnershipCount is the authoritative liveness count derived from
 	// session edges, persisted edges, and result dependency edges.
@@ -3388,7 +3402,7 @@ func (c *Cache) usageEntriesLocked(activeRoots map[sharedResultID]struct{}) []Ca
 				sizeBytes += sz
 			}
 		}
-		entries = append(entries, CacheUsageEntry{
+		entry := CacheUsageEntry{
 			ID:                        fmt.Sprintf("dagql.result.%d", resID),
 			Description:               description,
 			RecordType:                recordType,
@@ -3398,7 +3412,9 @@ func (c *Cache) usageEntriesLocked(activeRoots map[sharedResultID]struct{}) []Ca
 			CreatedTimeUnixNano:       createdAt,
 			MostRecentUseTimeUnixNano: lastUsedAt,
 			ActivelyUsed:              activelyUsed,
-		})
+		}
+		setCacheUsageProvenance(&entry, res)
+		entries = append(entries, entry)
 	}
 
 	slices.SortFunc(entries, func(a, b CacheUsageEntry) int {
@@ -3414,6 +3430,41 @@ func (c *Cache) usageEntriesLocked(activeRoots map[sharedResultID]struct{}) []Ca
 	return entries
 }
 
+// setCacheUsageProvenance records the module and cache tags a result was
+// produced with on its usage entry.
+func setCacheUsageProvenance(entry *CacheUsageEntry, res *sharedResult) {
+	if frame := res.loadResultCall(); frame != nil && frame.Module != nil {
+		entry.ModuleName = frame.Module.Name
+		entry.ModuleRef = frame.Module.Ref
+		entry.ModulePin = frame.Module.Pin
+	} else if res.cacheModule != nil {
+		entry.ModuleName = res.cacheModule.Name
+		entry.ModuleRef = res.cacheModule.Ref
+		entry.ModulePin = res.cacheModule.Pin
+	}
+	entry.Tags = slices.Clone(res.cacheTags)
+}
+
+// cacheModuleFromContext returns the module whose function the client making
+// a call runs in, if any.
+func cacheModuleFromContext(ctx context.Context) *engine.CacheModule {
+	clientMetadata, err := engine.ClientMetadataFromContext(ctx)
+	if err != nil || clientMetadata.CacheModule == nil {
+		return nil
+	}
+	cacheMod := *clientMetadata.CacheModule
+	return &cacheMod
+}
+
+// cacheTagsFromContext returns the cache tags of the client making a call.
+func cacheTagsFromContext(ctx context.Context) []string {
+	clientMetadata, err := engine.ClientMetadataFromContext(ctx)
+	if err != nil || len(clientMetadata.CacheTags) == 0 {
+		return nil
+	}
+	return slices.Clone(clientMetadata.CacheTags)
+}
+
 func (c *Cache) cacheUsageDagqlCallLocked(res *sharedResult) string {
 	if c == nil || res == nil {
 		return ""
@@ -4395,6 +4446,12 @@ func (c *Cache) initCompletedResult(ctx context.Context, resolver TypeResolver,
 	if oc.res.description == "" {
 		oc.res.description = requestForIndex.Field
 	}
+	if oc.res.cacheTags == nil {
+		oc.res.cacheTags = cacheTagsFromContext(ctx)
+	}
+	if oc.res.cacheModule == nil {
+		oc.res.cacheModule = cacheModuleFromContext(ctx)
+	}
 	if oc.res.description == "" {
 		if reqDig, err := requestForIndex.deriveRecipeDigest(c); err == nil {
 			oc.res.description = reqDig.String()
@@ -172,6 +172,16 @@ func (c *Cache) importPersistedState(ctx context.Context) error {
 				recordType:            row.RecordType,
 				persistedEnvelope:     &env,
 			}
+			if row.CacheTagsJSON != "" {
+				if err := json.Unmarshal([]byte(row.CacheTagsJSON), &res.cacheTags); err != nil {
+					return fmt.Errorf("import result %d cache_tags_json: %w", resultID, err)
+				}
+			}
+			if row.CacheModuleJSON != "" {
+				if err := json.Unmarshal([]byte(row.CacheModuleJSON), &res.cacheModule); err != nil {
+					return fmt.Errorf("import result %d cache_module_json: %w", resultID, err)
+				}
+			}
 			res.storeResultCall(frame)
 			c.traceResultCallFrameUpdated(ctx, res, "import_persisted_result", nil, frame)
 
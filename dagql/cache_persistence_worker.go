@@ -8,6 +8,7 @@ import (
 	"slices"
 
 	persistdb "github.com/dagger/dagger/dagql/persistdb"
+	"github.com/dagger/dagger/engine"
 	"github.com/dagger/dagger/engine/slog"
 )
 
@@ -139,6 +140,8 @@ func (c *Cache) snapshotPersistState(ctx context.Context) (persistStateSnapshot,
 				LastUsedAtUnixNano: payload.lastUsedAtUnixNano,
 				RecordType:         res.recordType,
 				Description:        res.description,
+				CacheTagsJSON:      persistedCacheTagsJSON(res.cacheTags),
+				CacheModuleJSON:    persistedCacheModuleJSON(res.cacheModule),
 			},
 			resultDeps: resultDeps,
 		})
@@ -359,6 +362,24 @@ func (c *Cache) applyPersistStateSnapshot(ctx context.Context, snapshot persistS
 	return nil
 }
 
+func persistedCacheTagsJSON(tags []string) string {
+	if len(tags) == 0 {
+		return "[]"
+	}
+	// marshaling a string slice can't fail
+	tagsJSON, _ := json.Marshal(tags)
+	return string(tagsJSON)
+}
+
+func persistedCacheModuleJSON(cacheMod *engine.CacheModule) string {
+	if cacheMod == nil {
+		return ""
+	}
+	// marshaling a struct of strings can't fail
+	cacheModJSON, _ := json.Marshal(cacheMod)
+	return string(cacheModJSON)
+}
+
 func resultSnapshotLinkRows(resultID sharedResultID, links []PersistedSnapshotRefLink) []persistdb.MirrorResultSnapshotLink {
 	if len(links) == 0 {
 		return nil
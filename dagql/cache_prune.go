@@ -261,6 +261,16 @@ func metadataPruneLog(ctx context.Context, report CacheMetadataPruneReport, err
 }
 
 func (c *Cache) Prune(ctx context.Context, policies []CachePrunePolicy) (CachePruneReport, error) {
+	return c.prune(ctx, policies, false)
+}
+
+// PlanPrune reports the entries Prune would remove given the same policies,
+// and the bytes removing them would free, without removing anything.
+func (c *Cache) PlanPrune(ctx context.Context, policies []CachePrunePolicy) (CachePruneReport, error) {
+	return c.prune(ctx, policies, true)
+}
+
+func (c *Cache) prune(ctx context.Context, policies []CachePrunePolicy, dryRun bool) (CachePruneReport, error) {
 	report := CachePruneReport{}
 	if len(policies) == 0 {
 		return report, nil
@@ -292,6 +302,16 @@ func (c *Cache) Prune(ctx context.Context, policies []CachePrunePolicy) (CachePr
 			continue
 		}
 
+		if dryRun {
+			for _, planEntry := range plan {
+				plannedEntry := planEntry.candidate.entry
+				plannedEntry.SizeBytes = planEntry.reclaimBytes
+				report.Entries = append(report.Entries, plannedEntry)
+				report.ReclaimedBytes += planEntry.reclaimBytes
+			}
+			continue
+		}
+
 		policyReclaimed := int64(0)
 		policyApplied := 0
 		for _, planEntry := range plan {
@@ -478,6 +498,7 @@ func (c *Cache) snapshotPruneStateCancelable(
 			snapshotResult.entry.RecordTypes = recordTypes
 			snapshotResult.entry.DagqlCall = callLabel
 			snapshotResult.entry.SizeBytes = sizeBytes
+			setCacheUsageProvenance(&snapshotResult.entry, res)
 			snapshotResult.callLabel = callLabel
 			snapshotResult.callFrame = callFrame
 			snapshot.usedBytes += sizeBytes
@@ -1076,6 +1097,17 @@ func cachePrunePolicyMatchesEntry(policy CachePrunePolicy, entry CacheUsageEntry
 	return true
 }
 
+// cachePruneListFilterKeys are the filter keys whose values can be listed in
+// a single comma-separated filter, matching entries that match any of them,
+// e.g. "module==a,module==b".
+var cachePruneListFilterKeys = map[string]bool{
+	"type":       true,
+	"recordtype": true,
+	"module":     true,
+	"field":      true,
+	"tag":        true,
+}
+
 func cachePruneFilterMatchesEntry(filter string, entry CacheUsageEntry) bool {
 	filter = strings.TrimSpace(filter)
 	if filter == "" {
@@ -1084,25 +1116,25 @@ func cachePruneFilterMatchesEntry(filter string, entry CacheUsageEntry) bool {
 
 	if strings.Contains(filter, ",") {
 		clauses := strings.Split(filter, ",")
-		recordTypeFilter := true
-		recordTypeMatch := false
+		listFilter := true
+		listMatch := false
 		for _, clause := range clauses {
 			key, value, ok := strings.Cut(clause, "==")
 			if !ok {
-				recordTypeFilter = false
+				listFilter = false
 				break
 			}
 			key = strings.TrimSpace(strings.ToLower(key))
-			if key != "type" && key != "recordtype" {
-				recordTypeFilter = false
+			if !cachePruneListFilterKeys[key] {
+				listFilter = false
 				break
 			}
-			if cacheUsageEntryRecordTypeMatches(entry, strings.TrimSpace(value)) {
-				recordTypeMatch = true
+			if cachePruneClauseMatchesEntry(key, strings.TrimSpace(value), entry) {
+				listMatch = true
 			}
 		}
-		if recordTypeFilter {
-			return recordTypeMatch
+		if listFilter {
+			return listMatch
 		}
 	}
 
@@ -1110,8 +1142,10 @@ func cachePruneFilterMatchesEntry(filter string, entry CacheUsageEntry) bool {
 	if !ok {
 		return false
 	}
-	key = strings.TrimSpace(strings.ToLower(key))
-	value = strings.TrimSpace(value)
+	return cachePruneClauseMatchesEntry(strings.TrimSpace(strings.ToLower(key)), strings.TrimSpace(value), entry)
+}
+
+func cachePruneClauseMatchesEntry(key, value string, entry CacheUsageEntry) bool {
 	switch key {
 	case "id":
 		return entry.ID == value
@@ -1122,11 +1156,48 @@ func cachePruneFilterMatchesEntry(filter string, entry CacheUsageEntry) bool {
 	case "inuse":
 		want, err := strconv.ParseBool(value)
 		return err == nil && entry.ActivelyUsed == want
+	case "module":
+		return cacheUsageEntryModuleMatches(entry, value)
+	case "field":
+		return cacheUsageEntryFieldMatches(entry, value)
+	case "tag":
+		return value != "" && slices.Contains(entry.Tags, value)
 	default:
 		return false
 	}
 }
 
+// cacheUsageEntryModuleMatches reports whether an entry was produced by a
+// function of the given module, named by its name, its source ref with or
+// without a version, or its pinned commit.
+func cacheUsageEntryModuleMatches(entry CacheUsageEntry, value string) bool {
+	if value == "" {
+		return false
+	}
+	switch value {
+	case entry.ModuleName, entry.ModuleRef, entry.ModulePin:
+		return true
+	}
+	versionAt := strings.LastIndex(entry.ModuleRef, "@")
+	return versionAt > 0 && entry.ModuleRef[:versionAt] == value
+}
+
+// cacheUsageEntryFieldMatches reports whether an entry was produced by the
+// given field, named with its type (e.g. "Container.from") or alone ("from").
+func cacheUsageEntryFieldMatches(entry CacheUsageEntry, value string) bool {
+	if value == "" || entry.DagqlCall == "" {
+		return false
+	}
+	if strings.Contains(value, ".") {
+		return entry.DagqlCall == value
+	}
+	_, field, ok := strings.Cut(entry.DagqlCall, ".")
+	if !ok {
+		field = entry.DagqlCall
+	}
+	return field == value
+}
+
 func cacheUsageEntryRecordTypeMatches(entry CacheUsageEntry, value string) bool {
 	if value == "" {
 		return false
@@ -44,3 +44,38 @@ func TestCachePrunePolicyMatchesExistingScalarFilters(t *testing.T) {
 	require.False(t, cachePrunePolicyMatchesEntry(CachePrunePolicy{Filters: []string{"inuse==false"}}, entry))
 	require.False(t, cachePrunePolicyMatchesEntry(CachePrunePolicy{Filters: []string{"type==source.local"}}, entry))
 }
+
+func TestCachePrunePolicyMatchesProvenanceFilters(t *testing.T) {
+	entry := CacheUsageEntry{
+		DagqlCall:  "Container.from",
+		ModuleName: "jest",
+		ModuleRef:  "github.com/dagger/jest@v1.2.0",
+		ModulePin:  "0123abcd",
+		Tags:       []string{"nightly", "pr-1234"},
+	}
+
+	for _, filter := range []string{
+		"module==jest",
+		"module==github.com/dagger/jest",
+		"module==github.com/dagger/jest@v1.2.0",
+		"module==0123abcd",
+		"field==Container.from",
+		"field==from",
+		"tag==pr-1234",
+		"module==other,module==jest",
+		"tag==other,tag==nightly",
+		"module==other,tag==nightly",
+	} {
+		require.True(t, cachePrunePolicyMatchesEntry(CachePrunePolicy{Filters: []string{filter}}, entry), filter)
+	}
+	for _, filter := range []string{
+		"module==other",
+		"module==github.com/dagger",
+		"field==Directory.from",
+		"field==withExec",
+		"tag==pr-1",
+	} {
+		require.False(t, cachePrunePolicyMatchesEntry(CachePrunePolicy{Filters: []string{filter}}, entry), filter)
+	}
+	require.False(t, cachePrunePolicyMatchesEntry(CachePrunePolicy{Filters: []string{"module=="}}, CacheUsageEntry{}))
+}
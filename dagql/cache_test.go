@@ -6121,6 +6121,104 @@ func TestCachePruneKeepDuration(t *testing.T) {
 	assert.Assert(t, newStillPresent)
 }
 
+func TestCachePruneSelective(t *testing.T) {
+	t.Parallel()
+
+	ctx := cacheTestContext(t.Context())
+	c, err := NewCache(ctx, "", nil, nil)
+	assert.NilError(t, err)
+
+	taggedCtx := engine.ContextWithClientMetadata(t.Context(), &engine.ClientMetadata{
+		ClientID:  "dagql-test-client",
+		SessionID: "test-session",
+		CacheTags: []string{"pr-1234"},
+	})
+	results := map[string]AnyResult{}
+	for _, tc := range []struct {
+		ctx   context.Context
+		field string
+	}{
+		{ctx, "prune-selective-untagged"},
+		{taggedCtx, "prune-selective-tagged"},
+		{taggedCtx, "prune-selective-tagged-other"},
+	} {
+		key := cacheTestIntCall(tc.field)
+		res, err := c.GetOrInitCall(tc.ctx, "test-session", noopTypeResolver{}, &CallRequest{
+			ResultCall:    key,
+			IsPersistable: true,
+		}, func(context.Context) (AnyResult, error) {
+			return cacheTestSizedIntResult(key, 1, 50, "snapshot://"+tc.field, nil), nil
+		})
+		assert.NilError(t, err)
+		results[tc.field] = res
+	}
+	cacheTestReleaseSession(t, c, ctx)
+
+	policies := []CachePrunePolicy{{Filters: []string{
+		"tag==pr-1234",
+		"field==prune-selective-tagged,field==prune-selective-missing",
+	}}}
+	planned, err := c.PlanPrune(ctx, policies)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(planned.Entries))
+	assert.Equal(t, cacheTestSharedResultEntryID(results["prune-selective-tagged"]), planned.Entries[0].ID)
+	assert.DeepEqual(t, []string{"pr-1234"}, planned.Entries[0].Tags)
+	assert.Equal(t, int64(50), planned.ReclaimedBytes)
+	assert.Equal(t, 3, len(c.UsageEntriesAll(ctx)))
+
+	pruned, err := c.Prune(ctx, policies)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, planned, pruned)
+	assert.Equal(t, 2, len(c.UsageEntriesAll(ctx)))
+}
+
+func TestCachePruneModuleAttribution(t *testing.T) {
+	t.Parallel()
+
+	ctx := cacheTestContext(t.Context())
+	c, err := NewCache(ctx, "", nil, nil)
+	assert.NilError(t, err)
+
+	// results produced while a module's function runs, e.g. container layers,
+	// are attributed to the module
+	moduleCtx := engine.ContextWithClientMetadata(t.Context(), &engine.ClientMetadata{
+		ClientID:  "dagql-test-client",
+		SessionID: "test-session",
+		CacheModule: &engine.CacheModule{
+			Name: "hello",
+			Ref:  "github.com/dagger/hello@v1.0.0",
+			Pin:  "0123456789abcdef",
+		},
+	})
+	results := map[string]AnyResult{}
+	for _, tc := range []struct {
+		ctx   context.Context
+		field string
+	}{
+		{ctx, "prune-module-outside"},
+		{moduleCtx, "prune-module-inside"},
+	} {
+		key := cacheTestIntCall(tc.field)
+		res, err := c.GetOrInitCall(tc.ctx, "test-session", noopTypeResolver{}, &CallRequest{
+			ResultCall:    key,
+			IsPersistable: true,
+		}, func(context.Context) (AnyResult, error) {
+			return cacheTestSizedIntResult(key, 1, 50, "snapshot://"+tc.field, nil), nil
+		})
+		assert.NilError(t, err)
+		results[tc.field] = res
+	}
+	cacheTestReleaseSession(t, c, ctx)
+
+	for _, filter := range []string{"module==hello", "module==github.com/dagger/hello", "module==0123456789abcdef"} {
+		planned, err := c.PlanPrune(ctx, []CachePrunePolicy{{Filters: []string{filter}}})
+		assert.NilError(t, err)
+		assert.Equal(t, 1, len(planned.Entries), filter)
+		assert.Equal(t, cacheTestSharedResultEntryID(results["prune-module-inside"]), planned.Entries[0].ID)
+		assert.Equal(t, "hello", planned.Entries[0].ModuleName)
+	}
+}
+
 func TestCachePruneThresholdTargetSpace(t *testing.T) {
 	t.Parallel()
 
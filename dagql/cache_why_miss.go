@@ -0,0 +1,360 @@
+package dagql
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/opencontainers/go-digest"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dagger/dagger/dagql/call"
+)
+
+// maxMissCandidates bounds how many earlier calls of the same field are
+// compared when explaining a miss, most recent first.
+const maxMissCandidates = 256
+
+// CacheMissExplanation explains why a call executed instead of reusing a
+// cached result, by comparing it with the most similar call of the same field
+// cached before it.
+type CacheMissExplanation struct {
+	// Call is the recipe digest of the explained call.
+	Call digest.Digest
+	// Nearest is the recipe digest of the most similar call cached before it,
+	// or empty when no call of the same field was cached before.
+	Nearest digest.Digest
+	// Differences are the inputs that differ between the nearest call and the
+	// explained one. Differing object inputs are compared in turn, so these
+	// are the inputs that diverged first, e.g. the value of a withEnvVariable
+	// several calls up the receiver chain.
+	//
+	// Empty with Nearest set to Call means an identical call was cached but
+	// couldn't be reused: it expired, or needs secrets or sockets the calling
+	// session hasn't loaded.
+	Differences []CacheMissDifference
+}
+
+// CacheMissDifference is an input that differs between a call and the nearest
+// cached one.
+type CacheMissDifference struct {
+	// Call displays the call the input belongs to, as called this time.
+	Call string
+	// Input names the input: an argument name, an implicit input name suffixed
+	// with " (implicit)", or one of "field", "receiver", "module", "view",
+	// "nth" and "content".
+	Input string
+	// Cached displays the input as the nearest cached call had it.
+	Cached string
+	// Current displays the input as the explained call had it.
+	Current string
+}
+
+// ExplainMiss explains why the call with the given recipe digest executed,
+// comparing it with the nearest call of the same field cached before it. The
+// call must still be in the cache: calls that are never cached, or were
+// pruned since, can't be explained.
+func (c *Cache) ExplainMiss(ctx context.Context, dig digest.Digest) (*CacheMissExplanation, error) {
+	target, candidates, err := c.missCandidates(dig)
+	if err != nil {
+		return nil, err
+	}
+	targetID, err := target.recipeID(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("explain miss: %w", err)
+	}
+
+	explanation := &CacheMissExplanation{Call: targetID.Digest()}
+	var nearest *call.ID
+	var nearestScore int
+	for _, candidate := range candidates {
+		candidateID, err := candidate.recipeID(ctx, c)
+		if err != nil {
+			// best-effort: a candidate whose recipe can't be rebuilt (e.g. a
+			// dependency was pruned) just isn't a candidate
+			continue
+		}
+		// score shallowly; only the nearest call is compared in depth
+		score := len(c.diffCalls(candidateID, targetID, nil))
+		if nearest == nil || score < nearestScore {
+			nearest, nearestScore = candidateID, score
+		}
+		if score == 0 {
+			break
+		}
+	}
+	if nearest == nil {
+		return explanation, nil
+	}
+	explanation.Nearest = nearest.Digest()
+	explanation.Differences = c.diffCalls(nearest, targetID, map[[2]digest.Digest]struct{}{})
+	return explanation, nil
+}
+
+// missCandidates returns the most recent result cached for a digest, and the
+// other calls of the same field cached before it, most recent first.
+func (c *Cache) missCandidates(dig digest.Digest) (*ResultCall, []*ResultCall, error) {
+	c.egraphMu.RLock()
+	defer c.egraphMu.RUnlock()
+
+	var target *sharedResult
+	var targetCreated int64
+	if resultSet := c.egraphResultsByDigest[dig.String()]; resultSet != nil {
+		for resID := range resultSet.Items() {
+			res := c.resultsByID[resID]
+			if res == nil || res.loadResultCall() == nil {
+				continue
+			}
+			created := res.loadPayloadState().createdAtUnixNano
+			if target == nil || created > targetCreated {
+				target, targetCreated = res, created
+			}
+		}
+	}
+	if target == nil {
+		return nil, nil, fmt.Errorf("no cached call with digest %s", dig)
+	}
+	targetFrame := target.loadResultCall()
+
+	type candidate struct {
+		frame   *ResultCall
+		created int64
+	}
+	var candidates []candidate
+	for _, res := range c.resultsByID {
+		if res == target {
+			continue
+		}
+		frame := res.loadResultCall()
+		if frame == nil || !sameCallShape(frame, targetFrame) {
+			continue
+		}
+		created := res.loadPayloadState().createdAtUnixNano
+		if created > targetCreated {
+			continue
+		}
+		candidates = append(candidates, candidate{frame: frame, created: created})
+	}
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		return cmp.Compare(b.created, a.created)
+	})
+	if len(candidates) > maxMissCandidates {
+		candidates = candidates[:maxMissCandidates]
+	}
+	frames := make([]*ResultCall, len(candidates))
+	for i, candidate := range candidates {
+		frames[i] = candidate.frame
+	}
+	return targetFrame, frames, nil
+}
+
+// sameCallShape reports whether two calls select the same field of the same
+// kind of receiver, making one a candidate to explain a miss of the other.
+func sameCallShape(a, b *ResultCall) bool {
+	if a.Kind != b.Kind || a.Field != b.Field || a.SyntheticOp != b.SyntheticOp {
+		return false
+	}
+	if (a.Receiver == nil) != (b.Receiver == nil) {
+		return false
+	}
+	if a.Type == nil || b.Type == nil {
+		return a.Type == b.Type
+	}
+	return a.Type.NamedType == b.Type.NamedType
+}
+
+// equivalentCalls reports whether two calls are known to produce the same
+// result: they have the same recipe or content, or the e-graph has learned
+// they're equivalent.
+func (c *Cache) equivalentCalls(a, b *call.ID) bool {
+	if a.Digest() == b.Digest() {
+		return true
+	}
+	if content := a.ContentDigest(); content != "" && content == b.ContentDigest() {
+		return true
+	}
+	c.egraphMu.RLock()
+	defer c.egraphMu.RUnlock()
+	classA, okA := c.egraphDigestToClass[a.Digest().String()]
+	classB, okB := c.egraphDigestToClass[b.Digest().String()]
+	if !okA || !okB {
+		return false
+	}
+	root := c.findEqClassLocked(classA)
+	return root != 0 && root == c.findEqClassLocked(classB)
+}
+
+// diffCalls compares a cached call with a current one, input by input. With
+// a non-nil seen set, differing object inputs are compared in turn, reporting
+// the inputs that diverged first instead of the objects themselves.
+func (c *Cache) diffCalls(cached, current *call.ID, seen map[[2]digest.Digest]struct{}) []CacheMissDifference {
+	self := current.DisplaySelf()
+	if cached.Field() != current.Field() {
+		return []CacheMissDifference{{
+			Call:    self,
+			Input:   "field",
+			Cached:  cached.DisplaySelf(),
+			Current: self,
+		}}
+	}
+
+	var diffs []CacheMissDifference
+	diffIDs := func(input string, cachedID, currentID *call.ID) {
+		switch {
+		case cachedID == nil && currentID == nil:
+			return
+		case cachedID == nil || currentID == nil:
+		case c.equivalentCalls(cachedID, currentID):
+			return
+		case seen != nil:
+			key := [2]digest.Digest{cachedID.Digest(), currentID.Digest()}
+			if _, ok := seen[key]; ok {
+				return
+			}
+			seen[key] = struct{}{}
+			if nested := c.diffCalls(cachedID, currentID, seen); len(nested) > 0 {
+				diffs = append(diffs, nested...)
+				return
+			}
+		}
+		diffs = append(diffs, CacheMissDifference{
+			Call:    self,
+			Input:   input,
+			Cached:  displayMissCall(cachedID),
+			Current: displayMissCall(currentID),
+		})
+	}
+	diffArgs := func(suffix string, cachedArgs, currentArgs []*call.Argument) {
+		names := make([]string, 0, len(currentArgs)+len(cachedArgs))
+		for _, arg := range currentArgs {
+			names = append(names, arg.Name())
+		}
+		for _, arg := range cachedArgs {
+			if !slices.Contains(names, arg.Name()) {
+				names = append(names, arg.Name())
+			}
+		}
+		for _, name := range names {
+			cachedArg, currentArg := findMissArg(cachedArgs, name), findMissArg(currentArgs, name)
+			cachedLit, cachedIsID := missArgID(cachedArg)
+			currentLit, currentIsID := missArgID(currentArg)
+			if cachedIsID && currentIsID {
+				diffIDs(name+suffix, cachedLit, currentLit)
+				continue
+			}
+			if cachedArg != nil && currentArg != nil && proto.Equal(cachedArg.PB(), currentArg.PB()) {
+				continue
+			}
+			diffs = append(diffs, CacheMissDifference{
+				Call:    self,
+				Input:   name + suffix,
+				Cached:  displayMissArg(cachedArg),
+				Current: displayMissArg(currentArg),
+			})
+		}
+	}
+
+	diffIDs("receiver", cached.Receiver(), current.Receiver())
+	diffArgs("", cached.Args(), current.Args())
+	diffArgs(" (implicit)", cached.ImplicitInputs(), current.ImplicitInputs())
+	if cachedMod, currentMod := cached.Module(), current.Module(); cachedMod != nil || currentMod != nil {
+		if cachedMod == nil || currentMod == nil {
+			diffs = append(diffs, CacheMissDifference{
+				Call:    self,
+				Input:   "module",
+				Cached:  displayMissModule(cachedMod),
+				Current: displayMissModule(currentMod),
+			})
+		} else {
+			diffIDs("module", cachedMod.ID(), currentMod.ID())
+		}
+	}
+	if cached.View() != current.View() {
+		diffs = append(diffs, CacheMissDifference{
+			Call:    self,
+			Input:   "view",
+			Cached:  string(cached.View()),
+			Current: string(current.View()),
+		})
+	}
+	if cached.Nth() != current.Nth() {
+		diffs = append(diffs, CacheMissDifference{
+			Call:    self,
+			Input:   "nth",
+			Cached:  strconv.FormatInt(cached.Nth(), 10),
+			Current: strconv.FormatInt(current.Nth(), 10),
+		})
+	}
+	if len(diffs) == 0 && cached.ContentDigest() != current.ContentDigest() {
+		// same recipe over different content, e.g. a host directory that
+		// changed between runs
+		diffs = append(diffs, CacheMissDifference{
+			Call:    self,
+			Input:   "content",
+			Cached:  displayMissDigest(cached.ContentDigest()),
+			Current: displayMissDigest(current.ContentDigest()),
+		})
+	}
+	return diffs
+}
+
+func findMissArg(args []*call.Argument, name string) *call.Argument {
+	for _, arg := range args {
+		if arg.Name() == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+// missArgID returns the object an argument refers to, if it's an ID.
+func missArgID(arg *call.Argument) (*call.ID, bool) {
+	if arg == nil {
+		return nil, false
+	}
+	lit, ok := arg.Value().(*call.LiteralID)
+	if !ok {
+		return nil, false
+	}
+	return lit.Value(), true
+}
+
+func displayMissArg(arg *call.Argument) string {
+	switch {
+	case arg == nil:
+		return "(unset)"
+	case arg.IsSensitive():
+		return "***"
+	default:
+		return arg.Value().Display()
+	}
+}
+
+func displayMissCall(id *call.ID) string {
+	if id == nil {
+		return "(none)"
+	}
+	return id.DisplaySelf()
+}
+
+func displayMissModule(mod *call.Module) string {
+	if mod == nil {
+		return "(none)"
+	}
+	if mod.Ref() == "" {
+		return mod.Name()
+	}
+	if mod.Pin() == "" {
+		return mod.Ref()
+	}
+	return mod.Ref() + "@" + mod.Pin()
+}
+
+func displayMissDigest(dig digest.Digest) string {
+	if dig == "" {
+		return "(unknown)"
+	}
+	return dig.String()
+}
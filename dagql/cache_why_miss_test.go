@@ -0,0 +1,75 @@
+package dagql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"gotest.tools/v3/assert"
+)
+
+func cacheTestStringArgCall(field string, receiver *ResultCall, name, value string) *ResultCall {
+	frame := cacheTestIntCall(field)
+	if receiver != nil {
+		frame.Receiver = &ResultCallRef{Call: receiver}
+	}
+	frame.Args = []*ResultCallArg{{
+		Name: name,
+		Value: &ResultCallLiteral{
+			Kind:        ResultCallLiteralKindString,
+			StringValue: value,
+		},
+	}}
+	return frame
+}
+
+func TestCacheExplainMiss(t *testing.T) {
+	t.Parallel()
+	ctx, c := cacheTestEvidenceEnv(t)
+
+	run := func(frame *ResultCall) digest.Digest {
+		t.Helper()
+		_, err := c.GetOrInitCall(ctx, "test-session", noopTypeResolver{}, &CallRequest{ResultCall: frame}, func(context.Context) (AnyResult, error) {
+			return cacheTestIntResult(frame, 1), nil
+		})
+		assert.NilError(t, err)
+		return cacheTestCallDigest(frame)
+	}
+
+	base1 := cacheTestStringArgCall("from", nil, "image", "alpine:1")
+	run(base1)
+	cached := run(cacheTestStringArgCall("withExec", base1, "args", "make"))
+	run(cacheTestStringArgCall("withExec", base1, "args", "make test"))
+
+	// the arguments differ
+	missed := run(cacheTestStringArgCall("withExec", base1, "args", "make lint"))
+	explanation, err := c.ExplainMiss(ctx, missed)
+	assert.NilError(t, err)
+	assert.Equal(t, missed, explanation.Call)
+	assert.Equal(t, 1, len(explanation.Differences))
+	assert.Equal(t, "args", explanation.Differences[0].Input)
+	assert.Equal(t, `"make lint"`, explanation.Differences[0].Current)
+
+	// the receiver differs: the difference is traced to its first input
+	base2 := cacheTestStringArgCall("from", nil, "image", "alpine:2")
+	run(base2)
+	missed = run(cacheTestStringArgCall("withExec", base2, "args", "make"))
+	explanation, err = c.ExplainMiss(ctx, missed)
+	assert.NilError(t, err)
+	assert.Equal(t, cached, explanation.Nearest)
+	assert.DeepEqual(t, []CacheMissDifference{{
+		Call:    `from(image: "alpine:2")`,
+		Input:   "image",
+		Cached:  `"alpine:1"`,
+		Current: `"alpine:2"`,
+	}}, explanation.Differences)
+
+	// nothing of the same field was cached before
+	first := run(cacheTestStringArgCall("withDirectory", base1, "path", "/src"))
+	explanation, err = c.ExplainMiss(ctx, first)
+	assert.NilError(t, err)
+	assert.Equal(t, digest.Digest(""), explanation.Nearest)
+
+	_, err = c.ExplainMiss(ctx, digest.FromString("unknown"))
+	assert.ErrorContains(t, err, "no cached call with digest")
+}
@@ -7,9 +7,11 @@ import (
 // CheckNode is a surfaced trace-level check (deduped by check name), with any
 // nested child checks beneath it.
 type CheckNode struct {
-	Name     string
-	Span     *Span // representative span (a failed one when the check failed)
-	Failed   bool
+	Name   string
+	Span   *Span // representative span (a failed one when the check failed)
+	Failed bool
+	// Flaky is set on a check that didn't fail but passed only on a retry.
+	Flaky    bool
 	Children []*CheckNode
 }
 
@@ -32,7 +34,7 @@ type CheckNode struct {
 //
 // Checks are deduped by name (a check is failed if any of its spans failed) and
 // nested under the nearest surfaced ancestor check. Roots and children are
-// ordered failed-first, then by name.
+// ordered failed-first, then flaky, then by name.
 //
 // The result is cached per DB mutation: every input (check names, ancestor
 // chains, boundaries, statuses, the root span) only changes when a span is
@@ -53,6 +55,7 @@ func (db *DB) buildSurfacedChecks() []*CheckNode {
 		span       *Span
 		parentName string
 		failed     bool
+		flaky      bool
 	}
 	byName := map[string]*info{}
 	for span := range db.Spans.Iter() {
@@ -94,15 +97,22 @@ func (db *DB) buildSurfacedChecks() []*CheckNode {
 		}
 		failed := span.IsFailedOrCausedFailure()
 		cur, ok := byName[span.CheckName]
+		if ok {
+			cur.flaky = cur.flaky || span.CheckFlaky
+		}
 		switch {
 		case !ok:
-			byName[span.CheckName] = &info{span: span, parentName: parentName, failed: failed}
+			byName[span.CheckName] = &info{span: span, parentName: parentName, failed: failed, flaky: span.CheckFlaky}
 		case failed && !cur.failed:
 			// prefer a failed representative so the rendered detail points at the
 			// failure
 			cur.span = span
 			cur.failed = true
 			cur.parentName = parentName
+		case span.CheckFlaky && !cur.failed:
+			// likewise a flaky one, which carries the attempts
+			cur.span = span
+			cur.parentName = parentName
 		default:
 			cur.failed = cur.failed || failed
 		}
@@ -110,7 +120,7 @@ func (db *DB) buildSurfacedChecks() []*CheckNode {
 
 	nodes := make(map[string]*CheckNode, len(byName))
 	for name, in := range byName {
-		nodes[name] = &CheckNode{Name: name, Span: in.span, Failed: in.failed}
+		nodes[name] = &CheckNode{Name: name, Span: in.span, Failed: in.failed, Flaky: in.flaky && !in.failed}
 	}
 	var roots []*CheckNode
 	for name, in := range byName {
@@ -128,6 +138,9 @@ func (db *DB) buildSurfacedChecks() []*CheckNode {
 			if ns[i].Failed != ns[j].Failed {
 				return ns[i].Failed // failed first
 			}
+			if ns[i].Flaky != ns[j].Flaky {
+				return ns[i].Flaky
+			}
 			return ns[i].Name < ns[j].Name
 		})
 		for _, n := range ns {
@@ -163,3 +163,45 @@ func TestSurfacedChecksMemoizedPerFrame(t *testing.T) {
 		t.Fatalf("failed check must sort first, got %+v", fresh[0])
 	}
 }
+
+func TestSurfacedChecksFlaky(t *testing.T) {
+	const (
+		rootID byte = iota + 1
+		lintID
+		flakyID
+		brokenID
+	)
+	root := SpanID{SpanID: trace.SpanID{rootID}}
+	flaky := checkSnapshot(flakyID, "integration", root, "integration")
+	flaky.CheckFlaky = true
+	flaky.CheckAttempts = 2
+	broken := checkSnapshot(brokenID, "e2e", root, "e2e")
+	broken.Status = sdktrace.Status{Code: codes.Error}
+
+	db := NewDB()
+	db.ImportSnapshots([]SpanSnapshot{
+		checkSnapshot(rootID, "dagger check", SpanID{}, ""),
+		checkSnapshot(lintID, "lint", root, "lint"),
+		flaky,
+		broken,
+	})
+
+	roots := db.SurfacedChecks()
+	if len(roots) != 3 {
+		t.Fatalf("expected 3 checks, got %+v", roots)
+	}
+	// failed first, then flaky, then the rest
+	for i, want := range []struct {
+		name          string
+		failed, flaky bool
+	}{
+		{"e2e", true, false},
+		{"integration", false, true},
+		{"lint", false, false},
+	} {
+		if got := roots[i]; got.Name != want.name || got.Failed != want.failed || got.Flaky != want.flaky {
+			t.Errorf("check %d = %s (failed=%v flaky=%v), want %s (failed=%v flaky=%v)",
+				i, got.Name, got.Failed, got.Flaky, want.name, want.failed, want.flaky)
+		}
+	}
+}
@@ -295,6 +295,11 @@ type SpanSnapshot struct {
 	// Check name + status
 	CheckName   string `json:",omitempty"`
 	CheckPassed bool   `json:",omitempty"`
+	// Set on a check that passed on a retry, along with how many times it ran.
+	CheckFlaky    bool `json:",omitempty"`
+	CheckAttempts int  `json:",omitempty"`
+	// Set on each attempt of a check that may be retried, starting at 1.
+	CheckAttempt int `json:",omitempty"`
 
 	// Generator name
 	GeneratorName string `json:",omitempty"`
@@ -333,6 +338,10 @@ type SpanSnapshot struct {
 	CallPayload string `json:",omitempty"`
 	CallScope   string `json:",omitempty"`
 
+	// CacheOutcome is what the cache decided for the call, e.g. "executed"
+	// for a miss. See telemetryattrs.CacheOutcomeAttr.
+	CacheOutcome string `json:",omitempty"`
+
 	ChildCount int  `json:",omitempty"`
 	HasLogs    bool `json:",omitempty"`
 
@@ -385,6 +394,11 @@ func (link *SpanLink) IsWait() bool {
 	return link.Purpose == telemetryattrs.LinkPurposeWait && link.WaitEnd.After(link.WaitStart)
 }
 
+// CacheMissed reports whether the span's call missed the cache and executed.
+func (span *Span) CacheMissed() bool {
+	return span.CacheOutcome == telemetryattrs.CacheOutcomeExecuted
+}
+
 func (snapshot *SpanSnapshot) ProcessAttribute(name string, val any) { //nolint: gocyclo
 	defer func() {
 		// a bit of a shortcut, but there shouldn't be much going on
@@ -408,6 +422,9 @@ func (snapshot *SpanSnapshot) ProcessAttribute(name string, val any) { //nolint:
 	case telemetry.CachedAttr:
 		snapshot.Cached = val.(bool)
 
+	case telemetryattrs.CacheOutcomeAttr:
+		snapshot.CacheOutcome = val.(string)
+
 	case telemetry.CanceledAttr:
 		snapshot.Canceled = val.(bool)
 
@@ -454,6 +471,15 @@ func (snapshot *SpanSnapshot) ProcessAttribute(name string, val any) { //nolint:
 		// TODO: redundant with span status?
 		snapshot.CheckPassed = val.(bool)
 
+	case telemetryattrs.CheckFlakyAttr:
+		snapshot.CheckFlaky = val.(bool)
+
+	case telemetryattrs.CheckAttemptsAttr:
+		snapshot.CheckAttempts = int(asInt64(val))
+
+	case telemetryattrs.CheckAttemptAttr:
+		snapshot.CheckAttempt = int(asInt64(val))
+
 	case telemetry.GeneratorNameAttr:
 		snapshot.GeneratorName = val.(string)
 
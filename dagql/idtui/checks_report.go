@@ -109,14 +109,18 @@ func (fe *frontendPretty) renderCheckNode(ctx tuist.Context, out TermOutput, r *
 	}
 }
 
-// checkStatusLine renders a check's one-line status: its icon (red ✘ / green ✔),
-// name, and faint duration, at the given indent.
+// checkStatusLine renders a check's one-line status: its icon (red ✘ / yellow
+// ↻ / green ✔), name, and faint duration, at the given indent.
 func (fe *frontendPretty) checkStatusLine(out TermOutput, r *renderer, node *dagui.CheckNode, indent string) string {
 	icon, color := IconSuccess, termenv.ANSIGreen
 	status := "OK"
-	if node.Failed {
+	switch {
+	case node.Failed:
 		icon, color = IconFailure, termenv.ANSIRed
 		status = "ERROR"
+	case node.Flaky:
+		icon, color = IconFlaky, termenv.ANSIYellow
+		status = fmt.Sprintf("FLAKY (%d attempts)", node.Span.CheckAttempts)
 	}
 	dur := dagui.FormatDuration(node.Span.Activity.Duration(r.now))
 	return fmt.Sprintf("%s%s %s %s %s",
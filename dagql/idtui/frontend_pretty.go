@@ -222,6 +222,10 @@ type frontendPretty struct {
 	// regular tree expansion).
 	progressExpanded map[dagui.SpanID]bool
 
+	// missExplanations holds why the cache missed, for rows whose
+	// explanation was asked for (the "m" keybind).
+	missExplanations map[dagui.SpanID]string
+
 	// viewDirty is set when DB data changes (ExportSpans, LogExport) and
 	// cleared by recalculateViewLocked in Render. This coalesces multiple
 	// data updates into a single recalculate per render frame.
@@ -2275,6 +2279,10 @@ func (fe *frontendPretty) keys(out *termenv.Output) []key.Binding {
 			key.WithHelp("b", "branch"),
 			KeyEnabled(focused != nil && spanLLMCallDigest(focused) != "" && fe.shell != nil),
 		),
+		key.NewBinding(key.WithKeys("m"),
+			key.WithHelp("m", "why miss"),
+			KeyEnabled(fe.canExplainMiss(focused)),
+		),
 		key.NewBinding(key.WithKeys("L"),
 			key.WithHelp("L", "logs"),
 			KeyEnabled(fe.spanHasLogs(focused)),
@@ -2975,23 +2983,38 @@ func checksHeaderLine(out TermOutput, nodes []*dagui.CheckNode) string {
 	return line
 }
 
-// checkBreakdownPartsFor renders the failed/passed tallies as "✘ N failed" /
-// "✔ N passed" parts (via the test summary's renderer, so the two headers stay
-// in visual lockstep) for the given checks, counted directly rather than
+// checkBreakdownPartsFor renders the failed/flaky/passed tallies as "✘ N
+// failed" / "↻ N flaky" / "✔ N passed" parts (via the test summary's renderer,
+// so the two headers stay in visual lockstep) for the given checks, counted
+// directly rather than
 // recursively: each CHECKS header tallies the checks listed directly beneath
 // it. Boundaries are already honored by SurfacedChecks, so checks a test
 // intentionally runs aren't among the nodes. NB: with incremental --full
 // loading the passed tally only covers checks already fetched.
 func checkBreakdownPartsFor(out TermOutput, nodes []*dagui.CheckNode) []string {
 	var counts dagui.TestCounts
+	var flaky int
 	for _, n := range nodes {
-		if n.Failed {
+		switch {
+		case n.Failed:
 			counts.Failing++
-		} else {
+		case n.Flaky:
+			flaky++
+		default:
 			counts.Passing++
 		}
 	}
-	return renderTestCountParts(out, counts)
+	parts := renderTestCountParts(out, counts)
+	if flaky > 0 {
+		// between the failures and the passes
+		at := 0
+		if counts.Failing > 0 {
+			at = 1
+		}
+		parts = slices.Insert(parts, at,
+			out.String(fmt.Sprintf("%s %d flaky", IconFlaky, flaky)).Foreground(termenv.ANSIYellow).String())
+	}
+	return parts
 }
 
 // renderLogsLines returns the zoomed span's log output as lines.
@@ -4229,6 +4252,9 @@ func (fe *frontendPretty) handleNavKeyUV(ev uv.KeyPressEvent) {
 	case "b":
 		fe.branch()
 		return
+	case "m":
+		fe.explainMiss()
+		return
 	case "L":
 		fe.openFocusedLogs()
 		return
@@ -4562,6 +4588,62 @@ func (fe *frontendPretty) doBranch(encodedID string, summary BranchSummary) {
 	}
 }
 
+func (fe *frontendPretty) canExplainMiss(span *dagui.Span) bool {
+	return fe.dag != nil && span != nil && span.CallDigest != "" && span.CacheMissed()
+}
+
+// explainMiss toggles an inline explanation of why the focused step missed
+// the cache.
+func (fe *frontendPretty) explainMiss() {
+	focused := fe.db.Spans.Map[fe.FocusedSpan]
+	if !fe.canExplainMiss(focused) {
+		return
+	}
+	spanID := focused.ID
+	update := func() {
+		if st, ok := fe.spanTrees[spanID]; ok {
+			st.Update()
+		}
+	}
+	if _, shown := fe.missExplanations[spanID]; shown {
+		delete(fe.missExplanations, spanID)
+		update()
+		return
+	}
+	if fe.missExplanations == nil {
+		fe.missExplanations = make(map[dagui.SpanID]string)
+	}
+	fe.missExplanations[spanID] = "Explaining cache miss..."
+	update()
+	dag, digest := fe.dag, focused.CallDigest
+	go func() {
+		var text string
+		explanation, err := ExplainCacheMiss(fe.runCtx, dag, digest)
+		if err != nil {
+			text = fmt.Sprintf("Can't explain cache miss: %s", err)
+		} else {
+			text = explanation.String()
+		}
+		fe.dispatch(func() {
+			if _, shown := fe.missExplanations[spanID]; !shown {
+				return
+			}
+			fe.missExplanations[spanID] = text
+			update()
+		})
+	}()
+}
+
+func (fe *frontendPretty) renderMissExplanation(out TermOutput, span *dagui.Span, prefix string) {
+	text, ok := fe.missExplanations[span.ID]
+	if !ok {
+		return
+	}
+	for line := range strings.SplitSeq(text, "\n") {
+		fmt.Fprintln(out, prefix+out.String(line).Faint().String())
+	}
+}
+
 func (fe *frontendPretty) terminal() {
 	if !fe.FocusedSpan.IsValid() {
 		return
@@ -5060,6 +5142,7 @@ func (fe *frontendPretty) renderRowContentRest(ctx tuist.Context, out TermOutput
 		fe.renderStepError(out, r, row, prefix)
 	}
 	fe.renderDebug(out, row.Span, prefix+Block25+" ", false)
+	fe.renderMissExplanation(out, row.Span, prefix+Block25+" ")
 }
 
 // renderableErrorOrigins filters a failed span's tracked origins down to the
@@ -6275,6 +6358,9 @@ func (fe *frontendPretty) renderStatus(out TermOutput, span *dagui.Span) {
 	if fe.flowingMode() && span.IsRunningOrEffectsRunning() {
 		fmt.Fprint(out, out.String(" "))
 		fmt.Fprint(out, out.String("RUNNING").Foreground(termenv.ANSIYellow))
+	} else if span.CheckPassed && span.CheckFlaky {
+		fmt.Fprint(out, out.String(" "))
+		fmt.Fprint(out, out.String(fmt.Sprintf("FLAKY (%d attempts)", span.CheckAttempts)).Foreground(termenv.ANSIYellow))
 	} else if span.CheckPassed {
 		fmt.Fprint(out, out.String(" "))
 		fmt.Fprint(out, out.String("OK").Foreground(termenv.ANSIGreen))
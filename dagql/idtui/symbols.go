@@ -42,6 +42,7 @@ const (
 	IconSkipped         = "∅"
 	IconSuccess         = "✔"
 	IconFailure         = "✘"
+	IconFlaky           = "↻"
 	IconCached          = "$" // cache money
 	Diamond             = "◆"
 	LLMPrompt           = "❯"
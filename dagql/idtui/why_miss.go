@@ -0,0 +1,98 @@
+package idtui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"dagger.io/dagger"
+)
+
+const whyMissQuery = `query WhyMiss($digest: String!) {
+  engine {
+    localCache {
+      whyMiss(digest: $digest) {
+        call
+        nearest
+        differences {
+          call
+          input
+          cached
+          current
+        }
+      }
+    }
+  }
+}`
+
+// CacheMissExplanation is why a call executed instead of reusing a cached
+// result, as explained by EngineCache.whyMiss.
+type CacheMissExplanation struct {
+	Call        string
+	Nearest     string
+	Differences []CacheMissDifference
+}
+
+// CacheMissDifference is an input that differs between a call and the
+// nearest cached one.
+type CacheMissDifference struct {
+	Call    string
+	Input   string
+	Cached  string
+	Current string
+}
+
+// ExplainCacheMiss asks the engine why the call with the given digest
+// executed.
+func ExplainCacheMiss(ctx context.Context, dag *dagger.Client, digest string) (*CacheMissExplanation, error) {
+	var res struct {
+		Engine struct {
+			LocalCache struct {
+				WhyMiss CacheMissExplanation
+			}
+		}
+	}
+	err := dag.Do(ctx, &dagger.Request{
+		Query:     whyMissQuery,
+		OpName:    "WhyMiss",
+		Variables: map[string]any{"digest": digest},
+	}, &dagger.Response{
+		Data: &res,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res.Engine.LocalCache.WhyMiss, nil
+}
+
+// maxMissValueWidth caps how much of an input value is shown, since
+// arguments can be whole file contents.
+const maxMissValueWidth = 80
+
+// String renders the explanation as a short plain-text report.
+func (explanation *CacheMissExplanation) String() string {
+	switch {
+	case explanation.Nearest == "":
+		return "No call of the same field was cached before it."
+	case len(explanation.Differences) == 0:
+		return "An identical call was cached, but couldn't be reused: it expired, or needs secrets or sockets the client hadn't loaded."
+	}
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Compared with the nearest cached call (%s):\n", explanation.Nearest)
+	for _, diff := range explanation.Differences {
+		fmt.Fprintf(&buf, "- %s: %s was %s, now %s\n",
+			truncateMissValue(diff.Call),
+			diff.Input,
+			truncateMissValue(diff.Cached),
+			truncateMissValue(diff.Current))
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func truncateMissValue(value string) string {
+	runes := []rune(strings.Join(strings.Fields(value), " "))
+	if len(runes) <= maxMissValueWidth {
+		return string(runes)
+	}
+	return string(runes[:maxMissValueWidth-1]) + "…"
+}
@@ -12,6 +12,8 @@ type MirrorResult struct {
 	LastUsedAtUnixNano int64
 	RecordType         string
 	Description        string
+	CacheTagsJSON      string
+	CacheModuleJSON    string
 }
 
 type MirrorEqClass struct {
@@ -117,15 +119,16 @@ const insertMirrorResult = `
 INSERT INTO results (
 	id, call_frame_json, self_payload, output_effect_ids_json,
 	expires_at_unix, created_at_unix_nano,
-	last_used_at_unix_nano, record_type, description
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	last_used_at_unix_nano, record_type, description, cache_tags_json,
+	cache_module_json
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 func (q *Queries) InsertMirrorResult(ctx context.Context, arg MirrorResult) error {
 	_, err := q.exec(ctx, nil, insertMirrorResult,
 		arg.ID, arg.CallFrameJSON, arg.SelfPayload, arg.OutputEffectIDs,
 		arg.ExpiresAtUnix, arg.CreatedAtUnixNano, arg.LastUsedAtUnixNano,
-		arg.RecordType, arg.Description,
+		arg.RecordType, arg.Description, arg.CacheTagsJSON, arg.CacheModuleJSON,
 	)
 	return err
 }
@@ -231,7 +234,8 @@ const listMirrorResults = `
 SELECT
 	id, call_frame_json, self_payload, output_effect_ids_json,
 	expires_at_unix, created_at_unix_nano,
-	last_used_at_unix_nano, record_type, description
+	last_used_at_unix_nano, record_type, description, cache_tags_json,
+	cache_module_json
 FROM results
 `
 
@@ -255,6 +259,8 @@ func (q *Queries) ListMirrorResults(ctx context.Context) ([]MirrorResult, error)
 			&row.LastUsedAtUnixNano,
 			&row.RecordType,
 			&row.Description,
+			&row.CacheTagsJSON,
+			&row.CacheModuleJSON,
 		); err != nil {
 			return nil, err
 		}
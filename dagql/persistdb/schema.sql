@@ -12,7 +12,9 @@ CREATE TABLE IF NOT EXISTS results (
     created_at_unix_nano INTEGER NOT NULL,
     last_used_at_unix_nano INTEGER NOT NULL,
     record_type TEXT NOT NULL DEFAULT '',
-    description TEXT NOT NULL DEFAULT ''
+    description TEXT NOT NULL DEFAULT '',
+    cache_tags_json TEXT NOT NULL DEFAULT '[]',
+    cache_module_json TEXT NOT NULL DEFAULT ''
 ) STRICT;
 
 CREATE TABLE IF NOT EXISTS eq_classes (
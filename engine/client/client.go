@@ -115,6 +115,9 @@ type Params struct {
 
 	AllowedLLMModules []string
 
+	// CacheTags are attached to the cache entries produced by the session.
+	CacheTags []string
+
 	PromptHandler prompt.PromptHandler
 
 	Stdin  io.Reader
@@ -154,6 +157,10 @@ type Params struct {
 
 	// Profile enables engine wall-clock profiling (wcprof) for this session.
 	Profile bool
+
+	// SyncWatcher, if set, records the host directories synced to the engine,
+	// so the caller can watch them for changes.
+	SyncWatcher *SyncWatcher
 }
 
 type Client struct {
@@ -591,6 +598,9 @@ func (c *Client) startSession(ctx context.Context) (rerr error) {
 	if err != nil {
 		return fmt.Errorf("new filesyncer: %w", err)
 	}
+	if c.Params.SyncWatcher != nil {
+		filesyncer = filesyncer.WithWatcher(c.Params.SyncWatcher)
+	}
 	attachables = append(attachables, filesyncer.AsSource(), filesyncer.AsTarget())
 	if c.Params.PromptHandler != nil {
 		attachables = append(attachables, prompt.NewPromptAttachable(c.Params.PromptHandler))
@@ -874,6 +884,13 @@ func (c *Client) Close() (rerr error) {
 		}
 	}
 
+	// release any engine provisioned just for this client
+	if closeable, ok := c.connector.(io.Closer); ok {
+		if err := closeable.Close(); err != nil {
+			rerr = errors.Join(rerr, fmt.Errorf("close engine connector: %w", err))
+		}
+	}
+
 	return rerr
 }
 
@@ -1447,6 +1464,7 @@ func (c *Client) clientMetadata() engine.ClientMetadata {
 		InteractiveCommand:             c.InteractiveCommand,
 		SSHAuthSocketPath:              sshAuthSock,
 		AllowedLLMModules:              c.AllowedLLMModules,
+		CacheTags:                      c.CacheTags,
 		EagerRuntime:                   c.EagerRuntime,
 		SingleQuery:                    c.SingleQuery,
 		SuppressCompatWorkspaceWarning: c.SuppressCompatWorkspaceWarning,
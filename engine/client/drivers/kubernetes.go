@@ -0,0 +1,621 @@
+package drivers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	telemetry "github.com/dagger/otel-go"
+	"go.opentelemetry.io/otel"
+
+	"github.com/dagger/dagger/engine/client/imageload"
+	"github.com/dagger/dagger/engine/distconsts"
+	"github.com/dagger/dagger/engine/slog"
+	"github.com/dagger/dagger/util/kubeconfig"
+)
+
+func init() {
+	register("k8s-pod", &kubernetesDriver{})
+	register("k8s-job", &kubernetesDriver{job: true})
+}
+
+const (
+	k8sEngineContainerName = "dagger-engine"
+	k8sDefaultEnginePort   = 1234
+	k8sDefaultNamespace    = "default"
+
+	// k8sEngineLabel is set on all the pods and jobs created by the driver,
+	// to find leftover engines
+	k8sEngineLabel = "dagger.io/engine"
+)
+
+var k8sPollInterval = time.Second
+
+// kubernetesDriver runs an engine in a Kubernetes cluster, and connects to it
+// by port-forwarding through the API server:
+//
+//	k8s-pod://<image ref>?namespace=<ns>&context=<ctx>
+//	k8s-job://<image ref>?namespace=<ns>&context=<ctx>
+//
+// k8s-pod runs a long-lived pod named after the image version, like the
+// image driver does with containers, and reuses it across sessions. k8s-job
+// runs a job per session, deleted when the session ends.
+type kubernetesDriver struct {
+	job bool
+}
+
+func (d *kubernetesDriver) Available(ctx context.Context) (bool, error) {
+	return true, nil // assume always available, errors are reported when provisioning
+}
+
+func (d *kubernetesDriver) ImageLoader(ctx context.Context) imageload.Backend {
+	return nil
+}
+
+type k8sCreateOpts struct {
+	imageRef  string
+	namespace string
+	name      string
+	volume    string
+	port      int
+	env       []string
+	cpus      string
+	memory    string
+	cleanup   bool
+}
+
+func (d *kubernetesDriver) Provision(ctx context.Context, target *url.URL, opts *DriverOpts) (_ Connector, rerr error) {
+	query := target.Query()
+	cluster, err := kubeconfig.Load(query.Get("context"))
+	if err != nil {
+		return nil, err
+	}
+
+	cleanup := true
+	if val, ok := os.LookupEnv("DAGGER_LEAVE_OLD_ENGINE"); ok {
+		b, _ := strconv.ParseBool(val)
+		cleanup = !b
+	} else if val := query.Get("cleanup"); val != "" {
+		cleanup, _ = strconv.ParseBool(val)
+	}
+
+	createOpts := k8sCreateOpts{
+		imageRef:  target.Host + target.Path,
+		namespace: query.Get("namespace"),
+		name:      query.Get("name"),
+		volume:    query.Get("volume"),
+		port:      k8sDefaultEnginePort,
+		env:       query["env"],
+		cpus:      query.Get("cpus"),
+		memory:    query.Get("memory"),
+		cleanup:   cleanup,
+	}
+	if createOpts.namespace == "" {
+		createOpts.namespace = cluster.Namespace
+	}
+	if createOpts.namespace == "" {
+		createOpts.namespace = k8sDefaultNamespace
+	}
+	if port := query.Get("port"); port != "" {
+		createOpts.port, err = strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q: %w", port, err)
+		}
+	}
+	if createOpts.name == "" {
+		id, err := resolveImageID(createOpts.imageRef)
+		if err != nil {
+			return nil, err
+		}
+		createOpts.name = k8sName(containerNamePrefix + id)
+	}
+
+	api := &k8sAPI{cluster: cluster, client: cluster.HTTPClient()}
+
+	ctx, span := otel.Tracer("").Start(ctx, "create kubernetes engine")
+	defer telemetry.EndWithCause(span, &rerr)
+
+	var conn *k8sConnector
+	if d.job {
+		conn, err = d.createJob(ctx, api, createOpts, opts)
+	} else {
+		conn, err = d.createPod(ctx, api, createOpts, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := api.waitPodReady(ctx, createOpts.namespace, conn.pod); err != nil {
+		conn.closeIfCleanup()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// createPod starts the engine pod for the image version, unless it's already
+// running, and removes the pods the driver started for other versions.
+func (d *kubernetesDriver) createPod(ctx context.Context, api *k8sAPI, opts k8sCreateOpts, dopts *DriverOpts) (*k8sConnector, error) {
+	slog := slog.SpanLogger(ctx, InstrumentationLibrary)
+
+	conn := &k8sConnector{api: api, namespace: opts.namespace, pod: opts.name, port: opts.port}
+
+	var existing k8sPod
+	err := api.do(ctx, http.MethodGet, k8sPodsPath(opts.namespace)+"/"+opts.name, nil, &existing)
+	switch {
+	case err == nil && existing.Metadata.DeletionTimestamp == nil &&
+		existing.Status.Phase != "Failed" && existing.Status.Phase != "Succeeded":
+		// already running (or starting), reuse it
+	case err == nil || errors.Is(err, errK8sNotFound):
+		if err == nil {
+			// the pod is done or going away; replace it
+			if err := api.deleteAndWait(ctx, k8sPodsPath(opts.namespace)+"/"+opts.name); err != nil {
+				return nil, fmt.Errorf("failed to delete stopped engine pod: %w", err)
+			}
+		}
+		pod := k8sEnginePod(opts, dopts)
+		pod.Spec.RestartPolicy = "Always"
+		if err := api.do(ctx, http.MethodPost, k8sPodsPath(opts.namespace), pod, nil); err != nil &&
+			!errors.Is(err, errK8sAlreadyExists) { // maybe someone else started it simultaneously?
+			return nil, fmt.Errorf("failed to create engine pod: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to get engine pod: %w", err)
+	}
+
+	if opts.cleanup {
+		// garbage collect the pods of other versions of the engine
+		var pods k8sList[k8sPod]
+		if err := api.do(ctx, http.MethodGet, k8sPodsPath(opts.namespace)+"?labelSelector="+url.QueryEscape(k8sEngineLabel+"=pod"), nil, &pods); err != nil {
+			slog.Warn("failed to list engine pods", "error", err)
+		}
+		for _, pod := range pods.Items {
+			// leave alone the pods named by users, like the container
+			// driver does with containers
+			if pod.Metadata.Name == opts.name || !strings.HasPrefix(pod.Metadata.Name, containerNamePrefix) {
+				continue
+			}
+			if err := api.do(ctx, http.MethodDelete, k8sPodsPath(opts.namespace)+"/"+pod.Metadata.Name, nil, nil); err != nil && !errors.Is(err, errK8sNotFound) {
+				slog.Warn("failed to remove old engine pod", "pod", pod.Metadata.Name, "error", err)
+			}
+		}
+	}
+	return conn, nil
+}
+
+// createJob starts a job running the engine for the session.
+func (d *kubernetesDriver) createJob(ctx context.Context, api *k8sAPI, opts k8sCreateOpts, dopts *DriverOpts) (*k8sConnector, error) {
+	pod := k8sEnginePod(opts, dopts)
+	pod.Metadata.Labels[k8sEngineLabel] = "job"
+	pod.Spec.RestartPolicy = "Never"
+	backoffLimit := 0
+	job := k8sJob{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Metadata: k8sObjectMeta{
+			GenerateName: opts.name + "-",
+			Namespace:    opts.namespace,
+			Labels:       map[string]string{k8sEngineLabel: "job"},
+		},
+		Spec: k8sJobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: k8sPodTemplate{
+				Metadata: k8sObjectMeta{Labels: pod.Metadata.Labels},
+				Spec:     pod.Spec,
+			},
+		},
+	}
+	var created k8sJob
+	if err := api.do(ctx, http.MethodPost, k8sJobsPath(opts.namespace), job, &created); err != nil {
+		return nil, fmt.Errorf("failed to create engine job: %w", err)
+	}
+	conn := &k8sConnector{
+		api:       api,
+		namespace: opts.namespace,
+		job:       created.Metadata.Name,
+		port:      opts.port,
+		cleanup:   opts.cleanup,
+	}
+
+	// find the pod of the job
+	selector := url.QueryEscape("job-name=" + created.Metadata.Name)
+	for {
+		var pods k8sList[k8sPod]
+		if err := api.do(ctx, http.MethodGet, k8sPodsPath(opts.namespace)+"?labelSelector="+selector, nil, &pods); err != nil {
+			conn.closeIfCleanup()
+			return nil, fmt.Errorf("failed to list engine job pods: %w", err)
+		}
+		if len(pods.Items) > 0 {
+			conn.pod = pods.Items[0].Metadata.Name
+			return conn, nil
+		}
+		select {
+		case <-ctx.Done():
+			conn.closeIfCleanup()
+			return nil, fmt.Errorf("waiting for engine job %s to start: %w", created.Metadata.Name, context.Cause(ctx))
+		case <-time.After(k8sPollInterval):
+		}
+	}
+}
+
+// k8sEnginePod returns the pod running the engine, with the same settings as
+// the container started by the image driver.
+func k8sEnginePod(opts k8sCreateOpts, dopts *DriverOpts) *k8sPod {
+	ctr := k8sContainer{
+		Name:  k8sEngineContainerName,
+		Image: opts.imageRef,
+		Args: []string{
+			"--debug", "--debugaddr", defaultDebugListenerAddress,
+			"--addr", fmt.Sprintf("tcp://0.0.0.0:%d", opts.port),
+			"--addr", "unix:///run/dagger/engine.sock",
+		},
+		Ports: []k8sContainerPort{{Name: "dagger", ContainerPort: opts.port}},
+		ReadinessProbe: &k8sProbe{
+			TCPSocket:     &k8sTCPSocketAction{Port: opts.port},
+			PeriodSeconds: 1,
+		},
+		SecurityContext: &k8sSecurityContext{Privileged: true},
+		VolumeMounts: []k8sVolumeMount{{
+			Name:      "data",
+			MountPath: distconsts.EngineDefaultStateDir,
+		}},
+	}
+	for _, env := range opts.env {
+		k, v, ok := strings.Cut(env, "=")
+		if !ok {
+			v = os.Getenv(k)
+		}
+		ctr.Env = append(ctr.Env, k8sEnvVar{Name: k, Value: v})
+	}
+	if dopts != nil && dopts.DaggerCloudToken != "" {
+		ctr.Env = append(ctr.Env, k8sEnvVar{Name: EnvDaggerCloudToken, Value: dopts.DaggerCloudToken})
+	}
+	limits := map[string]string{}
+	if opts.cpus != "" {
+		limits["cpu"] = opts.cpus
+	}
+	if opts.memory != "" {
+		limits["memory"] = opts.memory
+	}
+	if dopts != nil && dopts.GPUSupport != "" {
+		limits["nvidia.com/gpu"] = "1"
+		ctr.Env = append(ctr.Env, k8sEnvVar{Name: EnvGPUSupport, Value: dopts.GPUSupport})
+	}
+	if len(limits) > 0 {
+		ctr.Resources = &k8sResources{Limits: limits}
+	}
+
+	data := k8sVolume{Name: "data", EmptyDir: &struct{}{}}
+	if opts.volume != "" {
+		data = k8sVolume{Name: "data", PersistentVolumeClaim: &k8sPVCSource{ClaimName: opts.volume}}
+	}
+
+	return &k8sPod{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Metadata: k8sObjectMeta{
+			Name:      opts.name,
+			Namespace: opts.namespace,
+			Labels:    map[string]string{k8sEngineLabel: "pod"},
+		},
+		Spec: k8sPodSpec{
+			Containers: []k8sContainer{ctr},
+			Volumes:    []k8sVolume{data},
+		},
+	}
+}
+
+var k8sInvalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// k8sName turns a container name into a valid Kubernetes object name.
+func k8sName(name string) string {
+	name = k8sInvalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.Trim(name, "-.")
+}
+
+// k8sConnector connects to an engine pod through the API server.
+type k8sConnector struct {
+	api       *k8sAPI
+	namespace string
+	pod       string
+	port      int
+
+	// job is set for engines started by k8s-job, and deleted on Close if
+	// cleanup is set
+	job     string
+	cleanup bool
+}
+
+func (c *k8sConnector) Connect(ctx context.Context) (net.Conn, error) {
+	return c.api.portForward(ctx, c.namespace, c.pod, c.port)
+}
+
+func (c *k8sConnector) EngineID() string {
+	return c.pod
+}
+
+// Close deletes the job started for the session.
+func (c *k8sConnector) Close() error {
+	if c.job == "" || !c.cleanup {
+		return nil
+	}
+	// the pods of the job are deleted along with it
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	err := c.api.do(ctx, http.MethodDelete, k8sJobsPath(c.namespace)+"/"+c.job+"?propagationPolicy=Background", nil, nil)
+	if err != nil && !errors.Is(err, errK8sNotFound) {
+		return fmt.Errorf("failed to delete engine job %s: %w", c.job, err)
+	}
+	return nil
+}
+
+func (c *k8sConnector) closeIfCleanup() {
+	if c.cleanup {
+		_ = c.Close()
+	}
+}
+
+var (
+	errK8sNotFound      = errors.New("not found")
+	errK8sAlreadyExists = errors.New("already exists")
+)
+
+// k8sAPI is a minimal client for the Kubernetes API.
+type k8sAPI struct {
+	cluster *kubeconfig.Cluster
+	client  *http.Client
+}
+
+func k8sPodsPath(namespace string) string {
+	return "/api/v1/namespaces/" + url.PathEscape(namespace) + "/pods"
+}
+
+func k8sJobsPath(namespace string) string {
+	return "/apis/batch/v1/namespaces/" + url.PathEscape(namespace) + "/jobs"
+}
+
+func (api *k8sAPI) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, api.cluster.Server+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	api.cluster.Authorize(req)
+	resp, err := api.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var status struct {
+			Message string `json:"message"`
+			Reason  string `json:"reason"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&status)
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			return fmt.Errorf("%s: %w", status.Message, errK8sNotFound)
+		case resp.StatusCode == http.StatusConflict && status.Reason == "AlreadyExists":
+			return fmt.Errorf("%s: %w", status.Message, errK8sAlreadyExists)
+		default:
+			return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, status.Message)
+		}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// deleteAndWait deletes an object and waits until it's gone.
+func (api *k8sAPI) deleteAndWait(ctx context.Context, path string) error {
+	if err := api.do(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		if errors.Is(err, errK8sNotFound) {
+			return nil
+		}
+		return err
+	}
+	for {
+		if err := api.do(ctx, http.MethodGet, path, nil, nil); err != nil {
+			if errors.Is(err, errK8sNotFound) {
+				return nil
+			}
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(k8sPollInterval):
+		}
+	}
+}
+
+// k8sFatalWaitingReasons are the reasons of a waiting container that won't
+// start without intervention.
+var k8sFatalWaitingReasons = []string{
+	"ErrImagePull",
+	"ImagePullBackOff",
+	"InvalidImageName",
+	"CreateContainerConfigError",
+	"CrashLoopBackOff",
+}
+
+// waitPodReady polls a pod until it's ready, failing early if it can't start.
+func (api *k8sAPI) waitPodReady(ctx context.Context, namespace, name string) (rerr error) {
+	ctx, span := otel.Tracer("").Start(ctx, "wait for engine pod "+name)
+	defer telemetry.EndWithCause(span, &rerr)
+
+	for {
+		var pod k8sPod
+		if err := api.do(ctx, http.MethodGet, k8sPodsPath(namespace)+"/"+name, nil, &pod); err != nil && !errors.Is(err, errK8sNotFound) {
+			return fmt.Errorf("failed to get engine pod: %w", err)
+		}
+		if pod.Status.Phase == "Failed" || pod.Status.Phase == "Succeeded" {
+			return fmt.Errorf("engine pod %s exited: %s", name, pod.Status.Message)
+		}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == "Ready" && cond.Status == "True" {
+				return nil
+			}
+		}
+		for _, ctr := range pod.Status.ContainerStatuses {
+			if waiting := ctr.State.Waiting; waiting != nil {
+				for _, reason := range k8sFatalWaitingReasons {
+					if waiting.Reason == reason {
+						return fmt.Errorf("engine pod %s failed to start: %s: %s", name, waiting.Reason, waiting.Message)
+					}
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for engine pod %s to be ready: %w", name, context.Cause(ctx))
+		case <-time.After(k8sPollInterval):
+		}
+	}
+}
+
+// The subset of the Kubernetes API types used by the driver.
+
+type k8sObjectMeta struct {
+	Name              string            `json:"name,omitempty"`
+	GenerateName      string            `json:"generateName,omitempty"`
+	Namespace         string            `json:"namespace,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	DeletionTimestamp *time.Time        `json:"deletionTimestamp,omitempty"`
+}
+
+type k8sList[T any] struct {
+	Items []T `json:"items"`
+}
+
+type k8sPod struct {
+	APIVersion string        `json:"apiVersion,omitempty"`
+	Kind       string        `json:"kind,omitempty"`
+	Metadata   k8sObjectMeta `json:"metadata"`
+	Spec       k8sPodSpec    `json:"spec"`
+	Status     k8sPodStatus  `json:"status,omitzero"`
+}
+
+type k8sPodSpec struct {
+	Containers    []k8sContainer `json:"containers"`
+	Volumes       []k8sVolume    `json:"volumes,omitempty"`
+	RestartPolicy string         `json:"restartPolicy,omitempty"`
+}
+
+type k8sContainer struct {
+	Name            string              `json:"name"`
+	Image           string              `json:"image"`
+	Args            []string            `json:"args,omitempty"`
+	Env             []k8sEnvVar         `json:"env,omitempty"`
+	Ports           []k8sContainerPort  `json:"ports,omitempty"`
+	ReadinessProbe  *k8sProbe           `json:"readinessProbe,omitempty"`
+	Resources       *k8sResources       `json:"resources,omitempty"`
+	SecurityContext *k8sSecurityContext `json:"securityContext,omitempty"`
+	VolumeMounts    []k8sVolumeMount    `json:"volumeMounts,omitempty"`
+}
+
+type k8sEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type k8sContainerPort struct {
+	Name          string `json:"name,omitempty"`
+	ContainerPort int    `json:"containerPort"`
+}
+
+type k8sProbe struct {
+	TCPSocket     *k8sTCPSocketAction `json:"tcpSocket,omitempty"`
+	PeriodSeconds int                 `json:"periodSeconds,omitempty"`
+}
+
+type k8sTCPSocketAction struct {
+	Port int `json:"port"`
+}
+
+type k8sResources struct {
+	Limits map[string]string `json:"limits,omitempty"`
+}
+
+type k8sSecurityContext struct {
+	Privileged bool `json:"privileged"`
+}
+
+type k8sVolumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+}
+
+type k8sVolume struct {
+	Name                  string        `json:"name"`
+	EmptyDir              *struct{}     `json:"emptyDir,omitempty"`
+	PersistentVolumeClaim *k8sPVCSource `json:"persistentVolumeClaim,omitempty"`
+}
+
+type k8sPVCSource struct {
+	ClaimName string `json:"claimName"`
+}
+
+type k8sPodStatus struct {
+	Phase             string               `json:"phase,omitempty"`
+	Message           string               `json:"message,omitempty"`
+	Conditions        []k8sPodCondition    `json:"conditions,omitempty"`
+	ContainerStatuses []k8sContainerStatus `json:"containerStatuses,omitempty"`
+}
+
+type k8sPodCondition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+type k8sContainerStatus struct {
+	Name  string `json:"name"`
+	State struct {
+		Waiting *struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"waiting,omitempty"`
+	} `json:"state"`
+}
+
+type k8sJob struct {
+	APIVersion string        `json:"apiVersion,omitempty"`
+	Kind       string        `json:"kind,omitempty"`
+	Metadata   k8sObjectMeta `json:"metadata"`
+	Spec       k8sJobSpec    `json:"spec"`
+}
+
+type k8sJobSpec struct {
+	BackoffLimit *int           `json:"backoffLimit,omitempty"`
+	Template     k8sPodTemplate `json:"template"`
+}
+
+type k8sPodTemplate struct {
+	Metadata k8sObjectMeta `json:"metadata"`
+	Spec     k8sPodSpec    `json:"spec"`
+}
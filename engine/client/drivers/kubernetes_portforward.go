@@ -0,0 +1,161 @@
+package drivers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// The port-forward protocol of the Kubernetes API over WebSockets: each
+// message is prefixed with its channel, and each forwarded port has a data
+// channel and an error channel. The first two bytes received on each channel
+// are the port number.
+const (
+	k8sPortForwardProtocol = "v4.channel.k8s.io"
+	k8sDataChannel         = 0
+	k8sErrorChannel        = 1
+)
+
+// portForward opens a connection to a port of a pod, through the API server.
+func (api *k8sAPI) portForward(ctx context.Context, namespace, pod string, port int) (net.Conn, error) {
+	u, err := url.Parse(api.cluster.Server + k8sPodsPath(namespace) + "/" + url.PathEscape(pod) + "/portforward")
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+	u.RawQuery = url.Values{"ports": {strconv.Itoa(port)}}.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	api.cluster.Authorize(req)
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		TLSClientConfig:  api.cluster.TLSConfig,
+		Subprotocols:     []string{k8sPortForwardProtocol},
+		HandshakeTimeout: 30 * time.Second,
+	}
+	ws, resp, err := dialer.DialContext(ctx, u.String(), req.Header)
+	if err != nil {
+		if resp != nil {
+			defer resp.Body.Close()
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			return nil, fmt.Errorf("port-forward to pod %s: %s: %s", pod, resp.Status, strings.TrimSpace(string(msg)))
+		}
+		return nil, fmt.Errorf("port-forward to pod %s: %w", pod, err)
+	}
+	return &k8sPortForwardConn{ws: ws, port: port}, nil
+}
+
+// k8sPortForwardConn is a connection to a forwarded port.
+type k8sPortForwardConn struct {
+	ws   *websocket.Conn
+	port int
+
+	readMu sync.Mutex
+	buf    []byte
+	// the number of bytes of the port prefix read on each channel
+	prefixRead [2]int
+
+	writeMu sync.Mutex
+}
+
+var _ net.Conn = (*k8sPortForwardConn)(nil)
+
+func (c *k8sPortForwardConn) Read(p []byte) (int, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	for len(c.buf) == 0 {
+		typ, msg, err := c.ws.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return 0, io.EOF
+			}
+			return 0, err
+		}
+		if typ != websocket.BinaryMessage || len(msg) == 0 {
+			continue
+		}
+		channel, data := int(msg[0]), msg[1:]
+		if channel != k8sDataChannel && channel != k8sErrorChannel {
+			continue
+		}
+		// skip the port number sent first on each channel
+		if skip := min(2-c.prefixRead[channel], len(data)); skip > 0 {
+			c.prefixRead[channel] += skip
+			data = data[skip:]
+		}
+		if len(data) == 0 {
+			continue
+		}
+		if channel == k8sErrorChannel {
+			return 0, fmt.Errorf("port-forward to port %d: %s", c.port, data)
+		}
+		c.buf = data
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+func (c *k8sPortForwardConn) Write(p []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	msg := make([]byte, len(p)+1)
+	msg[0] = k8sDataChannel
+	copy(msg[1:], p)
+	if err := c.ws.WriteMessage(websocket.BinaryMessage, msg); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *k8sPortForwardConn) Close() error {
+	c.writeMu.Lock()
+	_ = c.ws.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+	c.writeMu.Unlock()
+	err := c.ws.Close()
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func (c *k8sPortForwardConn) LocalAddr() net.Addr {
+	return c.ws.LocalAddr()
+}
+
+func (c *k8sPortForwardConn) RemoteAddr() net.Addr {
+	return c.ws.RemoteAddr()
+}
+
+func (c *k8sPortForwardConn) SetDeadline(t time.Time) error {
+	return errors.Join(c.ws.SetReadDeadline(t), c.ws.SetWriteDeadline(t))
+}
+
+func (c *k8sPortForwardConn) SetReadDeadline(t time.Time) error {
+	return c.ws.SetReadDeadline(t)
+}
+
+func (c *k8sPortForwardConn) SetWriteDeadline(t time.Time) error {
+	return c.ws.SetWriteDeadline(t)
+}
@@ -0,0 +1,294 @@
+package drivers
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKubernetes is a fake API server, serving the requests made by the
+// kubernetes driver. Forwarded ports echo back what they receive.
+type fakeKubernetes struct {
+	t *testing.T
+
+	mu      sync.Mutex
+	pods    map[string]k8sPod
+	jobs    map[string]k8sJob
+	deleted []string
+}
+
+func (k *fakeKubernetes) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"kind":"Status","message":"Unauthorized"}`)
+		return
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"kind":"Status","reason":"NotFound","message":"not found"}`)
+	}
+	podsPath := k8sPodsPath("ci")
+	jobsPath := k8sJobsPath("ci")
+	switch {
+	case r.URL.Path == podsPath && r.Method == http.MethodPost:
+		var pod k8sPod
+		require.NoError(k.t, json.NewDecoder(r.Body).Decode(&pod))
+		if _, ok := k.pods[pod.Metadata.Name]; ok {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"kind":"Status","reason":"AlreadyExists","message":"already exists"}`)
+			return
+		}
+		k.pods[pod.Metadata.Name] = pod
+		json.NewEncoder(w).Encode(pod)
+
+	case r.URL.Path == podsPath && r.Method == http.MethodGet:
+		selector := r.URL.Query().Get("labelSelector")
+		key, value, _ := strings.Cut(selector, "=")
+		var list k8sList[k8sPod]
+		for _, pod := range k.pods {
+			if pod.Metadata.Labels[key] == value {
+				list.Items = append(list.Items, pod)
+			}
+		}
+		json.NewEncoder(w).Encode(list)
+
+	case r.URL.Path == jobsPath && r.Method == http.MethodPost:
+		var job k8sJob
+		require.NoError(k.t, json.NewDecoder(r.Body).Decode(&job))
+		job.Metadata.Name = job.Metadata.GenerateName + "abcde"
+		k.jobs[job.Metadata.Name] = job
+		// the job controller starts its pod
+		pod := k8sPod{Metadata: job.Spec.Template.Metadata, Spec: job.Spec.Template.Spec}
+		pod.Metadata.Name = job.Metadata.Name + "-xyz"
+		pod.Metadata.Labels["job-name"] = job.Metadata.Name
+		k.pods[pod.Metadata.Name] = pod
+		json.NewEncoder(w).Encode(job)
+
+	case strings.HasPrefix(r.URL.Path, jobsPath+"/") && r.Method == http.MethodDelete:
+		name := strings.TrimPrefix(r.URL.Path, jobsPath+"/")
+		require.Equal(k.t, "Background", r.URL.Query().Get("propagationPolicy"))
+		if _, ok := k.jobs[name]; !ok {
+			notFound()
+			return
+		}
+		delete(k.jobs, name)
+		k.deleted = append(k.deleted, "job/"+name)
+		fmt.Fprint(w, `{}`)
+
+	case strings.HasPrefix(r.URL.Path, podsPath+"/") && strings.HasSuffix(r.URL.Path, "/portforward"):
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, podsPath+"/"), "/portforward")
+		if _, ok := k.pods[name]; !ok {
+			notFound()
+			return
+		}
+		k.mu.Unlock()
+		defer k.mu.Lock()
+		k.portForward(w, r)
+
+	case strings.HasPrefix(r.URL.Path, podsPath+"/"):
+		name := strings.TrimPrefix(r.URL.Path, podsPath+"/")
+		pod, ok := k.pods[name]
+		if !ok {
+			notFound()
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			// pods are ready as soon as they're created
+			pod.Status.Phase = "Running"
+			pod.Status.Conditions = []k8sPodCondition{{Type: "Ready", Status: "True"}}
+			json.NewEncoder(w).Encode(pod)
+		case http.MethodDelete:
+			delete(k.pods, name)
+			k.deleted = append(k.deleted, "pod/"+name)
+			json.NewEncoder(w).Encode(pod)
+		}
+
+	default:
+		notFound()
+	}
+}
+
+func (k *fakeKubernetes) portForward(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{Subprotocols: []string{k8sPortForwardProtocol}}
+	ws, err := upgrader.Upgrade(w, r, nil)
+	require.NoError(k.t, err)
+	defer ws.Close()
+	require.Equal(k.t, k8sPortForwardProtocol, ws.Subprotocol())
+	require.Equal(k.t, "4321", r.URL.Query().Get("ports"))
+
+	// each channel starts with the port number
+	for _, channel := range []byte{k8sDataChannel, k8sErrorChannel} {
+		msg := []byte{channel, 0, 0}
+		binary.LittleEndian.PutUint16(msg[1:], 4321)
+		require.NoError(k.t, ws.WriteMessage(websocket.BinaryMessage, msg))
+	}
+	for {
+		_, msg, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		if string(msg[1:]) == "fail" {
+			ws.WriteMessage(websocket.BinaryMessage, append([]byte{k8sErrorChannel}, "connection refused"...))
+			continue
+		}
+		if err := ws.WriteMessage(websocket.BinaryMessage, msg); err != nil {
+			return
+		}
+	}
+}
+
+func setupFakeKubernetes(t *testing.T) *fakeKubernetes {
+	k := &fakeKubernetes{
+		t:    t,
+		pods: map[string]k8sPod{},
+		jobs: map[string]k8sJob{},
+	}
+	server := httptest.NewTLSServer(k)
+	t.Cleanup(server.Close)
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	kubeconfigPath := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfigPath, fmt.Appendf(nil, `apiVersion: v1
+kind: Config
+current-context: test
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+    namespace: ci
+clusters:
+- name: test
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+users:
+- name: test
+  user:
+    token: test-token
+`, server.URL, base64.StdEncoding.EncodeToString(caPEM)), 0o600))
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	prevInterval := k8sPollInterval
+	k8sPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { k8sPollInterval = prevInterval })
+	return k
+}
+
+func TestKubernetesPodDriver(t *testing.T) {
+	k := setupFakeKubernetes(t)
+	ctx := t.Context()
+
+	// a leftover engine of another version
+	k.pods["dagger-engine-v0.1.0"] = k8sPod{Metadata: k8sObjectMeta{
+		Name:   "dagger-engine-v0.1.0",
+		Labels: map[string]string{k8sEngineLabel: "pod"},
+	}}
+	// an engine someone started with a name of their own
+	k.pods["team-engine"] = k8sPod{Metadata: k8sObjectMeta{
+		Name:   "team-engine",
+		Labels: map[string]string{k8sEngineLabel: "pod"},
+	}}
+
+	driver, err := GetDriver(ctx, "k8s-pod")
+	require.NoError(t, err)
+	target, err := url.Parse("k8s-pod://registry.dagger.io/engine:v0.2.0?port=4321&cpus=2&env=FOO=bar")
+	require.NoError(t, err)
+	connector, err := driver.Provision(ctx, target, &DriverOpts{DaggerCloudToken: "cloud-token"})
+	require.NoError(t, err)
+
+	pod, ok := k.pods["dagger-engine-v0.2.0"]
+	require.True(t, ok, "engine pod not created")
+	require.Equal(t, "Always", pod.Spec.RestartPolicy)
+	ctr := pod.Spec.Containers[0]
+	require.Equal(t, "registry.dagger.io/engine:v0.2.0", ctr.Image)
+	require.Contains(t, ctr.Args, "tcp://0.0.0.0:4321")
+	require.Equal(t, "2", ctr.Resources.Limits["cpu"])
+	require.Contains(t, ctr.Env, k8sEnvVar{Name: "FOO", Value: "bar"})
+	require.Contains(t, ctr.Env, k8sEnvVar{Name: EnvDaggerCloudToken, Value: "cloud-token"})
+	require.True(t, ctr.SecurityContext.Privileged)
+	require.Equal(t, []string{"pod/dagger-engine-v0.1.0"}, k.deleted)
+	require.Contains(t, k.pods, "team-engine")
+	require.Equal(t, "dagger-engine-v0.2.0", connector.EngineID())
+
+	conn, err := connector.Connect(ctx)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(buf))
+
+	_, err = conn.Write([]byte("fail"))
+	require.NoError(t, err)
+	_, err = conn.Read(buf)
+	require.ErrorContains(t, err, "connection refused")
+	require.NoError(t, conn.Close())
+
+	// the pod is reused by the next session
+	k.deleted = nil
+	_, err = driver.Provision(ctx, target, &DriverOpts{})
+	require.NoError(t, err)
+	require.Empty(t, k.deleted)
+}
+
+func TestKubernetesJobDriver(t *testing.T) {
+	k := setupFakeKubernetes(t)
+	ctx := t.Context()
+
+	driver, err := GetDriver(ctx, "k8s-job")
+	require.NoError(t, err)
+	target, err := url.Parse("k8s-job://registry.dagger.io/engine:v0.2.0?port=4321")
+	require.NoError(t, err)
+	connector, err := driver.Provision(ctx, target, &DriverOpts{})
+	require.NoError(t, err)
+
+	job, ok := k.jobs["dagger-engine-v0.2.0-abcde"]
+	require.True(t, ok, "engine job not created")
+	require.Equal(t, "dagger-engine-v0.2.0-abcde-xyz", connector.EngineID())
+	require.Equal(t, "Never", job.Spec.Template.Spec.RestartPolicy)
+	require.Equal(t, 0, *job.Spec.BackoffLimit)
+
+	conn, err := connector.Connect(ctx)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(buf))
+	require.NoError(t, conn.Close())
+
+	// the job is deleted at the end of the session
+	closer, ok := connector.(io.Closer)
+	require.True(t, ok)
+	require.NoError(t, closer.Close())
+	require.Equal(t, []string{"job/dagger-engine-v0.2.0-abcde"}, k.deleted)
+}
+
+func TestK8sName(t *testing.T) {
+	require.Equal(t, "dagger-engine-v0.19.0", k8sName("dagger-engine-v0.19.0"))
+	require.Equal(t, "dagger-engine-main-foo", k8sName("dagger-engine-Main_Foo"))
+	require.Len(t, k8sName("dagger-engine-"+strings.Repeat("a", 100)), 63)
+}
@@ -74,6 +74,9 @@ func (mc metadataCarrier) Keys() []string {
 
 type Filesyncer struct {
 	uid, gid uint32
+
+	// watcher, if set, is told about every directory synced to the engine.
+	watcher *SyncWatcher
 }
 
 func NewFilesyncer() (Filesyncer, error) {
@@ -85,6 +88,13 @@ func NewFilesyncer() (Filesyncer, error) {
 	return f, nil
 }
 
+// WithWatcher returns a Filesyncer that records the directories it syncs to
+// the engine in the given watcher.
+func (f Filesyncer) WithWatcher(watcher *SyncWatcher) Filesyncer {
+	f.watcher = watcher
+	return f
+}
+
 func (f Filesyncer) AsSource() FilesyncSource {
 	return FilesyncSource(f)
 }
@@ -178,32 +188,43 @@ func (s FilesyncSource) DiffCopy(stream filesync.FileSync_DiffCopyServer) error
 
 	default:
 		// otherwise, do the whole directory sync back to the caller
-		fs, err := fsutil.NewFS(absPath)
+		filteredFS, err := localSyncFS(absPath, *opts)
 		if err != nil {
 			return err
 		}
-		filteredFS, err := fsutil.NewFilterFS(fs, &fsutil.FilterOpt{
-			IncludePatterns: opts.IncludePatterns,
-			ExcludePatterns: opts.ExcludePatterns,
-			FollowPaths:     opts.FollowPaths,
-			Map: func(p string, st *fstypes.Stat) fsutil.MapResult {
-				normalizeLocalImportStat(st)
-				return fsutil.MapResultKeep
-			},
-		})
-		if opts.UseGitIgnore {
-			filteredFS, err = fsxutil.NewGitIgnoreMarkedFS(filteredFS, fsxutil.NewGitIgnoreMatcher(fs))
-			if err != nil {
-				return err
-			}
-		}
-		if err != nil {
-			return err
+		if s.watcher != nil {
+			s.watcher.watch(ctx, absPath, *opts)
 		}
 		return fsutil.Send(stream.Context(), stream, filteredFS, nil)
 	}
 }
 
+// localSyncFS returns the view of the host directory at absPath that a
+// directory sync with the given options sends: its files filtered by the
+// include, exclude and follow patterns, and marked when gitignored.
+func localSyncFS(absPath string, opts engine.LocalImportOpts) (fsutil.FS, error) {
+	fs, err := fsutil.NewFS(absPath)
+	if err != nil {
+		return nil, err
+	}
+	filteredFS, err := fsutil.NewFilterFS(fs, &fsutil.FilterOpt{
+		IncludePatterns: opts.IncludePatterns,
+		ExcludePatterns: opts.ExcludePatterns,
+		FollowPaths:     opts.FollowPaths,
+		Map: func(p string, st *fstypes.Stat) fsutil.MapResult {
+			normalizeLocalImportStat(st)
+			return fsutil.MapResultKeep
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opts.UseGitIgnore {
+		return fsxutil.NewGitIgnoreMarkedFS(filteredFS, fsxutil.NewGitIgnoreMatcher(fs))
+	}
+	return filteredFS, nil
+}
+
 func normalizeLocalImportStat(st *fstypes.Stat) {
 	st.Uid = 0
 	st.Gid = 0
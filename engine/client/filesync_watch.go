@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dagger/dagger/engine"
+	fstypes "github.com/dagger/dagger/internal/fsutil/types"
+)
+
+// SyncWatcher watches the host directories a client syncs to the engine, so
+// that a long-lived session can tell when their content changed on disk.
+//
+// Each directory synced is recorded with the include, exclude and gitignore
+// filters of its sync, along with a snapshot of the files those filters
+// select. Changes compares the directories against their snapshot the same way
+// the sync itself does: by file size and modification time, not content.
+type SyncWatcher struct {
+	mu      sync.Mutex
+	dirs    map[string]*watchedDir
+	pending sync.WaitGroup
+}
+
+type watchedDir struct {
+	root  string
+	opts  engine.LocalImportOpts
+	stats map[string]*fstypes.Stat
+}
+
+func NewSyncWatcher() *SyncWatcher {
+	return &SyncWatcher{
+		dirs: map[string]*watchedDir{},
+	}
+}
+
+// watch records a sync of the host directory at absPath, and snapshots it in
+// the background. A directory synced again with the same filters keeps its
+// existing snapshot, which Changes keeps up to date.
+func (w *SyncWatcher) watch(ctx context.Context, absPath string, opts engine.LocalImportOpts) {
+	key := strings.Join([]string{
+		absPath,
+		strings.Join(opts.IncludePatterns, ","),
+		strings.Join(opts.ExcludePatterns, ","),
+		strings.Join(opts.FollowPaths, ","),
+		strconv.FormatBool(opts.UseGitIgnore),
+	}, "\x00")
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.dirs[key]; ok {
+		return
+	}
+	dir := &watchedDir{root: absPath, opts: opts}
+	w.dirs[key] = dir
+	w.pending.Add(1)
+	go func() {
+		defer w.pending.Done()
+		// A directory that can't be walked (e.g. removed since) is snapshotted
+		// as empty, so it shows as changed once it can be walked again.
+		stats, _ := dir.snapshot(context.WithoutCancel(ctx))
+		w.mu.Lock()
+		dir.stats = stats
+		w.mu.Unlock()
+	}()
+}
+
+// Changes returns the host paths that were added, modified or removed in the
+// watched directories since the last call, or since they were first synced.
+func (w *SyncWatcher) Changes(ctx context.Context) ([]string, error) {
+	w.pending.Wait()
+
+	w.mu.Lock()
+	dirs := make([]*watchedDir, 0, len(w.dirs))
+	for _, dir := range w.dirs {
+		dirs = append(dirs, dir)
+	}
+	w.mu.Unlock()
+
+	changed := map[string]struct{}{}
+	for _, dir := range dirs {
+		stats, err := dir.snapshot(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, context.Cause(ctx)
+			}
+			// keep the previous snapshot, the walk may succeed next time
+			continue
+		}
+		w.mu.Lock()
+		for path, stat := range stats {
+			if prev, ok := dir.stats[path]; !ok || !sameStat(prev, stat) {
+				changed[filepath.Join(dir.root, path)] = struct{}{}
+			}
+		}
+		for path := range dir.stats {
+			if _, ok := stats[path]; !ok {
+				changed[filepath.Join(dir.root, path)] = struct{}{}
+			}
+		}
+		dir.stats = stats
+		w.mu.Unlock()
+	}
+
+	paths := make([]string, 0, len(changed))
+	for path := range changed {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// Wait polls the watched directories every interval until some of them change,
+// and returns the changed paths once they settle: polling goes on until an
+// interval passes with no further change, so that a burst of writes (saving
+// several files, switching git branches) is returned as a single change.
+func (w *SyncWatcher) Wait(ctx context.Context, interval time.Duration) ([]string, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var changed []string
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		case <-ticker.C:
+		}
+		paths, err := w.Changes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 && len(changed) > 0 {
+			slices.Sort(changed)
+			return slices.Compact(changed), nil
+		}
+		changed = append(changed, paths...)
+	}
+}
+
+// snapshot stats the files of the directory selected by its sync filters.
+// Gitignored directories and .git directories are skipped entirely, as they
+// are not worth watching.
+func (dir *watchedDir) snapshot(ctx context.Context) (map[string]*fstypes.Stat, error) {
+	if _, err := os.Stat(dir.root); errors.Is(err, os.ErrNotExist) {
+		return map[string]*fstypes.Stat{}, nil
+	}
+	syncFS, err := localSyncFS(dir.root, dir.opts)
+	if err != nil {
+		return nil, err
+	}
+	stats := map[string]*fstypes.Stat{}
+	err = syncFS.Walk(ctx, "/", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		stat, ok := info.Sys().(*fstypes.Stat)
+		if !ok {
+			return nil
+		}
+		if stat.GitIgnored {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		stats[path] = stat
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// sameStat reports whether a file is unchanged, comparing directories only by
+// their metadata since their size and modification time change with their
+// entries, which are compared on their own.
+func sameStat(a, b *fstypes.Stat) bool {
+	if !a.IsDir() && (a.Size_ != b.Size_ || a.ModTime != b.ModTime) {
+		return false
+	}
+	return a.Mode == b.Mode && a.Linkname == b.Linkname
+}
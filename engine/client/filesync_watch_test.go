@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/engine"
+)
+
+func TestSyncWatcherChanges(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, path), []byte(content), 0o600))
+	}
+	write(".gitignore", "build/\n*.log\n")
+	write("main.go", "package main\n")
+	write("docs/README.md", "# docs\n")
+	write("src/lib.go", "package src\n")
+	write("build/out", "binary")
+
+	watcher := NewSyncWatcher()
+	watcher.watch(ctx, root, engine.LocalImportOpts{
+		Path:            root,
+		UseGitIgnore:    true,
+		ExcludePatterns: []string{"docs"},
+	})
+	changes, err := watcher.Changes(ctx)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	write("main.go", "package main\n\nfunc main() {}\n")
+	require.NoError(t, os.Remove(filepath.Join(root, "src", "lib.go")))
+	write("src/new.go", "package src\n")
+	// ignored, excluded or under .git: not watched
+	write("build/out", "new binary")
+	write("debug.log", "log")
+	write("docs/README.md", "# new docs\n")
+	write(".git/HEAD", "ref: refs/heads/main\n")
+
+	changes, err = watcher.Changes(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(root, "main.go"),
+		filepath.Join(root, "src", "lib.go"),
+		filepath.Join(root, "src", "new.go"),
+	}, changes)
+
+	// changes are reported once
+	changes, err = watcher.Changes(ctx)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	aws_config "github.com/aws/aws-sdk-go-v2/config"
@@ -20,15 +21,37 @@ var (
 	awsMutex                sync.Mutex
 	awsSecretsManagerClient *secretsmanager.Client
 	awsSSMClient            *ssm.Client
-	awsCache                = make(map[string][]byte)
+	awsCache                = make(map[string]awsCachedSecret)
 )
 
+type awsCachedSecret struct {
+	data []byte
+	// expiresAt is set from the "ttl" query parameter, or the next rotation
+	// of a Secrets Manager secret, whichever is earliest
+	expiresAt time.Time
+}
+
+// awsCacheGet returns the cached value of a secret, if it hasn't expired.
+func awsCacheGet(ctx context.Context, pathWithQuery string) ([]byte, bool) {
+	cached, ok := awsCache[pathWithQuery]
+	if !ok || (!cached.expiresAt.IsZero() && !cached.expiresAt.After(time.Now())) {
+		return nil, false
+	}
+	setExpiry(ctx, cached.expiresAt)
+	return cached.data, true
+}
+
+func awsCachePut(ctx context.Context, pathWithQuery string, data []byte, expiresAt time.Time) {
+	awsCache[pathWithQuery] = awsCachedSecret{data: data, expiresAt: expiresAt}
+	setExpiry(ctx, expiresAt)
+}
+
 func awsParameterStoreProvider(ctx context.Context, pathWithQuery string) ([]byte, error) {
 	awsMutex.Lock()
 	defer awsMutex.Unlock()
 
 	// Check cache first (cache key is the full pathWithQuery including params)
-	if cached, ok := awsCache[pathWithQuery]; ok {
+	if cached, ok := awsCacheGet(ctx, pathWithQuery); ok {
 		return cached, nil
 	}
 
@@ -40,6 +63,10 @@ func awsParameterStoreProvider(ctx context.Context, pathWithQuery string) ([]byt
 
 	path := parsed.Path
 	query := parsed.Query()
+	ttl, err := parseTTL(query.Get("ttl"), path)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize AWS clients if needed
 	if err := initAWSClients(ctx, query); err != nil {
@@ -54,8 +81,13 @@ func awsParameterStoreProvider(ctx context.Context, pathWithQuery string) ([]byt
 		return nil, err
 	}
 
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
 	// Cache the result
-	awsCache[pathWithQuery] = data
+	awsCachePut(ctx, pathWithQuery, data, expiresAt)
 	return data, nil
 }
 
@@ -64,7 +96,7 @@ func awsSecretManagerProvider(ctx context.Context, pathWithQuery string) ([]byte
 	defer awsMutex.Unlock()
 
 	// Check cache first (cache key is the full pathWithQuery including params)
-	if cached, ok := awsCache[pathWithQuery]; ok {
+	if cached, ok := awsCacheGet(ctx, pathWithQuery); ok {
 		return cached, nil
 	}
 
@@ -76,6 +108,10 @@ func awsSecretManagerProvider(ctx context.Context, pathWithQuery string) ([]byte
 
 	path := parsed.Path
 	query := parsed.Query()
+	ttl, err := parseTTL(query.Get("ttl"), path)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize AWS clients if needed
 	if err := initAWSClients(ctx, query); err != nil {
@@ -100,8 +136,17 @@ func awsSecretManagerProvider(ctx context.Context, pathWithQuery string) ([]byte
 		return nil, err
 	}
 
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+	// a pinned version never changes, but the current one does on rotation
+	if version == "" && (stage == "" || stage == "AWSCURRENT") {
+		expiresAt = earliest(expiresAt, awsSecretsManagerNextRotation(ctx, path))
+	}
+
 	// Cache the result
-	awsCache[pathWithQuery] = data
+	awsCachePut(ctx, pathWithQuery, data, expiresAt)
 
 	return data, nil
 }
@@ -190,6 +235,28 @@ func awsSecretsManagerGet(ctx context.Context, secretName, version, stage, field
 	return data, nil
 }
 
+// Returns when a Secrets Manager secret is next rotated, or the zero time if
+// rotation isn't enabled or can't be described, e.g. for lack of the
+// secretsmanager:DescribeSecret permission.
+func awsSecretsManagerNextRotation(ctx context.Context, secretName string) time.Time {
+	result, err := awsSecretsManagerClient.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{
+		SecretId: aws.String(secretName),
+	})
+	if err != nil {
+		return time.Time{}
+	}
+	return awsNextRotation(result)
+}
+
+// awsNextRotation returns when a described secret is next rotated, or the
+// zero time if rotation isn't enabled.
+func awsNextRotation(result *secretsmanager.DescribeSecretOutput) time.Time {
+	if result.RotationEnabled == nil || !*result.RotationEnabled || result.NextRotationDate == nil {
+		return time.Time{}
+	}
+	return *result.NextRotationDate
+}
+
 // Retrieve parameter from AWS Systems Manager Parameter Store
 func awsParameterStoreGet(ctx context.Context, parameterName string) ([]byte, error) {
 	input := &ssm.GetParameterInput{
@@ -0,0 +1,118 @@
+package secretprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/dagger/dagger/internal/buildkit/session/secrets"
+)
+
+const (
+	azureKeyVaultAPIVersion = "7.4"
+	azureKeyVaultScope      = "https://vault.azure.net/.default"
+	azureKeyVaultDNSSuffix  = ".vault.azure.net"
+)
+
+var (
+	azureMutex      sync.Mutex
+	azureCredential azcore.TokenCredential
+	// overridden in tests to point at a stub server
+	azureHTTPClient = http.DefaultClient
+	azureVaultURL   = func(vault string) string {
+		// a vault name with a dot is a full host name, e.g. for sovereign
+		// clouds: myvault.vault.azure.cn
+		if !strings.Contains(vault, ".") {
+			vault += azureKeyVaultDNSSuffix
+		}
+		return "https://" + vault
+	}
+)
+
+// Azure Key Vault provider for SecretProvider:
+//
+//	azkv://VAULT/SECRET[/VERSION]
+//
+// Credentials are looked up like the Azure SDKs do: environment variables,
+// workload identity, managed identity, then the Azure CLI.
+func azureKeyVaultProvider(ctx context.Context, pathWithQuery string) ([]byte, error) {
+	parsed, err := url.Parse(pathWithQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse azkv:// URI: %w", err)
+	}
+	parts := strings.Split(parsed.Path, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid Azure Key Vault secret path %q: expected VAULT/SECRET[/VERSION]", parsed.Path)
+	}
+	vault, name := parts[0], parts[1]
+	var version string
+	if len(parts) == 3 {
+		version = parts[2]
+	}
+
+	cred, err := azureConfigureCredential()
+	if err != nil {
+		return nil, err
+	}
+	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{azureKeyVaultScope},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Azure token: %w", err)
+	}
+
+	reqURL := azureVaultURL(vault) + "/secrets/" + url.PathEscape(name)
+	if version != "" {
+		reqURL += "/" + url.PathEscape(version)
+	}
+	reqURL += "?api-version=" + azureKeyVaultAPIVersion
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	resp, err := azureHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Azure Key Vault secret %s/%s: %w", vault, name, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Value string `json:"value"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode Azure Key Vault secret %s/%s: %s: %w", vault, name, resp.Status, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return []byte(body.Value), nil
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("azure key vault secret %s/%s: %w", vault, name, secrets.ErrNotFound)
+	default:
+		return nil, fmt.Errorf("failed to get Azure Key Vault secret %s/%s: %s: %s", vault, name, resp.Status, body.Error.Message)
+	}
+}
+
+func azureConfigureCredential() (azcore.TokenCredential, error) {
+	azureMutex.Lock()
+	defer azureMutex.Unlock()
+	if azureCredential != nil {
+		return azureCredential, nil
+	}
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Azure credentials: %w", err)
+	}
+	azureCredential = cred
+	return cred, nil
+}
@@ -0,0 +1,70 @@
+package secretprovider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/dagger/dagger/internal/buildkit/session/secrets"
+	"github.com/stretchr/testify/require"
+)
+
+type staticAzureCredential string
+
+func (cred staticAzureCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: string(cred), ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestAzureKeyVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" || r.URL.Query().Get("api-version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"code":"Unauthorized","message":"missing token"}}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/my-vault/secrets/db-password":
+			fmt.Fprint(w, `{"value":"latest"}`)
+		case "/my-vault/secrets/db-password/v1":
+			fmt.Fprint(w, `{"value":"first"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"code":"SecretNotFound","message":"not found"}}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	oldURL, oldClient, oldCred := azureVaultURL, azureHTTPClient, azureCredential
+	azureVaultURL = func(vault string) string { return server.URL + "/" + vault }
+	azureHTTPClient = server.Client()
+	azureCredential = staticAzureCredential("test-token")
+	t.Cleanup(func() {
+		azureVaultURL, azureHTTPClient, azureCredential = oldURL, oldClient, oldCred
+	})
+
+	ctx := context.Background()
+
+	value, err := azureKeyVaultProvider(ctx, "my-vault/db-password")
+	require.NoError(t, err)
+	require.Equal(t, "latest", string(value))
+
+	value, err = azureKeyVaultProvider(ctx, "my-vault/db-password/v1")
+	require.NoError(t, err)
+	require.Equal(t, "first", string(value))
+
+	_, err = azureKeyVaultProvider(ctx, "my-vault/missing")
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+
+	_, err = azureKeyVaultProvider(ctx, "my-vault")
+	require.ErrorContains(t, err, "expected VAULT/SECRET[/VERSION]")
+}
+
+func TestAzureVaultURL(t *testing.T) {
+	require.Equal(t, "https://my-vault.vault.azure.net", azureVaultURL("my-vault"))
+	require.Equal(t, "https://my-vault.vault.azure.cn", azureVaultURL("my-vault.vault.azure.cn"))
+}
@@ -0,0 +1,71 @@
+package secretprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExpiresAtHeader is the gRPC response header set by GetSecret when the
+// returned plaintext is known to expire, as an RFC 3339 timestamp. The engine
+// re-fetches the secret once it has passed.
+const ExpiresAtHeader = "dagger-secret-expires-at"
+
+type expiryKey struct{}
+
+type expiry struct {
+	mu sync.Mutex
+	at time.Time
+}
+
+// withExpiry returns a context that records the expiry reported by resolvers
+// through setExpiry.
+func withExpiry(ctx context.Context) (context.Context, *expiry) {
+	exp := &expiry{}
+	return context.WithValue(ctx, expiryKey{}, exp), exp
+}
+
+// setExpiry reports that the secret being resolved expires at the given
+// time. The earliest reported time wins; a zero time is ignored.
+func setExpiry(ctx context.Context, at time.Time) {
+	exp, ok := ctx.Value(expiryKey{}).(*expiry)
+	if !ok || at.IsZero() {
+		return
+	}
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	exp.at = earliest(exp.at, at)
+}
+
+func (exp *expiry) get() time.Time {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	return exp.at
+}
+
+// earliest returns the earliest of the non-zero times a and b.
+func earliest(a, b time.Time) time.Time {
+	switch {
+	case a.IsZero():
+		return b
+	case b.IsZero(), a.Before(b):
+		return a
+	default:
+		return b
+	}
+}
+
+// parseTTL parses the optional "ttl" query parameter of a secret URI.
+func parseTTL(ttlStr, key string) (time.Duration, error) {
+	ttlStr = strings.TrimSpace(ttlStr)
+	if ttlStr == "" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(ttlStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ttl %q provided for secret %q: %w", ttlStr, key, err)
+	}
+	return ttl, nil
+}
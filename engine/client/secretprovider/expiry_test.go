@@ -0,0 +1,156 @@
+package secretprovider
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/dagger/dagger/internal/buildkit/session/secrets"
+)
+
+func TestSetExpiry(t *testing.T) {
+	now := time.Now()
+
+	// no recorder: nothing to do
+	setExpiry(context.Background(), now)
+
+	ctx, exp := withExpiry(context.Background())
+	require.True(t, exp.get().IsZero())
+
+	setExpiry(ctx, time.Time{})
+	require.True(t, exp.get().IsZero())
+
+	setExpiry(ctx, now.Add(time.Hour))
+	require.Equal(t, now.Add(time.Hour), exp.get())
+
+	// the earliest expiry wins
+	setExpiry(ctx, now.Add(time.Minute))
+	setExpiry(ctx, now.Add(2*time.Hour))
+	require.Equal(t, now.Add(time.Minute), exp.get())
+}
+
+func TestParseTTL(t *testing.T) {
+	ttl, err := parseTTL("", "db")
+	require.NoError(t, err)
+	require.Zero(t, ttl)
+
+	ttl, err = parseTTL(" 90m ", "db")
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Minute, ttl)
+
+	_, err = parseTTL("soon", "db")
+	require.ErrorContains(t, err, `invalid ttl "soon" provided for secret "db"`)
+}
+
+func TestVaultLease(t *testing.T) {
+	// the lease of a dynamic secret wins over the metadata
+	require.Equal(t, time.Hour, vaultLease(&vault.KVSecret{
+		Raw:            &vault.Secret{LeaseDuration: 3600},
+		CustomMetadata: map[string]any{"ttl": "5m"},
+	}))
+
+	// a KV secret has no lease, but may declare one in its metadata
+	require.Equal(t, 5*time.Minute, vaultLease(&vault.KVSecret{
+		Raw:            &vault.Secret{},
+		CustomMetadata: map[string]any{"ttl": "5m"},
+	}))
+
+	require.Zero(t, vaultLease(&vault.KVSecret{}))
+	require.Zero(t, vaultLease(&vault.KVSecret{
+		CustomMetadata: map[string]any{"ttl": "whenever"},
+	}))
+	require.Zero(t, vaultLease(&vault.KVSecret{
+		CustomMetadata: map[string]any{"ttl": 300},
+	}))
+}
+
+func TestAWSNextRotation(t *testing.T) {
+	next := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.Equal(t, next, awsNextRotation(&secretsmanager.DescribeSecretOutput{
+		RotationEnabled:  aws.Bool(true),
+		NextRotationDate: aws.Time(next),
+	}))
+
+	// a past rotation schedule doesn't count once rotation is turned off
+	require.True(t, awsNextRotation(&secretsmanager.DescribeSecretOutput{
+		RotationEnabled:  aws.Bool(false),
+		NextRotationDate: aws.Time(next),
+	}).IsZero())
+	require.True(t, awsNextRotation(&secretsmanager.DescribeSecretOutput{
+		RotationEnabled: aws.Bool(true),
+	}).IsZero())
+	require.True(t, awsNextRotation(&secretsmanager.DescribeSecretOutput{}).IsZero())
+}
+
+func TestGetSecretExpiresAtHeader(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	resolvers["test-expiring"] = func(ctx context.Context, path string) ([]byte, error) {
+		if path == "rotating" {
+			setExpiry(ctx, expiresAt)
+		}
+		return []byte("hunter2"), nil
+	}
+	t.Cleanup(func() { delete(resolvers, "test-expiring") })
+
+	// the client's provider, and the proxy passing its responses through
+	provider := secrets.NewSecretsClient(newTestConn(t, NewSecretProvider().Register))
+	proxy := secrets.NewSecretsClient(newTestConn(t, NewSecretProviderProxy(provider).Register))
+
+	for name, client := range map[string]secrets.SecretsClient{
+		"provider": provider,
+		"proxy":    proxy,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var md metadata.MD
+			resp, err := client.GetSecret(t.Context(), &secrets.GetSecretRequest{
+				ID: "test-expiring://rotating",
+			}, grpc.Header(&md))
+			require.NoError(t, err)
+			require.Equal(t, "hunter2", string(resp.Data))
+			require.Equal(t, []string{"2030-01-02T03:04:05Z"}, md.Get(ExpiresAtHeader))
+
+			md = nil
+			_, err = client.GetSecret(t.Context(), &secrets.GetSecretRequest{
+				ID: "test-expiring://static",
+			}, grpc.Header(&md))
+			require.NoError(t, err)
+			require.Empty(t, md.Get(ExpiresAtHeader))
+		})
+	}
+}
+
+func newTestConn(t *testing.T, register func(*grpc.Server)) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	register(server)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		server.Stop()
+		_ = listener.Close()
+	})
+	return conn
+}
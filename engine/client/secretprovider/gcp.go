@@ -37,19 +37,16 @@ func gcpProvider(ctx context.Context, pathWithQuery string) ([]byte, error) {
 	// this is just path part without the query params such as ttl
 	key := parsed.Path
 
-	var ttl time.Duration
-	ttlStr := strings.TrimSpace(parsed.Query().Get("ttl"))
-	if ttlStr != "" {
-		ttl, err = time.ParseDuration(ttlStr)
-		if err != nil {
-			return nil, fmt.Errorf("invalid ttl %q provided for secret %q: %w", ttlStr, key, err)
-		}
+	ttl, err := parseTTL(parsed.Query().Get("ttl"), key)
+	if err != nil {
+		return nil, err
 	}
 
 	// Try to get from cache with read lock first
 	gcpMutex.RLock()
 	if existing, ok := gcpSecretCache[key]; ok && !gcpHasExpired(existing) {
 		gcpMutex.RUnlock()
+		setExpiry(ctx, existing.expiresAt)
 		return existing.data, nil
 	}
 	gcpMutex.RUnlock()
@@ -61,6 +58,7 @@ func gcpProvider(ctx context.Context, pathWithQuery string) ([]byte, error) {
 	// Double-check after acquiring write lock
 	if existing, ok := gcpSecretCache[key]; ok && !gcpHasExpired(existing) {
 		gcpUpdateCacheOrder(key)
+		setExpiry(ctx, existing.expiresAt)
 		return existing.data, nil
 	}
 
@@ -110,6 +108,7 @@ func gcpProvider(ctx context.Context, pathWithQuery string) ([]byte, error) {
 	gcpUpdateCacheOrder(key)
 	gcpEvictIfNeeded()
 
+	setExpiry(ctx, data.expiresAt)
 	return data.data, nil
 }
 
@@ -0,0 +1,73 @@
+package secretprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dagger/dagger/internal/buildkit/session/secrets"
+	"github.com/dagger/dagger/util/kubeconfig"
+)
+
+// Kubernetes provider for SecretProvider, reading a key of a Secret:
+//
+//	k8s://NAMESPACE/NAME/KEY[?context=CONTEXT]
+//
+// The cluster and credentials come from the kubeconfig ($KUBECONFIG or
+// ~/.kube/config), using its current context unless one is given. Without a
+// kubeconfig, the in-cluster service account is used.
+func k8sProvider(ctx context.Context, pathWithQuery string) ([]byte, error) {
+	parsed, err := url.Parse(pathWithQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse k8s:// URI: %w", err)
+	}
+	parts := strings.Split(parsed.Path, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("invalid k8s secret path %q: expected NAMESPACE/NAME/KEY", parsed.Path)
+	}
+	namespace, name, key := parts[0], parts[1], parts[2]
+
+	cluster, err := kubeconfig.Load(parsed.Query().Get("context"))
+	if err != nil {
+		return nil, err
+	}
+
+	reqURL := cluster.Server + "/api/v1/namespaces/" + url.PathEscape(namespace) + "/secrets/" + url.PathEscape(name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	cluster.Authorize(req)
+	resp, err := cluster.HTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get k8s secret %s/%s: %w", namespace, name, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("k8s secret %s/%s: %w", namespace, name, secrets.ErrNotFound)
+	case resp.StatusCode != http.StatusOK:
+		var status struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&status)
+		return nil, fmt.Errorf("failed to get k8s secret %s/%s: %s: %s", namespace, name, resp.Status, status.Message)
+	}
+
+	var secret struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("failed to decode k8s secret %s/%s: %w", namespace, name, err)
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("k8s secret %s/%s has no key %q: %w", namespace, name, key, secrets.ErrNotFound)
+	}
+	return base64.StdEncoding.DecodeString(value)
+}
@@ -0,0 +1,83 @@
+package secretprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dagger/dagger/internal/buildkit/session/secrets"
+	"github.com/stretchr/testify/require"
+)
+
+func TestK8sProvider(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"kind":"Status","message":"Unauthorized"}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/namespaces/ci/secrets/registry":
+			fmt.Fprintf(w, `{"kind":"Secret","data":{"password":%q}}`, base64.StdEncoding.EncodeToString([]byte("hunter2")))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"kind":"Status","message":"secrets not found"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	kubeconfigPath := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfigPath, fmt.Appendf(nil, `apiVersion: v1
+kind: Config
+current-context: test
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+- name: anonymous
+  context:
+    cluster: test
+    user: anonymous
+clusters:
+- name: test
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+users:
+- name: test
+  user:
+    token: test-token
+- name: anonymous
+  user: {}
+`, server.URL, base64.StdEncoding.EncodeToString(caPEM)), 0o600))
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	ctx := context.Background()
+
+	value, err := k8sProvider(ctx, "ci/registry/password")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", string(value))
+
+	_, err = k8sProvider(ctx, "ci/registry/username")
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+
+	_, err = k8sProvider(ctx, "ci/missing/password")
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+
+	_, err = k8sProvider(ctx, "ci/registry/password?context=anonymous")
+	require.ErrorContains(t, err, "Unauthorized")
+
+	_, err = k8sProvider(ctx, "ci/registry/password?context=missing")
+	require.ErrorContains(t, err, `context "missing" not found`)
+
+	_, err = k8sProvider(ctx, "ci/registry")
+	require.ErrorContains(t, err, "expected NAMESPACE/NAME/KEY")
+}
@@ -0,0 +1,113 @@
+package secretprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dagger/dagger/internal/buildkit/session/secrets"
+)
+
+// PluginPrefix is the prefix of the executables serving secret provider
+// schemes that aren't built in: the "acme://" scheme is served by a
+// dagger-secret-acme executable on the PATH.
+//
+// A plugin is run once per secret lookup. It reads a JSON PluginRequest on
+// stdin and writes a JSON PluginResponse on stdout. Anything it writes to
+// stderr is included in the error if it exits with a non-zero status.
+const PluginPrefix = "dagger-secret-"
+
+// PluginProtocolVersion is the version of the plugin protocol, sent in each
+// request so plugins can reject versions they don't know.
+const PluginProtocolVersion = 1
+
+// PluginRequest is sent to a plugin on stdin.
+type PluginRequest struct {
+	Version int `json:"version"`
+	// URI is the full secret URI, e.g. "acme://team/db?field=password".
+	URI string `json:"uri"`
+	// Scheme is the scheme of URI, e.g. "acme".
+	Scheme string `json:"scheme"`
+	// Path is URI without its scheme, e.g. "team/db?field=password".
+	Path string `json:"path"`
+}
+
+// PluginResponse is read from a plugin's stdout.
+type PluginResponse struct {
+	// Value is the plaintext of the secret.
+	Value []byte `json:"value,omitempty"`
+	// Error fails the lookup with the given message.
+	Error string `json:"error,omitempty"`
+	// NotFound marks Error as the secret not existing.
+	NotFound bool `json:"notFound,omitempty"`
+	// ExpiresAt is when Value stops being valid, if ever. The engine
+	// resolves the secret again after it.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+var pluginSchemeRegexp = regexp.MustCompile(`^[a-z][a-z0-9+.-]*$`)
+
+// validPluginScheme reports whether scheme can be served by a plugin.
+func validPluginScheme(scheme string) bool {
+	return pluginSchemeRegexp.MatchString(scheme)
+}
+
+// pluginResolver returns the resolver for a scheme served by a plugin, if a
+// plugin for it is on the PATH.
+func pluginResolver(scheme string) (SecretResolver, bool) {
+	if !validPluginScheme(scheme) {
+		return nil, false
+	}
+	path, err := exec.LookPath(PluginPrefix + scheme)
+	if err != nil {
+		return nil, false
+	}
+	return func(ctx context.Context, pathWithQuery string) ([]byte, error) {
+		return runPlugin(ctx, path, scheme, pathWithQuery)
+	}, true
+}
+
+func runPlugin(ctx context.Context, path, scheme, pathWithQuery string) ([]byte, error) {
+	req, err := json.Marshal(PluginRequest{
+		Version: PluginProtocolVersion,
+		URI:     scheme + "://" + pathWithQuery,
+		Scheme:  scheme,
+		Path:    pathWithQuery,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// #nosec G204
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(req)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("secret provider plugin %s failed: %w: %s", PluginPrefix+scheme, err, msg)
+		}
+		return nil, fmt.Errorf("secret provider plugin %s failed: %w", PluginPrefix+scheme, err)
+	}
+
+	var resp PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("secret provider plugin %s: invalid response: %w", PluginPrefix+scheme, err)
+	}
+	switch {
+	case resp.NotFound:
+		return nil, fmt.Errorf("secret provider plugin %s: %s: %w", PluginPrefix+scheme, resp.Error, secrets.ErrNotFound)
+	case resp.Error != "":
+		return nil, fmt.Errorf("secret provider plugin %s: %s", PluginPrefix+scheme, resp.Error)
+	}
+	if resp.ExpiresAt != nil {
+		setExpiry(ctx, *resp.ExpiresAt)
+	}
+	return resp.Value, nil
+}
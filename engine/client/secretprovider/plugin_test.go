@@ -0,0 +1,77 @@
+package secretprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dagger/dagger/internal/buildkit/session/secrets"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin stub is a shell script")
+	}
+
+	binDir := t.TempDir()
+	// answers based on the raw request, which carries the path verbatim
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "dagger-secret-acme"), []byte(`#!/bin/sh
+req=$(cat)
+case "$req" in
+  *'"version":1,"uri":"acme://team/db","scheme":"acme","path":"team/db"'*)
+    echo '{"value":"aHVudGVyMg=="}' ;;
+  *'"path":"team/rotating"'*)
+    echo '{"value":"aHVudGVyMg==","expiresAt":"2030-01-02T03:04:05Z"}' ;;
+  *'"path":"team/missing"'*)
+    echo '{"error":"no such secret","notFound":true}' ;;
+  *'"path":"team/denied"'*)
+    echo '{"error":"access denied"}' ;;
+  *)
+    echo "unexpected request: $req" >&2
+    exit 3 ;;
+esac
+`), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ctx := context.Background()
+	resolve := func(id string) ([]byte, error) {
+		resolver, path, err := ResolverForID(id)
+		if err != nil {
+			return nil, err
+		}
+		return resolver(ctx, path)
+	}
+
+	value, err := resolve("acme://team/db")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", string(value))
+
+	expCtx, exp := withExpiry(ctx)
+	resolver, path, err := ResolverForID("acme://team/rotating")
+	require.NoError(t, err)
+	value, err = resolver(expCtx, path)
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", string(value))
+	require.Equal(t, time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC), exp.get().UTC())
+
+	_, err = resolve("acme://team/missing")
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+	require.ErrorContains(t, err, "no such secret")
+
+	_, err = resolve("acme://team/denied")
+	require.ErrorContains(t, err, "access denied")
+
+	_, err = resolve("acme://other")
+	require.ErrorContains(t, err, "unexpected request")
+
+	_, err = resolve("nope://team/db")
+	require.ErrorContains(t, err, "no dagger-secret-nope executable found in PATH")
+
+	// the engine accepts plugin schemes without having the plugin itself
+	require.NoError(t, ValidateID("nope://team/db"))
+	require.Error(t, ValidateID("Not A Scheme://team/db"))
+}
@@ -7,11 +7,13 @@ import (
 	"maps"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/dagger/dagger/internal/buildkit/session/secrets"
 	"github.com/dagger/dagger/util/grpcutil"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -27,6 +29,8 @@ var resolvers = map[string]SecretResolver{
 	"gcp":       gcpProvider,
 	"aws+sm":    awsSecretManagerProvider,
 	"aws+ps":    awsParameterStoreProvider,
+	"k8s":       k8sProvider,
+	"azkv":      azureKeyVaultProvider,
 }
 
 func Schemes() []string {
@@ -41,11 +45,28 @@ func ResolverForID(id string) (SecretResolver, string, error) {
 
 	resolver, ok := resolvers[scheme]
 	if !ok {
-		return nil, "", fmt.Errorf("unsupported secret provider: %q", scheme)
+		resolver, ok = pluginResolver(scheme)
+	}
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported secret provider: %q (no %s%s executable found in PATH)", scheme, PluginPrefix, scheme)
 	}
 	return resolver, pathWithQuery, nil
 }
 
+// ValidateID checks that id is a secret URI that a client may be able to
+// resolve. Unlike ResolverForID, it doesn't look for plugins, which are only
+// installed where the client runs.
+func ValidateID(id string) error {
+	scheme, _, ok := strings.Cut(id, "://")
+	if !ok {
+		return fmt.Errorf("parse %q: malformed id", id)
+	}
+	if _, ok := resolvers[scheme]; !ok && !validPluginScheme(scheme) {
+		return fmt.Errorf("unsupported secret provider: %q", scheme)
+	}
+	return nil
+}
+
 type SecretProvider struct{}
 
 func NewSecretProvider() SecretProvider {
@@ -62,6 +83,7 @@ func (sp SecretProvider) GetSecret(ctx context.Context, req *secrets.GetSecretRe
 		return nil, err
 	}
 
+	ctx, exp := withExpiry(ctx)
 	plaintext, err := resolver(ctx, u)
 	if err != nil {
 		if errors.Is(err, secrets.ErrNotFound) {
@@ -70,6 +92,13 @@ func (sp SecretProvider) GetSecret(ctx context.Context, req *secrets.GetSecretRe
 		return nil, err
 	}
 
+	if at := exp.get(); !at.IsZero() {
+		md := metadata.Pairs(ExpiresAtHeader, at.UTC().Format(time.RFC3339))
+		if err := grpc.SetHeader(ctx, md); err != nil {
+			return nil, fmt.Errorf("set secret expiry: %w", err)
+		}
+	}
+
 	return &secrets.GetSecretResponse{
 		Data: plaintext,
 	}, nil
@@ -90,5 +119,16 @@ func (sp SecretProviderProxy) Register(server *grpc.Server) {
 }
 
 func (sp SecretProviderProxy) GetSecret(ctx context.Context, req *secrets.GetSecretRequest) (*secrets.GetSecretResponse, error) {
-	return sp.client.GetSecret(grpcutil.IncomingToOutgoingContext(ctx), req)
+	var md metadata.MD
+	resp, err := sp.client.GetSecret(grpcutil.IncomingToOutgoingContext(ctx), req, grpc.Header(&md))
+	if err != nil {
+		return nil, err
+	}
+	// pass the expiry of the secret through
+	if at := md.Get(ExpiresAtHeader); len(at) > 0 {
+		if err := grpc.SetHeader(ctx, metadata.Pairs(ExpiresAtHeader, at[0])); err != nil {
+			return nil, fmt.Errorf("set secret expiry: %w", err)
+		}
+	}
+	return resp, nil
 }
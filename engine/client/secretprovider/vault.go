@@ -1,6 +1,7 @@
 package secretprovider
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"net/url"
@@ -22,6 +23,8 @@ var (
 	mutex       sync.Mutex
 	vaultClient *vault.Client
 	vaultCache  = make(map[string]dataWithTTL)
+	// vaultKVMounts holds the mounts found to be KV version 2 secrets engines.
+	vaultKVMounts = make(map[string]bool)
 )
 
 // HashiCorp Vault provider for SecretProvider
@@ -37,13 +40,9 @@ func vaultProvider(ctx context.Context, pathWithQuery string) ([]byte, error) {
 	// this is just path part without the query params such as ttl
 	key := parsed.Path
 
-	var ttl time.Duration
-	ttlStr := strings.TrimSpace(parsed.Query().Get("ttl"))
-	if ttlStr != "" {
-		ttl, err = time.ParseDuration(ttlStr)
-		if err != nil {
-			return nil, fmt.Errorf("invalid ttl %q provided for secret %q: %w", ttlStr, key, err)
-		}
+	ttl, err := parseTTL(parsed.Query().Get("ttl"), key)
+	if err != nil {
+		return nil, err
 	}
 
 	// KVv2 mount path. Default "secret"
@@ -69,6 +68,10 @@ func vaultProvider(ctx context.Context, pathWithQuery string) ([]byte, error) {
 			}
 		}
 
+		if err := vaultCheckKVMount(ctx, vaultClient, mount); err != nil {
+			return nil, err
+		}
+
 		// read the secret
 		s, err := vaultClient.KVv2(mount).Get(ctx, secretPath)
 		if err != nil {
@@ -78,13 +81,18 @@ func vaultProvider(ctx context.Context, pathWithQuery string) ([]byte, error) {
 			data: s.Data,
 		}
 
+		now := time.Now()
 		if ttl > 0 {
-			data.expiresAt = time.Now().Add(ttl)
+			data.expiresAt = now.Add(ttl)
+		}
+		if lease := vaultLease(s); lease > 0 {
+			data.expiresAt = earliest(data.expiresAt, now.Add(lease))
 		}
 
 		// cache response
 		vaultCache[key] = data
 	}
+	setExpiry(ctx, vaultCache[key].expiresAt)
 
 	secretDataAny := vaultCache[key].data[secretField]
 	if secretDataAny == nil {
@@ -97,6 +105,54 @@ func vaultProvider(ctx context.Context, pathWithQuery string) ([]byte, error) {
 	return []byte(secretData), nil
 }
 
+// vaultCheckKVMount fails unless mount is a KV version 2 secrets engine.
+// Other engines, like the database engine, hand out dynamic secrets with
+// leases that would have to be renewed and revoked, which isn't supported.
+func vaultCheckKVMount(ctx context.Context, client *vault.Client, mount string) error {
+	if vaultKVMounts[mount] {
+		return nil
+	}
+	// the request the vault CLI makes to tell KV versions apart, allowed to
+	// any token with access to a path in the mount
+	s, err := client.Logical().ReadWithContext(ctx, "sys/internal/ui/mounts/"+mount)
+	if err != nil {
+		return fmt.Errorf("mount %q: %w", mount, err)
+	}
+	if s == nil || s.Data == nil {
+		return fmt.Errorf("mount %q not found", mount)
+	}
+	engine, _ := s.Data["type"].(string)
+	var version string
+	if options, ok := s.Data["options"].(map[string]any); ok {
+		version, _ = options["version"].(string)
+	}
+	if engine != "kv" || version != "2" {
+		if engine == "kv" {
+			engine = "KV version " + cmp.Or(version, "1")
+		}
+		return fmt.Errorf("mount %q is a %s secrets engine: only KV version 2 secrets are supported", mount, engine)
+	}
+	vaultKVMounts[mount] = true
+	return nil
+}
+
+// vaultLease returns how long a secret read from Vault is valid for: its
+// lease duration, or else the "ttl" custom metadata of the KV secret, as set
+// by `vault kv metadata put -custom-metadata=ttl=1h`.
+func vaultLease(s *vault.KVSecret) time.Duration {
+	if s.Raw != nil && s.Raw.LeaseDuration > 0 {
+		return time.Duration(s.Raw.LeaseDuration) * time.Second
+	}
+	if ttlStr, ok := s.CustomMetadata["ttl"].(string); ok {
+		// metadata is set by whoever wrote the secret: ignore a bad value
+		// rather than fail to read it
+		if ttl, err := parseTTL(ttlStr, ""); err == nil {
+			return ttl
+		}
+	}
+	return 0
+}
+
 func hasExpired(data dataWithTTL) bool {
 	// if no ttl set, assume no ttl required
 	if data.expiresAt.IsZero() {
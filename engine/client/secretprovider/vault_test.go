@@ -0,0 +1,45 @@
+package secretprovider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	vault "github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultCheckKVMount(t *testing.T) {
+	mounts := map[string]map[string]any{
+		"secret":   {"type": "kv", "options": map[string]any{"version": "2"}},
+		"kv1":      {"type": "kv", "options": map[string]any{}},
+		"database": {"type": "database"},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mount, ok := strings.CutPrefix(r.URL.Path, "/v1/sys/internal/ui/mounts/")
+		data, found := mounts[mount]
+		if !ok || !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer srv.Close()
+
+	config := vault.DefaultConfig()
+	config.Address = srv.URL
+	client, err := vault.NewClient(config)
+	require.NoError(t, err)
+	client.SetToken("token")
+
+	ctx := context.Background()
+	require.NoError(t, vaultCheckKVMount(ctx, client, "secret"))
+	require.ErrorContains(t, vaultCheckKVMount(ctx, client, "kv1"),
+		`mount "kv1" is a KV version 1 secrets engine: only KV version 2 secrets are supported`)
+	require.ErrorContains(t, vaultCheckKVMount(ctx, client, "database"),
+		`mount "database" is a database secrets engine`)
+	require.ErrorContains(t, vaultCheckKVMount(ctx, client, "missing"), `mount "missing"`)
+}
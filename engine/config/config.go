@@ -242,4 +242,49 @@ type Security struct {
 	// Disabling this option ensures that dagger build containers do not run as
 	// privileged, and is a basic form of security hardening.
 	InsecureRootCapabilities *bool `json:"insecureRootCapabilities,omitempty"`
+
+	// DefaultProfile is the security profile applied to all containers.
+	// Containers can tighten it further with Container.withSecurityProfile,
+	// but not loosen it.
+	DefaultProfile *SecurityProfile `json:"defaultProfile,omitempty"`
+
+	// AllowedCapabilities restricts the capabilities that
+	// Container.withSecurityProfile may add, including those dropped by the
+	// default profile. If unset, the capabilities containers get by default
+	// may be added back unless dropped by the default profile, and any other
+	// capability only if insecure root capabilities are allowed.
+	AllowedCapabilities []string `json:"allowedCapabilities,omitempty"`
+
+	// Egress is the egress policy applied to all containers with network
+	// access, on top of the policies set with Container.withEgressPolicy and
+	// in dagger.toml. Egress is unrestricted by default.
+	Egress *EgressPolicy `json:"egress,omitempty"`
+}
+
+type EgressPolicy struct {
+	// Allow lists the domains, IP addresses and CIDRs containers may connect
+	// to, e.g. "github.com" or "10.0.0.0/8".
+	Allow []string `json:"allow,omitempty"`
+
+	// AllowRegistries allows containers to connect to well-known public
+	// registries and to the registries and mirrors configured in registries.
+	AllowRegistries bool `json:"allowRegistries,omitempty"`
+}
+
+type SecurityProfile struct {
+	// SeccompProfile is the path, on the engine host, to a seccomp profile in
+	// the JSON format used by Docker. It replaces the default seccomp
+	// profile.
+	SeccompProfile string `json:"seccompProfile,omitempty"`
+
+	// CapDrop is the list of capabilities dropped from containers, e.g.
+	// "CAP_NET_RAW" or "ALL".
+	CapDrop []string `json:"capDrop,omitempty"`
+
+	// ReadOnlyRootfs mounts the root filesystem of containers read-only.
+	ReadOnlyRootfs bool `json:"readOnlyRootfs,omitempty"`
+
+	// NoNewPrivileges prevents processes in containers from gaining
+	// privileges, e.g. through setuid binaries.
+	NoNewPrivileges bool `json:"noNewPrivileges,omitempty"`
 }
@@ -45,6 +45,10 @@ const (
 	SessionMethodNameMetaKey = "X-Docker-Expose-Session-Grpc-Method"
 )
 
+// MCPWorkspaceChangedMethod is the JSON-RPC notification `dagger mcp` sends
+// its MCP server when files of the workspace changed on the host.
+const MCPWorkspaceChangedMethod = "notifications/dagger/workspaceChanged"
+
 const (
 	OTelTraceParentEnv      = "TRACEPARENT"
 	OTelTracesExporterEnv   = "OTEL_TRACES_EXPORTER"
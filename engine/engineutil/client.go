@@ -77,6 +77,8 @@ type Opts struct {
 	ApparmorProfile     string
 	SELinux             bool
 	Entitlements        entitlements.Set
+	SecurityPolicy      SecurityPolicy
+	EgressPolicy        EgressPolicy
 
 	HostMntNS  *os.File
 	CleanMntNS *os.File
@@ -438,6 +440,25 @@ func (c *Client) PromptAllowLLM(ctx context.Context, moduleRepoURL string) error
 	return fmt.Errorf("module %s was denied LLM access; pass --allow-llm=%s or --allow-llm=all to allow", moduleRepoURL, moduleRepoURL)
 }
 
+// PromptToolCall asks the user whether an LLM may make a tool call. Clients
+// that can't prompt answer with the default, denying it.
+func (c *Client) PromptToolCall(ctx context.Context, tool, args string) (bool, error) {
+	caller, err := c.GetMainClientCaller(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get main client caller to prompt for tool call approval: %w", err)
+	}
+
+	response, err := prompt.NewPromptClient(caller.Conn()).PromptBool(ctx, &prompt.BoolRequest{
+		Title:   "Allow tool call?",
+		Prompt:  fmt.Sprintf("The LLM wants to call **%s** with:\n\n```json\n%s\n```\n\nAllow it?", tool, args),
+		Default: false,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to prompt user for tool call approval: %w", err)
+	}
+	return response.Response, nil
+}
+
 func (c *Client) PromptHumanHelp(ctx context.Context, title, question string) (string, error) {
 	caller, err := c.GetMainClientCaller(ctx)
 	if err != nil {
@@ -28,6 +28,8 @@ type ContainerExport struct {
 	Ref         bkcache.ImmutableRef
 	Config      dockerspec.DockerOCIImageConfig
 	Annotations []containerutil.ContainerAnnotation
+	// Attestations are attached to the exported image's manifest.
+	Attestations []imageexport.Attestation
 }
 
 type PreparedContainerImage struct {
@@ -80,6 +82,7 @@ func (image *PreparedContainerImage) Blob(id string) (ContainerImageBlob, error)
 
 func (c *Client) buildExportRequest(
 	inputByPlatform map[string]ContainerExport,
+	names ...string,
 ) (*imageexport.ExportRequest, error) {
 	inputs := make([]imageexport.PlatformExportInput, 0, len(inputByPlatform))
 	for platformKey, input := range inputByPlatform {
@@ -113,13 +116,14 @@ func (c *Client) buildExportRequest(
 			},
 			ManifestAnnotations:           manifestAnnotations,
 			ManifestDescriptorAnnotations: manifestDescriptorAnnotations,
+			Attestations:                  input.Attestations,
 		})
 	}
 
 	slices.SortFunc(inputs, func(a, b imageexport.PlatformExportInput) int {
 		return cmp.Compare(a.Key, b.Key)
 	})
-	return &imageexport.ExportRequest{Platforms: inputs}, nil
+	return &imageexport.ExportRequest{Platforms: inputs, Names: names}, nil
 }
 
 func (c *Client) exportCommitOpts(
@@ -226,7 +230,7 @@ func (c *Client) PublishContainerImage(
 	}
 	defer cancel(errors.New("publish container image done"))
 
-	req, err := c.buildExportRequest(inputByPlatform)
+	req, err := c.buildExportRequest(inputByPlatform, refName)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,319 @@
+package engineutil
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/dagger/dagger/internal/buildkit/solver/pb"
+	"github.com/dagger/dagger/internal/buildkit/util/bklog"
+	"github.com/dagger/dagger/network"
+	"github.com/dagger/dagger/network/netinst"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EgressPolicy is the engine-wide policy for the egress of containers.
+type EgressPolicy struct {
+	// Default is applied to every container with network access, on top of
+	// their own policies. No egress is restricted by default if nil.
+	Default *network.EgressPolicy
+	// RegistryDomains are the domains allowed by policies that allow
+	// registries.
+	RegistryDomains []string
+}
+
+func (c *Client) setupEgressPolicy(ctx context.Context, state *execState) error {
+	policies := state.procInfo.Meta.EgressPolicies
+	if c.EgressPolicy.Default != nil {
+		policies = append([]network.EgressPolicy{*c.EgressPolicy.Default}, policies...)
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	switch state.procInfo.Meta.NetMode {
+	case pb.NetMode_NONE:
+		return nil
+	case pb.NetMode_HOST:
+		return errors.New("egress policies can't be enforced on containers using the host network")
+	}
+	if state.procInfo.Meta.SecurityMode == pb.SecurityMode_INSECURE {
+		return errors.New("egress policies can't be enforced on containers with insecure root capabilities")
+	}
+	if err := checkEgressCapabilities(state.procInfo.Meta.SecurityProfile.CapAdd); err != nil {
+		return err
+	}
+
+	allowed := make([][]netip.Prefix, len(policies))
+	domains := make([][]string, len(policies))
+	for i, policy := range policies {
+		var err error
+		allowed[i], domains[i], err = c.egressAllowList(policy)
+		if err != nil {
+			return err
+		}
+	}
+	nameservers, err := resolvConfNameservers(state.resolvConfPath)
+	if err != nil {
+		return err
+	}
+
+	// network namespaces are reused, so the rules have to be removed even if
+	// the exec was canceled
+	resetCtx := context.WithoutCancel(ctx)
+	var appliedIPv6 atomic.Bool
+	state.cleanups.Add("reset egress rules", func() error {
+		_, err := runInNetNS(resetCtx, state, func() (struct{}, error) {
+			err := netinst.ResetEgressRules(resetCtx, false)
+			if appliedIPv6.Load() {
+				err = errors.Join(err, netinst.ResetEgressRules(resetCtx, true))
+			}
+			return struct{}{}, err
+		})
+		return err
+	})
+
+	// the addresses of allowed domains are allowed as the container resolves
+	// them, by proxying its DNS queries
+	var dnsRules string
+	if slices.ContainsFunc(domains, func(domains []string) bool { return len(domains) > 0 }) {
+		dnsRules, err = c.startEgressDNSProxy(ctx, state, nameservers, domains, &appliedIPv6)
+		if err != nil {
+			return fmt.Errorf("start egress DNS proxy: %w", err)
+		}
+	}
+
+	_, err = runInNetNS(ctx, state, func() (struct{}, error) {
+		local, err := localPrefixes()
+		if err != nil {
+			return struct{}{}, err
+		}
+		// DNS is resolved through the session's nameservers
+		local = append(local, nameservers...)
+
+		if err := netinst.ApplyEgressRules(ctx, netinst.EgressRules(allowed, local, false)+dnsRules, false); err != nil {
+			return struct{}{}, err
+		}
+		if err := netinst.ApplyEgressRules(ctx, netinst.EgressRules(allowed, local, true), true); err != nil {
+			// without IPv6 addresses, there's nothing to restrict
+			if slices.ContainsFunc(local, func(prefix netip.Prefix) bool {
+				return prefix.Addr().Is6() && !prefix.Addr().IsLinkLocalUnicast()
+			}) {
+				return struct{}{}, err
+			}
+			bklog.G(ctx).WithError(err).Debug("skipping IPv6 egress rules")
+			return struct{}{}, nil
+		}
+		appliedIPv6.Store(true)
+		return struct{}{}, nil
+	})
+	if err != nil {
+		return fmt.Errorf("apply egress policy: %w", err)
+	}
+
+	egressLog, err := runInNetNS(ctx, state, func() (*netinst.EgressLog, error) {
+		return netinst.OpenEgressLog()
+	})
+	if err != nil {
+		bklog.G(ctx).WithError(err).Warn("blocked connections won't be audited")
+		return nil
+	}
+	state.cleanups.Add("close egress log", egressLog.Close)
+	go auditEgress(ctx, egressLog, policies)
+	return nil
+}
+
+// egressBypassCapabilities are the capabilities letting a container change
+// or get around the egress rules of its network namespace.
+var egressBypassCapabilities = []string{"CAP_NET_ADMIN", "CAP_NET_RAW"}
+
+// checkEgressCapabilities refuses containers adding capabilities that would
+// let them bypass their egress policies.
+func checkEgressCapabilities(capAdd []string) error {
+	for _, capName := range capAdd {
+		if slices.Contains(egressBypassCapabilities, capName) {
+			return &SecurityPolicyError{
+				Reason: fmt.Sprintf("egress policies can't be enforced on containers adding capability %s", capName),
+			}
+		}
+	}
+	return nil
+}
+
+// egressAllowList returns the addresses and domains allowed by a policy.
+func (c *Client) egressAllowList(policy network.EgressPolicy) ([]netip.Prefix, []string, error) {
+	dests := policy.Allow
+	if policy.AllowRegistries {
+		dests = append(slices.Clone(dests), c.EgressPolicy.RegistryDomains...)
+	}
+	var allowed []netip.Prefix
+	var domains []string
+	for _, dest := range dests {
+		prefix, domain, err := network.ParseEgressDestination(dest)
+		if err != nil {
+			return nil, nil, err
+		}
+		if domain != "" {
+			domains = append(domains, domain)
+		} else {
+			allowed = append(allowed, prefix)
+		}
+	}
+	return allowed, domains, nil
+}
+
+// startEgressDNSProxy serves the DNS queries of the container from its
+// network namespace, allowing the addresses its nameservers resolve the
+// allowed domains to. It returns the rules redirecting the queries to it.
+//
+// Only the queries sent to IPv4 nameservers are redirected, so the domains
+// can't be reached when resolved through IPv6 ones.
+func (c *Client) startEgressDNSProxy(
+	ctx context.Context,
+	state *execState,
+	nameservers []netip.Prefix,
+	domains [][]string,
+	appliedIPv6 *atomic.Bool,
+) (string, error) {
+	type listeners struct {
+		udp net.PacketConn
+		tcp net.Listener
+	}
+	l, err := runInNetNS(ctx, state, func() (listeners, error) {
+		udp, err := net.ListenPacket("udp4", "127.0.0.1:0")
+		if err != nil {
+			return listeners{}, err
+		}
+		tcp, err := net.Listen("tcp4", "127.0.0.1:0")
+		if err != nil {
+			udp.Close()
+			return listeners{}, err
+		}
+		return listeners{udp: udp, tcp: tcp}, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	state.cleanups.Add("close egress DNS proxy", func() error {
+		return errors.Join(l.udp.Close(), l.tcp.Close())
+	})
+
+	proxy := &netinst.EgressDNSProxy{
+		Domains: domains,
+		Allow: func(ctx context.Context, policies []int, addrs []netip.Addr) {
+			_, err := runInNetNS(ctx, state, func() (struct{}, error) {
+				err := netinst.AddEgressRules(ctx, netinst.EgressAllowRules(policies, addrs, false), false)
+				if appliedIPv6.Load() {
+					err = errors.Join(err, netinst.AddEgressRules(ctx, netinst.EgressAllowRules(policies, addrs, true), true))
+				}
+				return struct{}{}, err
+			})
+			if err != nil {
+				bklog.G(ctx).WithError(err).Warn("failed to allow egress to resolved addresses")
+			}
+		},
+	}
+	for _, ns := range nameservers {
+		proxy.Nameservers = append(proxy.Nameservers, netip.AddrPortFrom(ns.Addr(), 53))
+	}
+	go proxy.ServeUDP(ctx, l.udp)
+	go proxy.ServeTCP(ctx, l.tcp)
+
+	return netinst.EgressDNSRules(nameservers,
+		l.udp.LocalAddr().(*net.UDPAddr).Port,
+		l.tcp.Addr().(*net.TCPAddr).Port,
+	), nil
+}
+
+// localPrefixes returns the networks of the interfaces of the current network
+// namespace, which are always reachable.
+func localPrefixes() ([]netip.Prefix, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("list interfaces: %w", err)
+	}
+	var prefixes []netip.Prefix
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, fmt.Errorf("list addresses of %s: %w", iface.Name, err)
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			prefix, err := netip.ParsePrefix(ipNet.String())
+			if err != nil {
+				continue
+			}
+			prefixes = append(prefixes, prefix.Masked())
+		}
+	}
+	return prefixes, nil
+}
+
+func resolvConfNameservers(path string) ([]netip.Prefix, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("read resolv.conf: %w", err)
+	}
+	defer f.Close()
+	var nameservers []netip.Prefix
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+		addr, err := netip.ParseAddr(fields[1])
+		if err != nil {
+			continue
+		}
+		addr = addr.Unmap()
+		nameservers = append(nameservers, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return nameservers, scanner.Err()
+}
+
+// auditEgress records the connections blocked by egress policies as events
+// of the exec's span, once per destination.
+func auditEgress(ctx context.Context, egressLog *netinst.EgressLog, policies []network.EgressPolicy) {
+	span := trace.SpanFromContext(ctx)
+	seen := map[netinst.BlockedConn]struct{}{}
+	for {
+		conn, err := egressLog.Read()
+		if err != nil {
+			return
+		}
+		if _, ok := seen[conn]; ok {
+			continue
+		}
+		seen[conn] = struct{}{}
+
+		var source string
+		if conn.Policy >= 0 && conn.Policy < len(policies) {
+			source = policies[conn.Policy].Source
+		}
+		bklog.G(ctx).Debugf("egress to %s %s:%d blocked by %s policy", conn.Protocol, conn.Dest, conn.Port, source)
+		span.AddEvent("Egress blocked", trace.WithAttributes(
+			attribute.String("egress.destination", conn.Dest.String()),
+			attribute.Int("egress.port", int(conn.Port)),
+			attribute.String("egress.protocol", conn.Protocol),
+			attribute.String("egress.policy", source),
+		))
+	}
+}
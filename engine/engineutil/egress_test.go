@@ -0,0 +1,21 @@
+package engineutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckEgressCapabilities(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, checkEgressCapabilities(nil))
+	require.NoError(t, checkEgressCapabilities([]string{"CAP_CHOWN", "CAP_NET_BIND_SERVICE"}))
+
+	for _, capName := range []string{"CAP_NET_ADMIN", "CAP_NET_RAW"} {
+		err := checkEgressCapabilities([]string{"CAP_CHOWN", capName})
+		var policyErr *SecurityPolicyError
+		require.ErrorAs(t, err, &policyErr, capName)
+		require.ErrorContains(t, err, "egress policies can't be enforced on containers adding capability "+capName)
+	}
+}
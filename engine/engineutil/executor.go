@@ -24,6 +24,7 @@ import (
 	runc "github.com/containerd/go-runc"
 	"github.com/dagger/dagger/dagql"
 	"github.com/dagger/dagger/engine"
+	"github.com/dagger/dagger/engine/engineutil/resources"
 	"github.com/dagger/dagger/engine/wcprof"
 	"github.com/dagger/dagger/internal/buildkit/executor"
 	"github.com/dagger/dagger/internal/buildkit/executor/oci"
@@ -34,6 +35,7 @@ import (
 	"github.com/dagger/dagger/internal/buildkit/util/entitlements"
 	"github.com/dagger/dagger/internal/buildkit/util/stack"
 	"github.com/dagger/dagger/util/cleanups"
+	units "github.com/docker/go-units"
 	"github.com/moby/sys/signal"
 	"github.com/opencontainers/go-digest"
 	"github.com/opencontainers/runtime-spec/specs-go"
@@ -127,6 +129,11 @@ func (c *Client) Run(
 	if err := c.validateEntitlements(procInfo.Meta); err != nil {
 		return err
 	}
+	securityProfile, err := c.SecurityPolicy.Resolve(procInfo.Meta.SecurityProfile)
+	if err != nil {
+		return err
+	}
+	procInfo.Meta.SecurityProfile = securityProfile
 
 	state := newExecState(
 		id,
@@ -170,8 +177,9 @@ func (c *Client) Run(
 	if dagql.OTelProfActive(ctx) {
 		ctx, execRunSpan = beginOTelExecRun(ctx, execIdent)
 	}
-	err := c.run(ctx, state,
+	err = c.run(ctx, state,
 		namedSetupFunc{"setupNetwork", c.setupNetwork},
+		namedSetupFunc{"setupEgressPolicy", c.setupEgressPolicy},
 		namedSetupFunc{"injectInit", c.injectInit},
 		namedSetupFunc{"generateBaseSpec", c.generateBaseSpec},
 		namedSetupFunc{"filterEnvs", c.filterEnvs},
@@ -183,9 +191,12 @@ func (c *Client) Run(
 		namedSetupFunc{"setupSecretScrubbing", c.setupSecretScrubbing},
 		namedSetupFunc{"setProxyEnvs", c.setProxyEnvs},
 		namedSetupFunc{"enableGPU", c.enableGPU},
+		namedSetupFunc{"applyResourceLimits", c.applyResourceLimits},
 		namedSetupFunc{"createCWD", c.createCWD},
 		namedSetupFunc{"setupNestedClient", c.setupNestedClient},
 		namedSetupFunc{"installCACerts", c.installCACerts},
+		// applied last, so the setup steps above aren't restricted by it
+		namedSetupFunc{"applySecurityProfile", c.applySecurityProfile},
 		namedSetupFunc{"runContainer", c.runContainer},
 	)
 	execOp.EndErr(err)
@@ -391,8 +402,10 @@ func (c *Client) Exec(ctx context.Context, id string, process executor.ProcessIn
 		}
 	}
 
-	err = c.exec(ctx, id, spec.Process, process, nil)
-	return exitError(ctx, "", err, process.Meta.ValidExitCodes)
+	runCtx, cancel := withProcessTimeout(ctx, process.Meta)
+	defer cancel()
+	err = c.exec(runCtx, id, spec.Process, process, nil)
+	return processTimeoutError(ctx, runCtx, exitError(ctx, "", err, process.Meta.ValidExitCodes), process.Meta)
 }
 
 func (c *Client) exec(ctx context.Context, id string, specsProcess *specs.Process, process executor.ProcessInfo, started func()) error {
@@ -462,6 +475,118 @@ func exitError(ctx context.Context, exitCodePath string, err error, validExitCod
 	}
 }
 
+// ExecTimeoutError is returned when a process is killed for running longer
+// than its configured timeout.
+type ExecTimeoutError struct {
+	Timeout time.Duration
+	// Err is the error the process exited with after being killed, if any.
+	Err error
+}
+
+func (e *ExecTimeoutError) Error() string {
+	msg := fmt.Sprintf("process timed out after %s", e.Timeout)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ExecTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// withProcessTimeout bounds ctx by the process timeout, if any. Once the
+// returned context is done, the process handle SIGKILLs the container's init
+// process, which takes the rest of the process tree down with it.
+func withProcessTimeout(ctx context.Context, meta executor.Meta) (context.Context, context.CancelFunc) {
+	if meta.Timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, meta.Timeout, &ExecTimeoutError{Timeout: meta.Timeout})
+}
+
+// processTimeoutError converts the result of a process that ran under
+// withProcessTimeout into an *ExecTimeoutError if the timeout fired.
+func processTimeoutError(ctx, runCtx context.Context, err error, meta executor.Meta) error {
+	if err == nil || ctx.Err() != nil {
+		// the process finished in time, or the caller went away; neither is a
+		// timeout
+		return err
+	}
+	var timeoutErr *ExecTimeoutError
+	if !errors.As(context.Cause(runCtx), &timeoutErr) {
+		return err
+	}
+	trace.SpanFromContext(ctx).AddEvent(
+		"Container timed out",
+		trace.WithAttributes(attribute.String("exec.timeout", meta.Timeout.String())),
+	)
+	if meta.ValidTimeout {
+		return nil
+	}
+	return &ExecTimeoutError{Timeout: timeoutErr.Timeout, Err: err}
+}
+
+// ExecOOMError is returned when a process is killed by the OOM killer for
+// exceeding its container's memory limit.
+type ExecOOMError struct {
+	// MemoryLimit is the container's memory limit, in bytes.
+	MemoryLimit int64
+	// PeakMemory is the highest memory usage observed for the container, in
+	// bytes, or zero if unknown.
+	PeakMemory int64
+	// Err is the error the process exited with after being killed, if any.
+	Err error
+}
+
+func (e *ExecOOMError) Error() string {
+	msg := fmt.Sprintf("process killed after exceeding memory limit of %s", units.BytesSize(float64(e.MemoryLimit)))
+	if e.PeakMemory > 0 {
+		msg += fmt.Sprintf(" (peak %s)", units.BytesSize(float64(e.PeakMemory)))
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ExecOOMError) Unwrap() error {
+	return e.Err
+}
+
+// processOOMError converts the result of a process that ran in the given
+// cgroup into an *ExecOOMError if anything in it was OOM killed under the
+// configured memory limit.
+func processOOMError(ctx context.Context, cgroupPath string, err error, meta executor.Meta) error {
+	if err == nil || cgroupPath == "" || meta.ResourceLimits.MemoryBytes == 0 {
+		return err
+	}
+	stats, statsErr := resources.ReadOOMStats(cgroupPath)
+	if statsErr != nil {
+		bklog.G(ctx).WithError(statsErr).Warn("failed to read container OOM stats")
+		return err
+	}
+	return oomError(ctx, err, meta, stats)
+}
+
+func oomError(ctx context.Context, err error, meta executor.Meta, stats resources.OOMStats) error {
+	if err == nil || stats.OOMKills == 0 {
+		return err
+	}
+	trace.SpanFromContext(ctx).AddEvent(
+		"Container OOM killed",
+		trace.WithAttributes(
+			attribute.Int64("exec.memory_limit", meta.ResourceLimits.MemoryBytes),
+			attribute.Int64("exec.memory_peak", stats.PeakBytes),
+		),
+	)
+	return &ExecOOMError{
+		MemoryLimit: meta.ResourceLimits.MemoryBytes,
+		PeakMemory:  stats.PeakBytes,
+		Err:         err,
+	}
+}
+
 type forwardIO struct {
 	stdin          io.ReadCloser
 	stdout, stderr io.WriteCloser
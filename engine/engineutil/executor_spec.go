@@ -1034,6 +1034,52 @@ func (c *Client) enableGPU(_ context.Context, state *execState) error {
 	return nil
 }
 
+// cpuPeriod is the CFS scheduling period, in microseconds, used to express
+// CPU limits as a quota.
+const cpuPeriod = 100000
+
+func (c *Client) applyResourceLimits(_ context.Context, state *execState) error {
+	limits := state.procInfo.Meta.ResourceLimits
+	if limits.IsZero() {
+		return nil
+	}
+
+	if state.spec.Linux == nil {
+		state.spec.Linux = &specs.Linux{}
+	}
+	if state.spec.Linux.Resources == nil {
+		state.spec.Linux.Resources = &specs.LinuxResources{}
+	}
+	res := state.spec.Linux.Resources
+
+	if limits.MilliCPU > 0 {
+		if res.CPU == nil {
+			res.CPU = &specs.LinuxCPU{}
+		}
+		period := uint64(cpuPeriod)
+		quota := limits.MilliCPU * cpuPeriod / 1000
+		res.CPU.Period = &period
+		res.CPU.Quota = &quota
+	}
+	if limits.MemoryBytes > 0 {
+		if res.Memory == nil {
+			res.Memory = &specs.LinuxMemory{}
+		}
+		limit := limits.MemoryBytes
+		// setting swap to the same value as the limit disallows swap usage, so
+		// the limit is a hard cap
+		swap := limits.MemoryBytes
+		res.Memory.Limit = &limit
+		res.Memory.Swap = &swap
+	}
+	if limits.Pids > 0 {
+		pids := limits.Pids
+		res.Pids = &specs.LinuxPids{Limit: &pids}
+	}
+
+	return nil
+}
+
 func (c *Client) createCWD(_ context.Context, state *execState) error {
 	newp, err := fs.RootPath(state.rootfsPath, state.procInfo.Meta.Cwd)
 	if err != nil {
@@ -1455,7 +1501,9 @@ func (c *Client) runContainer(ctx context.Context, state *execState) (rerr error
 		return eg.Wait()
 	}
 
-	runErr := c.callWithIO(ctx, state.procInfo, startedCallback, killer, runcCall)
+	runCtx, cancelRun := withProcessTimeout(ctx, state.procInfo.Meta)
+	defer cancelRun()
+	runErr := c.callWithIO(runCtx, state.procInfo, startedCallback, killer, runcCall)
 	endWall := time.Now()
 	// Scrub + bound the captured user command ONCE, only when a profile source is
 	// active, and feed the SAME slice to both sinks below so native and OTel carry
@@ -1489,5 +1537,7 @@ func (c *Client) runContainer(ctx context.Context, state *execState) (rerr error
 		profStartedWallTime = time.Unix(0, ns)
 	}
 	emitOTelExecSplit(ctx, state.id, profStartWall, profStartedWallTime, endWall, runErr, profArgv)
-	return exitError(ctx, state.exitCodePath, runErr, state.procInfo.Meta.ValidExitCodes)
+	runErr = exitError(ctx, state.exitCodePath, runErr, state.procInfo.Meta.ValidExitCodes)
+	runErr = processOOMError(ctx, cgroupPath, runErr, state.procInfo.Meta)
+	return processTimeoutError(ctx, runCtx, runErr, state.procInfo.Meta)
 }
@@ -143,3 +143,40 @@ func (ns *noopNetworkNamespace) Set(*specs.Spec) error {
 func (ns *noopNetworkNamespace) Sample() (*resourcestypes.NetworkSample, error) {
 	return nil, nil
 }
+
+func TestApplyResourceLimits(t *testing.T) {
+	t.Parallel()
+
+	state := &execState{
+		procInfo: &executor.ProcessInfo{
+			Meta: executor.Meta{
+				ResourceLimits: executor.ResourceLimits{
+					MilliCPU:    1500,
+					MemoryBytes: 512 << 20,
+					Pids:        64,
+				},
+			},
+		},
+		spec: &specs.Spec{Linux: &specs.Linux{}},
+	}
+	require.NoError(t, (&Client{}).applyResourceLimits(context.Background(), state))
+
+	res := state.spec.Linux.Resources
+	require.NotNil(t, res)
+	require.Equal(t, uint64(100000), *res.CPU.Period)
+	require.Equal(t, int64(150000), *res.CPU.Quota)
+	require.Equal(t, int64(512<<20), *res.Memory.Limit)
+	require.Equal(t, int64(512<<20), *res.Memory.Swap)
+	require.Equal(t, int64(64), *res.Pids.Limit)
+}
+
+func TestApplyResourceLimitsUnset(t *testing.T) {
+	t.Parallel()
+
+	state := &execState{
+		procInfo: &executor.ProcessInfo{},
+		spec:     &specs.Spec{Linux: &specs.Linux{}},
+	}
+	require.NoError(t, (&Client{}).applyResourceLimits(context.Background(), state))
+	require.Nil(t, state.spec.Linux.Resources)
+}
@@ -0,0 +1,79 @@
+package engineutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dagger/dagger/engine/engineutil/resources"
+	"github.com/dagger/dagger/internal/buildkit/executor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessTimeoutError(t *testing.T) {
+	t.Parallel()
+
+	meta := executor.Meta{Timeout: time.Millisecond}
+	runCtx, cancel := withProcessTimeout(context.Background(), meta)
+	defer cancel()
+	<-runCtx.Done()
+
+	killed := errors.New("exit code: 137")
+	err := processTimeoutError(context.Background(), runCtx, killed, meta)
+	var timeoutErr *ExecTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.Equal(t, time.Millisecond, timeoutErr.Timeout)
+	require.ErrorIs(t, err, killed)
+	require.Equal(t, "process timed out after 1ms: exit code: 137", err.Error())
+
+	// finishing in time is never a timeout, even if the deadline passed since
+	require.NoError(t, processTimeoutError(context.Background(), runCtx, nil, meta))
+
+	meta.ValidTimeout = true
+	require.NoError(t, processTimeoutError(context.Background(), runCtx, killed, meta))
+}
+
+func TestProcessTimeoutErrorNoTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	runCtx, cancel := withProcessTimeout(ctx, executor.Meta{})
+	defer cancel()
+	require.Equal(t, ctx, runCtx)
+
+	failed := errors.New("exit code: 1")
+	require.Equal(t, failed, processTimeoutError(ctx, runCtx, failed, executor.Meta{}))
+}
+
+func TestProcessTimeoutErrorCallerCanceled(t *testing.T) {
+	t.Parallel()
+
+	meta := executor.Meta{Timeout: time.Hour}
+	ctx, cancelCaller := context.WithCancel(context.Background())
+	runCtx, cancel := withProcessTimeout(ctx, meta)
+	defer cancel()
+	cancelCaller()
+
+	failed := errors.New("exit code: 137")
+	require.Equal(t, failed, processTimeoutError(ctx, runCtx, failed, meta))
+}
+
+func TestOOMError(t *testing.T) {
+	t.Parallel()
+
+	meta := executor.Meta{ResourceLimits: executor.ResourceLimits{MemoryBytes: 64 << 20}}
+	killed := errors.New("exit code: 137")
+
+	err := oomError(context.Background(), killed, meta, resources.OOMStats{OOMKills: 1, PeakBytes: 64 << 20})
+	var oomErr *ExecOOMError
+	require.ErrorAs(t, err, &oomErr)
+	require.Equal(t, int64(64<<20), oomErr.MemoryLimit)
+	require.Equal(t, int64(64<<20), oomErr.PeakMemory)
+	require.ErrorIs(t, err, killed)
+	require.Equal(t, "process killed after exceeding memory limit of 64MiB (peak 64MiB): exit code: 137", err.Error())
+
+	// failures without OOM kills are left alone
+	require.Equal(t, killed, oomError(context.Background(), killed, meta, resources.OOMStats{PeakBytes: 1 << 20}))
+	require.NoError(t, oomError(context.Background(), nil, meta, resources.OOMStats{OOMKills: 1}))
+}
@@ -0,0 +1,180 @@
+package imageexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/pkg/labels"
+	attestationtypes "github.com/dagger/dagger/internal/buildkit/util/attestation"
+	"github.com/dagger/dagger/internal/buildkit/util/progress"
+	"github.com/dagger/dagger/internal/buildkit/util/purl"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	packageurl "github.com/package-url/packageurl-go"
+	"github.com/pkg/errors"
+)
+
+// Attestation is an in-toto predicate attached to a platform's image
+// manifest. The statement wrapping it is made to refer to the manifest once
+// it's committed.
+type Attestation struct {
+	PredicateType string
+	Predicate     json.RawMessage
+}
+
+const (
+	inTotoStatementType = "https://in-toto.io/Statement/v0.1"
+	inTotoMediaType     = "application/vnd.in-toto+json"
+)
+
+// attestationPlatform is the platform of attestation manifests in an image
+// index, which keeps platform-matching clients from pulling them as images.
+var attestationPlatform = ocispecs.Platform{
+	Architecture: "unknown",
+	OS:           "unknown",
+}
+
+type inTotoStatement struct {
+	Type          string          `json:"_type"`
+	PredicateType string          `json:"predicateType"`
+	Subject       []inTotoSubject `json:"subject"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+func hasAttestations(req *ExportRequest) bool {
+	for _, input := range req.Platforms {
+		if len(input.Attestations) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// attestationSubjects returns the in-toto subjects referring to a platform's
+// manifest under each of the image's names.
+func attestationSubjects(names []string, platform ocispecs.Platform, manifest ocispecs.Descriptor) ([]inTotoSubject, error) {
+	dgst := map[string]string{manifest.Digest.Algorithm().String(): manifest.Digest.Encoded()}
+	var subjects []inTotoSubject
+	for _, name := range names {
+		pl, err := purl.RefToPURL(packageurl.TypeDocker, name, &platform)
+		if err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, inTotoSubject{Name: pl, Digest: dgst})
+	}
+	if len(subjects) == 0 {
+		subjects = append(subjects, inTotoSubject{Name: "_", Digest: dgst})
+	}
+	return subjects, nil
+}
+
+// commitAttestationsManifest writes an attestation manifest holding an in-toto
+// statement per attestation about the given platform manifest, in the layout
+// BuildKit uses so that existing tooling can find them in the image index.
+func (w *Writer) commitAttestationsManifest(
+	ctx context.Context,
+	names []string,
+	input PlatformExportInput,
+	target ocispecs.Descriptor,
+	opts CommitOpts,
+) (ocispecs.Descriptor, error) {
+	manifestType := ocispecs.MediaTypeImageManifest
+	configType := ocispecs.MediaTypeImageConfig
+	if !opts.OCITypes {
+		manifestType = images.MediaTypeDockerSchema2Manifest
+		configType = images.MediaTypeDockerSchema2Config
+	}
+
+	subjects, err := attestationSubjects(names, input.Platform, target)
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+
+	config := ocispecs.Image{
+		Platform: attestationPlatform,
+		RootFS:   ocispecs.RootFS{Type: "layers"},
+	}
+	manifestDoc := ocispecs.Manifest{
+		MediaType: manifestType,
+		Versioned: specs.Versioned{
+			SchemaVersion: 2,
+		},
+	}
+	gcLabels := map[string]string{}
+	for i, att := range input.Attestations {
+		stmtJSON, err := json.Marshal(inTotoStatement{
+			Type:          inTotoStatementType,
+			PredicateType: att.PredicateType,
+			Subject:       subjects,
+			Predicate:     att.Predicate,
+		})
+		if err != nil {
+			return ocispecs.Descriptor{}, errors.Wrap(err, "failed to marshal attestation")
+		}
+		stmtDigest := digest.FromBytes(stmtJSON)
+		desc := ocispecs.Descriptor{
+			MediaType: inTotoMediaType,
+			Digest:    stmtDigest,
+			Size:      int64(len(stmtJSON)),
+			Annotations: map[string]string{
+				"in-toto.io/predicate-type": att.PredicateType,
+			},
+		}
+		if err := content.WriteBlob(ctx, w.opt.ContentStore, stmtDigest.String(), bytes.NewReader(stmtJSON), desc, content.WithLabels(map[string]string{
+			labels.LabelUncompressed: stmtDigest.String(),
+		})); err != nil {
+			return ocispecs.Descriptor{}, errors.Wrapf(err, "error writing attestation blob %s", stmtDigest)
+		}
+		manifestDoc.Layers = append(manifestDoc.Layers, desc)
+		config.RootFS.DiffIDs = append(config.RootFS.DiffIDs, stmtDigest)
+		gcLabels[fmt.Sprintf("containerd.io/gc.ref.content.%d", i+1)] = stmtDigest.String()
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return ocispecs.Descriptor{}, errors.Wrap(err, "failed to marshal attestations config")
+	}
+	configDigest := digest.FromBytes(configJSON)
+	manifestDoc.Config = ocispecs.Descriptor{
+		Digest:    configDigest,
+		Size:      int64(len(configJSON)),
+		MediaType: configType,
+	}
+	gcLabels["containerd.io/gc.ref.content.0"] = configDigest.String()
+
+	manifestJSON, err := json.MarshalIndent(manifestDoc, "", "  ")
+	if err != nil {
+		return ocispecs.Descriptor{}, errors.Wrap(err, "failed to marshal attestation manifest")
+	}
+	manifestDigest := digest.FromBytes(manifestJSON)
+	manifestDesc := ocispecs.Descriptor{
+		Digest:    manifestDigest,
+		Size:      int64(len(manifestJSON)),
+		MediaType: manifestType,
+	}
+	done := progress.OneOff(ctx, "exporting attestation manifest "+manifestDigest.String())
+	if err := content.WriteBlob(ctx, w.opt.ContentStore, configDigest.String(), bytes.NewReader(configJSON), manifestDoc.Config); err != nil {
+		return ocispecs.Descriptor{}, done(errors.Wrap(err, "error writing attestations config blob"))
+	}
+	if err := content.WriteBlob(ctx, w.opt.ContentStore, manifestDigest.String(), bytes.NewReader(manifestJSON), manifestDesc, content.WithLabels(gcLabels)); err != nil {
+		return ocispecs.Descriptor{}, done(errors.Wrapf(err, "error writing attestation manifest blob %s", manifestDigest))
+	}
+	done(nil)
+
+	manifestDesc.Platform = &attestationPlatform
+	manifestDesc.Annotations = map[string]string{
+		attestationtypes.DockerAnnotationReferenceType:   attestationtypes.DockerAnnotationReferenceTypeDefault,
+		attestationtypes.DockerAnnotationReferenceDigest: target.Digest.String(),
+	}
+	return manifestDesc, nil
+}
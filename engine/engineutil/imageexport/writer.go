@@ -44,6 +44,10 @@ type PlatformExportInput struct {
 
 	ManifestAnnotations           map[string]string
 	ManifestDescriptorAnnotations map[string]string
+
+	// Attestations are written to an attestation manifest referring to this
+	// platform's manifest.
+	Attestations []Attestation
 }
 
 type ExportRequest struct {
@@ -52,6 +56,9 @@ type ExportRequest struct {
 	IndexAnnotations           map[string]string
 	IndexDescriptorAnnotations map[string]string
 	InlineCache                map[string][]byte
+
+	// Names the image is exported under, used to name attestation subjects.
+	Names []string
 }
 
 type WriterOpt struct {
@@ -114,7 +121,9 @@ func (w *Writer) Assemble(
 	sourceAnnotations := map[digest.Digest]map[string]string{}
 	exportedPlatforms := make([]ExportedPlatform, 0, len(req.Platforms))
 
-	if len(req.Platforms) == 1 {
+	// attestation manifests live alongside the image manifests in an index,
+	// so single platform images need one too when there are attestations
+	if len(req.Platforms) == 1 && !hasAttestations(req) {
 		input := req.Platforms[0]
 		chain := &chains[0]
 		if chain.Provider == nil {
@@ -159,6 +168,7 @@ func (w *Writer) Assemble(
 	}
 
 	gcLabels := map[string]string{}
+	var attestationManifests []ocispecs.Descriptor
 	for i, input := range req.Platforms {
 		chain := &chains[i]
 		if chain.Provider == nil {
@@ -187,6 +197,18 @@ func (w *Writer) Assemble(
 			ManifestDesc: manifestDesc,
 			ConfigDesc:   configDesc,
 		})
+
+		if len(input.Attestations) > 0 {
+			attDesc, err := w.commitAttestationsManifest(ctx, req.Names, input, manifestDesc, opts)
+			if err != nil {
+				return nil, err
+			}
+			attestationManifests = append(attestationManifests, attDesc)
+		}
+	}
+	for _, attDesc := range attestationManifests {
+		gcLabels[fmt.Sprintf("containerd.io/gc.ref.content.%d", len(index.Manifests))] = attDesc.Digest.String()
+		index.Manifests = append(index.Manifests, attDesc)
 	}
 
 	indexJSON, err := json.MarshalIndent(index, "", "  ")
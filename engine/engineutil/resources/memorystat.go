@@ -15,6 +15,7 @@ import (
 const (
 	memoryCurrentFile = "memory.current"
 	memoryPeakFile    = "memory.peak"
+	memoryEventsFile  = "memory.events"
 )
 
 type memoryCurrentSampler struct {
@@ -106,3 +107,50 @@ func (s *memoryPeakSampler) sample(ctx context.Context) error {
 
 	return nil
 }
+
+// OOMStats describes the out-of-memory kills and peak memory usage of a
+// cgroup.
+type OOMStats struct {
+	// OOMKills is the number of processes killed by the OOM killer.
+	OOMKills int64
+	// PeakBytes is the highest memory usage recorded, or zero if the kernel
+	// doesn't report it.
+	PeakBytes int64
+}
+
+// ReadOOMStats reads the OOM stats of the cgroup at the given path relative to
+// the cgroup mountpoint. It must be called before the cgroup is removed.
+func ReadOOMStats(cgroupNSSubpath string) (OOMStats, error) {
+	return readOOMStats(filepath.Join(defaultMountpoint, cgroupNSSubpath))
+}
+
+func readOOMStats(cgroupPath string) (OOMStats, error) {
+	var stats OOMStats
+
+	eventsPath := filepath.Join(cgroupPath, memoryEventsFile)
+	bs, err := os.ReadFile(eventsPath)
+	if err != nil {
+		return stats, fmt.Errorf("failed to read %s: %w", eventsPath, err)
+	}
+	for key, value := range flatKeyValuesInt64(bs) {
+		if key == "oom_kill" {
+			stats.OOMKills = value
+		}
+	}
+
+	peakPath := filepath.Join(cgroupPath, memoryPeakFile)
+	bs, err = os.ReadFile(peakPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// memory.peak is only available on kernels >= 5.19
+		return stats, nil
+	case err != nil:
+		return stats, fmt.Errorf("failed to read %s: %w", peakPath, err)
+	}
+	stats.PeakBytes, err = singleValue(bs)
+	if err != nil {
+		return stats, fmt.Errorf("error converting value to int64: %w", err)
+	}
+
+	return stats, nil
+}
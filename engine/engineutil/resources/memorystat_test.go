@@ -0,0 +1,32 @@
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOOMStats(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, memoryEventsFile), []byte("low 0\nhigh 0\nmax 12\noom 1\noom_kill 1\noom_group_kill 0\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, memoryPeakFile), []byte("67108864\n"), 0o600))
+
+	stats, err := readOOMStats(dir)
+	require.NoError(t, err)
+	require.Equal(t, OOMStats{OOMKills: 1, PeakBytes: 64 << 20}, stats)
+}
+
+func TestReadOOMStatsNoPeak(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, memoryEventsFile), []byte("oom 0\noom_kill 0\n"), 0o600))
+
+	stats, err := readOOMStats(dir)
+	require.NoError(t, err)
+	require.Equal(t, OOMStats{}, stats)
+}
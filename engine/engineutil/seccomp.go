@@ -0,0 +1,118 @@
+package engineutil
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// layerSeccomp returns a seccomp filter allowing only the syscalls both base
+// and top allow, so that a container's own profile can tighten the default
+// one but not loosen it.
+//
+// Syscalls that both filters only allow for some arguments can't be combined
+// unless their rules are the same. Syscalls allowed for some arguments by
+// one filter fall back to the stricter default action of the two otherwise.
+func layerSeccomp(base, top *specs.LinuxSeccomp) (*specs.LinuxSeccomp, error) {
+	layered := &specs.LinuxSeccomp{
+		DefaultAction:    base.DefaultAction,
+		DefaultErrnoRet:  base.DefaultErrnoRet,
+		Architectures:    base.Architectures,
+		Flags:            slices.Clone(base.Flags),
+		ListenerPath:     base.ListenerPath,
+		ListenerMetadata: base.ListenerMetadata,
+	}
+	if seccompAllows(base.DefaultAction) && !seccompAllows(top.DefaultAction) {
+		layered.DefaultAction = top.DefaultAction
+		layered.DefaultErrnoRet = top.DefaultErrnoRet
+	}
+	if len(layered.Architectures) == 0 {
+		layered.Architectures = top.Architectures
+	}
+	for _, flag := range top.Flags {
+		if !slices.Contains(layered.Flags, flag) {
+			layered.Flags = append(layered.Flags, flag)
+		}
+	}
+
+	baseRules, names := seccompRules(base, nil)
+	topRules, names := seccompRules(top, names)
+	for _, name := range names {
+		rules, err := layerSyscallRules(
+			name,
+			syscallRules(base, baseRules, name),
+			syscallRules(top, topRules, name),
+		)
+		if err != nil {
+			return nil, err
+		}
+		layered.Syscalls = append(layered.Syscalls, rules...)
+	}
+	return layered, nil
+}
+
+// layerSyscallRules combines the rules of two filters for a syscall.
+func layerSyscallRules(name string, base, top []specs.LinuxSyscall) ([]specs.LinuxSyscall, error) {
+	baseAction, baseUnconditional := unconditionalAction(base)
+	topAction, topUnconditional := unconditionalAction(top)
+	switch {
+	case baseUnconditional && !seccompAllows(baseAction):
+		return base, nil
+	case topUnconditional && !seccompAllows(topAction):
+		return top, nil
+	case baseUnconditional:
+		return top, nil
+	case topUnconditional:
+		return base, nil
+	case reflect.DeepEqual(base, top):
+		return base, nil
+	default:
+		return nil, fmt.Errorf("the seccomp profile and the default one both restrict the arguments of %s differently", name)
+	}
+}
+
+// seccompRules returns the rules of a filter by syscall, each for that
+// syscall only, and appends the syscalls not seen yet to names.
+func seccompRules(filter *specs.LinuxSeccomp, names []string) (map[string][]specs.LinuxSyscall, []string) {
+	rules := map[string][]specs.LinuxSyscall{}
+	for _, rule := range filter.Syscalls {
+		for _, name := range rule.Names {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+			named := rule
+			named.Names = []string{name}
+			rules[name] = append(rules[name], named)
+		}
+	}
+	return rules, names
+}
+
+// syscallRules returns the rules of a filter for a syscall, falling back to
+// its default action.
+func syscallRules(filter *specs.LinuxSeccomp, rules map[string][]specs.LinuxSyscall, name string) []specs.LinuxSyscall {
+	if syscallRules, ok := rules[name]; ok {
+		return syscallRules
+	}
+	return []specs.LinuxSyscall{{
+		Names:    []string{name},
+		Action:   filter.DefaultAction,
+		ErrnoRet: filter.DefaultErrnoRet,
+	}}
+}
+
+// unconditionalAction returns the action of rules applying to a syscall
+// whatever its arguments.
+func unconditionalAction(rules []specs.LinuxSyscall) (specs.LinuxSeccompAction, bool) {
+	if len(rules) != 1 || len(rules[0].Args) > 0 {
+		return "", false
+	}
+	return rules[0].Action, true
+}
+
+// seccompAllows reports whether a seccomp action lets syscalls through.
+func seccompAllows(action specs.LinuxSeccompAction) bool {
+	return action == specs.ActAllow || action == specs.ActLog
+}
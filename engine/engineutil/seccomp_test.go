@@ -0,0 +1,69 @@
+package engineutil
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayerSeccomp(t *testing.T) {
+	t.Parallel()
+
+	cloneArgs := []specs.LinuxSeccompArg{{Index: 0, Value: 0x10000000, Op: specs.OpMaskedEqual, ValueTwo: 0}}
+	base := &specs.LinuxSeccomp{
+		DefaultAction: specs.ActErrno,
+		Architectures: []specs.Arch{specs.ArchX86_64},
+		Syscalls: []specs.LinuxSyscall{
+			{Names: []string{"read", "write", "ptrace"}, Action: specs.ActAllow},
+			{Names: []string{"clone"}, Action: specs.ActAllow, Args: cloneArgs},
+		},
+	}
+
+	// an allowlist only keeps the syscalls both allow
+	layered, err := layerSeccomp(base, &specs.LinuxSeccomp{
+		DefaultAction: specs.ActKill,
+		Syscalls: []specs.LinuxSyscall{
+			{Names: []string{"read", "clone", "reboot"}, Action: specs.ActAllow},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &specs.LinuxSeccomp{
+		DefaultAction: specs.ActErrno,
+		Architectures: []specs.Arch{specs.ArchX86_64},
+		Syscalls: []specs.LinuxSyscall{
+			{Names: []string{"read"}, Action: specs.ActAllow},
+			{Names: []string{"write"}, Action: specs.ActKill},
+			{Names: []string{"ptrace"}, Action: specs.ActKill},
+			{Names: []string{"clone"}, Action: specs.ActAllow, Args: cloneArgs},
+			{Names: []string{"reboot"}, Action: specs.ActErrno},
+		},
+	}, layered)
+
+	// a denylist is added to the default one
+	layered, err = layerSeccomp(base, &specs.LinuxSeccomp{
+		DefaultAction: specs.ActAllow,
+		Syscalls: []specs.LinuxSyscall{
+			{Names: []string{"ptrace"}, Action: specs.ActErrno},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, specs.ActErrno, layered.DefaultAction)
+	require.Equal(t, []specs.LinuxSyscall{
+		{Names: []string{"read"}, Action: specs.ActAllow},
+		{Names: []string{"write"}, Action: specs.ActAllow},
+		{Names: []string{"ptrace"}, Action: specs.ActErrno},
+		{Names: []string{"clone"}, Action: specs.ActAllow, Args: cloneArgs},
+	}, layered.Syscalls)
+
+	// the same rules on arguments are kept, different ones can't be combined
+	_, err = layerSeccomp(base, base)
+	require.NoError(t, err)
+	_, err = layerSeccomp(base, &specs.LinuxSeccomp{
+		DefaultAction: specs.ActErrno,
+		Syscalls: []specs.LinuxSyscall{
+			{Names: []string{"clone"}, Action: specs.ActAllow, Args: []specs.LinuxSeccompArg{{Index: 1, Value: 1, Op: specs.OpEqualTo}}},
+		},
+	})
+	require.ErrorContains(t, err, "arguments of clone")
+}
@@ -0,0 +1,222 @@
+package engineutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dagger/dagger/internal/buildkit/executor"
+	"github.com/moby/profiles/seccomp"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// SecurityPolicy is the engine-wide policy for the security profiles of
+// containers.
+type SecurityPolicy struct {
+	// Default is applied to every container. Containers can tighten it with
+	// their own profile, but not loosen it. Its seccomp profile replaces the
+	// built-in one, and containers' own seccomp profiles are layered on top.
+	Default executor.SecurityProfile
+	// AllowedCapabilities lists the capabilities containers may add. If nil,
+	// any capability of DefaultCapabilities not dropped by Default may be
+	// added, and any other too with InsecureCapabilities.
+	AllowedCapabilities []string
+	// InsecureCapabilities allows adding capabilities outside of
+	// DefaultCapabilities, such as CAP_SYS_ADMIN. It's set when the engine
+	// grants the security.insecure entitlement.
+	InsecureCapabilities bool
+}
+
+// DefaultCapabilities are the capabilities containers get by default.
+var DefaultCapabilities = []string{
+	"CAP_CHOWN",
+	"CAP_DAC_OVERRIDE",
+	"CAP_FSETID",
+	"CAP_FOWNER",
+	"CAP_MKNOD",
+	"CAP_NET_RAW",
+	"CAP_SETGID",
+	"CAP_SETUID",
+	"CAP_SETFCAP",
+	"CAP_SETPCAP",
+	"CAP_NET_BIND_SERVICE",
+	"CAP_SYS_CHROOT",
+	"CAP_KILL",
+	"CAP_AUDIT_WRITE",
+}
+
+// SecurityPolicyError is returned when a container's security profile
+// violates the engine's security policy.
+type SecurityPolicyError struct {
+	Reason string
+}
+
+func (e *SecurityPolicyError) Error() string {
+	return "security policy violation: " + e.Reason
+}
+
+// Resolve returns the profile to apply to a container, combining its own
+// profile with the policy's default.
+func (p SecurityPolicy) Resolve(profile executor.SecurityProfile) (executor.SecurityProfile, error) {
+	for _, capName := range profile.CapAdd {
+		if p.AllowedCapabilities != nil {
+			if !slices.Contains(p.AllowedCapabilities, capName) {
+				return profile, &SecurityPolicyError{
+					Reason: fmt.Sprintf("capability %s is not in the engine's allowed capabilities (%s)", capName, strings.Join(p.AllowedCapabilities, ", ")),
+				}
+			}
+			continue
+		}
+		if slices.Contains(p.Default.CapDrop, "ALL") || slices.Contains(p.Default.CapDrop, capName) {
+			return profile, &SecurityPolicyError{
+				Reason: fmt.Sprintf("capability %s is dropped by the engine's default security profile and can't be added", capName),
+			}
+		}
+		if !p.InsecureCapabilities && !slices.Contains(DefaultCapabilities, capName) {
+			return profile, &SecurityPolicyError{
+				Reason: fmt.Sprintf("capability %s is not in the default set and can only be added if the engine allows insecure root capabilities", capName),
+			}
+		}
+	}
+
+	resolved := executor.SecurityProfile{
+		SeccompProfile:        profile.SeccompProfile,
+		DefaultSeccompProfile: p.Default.SeccompProfile,
+		CapAdd:                slices.Clone(profile.CapAdd),
+		CapDrop:               slices.Clone(p.Default.CapDrop),
+		ReadOnlyRootfs:        profile.ReadOnlyRootfs || p.Default.ReadOnlyRootfs,
+		NoNewPrivileges:       profile.NoNewPrivileges || p.Default.NoNewPrivileges,
+	}
+	for _, capName := range profile.CapDrop {
+		if !slices.Contains(resolved.CapDrop, capName) {
+			resolved.CapDrop = append(resolved.CapDrop, capName)
+		}
+	}
+	return resolved, nil
+}
+
+var capabilityRegexp = regexp.MustCompile(`^CAP_[A-Z_]+$`)
+
+// NormalizeCapability returns the canonical name of a capability, accepting
+// names without the "CAP_" prefix and in any case, like "net_raw". "ALL" is
+// returned as is.
+func NormalizeCapability(name string) (string, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "ALL" {
+		return name, nil
+	}
+	if !strings.HasPrefix(name, "CAP_") {
+		name = "CAP_" + name
+	}
+	if !capabilityRegexp.MatchString(name) {
+		return "", fmt.Errorf("invalid capability %q", name)
+	}
+	return name, nil
+}
+
+// ValidateSeccompProfile checks that a seccomp profile is in the JSON format
+// used by Docker.
+func ValidateSeccompProfile(profile string) error {
+	var config seccomp.Seccomp
+	if err := json.Unmarshal([]byte(profile), &config); err != nil {
+		return fmt.Errorf("invalid seccomp profile: %w", err)
+	}
+	if config.DefaultAction == "" {
+		return errors.New("invalid seccomp profile: missing defaultAction")
+	}
+	return nil
+}
+
+func (c *Client) applySecurityProfile(_ context.Context, state *execState) error {
+	profile := state.procInfo.Meta.SecurityProfile
+	if profile.IsZero() {
+		return nil
+	}
+
+	if profile.ReadOnlyRootfs {
+		if state.spec.Root == nil {
+			state.spec.Root = &specs.Root{}
+		}
+		state.spec.Root.Readonly = true
+	}
+	if profile.NoNewPrivileges {
+		state.spec.Process.NoNewPrivileges = true
+	}
+
+	capsChanged := len(profile.CapAdd) > 0 || len(profile.CapDrop) > 0
+	if capsChanged {
+		if state.spec.Process.Capabilities == nil {
+			state.spec.Process.Capabilities = &specs.LinuxCapabilities{}
+		}
+		applyCapabilities(state.spec.Process.Capabilities, profile.CapAdd, profile.CapDrop)
+	}
+
+	if state.spec.Linux == nil {
+		state.spec.Linux = &specs.Linux{}
+	}
+	seccompProfile := state.spec.Linux.Seccomp
+	switch {
+	case profile.DefaultSeccompProfile != "":
+		var err error
+		seccompProfile, err = seccomp.LoadProfile(profile.DefaultSeccompProfile, state.spec)
+		if err != nil {
+			return fmt.Errorf("load default seccomp profile: %w", err)
+		}
+	case capsChanged && seccompProfile != nil:
+		// the default profile allows syscalls based on capabilities, so it
+		// has to be generated again
+		var err error
+		seccompProfile, err = seccomp.GetDefaultProfile(state.spec)
+		if err != nil {
+			return fmt.Errorf("generate seccomp profile: %w", err)
+		}
+	}
+	if profile.SeccompProfile != "" {
+		containerProfile, err := seccomp.LoadProfile(profile.SeccompProfile, state.spec)
+		if err != nil {
+			return fmt.Errorf("load seccomp profile: %w", err)
+		}
+		if seccompProfile == nil {
+			seccompProfile = containerProfile
+		} else {
+			seccompProfile, err = layerSeccomp(seccompProfile, containerProfile)
+			if err != nil {
+				return fmt.Errorf("layer seccomp profile: %w", err)
+			}
+		}
+	}
+	state.spec.Linux.Seccomp = seccompProfile
+	return nil
+}
+
+// applyCapabilities drops and then adds capabilities to the bounding,
+// effective and permitted sets, so that dropping "ALL" and adding some keeps
+// only those.
+func applyCapabilities(caps *specs.LinuxCapabilities, add, drop []string) {
+	dropAll := slices.Contains(drop, "ALL")
+	filter := func(set []string) []string {
+		if dropAll {
+			return nil
+		}
+		return slices.DeleteFunc(slices.Clone(set), func(capName string) bool {
+			return slices.Contains(drop, capName)
+		})
+	}
+	caps.Bounding = filter(caps.Bounding)
+	caps.Effective = filter(caps.Effective)
+	caps.Permitted = filter(caps.Permitted)
+	caps.Inheritable = filter(caps.Inheritable)
+	caps.Ambient = filter(caps.Ambient)
+
+	for _, capName := range add {
+		for _, set := range []*[]string{&caps.Bounding, &caps.Effective, &caps.Permitted} {
+			if !slices.Contains(*set, capName) {
+				*set = append(*set, capName)
+			}
+		}
+	}
+}
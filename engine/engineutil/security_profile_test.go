@@ -0,0 +1,182 @@
+package engineutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dagger/dagger/internal/buildkit/executor"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityPolicyResolve(t *testing.T) {
+	t.Parallel()
+
+	policy := SecurityPolicy{
+		Default: executor.SecurityProfile{
+			CapDrop:         []string{"CAP_NET_RAW"},
+			NoNewPrivileges: true,
+		},
+		InsecureCapabilities: true,
+	}
+
+	profile, err := policy.Resolve(executor.SecurityProfile{
+		CapAdd:         []string{"CAP_SYS_PTRACE"},
+		CapDrop:        []string{"CAP_NET_RAW", "CAP_MKNOD"},
+		ReadOnlyRootfs: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, executor.SecurityProfile{
+		CapAdd:          []string{"CAP_SYS_PTRACE"},
+		CapDrop:         []string{"CAP_NET_RAW", "CAP_MKNOD"},
+		ReadOnlyRootfs:  true,
+		NoNewPrivileges: true,
+	}, profile)
+
+	// the default can't be loosened
+	_, err = policy.Resolve(executor.SecurityProfile{CapAdd: []string{"CAP_NET_RAW"}})
+	var policyErr *SecurityPolicyError
+	require.ErrorAs(t, err, &policyErr)
+	require.ErrorContains(t, err, "security policy violation: capability CAP_NET_RAW is dropped by the engine's default security profile")
+
+	// only the default capabilities can be added without insecure root
+	// capabilities
+	secure := policy
+	secure.InsecureCapabilities = false
+	_, err = secure.Resolve(executor.SecurityProfile{CapAdd: []string{"CAP_SYS_ADMIN"}})
+	require.ErrorAs(t, err, &policyErr)
+	require.ErrorContains(t, err, "capability CAP_SYS_ADMIN is not in the default set")
+	_, err = secure.Resolve(executor.SecurityProfile{CapAdd: []string{"CAP_NET_ADMIN"}})
+	require.ErrorAs(t, err, &policyErr)
+	profile, err = secure.Resolve(executor.SecurityProfile{CapAdd: []string{"CAP_KILL"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"CAP_KILL"}, profile.CapAdd)
+
+	// the container's seccomp profile is layered on top of the default one
+	policy.Default.SeccompProfile = `{"defaultAction":"SCMP_ACT_ERRNO"}`
+	profile, err = policy.Resolve(executor.SecurityProfile{SeccompProfile: `{"defaultAction":"SCMP_ACT_ALLOW"}`})
+	require.NoError(t, err)
+	require.Equal(t, `{"defaultAction":"SCMP_ACT_ALLOW"}`, profile.SeccompProfile)
+	require.Equal(t, policy.Default.SeccompProfile, profile.DefaultSeccompProfile)
+
+	profile, err = policy.Resolve(executor.SecurityProfile{})
+	require.NoError(t, err)
+	require.Empty(t, profile.SeccompProfile)
+	require.Equal(t, policy.Default.SeccompProfile, profile.DefaultSeccompProfile)
+}
+
+func TestSecurityPolicyAllowedCapabilities(t *testing.T) {
+	t.Parallel()
+
+	policy := SecurityPolicy{
+		Default:             executor.SecurityProfile{CapDrop: []string{"ALL"}},
+		AllowedCapabilities: []string{"CAP_NET_BIND_SERVICE"},
+	}
+
+	profile, err := policy.Resolve(executor.SecurityProfile{CapAdd: []string{"CAP_NET_BIND_SERVICE"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"CAP_NET_BIND_SERVICE"}, profile.CapAdd)
+	require.Equal(t, []string{"ALL"}, profile.CapDrop)
+
+	_, err = policy.Resolve(executor.SecurityProfile{CapAdd: []string{"CAP_SYS_ADMIN"}})
+	require.ErrorContains(t, err, "capability CAP_SYS_ADMIN is not in the engine's allowed capabilities (CAP_NET_BIND_SERVICE)")
+}
+
+func TestNormalizeCapability(t *testing.T) {
+	t.Parallel()
+
+	for in, out := range map[string]string{
+		"net_raw":       "CAP_NET_RAW",
+		"CAP_SYS_ADMIN": "CAP_SYS_ADMIN",
+		"all":           "ALL",
+	} {
+		capName, err := NormalizeCapability(in)
+		require.NoError(t, err)
+		require.Equal(t, out, capName)
+	}
+	_, err := NormalizeCapability("net-raw")
+	require.Error(t, err)
+}
+
+func TestApplySecurityProfile(t *testing.T) {
+	t.Parallel()
+
+	defaultCaps := []string{"CAP_CHOWN", "CAP_NET_RAW", "CAP_SETUID"}
+	newState := func(profile executor.SecurityProfile) *execState {
+		return &execState{
+			procInfo: &executor.ProcessInfo{
+				Meta: executor.Meta{SecurityProfile: profile},
+			},
+			spec: &specs.Spec{
+				Root: &specs.Root{Path: "rootfs"},
+				Process: &specs.Process{
+					Capabilities: &specs.LinuxCapabilities{
+						Bounding:  defaultCaps,
+						Effective: defaultCaps,
+						Permitted: defaultCaps,
+					},
+				},
+				Linux: &specs.Linux{},
+			},
+		}
+	}
+
+	state := newState(executor.SecurityProfile{
+		CapAdd:          []string{"CAP_SYS_PTRACE"},
+		CapDrop:         []string{"CAP_NET_RAW"},
+		ReadOnlyRootfs:  true,
+		NoNewPrivileges: true,
+		SeccompProfile:  `{"defaultAction":"SCMP_ACT_ERRNO","syscalls":[{"names":["read","write"],"action":"SCMP_ACT_ALLOW"}]}`,
+	})
+	require.NoError(t, (&Client{}).applySecurityProfile(context.Background(), state))
+	require.True(t, state.spec.Root.Readonly)
+	require.True(t, state.spec.Process.NoNewPrivileges)
+	caps := state.spec.Process.Capabilities
+	require.Equal(t, []string{"CAP_CHOWN", "CAP_SETUID", "CAP_SYS_PTRACE"}, caps.Bounding)
+	require.Equal(t, []string{"CAP_CHOWN", "CAP_SETUID", "CAP_SYS_PTRACE"}, caps.Effective)
+	require.Equal(t, []string{"CAP_CHOWN", "CAP_SETUID", "CAP_SYS_PTRACE"}, caps.Permitted)
+	require.NotNil(t, state.spec.Linux.Seccomp)
+	require.Equal(t, specs.ActErrno, state.spec.Linux.Seccomp.DefaultAction)
+	require.Equal(t, []string{"read", "write"}, state.spec.Linux.Seccomp.Syscalls[0].Names)
+
+	state = newState(executor.SecurityProfile{
+		CapAdd:  []string{"CAP_NET_BIND_SERVICE"},
+		CapDrop: []string{"ALL"},
+	})
+	require.NoError(t, (&Client{}).applySecurityProfile(context.Background(), state))
+	require.Equal(t, []string{"CAP_NET_BIND_SERVICE"}, state.spec.Process.Capabilities.Bounding)
+	require.False(t, state.spec.Root.Readonly)
+	require.Nil(t, state.spec.Linux.Seccomp)
+
+	// a container's seccomp profile can't allow what the default one denies
+	state = newState(executor.SecurityProfile{
+		SeccompProfile: `{"defaultAction":"SCMP_ACT_ALLOW","syscalls":[{"names":["mkdir"],"action":"SCMP_ACT_ERRNO"}]}`,
+	})
+	state.spec.Linux.Seccomp = &specs.LinuxSeccomp{
+		DefaultAction: specs.ActErrno,
+		Syscalls: []specs.LinuxSyscall{
+			{Names: []string{"read", "write", "mkdir"}, Action: specs.ActAllow},
+		},
+	}
+	require.NoError(t, (&Client{}).applySecurityProfile(context.Background(), state))
+	require.Equal(t, &specs.LinuxSeccomp{
+		DefaultAction: specs.ActErrno,
+		Syscalls: []specs.LinuxSyscall{
+			{Names: []string{"read"}, Action: specs.ActAllow},
+			{Names: []string{"write"}, Action: specs.ActAllow},
+			{Names: []string{"mkdir"}, Action: specs.ActErrno},
+		},
+	}, state.spec.Linux.Seccomp)
+
+	// the engine's default seccomp profile replaces the built-in one
+	state = newState(executor.SecurityProfile{
+		DefaultSeccompProfile: `{"defaultAction":"SCMP_ACT_ALLOW","syscalls":[{"names":["reboot"],"action":"SCMP_ACT_KILL"}]}`,
+		SeccompProfile:        `{"defaultAction":"SCMP_ACT_ALLOW","syscalls":[{"names":["mkdir"],"action":"SCMP_ACT_ERRNO"}]}`,
+	})
+	require.NoError(t, (&Client{}).applySecurityProfile(context.Background(), state))
+	require.Equal(t, specs.ActAllow, state.spec.Linux.Seccomp.DefaultAction)
+	require.Equal(t, []specs.LinuxSyscall{
+		{Names: []string{"reboot"}, Action: specs.ActKill},
+		{Names: []string{"mkdir"}, Action: specs.ActErrno},
+	}, state.spec.Linux.Seccomp.Syscalls)
+}
@@ -15,6 +15,7 @@ import (
 
 	controlapi "github.com/dagger/dagger/internal/buildkit/api/services/control"
 	"github.com/dagger/dagger/internal/cloud/auth"
+	"github.com/dagger/dagger/network"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -98,6 +99,20 @@ type ClientMetadata struct {
 	// Modules permitted to access LLM APIs or "all" to bypass restrictions for any loaded module.
 	AllowedLLMModules []string `json:"allowed_llm_modules"`
 
+	// Egress policies applied to the containers run by the client, inherited
+	// from the containers and modules its parent clients run in.
+	EgressPolicies []network.EgressPolicy `json:"egress_policies,omitempty"`
+
+	// Tags attached to the cache entries produced by the client, inherited by
+	// the containers and modules it runs, so they can be pruned selectively.
+	CacheTags []string `json:"cache_tags,omitempty"`
+
+	// The module whose function the client runs in, attached to the cache
+	// entries it produces, e.g. the layers of the containers the function
+	// runs, so they can be pruned along with the module's function calls.
+	// Inherited by the containers and modules it runs.
+	CacheModule *CacheModule `json:"cache_module,omitempty"`
+
 	// Disable lazy loading on module runtime.
 	EagerRuntime bool `json:"eager_runtime"`
 
@@ -170,6 +185,14 @@ type ClientMetadata struct {
 	Profile bool `json:"profile,omitempty"`
 }
 
+// CacheModule identifies a module in the cache entries it produced: by name,
+// source ref and, for git sources, pinned commit.
+type CacheModule struct {
+	Name string `json:"name"`
+	Ref  string `json:"ref,omitempty"`
+	Pin  string `json:"pin,omitempty"`
+}
+
 type clientMetadataCtxKey struct{}
 
 func ContextWithClientMetadata(ctx context.Context, clientMetadata *ClientMetadata) context.Context {
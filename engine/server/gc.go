@@ -116,7 +116,11 @@ func (srv *Server) PruneEngineLocalCacheEntries(ctx context.Context, opts core.E
 		if err != nil {
 			return nil, err
 		}
-		report, err = srv.engineCache.Prune(ctx, prunePolicies)
+		prune := srv.engineCache.Prune
+		if opts.DryRun {
+			prune = srv.engineCache.PlanPrune
+		}
+		report, err = prune(ctx, prunePolicies)
 		if err != nil {
 			rerr = errors.Join(rerr, fmt.Errorf("failed to prune dagql cache: %w", err))
 		}
@@ -146,6 +150,11 @@ func engineLocalCachePruneModes(opts core.EngineCachePruneOptions, automaticGCEn
 	// Preserve the legacy no-option prune-all behavior, while ensuring that a
 	// structural-only request does not run that implicit disk stage first.
 	disk = opts.UseDefaultPolicy || hasDiskOptions || !explicitMetadata
+	// The structural stage can't be planned; a dry run only reports the disk
+	// stage.
+	if opts.DryRun {
+		metadata = false
+	}
 	return disk, metadata
 }
 
@@ -199,6 +208,11 @@ func engineCacheEntrySetFromUsage(entries []dagql.CacheUsageEntry) *core.EngineC
 			RecordType:                entry.RecordType,
 			RecordTypes:               entry.RecordTypes,
 			DagqlCall:                 entry.DagqlCall,
+			Module:                    entry.ModuleRef,
+			Tags:                      entry.Tags,
+		}
+		if ent.Module == "" {
+			ent.Module = entry.ModuleName
 		}
 		set.EntriesList = append(set.EntriesList, ent)
 		set.DiskSpaceBytes += int(entry.SizeBytes)
@@ -229,12 +243,45 @@ func resolveEngineLocalCachePrunePolicies(defaultPolicy []dagqlCachePrunePolicy,
 			return nil, err
 		}
 	}
+	applyEngineCachePruneSelectors(prunePolicies, opts)
 	for i := range prunePolicies {
 		prunePolicies[i].CurrentFreeSpace = dstat.Available
 	}
 	return prunePolicies, nil
 }
 
+// applyEngineCachePruneSelectors narrows the prune policies to the entries
+// matching the module, field, tag and age selectors of a prune request.
+func applyEngineCachePruneSelectors(prunePolicies []dagqlCachePrunePolicy, opts core.EngineCachePruneOptions) {
+	var filters []string
+	for key, values := range map[string][]string{
+		"module": opts.Modules,
+		"field":  opts.Fields,
+		"tag":    opts.Tags,
+	} {
+		clauses := make([]string, 0, len(values))
+		for _, value := range values {
+			if value = strings.TrimSpace(value); value != "" {
+				clauses = append(clauses, key+"=="+value)
+			}
+		}
+		if len(clauses) > 0 {
+			filters = append(filters, strings.Join(clauses, ","))
+		}
+	}
+	slices.Sort(filters)
+	for i := range prunePolicies {
+		if len(filters) > 0 {
+			// All matches every entry regardless of filters
+			prunePolicies[i].All = false
+			prunePolicies[i].Filters = append(prunePolicies[i].Filters, filters...)
+		}
+		if opts.OlderThan > prunePolicies[i].KeepDuration {
+			prunePolicies[i].KeepDuration = opts.OlderThan
+		}
+	}
+}
+
 func applyEngineCachePruneSpaceOverrides(prunePolicies []dagqlCachePrunePolicy, dstat disk.DiskStat, maxUsedSpace, reservedSpace, minFreeSpace, targetSpace string) error {
 	var (
 		maxUsedSpaceBytes  int64
@@ -197,6 +197,57 @@ func TestResolveEngineLocalCachePrunePoliciesUsesAvailableSpaceForMinFree(t *tes
 	require.GreaterOrEqual(t, dstat.Free, prunePolicies[0].MinFreeSpace)
 }
 
+func TestResolveEngineLocalCachePrunePoliciesSelectors(t *testing.T) {
+	dstat := disk.DiskStat{Total: 100 * 1e9}
+	defaultPolicy := []dagqlCachePrunePolicy{
+		{
+			All:          true,
+			KeepDuration: time.Hour,
+		},
+		{
+			Filters:      []string{"type==source.local"},
+			KeepDuration: 72 * time.Hour,
+		},
+	}
+	originalPolicy := cloneDagqlCachePrunePolicies(defaultPolicy)
+
+	opts := core.EngineCachePruneOptions{
+		UseDefaultPolicy: true,
+		Modules:          []string{"github.com/dagger/jest", " "},
+		Fields:           []string{"Container.from", "Directory.from"},
+		OlderThan:        24 * time.Hour,
+	}
+	prunePolicies, err := resolveEngineLocalCachePrunePolicies(defaultPolicy, opts, dstat)
+	require.NoError(t, err)
+	require.Len(t, prunePolicies, 2)
+
+	require.False(t, prunePolicies[0].All)
+	require.Equal(t, []string{
+		"field==Container.from,field==Directory.from",
+		"module==github.com/dagger/jest",
+	}, prunePolicies[0].Filters)
+	require.Equal(t, 24*time.Hour, prunePolicies[0].KeepDuration)
+
+	require.Equal(t, []string{
+		"type==source.local",
+		"field==Container.from,field==Directory.from",
+		"module==github.com/dagger/jest",
+	}, prunePolicies[1].Filters)
+	require.Equal(t, 72*time.Hour, prunePolicies[1].KeepDuration)
+
+	require.Equal(t, originalPolicy, defaultPolicy)
+
+	// an age alone keeps pruning every entry old enough
+	prunePolicies, err = resolveEngineLocalCachePrunePolicies(nil, core.EngineCachePruneOptions{
+		OlderThan: time.Hour,
+	}, dstat)
+	require.NoError(t, err)
+	require.Equal(t, []dagqlCachePrunePolicy{{
+		All:          true,
+		KeepDuration: time.Hour,
+	}}, prunePolicies)
+}
+
 func TestEngineLocalCachePruneModes(t *testing.T) {
 	maximum := int64(100)
 	target := int64(50)
@@ -244,6 +295,17 @@ func TestEngineLocalCachePruneModes(t *testing.T) {
 			opts:     core.EngineCachePruneOptions{UseDefaultPolicy: true},
 			wantDisk: true,
 		},
+		{
+			name: "dry run",
+			opts: core.EngineCachePruneOptions{
+				UseDefaultPolicy:     true,
+				MaxEstimatedBytes:    &maximum,
+				TargetEstimatedBytes: &target,
+				DryRun:               true,
+			},
+			automaticGC: true,
+			wantDisk:    true,
+		},
 		{
 			name: "disabled default policy with explicit metadata",
 			opts: core.EngineCachePruneOptions{
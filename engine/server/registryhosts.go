@@ -61,7 +61,7 @@ func newRegistryHosts(registries map[string]resolverconfig.RegistryConfig) docke
 				Scheme:       "https",
 				Host:         originHost,
 				Path:         defaultRegistryPath,
-				Capabilities: docker.HostCapabilityPush | docker.HostCapabilityPull | docker.HostCapabilityResolve,
+				Capabilities: docker.HostCapabilityPush | docker.HostCapabilityPull | docker.HostCapabilityResolve | docker.HostCapabilityReferrers,
 			})
 			if err != nil {
 				return nil, err
@@ -0,0 +1,188 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	cerrdefs "github.com/containerd/errdefs"
+	bkcache "github.com/dagger/dagger/engine/snapshots"
+	"github.com/dagger/dagger/internal/buildkit/util/contentutil"
+	"github.com/dagger/dagger/internal/buildkit/util/tracing"
+	telemetry "github.com/dagger/otel-go"
+	"github.com/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type ReferrersOpts struct {
+	ArtifactType      string
+	Network           NetworkConfig
+	RegistryTransport RegistryTransport
+}
+
+// Referrers lists the manifests in the repository of ref whose subject is the
+// manifest with the given digest, along with a provider for their content.
+//
+// The registry's referrers API is used if it has one, falling back to the
+// referrers tag schema otherwise.
+func (r *Resolver) Referrers(ctx context.Context, ref string, dgst digest.Digest, opts ReferrersOpts) (_ []ocispecs.Descriptor, _ content.Provider, rerr error) {
+	span, ctx := tracing.StartSpan(ctx, "listing referrers of "+bkcache.DisplayRef(ref), telemetry.Encapsulated())
+	defer func() {
+		tracing.FinishWithError(span, rerr)
+	}()
+
+	parsedRef, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	repoRef, err := reference.WithDigest(parsedRef, dgst)
+	if err != nil {
+		return nil, nil, err
+	}
+	resolver := docker.NewResolver(docker.ResolverOptions{
+		Hosts: r.registryHosts(opts.Network, opts.RegistryTransport),
+	})
+	fetcher, err := resolver.Fetcher(ctx, repoRef.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	referrersFetcher, ok := fetcher.(remotes.ReferrersFetcher)
+	if !ok {
+		return nil, nil, fmt.Errorf("registry fetcher %T does not support referrers", fetcher)
+	}
+	var fetchOpts []remotes.FetchReferrersOpt
+	if opts.ArtifactType != "" {
+		fetchOpts = append(fetchOpts, remotes.WithReferrerArtifactTypes(opts.ArtifactType))
+	}
+	descs, err := referrersFetcher.FetchReferrers(ctx, dgst, fetchOpts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetch referrers of %s: %w", dgst, err)
+	}
+	return descs, contentutil.FromFetcher(fetcher), nil
+}
+
+// PushReferrer pushes a manifest with a subject to the repository of ref.
+//
+// Registries without a referrers API don't index manifests by subject, so the
+// referrers tag schema's index for the subject is updated as well. It's
+// updated even when the registry has a referrers API, as we can't tell from
+// the push response whether it does.
+func (r *Resolver) PushReferrer(ctx context.Context, img *PushedImage, ref string, opts PushOpts) (rerr error) {
+	span, ctx := tracing.StartSpan(ctx, "pushing referrer to "+bkcache.DisplayRef(ref), telemetry.Encapsulated(), telemetry.Encapsulate())
+	defer func() {
+		tracing.FinishWithError(span, rerr)
+	}()
+
+	manifestJSON, err := content.ReadBlob(ctx, img.Provider, img.RootDesc)
+	if err != nil {
+		return err
+	}
+	var manifest ocispecs.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return fmt.Errorf("decode referrer manifest: %w", err)
+	}
+	if manifest.Subject == nil {
+		return errors.New("referrer manifest has no subject")
+	}
+
+	parsedRef, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return err
+	}
+	if err := r.PushImage(ctx, img, parsedRef.Name(), PushOpts{
+		RegistryTransport: opts.RegistryTransport,
+		ByDigest:          true,
+		Network:           opts.Network,
+	}); err != nil {
+		return err
+	}
+
+	referrer := ocispecs.Descriptor{
+		MediaType:    img.RootDesc.MediaType,
+		ArtifactType: manifest.ArtifactType,
+		Digest:       img.RootDesc.Digest,
+		Size:         img.RootDesc.Size,
+		Annotations:  manifest.Annotations,
+	}
+	if referrer.ArtifactType == "" {
+		referrer.ArtifactType = manifest.Config.MediaType
+	}
+	return r.pushReferrersTag(ctx, parsedRef, manifest.Subject.Digest, referrer, opts)
+}
+
+// referrersTag returns the tag of the index listing the referrers of a
+// manifest on registries without a referrers API.
+//
+// See https://github.com/opencontainers/distribution-spec/blob/v1.1.0/spec.md#referrers-tag-schema
+func referrersTag(dgst digest.Digest) string {
+	return strings.Replace(dgst.String(), ":", "-", 1)
+}
+
+func (r *Resolver) pushReferrersTag(ctx context.Context, repo reference.Named, subject digest.Digest, referrer ocispecs.Descriptor, opts PushOpts) error {
+	tagged, err := reference.WithTag(repo, referrersTag(subject))
+	if err != nil {
+		return err
+	}
+	resolver := docker.NewResolver(docker.ResolverOptions{
+		Hosts: r.pushRegistryHosts(opts.RegistryTransport, opts.Network),
+	})
+
+	index := ocispecs.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispecs.MediaTypeImageIndex,
+	}
+	_, existingDesc, err := resolver.Resolve(ctx, tagged.String())
+	switch {
+	case err == nil:
+		fetcher, err := resolver.Fetcher(ctx, tagged.String())
+		if err != nil {
+			return err
+		}
+		existing, err := content.ReadBlob(ctx, contentutil.FromFetcher(fetcher), existingDesc)
+		if err != nil {
+			return fmt.Errorf("read referrers index %s: %w", tagged, err)
+		}
+		if err := json.Unmarshal(existing, &index); err != nil {
+			return fmt.Errorf("decode referrers index %s: %w", tagged, err)
+		}
+	case !errors.Is(err, cerrdefs.ErrNotFound):
+		return fmt.Errorf("resolve referrers index %s: %w", tagged, err)
+	}
+	if slices.ContainsFunc(index.Manifests, func(desc ocispecs.Descriptor) bool {
+		return desc.Digest == referrer.Digest
+	}) {
+		return nil
+	}
+	index.Manifests = append(index.Manifests, referrer)
+
+	indexJSON, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	indexDesc := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(indexJSON),
+		Size:      int64(len(indexJSON)),
+	}
+	buf := contentutil.NewBuffer()
+	if err := content.WriteBlob(ctx, buf, indexDesc.Digest.String(), bytes.NewReader(indexJSON), indexDesc); err != nil {
+		return err
+	}
+	pusher, err := resolver.Pusher(ctx, tagged.String())
+	if err != nil {
+		return err
+	}
+	if _, err := pushHandler(pusher, buf)(ctx, indexDesc); err != nil {
+		return fmt.Errorf("push referrers index %s: %w", tagged, err)
+	}
+	return nil
+}
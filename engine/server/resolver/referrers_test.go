@@ -0,0 +1,205 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	localcontentstore "github.com/containerd/containerd/v2/plugins/content/local"
+	"github.com/dagger/dagger/internal/buildkit/util/contentutil"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushReferrerWithoutReferrersAPI(t *testing.T) {
+	ctx := context.Background()
+	registry := newMemoryRegistry()
+	t.Cleanup(registry.Close)
+	registryHost := strings.TrimPrefix(registry.URL, "http://")
+
+	store, err := localcontentstore.NewLabeledStore(t.TempDir(), newTestContentLabelStore())
+	require.NoError(t, err)
+	rslvr := New(Opts{
+		Hosts: func(domain string) ([]docker.RegistryHost, error) {
+			return []docker.RegistryHost{
+				{
+					Client: registry.Client(),
+					Scheme: "http",
+					Host:   registryHost,
+					Path:   "/v2",
+					Capabilities: docker.HostCapabilityPull |
+						docker.HostCapabilityResolve |
+						docker.HostCapabilityPush |
+						docker.HostCapabilityReferrers,
+				},
+			}, nil
+		},
+		ContentStore: store,
+		LeaseManager: newTestLeaseManager(),
+	})
+	t.Cleanup(func() {
+		require.NoError(t, rslvr.Close())
+	})
+
+	ref := registryHost + "/dagger/test:latest"
+	subject := testDescriptor(ocispecs.MediaTypeImageManifest, []byte(`{"schemaVersion":2}`))
+	opts := PushOpts{RegistryTransport: RegistryTransport{Protocol: RegistryProtocolHTTP}}
+
+	var pushed []digest.Digest
+	for _, payload := range []string{"first", "second"} {
+		img := newTestReferrer(t, ctx, subject, []byte(payload))
+		require.NoError(t, rslvr.PushReferrer(ctx, img, ref, opts))
+		// pushing twice doesn't list it twice
+		require.NoError(t, rslvr.PushReferrer(ctx, img, ref, opts))
+		pushed = append(pushed, img.RootDesc.Digest)
+	}
+
+	descs, provider, err := rslvr.Referrers(ctx, ref, subject.Digest, ReferrersOpts{
+		ArtifactType:      "application/vnd.dagger.test",
+		RegistryTransport: RegistryTransport{Protocol: RegistryProtocolHTTP},
+	})
+	require.NoError(t, err)
+	require.Len(t, descs, 2)
+	for i, desc := range descs {
+		require.Equal(t, pushed[i], desc.Digest)
+		require.Equal(t, "application/vnd.dagger.test", desc.ArtifactType)
+		manifestJSON, err := content.ReadBlob(ctx, provider, desc)
+		require.NoError(t, err)
+		var manifest ocispecs.Manifest
+		require.NoError(t, json.Unmarshal(manifestJSON, &manifest))
+		require.Equal(t, subject.Digest, manifest.Subject.Digest)
+	}
+
+	descs, _, err = rslvr.Referrers(ctx, ref, digest.FromString("unsigned"), ReferrersOpts{
+		RegistryTransport: RegistryTransport{Protocol: RegistryProtocolHTTP},
+	})
+	require.NoError(t, err)
+	require.Empty(t, descs)
+}
+
+func newTestReferrer(t *testing.T, ctx context.Context, subject ocispecs.Descriptor, payload []byte) *PushedImage {
+	t.Helper()
+
+	buf := contentutil.NewBuffer()
+	layer := testDescriptor("application/vnd.dagger.test.layer", payload)
+	require.NoError(t, content.WriteBlob(ctx, buf, layer.Digest.String(), bytes.NewReader(payload), layer))
+	require.NoError(t, content.WriteBlob(ctx, buf, ocispecs.DescriptorEmptyJSON.Digest.String(), bytes.NewReader(ocispecs.DescriptorEmptyJSON.Data), ocispecs.DescriptorEmptyJSON))
+	manifestJSON, err := json.Marshal(ocispecs.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispecs.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.dagger.test",
+		Config:       ocispecs.DescriptorEmptyJSON,
+		Layers:       []ocispecs.Descriptor{layer},
+		Subject:      &subject,
+	})
+	require.NoError(t, err)
+	manifestDesc := testDescriptor(ocispecs.MediaTypeImageManifest, manifestJSON)
+	require.NoError(t, content.WriteBlob(ctx, buf, manifestDesc.Digest.String(), bytes.NewReader(manifestJSON), manifestDesc))
+	return &PushedImage{
+		RootDesc: manifestDesc,
+		Provider: buf,
+	}
+}
+
+// memoryRegistry is a minimal registry without a referrers API.
+type memoryRegistry struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+}
+
+func newMemoryRegistry() *memoryRegistry {
+	registry := &memoryRegistry{
+		blobs:     map[string][]byte{},
+		manifests: map[string][]byte{},
+	}
+	registry.Server = httptest.NewServer(http.HandlerFunc(registry.serveHTTP))
+	return registry
+}
+
+func (r *memoryRegistry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	switch {
+	case req.URL.Path == "/v2/":
+		w.Header().Set("Docker-Distribution-Api-Version", "registry/2.0")
+		w.WriteHeader(http.StatusOK)
+
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/blobs/uploads/"):
+		w.Header().Set("Location", req.URL.Path+"upload")
+		w.Header().Set("Range", "0-0")
+		w.WriteHeader(http.StatusAccepted)
+
+	case req.Method == http.MethodPut && strings.Contains(path, "/blobs/uploads/"):
+		payload, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.blobs[req.URL.Query().Get("digest")] = payload
+		w.WriteHeader(http.StatusCreated)
+
+	case strings.Contains(path, "/blobs/"):
+		payload, ok := r.blobs[path[strings.LastIndex(path, "/")+1:]]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		r.serveContent(w, req, "application/octet-stream", payload)
+
+	case req.Method == http.MethodPut && strings.Contains(path, "/manifests/"):
+		payload, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.manifests[path] = payload
+		name, _, _ := strings.Cut(path, "/manifests/")
+		r.manifests[name+"/manifests/"+digest.FromBytes(payload).String()] = payload
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(payload).String())
+		w.WriteHeader(http.StatusCreated)
+
+	case strings.Contains(path, "/manifests/"):
+		payload, ok := r.manifests[path]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		var manifest struct {
+			MediaType string `json:"mediaType"`
+		}
+		if err := json.Unmarshal(payload, &manifest); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		r.serveContent(w, req, manifest.MediaType, payload)
+
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func (r *memoryRegistry) serveContent(w http.ResponseWriter, req *http.Request, mediaType string, payload []byte) {
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Docker-Content-Digest", digest.FromBytes(payload).String())
+	w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+	w.WriteHeader(http.StatusOK)
+	if req.Method != http.MethodHead {
+		_, _ = w.Write(payload)
+	}
+}
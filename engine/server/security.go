@@ -0,0 +1,104 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dagger/dagger/engine/config"
+	"github.com/dagger/dagger/engine/engineutil"
+	resolverconfig "github.com/dagger/dagger/internal/buildkit/util/resolver/config"
+	"github.com/dagger/dagger/network"
+)
+
+// securityPolicy returns the policy for the security profiles of containers
+// set in the engine config.
+func securityPolicy(cfg *config.Security) (engineutil.SecurityPolicy, error) {
+	var policy engineutil.SecurityPolicy
+	if cfg == nil {
+		return policy, nil
+	}
+	if cfg.AllowedCapabilities != nil {
+		policy.AllowedCapabilities = []string{}
+		for _, capName := range cfg.AllowedCapabilities {
+			capName, err := engineutil.NormalizeCapability(capName)
+			if err != nil {
+				return policy, fmt.Errorf("allowed capabilities: %w", err)
+			}
+			policy.AllowedCapabilities = append(policy.AllowedCapabilities, capName)
+		}
+	}
+	if cfg.DefaultProfile == nil {
+		return policy, nil
+	}
+	profile := cfg.DefaultProfile
+	policy.Default.ReadOnlyRootfs = profile.ReadOnlyRootfs
+	policy.Default.NoNewPrivileges = profile.NoNewPrivileges
+	for _, capName := range profile.CapDrop {
+		capName, err := engineutil.NormalizeCapability(capName)
+		if err != nil {
+			return policy, fmt.Errorf("default profile: %w", err)
+		}
+		policy.Default.CapDrop = append(policy.Default.CapDrop, capName)
+	}
+	if profile.SeccompProfile != "" {
+		seccompProfile, err := os.ReadFile(profile.SeccompProfile)
+		if err != nil {
+			return policy, fmt.Errorf("default profile: read seccomp profile: %w", err)
+		}
+		if err := engineutil.ValidateSeccompProfile(string(seccompProfile)); err != nil {
+			return policy, fmt.Errorf("default profile: %s: %w", profile.SeccompProfile, err)
+		}
+		policy.Default.SeccompProfile = string(seccompProfile)
+	}
+	return policy, nil
+}
+
+// egressPolicy returns the policy for the egress of containers set in the
+// engine config.
+func egressPolicy(cfg *config.Security, registries map[string]resolverconfig.RegistryConfig) (engineutil.EgressPolicy, error) {
+	policy := engineutil.EgressPolicy{
+		RegistryDomains: slices.Clone(network.DefaultRegistryDomains),
+	}
+	for name, registry := range registries {
+		for _, ref := range append([]string{name}, registry.Mirrors...) {
+			if host := registryHost(ref); host != "" && !slices.Contains(policy.RegistryDomains, host) {
+				policy.RegistryDomains = append(policy.RegistryDomains, host)
+			}
+		}
+	}
+	slices.Sort(policy.RegistryDomains[len(network.DefaultRegistryDomains):])
+
+	if cfg == nil || cfg.Egress == nil {
+		return policy, nil
+	}
+	policy.Default = &network.EgressPolicy{
+		Allow:           cfg.Egress.Allow,
+		AllowRegistries: cfg.Egress.AllowRegistries,
+		Source:          "engine",
+	}
+	if err := policy.Default.Validate(); err != nil {
+		return policy, fmt.Errorf("egress: %w", err)
+	}
+	return policy, nil
+}
+
+// registryHost returns the host of a registry or mirror, which may be a URL
+// or include a port.
+func registryHost(ref string) string {
+	if strings.Contains(ref, "://") {
+		u, err := url.Parse(ref)
+		if err != nil {
+			return ""
+		}
+		return u.Hostname()
+	}
+	ref, _, _ = strings.Cut(ref, "/")
+	if host, _, err := net.SplitHostPort(ref); err == nil {
+		return host
+	}
+	return ref
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dagger/dagger/engine/config"
+	resolverconfig "github.com/dagger/dagger/internal/buildkit/util/resolver/config"
+	"github.com/dagger/dagger/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityPolicy(t *testing.T) {
+	t.Parallel()
+
+	seccompPath := filepath.Join(t.TempDir(), "seccomp.json")
+	require.NoError(t, os.WriteFile(seccompPath, []byte(`{"defaultAction":"SCMP_ACT_ERRNO"}`), 0o600))
+
+	policy, err := securityPolicy(&config.Security{
+		DefaultProfile: &config.SecurityProfile{
+			SeccompProfile:  seccompPath,
+			CapDrop:         []string{"net_raw"},
+			NoNewPrivileges: true,
+		},
+		AllowedCapabilities: []string{"NET_BIND_SERVICE"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `{"defaultAction":"SCMP_ACT_ERRNO"}`, policy.Default.SeccompProfile)
+	require.Equal(t, []string{"CAP_NET_RAW"}, policy.Default.CapDrop)
+	require.True(t, policy.Default.NoNewPrivileges)
+	require.Equal(t, []string{"CAP_NET_BIND_SERVICE"}, policy.AllowedCapabilities)
+
+	policy, err = securityPolicy(nil)
+	require.NoError(t, err)
+	require.True(t, policy.Default.IsZero())
+	require.Nil(t, policy.AllowedCapabilities)
+
+	_, err = securityPolicy(&config.Security{
+		DefaultProfile: &config.SecurityProfile{SeccompProfile: filepath.Join(t.TempDir(), "missing.json")},
+	})
+	require.ErrorContains(t, err, "read seccomp profile")
+}
+
+func TestEgressPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := egressPolicy(&config.Security{
+		Egress: &config.EgressPolicy{
+			Allow:           []string{"github.com"},
+			AllowRegistries: true,
+		},
+	}, map[string]resolverconfig.RegistryConfig{
+		"docker.io":                 {Mirrors: []string{"https://mirror.example.com:5000/v2"}},
+		"registry.example.com:5000": {},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &network.EgressPolicy{
+		Allow:           []string{"github.com"},
+		AllowRegistries: true,
+		Source:          "engine",
+	}, policy.Default)
+	require.Subset(t, policy.RegistryDomains, network.DefaultRegistryDomains)
+	require.Contains(t, policy.RegistryDomains, "docker.io")
+	require.Contains(t, policy.RegistryDomains, "mirror.example.com")
+	require.Contains(t, policy.RegistryDomains, "registry.example.com")
+
+	policy, err = egressPolicy(nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, policy.Default)
+	require.Equal(t, network.DefaultRegistryDomains, policy.RegistryDomains)
+
+	_, err = egressPolicy(&config.Security{
+		Egress: &config.EgressPolicy{Allow: []string{"not a domain"}},
+	}, nil)
+	require.ErrorContains(t, err, "egress: invalid egress destination")
+}
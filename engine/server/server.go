@@ -116,6 +116,8 @@ type Server struct {
 	apparmorProfile         string
 	selinux                 bool
 	entitlements            entitlements.Set
+	securityPolicy          engineutil.SecurityPolicy
+	egressPolicy            engineutil.EgressPolicy
 	enabledPlatforms        []ocispecs.Platform
 	defaultPlatform         ocispecs.Platform
 	registryHosts           docker.RegistryHosts
@@ -306,6 +308,12 @@ func NewServer(ctx context.Context, opts *NewServerOpts) (*Server, error) {
 		srv.entitlements[entitlements.EntitlementNetworkHost] = struct{}{}
 	}
 
+	srv.securityPolicy, err = securityPolicy(cfg.Security)
+	if err != nil {
+		return nil, fmt.Errorf("invalid security config: %w", err)
+	}
+	_, srv.securityPolicy.InsecureCapabilities = srv.entitlements[entitlements.EntitlementSecurityInsecure]
+
 	srv.defaultPlatform = platforms.Normalize(platforms.DefaultSpec())
 	if platformsStr := ociCfg.Platforms; len(platformsStr) != 0 {
 		var err error
@@ -332,6 +340,11 @@ func NewServer(ctx context.Context, opts *NewServerOpts) (*Server, error) {
 	}
 	srv.registryHosts = newRegistryHosts(registries)
 
+	srv.egressPolicy, err = egressPolicy(cfg.Security, registries)
+	if err != nil {
+		return nil, fmt.Errorf("invalid security config: %w", err)
+	}
+
 	srv.builtinContentStore, err = openBuiltinOCIStore()
 	if err != nil {
 		return nil, fmt.Errorf("failed to open builtin content store: %w", err)
@@ -435,6 +448,8 @@ func NewServer(ctx context.Context, opts *NewServerOpts) (*Server, error) {
 		ApparmorProfile:     srv.apparmorProfile,
 		SELinux:             srv.selinux,
 		Entitlements:        srv.entitlements,
+		SecurityPolicy:      srv.securityPolicy,
+		EgressPolicy:        srv.egressPolicy,
 
 		HostMntNS:  hostMntNS,
 		CleanMntNS: srv.cleanMntNS,
@@ -58,6 +58,7 @@ import (
 	"github.com/dagger/dagger/engine/slog"
 	enginetel "github.com/dagger/dagger/engine/telemetry"
 	"github.com/dagger/dagger/engine/wcprof"
+	"github.com/dagger/dagger/network"
 	"github.com/dagger/dagger/util/cleanups"
 )
 
@@ -135,6 +136,11 @@ type daggerSession struct {
 
 	lockFiles  map[workspaceLockKey]*workspaceLockState
 	lockFileMu sync.RWMutex
+
+	// egress policies of the modules loaded from workspace configs, by
+	// module name
+	moduleEgressPolicies   map[string]network.EgressPolicy
+	moduleEgressPoliciesMu sync.RWMutex
 }
 
 type workspaceLockKey struct {
@@ -1453,6 +1459,13 @@ func (srv *Server) ServeHTTPToNestedClient(
 		}
 		if typed.Self() != nil {
 			moduleContext = typed
+			// attribute what the module's functions produce to it in the cache
+			cacheMod, err := typed.Self().CacheModule()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			clientMetadata.CacheModule = cacheMod
 		}
 	}
 
@@ -1483,6 +1496,7 @@ func (srv *Server) ServeHTTPToNestedClient(
 func nestedClientMetadataForRequest(h http.Header, nestedClientMetadata *engine.ClientMetadata) *engine.ClientMetadata {
 	clientMetadata := *nestedClientMetadata
 	clientMetadata.AllowedLLMModules = slices.Clone(nestedClientMetadata.AllowedLLMModules)
+	clientMetadata.CacheTags = slices.Clone(nestedClientMetadata.CacheTags)
 	if clientMetadata.ClientVersion == "" {
 		clientMetadata.ClientVersion = engine.Version
 	}
@@ -2002,6 +2016,13 @@ func (srv *Server) serveShutdown(w http.ResponseWriter, r *http.Request, client
 			shutdownErr = errors.Join(shutdownErr, fmt.Errorf("flush workspace locks: %w", err))
 			slog.Error("failed to flush workspace locks", "error", err)
 		}
+		err = drainPhase("flush LLM cassettes", func() error {
+			return core.FlushLLMCassettes(context.WithoutCancel(ctx), client.clientID)
+		})
+		if err != nil {
+			shutdownErr = errors.Join(shutdownErr, err)
+			slog.Error("failed to flush LLM cassettes", "error", err)
+		}
 
 		// this must be done after lockfile flushing (since lockfiles make use of attachables to write data to host)
 		sess.beginClosing()
@@ -2030,6 +2051,13 @@ func (srv *Server) serveShutdown(w http.ResponseWriter, r *http.Request, client
 			shutdownErr = errors.Join(shutdownErr, fmt.Errorf("flush workspace locks: %w", err))
 			slog.Error("failed to flush workspace locks", "error", err)
 		}
+		err = drainPhase("flush LLM cassettes", func() error {
+			return core.FlushLLMCassettes(context.WithoutCancel(ctx), client.clientID)
+		})
+		if err != nil {
+			shutdownErr = errors.Join(shutdownErr, err)
+			slog.Error("failed to flush LLM cassettes", "error", err)
+		}
 	}
 
 	// Flush telemetry so nested spans land in the DBs the CLI drains. A client's
@@ -2595,6 +2623,32 @@ func (srv *Server) MainClientCallerMetadata(ctx context.Context) (*engine.Client
 	return srv.SpecificClientMetadata(ctx, client.daggerSession.mainClientCallerID)
 }
 
+// The egress policy set in the workspace config for the module of the given
+// name, or nil if none is.
+func (srv *Server) ModuleEgressPolicy(ctx context.Context, modName string) (*network.EgressPolicy, error) {
+	client, err := srv.clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sess := client.daggerSession
+	sess.moduleEgressPoliciesMu.RLock()
+	defer sess.moduleEgressPoliciesMu.RUnlock()
+	policy, ok := sess.moduleEgressPolicies[modName]
+	if !ok {
+		return nil, nil
+	}
+	return &policy, nil
+}
+
+func (sess *daggerSession) setModuleEgressPolicy(modName string, policy network.EgressPolicy) {
+	sess.moduleEgressPoliciesMu.Lock()
+	defer sess.moduleEgressPoliciesMu.Unlock()
+	if sess.moduleEgressPolicies == nil {
+		sess.moduleEgressPolicies = map[string]network.EgressPolicy{}
+	}
+	sess.moduleEgressPolicies[modName] = policy
+}
+
 // The Client metadata of a specific client ID within the same session as the
 // current client.
 func (srv *Server) SpecificClientMetadata(ctx context.Context, clientID string) (*engine.ClientMetadata, error) {
@@ -452,6 +452,9 @@ type pendingModule struct {
 	DefaultsFromDotEnv bool
 	ArgCustomizations  []*modules.ModuleConfigArgument
 
+	// Egress policy of the module set in the workspace config, if any.
+	Egress *workspace.ModuleEgress
+
 	// If set, load this module's implementation from Ref but resolve
 	// +defaultPath inputs from this source ref instead.
 	DefaultPathContextSourceRef string
@@ -553,6 +556,7 @@ func workspaceConfigPendingModules(
 			DisableFindUp:      true,
 			ConfigDefaults:     entry.Settings,
 			DefaultsFromDotEnv: cfg.DefaultsFromDotEnv,
+			Egress:             entry.Egress,
 			legacyFieldPolicy:  legacyWorkspaceFieldPolicyRejectAsWorkspace,
 		}
 
@@ -777,6 +781,7 @@ func (srv *Server) detectAndLoadWorkspaceWithRootfs(
 	if err := attachUserWorkspaceOverlay(ctx, clientMD, readFile, hostReadFile, ws, coreWS, remoteKey, isLocal); err != nil {
 		return err
 	}
+	coreWS.SetLLMToolPolicies(workspaceLLMToolPolicies(wsConfig))
 	client.workspace = coreWS
 
 	if !loadModules {
@@ -967,6 +972,23 @@ func attachUserWorkspaceOverlay(
 	return nil
 }
 
+// workspaceLLMToolPolicies returns the [[llm.tools]] policies of a workspace
+// config, which the engine enforces on every LLM of the session.
+func workspaceLLMToolPolicies(cfg *workspace.Config) []core.LLMToolPolicy {
+	if cfg == nil || cfg.LLM == nil {
+		return nil
+	}
+	policies := make([]core.LLMToolPolicy, 0, len(cfg.LLM.Tools))
+	for _, policy := range cfg.LLM.Tools {
+		policies = append(policies, core.LLMToolPolicy{
+			Tool:     policy.Tool,
+			ReadOnly: policy.ReadOnly,
+			Action:   policy.Action,
+		})
+	}
+	return policies
+}
+
 // localWorkspaceUserConfigKey derives the user-config key for a local
 // workspace from its git origin remote, resolved the way `git config --get`
 // would see it (include/includeIf directives followed). Best-effort: a
@@ -1790,6 +1812,10 @@ func (srv *Server) serveResolvedModuleLoadsLocked(client *daggerClient, loads []
 		if err := srv.serveModule(client, core.NewUserMod(load.primary), core.InstallOpts{Entrypoint: load.primaryEntrypoint}); err != nil {
 			return moduleLoadErr(loads[i], err)
 		}
+		if egress := loads[i].mod.Egress; egress != nil {
+			name := load.primary.Self().Name()
+			client.daggerSession.setModuleEgressPolicy(name, egress.Policy(name))
+		}
 		// For the entrypoint module (the one the user targets via dagger call),
 		// also serve its direct dependencies so the client schema can resolve
 		// concrete types behind interfaces. This mirrors the includeDependencies
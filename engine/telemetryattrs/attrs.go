@@ -10,6 +10,19 @@ const (
 	// on a successful run. (bool)
 	GenerateSkippedAttr = "dagger.io/generate.skipped"
 
+	// CheckAttemptsAttr is set on a check span alongside the check result: how
+	// many times the check ran, counting retries. (int64)
+	CheckAttemptsAttr = "dagger.io/check.attempts"
+
+	// CheckFlakyAttr marks a check span whose check failed at first but passed
+	// on a retry. The TUI and reports list these apart from failed checks, so
+	// they can be looked into without failing the run. (bool)
+	CheckFlakyAttr = "dagger.io/check.flaky"
+
+	// CheckAttemptAttr is set on each attempt span of a check that is retried:
+	// the attempt number, starting at 1. (int64)
+	CheckAttemptAttr = "dagger.io/check.attempt"
+
 	// DagBlockedAttr marks a lazy-evaluation resume span that aborted because a
 	// prerequisite result's evaluation failed, rather than because the result's
 	// own deferred work failed. The UI treats a blocked resumption as if the
@@ -5,10 +5,12 @@ import (
 	"io"
 	"net"
 	"syscall"
+	"time"
 
 	"github.com/containerd/containerd/v2/core/mount"
 	resourcestypes "github.com/dagger/dagger/internal/buildkit/executor/resources/types"
 	"github.com/dagger/dagger/internal/buildkit/solver/pb"
+	"github.com/dagger/dagger/network"
 )
 
 type Meta struct {
@@ -26,9 +28,72 @@ type Meta struct {
 	SecurityMode   pb.SecurityMode
 	ValidExitCodes []int
 
+	// Timeout bounds how long the process may run before it is killed. Zero
+	// means no timeout.
+	Timeout time.Duration
+	// ValidTimeout makes hitting Timeout a successful exit rather than an
+	// error.
+	ValidTimeout bool
+	// ResourceLimits caps the cgroup resources available to the container.
+	ResourceLimits ResourceLimits
+	// SecurityProfile restricts what processes in the container may do, on
+	// top of the default sandbox.
+	SecurityProfile SecurityProfile
+	// EgressPolicies restrict the destinations the container can connect to.
+	// A destination must be allowed by every policy.
+	EgressPolicies []network.EgressPolicy
+
 	RemoveMountStubsRecursive bool
 }
 
+// ResourceLimits caps the resources available to a container's cgroup. Zero
+// values leave the corresponding resource unlimited.
+type ResourceLimits struct {
+	// MilliCPU is the CPU time the container may use, in thousandths of a CPU.
+	MilliCPU int64 `json:"milliCPU,omitempty"`
+	// MemoryBytes is the maximum memory the container may use before
+	// processes in it are OOM killed.
+	MemoryBytes int64 `json:"memoryBytes,omitempty"`
+	// Pids is the maximum number of processes the container may run at once.
+	Pids int64 `json:"pids,omitempty"`
+}
+
+// IsZero reports whether no limit is set.
+func (l ResourceLimits) IsZero() bool {
+	return l == ResourceLimits{}
+}
+
+// SecurityProfile tightens the sandbox of a container. Zero values keep the
+// defaults.
+type SecurityProfile struct {
+	// SeccompProfile is a seccomp profile in the JSON format used by Docker,
+	// layered on top of the default one: syscalls are only allowed if both
+	// allow them.
+	SeccompProfile string `json:"seccompProfile,omitempty"`
+	// DefaultSeccompProfile replaces the default seccomp profile. It's set by
+	// the engine's security policy, not by containers.
+	DefaultSeccompProfile string `json:"defaultSeccompProfile,omitempty"`
+	// CapAdd and CapDrop are the capabilities added to and dropped from the
+	// default set, e.g. "CAP_NET_RAW". CapDrop may include "ALL".
+	CapAdd  []string `json:"capAdd,omitempty"`
+	CapDrop []string `json:"capDrop,omitempty"`
+	// ReadOnlyRootfs mounts the container's root filesystem read-only.
+	ReadOnlyRootfs bool `json:"readOnlyRootfs,omitempty"`
+	// NoNewPrivileges prevents processes from gaining privileges, e.g.
+	// through setuid binaries.
+	NoNewPrivileges bool `json:"noNewPrivileges,omitempty"`
+}
+
+// IsZero reports whether the profile keeps all the defaults.
+func (p SecurityProfile) IsZero() bool {
+	return p.SeccompProfile == "" &&
+		p.DefaultSeccompProfile == "" &&
+		len(p.CapAdd) == 0 &&
+		len(p.CapDrop) == 0 &&
+		!p.ReadOnlyRootfs &&
+		!p.NoNewPrivileges
+}
+
 type MountableRef interface {
 	Mount() ([]mount.Mount, func() error, error)
 }
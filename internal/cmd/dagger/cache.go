@@ -0,0 +1,162 @@
+package daggercmd
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/juju/ansiterm/tabwriter"
+	"github.com/spf13/cobra"
+
+	"dagger.io/dagger"
+	"github.com/dagger/dagger/engine/client"
+)
+
+var (
+	cachePruneModules   []string
+	cachePruneFields    []string
+	cachePruneTags      []string
+	cachePruneOlderThan time.Duration
+	cachePruneDryRun    bool
+	cachePruneJSON      bool
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the engine's cache",
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune [options]",
+	Short: "Prune the engine's cache",
+	Long: `Prune releasable entries from the engine's local cache.
+
+Filters narrow pruning to some of the entries: the results of one module's
+functions, of some fields, produced by sessions run with a --cache-tag, or not
+used for a while. Each filter can be repeated to match any of its values;
+entries must match all the given filters. Without filters, every releasable
+entry is pruned.
+
+Use --dry-run to list the entries that would be pruned, and the disk space
+pruning them would free.`,
+	Example: `dagger cache prune --module github.com/dagger/jest --dry-run
+dagger cache prune --field Container.from --older-than 168h
+dagger --cache-tag pr-1234 check
+dagger cache prune --tag pr-1234`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withEngine(cmd.Context(), client.Params{
+			SkipWorkspaceModules: true,
+		}, func(ctx context.Context, engineClient *client.Client) error {
+			pruned, err := pruneCache(ctx, engineClient.Dagger())
+			if err != nil {
+				return err
+			}
+			if cachePruneJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(pruned)
+			}
+			return pruned.write(cmd.OutOrStdout(), cachePruneDryRun)
+		})
+	},
+}
+
+//go:embed cache.graphql
+var pruneCacheQuery string
+
+type prunedCacheEntries struct {
+	EntryCount     int
+	DiskSpaceBytes int
+	Entries        []prunedCacheEntry
+}
+
+type prunedCacheEntry struct {
+	Description               string
+	DagqlCall                 string
+	Module                    string
+	Tags                      []string
+	DiskSpaceBytes            int
+	MostRecentUseTimeUnixNano int
+}
+
+func pruneCache(ctx context.Context, dag *dagger.Client) (*prunedCacheEntries, error) {
+	vars := map[string]any{
+		"dryRun": cachePruneDryRun,
+	}
+	for name, values := range map[string][]string{
+		"module": cachePruneModules,
+		"field":  cachePruneFields,
+		"tag":    cachePruneTags,
+	} {
+		if len(values) > 0 {
+			vars[name] = values
+		}
+	}
+	if cachePruneOlderThan > 0 {
+		vars["olderThan"] = cachePruneOlderThan.String()
+	}
+	var res struct {
+		Engine struct {
+			LocalCache struct {
+				PruneEntries prunedCacheEntries
+			}
+		}
+	}
+	err := dag.Do(ctx, &dagger.Request{
+		Query:     pruneCacheQuery,
+		OpName:    "PruneCache",
+		Variables: vars,
+	}, &dagger.Response{
+		Data: &res,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res.Engine.LocalCache.PruneEntries, nil
+}
+
+func (pruned *prunedCacheEntries) write(w io.Writer, dryRun bool) error {
+	if len(pruned.Entries) > 0 {
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', tabwriter.DiscardEmptyColumns)
+		fmt.Fprintln(tw, "SIZE\tLAST USED\tCALL\tMODULE\tTAGS")
+		for _, entry := range pruned.Entries {
+			call := entry.DagqlCall
+			if call == "" {
+				call = entry.Description
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+				units.HumanSize(float64(entry.DiskSpaceBytes)),
+				time.Unix(0, int64(entry.MostRecentUseTimeUnixNano)).Format(time.DateTime),
+				call,
+				entry.Module,
+				strings.Join(entry.Tags, ","))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	verb := "Pruned"
+	if dryRun {
+		verb = "Would prune"
+	}
+	_, err := fmt.Fprintf(w, "%s %d entries, freeing %s\n",
+		verb, pruned.EntryCount, units.HumanSize(float64(pruned.DiskSpaceBytes)))
+	return err
+}
+
+func init() {
+	flags := cachePruneCmd.Flags()
+	flags.StringArrayVar(&cachePruneModules, "module", nil, "Only prune the results of this module's functions and of the work they run, named by name, source ref or pinned commit")
+	flags.StringArrayVar(&cachePruneFields, "field", nil, "Only prune the results of this field, e.g. Container.from")
+	flags.StringArrayVar(&cachePruneTags, "tag", nil, "Only prune entries produced by sessions run with this --cache-tag")
+	flags.DurationVar(&cachePruneOlderThan, "older-than", 0, "Only prune entries not used for at least this long, e.g. 72h")
+	flags.BoolVar(&cachePruneDryRun, "dry-run", false, "List the entries that would be pruned, without pruning them")
+	flags.BoolVar(&cachePruneJSON, "json", false, "Output the pruned entries in JSON format")
+	cacheCmd.AddCommand(cachePruneCmd)
+}
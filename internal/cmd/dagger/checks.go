@@ -7,6 +7,7 @@ import (
 	"io"
 	"strings"
 
+	"github.com/juju/ansiterm/tabwriter"
 	"github.com/spf13/cobra"
 	"go.opentelemetry.io/otel/codes"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/dagger/dagger/dagql/idtui"
 	"github.com/dagger/dagger/engine/client"
 	"github.com/dagger/dagger/engine/slog"
+	"github.com/dagger/dagger/util/checkshard"
 	telemetry "github.com/dagger/otel-go"
 )
 
@@ -24,6 +26,12 @@ var (
 	checksNoGenerate   bool
 	checksOnlyGenerate bool
 	checksSkip         []string
+	checksReports      []string
+	checksWatch        bool
+	checksAffected     string
+	checksExplain      bool
+	checksShard        string
+	checksTimings      string
 )
 
 //go:embed checks.graphql
@@ -35,7 +43,18 @@ func init() {
 	checksCmd.Flags().BoolVar(&checksNoGenerate, "no-generate", false, "Only run annotated check functions, skip generate-as-checks")
 	checksCmd.Flags().BoolVar(&checksOnlyGenerate, "generate", false, "Only run generate-as-checks, skip annotated check functions")
 	checksCmd.Flags().StringArrayVar(&checksSkip, "skip", nil, "Skip checks matching the specified patterns")
+	checksCmd.Flags().StringArrayVar(&checksReports, "report", nil, "Write a report of the results to a file, as format=path. Formats: junit, sarif")
+	checksCmd.Flags().BoolVar(&checksWatch, "watch", false, "Run the checks again whenever their input files change")
+	checksCmd.Flags().StringVar(&checksAffected, "affected-since", "", "Only run the checks that files changed since the given git ref can affect")
+	checksCmd.Flags().BoolVar(&checksExplain, "explain", false, "With --affected-since, explain why each check runs or is skipped")
+	checksCmd.Flags().StringVar(&checksShard, "shard", "", "Only run one shard of the checks, as index/total, e.g. 2/5 for the second of five CI jobs")
+	checksCmd.Flags().StringVar(&checksTimings, "shard-timings", "", "Balance the shards by the check durations in this JSON file, and record the durations of this run in it")
 	checksCmd.MarkFlagsMutuallyExclusive("no-generate", "generate")
+	checksCmd.MarkFlagsMutuallyExclusive("watch", "list")
+	checksCmd.MarkFlagsMutuallyExclusive("watch", "report")
+	checksCmd.MarkFlagsMutuallyExclusive("watch", "affected-since")
+	checksCmd.MarkFlagsMutuallyExclusive("watch", "shard")
+	checksCmd.MarkFlagsMutuallyExclusive("watch", "shard-timings")
 }
 
 var checksCmd = &cobra.Command{
@@ -48,6 +67,10 @@ Examples:
   dagger check -l                 # List all available checks
   dagger check go:lint            # Run the go:lint check and any subchecks
   dagger check --skip '**e2e'     # Run all checks except those matching '**e2e'
+  dagger check --report junit=junit.xml --report sarif=lint.sarif  # Also write reports for CI
+  dagger check --watch go:lint    # Run go:lint again on every change to its inputs
+  dagger check --affected-since origin/main --explain  # Run only the checks the branch's changes can affect
+  dagger check --shard 2/5 --shard-timings .dagger/check-timings.json  # Run the second of five shards, balanced by duration
   dagger -W github.com/acme/ws check go:lint  # Run check(s) against explicit workspace
 `,
 	Args: cobra.ArbitraryArgs,
@@ -55,28 +78,94 @@ Examples:
 }
 
 func runChecksCommand(cmd *cobra.Command, args []string) error {
+	if checksExplain && checksAffected == "" {
+		return fmt.Errorf("--explain requires --affected-since")
+	}
+	reports, err := parseCheckReports(checksReports)
+	if err != nil {
+		return err
+	}
+	var shardIndex, shardTotal int
+	if checksShard != "" {
+		shardIndex, shardTotal, err = checkshard.ParseShard(checksShard)
+		if err != nil {
+			return err
+		}
+	}
+	var spans *dagui.DB
+	if len(reports) > 0 || checksTimings != "" {
+		// Collect the trace for the test cases the checks report, and for
+		// how long they took
+		spans = dagui.NewDB()
+		extraLiveTraceExporters = append(extraLiveTraceExporters, spans)
+	}
+
 	params := client.Params{
 		EnableCloudScaleOut:  enableScaleOut,
 		LoadWorkspaceModules: true,
 	}
-	return withEngine(
+	if checksWatch {
+		params.SyncWatcher = client.NewSyncWatcher()
+	}
+	checksOpts := dagger.WorkspaceChecksOpts{
+		Include:      args,
+		Skip:         checksSkip,
+		NoGenerate:   checksNoGenerate,
+		OnlyGenerate: checksOnlyGenerate,
+	}
+	var results []checkResult
+	err = withEngine(
 		cmd.Context(),
 		params,
 		func(ctx context.Context, engineClient *client.Client) error {
 			dag := engineClient.Dagger()
+			if checksWatch {
+				return watchWorkspace(ctx, dag, params.SyncWatcher, func(ctx context.Context, ws *dagger.Workspace) error {
+					_, err := runChecks(ctx, dag, ws.Checks(checksOpts), cmd, args)
+					return err
+				})
+			}
 			ws := dag.CurrentWorkspace()
-			checks := ws.Checks(dagger.WorkspaceChecksOpts{
-				Include:      args,
-				Skip:         checksSkip,
-				NoGenerate:   checksNoGenerate,
-				OnlyGenerate: checksOnlyGenerate,
-			})
+			checks := ws.Checks(checksOpts)
+			include := args
+			if checksAffected != "" {
+				var err error
+				checks, err = selectAffectedChecks(ctx, dag, ws, checks, checksAffected, args, cmd.OutOrStdout())
+				if err != nil {
+					return err
+				}
+				// the patterns have been checked against all the checks already
+				include = nil
+			}
+			if checksShard != "" {
+				var err error
+				checks, err = selectShard(ctx, dag, checks, shardIndex, shardTotal, checksTimings, include)
+				if err != nil {
+					return err
+				}
+				include = nil
+			}
 			if checksListMode {
 				return listChecks(ctx, dag, checks, cmd)
 			}
-			return runChecks(ctx, dag, checks, cmd, args)
+			var err error
+			results, err = runChecks(ctx, dag, checks, cmd, include)
+			return err
 		},
 	)
+	// Write the reports once the session is closed and its telemetry flushed,
+	// failed checks included.
+	if results != nil {
+		if reportErr := reports.write(results, spans); reportErr != nil {
+			return reportErr
+		}
+		if checksTimings != "" {
+			if timingsErr := updateCheckTimings(checksTimings, results, spans); timingsErr != nil {
+				return timingsErr
+			}
+		}
+	}
+	return err
 }
 
 // loadGroupListDetails fetches name+description for every item in a group
@@ -187,7 +276,7 @@ func writeCheckList(w io.Writer, checks []*CheckInfo) error {
 }
 
 // 'dagger checks' (runs by default)
-func runChecks(ctx context.Context, dag *dagger.Client, checkgroup *dagger.CheckGroup, _ *cobra.Command, include []string) error {
+func runChecks(ctx context.Context, dag *dagger.Client, checkgroup *dagger.CheckGroup, _ *cobra.Command, include []string) ([]checkResult, error) {
 	ctx, zoomSpan := Tracer().Start(ctx, "checks", telemetry.Passthrough())
 	defer zoomSpan.End()
 	Frontend.SetPrimary(dagui.SpanID{SpanID: zoomSpan.SpanContext().SpanID()})
@@ -197,15 +286,13 @@ func runChecks(ctx context.Context, dag *dagger.Client, checkgroup *dagger.Check
 	// FIXME: this feels a little weird. Can we move the relevant telemetry collection in the API?
 	id, err := checkgroup.ID(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var res struct {
 		CheckGroup struct {
 			Run struct {
-				List []struct {
-					Passed bool
-				}
+				List []checkResult
 			}
 		}
 	}
@@ -225,22 +312,154 @@ func runChecks(ctx context.Context, dag *dagger.Client, checkgroup *dagger.Check
 		Data: &res,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if err := validateCheckSelection(include, len(res.CheckGroup.Run.List)); err != nil {
-		return err
+	results := res.CheckGroup.Run.List
+	if err := validateCheckSelection(include, len(results)); err != nil {
+		return nil, err
 	}
 
 	var failed int
-	for _, check := range res.CheckGroup.Run.List {
-		if !check.Passed {
+	var flaky []string
+	for _, check := range results {
+		switch {
+		case !check.Passed:
 			failed++
+		case check.Flaky:
+			flaky = append(flaky, cliName(check.Name))
 		}
 	}
+	if len(flaky) > 0 {
+		// flaky checks don't fail the run, but shouldn't go unnoticed
+		slog.Warn("checks passed only on a retry", "checks", strings.Join(flaky, ", "))
+	}
 	if failed > 0 {
-		return idtui.ExitError{OriginalCode: 1, Original: fmt.Errorf("%d checks failed", failed)}
+		return results, idtui.ExitError{OriginalCode: 1, Original: fmt.Errorf("%d checks failed", failed)}
+	}
+	return results, nil
+}
+
+type affectedCheck struct {
+	Name          string
+	ChangedInputs []string
+	Inputs        []checkInput
+}
+
+type checkInput struct {
+	Path    string
+	Exclude []string
+	Reason  string
+}
+
+// selectAffectedChecks narrows checks down to those that the changes made to
+// the workspace since ref can affect, going by the inputs of each check. With
+// --explain, it writes why each check was selected or not to w.
+func selectAffectedChecks(
+	ctx context.Context,
+	dag *dagger.Client,
+	ws *dagger.Workspace,
+	checks *dagger.CheckGroup,
+	ref string,
+	include []string,
+	w io.Writer,
+) (_ *dagger.CheckGroup, rerr error) {
+	ctx, span := Tracer().Start(ctx, "select checks affected since "+ref)
+	defer telemetry.EndWithCause(span, &rerr)
+
+	wsID, err := ws.ID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var changes struct {
+		Workspace struct {
+			Git struct {
+				ChangesSince struct {
+					ID dagger.ID
+				}
+			}
+		}
+	}
+	err = dag.Do(ctx, &dagger.Request{
+		Query:  loadChecksQuery,
+		OpName: "WorkspaceChangesSince",
+		Variables: map[string]any{
+			"workspace": wsID,
+			"ref":       ref,
+		},
+	}, &dagger.Response{
+		Data: &changes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("changes since %s: %w", ref, err)
+	}
+
+	checksID, err := checks.ID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		CheckGroup struct {
+			AffectedBy struct {
+				ID dagger.ID
+			}
+			List []affectedCheck
+		}
+	}
+	err = dag.Do(ctx, &dagger.Request{
+		Query:  loadChecksQuery,
+		OpName: "CheckGroupAffectedBy",
+		Variables: map[string]any{
+			"checkGroup": checksID,
+			"changes":    changes.Workspace.Git.ChangesSince.ID,
+		},
+	}, &dagger.Response{
+		Data: &res,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCheckSelection(include, len(res.CheckGroup.List)); err != nil {
+		return nil, err
+	}
+	if checksExplain {
+		if err := writeAffectedChecks(w, res.CheckGroup.List); err != nil {
+			return nil, err
+		}
+	}
+	return dagger.Ref[*dagger.CheckGroup](dag, res.CheckGroup.AffectedBy.ID), nil
+}
+
+// writeAffectedChecks writes a table of whether each check is affected by the
+// changes, and why.
+func writeAffectedChecks(w io.Writer, checks []affectedCheck) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tRUN\tWHY")
+	for _, check := range checks {
+		run, why := "yes", ""
+		switch changed := check.ChangedInputs; len(changed) {
+		case 0:
+			run = "no"
+			inputs := make([]string, 0, len(check.Inputs))
+			for _, in := range check.Inputs {
+				input := in.Path
+				if len(in.Exclude) > 0 {
+					input += fmt.Sprintf(" excluding %s", strings.Join(in.Exclude, ", "))
+				}
+				inputs = append(inputs, fmt.Sprintf("%s (%s)", input, in.Reason))
+			}
+			if len(inputs) == 0 {
+				why = "reads nothing from the workspace"
+			} else {
+				why = "no changes to " + strings.Join(inputs, "; ")
+			}
+		case 1:
+			why = changed[0] + " changed"
+		default:
+			why = fmt.Sprintf("%s and %d more changed", changed[0], len(changed)-1)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", cliName(check.Name), run, why)
 	}
-	return nil
+	return tw.Flush()
 }
 
 func validateCheckSelection(include []string, selected int) error {
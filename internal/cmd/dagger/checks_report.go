@@ -0,0 +1,201 @@
+package daggercmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dagger/dagger/dagql/dagui"
+	"github.com/dagger/dagger/util/checkreport"
+)
+
+// checkResult is the outcome of a check, as returned by CheckGroup.run.
+type checkResult struct {
+	Name      string
+	Completed bool
+	Passed    bool
+	Attempts  int
+	Flaky     bool
+	Error     *struct {
+		Message string
+	}
+	Findings []checkreport.Finding
+}
+
+// checkReport is a report requested with --report format=path.
+type checkReport struct {
+	Format string
+	Path   string
+}
+
+type checkReports []checkReport
+
+var checkReportFormats = map[string]func([]checkreport.Check) ([]byte, error){
+	"junit": checkreport.JUnit,
+	"sarif": checkreport.SARIF,
+}
+
+func parseCheckReports(specs []string) (checkReports, error) {
+	reports := make(checkReports, 0, len(specs))
+	for _, spec := range specs {
+		format, path, ok := strings.Cut(spec, "=")
+		format = strings.ToLower(format)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("invalid report %q: expected format=path", spec)
+		}
+		if _, ok := checkReportFormats[format]; !ok {
+			return nil, fmt.Errorf("invalid report %q: unknown format %q (expected junit or sarif)", spec, format)
+		}
+		reports = append(reports, checkReport{Format: format, Path: path})
+	}
+	return reports, nil
+}
+
+// write renders the check results to each report's file. The test cases of
+// each check are read from the spans of its run.
+func (reports checkReports) write(results []checkResult, spans *dagui.DB) error {
+	if len(reports) == 0 {
+		return nil
+	}
+	checks := reportChecks(results, spans)
+	for _, report := range reports {
+		out, err := checkReportFormats[report.Format](checks)
+		if err != nil {
+			return err
+		}
+		if dir := filepath.Dir(report.Path); dir != "." {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return fmt.Errorf("write %s report: %w", report.Format, err)
+			}
+		}
+		if err := os.WriteFile(report.Path, out, 0o644); err != nil {
+			return fmt.Errorf("write %s report: %w", report.Format, err)
+		}
+	}
+	return nil
+}
+
+func reportChecks(results []checkResult, spans *dagui.DB) []checkreport.Check {
+	checkSpans := checkSpansByName(spans)
+	// the last attempt of each retried check, whose tests are the ones that
+	// count
+	lastAttempts := map[string]*dagui.Span{}
+	if spans != nil {
+		for span := range spans.Spans.Iter() {
+			if span.CheckAttempt > 0 && span.ParentSpan != nil && span.ParentSpan.CheckName != "" {
+				name := span.ParentSpan.CheckName
+				if prev, ok := lastAttempts[name]; !ok || span.CheckAttempt > prev.CheckAttempt {
+					lastAttempts[name] = span
+				}
+			}
+		}
+	}
+
+	checks := make([]checkreport.Check, 0, len(results))
+	for _, result := range results {
+		check := checkreport.Check{
+			Name:      cliName(result.Name),
+			Completed: result.Completed,
+			Passed:    result.Passed,
+			Flaky:     result.Flaky,
+			Attempts:  result.Attempts,
+			Findings:  result.Findings,
+		}
+		if result.Error != nil {
+			check.Error = result.Error.Message
+		}
+		for i, finding := range check.Findings {
+			check.Findings[i].Level = strings.ToLower(finding.Level)
+		}
+		if span := checkSpans[result.Name]; span != nil {
+			check.Duration = checkSpanDuration(span)
+			testSpan := span
+			if attempt := lastAttempts[result.Name]; attempt != nil {
+				testSpan = attempt
+			}
+			check.Tests = reportTests(spans.TestViewForSpan(testSpan))
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkSpansByName returns the span of each check that ran, by check name.
+func checkSpansByName(spans *dagui.DB) map[string]*dagui.Span {
+	checkSpans := map[string]*dagui.Span{}
+	if spans == nil {
+		return checkSpans
+	}
+	for span := range spans.Spans.Iter() {
+		if span.CheckName == "" {
+			continue
+		}
+		if prev, ok := checkSpans[span.CheckName]; !ok || span.StartTime.Before(prev.StartTime) {
+			checkSpans[span.CheckName] = span
+		}
+	}
+	return checkSpans
+}
+
+// checkSpanDuration returns how long a check took, or zero if its span
+// hasn't ended.
+func checkSpanDuration(span *dagui.Span) time.Duration {
+	if span.EndTime.IsZero() {
+		return 0
+	}
+	return span.EndTime.Sub(span.StartTime)
+}
+
+// reportTests flattens a check's test view into its leaf test cases, each
+// attributed to the suite it ran in.
+func reportTests(view *dagui.TestView) []checkreport.Test {
+	if !view.HasTests() {
+		return nil
+	}
+	var tests []checkreport.Test
+	var walk func(node *dagui.TestNode, suite string)
+	walk = func(node *dagui.TestNode, suite string) {
+		if node.Kind != dagui.TestNodeCase {
+			suite = testNodeName(node)
+		} else if !hasTestCaseChildren(node) && node.Span != nil {
+			test := checkreport.Test{
+				Suite:   suite,
+				Name:    testNodeName(node),
+				Failed:  node.SelfCategory == dagui.TestCategoryFailing,
+				Skipped: node.SelfCategory == dagui.TestCategorySkipped,
+			}
+			if !node.Span.EndTime.IsZero() {
+				test.Duration = node.Span.EndTime.Sub(node.Span.StartTime)
+			}
+			if test.Failed {
+				test.Message = node.Span.Status.Description
+			}
+			tests = append(tests, test)
+		}
+		for _, child := range node.Children {
+			walk(child, suite)
+		}
+	}
+	for _, root := range view.Roots {
+		walk(root, "")
+	}
+	return tests
+}
+
+func testNodeName(node *dagui.TestNode) string {
+	if node.FullName != "" {
+		return node.FullName
+	}
+	return node.Name
+}
+
+func hasTestCaseChildren(node *dagui.TestNode) bool {
+	for _, child := range node.Children {
+		if child.Kind == dagui.TestNodeCase {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package daggercmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dagger/dagger/dagql/dagui"
+	"github.com/dagger/dagger/util/checkreport"
+)
+
+func TestParseCheckReports(t *testing.T) {
+	reports, err := parseCheckReports([]string{"junit=out/junit.xml", "SARIF=lint.sarif"})
+	require.NoError(t, err)
+	require.Equal(t, checkReports{
+		{Format: "junit", Path: "out/junit.xml"},
+		{Format: "sarif", Path: "lint.sarif"},
+	}, reports)
+
+	_, err = parseCheckReports([]string{"junit"})
+	require.ErrorContains(t, err, "expected format=path")
+	_, err = parseCheckReports([]string{"html=report.html"})
+	require.ErrorContains(t, err, `unknown format "html"`)
+}
+
+func TestReportChecks(t *testing.T) {
+	span := func(id byte, name string, parent byte, mod func(*dagui.SpanSnapshot)) dagui.SpanSnapshot {
+		start := time.Unix(int64(id), 0)
+		snapshot := dagui.SpanSnapshot{
+			ID:        dagui.SpanID{SpanID: trace.SpanID{id}},
+			TraceID:   dagui.TraceID{TraceID: trace.TraceID{1}},
+			Name:      name,
+			StartTime: start,
+			EndTime:   start.Add(time.Second),
+			Status:    sdktrace.Status{Code: codes.Ok},
+		}
+		if parent != 0 {
+			snapshot.ParentID = dagui.SpanID{SpanID: trace.SpanID{parent}}
+		}
+		if mod != nil {
+			mod(&snapshot)
+		}
+		return snapshot
+	}
+	db := dagui.NewDB()
+	db.ImportSnapshots([]dagui.SpanSnapshot{
+		span(1, "dagger check", 0, nil),
+		span(2, "test", 1, func(s *dagui.SpanSnapshot) {
+			s.CheckName = "test"
+			s.Status = sdktrace.Status{Code: codes.Error, Description: "1 test failed"}
+		}),
+		span(3, "pkg", 2, func(s *dagui.SpanSnapshot) {
+			s.TestSuiteName = "pkg"
+		}),
+		span(4, "TestOK", 3, func(s *dagui.SpanSnapshot) {
+			s.TestCaseName = "TestOK"
+			s.TestStatus = dagui.TestStatusSuccess
+		}),
+		span(5, "TestBad", 3, func(s *dagui.SpanSnapshot) {
+			s.TestCaseName = "TestBad"
+			s.TestStatus = dagui.TestStatusFailure
+			s.Status = sdktrace.Status{Code: codes.Error, Description: "want 1, got 2"}
+		}),
+		span(6, "lint", 1, func(s *dagui.SpanSnapshot) {
+			s.CheckName = "lint"
+		}),
+		// a check that failed its first attempt and passed its second
+		span(7, "integration", 1, func(s *dagui.SpanSnapshot) {
+			s.CheckName = "integration"
+			s.CheckFlaky = true
+			s.CheckAttempts = 2
+		}),
+		span(8, "attempt 1 of 2", 7, func(s *dagui.SpanSnapshot) {
+			s.CheckAttempt = 1
+			s.Status = sdktrace.Status{Code: codes.Error, Description: "1 test failed"}
+		}),
+		span(9, "TestRetry", 8, func(s *dagui.SpanSnapshot) {
+			s.TestCaseName = "TestRetry"
+			s.TestStatus = dagui.TestStatusFailure
+			s.Status = sdktrace.Status{Code: codes.Error, Description: "timeout"}
+		}),
+		span(10, "attempt 2 of 2", 7, func(s *dagui.SpanSnapshot) {
+			s.CheckAttempt = 2
+		}),
+		span(11, "TestRetry", 10, func(s *dagui.SpanSnapshot) {
+			s.TestCaseName = "TestRetry"
+			s.TestStatus = dagui.TestStatusSuccess
+		}),
+	})
+
+	checks := reportChecks([]checkResult{
+		{Name: "test", Completed: true, Error: &struct{ Message string }{"1 test failed"}},
+		{Name: "lint", Completed: true, Passed: true, Findings: []checkreport.Finding{
+			{Message: "unused variable", Level: "WARNING", Path: "main.go", Line: 3},
+		}},
+		{Name: "integration", Completed: true, Passed: true, Attempts: 2, Flaky: true},
+	}, db)
+	require.Len(t, checks, 3)
+
+	test := checks[0]
+	require.Equal(t, "1 test failed", test.Error)
+	require.Equal(t, time.Second, test.Duration)
+	require.Equal(t, []checkreport.Test{
+		{Suite: "pkg", Name: "TestBad", Duration: time.Second, Failed: true, Message: "want 1, got 2"},
+		{Suite: "pkg", Name: "TestOK", Duration: time.Second},
+	}, test.Tests)
+
+	lint := checks[1]
+	require.Empty(t, lint.Tests)
+	require.Equal(t, checkreport.LevelWarning, lint.Findings[0].Level)
+
+	// only the tests of the last attempt are reported
+	integration := checks[2]
+	require.True(t, integration.Flaky)
+	require.Equal(t, 2, integration.Attempts)
+	require.Equal(t, []checkreport.Test{
+		{Name: "TestRetry", Duration: time.Second},
+	}, integration.Tests)
+
+	dir := t.TempDir()
+	reports := checkReports{{Format: "junit", Path: filepath.Join(dir, "reports", "junit.xml")}}
+	require.NoError(t, reports.write([]checkResult{{Name: "lint", Completed: true, Passed: true}}, nil))
+	junit, err := os.ReadFile(reports[0].Path)
+	require.NoError(t, err)
+	require.Contains(t, string(junit), `<testcase name="lint" classname="lint"`)
+}
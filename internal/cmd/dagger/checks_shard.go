@@ -0,0 +1,125 @@
+package daggercmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"dagger.io/dagger"
+	"github.com/dagger/dagger/dagql/dagui"
+	"github.com/dagger/dagger/engine/slog"
+	"github.com/dagger/dagger/util/checkshard"
+	telemetry "github.com/dagger/otel-go"
+)
+
+// selectShard narrows checks down to shard index of total. The shards are
+// balanced by the durations in the timing file at timingsPath if there is
+// one, or else split by count. The include patterns are checked against all
+// the checks, since a shard may well get none of those they match.
+func selectShard(
+	ctx context.Context,
+	dag *dagger.Client,
+	checks *dagger.CheckGroup,
+	index, total int,
+	timingsPath string,
+	include []string,
+) (_ *dagger.CheckGroup, rerr error) {
+	ctx, span := Tracer().Start(ctx, fmt.Sprintf("select checks in shard %d/%d", index, total))
+	defer telemetry.EndWithCause(span, &rerr)
+
+	checksID, err := checks.ID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vars := map[string]any{
+		"checkGroup": checksID,
+		"index":      index,
+		"total":      total,
+	}
+	if timingsPath != "" {
+		switch _, err := os.Stat(timingsPath); {
+		case err == nil:
+			path, err := filepath.Abs(timingsPath)
+			if err != nil {
+				return nil, err
+			}
+			timingsID, err := dag.Host().File(path, dagger.HostFileOpts{NoCache: true}).ID(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("load check timings: %w", err)
+			}
+			vars["timings"] = timingsID
+		case errors.Is(err, os.ErrNotExist):
+			slog.Info("no check timings yet, splitting the checks by count", "path", timingsPath)
+		default:
+			return nil, fmt.Errorf("load check timings: %w", err)
+		}
+	}
+
+	var res struct {
+		CheckGroup struct {
+			Shard struct {
+				ID dagger.ID
+			}
+			List []struct {
+				Name string
+			}
+		}
+	}
+	err = dag.Do(ctx, &dagger.Request{
+		Query:     loadChecksQuery,
+		OpName:    "CheckGroupShard",
+		Variables: vars,
+	}, &dagger.Response{
+		Data: &res,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCheckSelection(include, len(res.CheckGroup.List)); err != nil {
+		return nil, err
+	}
+	return dagger.Ref[*dagger.CheckGroup](dag, res.CheckGroup.Shard.ID), nil
+}
+
+// updateCheckTimings records how long each check that completed took in the
+// timing file at path. The timings of the other checks are kept, as they may
+// have run in another shard.
+func updateCheckTimings(path string, results []checkResult, spans *dagui.DB) error {
+	timings := checkshard.Timings{}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		timings, err = checkshard.ParseTimings(data)
+		if err != nil {
+			return fmt.Errorf("update %s: %w", path, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("update check timings: %w", err)
+	}
+
+	checkSpans := checkSpansByName(spans)
+	for _, result := range results {
+		if !result.Completed {
+			continue
+		}
+		if span := checkSpans[result.Name]; span != nil && !span.EndTime.IsZero() {
+			timings[result.Name] = checkSpanDuration(span)
+		}
+	}
+
+	out, err := timings.Marshal()
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("update check timings: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		return fmt.Errorf("update check timings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package daggercmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dagger/dagger/dagql/dagui"
+	"github.com/dagger/dagger/util/checkshard"
+)
+
+func TestUpdateCheckTimings(t *testing.T) {
+	span := func(id byte, checkName string, took time.Duration) dagui.SpanSnapshot {
+		start := time.Unix(int64(id), 0)
+		return dagui.SpanSnapshot{
+			ID:        dagui.SpanID{SpanID: trace.SpanID{id}},
+			TraceID:   dagui.TraceID{TraceID: trace.TraceID{1}},
+			Name:      checkName,
+			StartTime: start,
+			EndTime:   start.Add(took),
+			CheckName: checkName,
+		}
+	}
+	db := dagui.NewDB()
+	db.ImportSnapshots([]dagui.SpanSnapshot{
+		span(1, "go:lint", 3*time.Second),
+		span(2, "go:test", 2*time.Minute),
+		span(3, "e2e", time.Minute),
+	})
+
+	path := filepath.Join(t.TempDir(), "ci", "timings.json")
+	initial, err := checkshard.Timings{
+		"go:test": time.Minute,
+		// ran in another shard
+		"docs:build": 30 * time.Second,
+	}.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, initial, 0o644))
+
+	require.NoError(t, updateCheckTimings(path, []checkResult{
+		{Name: "go:lint", Completed: true, Passed: true},
+		{Name: "go:test", Completed: true},
+		// cancelled by --failfast: how long it took says nothing
+		{Name: "e2e"},
+	}, db))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	timings, err := checkshard.ParseTimings(data)
+	require.NoError(t, err)
+	require.Equal(t, checkshard.Timings{
+		"go:lint":    3 * time.Second,
+		"go:test":    2 * time.Minute,
+		"docs:build": 30 * time.Second,
+	}, timings)
+
+	// the first run creates the file
+	path = filepath.Join(t.TempDir(), "timings.json")
+	require.NoError(t, updateCheckTimings(path, []checkResult{
+		{Name: "e2e", Completed: true, Passed: true},
+	}, db))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"checks": {"e2e": 60}}`, string(data))
+}
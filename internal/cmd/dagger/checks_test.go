@@ -76,3 +76,31 @@ func TestValidateCheckSelection(t *testing.T) {
 		)
 	})
 }
+
+func TestWriteAffectedChecks(t *testing.T) {
+	var out bytes.Buffer
+	err := writeAffectedChecks(&out, []affectedCheck{
+		{
+			Name:          "go:lint",
+			ChangedInputs: []string{"go/main.go", "go/util.go"},
+		},
+		{
+			Name:          "docs:build",
+			ChangedInputs: []string{"docs/index.md"},
+		},
+		{
+			Name: "site:test",
+			Inputs: []checkInput{
+				{Path: "/site", Reason: "source of module site"},
+				{Path: "/web", Exclude: []string{"node_modules"}, Reason: `default path of argument "source" of site:test`},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	text := out.String()
+	require.Regexp(t, `(?m)^CHECK\s+RUN\s+WHY$`, text)
+	require.Regexp(t, `(?m)^go:lint\s+yes\s+go/main.go and 1 more changed$`, text)
+	require.Regexp(t, `(?m)^docs:build\s+yes\s+docs/index.md changed$`, text)
+	require.Contains(t, text, `no changes to /site (source of module site); /web excluding node_modules (default path of argument "source" of site:test)`)
+}
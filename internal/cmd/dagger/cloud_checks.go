@@ -401,6 +401,9 @@ func cloudResultForStatus(status string) string {
 	switch strings.ToLower(status) {
 	case "success", "succeeded", "passed", "ok":
 		return "green"
+	case "flaky":
+		// passed, but only on a retry
+		return "flaky"
 	case "failure", "failed", "error", "errored", "cancelled", "canceled":
 		return "red"
 	default:
@@ -418,8 +421,10 @@ func stricterCloudResult(a, b string) string {
 func cloudResultRank(result string) int {
 	switch result {
 	case "red":
-		return 3
+		return 4
 	case "pending":
+		return 3
+	case "flaky":
 		return 2
 	case "green":
 		return 1
@@ -450,7 +455,7 @@ func cloudChecksSummary(rows []cloudCheckRow) string {
 	passed := 0
 	for _, checkResult := range byCheck {
 		result = stricterCloudResult(result, checkResult)
-		if checkResult == "green" {
+		if checkResult == "green" || checkResult == "flaky" {
 			passed++
 		}
 	}
@@ -475,6 +480,7 @@ func cloudChecksEmojiSummary(rows []cloudCheckRow) string {
 	resultCounts := []resultCount{
 		{result: "red", count: counts["red"]},
 		{result: "pending", count: counts["pending"]},
+		{result: "flaky", count: counts["flaky"]},
 		{result: "green", count: counts["green"]},
 	}
 	sort.SliceStable(resultCounts, func(i, j int) bool {
@@ -515,6 +521,8 @@ func cloudResultEmoji(result string) string {
 		return "🔴"
 	case "pending":
 		return "🟡"
+	case "flaky":
+		return "🟠"
 	case "green":
 		return "🟢"
 	default:
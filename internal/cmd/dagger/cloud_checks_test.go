@@ -87,8 +87,9 @@ func TestCloudChecksEmojiSummary(t *testing.T) {
 		{Dimensions: map[string]string{"check": "unit"}, Result: "red"},
 		{Dimensions: map[string]string{"check": "docs"}, Result: "pending"},
 		{Dimensions: map[string]string{"check": "deploy"}, Result: "pending"},
+		{Dimensions: map[string]string{"check": "e2e"}, Result: "flaky"},
 	}
-	require.Equal(t, "🟡2 🔴1 🟢1", cloudChecksEmojiSummary(rows))
+	require.Equal(t, "🟡2 🔴1 🟠1 🟢1", cloudChecksEmojiSummary(rows))
 }
 
 func TestWorkspaceActivityRowsIncludePRMetadata(t *testing.T) {
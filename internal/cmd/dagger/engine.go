@@ -170,6 +170,7 @@ func finalizeEngineParams(ctx context.Context, params client.Params) (client.Par
 	}
 
 	params.AllowedLLMModules = allowedLLMModules
+	params.CacheTags = cacheTags
 
 	params.Profile = profileFlag
 
@@ -308,6 +309,11 @@ func engineTelemetryConfig(ctx context.Context) telemetry.Config {
 	return engineTelemetryConfigWithCloud(ctx, enginetel.ConfiguredCloudExporters)
 }
 
+// extraLiveTraceExporters receive the spans of the command's engine sessions,
+// alongside the frontend: e.g. to write reports from the trace once the command
+// completes.
+var extraLiveTraceExporters []sdktrace.SpanExporter
+
 type configuredCloudExportersFunc func(context.Context) (sdktrace.SpanExporter, sdklog.Exporter, sdkmetric.Exporter, bool)
 
 func engineTelemetryConfigWithCloud(ctx context.Context, configuredCloudExporters configuredCloudExportersFunc) telemetry.Config {
@@ -320,6 +326,7 @@ func engineTelemetryConfigWithCloud(ctx context.Context, configuredCloudExporter
 		cfg.LiveLogExporters = append(cfg.LiveLogExporters, Frontend.LogExporter())
 		cfg.LiveMetricExporters = append(cfg.LiveMetricExporters, Frontend.MetricExporter())
 	}
+	cfg.LiveTraceExporters = append(cfg.LiveTraceExporters, extraLiveTraceExporters...)
 	if !skipSharedTelemetryExporters {
 		if spans, logs, metrics, ok := configuredCloudExporters(ctx); ok {
 			// Wrap the Cloud span exporter in a LARGE-queue live processor instead of
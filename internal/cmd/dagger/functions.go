@@ -1119,6 +1119,7 @@ func startInteractivePromptModeWithResume(ctx context.Context, dag *dagger.Clien
 	// Remember the composed agent group as the base to reset to on .clear, so
 	// clearing history returns to the initially selected agents rather than a
 	// blank LLM.
+	llm = handler.llmSession.withLLMConfig(llm)
 	handler.llmSession.initialLLM = llm
 	if err := handler.llmSession.updateLLM(llm); err != nil {
 		return err
@@ -20,6 +20,7 @@ var (
 	generateListMode    bool
 	generateRequireLoad bool
 	generateNoApply     bool
+	generateWatch       bool
 )
 
 //go:embed generators.graphql
@@ -29,6 +30,8 @@ func init() {
 	generateCmd.Flags().BoolVarP(&generateListMode, "list", "l", false, "List available generators")
 	generateCmd.Flags().BoolVar(&generateRequireLoad, "require-load", false, "Fail if any workspace module cannot be loaded (default: report as a warning and generate the rest)")
 	generateCmd.Flags().BoolVar(&generateNoApply, "no-apply", false, "Compute and show a summary of generated changes without applying them")
+	generateCmd.Flags().BoolVar(&generateWatch, "watch", false, "Generate again whenever the generators' input files change")
+	generateCmd.MarkFlagsMutuallyExclusive("watch", "list")
 }
 
 var generateCmd = &cobra.Command{
@@ -41,6 +44,7 @@ Examples:
   dagger generate -l                         # List all available generators
   dagger generate --no-apply                 # Show generated changes without applying them
   dagger generate go:bin                     # Generate by selecting the generator function
+  dagger generate -y --watch                 # Generate and apply again on every change
   dagger -W github.com/acme/ws generate go:bin  # Generate against explicit workspace
 `,
 	Args: cobra.ArbitraryArgs,
@@ -53,18 +57,21 @@ Examples:
 		params := client.Params{
 			LoadWorkspaceModules: true,
 		}
+		if generateWatch {
+			params.SyncWatcher = client.NewSyncWatcher()
+		}
+		generatorsOf := func(ws *dagger.Workspace) *dagger.GeneratorGroup {
+			if len(args) > 0 {
+				return ws.Generators(dagger.WorkspaceGeneratorsOpts{Include: args})
+			}
+			return ws.Generators()
+		}
 		return withEngine(
 			cmd.Context(),
 			params,
 			func(ctx context.Context, engineClient *client.Client) error {
 				dag := engineClient.Dagger()
-				ws := dag.CurrentWorkspace()
-				var generators *dagger.GeneratorGroup
-				if len(args) > 0 {
-					generators = ws.Generators(dagger.WorkspaceGeneratorsOpts{Include: args})
-				} else {
-					generators = ws.Generators()
-				}
+				generators := generatorsOf(dag.CurrentWorkspace())
 				// Loading is best-effort: a module that fails to load is skipped
 				// and surfaced in telemetry (engine-side, rendered like a check
 				// that did not pass) while the rest still generate. --require-load
@@ -82,6 +89,13 @@ Examples:
 				if generateListMode {
 					return listGenerators(ctx, dag, generators, cmd)
 				}
+				if generateWatch {
+					// Applied changes are themselves picked up as a change, but
+					// generating again from them yields no further changes.
+					return watchWorkspace(ctx, dag, params.SyncWatcher, func(ctx context.Context, ws *dagger.Workspace) error {
+						return runGenerators(ctx, dag, generatorsOf(ws), cmd, disposition)
+					})
+				}
 				return runGenerators(ctx, dag, generators, cmd, disposition)
 			},
 		)
@@ -19,6 +19,7 @@ import (
 
 	"dagger.io/dagger"
 	"github.com/dagger/dagger/core/modelcatalog"
+	"github.com/dagger/dagger/core/workspace"
 	"github.com/dagger/dagger/dagql/idtui"
 	"github.com/dagger/dagger/engine/slog"
 	"github.com/dagger/dagger/internal/cmd/dagger/llmconfig"
@@ -83,6 +84,11 @@ type LLMSession struct {
 	// .clear resets to a plain workspace-bound LLM.
 	initialLLM *dagger.LLM
 
+	// llmConfig is the workspace's [llm] table, whose budget and tool
+	// policies apply to every conversation of the session. Nil when the
+	// workspace sets none.
+	llmConfig *workspace.LLMConfig
+
 	// subscriptionLabelCache caches the OAuth subscription label for the status
 	// line, resolved lazily on first use.
 	subscriptionLabelCache string
@@ -146,6 +152,12 @@ func NewLLMSession(
 		sink.SetLLMCostFunc(modelcatalog.Cost)
 	}
 
+	llmConfig, err := workspaceLLMConfig(ctx, dag)
+	if err != nil {
+		return nil, err
+	}
+	s.llmConfig = llmConfig
+
 	s.reset()
 
 	// Grab the model to check for a valid config
@@ -189,12 +201,42 @@ func (s *LLMSession) reset() {
 			llm = llm.WithModel(s.model)
 		}
 	} else {
-		llm = s.dag.LLM(dagger.LLMOpts{Model: s.model}).
-			WithWorkspace(s.dag.CurrentWorkspace())
+		llm = s.withLLMConfig(s.dag.LLM(dagger.LLMOpts{Model: s.model}).
+			WithWorkspace(s.dag.CurrentWorkspace()))
 	}
 	s.updateLLM(llm)
 }
 
+// withLLMConfig applies the workspace's budget to a conversation, if any. Its
+// tool policies are enforced by the engine, on every LLM of the session.
+func (s *LLMSession) withLLMConfig(llm *dagger.LLM) *dagger.LLM {
+	if s.llmConfig == nil {
+		return llm
+	}
+	if budget := s.llmConfig.Budget; budget != nil {
+		llm = llm.WithBudget(dagger.LLMWithBudgetOpts{
+			MaxInputTokens:  int(budget.MaxInputTokens),
+			MaxOutputTokens: int(budget.MaxOutputTokens),
+			MaxCostUSD:      budget.MaxCostUSD,
+		})
+	}
+	return llm
+}
+
+// workspaceLLMConfig reads the [llm] table of the current workspace's
+// dagger.toml.
+func workspaceLLMConfig(ctx context.Context, dag *dagger.Client) (*workspace.LLMConfig, error) {
+	data, err := dag.CurrentWorkspace().ConfigRead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read LLM config: %w", err)
+	}
+	cfg, err := workspace.ParseConfig([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("read LLM config: %w", err)
+	}
+	return cfg.LLM, nil
+}
+
 func (s *LLMSession) Fork() *LLMSession {
 	// FIXME: this was a half-baked feature, currently does more harm than good
 	// because we lose partial progress on interrupt
@@ -871,7 +913,7 @@ func (s *LLMSession) LoadSession(ctx, replayCtx context.Context, sessionID strin
 	}
 
 	// updateLLM refreshes the status line from the restored conversation's stats.
-	return s.updateLLM(loadedLLM)
+	return s.updateLLM(s.withLLMConfig(loadedLLM))
 }
 
 // conflictMarkerCue reports whether restoring the session left conflict
@@ -16,6 +16,7 @@ import (
 	"github.com/dagger/dagger/engine/slog"
 	"github.com/dagger/dagger/internal/cmd/dagger/llmconfig"
 	"github.com/dagger/dagger/util/cleanups"
+	"github.com/dagger/dagger/util/llmenv"
 )
 
 // oauthEnvProviders maps the auth-token environment variable the engine
@@ -33,6 +34,7 @@ func init() {
 		llmConfigCmd,
 		llmSetupCmd,
 		llmAddKeyCmd,
+		llmAddProviderCmd,
 		llmRemoveKeyCmd,
 		llmSetDefaultCmd,
 		llmResetCmd,
@@ -116,6 +118,10 @@ func applyLLMConfigEnv() {
 			setIfEmpty("GEMINI_MODEL", cfg.LLM.DefaultModel)
 		case "local":
 			setIfEmpty("LOCAL_MODEL", cfg.LLM.DefaultModel)
+		default:
+			if p := cfg.LLM.Providers[cfg.LLM.DefaultProvider]; llmconfig.IsNamedProvider(cfg.LLM.DefaultProvider, p) {
+				setIfEmpty(llmenv.NamedProvider(cfg.LLM.DefaultProvider, "MODEL"), cfg.LLM.DefaultModel)
+			}
 		}
 	}
 	// The openai and openrouter providers share the OPENAI_* variables. Pick a
@@ -130,10 +136,24 @@ func applyLLMConfigEnv() {
 			}
 		}
 	}
+	var named []string
 	for name, p := range cfg.LLM.Providers {
 		if !p.Enabled {
 			continue
 		}
+		if llmconfig.IsNamedProvider(name, p) {
+			// Self-hosted endpoints configured by name (`dagger llm
+			// add-provider`), tunneled through this client like the local
+			// one. The API key may be a secret reference, which the engine
+			// resolves against this client.
+			named = append(named, name)
+			setIfEmpty(llmenv.NamedProvider(name, "BASE_URL"), p.BaseURL)
+			setIfEmpty(llmenv.NamedProvider(name, "API_KEY"), p.APIKey)
+			setIfEmpty(llmenv.NamedProvider(name, "API_COMPAT"), p.APICompat)
+			setIfEmpty(llmenv.NamedProvider(name, "MODELS"), strings.Join(p.Models, ","))
+			setIfEmpty(llmenv.NamedProvider(name, "MODEL"), p.Model)
+			continue
+		}
 		if p.IsOAuth() {
 			// OAuth subscription providers export a bearer token that the
 			// engine's router picks up. Anthropic (Claude Code) and OpenAI
@@ -189,6 +209,8 @@ func applyLLMConfigEnv() {
 			setIfEmpty("LOCAL_API_KEY", p.APIKey)
 		}
 	}
+	slices.Sort(named)
+	setIfEmpty(llmenv.Providers, strings.Join(named, ","))
 }
 
 var llmParentCmd = &cobra.Command{
@@ -237,6 +259,9 @@ var llmConfigCmd = &cobra.Command{
 				if provider.BaseURL != "" && provider.APICompat == "" {
 					fmt.Fprintf(cmd.OutOrStdout(), "    Base URL: %s\n", provider.BaseURL)
 				}
+				if len(provider.Models) > 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "    Models: %s\n", strings.Join(provider.Models, ", "))
+				}
 			}
 		}
 
@@ -371,6 +396,91 @@ Supported providers:
 	},
 }
 
+var (
+	addProviderBaseURL  string
+	addProviderAPIKey   string
+	addProviderModels   []string
+	addProviderModel    string
+	addProviderProtocol string
+)
+
+func init() {
+	llmAddProviderCmd.Flags().StringVar(&addProviderBaseURL, "base-url", "", "Base URL of the provider's API (defaults to http://localhost:11434/v1 for ollama)")
+	llmAddProviderCmd.Flags().StringVar(&addProviderAPIKey, "api-key", "", "API key, or a secret reference such as env://VLLM_TOKEN or op://vault/item/field")
+	llmAddProviderCmd.Flags().StringSliceVar(&addProviderModels, "models", nil, "Models served by the provider")
+	llmAddProviderCmd.Flags().StringVar(&addProviderModel, "model", "", "Default model of the provider (defaults to the first of --models)")
+	llmAddProviderCmd.Flags().StringVar(&addProviderProtocol, "protocol", "openai", "API protocol of the provider: openai, anthropic or ollama")
+}
+
+var llmAddProviderCmd = &cobra.Command{
+	Use:   "add-provider <name>",
+	Short: "Add or update a self-hosted, named provider",
+	Long: `Add or update a self-hosted, named provider, such as a vLLM or LiteLLM
+gateway or an Ollama server.
+
+Its models are selected as <name>/<model>, e.g. with LLM.withModel or
+--model, or by their bare name if listed with --models. The provider only
+needs to be reachable from this host: the engine reaches it through the
+client's session.
+`,
+	Example: `  dagger llm add-provider vllm --base-url http://gpu-box:8000/v1 --api-key env://VLLM_TOKEN --models qwen3-coder
+  dagger llm add-provider ollama --protocol ollama --models llama3.2`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if llmconfig.IsBuiltinProvider(name) {
+			return fmt.Errorf("%q is a builtin provider, use 'dagger llm add-key' or 'dagger llm setup' instead", name)
+		}
+		if name == "" || strings.ContainsAny(name, "/, ") {
+			return fmt.Errorf("invalid provider name %q: must not contain '/', ',' or spaces", name)
+		}
+		switch addProviderProtocol {
+		case "openai", "anthropic":
+			if addProviderBaseURL == "" {
+				return fmt.Errorf("--base-url is required for the %s protocol", addProviderProtocol)
+			}
+		case "ollama":
+		default:
+			return fmt.Errorf("unsupported protocol %q, must be one of: openai, anthropic, ollama", addProviderProtocol)
+		}
+
+		cfg, err := llmconfig.Load()
+		if err != nil {
+			return err
+		}
+		if cfg == nil {
+			cfg = &llmconfig.Config{}
+			cfg.LLM.Providers = make(map[string]llmconfig.Provider)
+		}
+
+		model := addProviderModel
+		if model == "" && len(addProviderModels) > 0 {
+			model = addProviderModels[0]
+		}
+		cfg.LLM.Providers[name] = llmconfig.Provider{
+			APIKey:    addProviderAPIKey,
+			BaseURL:   addProviderBaseURL,
+			Model:     model,
+			APICompat: addProviderProtocol,
+			Models:    addProviderModels,
+			Enabled:   true,
+		}
+
+		// If this is the first provider, set it as default
+		if cfg.LLM.DefaultProvider == "" {
+			cfg.LLM.DefaultProvider = name
+			cfg.LLM.DefaultModel = model
+		}
+
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "%s Provider %s saved successfully!\n", idtui.IconSuccess, name)
+		return nil
+	},
+}
+
 var llmRemoveKeyCmd = &cobra.Command{
 	Use:   "remove-key <provider>",
 	Short: "Remove API key for a provider",
@@ -442,6 +552,9 @@ var llmSetDefaultCmd = &cobra.Command{
 			// requests back to the old provider. Prefer the provider's own
 			// configured model, then its catalog default; otherwise clear it.
 			model := providerCfg.Model
+			if model == "" && len(providerCfg.Models) > 0 {
+				model = providerCfg.Models[0]
+			}
 			if model == "" {
 				model = llmconfig.DefaultModelForProvider(provider)
 			}
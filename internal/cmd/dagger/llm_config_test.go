@@ -122,3 +122,80 @@ func TestApplyLLMConfigEnvOpenAISlot(t *testing.T) {
 		})
 	}
 }
+
+// TestApplyLLMConfigEnvNamedProviders verifies that providers added with
+// `dagger llm add-provider` are exported under DAGGER_LLM_PROVIDER_<NAME>_*,
+// and that a named default provider gets the default model.
+func TestApplyLLMConfigEnvNamedProviders(t *testing.T) {
+	tempDir := t.TempDir()
+	origConfigRoot := llmconfig.ConfigRoot
+	origConfigFile := llmconfig.ConfigFile
+	t.Cleanup(func() {
+		llmconfig.ConfigRoot = origConfigRoot
+		llmconfig.ConfigFile = origConfigFile
+	})
+	llmconfig.ConfigRoot = filepath.Join(tempDir, "dagger")
+	llmconfig.ConfigFile = filepath.Join(llmconfig.ConfigRoot, llmconfig.ConfigFileName)
+
+	for _, key := range []string{
+		"DAGGER_LLM_PROVIDERS",
+		"DAGGER_LLM_PROVIDER_MY_VLLM_BASE_URL",
+		"DAGGER_LLM_PROVIDER_MY_VLLM_API_KEY",
+		"DAGGER_LLM_PROVIDER_MY_VLLM_API_COMPAT",
+		"DAGGER_LLM_PROVIDER_MY_VLLM_MODELS",
+		"DAGGER_LLM_PROVIDER_MY_VLLM_MODEL",
+		"DAGGER_LLM_PROVIDER_HOME_OLLAMA_API_COMPAT",
+		"DAGGER_LLM_PROVIDER_HOME_OLLAMA_MODELS",
+		"DAGGER_LLM_PROVIDER_HOME_OLLAMA_MODEL",
+	} {
+		if val, ok := os.LookupEnv(key); ok {
+			t.Cleanup(func() { os.Setenv(key, val) })
+			os.Unsetenv(key)
+		} else {
+			t.Cleanup(func() { os.Unsetenv(key) })
+		}
+	}
+
+	t.Cleanup(func() {
+		addProviderBaseURL, addProviderAPIKey, addProviderModel = "", "", ""
+		addProviderModels = nil
+		addProviderProtocol = "openai"
+	})
+	llmAddProviderCmd.SetOut(io.Discard)
+
+	addProviderBaseURL = "http://gpu-box:8000/v1"
+	addProviderAPIKey = "env://VLLM_TOKEN"
+	addProviderModels = []string{"qwen3-coder", "gpt-oss-120b"}
+	addProviderProtocol = "openai"
+	if err := llmAddProviderCmd.RunE(llmAddProviderCmd, []string{"my-vllm"}); err != nil {
+		t.Fatalf("add-provider failed: %v", err)
+	}
+	addProviderBaseURL, addProviderAPIKey = "", ""
+	addProviderModels = []string{"llama3.2"}
+	addProviderProtocol = "ollama"
+	if err := llmAddProviderCmd.RunE(llmAddProviderCmd, []string{"local"}); err == nil {
+		t.Fatal("add-provider accepted the name of a builtin provider")
+	}
+	if err := llmAddProviderCmd.RunE(llmAddProviderCmd, []string{"home-ollama"}); err != nil {
+		t.Fatalf("add-provider failed: %v", err)
+	}
+
+	applyLLMConfigEnv()
+
+	for key, want := range map[string]string{
+		"DAGGER_LLM_PROVIDERS":                   "home-ollama,my-vllm",
+		"DAGGER_LLM_PROVIDER_MY_VLLM_BASE_URL":   "http://gpu-box:8000/v1",
+		"DAGGER_LLM_PROVIDER_MY_VLLM_API_KEY":    "env://VLLM_TOKEN",
+		"DAGGER_LLM_PROVIDER_MY_VLLM_API_COMPAT": "openai",
+		"DAGGER_LLM_PROVIDER_MY_VLLM_MODELS":     "qwen3-coder,gpt-oss-120b",
+		// the first provider added became the default
+		"DAGGER_LLM_PROVIDER_MY_VLLM_MODEL":          "qwen3-coder",
+		"DAGGER_LLM_PROVIDER_HOME_OLLAMA_API_COMPAT": "ollama",
+		"DAGGER_LLM_PROVIDER_HOME_OLLAMA_MODEL":      "llama3.2",
+	} {
+		t.Cleanup(func() { os.Unsetenv(key) })
+		if got := os.Getenv(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 
 	"github.com/adrg/xdg"
@@ -68,9 +69,32 @@ type Provider struct {
 	ReasoningEffort string `toml:"reasoning_effort,omitempty"`
 
 	// APICompat selects which API protocol to use for custom/local endpoints.
-	// Values: "openai" (OpenAI-compatible) or "anthropic" (Anthropic-compatible).
-	// When set, BaseURL is used as the endpoint and the model name is passed through.
+	// Values: "openai" (OpenAI-compatible), "anthropic" (Anthropic-compatible)
+	// or "ollama" (Ollama's OpenAI-compatible API). When set, BaseURL is used
+	// as the endpoint and the model name is passed through.
 	APICompat string `toml:"api_compat,omitempty"`
+
+	// Models lists the models served by a named provider (see
+	// IsNamedProvider), which route to it by exact match.
+	Models []string `toml:"models,omitempty"`
+}
+
+// builtinProviders are the provider names with a meaning of their own, to
+// the CLI or to the engine's LLM router.
+var builtinProviders = []string{
+	"anthropic", "openai", "openai-codex", "openrouter", "google", "gemini",
+	"local", "meta", "mistral", "deepseek", "other",
+}
+
+// IsBuiltinProvider returns true if name is reserved for a builtin provider.
+func IsBuiltinProvider(name string) bool {
+	return slices.Contains(builtinProviders, name)
+}
+
+// IsNamedProvider returns true if the provider is a self-hosted endpoint
+// configured under a name of its own with `dagger llm add-provider`.
+func IsNamedProvider(name string, p Provider) bool {
+	return !IsBuiltinProvider(name) && p.APICompat != ""
 }
 
 // IsOAuth returns true if this provider uses OAuth authentication.
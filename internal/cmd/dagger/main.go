@@ -77,6 +77,7 @@ var (
 	_, useCloudEngine        = os.LookupEnv("DAGGER_CLOUD_ENGINE")
 	enableScaleOut           bool
 	profileFlag              bool
+	cacheTags                []string
 
 	dotOutputFilePath string
 	dotFocusField     string
@@ -94,6 +95,14 @@ const daggerXReleaseEnv = "DAGGER_X_RELEASE"
 
 var githubCommitAPI = "https://api.github.com/repos/dagger/dagger/commits/"
 
+func cacheTagsFromEnv() []string {
+	// DAGGER_CACHE_TAGS is the environment equivalent of --cache-tag.
+	if tags := os.Getenv("DAGGER_CACHE_TAGS"); tags != "" {
+		return strings.Split(tags, ",")
+	}
+	return nil
+}
+
 func silentFromEnv() bool {
 	// DAGGER_SILENT is the environment equivalent of --silent.
 	silent, _ := strconv.ParseBool(os.Getenv("DAGGER_SILENT"))
@@ -182,9 +191,11 @@ func init() {
 	sdkCmd.GroupID = "toolbox"
 	cloudCmd.GroupID = "toolbox"
 	workspaceCmd.GroupID = "toolbox"
+	cacheCmd.GroupID = "toolbox"
 
 	versionRoot := versionCmd()
 	versionRoot.GroupID = "utility"
+	debugCmd.GroupID = "utility"
 
 	// Cobra auto-creates the help command; assign it to the utility group too.
 	rootCmd.SetHelpCommandGroupID("utility")
@@ -216,6 +227,8 @@ func init() {
 		sessionAliasCmd,
 		shellCmd,
 		mcpCmd,
+		debugCmd,
+		cacheCmd,
 	)
 
 	rootCmd.PersistentFlags().StringVar(&cloudOrgFlag, "org", "", "Dagger Cloud org name for Cloud-scoped commands")
@@ -426,6 +439,7 @@ func installGlobalFlags(flags *pflag.FlagSet) {
 	flags.BoolVarP(&web, "web", "w", false, "Open trace URL in a web browser")
 	flags.BoolVarP(&noExit, "no-exit", "E", false, "Leave the TUI running after completion")
 	flags.BoolVarP(&autoApply, "auto-apply", "y", false, "Automatically apply changes when a changeset is returned")
+	flags.StringSliceVar(&cacheTags, "cache-tag", cacheTagsFromEnv(), "Tag the cache entries produced by this session, to prune them with 'dagger cache prune --tag'")
 	flags.StringVar(&xRelease, "x-release", xRelease, "Run an experimental release from a Dagger git ref")
 
 	flags.StringVar(&dotOutputFilePath, "dot-output", "", "If set, write the calls made during execution to a dot file at the given path before exiting")
@@ -1,32 +1,59 @@
 package daggercmd
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/subtle"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/dagger/dagger/dagql/idtui"
+	"github.com/dagger/dagger/engine"
 	"github.com/dagger/dagger/engine/client"
+	"github.com/dagger/dagger/engine/slog"
 	"github.com/dagger/querybuilder"
 	"github.com/spf13/cobra"
+	"golang.org/x/net/http2"
+	"golang.org/x/sync/errgroup"
 )
 
 var (
-	mcpStdio      bool
-	mcpSseAddr    string
-	envPrivileged bool
+	mcpStdio          bool
+	mcpListen         string
+	mcpToken          string
+	mcpAllowedOrigins []string
+	envPrivileged     bool
 )
 
 func init() {
 	mcpCmd.PersistentFlags().BoolVar(&mcpStdio, "stdio", true, "Use standard input/output for communicating with the MCP server")
 	mcpCmd.PersistentFlags().BoolVar(&envPrivileged, "env-privileged", false, "Expose the core API as tools")
-	mcpCmd.PersistentFlags().StringVar(&mcpSseAddr, "sse-addr", "", "Address of the MCP SSE server (no SSE server if empty)")
+	mcpCmd.PersistentFlags().StringVar(&mcpListen, "listen", "", "Serve the MCP streamable HTTP transport on the given address, e.g. :8080, instead of stdio. Listens on loopback unless a host is given")
+	mcpCmd.PersistentFlags().StringVar(&mcpToken, "token", "", "Require this bearer token from HTTP clients. Defaults to $DAGGER_MCP_TOKEN")
+	mcpCmd.PersistentFlags().StringSliceVar(&mcpAllowedOrigins, "allow-origin", nil, "Allow HTTP requests from browsers on this origin, e.g. https://example.com, on top of loopback ones")
 }
 
 var mcpCmd = &cobra.Command{
 	Use:   "mcp [options]",
 	Short: "Expose a dagger module as an MCP server",
 	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if mcpListen != "" {
+			// stdio is left alone
+			return nil
+		}
+
 		if progress == "tty" {
 			return fmt.Errorf("cannot use tty progress output: it interferes with mcp stdio")
 		}
@@ -42,11 +69,44 @@ var mcpCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		cmd.SetContext(idtui.WithPrintTraceLink(ctx, true))
+
+		if mcpListen != "" {
+			listener, err := net.Listen("tcp", mcpListenAddr(mcpListen))
+			if err != nil {
+				return fmt.Errorf("listen: %w", err)
+			}
+			defer listener.Close()
+
+			// the engine serves HTTP/2 over the session's pipe, which is
+			// wired to one end of an in-memory connection
+			engineConn, cliConn := net.Pipe()
+			defer cliConn.Close()
+
+			return withEngine(ctx, client.Params{
+				Stdin:                engineConn,
+				Stdout:               engineConn,
+				LoadWorkspaceModules: true,
+				SyncWatcher:          client.NewSyncWatcher(),
+			}, func(ctx context.Context, engineClient *client.Client) error {
+				return mcpServeHTTP(ctx, engineClient, listener, cliConn)
+			})
+		}
+
+		if !mcpStdio {
+			return errors.New("either --stdio or --listen must be set")
+		}
+		// the MCP client's messages are passed through line by line, for
+		// notifications of workspace changes to be sent in between
+		engineStdin := newMCPStdin(stdin)
 		return withEngine(ctx, client.Params{
-			Stdin:                stdin,
+			Stdin:                engineStdin,
 			Stdout:               stdout,
 			LoadWorkspaceModules: true,
-		}, mcpStart)
+			SyncWatcher:          client.NewSyncWatcher(),
+		}, func(ctx context.Context, engineClient *client.Client) error {
+			go watchMCPWorkspace(ctx, engineClient.Params.SyncWatcher, engineStdin.send)
+			return mcpStart(ctx, engineClient)
+		})
 	},
 	Hidden: true,
 	Annotations: map[string]string{
@@ -56,15 +116,219 @@ var mcpCmd = &cobra.Command{
 
 // dagger -m github.com/org/repo mcp
 func mcpStart(ctx context.Context, engineClient *client.Client) error {
-	if mcpSseAddr != "" || !mcpStdio {
-		return errors.New("currently MCP only works with stdio")
+	q, err := mcpQuery(ctx, engineClient, false)
+	if err != nil {
+		return err
 	}
 
-	modDef, err := initializeWorkspace(ctx, engineClient.Dagger(), loadTypeDefsOpts{HideCore: true})
+	var response any
+	if err := makeRequest(ctx, q, &response); err != nil {
+		return fmt.Errorf("error starting MCP server: %w", err)
+	}
+
+	return nil
+}
+
+// dagger mcp --listen :8080
+//
+// The engine serves the streamable HTTP transport over HTTP/2 on the pipe, and
+// every HTTP client connecting to the listener is proxied through it, sharing
+// the same session.
+func mcpServeHTTP(ctx context.Context, engineClient *client.Client, listener net.Listener, pipe net.Conn) error {
+	q, err := mcpQuery(ctx, engineClient, true)
 	if err != nil {
 		return err
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+		pipe.Close()
+	}()
+
+	eg := new(errgroup.Group)
+	eg.Go(func() error {
+		defer cancel()
+		var response any
+		if err := makeRequest(ctx, q, &response); err != nil {
+			return fmt.Errorf("error starting MCP server: %w", err)
+		}
+		return nil
+	})
+	eg.Go(func() error {
+		defer cancel()
+		transport, err := http2.ConfigureTransports(&http.Transport{})
+		if err != nil {
+			return err
+		}
+		// blocks until the engine starts serving the pipe
+		conn, err := transport.NewClientConn(pipe)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("connect to MCP server: %w", err)
+		}
+		token := mcpToken
+		if token == "" {
+			token = os.Getenv("DAGGER_MCP_TOKEN")
+		}
+		srv := &http.Server{
+			Handler: mcpHTTPGuard(&httputil.ReverseProxy{
+				Rewrite: func(r *httputil.ProxyRequest) {
+					r.Out.URL.Scheme = "http"
+					r.Out.URL.Host = "mcp"
+					r.Out.Header.Del("Authorization")
+				},
+				Transport: conn,
+				// stream server-sent events as they come
+				FlushInterval: -1,
+			}, token, mcpAllowedOrigins),
+		}
+		go watchMCPWorkspace(ctx, engineClient.Params.SyncWatcher, func(msg []byte) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://mcp/mcp", bytes.NewReader(msg))
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := conn.RoundTrip(req)
+			if err != nil {
+				return err
+			}
+			return resp.Body.Close()
+		})
+		fmt.Fprintf(stderr, "MCP server listening on http://%s/mcp\n", listener.Addr())
+		if err := srv.Serve(listener); err != nil && ctx.Err() == nil {
+			return fmt.Errorf("serve MCP: %w", err)
+		}
+		return nil
+	})
+	return eg.Wait()
+}
+
+// watchMCPWorkspace notifies the MCP server whenever files of the workspace
+// synced to the engine change on the host, for it to notify clients of the
+// resources that changed with them.
+func watchMCPWorkspace(ctx context.Context, watcher *client.SyncWatcher, send func([]byte) error) {
+	msg, err := json.Marshal(map[string]string{
+		"jsonrpc": "2.0",
+		"method":  engine.MCPWorkspaceChangedMethod,
+	})
+	if err != nil {
+		return
+	}
+	for {
+		changed, err := watcher.Wait(ctx, watchInterval)
+		if err != nil {
+			return
+		}
+		slog.Debug("workspace files changed, notifying the MCP server", "changes", describeChanges(changed))
+		if err := send(msg); err != nil {
+			slog.Warn("could not notify the MCP server of workspace changes", "error", err)
+		}
+	}
+}
+
+// mcpStdin passes the MCP client's messages through to the MCP server line by
+// line, so that other messages can be sent in between.
+type mcpStdin struct {
+	*io.PipeReader
+	mu sync.Mutex
+	w  *io.PipeWriter
+}
+
+func newMCPStdin(r io.Reader) *mcpStdin {
+	pr, pw := io.Pipe()
+	in := &mcpStdin{PipeReader: pr, w: pw}
+	go func() {
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if err := in.write(line); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return in
+}
+
+// send sends a message to the MCP server between the client's messages.
+func (in *mcpStdin) send(msg []byte) error {
+	return in.write(append(slices.Clip(msg), '\n'))
+}
+
+func (in *mcpStdin) write(p []byte) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	_, err := in.w.Write(p)
+	return err
+}
+
+// mcpListenAddr returns the address to listen on for --listen, defaulting to
+// loopback when only a port is given, so that the server isn't exposed to
+// the network by accident.
+func mcpListenAddr(addr string) string {
+	if _, err := strconv.Atoi(addr); err == nil {
+		return net.JoinHostPort("127.0.0.1", addr)
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("127.0.0.1", port)
+}
+
+// mcpHTTPGuard only lets through requests with the bearer token, if any, and
+// from browsers on loopback or allowed origins, which guards against DNS
+// rebinding attacks from websites.
+func mcpHTTPGuard(next http.Handler, token string, allowedOrigins []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" && !mcpOriginAllowed(origin, allowedOrigins) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+		if token != "" {
+			auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(auth), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func mcpOriginAllowed(origin string, allowedOrigins []string) bool {
+	if slices.Contains(allowedOrigins, origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func mcpQuery(ctx context.Context, engineClient *client.Client, overHTTP bool) (*querybuilder.Selection, error) {
+	modDef, err := initializeWorkspace(ctx, engineClient.Dagger(), loadTypeDefsOpts{HideCore: true})
+	if err != nil {
+		return nil, err
+	}
+
 	hasWorkspaceModule := false
 	if modDef != nil && modDef.MainObject != nil {
 		if fp := modDef.MainObject.AsFunctionProvider(); fp != nil {
@@ -77,7 +341,7 @@ func mcpStart(ctx context.Context, engineClient *client.Client) error {
 		}
 	}
 	if !hasWorkspaceModule && !envPrivileged {
-		return fmt.Errorf("no module found and --env-privileged not specified")
+		return nil, fmt.Errorf("no module found and --env-privileged not specified")
 	}
 
 	// llm() starts unbound: resolve the current workspace and bind it
@@ -86,7 +350,7 @@ func mcpStart(ctx context.Context, engineClient *client.Client) error {
 	// for the served MCP toolset (see LLM.MCP / MCP.bindWorkspaceModuleTools).
 	wsID, err := engineClient.Dagger().CurrentWorkspace().ID(ctx)
 	if err != nil {
-		return fmt.Errorf("resolve current workspace: %w", err)
+		return nil, fmt.Errorf("resolve current workspace: %w", err)
 	}
 
 	q := querybuilder.Query().Client(engineClient.Dagger().GraphQLClient())
@@ -94,11 +358,8 @@ func mcpStart(ctx context.Context, engineClient *client.Client) error {
 		Select("llm").
 		Select("withWorkspace").Arg("workspace", wsID).
 		Select("__mcp")
-
-	var response any
-	if err := makeRequest(ctx, q, &response); err != nil {
-		return fmt.Errorf("error starting MCP server: %w", err)
+	if overHTTP {
+		q = q.Arg("http", true)
 	}
-
-	return nil
+	return q, nil
 }
@@ -0,0 +1,83 @@
+package daggercmd
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPListenAddr(t *testing.T) {
+	require.Equal(t, "127.0.0.1:8080", mcpListenAddr(":8080"))
+	require.Equal(t, "127.0.0.1:8080", mcpListenAddr("8080"))
+	require.Equal(t, "0.0.0.0:8080", mcpListenAddr("0.0.0.0:8080"))
+	require.Equal(t, "[::1]:8080", mcpListenAddr("[::1]:8080"))
+	require.Equal(t, "localhost:8080", mcpListenAddr("localhost:8080"))
+}
+
+func TestMCPHTTPGuard(t *testing.T) {
+	var gotAuth string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	})
+	serve := func(handler http.Handler, headers map[string]string) int {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("origin", func(t *testing.T) {
+		handler := mcpHTTPGuard(next, "", []string{"https://example.com"})
+		require.Equal(t, http.StatusOK, serve(handler, nil))
+		for _, origin := range []string{"http://localhost:3000", "http://127.0.0.1", "http://[::1]:8080", "https://example.com"} {
+			require.Equal(t, http.StatusOK, serve(handler, map[string]string{"Origin": origin}), origin)
+		}
+		// a website whose domain resolves to loopback (DNS rebinding)
+		for _, origin := range []string{"http://evil.example:8080", "https://example.com:8443", "null"} {
+			require.Equal(t, http.StatusForbidden, serve(handler, map[string]string{"Origin": origin}), origin)
+		}
+	})
+
+	t.Run("bearer token", func(t *testing.T) {
+		handler := mcpHTTPGuard(next, "s3cret", nil)
+		require.Equal(t, http.StatusUnauthorized, serve(handler, nil))
+		require.Equal(t, http.StatusUnauthorized, serve(handler, map[string]string{"Authorization": "Bearer wrong"}))
+		require.Equal(t, http.StatusUnauthorized, serve(handler, map[string]string{"Authorization": "s3cret"}))
+		require.Equal(t, http.StatusOK, serve(handler, map[string]string{"Authorization": "Bearer s3cret"}))
+		require.Equal(t, "Bearer s3cret", gotAuth)
+	})
+}
+
+func TestMCPStdin(t *testing.T) {
+	clientR, clientW := io.Pipe()
+	in := newMCPStdin(clientR)
+	lines := bufio.NewReader(in)
+
+	go clientW.Write([]byte(`{"id":1}` + "\n" + `{"id":`))
+	line, err := lines.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, `{"id":1}`+"\n", line)
+
+	// a message sent between the client's doesn't split them
+	go in.send([]byte(`{"method":"changed"}`))
+	line, err = lines.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, `{"method":"changed"}`+"\n", line)
+
+	go func() {
+		clientW.Write([]byte(`2}` + "\n"))
+		clientW.Close()
+	}()
+	line, err = lines.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, `{"id":2}`+"\n", line)
+	_, err = lines.ReadString('\n')
+	require.ErrorIs(t, err, io.EOF)
+}
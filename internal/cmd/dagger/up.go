@@ -3,6 +3,8 @@ package daggercmd
 import (
 	"context"
 	_ "embed"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -13,13 +15,18 @@ import (
 	telemetry "github.com/dagger/otel-go"
 )
 
-var upListMode bool
+var (
+	upListMode bool
+	upWatch    bool
+)
 
 //go:embed up.graphql
 var loadUpQuery string
 
 func init() {
 	upCmd.Flags().BoolVarP(&upListMode, "list", "l", false, "List available services")
+	upCmd.Flags().BoolVar(&upWatch, "watch", false, "Restart services whenever their input files change")
+	upCmd.MarkFlagsMutuallyExclusive("watch", "list")
 }
 
 var upCmd = &cobra.Command{
@@ -31,26 +38,34 @@ Examples:
   dagger up                       # Start all services
   dagger up -l                    # List all available services
   dagger up web                   # Start only the 'web' service
+  dagger up --watch               # Start all services, restarting them as their inputs change
 `,
 	Args: cobra.ArbitraryArgs,
 	Annotations: map[string]string{
 		showFinalProgressKey: "true",
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		params := client.Params{
+			LoadWorkspaceModules: true,
+		}
+		if upWatch {
+			params.SyncWatcher = client.NewSyncWatcher()
+		}
+		servicesOf := func(ws *dagger.Workspace) *dagger.UpGroup {
+			if len(args) > 0 {
+				return ws.Services(dagger.WorkspaceServicesOpts{Include: args})
+			}
+			return ws.Services()
+		}
 		return withEngine(
 			cmd.Context(),
-			client.Params{
-				LoadWorkspaceModules: true,
-			},
+			params,
 			func(ctx context.Context, engineClient *client.Client) error {
 				dag := engineClient.Dagger()
-				ws := dag.CurrentWorkspace()
-				var services *dagger.UpGroup
-				if len(args) > 0 {
-					services = ws.Services(dagger.WorkspaceServicesOpts{Include: args})
-				} else {
-					services = ws.Services()
+				if upWatch {
+					return watchServices(ctx, dag, params.SyncWatcher, servicesOf)
 				}
+				services := servicesOf(dag.CurrentWorkspace())
 				if upListMode {
 					return listServices(ctx, dag, services, cmd)
 				}
@@ -115,3 +130,156 @@ func runServices(ctx context.Context, upGroup *dagger.UpGroup, _ *cobra.Command)
 	}
 	return err
 }
+
+// watchServices runs the selected services like runServices, but restarts
+// each of them when its inputs change.
+//
+// Every service runs on its own, so that it can be restarted without the
+// others. On each change the services of the reloaded workspace are listed
+// with their digest: a service is restarted only if its digest changed (or it
+// exited), services that went away are stopped, and new ones are started. The
+// port forwards from dagger.toml are part of each service, so a restarted
+// service comes back on the same host ports.
+func watchServices(
+	ctx context.Context,
+	dag *dagger.Client,
+	watcher *client.SyncWatcher,
+	servicesOf func(*dagger.Workspace) *dagger.UpGroup,
+) error {
+	ctx, zoomSpan := Tracer().Start(ctx, "services", telemetry.Passthrough())
+	defer zoomSpan.End()
+	Frontend.SetPrimary(dagui.SpanID{SpanID: zoomSpan.SpanContext().SpanID()})
+	slog.SetDefault(slog.SpanLogger(ctx, InstrumentationLibrary))
+
+	running := map[string]*watchedService{}
+	defer func() {
+		for _, svc := range running {
+			svc.stop()
+		}
+	}()
+	return watchWorkspace(ctx, dag, watcher, func(ctx context.Context, ws *dagger.Workspace) error {
+		return restartServices(ctx, dag, servicesOf(ws), running)
+	})
+}
+
+// restartServices brings the running services in line with the services of
+// the group, restarting only those that are out of date.
+func restartServices(ctx context.Context, dag *dagger.Client, upGroup *dagger.UpGroup, running map[string]*watchedService) error {
+	id, err := upGroup.ID(ctx)
+	if err != nil {
+		return err
+	}
+	var res struct {
+		Group struct {
+			List []struct {
+				ID     string
+				Name   string
+				Digest string
+			}
+		}
+	}
+	err = dag.Do(ctx, &dagger.Request{
+		Query:  loadUpQuery,
+		OpName: "UpGroupDigests",
+		Variables: map[string]any{
+			"id": id,
+		},
+	}, &dagger.Response{
+		Data: &res,
+	})
+	if err != nil {
+		return err
+	}
+
+	selected := map[string]bool{}
+	for _, up := range res.Group.List {
+		selected[up.Name] = true
+		if svc, ok := running[up.Name]; ok {
+			if svc.digest == up.Digest && !svc.exited() {
+				continue
+			}
+			svc.stop()
+		}
+		running[up.Name] = startService(ctx, dag, up.ID, up.Digest)
+	}
+	for name, svc := range running {
+		if !selected[name] {
+			svc.stop()
+			delete(running, name)
+		}
+	}
+	return nil
+}
+
+// watchedService is a service started by watchServices.
+type watchedService struct {
+	digest string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func startService(ctx context.Context, dag *dagger.Client, id, digest string) *watchedService {
+	ctx, cancel := context.WithCancel(ctx)
+	svc := &watchedService{
+		digest: digest,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(svc.done)
+		deadline := time.Now().Add(portReleaseTimeout)
+		for {
+			// Up.run blocks until cancelled; its failures show in its own span.
+			err := dag.Do(ctx, &dagger.Request{
+				Query:  loadUpQuery,
+				OpName: "UpRun",
+				Variables: map[string]any{
+					"id": id,
+				},
+			}, &dagger.Response{})
+			// The service this one replaces may still be releasing its host
+			// ports, so retry binding them for a little while.
+			if err == nil || !isAddrInUse(err) || time.Now().After(deadline) {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(portReleaseInterval):
+			}
+		}
+	}()
+	return svc
+}
+
+const (
+	// portReleaseTimeout bounds how long a restarted service waits for the
+	// host ports of the service it replaces to be released.
+	portReleaseTimeout  = 10 * time.Second
+	portReleaseInterval = 200 * time.Millisecond
+)
+
+// isAddrInUse reports whether err is a failure to bind a host port that is
+// still in use.
+func isAddrInUse(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "address already in use") ||
+		strings.Contains(msg, "Only one usage of each socket address") // windows
+}
+
+func (svc *watchedService) exited() bool {
+	select {
+	case <-svc.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop cancels the service's run and waits for it to return. The engine may
+// release the service's host ports only afterwards, which startService
+// tolerates by retrying the bind.
+func (svc *watchedService) stop() {
+	svc.cancel()
+	<-svc.done
+}
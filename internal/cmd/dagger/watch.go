@@ -0,0 +1,74 @@
+package daggercmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"dagger.io/dagger"
+	"github.com/dagger/dagger/dagql/idtui"
+	"github.com/dagger/dagger/engine/client"
+	"github.com/dagger/dagger/engine/slog"
+)
+
+// watchInterval is how often --watch polls the workspace for changes.
+const watchInterval = 500 * time.Millisecond
+
+// watchWorkspace calls run with the current workspace, then again every time
+// files the session synced from the host change, with the workspace reloaded so
+// they are read afresh. Everything stays in the one session: work whose inputs
+// didn't change is a cache hit, so only the affected work actually re-runs.
+//
+// A failed run doesn't stop watching. watchWorkspace returns once ctx is
+// cancelled (e.g. Ctrl+C).
+func watchWorkspace(
+	ctx context.Context,
+	dag *dagger.Client,
+	watcher *client.SyncWatcher,
+	run func(context.Context, *dagger.Workspace) error,
+) error {
+	ws := dag.CurrentWorkspace()
+	for {
+		if err := run(ctx, ws); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			// Failed checks and the like have been reported already.
+			var exitErr idtui.ExitError
+			if !errors.As(err, &exitErr) {
+				slog.Error("run failed", "error", err)
+			}
+		}
+		changed, err := watcher.Wait(ctx, watchInterval)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		slog.Info("files changed, running again", "changes", describeChanges(changed))
+		ws = dag.CurrentWorkspace().Reloaded()
+	}
+}
+
+// describeChanges summarizes changed paths for a log line, relative to the
+// current directory when they are under it.
+func describeChanges(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	first := paths[0]
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, first); err == nil && !strings.HasPrefix(rel, "..") {
+			first = rel
+		}
+	}
+	if len(paths) == 1 {
+		return first
+	}
+	return fmt.Sprintf("%s and %d more", first, len(paths)-1)
+}
@@ -0,0 +1,24 @@
+package daggercmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeChanges(t *testing.T) {
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	require.Empty(t, describeChanges(nil))
+	require.Equal(t, filepath.Join("src", "main.go"), describeChanges([]string{
+		filepath.Join(cwd, "src", "main.go"),
+	}))
+	require.Equal(t, "/elsewhere/go.mod and 2 more", describeChanges([]string{
+		"/elsewhere/go.mod",
+		filepath.Join(cwd, "a"),
+		filepath.Join(cwd, "b"),
+	}))
+}
@@ -0,0 +1,65 @@
+package daggercmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dagger/dagger/dagql/idtui"
+	"github.com/dagger/dagger/engine/client"
+	"github.com/spf13/cobra"
+)
+
+var whyMissJSON bool
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Debug the engine",
+	Annotations: map[string]string{
+		"experimental": "true",
+	},
+}
+
+var whyMissCmd = &cobra.Command{
+	Use:   "why-miss <call digest>",
+	Short: "Explain why a call missed the cache",
+	Long: `Explain why a call executed instead of reusing a cached result.
+
+The call is compared with the most similar call of the same field cached before
+it, reporting the inputs that differ. Differing objects are compared in turn, so
+the report points at the inputs that diverged first: an argument, an
+environment variable set further up a container's chain, or the content of a
+host directory.
+
+The call digest is recorded on the call's span as dagger.io/dag.digest, and
+shown as CallDigest in the TUI's debug view (the "?" key). In the TUI, the "m"
+key explains the focused step's miss inline.
+
+The call must still be in the engine's cache.`,
+	Example: "dagger debug why-miss sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		digest := strings.TrimSpace(args[0])
+		return withEngine(cmd.Context(), client.Params{
+			SkipWorkspaceModules: true,
+		}, func(ctx context.Context, engineClient *client.Client) error {
+			explanation, err := idtui.ExplainCacheMiss(ctx, engineClient.Dagger(), digest)
+			if err != nil {
+				return err
+			}
+			if whyMissJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(explanation)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), explanation)
+			return nil
+		})
+	},
+}
+
+func init() {
+	whyMissCmd.Flags().BoolVar(&whyMissJSON, "json", false, "Output the explanation in JSON format")
+	debugCmd.AddCommand(whyMissCmd)
+}
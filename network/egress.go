@@ -0,0 +1,95 @@
+package network
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// EgressPolicy restricts the destinations a container can connect to.
+//
+// Anything not allowed by the policy is denied, so an empty policy denies
+// all egress. Containers can always reach the services of their session.
+type EgressPolicy struct {
+	// Allow lists the domains, IP addresses and CIDRs that may be reached.
+	Allow []string `json:"allow,omitempty"`
+
+	// AllowRegistries allows reaching the container registries known to the
+	// engine.
+	AllowRegistries bool `json:"allowRegistries,omitempty"`
+
+	// Source describes where the policy was set, e.g. "engine" or "module
+	// foo". It is reported when a connection is blocked.
+	Source string `json:"source,omitempty"`
+}
+
+// DefaultRegistryDomains are the domains of well-known public registries,
+// including the domains their blobs are served from, that are allowed by
+// policies that allow registries.
+var DefaultRegistryDomains = []string{
+	"registry-1.docker.io",
+	"auth.docker.io",
+	"production.cloudflare.docker.com",
+	"index.docker.io",
+	"registry.dagger.io",
+	"ghcr.io",
+	"pkg-containers.githubusercontent.com",
+	"quay.io",
+	"cdn01.quay.io",
+	"cdn02.quay.io",
+	"cdn03.quay.io",
+	"gcr.io",
+	"registry.k8s.io",
+	"public.ecr.aws",
+	"mcr.microsoft.com",
+}
+
+// Validate checks that all the destinations allowed by the policy are valid.
+func (p EgressPolicy) Validate() error {
+	for _, dest := range p.Allow {
+		if _, _, err := ParseEgressDestination(dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseEgressDestination parses a destination allowed by an egress policy,
+// which is either an IP address or CIDR, returned as a prefix, or a domain.
+func ParseEgressDestination(dest string) (prefix netip.Prefix, domain string, _ error) {
+	dest = strings.TrimSpace(dest)
+	if strings.Contains(dest, "/") {
+		prefix, err := netip.ParsePrefix(dest)
+		if err != nil {
+			return netip.Prefix{}, "", fmt.Errorf("invalid egress CIDR %q: %w", dest, err)
+		}
+		return prefix.Masked(), "", nil
+	}
+	if addr, err := netip.ParseAddr(dest); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen()), "", nil
+	}
+	if !isDomain(dest) {
+		return netip.Prefix{}, "", fmt.Errorf("invalid egress destination %q: must be a domain, an IP address or a CIDR", dest)
+	}
+	return netip.Prefix{}, strings.ToLower(strings.TrimSuffix(dest, ".")), nil
+}
+
+func isDomain(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for label := range strings.SplitSeq(s, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			switch {
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package network
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEgressDestination(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		dest   string
+		prefix netip.Prefix
+		domain string
+		err    string
+	}{
+		{dest: "GitHub.com.", domain: "github.com"},
+		{dest: "proxy_golang.org", domain: "proxy_golang.org"},
+		{dest: "10.1.2.3", prefix: netip.MustParsePrefix("10.1.2.3/32")},
+		{dest: "10.1.2.3/8", prefix: netip.MustParsePrefix("10.0.0.0/8")},
+		{dest: "2001:db8::1", prefix: netip.MustParsePrefix("2001:db8::1/128")},
+		{dest: "10.0.0.0/33", err: "invalid egress CIDR"},
+		{dest: "*.github.com", err: "invalid egress destination"},
+		{dest: "github.com:443", err: "invalid egress destination"},
+		{dest: "", err: "invalid egress destination"},
+	} {
+		t.Run(tc.dest, func(t *testing.T) {
+			prefix, domain, err := ParseEgressDestination(tc.dest)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.prefix, prefix)
+			require.Equal(t, tc.domain, domain)
+		})
+	}
+
+	require.NoError(t, EgressPolicy{Allow: []string{"github.com", "10.0.0.0/8"}}.Validate())
+	require.Error(t, EgressPolicy{Allow: []string{"github.com", "nope!"}}.Validate())
+}
@@ -0,0 +1,165 @@
+package netinst
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/netip"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// EgressLogGroup is the NFLOG group blocked connections are logged to, in the
+// network namespace of a container.
+const EgressLogGroup = 100
+
+const (
+	egressChainPrefix = "DAGGER-EGRESS-"
+	egressBlockChain  = egressChainPrefix + "BLOCK"
+)
+
+// EgressRules returns the input of iptables-restore (or ip6tables-restore if
+// ipv6 is set) restricting egress from a network namespace.
+//
+// Every policy in allowed must allow a destination for it to be reached,
+// except for destinations in local, which are always allowed. Blocked
+// connections are logged to EgressLogGroup, prefixed with the index of the
+// first policy blocking them, and rejected.
+func EgressRules(allowed [][]netip.Prefix, local []netip.Prefix, ipv6 bool) string {
+	var buf strings.Builder
+	fmt.Fprintln(&buf, "*filter")
+	fmt.Fprintln(&buf, ":INPUT ACCEPT [0:0]")
+	fmt.Fprintln(&buf, ":FORWARD ACCEPT [0:0]")
+	fmt.Fprintln(&buf, ":OUTPUT ACCEPT [0:0]")
+	fmt.Fprintf(&buf, ":%s - [0:0]\n", egressBlockChain)
+	for i := range allowed {
+		fmt.Fprintf(&buf, ":%s%d - [0:0]\n", egressChainPrefix, i)
+	}
+
+	fmt.Fprintln(&buf, "-A OUTPUT -o lo -j ACCEPT")
+	fmt.Fprintln(&buf, "-A OUTPUT -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT")
+	for _, prefix := range local {
+		if prefix.Addr().Is6() == ipv6 {
+			fmt.Fprintf(&buf, "-A OUTPUT -d %s -j ACCEPT\n", prefix)
+		}
+	}
+	for i := range allowed {
+		fmt.Fprintf(&buf, "-A OUTPUT -j %s%d\n", egressChainPrefix, i)
+	}
+
+	for i, prefixes := range allowed {
+		chain := egressChainPrefix + strconv.Itoa(i)
+		for _, prefix := range prefixes {
+			if prefix.Addr().Is6() == ipv6 {
+				fmt.Fprintf(&buf, "-A %s -d %s -j RETURN\n", chain, prefix)
+			}
+		}
+		fmt.Fprintf(&buf, "-A %s -j NFLOG --nflog-group %d --nflog-prefix %d\n", chain, EgressLogGroup, i)
+		fmt.Fprintf(&buf, "-A %s -j %s\n", chain, egressBlockChain)
+	}
+
+	fmt.Fprintf(&buf, "-A %s -p tcp -j REJECT --reject-with tcp-reset\n", egressBlockChain)
+	if ipv6 {
+		fmt.Fprintf(&buf, "-A %s -j REJECT --reject-with icmp6-adm-prohibited\n", egressBlockChain)
+	} else {
+		fmt.Fprintf(&buf, "-A %s -j REJECT --reject-with icmp-admin-prohibited\n", egressBlockChain)
+	}
+	fmt.Fprintln(&buf, "COMMIT")
+	return buf.String()
+}
+
+// EgressDNSRules returns the input of iptables-restore redirecting the DNS
+// queries sent to the given IPv4 nameservers to the UDP and TCP ports of an
+// EgressDNSProxy listening on the loopback interface.
+func EgressDNSRules(nameservers []netip.Prefix, udpPort, tcpPort int) string {
+	var buf strings.Builder
+	fmt.Fprintln(&buf, "*nat")
+	fmt.Fprintln(&buf, ":PREROUTING ACCEPT [0:0]")
+	fmt.Fprintln(&buf, ":INPUT ACCEPT [0:0]")
+	fmt.Fprintln(&buf, ":OUTPUT ACCEPT [0:0]")
+	fmt.Fprintln(&buf, ":POSTROUTING ACCEPT [0:0]")
+	for _, prefix := range nameservers {
+		if !prefix.Addr().Is4() {
+			continue
+		}
+		fmt.Fprintf(&buf, "-A OUTPUT -d %s -p udp --dport 53 -j REDIRECT --to-ports %d\n", prefix, udpPort)
+		fmt.Fprintf(&buf, "-A OUTPUT -d %s -p tcp --dport 53 -j REDIRECT --to-ports %d\n", prefix, tcpPort)
+	}
+	fmt.Fprintln(&buf, "COMMIT")
+	return buf.String()
+}
+
+// EgressAllowRules returns the input of iptables-restore --noflush (or
+// ip6tables-restore if ipv6 is set) letting the given policies of the rules
+// generated by EgressRules reach addrs. It's empty if no address is of the
+// requested family.
+func EgressAllowRules(policies []int, addrs []netip.Addr, ipv6 bool) string {
+	var buf strings.Builder
+	for _, addr := range addrs {
+		if addr.Is6() != ipv6 {
+			continue
+		}
+		for _, i := range policies {
+			fmt.Fprintf(&buf, "-I %s%d -d %s -j RETURN\n", egressChainPrefix, i, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	if buf.Len() == 0 {
+		return ""
+	}
+	return "*filter\n" + buf.String() + "COMMIT\n"
+}
+
+// ApplyEgressRules replaces the tables of the current network namespace
+// present in the given rules, generated by EgressRules and EgressDNSRules.
+func ApplyEgressRules(ctx context.Context, rules string, ipv6 bool) error {
+	return restoreEgressRules(ctx, rules, ipv6)
+}
+
+// AddEgressRules adds the given rules, generated by EgressAllowRules, to
+// the ones applied by ApplyEgressRules.
+func AddEgressRules(ctx context.Context, rules string, ipv6 bool) error {
+	if rules == "" {
+		return nil
+	}
+	return restoreEgressRules(ctx, rules, ipv6, "--noflush")
+}
+
+func restoreEgressRules(ctx context.Context, rules string, ipv6 bool, args ...string) error {
+	bin := "iptables-restore"
+	if ipv6 {
+		bin = "ip6tables-restore"
+	}
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdin = strings.NewReader(rules)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", bin, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// ResetEgressRules removes egress rules applied by ApplyEgressRules, so that
+// the network namespace can be reused.
+func ResetEgressRules(ctx context.Context, ipv6 bool) error {
+	if ipv6 {
+		return ApplyEgressRules(ctx, emptyFilterTable, ipv6)
+	}
+	return ApplyEgressRules(ctx, emptyFilterTable+emptyNATTable, ipv6)
+}
+
+const emptyFilterTable = `*filter
+:INPUT ACCEPT [0:0]
+:FORWARD ACCEPT [0:0]
+:OUTPUT ACCEPT [0:0]
+COMMIT
+`
+
+const emptyNATTable = `*nat
+:PREROUTING ACCEPT [0:0]
+:INPUT ACCEPT [0:0]
+:OUTPUT ACCEPT [0:0]
+:POSTROUTING ACCEPT [0:0]
+COMMIT
+`
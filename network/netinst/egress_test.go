@@ -0,0 +1,77 @@
+package netinst
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEgressRules(t *testing.T) {
+	t.Parallel()
+
+	allowed := [][]netip.Prefix{
+		{netip.MustParsePrefix("140.82.112.3/32"), netip.MustParsePrefix("2001:db8::1/128")},
+		nil,
+	}
+	local := []netip.Prefix{netip.MustParsePrefix("10.87.0.0/16")}
+
+	require.Equal(t, `*filter
+:INPUT ACCEPT [0:0]
+:FORWARD ACCEPT [0:0]
+:OUTPUT ACCEPT [0:0]
+:DAGGER-EGRESS-BLOCK - [0:0]
+:DAGGER-EGRESS-0 - [0:0]
+:DAGGER-EGRESS-1 - [0:0]
+-A OUTPUT -o lo -j ACCEPT
+-A OUTPUT -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT
+-A OUTPUT -d 10.87.0.0/16 -j ACCEPT
+-A OUTPUT -j DAGGER-EGRESS-0
+-A OUTPUT -j DAGGER-EGRESS-1
+-A DAGGER-EGRESS-0 -d 140.82.112.3/32 -j RETURN
+-A DAGGER-EGRESS-0 -j NFLOG --nflog-group 100 --nflog-prefix 0
+-A DAGGER-EGRESS-0 -j DAGGER-EGRESS-BLOCK
+-A DAGGER-EGRESS-1 -j NFLOG --nflog-group 100 --nflog-prefix 1
+-A DAGGER-EGRESS-1 -j DAGGER-EGRESS-BLOCK
+-A DAGGER-EGRESS-BLOCK -p tcp -j REJECT --reject-with tcp-reset
+-A DAGGER-EGRESS-BLOCK -j REJECT --reject-with icmp-admin-prohibited
+COMMIT
+`, EgressRules(allowed, local, false))
+
+	ipv6 := EgressRules(allowed, local, true)
+	require.Contains(t, ipv6, "-A DAGGER-EGRESS-0 -d 2001:db8::1/128 -j RETURN\n")
+	require.NotContains(t, ipv6, "140.82.112.3")
+	require.NotContains(t, ipv6, "10.87.0.0/16")
+	require.Contains(t, ipv6, "--reject-with icmp6-adm-prohibited\n")
+}
+
+func TestEgressDNSRules(t *testing.T) {
+	t.Parallel()
+
+	nameservers := []netip.Prefix{netip.MustParsePrefix("10.87.0.1/32"), netip.MustParsePrefix("fd00::1/128")}
+	require.Equal(t, `*nat
+:PREROUTING ACCEPT [0:0]
+:INPUT ACCEPT [0:0]
+:OUTPUT ACCEPT [0:0]
+:POSTROUTING ACCEPT [0:0]
+-A OUTPUT -d 10.87.0.1/32 -p udp --dport 53 -j REDIRECT --to-ports 5353
+-A OUTPUT -d 10.87.0.1/32 -p tcp --dport 53 -j REDIRECT --to-ports 5354
+COMMIT
+`, EgressDNSRules(nameservers, 5353, 5354))
+}
+
+func TestEgressAllowRules(t *testing.T) {
+	t.Parallel()
+
+	addrs := []netip.Addr{netip.MustParseAddr("140.82.112.3"), netip.MustParseAddr("2001:db8::1")}
+	require.Equal(t, `*filter
+-I DAGGER-EGRESS-0 -d 140.82.112.3/32 -j RETURN
+-I DAGGER-EGRESS-2 -d 140.82.112.3/32 -j RETURN
+COMMIT
+`, EgressAllowRules([]int{0, 2}, addrs, false))
+	require.Equal(t, `*filter
+-I DAGGER-EGRESS-1 -d 2001:db8::1/128 -j RETURN
+COMMIT
+`, EgressAllowRules([]int{1}, addrs, true))
+	require.Empty(t, EgressAllowRules([]int{0}, addrs[:1], true))
+}
@@ -0,0 +1,261 @@
+package netinst
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// egressDNSTimeout is how long a nameserver has to answer a query forwarded
+// by an EgressDNSProxy.
+const egressDNSTimeout = 5 * time.Second
+
+// egressDNSMaxMsgSize is the largest DNS message, over TCP.
+const egressDNSMaxMsgSize = 65535
+
+// EgressDNSProxy forwards the DNS queries of a container, redirected by the
+// rules generated by EgressDNSRules, to its nameservers.
+//
+// Before replying, it lets the policies allowing the queried domain reach the
+// addresses in the answer, so that the destinations allowed by a policy are
+// the ones resolved by the container itself rather than by the engine.
+type EgressDNSProxy struct {
+	// Nameservers are the nameservers of the container, tried in order.
+	Nameservers []netip.AddrPort
+	// Domains are the domains allowed by each policy.
+	Domains [][]string
+	// Allow lets the given policies reach addrs. The answer is sent to the
+	// container once it returns, even if it fails.
+	Allow func(ctx context.Context, policies []int, addrs []netip.Addr)
+
+	mu      sync.Mutex
+	allowed map[egressDNSAllowed]struct{}
+}
+
+type egressDNSAllowed struct {
+	policy int
+	addr   netip.Addr
+}
+
+// ServeUDP answers the queries received on conn until it's closed.
+func (p *EgressDNSProxy) ServeUDP(ctx context.Context, conn net.PacketConn) error {
+	buf := make([]byte, egressDNSMaxMsgSize)
+	for {
+		n, client, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		query := slices.Clone(buf[:n])
+		go func() {
+			answer, err := p.exchange(ctx, "udp", query)
+			if err != nil {
+				// the container's resolver will retry or time out
+				return
+			}
+			conn.WriteTo(answer, client)
+		}()
+	}
+}
+
+// ServeTCP answers the queries received on the connections accepted by l
+// until it's closed.
+func (p *EgressDNSProxy) ServeTCP(ctx context.Context, l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			for {
+				conn.SetReadDeadline(time.Now().Add(egressDNSTimeout))
+				query, err := readTCPMessage(conn)
+				if err != nil {
+					return
+				}
+				answer, err := p.exchange(ctx, "tcp", query)
+				if err != nil {
+					return
+				}
+				if err := writeTCPMessage(conn, answer); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// exchange forwards a query to the first nameserver answering it, and allows
+// the addresses in its answer.
+func (p *EgressDNSProxy) exchange(ctx context.Context, network string, query []byte) ([]byte, error) {
+	var errs error
+	for _, ns := range p.Nameservers {
+		answer, err := exchangeDNS(ctx, network, ns, query)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		p.allow(ctx, answer)
+		return answer, nil
+	}
+	if errs == nil {
+		return nil, errors.New("no nameserver")
+	}
+	return nil, errs
+}
+
+func (p *EgressDNSProxy) allow(ctx context.Context, answer []byte) {
+	name, addrs, err := answerAddrs(answer)
+	if err != nil || len(addrs) == 0 {
+		return
+	}
+	var policies []int
+	for i, domains := range p.Domains {
+		if slices.Contains(domains, name) {
+			policies = append(policies, i)
+		}
+	}
+	if len(policies) == 0 {
+		return
+	}
+
+	// only allow what hasn't been yet, so that repeated queries don't keep
+	// adding rules
+	p.mu.Lock()
+	if p.allowed == nil {
+		p.allowed = map[egressDNSAllowed]struct{}{}
+	}
+	var newAddrs []netip.Addr
+	for _, addr := range addrs {
+		for _, policy := range policies {
+			key := egressDNSAllowed{policy: policy, addr: addr}
+			if _, ok := p.allowed[key]; ok {
+				continue
+			}
+			p.allowed[key] = struct{}{}
+			if !slices.Contains(newAddrs, addr) {
+				newAddrs = append(newAddrs, addr)
+			}
+		}
+	}
+	p.mu.Unlock()
+	if len(newAddrs) > 0 {
+		p.Allow(ctx, policies, newAddrs)
+	}
+}
+
+func exchangeDNS(ctx context.Context, network string, ns netip.AddrPort, query []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, egressDNSTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, ns.String())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if network == "tcp" {
+		if err := writeTCPMessage(conn, query); err != nil {
+			return nil, err
+		}
+		return readTCPMessage(conn)
+	}
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, egressDNSMaxMsgSize)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// answerAddrs returns the domain queried by a DNS answer, and the IP
+// addresses it resolved to, following CNAMEs.
+func answerAddrs(msg []byte) (string, []netip.Addr, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(msg)
+	if err != nil {
+		return "", nil, err
+	}
+	if !header.Response || header.RCode != dnsmessage.RCodeSuccess {
+		return "", nil, nil
+	}
+	question, err := parser.Question()
+	if err != nil {
+		return "", nil, err
+	}
+	if err := parser.SkipAllQuestions(); err != nil {
+		return "", nil, err
+	}
+	var addrs []netip.Addr
+	for {
+		rh, err := parser.AnswerHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		switch rh.Type {
+		case dnsmessage.TypeA:
+			r, err := parser.AResource()
+			if err != nil {
+				return "", nil, err
+			}
+			addrs = append(addrs, netip.AddrFrom4(r.A))
+		case dnsmessage.TypeAAAA:
+			r, err := parser.AAAAResource()
+			if err != nil {
+				return "", nil, err
+			}
+			addrs = append(addrs, netip.AddrFrom16(r.AAAA).Unmap())
+		default:
+			if err := parser.SkipAnswer(); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	name := strings.ToLower(strings.TrimSuffix(question.Name.String(), "."))
+	return name, addrs, nil
+}
+
+func readTCPMessage(r io.Reader) ([]byte, error) {
+	var size uint16
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func writeTCPMessage(w io.Writer, msg []byte) error {
+	if len(msg) > egressDNSMaxMsgSize {
+		return fmt.Errorf("DNS message too large: %d bytes", len(msg))
+	}
+	buf := binary.BigEndian.AppendUint16(make([]byte, 0, 2+len(msg)), uint16(len(msg)))
+	_, err := w.Write(append(buf, msg...))
+	return err
+}
@@ -0,0 +1,171 @@
+package netinst
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestEgressDNSProxy(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	// the nameserver rotates the addresses it answers with
+	nameserver, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { nameserver.Close() })
+	go func() {
+		buf := make([]byte, egressDNSMaxMsgSize)
+		for i := byte(1); ; i++ {
+			n, client, err := nameserver.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			nameserver.WriteTo(testDNSAnswer(t, buf[:n], [4]byte{192, 0, 2, i}), client)
+		}
+	}()
+
+	type allowed struct {
+		policies []int
+		addrs    []netip.Addr
+	}
+	var mu sync.Mutex
+	var allows []allowed
+	proxy := &EgressDNSProxy{
+		Nameservers: []netip.AddrPort{nameserver.LocalAddr().(*net.UDPAddr).AddrPort()},
+		Domains:     [][]string{{"example.com"}, {"github.com", "example.com"}, {"github.com"}},
+		Allow: func(_ context.Context, policies []int, addrs []netip.Addr) {
+			mu.Lock()
+			defer mu.Unlock()
+			allows = append(allows, allowed{policies: policies, addrs: addrs})
+		},
+	}
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	go proxy.ServeUDP(ctx, conn)
+
+	resolve := func(name string) netip.Addr {
+		t.Helper()
+		client, err := net.Dial("udp4", conn.LocalAddr().String())
+		require.NoError(t, err)
+		defer client.Close()
+		_, err = client.Write(testDNSQuery(t, name))
+		require.NoError(t, err)
+		buf := make([]byte, egressDNSMaxMsgSize)
+		n, err := client.Read(buf)
+		require.NoError(t, err)
+		resolved, addrs, err := answerAddrs(buf[:n])
+		require.NoError(t, err)
+		require.Equal(t, strings.ToLower(name), resolved)
+		require.Len(t, addrs, 1)
+		return addrs[0]
+	}
+
+	first := resolve("Example.COM")
+	second := resolve("example.com")
+	require.NotEqual(t, first, second)
+	resolve("dagger.io")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []allowed{
+		{policies: []int{0, 1}, addrs: []netip.Addr{first}},
+		{policies: []int{0, 1}, addrs: []netip.Addr{second}},
+	}, allows)
+}
+
+func TestEgressDNSProxyTCP(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	nameserver, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { nameserver.Close() })
+	go func() {
+		for {
+			conn, err := nameserver.Accept()
+			if err != nil {
+				return
+			}
+			query, err := readTCPMessage(conn)
+			if err == nil {
+				writeTCPMessage(conn, testDNSAnswer(t, query, [4]byte{192, 0, 2, 1}))
+			}
+			conn.Close()
+		}
+	}()
+
+	var allowedAddrs []netip.Addr
+	proxy := &EgressDNSProxy{
+		Nameservers: []netip.AddrPort{nameserver.Addr().(*net.TCPAddr).AddrPort()},
+		Domains:     [][]string{{"example.com"}},
+		Allow: func(_ context.Context, _ []int, addrs []netip.Addr) {
+			allowedAddrs = append(allowedAddrs, addrs...)
+		},
+	}
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go proxy.ServeTCP(ctx, l)
+
+	client, err := net.Dial("tcp4", l.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+	require.NoError(t, writeTCPMessage(client, testDNSQuery(t, "example.com")))
+	answer, err := readTCPMessage(client)
+	require.NoError(t, err)
+	_, addrs, err := answerAddrs(answer)
+	require.NoError(t, err)
+	require.Equal(t, []netip.Addr{netip.MustParseAddr("192.0.2.1")}, addrs)
+	// the addresses are allowed before the answer is sent
+	require.Equal(t, addrs, allowedAddrs)
+}
+
+func testDNSQuery(t *testing.T, name string) []byte {
+	t.Helper()
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 1, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName(name + "."),
+			Type:  dnsmessage.TypeA,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	buf, err := msg.Pack()
+	require.NoError(t, err)
+	return buf
+}
+
+func testDNSAnswer(t *testing.T, query []byte, addr [4]byte) []byte {
+	t.Helper()
+	var msg dnsmessage.Message
+	require.NoError(t, msg.Unpack(query))
+	msg.Response = true
+	msg.Answers = []dnsmessage.Resource{{
+		Header: dnsmessage.ResourceHeader{
+			Name:  dnsmessage.MustNewName("cdn.example.net."),
+			Type:  dnsmessage.TypeCNAME,
+			Class: dnsmessage.ClassINET,
+			TTL:   60,
+		},
+		Body: &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("edge.example.net.")},
+	}, {
+		Header: dnsmessage.ResourceHeader{
+			Name:  dnsmessage.MustNewName("edge.example.net."),
+			Type:  dnsmessage.TypeA,
+			Class: dnsmessage.ClassINET,
+			TTL:   60,
+		},
+		Body: &dnsmessage.AResource{A: addr},
+	}}
+	buf, err := msg.Pack()
+	require.NoError(t, err)
+	return buf
+}
@@ -0,0 +1,231 @@
+package netinst
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// The parts of the nfnetlink_log protocol used to read logged packets, from
+// linux/netfilter/nfnetlink_log.h.
+const (
+	nfulnlMsgPacket = unix.NFNL_SUBSYS_ULOG<<8 | 0
+	nfulnlMsgConfig = unix.NFNL_SUBSYS_ULOG<<8 | 1
+
+	nfulaCfgCmd  = 1
+	nfulaCfgMode = 2
+
+	nfulnlCfgCmdBind = 1
+	nfulnlCopyPacket = 2
+
+	nfulaPayload = 9
+	nfulaPrefix  = 10
+
+	// enough for the IP header and the ports of TCP and UDP
+	nflogCopyRange = 128
+)
+
+// BlockedConn is a connection blocked by egress rules.
+type BlockedConn struct {
+	// Policy is the index of the policy that blocked the connection, in the
+	// policies passed to EgressRules.
+	Policy int
+	// Dest is the destination of the connection.
+	Dest netip.Addr
+	// Port is the destination port, for TCP and UDP.
+	Port uint16
+	// Protocol is the name of the IP protocol, like "tcp".
+	Protocol string
+}
+
+// EgressLog reads the connections blocked by egress rules.
+type EgressLog struct {
+	f   *os.File
+	seq atomic.Uint32
+	buf []byte
+}
+
+// OpenEgressLog subscribes to the connections logged to EgressLogGroup in the
+// current network namespace.
+func OpenEgressLog() (*EgressLog, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC|unix.SOCK_NONBLOCK, unix.NETLINK_NETFILTER)
+	if err != nil {
+		return nil, fmt.Errorf("open netfilter socket: %w", err)
+	}
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("bind netfilter socket: %w", err)
+	}
+	l := &EgressLog{
+		f:   os.NewFile(uintptr(fd), "nflog"),
+		buf: make([]byte, 64*1024),
+	}
+
+	mode := make([]byte, 6)
+	binary.BigEndian.PutUint32(mode, nflogCopyRange)
+	mode[4] = nfulnlCopyPacket
+	for _, attr := range []struct {
+		typ  uint16
+		data []byte
+	}{
+		{nfulaCfgCmd, []byte{nfulnlCfgCmdBind}},
+		{nfulaCfgMode, mode},
+	} {
+		if err := l.configure(attr.typ, attr.data); err != nil {
+			l.Close()
+			return nil, fmt.Errorf("configure nflog group %d: %w", EgressLogGroup, err)
+		}
+	}
+	return l, nil
+}
+
+// Read returns the next blocked connection, waiting for one if needed.
+func (l *EgressLog) Read() (BlockedConn, error) {
+	for {
+		n, err := l.f.Read(l.buf)
+		if err != nil {
+			return BlockedConn{}, err
+		}
+		msgs, err := syscall.ParseNetlinkMessage(l.buf[:n])
+		if err != nil {
+			continue
+		}
+		for _, msg := range msgs {
+			if msg.Header.Type != nfulnlMsgPacket {
+				continue
+			}
+			if conn, ok := parseBlockedConn(msg.Data); ok {
+				return conn, nil
+			}
+		}
+	}
+}
+
+// Close stops reading blocked connections, interrupting Read.
+func (l *EgressLog) Close() error {
+	return l.f.Close()
+}
+
+// configure sends a config message for EgressLogGroup and waits for the
+// kernel to acknowledge it.
+func (l *EgressLog) configure(typ uint16, data []byte) error {
+	seq := l.seq.Add(1)
+	attrLen := unix.SizeofNlAttr + len(data)
+	msgLen := unix.SizeofNlMsghdr + 4 + nlAlign(attrLen)
+	msg := make([]byte, msgLen)
+	binary.NativeEndian.PutUint32(msg[0:], uint32(msgLen))
+	binary.NativeEndian.PutUint16(msg[4:], nfulnlMsgConfig)
+	binary.NativeEndian.PutUint16(msg[6:], unix.NLM_F_REQUEST|unix.NLM_F_ACK)
+	binary.NativeEndian.PutUint32(msg[8:], seq)
+	// nfgenmsg: family, version, group (big-endian)
+	msg[16] = unix.AF_UNSPEC
+	msg[17] = unix.NFNETLINK_V0
+	binary.BigEndian.PutUint16(msg[18:], EgressLogGroup)
+	binary.NativeEndian.PutUint16(msg[20:], uint16(attrLen))
+	binary.NativeEndian.PutUint16(msg[22:], typ)
+	copy(msg[24:], data)
+	if _, err := l.f.Write(msg); err != nil {
+		return err
+	}
+
+	for {
+		n, err := l.f.Read(l.buf)
+		if err != nil {
+			return err
+		}
+		msgs, err := syscall.ParseNetlinkMessage(l.buf[:n])
+		if err != nil {
+			return err
+		}
+		for _, reply := range msgs {
+			if reply.Header.Type != unix.NLMSG_ERROR || reply.Header.Seq != seq {
+				continue
+			}
+			if len(reply.Data) < 4 {
+				return errors.New("short netlink ack")
+			}
+			if errno := -int32(binary.NativeEndian.Uint32(reply.Data)); errno != 0 {
+				return syscall.Errno(errno)
+			}
+			return nil
+		}
+	}
+}
+
+// parseBlockedConn parses a packet message of nfnetlink_log.
+func parseBlockedConn(data []byte) (BlockedConn, bool) {
+	if len(data) < 4 {
+		return BlockedConn{}, false
+	}
+	var conn BlockedConn
+	var prefix string
+	var payload []byte
+	for attrs := data[4:]; len(attrs) >= unix.SizeofNlAttr; {
+		attrLen := int(binary.NativeEndian.Uint16(attrs))
+		typ := binary.NativeEndian.Uint16(attrs[2:]) &^ (unix.NLA_F_NESTED | unix.NLA_F_NET_BYTEORDER)
+		if attrLen < unix.SizeofNlAttr || attrLen > len(attrs) {
+			break
+		}
+		value := attrs[unix.SizeofNlAttr:attrLen]
+		switch typ {
+		case nfulaPrefix:
+			prefix = strings.TrimRight(string(value), "\x00")
+		case nfulaPayload:
+			payload = value
+		}
+		attrs = attrs[min(nlAlign(attrLen), len(attrs)):]
+	}
+
+	policy, err := strconv.Atoi(prefix)
+	if err != nil {
+		return BlockedConn{}, false
+	}
+	conn.Policy = policy
+
+	var proto uint8
+	var transport []byte
+	switch {
+	case len(payload) >= 20 && payload[0]>>4 == 4:
+		headerLen := int(payload[0]&0x0f) * 4
+		conn.Dest = netip.AddrFrom4([4]byte(payload[16:20]))
+		proto = payload[9]
+		if headerLen <= len(payload) {
+			transport = payload[headerLen:]
+		}
+	case len(payload) >= 40 && payload[0]>>4 == 6:
+		conn.Dest = netip.AddrFrom16([16]byte(payload[24:40]))
+		proto = payload[6]
+		transport = payload[40:]
+	default:
+		return BlockedConn{}, false
+	}
+
+	switch proto {
+	case unix.IPPROTO_TCP:
+		conn.Protocol = "tcp"
+	case unix.IPPROTO_UDP:
+		conn.Protocol = "udp"
+	case unix.IPPROTO_ICMP:
+		conn.Protocol = "icmp"
+	case unix.IPPROTO_ICMPV6:
+		conn.Protocol = "icmpv6"
+	default:
+		conn.Protocol = strconv.Itoa(int(proto))
+	}
+	if (proto == unix.IPPROTO_TCP || proto == unix.IPPROTO_UDP) && len(transport) >= 4 {
+		conn.Port = binary.BigEndian.Uint16(transport[2:4])
+	}
+	return conn, true
+}
+
+func nlAlign(n int) int {
+	return (n + unix.NLA_ALIGNTO - 1) &^ (unix.NLA_ALIGNTO - 1)
+}
@@ -0,0 +1,48 @@
+package netinst
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseBlockedConn(t *testing.T) {
+	t.Parallel()
+
+	attr := func(typ uint16, value []byte) []byte {
+		b := make([]byte, nlAlign(unix.SizeofNlAttr+len(value)))
+		binary.NativeEndian.PutUint16(b, uint16(unix.SizeofNlAttr+len(value)))
+		binary.NativeEndian.PutUint16(b[2:], typ)
+		copy(b[unix.SizeofNlAttr:], value)
+		return b
+	}
+
+	// an IPv4 TCP SYN to 140.82.112.3:443
+	packet := make([]byte, 24)
+	packet[0] = 0x45
+	packet[9] = unix.IPPROTO_TCP
+	copy(packet[16:], []byte{140, 82, 112, 3})
+	binary.BigEndian.PutUint16(packet[22:], 443)
+
+	msg := []byte{unix.AF_INET, unix.NFNETLINK_V0, 0, EgressLogGroup}
+	msg = append(msg, attr(nfulaPrefix, []byte("1\x00"))...)
+	msg = append(msg, attr(nfulaPayload, packet)...)
+
+	conn, ok := parseBlockedConn(msg)
+	require.True(t, ok)
+	require.Equal(t, BlockedConn{
+		Policy:   1,
+		Dest:     netip.MustParseAddr("140.82.112.3"),
+		Port:     443,
+		Protocol: "tcp",
+	}, conn)
+
+	// packets logged by other rules are ignored
+	msg = []byte{unix.AF_INET, unix.NFNETLINK_V0, 0, EgressLogGroup}
+	msg = append(msg, attr(nfulaPayload, packet)...)
+	_, ok = parseBlockedConn(msg)
+	require.False(t, ok)
+}
@@ -64,6 +64,14 @@ func Changeset() *dagger.Changeset {
 	return client.Changeset()
 }
 
+// Create a finding, for a lint check to report a problem.
+//
+// A check function returning a list of findings fails if any of them is an error.
+func CheckFinding(message string, opts ...dagger.CheckFindingOpts) *dagger.CheckFinding {
+	client := initClient()
+	return client.CheckFinding(message, opts...)
+}
+
 // Dagger Cloud configuration and state
 func Cloud() *dagger.Cloud {
 	client := initClient()
@@ -985,13 +985,16 @@ func (r *Changeset) AsSyncer() Syncer {
 type Check struct {
 	query *querybuilder.Selection
 
+	attempts    *int
 	checkType   *string
 	completed   *bool
 	description *string
+	flaky       *bool
 	id          *ID
 	name        *string
 	passed      *bool
 	resultEmoji *string
+	retries     *int
 }
 type WithCheckFunc func(r *Check) *Check
 
@@ -1008,6 +1011,31 @@ func (r *Check) WithGraphQLQuery(q *querybuilder.Selection) *Check {
 	}
 }
 
+// How many times the check ran, counting retries, or 0 if it hasn't run
+func (r *Check) Attempts(ctx context.Context) (int, error) {
+	if r.attempts != nil {
+		return *r.attempts, nil
+	}
+	q := r.query.Select("attempts")
+
+	var response int
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The paths changed by the given changes that the check reads
+func (r *Check) ChangedInputs(ctx context.Context, changes *Changeset) ([]string, error) {
+	assertNotNil("changes", changes)
+	q := r.query.Select("changedInputs")
+	q = q.Arg("changes", changes)
+
+	var response []string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // The type of check: 'check' for annotated checks, 'generate' for generate-as-checks
 func (r *Check) CheckType(ctx context.Context) (string, error) {
 	if r.checkType != nil {
@@ -1064,6 +1092,52 @@ func (r *Check) Error(ctx context.Context) (*Error, error) {
 	}, nil
 }
 
+// The findings reported by the check, if its function returns a list of findings
+func (r *Check) Findings(ctx context.Context) ([]CheckFinding, error) {
+	q := r.query.Select("findings")
+
+	q = q.Select("id")
+
+	type findings struct {
+		Id ID
+	}
+
+	convert := func(fields []findings) []CheckFinding {
+		out := []CheckFinding{}
+
+		for i := range fields {
+			val := CheckFinding{id: &fields[i].Id}
+			val.query = selectNode(q.Root(), fields[i].Id, "CheckFinding")
+			out = append(out, val)
+		}
+
+		return out
+	}
+	var response []findings
+
+	q = q.Bind(&response)
+
+	err := q.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert(response), nil
+}
+
+// Whether the check failed at first but passed on a retry
+func (r *Check) Flaky(ctx context.Context) (bool, error) {
+	if r.flaky != nil {
+		return *r.flaky, nil
+	}
+	q := r.query.Select("flaky")
+
+	var response bool
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // A unique identifier for this Check.
 func (r *Check) ID(ctx context.Context) (ID, error) {
 	if r.id != nil {
@@ -1104,6 +1178,39 @@ func (r *Check) MarshalJSON() ([]byte, error) {
 	return json.Marshal(id)
 }
 
+// The parts of the workspace the check reads, as far as can be told without running it
+func (r *Check) Inputs(ctx context.Context) ([]CheckInput, error) {
+	q := r.query.Select("inputs")
+
+	q = q.Select("id")
+
+	type inputs struct {
+		Id ID
+	}
+
+	convert := func(fields []inputs) []CheckInput {
+		out := []CheckInput{}
+
+		for i := range fields {
+			val := CheckInput{id: &fields[i].Id}
+			val.query = selectNode(q.Root(), fields[i].Id, "CheckInput")
+			out = append(out, val)
+		}
+
+		return out
+	}
+	var response []inputs
+
+	q = q.Bind(&response)
+
+	err := q.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert(response), nil
+}
+
 // Return the fully qualified name of the check
 func (r *Check) Name(ctx context.Context) (string, error) {
 	if r.name != nil {
@@ -1162,6 +1269,19 @@ func (r *Check) ResultEmoji(ctx context.Context) (string, error) {
 	return response, q.Execute(ctx)
 }
 
+// How many times the check is retried when it fails, before failing it
+func (r *Check) Retries(ctx context.Context) (int, error) {
+	if r.retries != nil {
+		return *r.retries, nil
+	}
+	q := r.query.Select("retries")
+
+	var response int
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // Execute the check
 func (r *Check) Run() *Check {
 	q := r.query.Select("run")
@@ -1179,6 +1299,151 @@ func (r *Check) AsNode() Node {
 	}
 }
 
+// A problem reported by a lint check.
+type CheckFinding struct {
+	query *querybuilder.Selection
+
+	column  *int
+	id      *ID
+	level   *CheckFindingLevel
+	line    *int
+	message *string
+	path    *string
+	rule    *string
+}
+
+func (r *CheckFinding) WithGraphQLQuery(q *querybuilder.Selection) *CheckFinding {
+	return &CheckFinding{
+		query: q,
+	}
+}
+
+// The column of the problem, starting at 1, or 0 if unknown.
+func (r *CheckFinding) Column(ctx context.Context) (int, error) {
+	if r.column != nil {
+		return *r.column, nil
+	}
+	q := r.query.Select("column")
+
+	var response int
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// A unique identifier for this CheckFinding.
+func (r *CheckFinding) ID(ctx context.Context) (ID, error) {
+	if r.id != nil {
+		return *r.id, nil
+	}
+	q := r.query.Select("id")
+
+	var response ID
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// XXX_GraphQLType is an internal function. It returns the native GraphQL type name
+func (r *CheckFinding) XXX_GraphQLType() string {
+	return "CheckFinding"
+}
+
+// XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
+func (r *CheckFinding) XXX_GraphQLIDType() string {
+	return "ID"
+}
+
+// XXX_GraphQLID is an internal function. It returns the underlying type ID
+func (r *CheckFinding) XXX_GraphQLID(ctx context.Context) (string, error) {
+	id, err := r.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(id), nil
+}
+
+func (r *CheckFinding) MarshalJSON() ([]byte, error) {
+	id, err := r.ID(marshalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(id)
+}
+
+// How serious the problem is.
+func (r *CheckFinding) Level(ctx context.Context) (CheckFindingLevel, error) {
+	if r.level != nil {
+		return *r.level, nil
+	}
+	q := r.query.Select("level")
+
+	var response CheckFindingLevel
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The line of the problem, starting at 1, or 0 if unknown.
+func (r *CheckFinding) Line(ctx context.Context) (int, error) {
+	if r.line != nil {
+		return *r.line, nil
+	}
+	q := r.query.Select("line")
+
+	var response int
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// A description of the problem.
+func (r *CheckFinding) Message(ctx context.Context) (string, error) {
+	if r.message != nil {
+		return *r.message, nil
+	}
+	q := r.query.Select("message")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The path of the file with the problem, relative to the root of the checked source, if any.
+func (r *CheckFinding) Path(ctx context.Context) (string, error) {
+	if r.path != nil {
+		return *r.path, nil
+	}
+	q := r.query.Select("path")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The identifier of the rule that found the problem, if any.
+func (r *CheckFinding) Rule(ctx context.Context) (string, error) {
+	if r.rule != nil {
+		return *r.rule, nil
+	}
+	q := r.query.Select("rule")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// AsNode returns this CheckFinding as a Node.
+// This is a local type conversion — no GraphQL call.
+func (r *CheckFinding) AsNode() Node {
+	return &NodeClient{
+		query: r.query,
+	}
+}
+
 type CheckGroup struct {
 	query *querybuilder.Selection
 
@@ -1199,6 +1464,17 @@ func (r *CheckGroup) WithGraphQLQuery(q *querybuilder.Selection) *CheckGroup {
 	}
 }
 
+// Return only the checks that the given changes can affect, going by the inputs of each check.
+func (r *CheckGroup) AffectedBy(changes *Changeset) *CheckGroup {
+	assertNotNil("changes", changes)
+	q := r.query.Select("affectedBy")
+	q = q.Arg("changes", changes)
+
+	return &CheckGroup{
+		query: q,
+	}
+}
+
 // A unique identifier for this CheckGroup.
 func (r *CheckGroup) ID(ctx context.Context) (ID, error) {
 	if r.id != nil {
@@ -1272,9 +1548,23 @@ func (r *CheckGroup) List(ctx context.Context) ([]Check, error) {
 	return convert(response), nil
 }
 
-// Generate a markdown report
-func (r *CheckGroup) Report() *File {
+// CheckGroupReportOpts contains options for CheckGroup.Report
+type CheckGroupReportOpts struct {
+	// The format of the report.
+	//
+	// Default: MARKDOWN
+	Format CheckReportFormat
+}
+
+// Generate a report of the checks and their results
+func (r *CheckGroup) Report(opts ...CheckGroupReportOpts) *File {
 	q := r.query.Select("report")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `format` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Format) {
+			q = q.Arg("format", opts[i].Format)
+		}
+	}
 
 	return &File{
 		query: q,
@@ -1302,6 +1592,33 @@ func (r *CheckGroup) Run(opts ...CheckGroupRunOpts) *CheckGroup {
 	}
 }
 
+// CheckGroupShardOpts contains options for CheckGroup.Shard
+type CheckGroupShardOpts struct {
+	// A JSON file of how long each check took before, as written by 'dagger check --shard-timings', to balance the shards by duration.
+	//
+	// Without it, the shards get the same number of checks.
+	Timings *File
+}
+
+// Return only the checks in one shard of the group, to split the checks across the jobs of a CI run.
+//
+// The split is deterministic: every job computes the same one from the same checks and timings.
+func (r *CheckGroup) Shard(index int, total int, opts ...CheckGroupShardOpts) *CheckGroup {
+	q := r.query.Select("shard")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `timings` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Timings) {
+			q = q.Arg("timings", opts[i].Timings)
+		}
+	}
+	q = q.Arg("index", index)
+	q = q.Arg("total", total)
+
+	return &CheckGroup{
+		query: q,
+	}
+}
+
 // AsNode returns this CheckGroup as a Node.
 // This is a local type conversion — no GraphQL call.
 func (r *CheckGroup) AsNode() Node {
@@ -1310,6 +1627,105 @@ func (r *CheckGroup) AsNode() Node {
 	}
 }
 
+// A part of the workspace that a check reads.
+type CheckInput struct {
+	query *querybuilder.Selection
+
+	id     *ID
+	path   *string
+	reason *string
+}
+
+func (r *CheckInput) WithGraphQLQuery(q *querybuilder.Selection) *CheckInput {
+	return &CheckInput{
+		query: q,
+	}
+}
+
+// Patterns of paths under the path that the check doesn't read, from the +ignore of its argument.
+func (r *CheckInput) Exclude(ctx context.Context) ([]string, error) {
+	q := r.query.Select("exclude")
+
+	var response []string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// A unique identifier for this CheckInput.
+func (r *CheckInput) ID(ctx context.Context) (ID, error) {
+	if r.id != nil {
+		return *r.id, nil
+	}
+	q := r.query.Select("id")
+
+	var response ID
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// XXX_GraphQLType is an internal function. It returns the native GraphQL type name
+func (r *CheckInput) XXX_GraphQLType() string {
+	return "CheckInput"
+}
+
+// XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
+func (r *CheckInput) XXX_GraphQLIDType() string {
+	return "ID"
+}
+
+// XXX_GraphQLID is an internal function. It returns the underlying type ID
+func (r *CheckInput) XXX_GraphQLID(ctx context.Context) (string, error) {
+	id, err := r.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(id), nil
+}
+
+func (r *CheckInput) MarshalJSON() ([]byte, error) {
+	id, err := r.ID(marshalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(id)
+}
+
+// The path the check reads, relative to the workspace root. A directory includes everything under it.
+func (r *CheckInput) Path(ctx context.Context) (string, error) {
+	if r.path != nil {
+		return *r.path, nil
+	}
+	q := r.query.Select("path")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// Why the check reads the path.
+func (r *CheckInput) Reason(ctx context.Context) (string, error) {
+	if r.reason != nil {
+		return *r.reason, nil
+	}
+	q := r.query.Select("reason")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// AsNode returns this CheckInput as a Node.
+// This is a local type conversion — no GraphQL call.
+func (r *CheckInput) AsNode() Node {
+	return &NodeClient{
+		query: r.query,
+	}
+}
+
 // An internal persistent filesync mirror.
 type ClientFilesyncMirror struct {
 	query *querybuilder.Selection
@@ -1501,6 +1917,10 @@ type ContainerAsServiceOpts struct {
 	//
 	// This should only be used if the user requires that their exec process be the pid 1 process in the container. Otherwise it may result in unexpected behavior.
 	NoInit bool
+	// Command to execute in the service when a secret set with withSecretVariable expires (e.g. ["nginx", "-s", "reload"]).
+	//
+	// Secrets expire when their provider reports a TTL, such as a Vault lease or an AWS Secrets Manager rotation. They are then resolved again and passed to this command in its environment. If empty, the service keeps running with the expired values.
+	SecretRefreshCommand []string
 }
 
 // Turn the container into a Service.
@@ -1533,6 +1953,10 @@ func (r *Container) AsService(opts ...ContainerAsServiceOpts) *Service {
 		if !querybuilder.IsZeroValue(opts[i].NoInit) {
 			q = q.Arg("noInit", opts[i].NoInit)
 		}
+		// `secretRefreshCommand` optional argument
+		if !querybuilder.IsZeroValue(opts[i].SecretRefreshCommand) {
+			q = q.Arg("secretRefreshCommand", opts[i].SecretRefreshCommand)
+		}
 	}
 
 	return &Service{
@@ -1556,6 +1980,10 @@ type ContainerAsTarballOpts struct {
 	//
 	// Default: OCIMediaTypes
 	MediaTypes ImageMediaTypes
+	// Attach a software bill of materials for each platform, generated from the package databases of its root filesystem, in the given format.
+	Sbom ImageSBOMFormat
+	// Attach a SLSA provenance attestation for each platform, describing the calls that built it and the images, git repositories and HTTP resources it was built from.
+	Provenance bool
 }
 
 // Package the container state as an OCI image, and return it as a tar archive
@@ -1574,6 +2002,14 @@ func (r *Container) AsTarball(opts ...ContainerAsTarballOpts) *File {
 		if !querybuilder.IsZeroValue(opts[i].MediaTypes) {
 			q = q.Arg("mediaTypes", opts[i].MediaTypes)
 		}
+		// `sbom` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Sbom) {
+			q = q.Arg("sbom", opts[i].Sbom)
+		}
+		// `provenance` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Provenance) {
+			q = q.Arg("provenance", opts[i].Provenance)
+		}
 	}
 
 	return &File{
@@ -1802,6 +2238,10 @@ type ContainerExportOpts struct {
 	MediaTypes ImageMediaTypes
 	// Replace "${VAR}" or "$VAR" in the value of path according to the current environment variables defined in the container (e.g. "/$VAR/foo").
 	Expand bool
+	// Attach a software bill of materials for each platform, generated from the package databases of its root filesystem, in the given format.
+	Sbom ImageSBOMFormat
+	// Attach a SLSA provenance attestation for each platform, describing the calls that built it and the images, git repositories and HTTP resources it was built from.
+	Provenance bool
 }
 
 // Writes the container as an OCI tarball to the destination file path on the host.
@@ -1829,6 +2269,14 @@ func (r *Container) Export(ctx context.Context, path string, opts ...ContainerEx
 		if !querybuilder.IsZeroValue(opts[i].Expand) {
 			q = q.Arg("expand", opts[i].Expand)
 		}
+		// `sbom` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Sbom) {
+			q = q.Arg("sbom", opts[i].Sbom)
+		}
+		// `provenance` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Provenance) {
+			q = q.Arg("provenance", opts[i].Provenance)
+		}
 	}
 	q = q.Arg("path", path)
 
@@ -1952,6 +2400,14 @@ type ContainerFromOpts struct {
 	Protocol RegistryProtocol
 	// Allow HTTPS registry communication without verifying the server certificate.
 	InsecureSkipTLSVerify bool
+	// PEM-encoded public key the image must be signed with.
+	//
+	// Signatures are looked up as cosign signatures attached to the image as OCI referrers, as pushed by "cosign sign" or Container.publish.
+	SignatureKey string
+	// Fail if the image has no signature of its digest.
+	//
+	// Requires signatureKey, which already makes a signature by that key mandatory.
+	RequireSignature bool
 }
 
 // Download a container image, and apply it to the container state. All previous state will be lost.
@@ -1970,6 +2426,14 @@ func (r *Container) From(address string, opts ...ContainerFromOpts) *Container {
 		if !querybuilder.IsZeroValue(opts[i].InsecureSkipTLSVerify) {
 			q = q.Arg("insecureSkipTLSVerify", opts[i].InsecureSkipTLSVerify)
 		}
+		// `signatureKey` optional argument
+		if !querybuilder.IsZeroValue(opts[i].SignatureKey) {
+			q = q.Arg("signatureKey", opts[i].SignatureKey)
+		}
+		// `requireSignature` optional argument
+		if !querybuilder.IsZeroValue(opts[i].RequireSignature) {
+			q = q.Arg("requireSignature", opts[i].RequireSignature)
+		}
 	}
 	q = q.Arg("address", address)
 
@@ -2213,6 +2677,16 @@ type ContainerPublishOpts struct {
 	Protocol RegistryProtocol
 	// Allow HTTPS registry communication without verifying the server certificate.
 	InsecureSkipTLSVerify bool
+	// Attach a software bill of materials for each platform, generated from the package databases of its root filesystem, in the given format.
+	Sbom ImageSBOMFormat
+	// Attach a SLSA provenance attestation for each platform, describing the calls that built it and the images, git repositories and HTTP resources it was built from.
+	Provenance bool
+	// PEM-encoded private key to sign the published image with.
+	//
+	// The signature is in the cosign format and is pushed as an OCI referrer of the image, so "cosign verify" and Container.from can check it. Encrypted keys generated by "cosign generate-key-pair" are supported.
+	SigningKey *Secret
+	// Password to decrypt the signing key with.
+	SigningKeyPassword *Secret
 }
 
 // Package the container state as an OCI image, and publish it to a registry
@@ -2248,6 +2722,22 @@ func (r *Container) Publish(ctx context.Context, address string, opts ...Contain
 		if !querybuilder.IsZeroValue(opts[i].InsecureSkipTLSVerify) {
 			q = q.Arg("insecureSkipTLSVerify", opts[i].InsecureSkipTLSVerify)
 		}
+		// `sbom` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Sbom) {
+			q = q.Arg("sbom", opts[i].Sbom)
+		}
+		// `provenance` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Provenance) {
+			q = q.Arg("provenance", opts[i].Provenance)
+		}
+		// `signingKey` optional argument
+		if !querybuilder.IsZeroValue(opts[i].SigningKey) {
+			q = q.Arg("signingKey", opts[i].SigningKey)
+		}
+		// `signingKeyPassword` optional argument
+		if !querybuilder.IsZeroValue(opts[i].SigningKeyPassword) {
+			q = q.Arg("signingKeyPassword", opts[i].SigningKeyPassword)
+		}
 	}
 	q = q.Arg("address", address)
 
@@ -2398,6 +2888,10 @@ type ContainerUpOpts struct {
 	//
 	// This should only be used if the user requires that their exec process be the pid 1 process in the container. Otherwise it may result in unexpected behavior.
 	NoInit bool
+	// Command to execute in the service when a secret set with withSecretVariable expires (e.g. ["nginx", "-s", "reload"]).
+	//
+	// Secrets expire when their provider reports a TTL, such as a Vault lease or an AWS Secrets Manager rotation. They are then resolved again and passed to this command in its environment. If empty, the service keeps running with the expired values.
+	SecretRefreshCommand []string
 }
 
 // Starts a Service and creates a tunnel that forwards traffic from the caller's network to that service.
@@ -2441,6 +2935,10 @@ func (r *Container) Up(ctx context.Context, opts ...ContainerUpOpts) error {
 		if !querybuilder.IsZeroValue(opts[i].NoInit) {
 			q = q.Arg("noInit", opts[i].NoInit)
 		}
+		// `secretRefreshCommand` optional argument
+		if !querybuilder.IsZeroValue(opts[i].SecretRefreshCommand) {
+			q = q.Arg("secretRefreshCommand", opts[i].SecretRefreshCommand)
+		}
 	}
 
 	return q.Execute(ctx)
@@ -2480,6 +2978,16 @@ func (r *Container) WithDefaultArgs(args []string) *Container {
 	}
 }
 
+// Set the default timeout for commands executed with withExec.
+func (r *Container) WithDefaultExecTimeout(timeout string) *Container {
+	q := r.query.Select("withDefaultExecTimeout")
+	q = q.Arg("timeout", timeout)
+
+	return &Container{
+		query: q,
+	}
+}
+
 // ContainerWithDefaultTerminalCmdOpts contains options for Container.WithDefaultTerminalCmd
 type ContainerWithDefaultTerminalCmdOpts struct {
 	// Provides Dagger access to the executed command.
@@ -2624,6 +3132,37 @@ func (r *Container) WithDockerHealthcheck(args []string, opts ...ContainerWithDo
 	}
 }
 
+// ContainerWithEgressPolicyOpts contains options for Container.WithEgressPolicy
+type ContainerWithEgressPolicyOpts struct {
+	// Domains, IP addresses and CIDRs that can be reached (e.g. "github.com", "10.0.0.0/8"). Domains are resolved when commands start.
+	Allow []string
+	// Allow reaching well-known public container registries and the registries configured in the engine.
+	AllowRegistries bool
+}
+
+// Restrict the destinations commands and services run in this container can connect to.
+//
+// Anything not allowed is denied, so a policy without arguments denies all egress. Services bound to the container stay reachable.
+//
+// Replaces any policy set previously. The egress policies of the engine and of the module running the container still apply. Blocked connections are recorded as events of the command's span.
+func (r *Container) WithEgressPolicy(opts ...ContainerWithEgressPolicyOpts) *Container {
+	q := r.query.Select("withEgressPolicy")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `allow` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Allow) {
+			q = q.Arg("allow", opts[i].Allow)
+		}
+		// `allowRegistries` optional argument
+		if !querybuilder.IsZeroValue(opts[i].AllowRegistries) {
+			q = q.Arg("allowRegistries", opts[i].AllowRegistries)
+		}
+	}
+
+	return &Container{
+		query: q,
+	}
+}
+
 // ContainerWithEntrypointOpts contains options for Container.WithEntrypoint
 type ContainerWithEntrypointOpts struct {
 	// Don't reset the default arguments when setting the entrypoint. By default it is reset, since entrypoint and default args are often tightly coupled.
@@ -2718,6 +3257,10 @@ type ContainerWithExecOpts struct {
 	//
 	// Only use this if you specifically need the command to be pid 1 in the container. Otherwise it may result in unexpected behavior. If you're not sure, you don't need this.
 	NoInit bool
+	// Kill the command and its child processes if it runs longer than this duration. Example: "5m"
+	//
+	// Defaults to the container's default exec timeout (see "withDefaultExecTimeout"). A timed out command fails with an exec error that includes the output produced so far, unless "expect" is TIMEOUT.
+	Timeout string
 }
 
 // Execute a command in the container, and return a new snapshot of the container state after execution.
@@ -2764,6 +3307,10 @@ func (r *Container) WithExec(args []string, opts ...ContainerWithExecOpts) *Cont
 		if !querybuilder.IsZeroValue(opts[i].NoInit) {
 			q = q.Arg("noInit", opts[i].NoInit)
 		}
+		// `timeout` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Timeout) {
+			q = q.Arg("timeout", opts[i].Timeout)
+		}
 	}
 	q = q.Arg("args", args)
 
@@ -3226,6 +3773,41 @@ func (r *Container) WithRegistryAuth(address string, username string, secret *Se
 	}
 }
 
+// ContainerWithResourceLimitsOpts contains options for Container.WithResourceLimits
+type ContainerWithResourceLimitsOpts struct {
+	// CPU time available, as a number of CPUs (e.g. "1.5") or millicpus (e.g. "500m"). Unlimited if empty.
+	CPU string
+	// Maximum memory, in bytes or with a unit suffix (e.g. "512m", "2GiB"). Unlimited if empty.
+	Memory string
+	// Maximum number of processes running at once. Unlimited if zero.
+	Pids int
+}
+
+// Limit the CPU, memory and process count available to commands and services run in this container.
+//
+// Replaces any limits set previously. A command that exceeds its memory limit is killed and fails with an exec error reporting the peak memory it used.
+func (r *Container) WithResourceLimits(opts ...ContainerWithResourceLimitsOpts) *Container {
+	q := r.query.Select("withResourceLimits")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `cpu` optional argument
+		if !querybuilder.IsZeroValue(opts[i].CPU) {
+			q = q.Arg("cpu", opts[i].CPU)
+		}
+		// `memory` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Memory) {
+			q = q.Arg("memory", opts[i].Memory)
+		}
+		// `pids` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Pids) {
+			q = q.Arg("pids", opts[i].Pids)
+		}
+	}
+
+	return &Container{
+		query: q,
+	}
+}
+
 // Change the container's root filesystem. The previous root filesystem will be lost.
 func (r *Container) WithRootfs(directory *Directory) *Container {
 	assertNotNil("directory", directory)
@@ -3249,6 +3831,53 @@ func (r *Container) WithSecretVariable(name string, secret *Secret) *Container {
 	}
 }
 
+// ContainerWithSecurityProfileOpts contains options for Container.WithSecurityProfile
+type ContainerWithSecurityProfileOpts struct {
+	// A seccomp profile, in the JSON format used by Docker, layered on top of the default one: syscalls are only allowed if both allow them.
+	SeccompProfile *File
+	// Capabilities to add to the default set (e.g. "NET_BIND_SERVICE" or "CAP_NET_BIND_SERVICE"). Capabilities outside of the default set, like "SYS_ADMIN", require the engine to allow insecure root capabilities.
+	CapAdd []string
+	// Capabilities to drop from the default set (e.g. "NET_RAW"), or "ALL" to drop them all.
+	CapDrop []string
+	// Mount the root filesystem read-only. Mounted directories, files and caches stay writable.
+	ReadOnlyRootfs bool
+	// Prevent processes from gaining privileges, e.g. through setuid binaries.
+	NoNewPrivileges bool
+}
+
+// Restrict what commands and services run in this container are allowed to do.
+//
+// Replaces any profile set previously. The engine's default security profile still applies: it can be tightened, but not loosened, and a profile violating the engine's security policy makes commands fail.
+func (r *Container) WithSecurityProfile(opts ...ContainerWithSecurityProfileOpts) *Container {
+	q := r.query.Select("withSecurityProfile")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `seccompProfile` optional argument
+		if !querybuilder.IsZeroValue(opts[i].SeccompProfile) {
+			q = q.Arg("seccompProfile", opts[i].SeccompProfile)
+		}
+		// `capAdd` optional argument
+		if !querybuilder.IsZeroValue(opts[i].CapAdd) {
+			q = q.Arg("capAdd", opts[i].CapAdd)
+		}
+		// `capDrop` optional argument
+		if !querybuilder.IsZeroValue(opts[i].CapDrop) {
+			q = q.Arg("capDrop", opts[i].CapDrop)
+		}
+		// `readOnlyRootfs` optional argument
+		if !querybuilder.IsZeroValue(opts[i].ReadOnlyRootfs) {
+			q = q.Arg("readOnlyRootfs", opts[i].ReadOnlyRootfs)
+		}
+		// `noNewPrivileges` optional argument
+		if !querybuilder.IsZeroValue(opts[i].NoNewPrivileges) {
+			q = q.Arg("noNewPrivileges", opts[i].NoNewPrivileges)
+		}
+	}
+
+	return &Container{
+		query: q,
+	}
+}
+
 // Establish a runtime dependency from a container to a network service.
 //
 // The service will be started automatically when needed and detached when it is no longer needed, executing the default command if none is set.
@@ -5514,6 +6143,53 @@ func (r *EngineCache) Prune(ctx context.Context, opts ...EngineCachePruneOpts) e
 	return q.Execute(ctx)
 }
 
+// EngineCachePruneEntriesOpts contains options for EngineCache.PruneEntries
+type EngineCachePruneEntriesOpts struct {
+	// Only prune the results of function calls to one of these modules, named by name, source ref with or without version (e.g. "github.com/dagger/jest"), or pinned commit.
+	Module []string
+	// Only prune the results of one of these fields, named with their type (e.g. "Container.from") or alone (e.g. "from").
+	Field []string
+	// Only prune the entries produced by clients with one of these cache tags.
+	Tag []string
+	// Only prune the entries not used for at least this long (e.g. "72h").
+	OlderThan string
+	// Report the entries that would be pruned, without pruning them.
+	DryRun bool
+}
+
+// Prune the releasable cache entries matching all of the given filters, or all releasable entries if none are given.
+//
+// Returns the entries pruned, or with dryRun the entries that would be pruned, with the disk space they free.
+func (r *EngineCache) PruneEntries(opts ...EngineCachePruneEntriesOpts) *EngineCacheEntrySet {
+	q := r.query.Select("pruneEntries")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `module` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Module) {
+			q = q.Arg("module", opts[i].Module)
+		}
+		// `field` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Field) {
+			q = q.Arg("field", opts[i].Field)
+		}
+		// `tag` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Tag) {
+			q = q.Arg("tag", opts[i].Tag)
+		}
+		// `olderThan` optional argument
+		if !querybuilder.IsZeroValue(opts[i].OlderThan) {
+			q = q.Arg("olderThan", opts[i].OlderThan)
+		}
+		// `dryRun` optional argument
+		if !querybuilder.IsZeroValue(opts[i].DryRun) {
+			q = q.Arg("dryRun", opts[i].DryRun)
+		}
+	}
+
+	return &EngineCacheEntrySet{
+		query: q,
+	}
+}
+
 // The minimum amount of disk space this policy is guaranteed to retain.
 func (r *EngineCache) ReservedSpace(ctx context.Context) (int, error) {
 	if r.reservedSpace != nil {
@@ -5540,6 +6216,16 @@ func (r *EngineCache) TargetSpace(ctx context.Context) (int, error) {
 	return response, q.Execute(ctx)
 }
 
+// Explain why a call executed instead of reusing a cached result, by comparing it with the most similar call of the same field cached before it. The call must still be in the cache.
+func (r *EngineCache) WhyMiss(digest string) *EngineCacheMissExplanation {
+	q := r.query.Select("whyMiss")
+	q = q.Arg("digest", digest)
+
+	return &EngineCacheMissExplanation{
+		query: q,
+	}
+}
+
 // AsNode returns this EngineCache as a Node.
 // This is a local type conversion — no GraphQL call.
 func (r *EngineCache) AsNode() Node {
@@ -5558,6 +6244,7 @@ type EngineCacheEntry struct {
 	description               *string
 	diskSpaceBytes            *int
 	id                        *ID
+	module                    *string
 	mostRecentUseTimeUnixNano *int
 	recordType                *string
 }
@@ -5673,6 +6360,19 @@ func (r *EngineCacheEntry) MarshalJSON() ([]byte, error) {
 	return json.Marshal(id)
 }
 
+// The source ref, or the name of a module without one, of the module whose function call produced this cache entry, if any.
+func (r *EngineCacheEntry) Module(ctx context.Context) (string, error) {
+	if r.module != nil {
+		return *r.module, nil
+	}
+	q := r.query.Select("module")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // The most recent time the cache entry was used, in Unix nanoseconds.
 func (r *EngineCacheEntry) MostRecentUseTimeUnixNano(ctx context.Context) (int, error) {
 	if r.mostRecentUseTimeUnixNano != nil {
@@ -5709,6 +6409,16 @@ func (r *EngineCacheEntry) RecordTypes(ctx context.Context) ([]string, error) {
 	return response, q.Execute(ctx)
 }
 
+// The cache tags of the client that produced this cache entry.
+func (r *EngineCacheEntry) Tags(ctx context.Context) ([]string, error) {
+	q := r.query.Select("tags")
+
+	var response []string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // AsNode returns this EngineCacheEntry as a Node.
 // This is a local type conversion — no GraphQL call.
 func (r *EngineCacheEntry) AsNode() Node {
@@ -5839,6 +6549,245 @@ func (r *EngineCacheEntrySet) AsNode() Node {
 	}
 }
 
+// An input that differs between a call and the nearest cached one
+type EngineCacheMissDifference struct {
+	query *querybuilder.Selection
+
+	cached  *string
+	call    *string
+	current *string
+	id      *ID
+	input   *string
+}
+
+func (r *EngineCacheMissDifference) WithGraphQLQuery(q *querybuilder.Selection) *EngineCacheMissDifference {
+	return &EngineCacheMissDifference{
+		query: q,
+	}
+}
+
+// The input as the nearest cached call had it.
+func (r *EngineCacheMissDifference) Cached(ctx context.Context) (string, error) {
+	if r.cached != nil {
+		return *r.cached, nil
+	}
+	q := r.query.Select("cached")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The call the input belongs to, as called this time.
+func (r *EngineCacheMissDifference) Call(ctx context.Context) (string, error) {
+	if r.call != nil {
+		return *r.call, nil
+	}
+	q := r.query.Select("call")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The input as the explained call had it.
+func (r *EngineCacheMissDifference) Current(ctx context.Context) (string, error) {
+	if r.current != nil {
+		return *r.current, nil
+	}
+	q := r.query.Select("current")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// A unique identifier for this EngineCacheMissDifference.
+func (r *EngineCacheMissDifference) ID(ctx context.Context) (ID, error) {
+	if r.id != nil {
+		return *r.id, nil
+	}
+	q := r.query.Select("id")
+
+	var response ID
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// XXX_GraphQLType is an internal function. It returns the native GraphQL type name
+func (r *EngineCacheMissDifference) XXX_GraphQLType() string {
+	return "EngineCacheMissDifference"
+}
+
+// XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
+func (r *EngineCacheMissDifference) XXX_GraphQLIDType() string {
+	return "ID"
+}
+
+// XXX_GraphQLID is an internal function. It returns the underlying type ID
+func (r *EngineCacheMissDifference) XXX_GraphQLID(ctx context.Context) (string, error) {
+	id, err := r.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(id), nil
+}
+
+func (r *EngineCacheMissDifference) MarshalJSON() ([]byte, error) {
+	id, err := r.ID(marshalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(id)
+}
+
+// The name of the input: an argument, an implicit input suffixed with " (implicit)", or one of "field", "receiver", "module", "view", "nth" and "content".
+func (r *EngineCacheMissDifference) Input(ctx context.Context) (string, error) {
+	if r.input != nil {
+		return *r.input, nil
+	}
+	q := r.query.Select("input")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// AsNode returns this EngineCacheMissDifference as a Node.
+// This is a local type conversion — no GraphQL call.
+func (r *EngineCacheMissDifference) AsNode() Node {
+	return &NodeClient{
+		query: r.query,
+	}
+}
+
+// Why a call executed instead of reusing a cached result
+type EngineCacheMissExplanation struct {
+	query *querybuilder.Selection
+
+	call    *string
+	id      *ID
+	nearest *string
+}
+
+func (r *EngineCacheMissExplanation) WithGraphQLQuery(q *querybuilder.Selection) *EngineCacheMissExplanation {
+	return &EngineCacheMissExplanation{
+		query: q,
+	}
+}
+
+// The digest of the explained call.
+func (r *EngineCacheMissExplanation) Call(ctx context.Context) (string, error) {
+	if r.call != nil {
+		return *r.call, nil
+	}
+	q := r.query.Select("call")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The inputs that differ from the nearest cached call. Differing objects are compared in turn, down to the inputs that diverged first. Empty when an identical call was cached but couldn't be reused, because it expired or needs secrets or sockets the client hasn't loaded.
+func (r *EngineCacheMissExplanation) Differences(ctx context.Context) ([]EngineCacheMissDifference, error) {
+	q := r.query.Select("differences")
+
+	q = q.Select("id")
+
+	type differences struct {
+		Id ID
+	}
+
+	convert := func(fields []differences) []EngineCacheMissDifference {
+		out := []EngineCacheMissDifference{}
+
+		for i := range fields {
+			val := EngineCacheMissDifference{id: &fields[i].Id}
+			val.query = selectNode(q.Root(), fields[i].Id, "EngineCacheMissDifference")
+			out = append(out, val)
+		}
+
+		return out
+	}
+	var response []differences
+
+	q = q.Bind(&response)
+
+	err := q.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert(response), nil
+}
+
+// A unique identifier for this EngineCacheMissExplanation.
+func (r *EngineCacheMissExplanation) ID(ctx context.Context) (ID, error) {
+	if r.id != nil {
+		return *r.id, nil
+	}
+	q := r.query.Select("id")
+
+	var response ID
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// XXX_GraphQLType is an internal function. It returns the native GraphQL type name
+func (r *EngineCacheMissExplanation) XXX_GraphQLType() string {
+	return "EngineCacheMissExplanation"
+}
+
+// XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
+func (r *EngineCacheMissExplanation) XXX_GraphQLIDType() string {
+	return "ID"
+}
+
+// XXX_GraphQLID is an internal function. It returns the underlying type ID
+func (r *EngineCacheMissExplanation) XXX_GraphQLID(ctx context.Context) (string, error) {
+	id, err := r.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(id), nil
+}
+
+func (r *EngineCacheMissExplanation) MarshalJSON() ([]byte, error) {
+	id, err := r.ID(marshalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(id)
+}
+
+// The digest of the most similar call of the same field cached before it, or empty if there was none.
+func (r *EngineCacheMissExplanation) Nearest(ctx context.Context) (string, error) {
+	if r.nearest != nil {
+		return *r.nearest, nil
+	}
+	q := r.query.Select("nearest")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// AsNode returns this EngineCacheMissExplanation as a Node.
+// This is a local type conversion — no GraphQL call.
+func (r *EngineCacheMissExplanation) AsNode() Node {
+	return &NodeClient{
+		query: r.query,
+	}
+}
+
 // A definition of a custom enum defined in a Module.
 type EnumTypeDef struct {
 	query *querybuilder.Selection
@@ -7487,11 +8436,25 @@ func (r *Function) WithCachePolicy(policy FunctionCachePolicy, opts ...FunctionW
 	return &Function{
 		query: q,
 	}
-}
-
-// Returns the function with a flag indicating it's a check.
-func (r *Function) WithCheck() *Function {
-	q := r.query.Select("withCheck")
+}
+
+// FunctionWithCheckOpts contains options for Function.WithCheck
+type FunctionWithCheckOpts struct {
+	// How many times to retry the check when it fails, before failing it.
+	//
+	// A check that passes on a retry is reported as flaky.
+	Retries int
+}
+
+// Returns the function with a flag indicating it's a check.
+func (r *Function) WithCheck(opts ...FunctionWithCheckOpts) *Function {
+	q := r.query.Select("withCheck")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `retries` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Retries) {
+			q = q.Arg("retries", opts[i].Retries)
+		}
+	}
 
 	return &Function{
 		query: q,
@@ -10401,6 +11364,43 @@ func (r *LLM) Model(ctx context.Context) (string, error) {
 	return response, q.Execute(ctx)
 }
 
+// The model's most recent reply, decoded as a JSON value matching the output schema set with withOutputSchema.
+func (r *LLM) Output() *JSONValue {
+	q := r.query.Select("output")
+
+	return &JSONValue{
+		query: q,
+	}
+}
+
+// LLMParallelOpts contains options for LLM.Parallel
+type LLMParallelOpts struct {
+	// Cap the number of steps of each sub-agent's loop.
+	MaxSteps int
+	// Cap the model's output tokens on each step of the sub-agents. Defaults to the model's maximum.
+	MaxTokens int
+}
+
+// Run sub-agents concurrently, one per prompt, each looping in its own fork of the conversation with its own copy of the workspace. Their replies, or failures, are then queued as a single prompt for the model to act on, and the changes they made to the bound workspace are merged into it. At most 8 sub-agents loop at once. The tokens they consume, even those that failed, count towards this conversation's usage, and what's left of its budget is split evenly between them. Fails if every sub-agent failed, if their workspace changes conflict, or if they went over the budget.
+func (r *LLM) Parallel(prompts []string, opts ...LLMParallelOpts) *LLM {
+	q := r.query.Select("parallel")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `maxSteps` optional argument
+		if !querybuilder.IsZeroValue(opts[i].MaxSteps) {
+			q = q.Arg("maxSteps", opts[i].MaxSteps)
+		}
+		// `maxTokens` optional argument
+		if !querybuilder.IsZeroValue(opts[i].MaxTokens) {
+			q = q.Arg("maxTokens", opts[i].MaxTokens)
+		}
+	}
+	q = q.Arg("prompts", prompts)
+
+	return &LLM{
+		query: q,
+	}
+}
+
 // A portable, self-contained ID for the conversation that node() can resolve in any session. Unlike id, which may return an engine-local runtime handle valid only within the current session, this returns the recipe form suitable for persisting and later restoring the conversation. The recipe is flattened: bindings superseded during the session (workspace overlays recorded by each mutating tool call, and re-bound toolsets) are dropped, while the current workspace binding — including any pending, un-exported edits — is preserved.
 func (r *LLM) PortableID(ctx context.Context) (ID, error) {
 	if r.portableID != nil {
@@ -10555,12 +11555,83 @@ func (r *LLM) Transcript(ctx context.Context) (string, error) {
 	return response, q.Execute(ctx)
 }
 
+// LLMWithBudgetOpts contains options for LLM.WithBudget
+type LLMWithBudgetOpts struct {
+	// Cap the input tokens, cached or not. Zero means no limit.
+	MaxInputTokens int
+	// Cap the output tokens. Each step's output is also capped to what's left. Zero means no limit.
+	MaxOutputTokens int
+	// Cap the cost in US dollars, priced from the model catalog at the rates of the current model. Zero means no limit.
+	MaxCostUSD float64
+}
+
+// Set a budget for the conversation, counted over all of its API calls as reported by tokenUsage. Once any limit is reached, step and loop fail with an LLM_BUDGET_EXCEEDED error carrying the final transcript, without calling the model again.
+func (r *LLM) WithBudget(opts ...LLMWithBudgetOpts) *LLM {
+	q := r.query.Select("withBudget")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `maxInputTokens` optional argument
+		if !querybuilder.IsZeroValue(opts[i].MaxInputTokens) {
+			q = q.Arg("maxInputTokens", opts[i].MaxInputTokens)
+		}
+		// `maxOutputTokens` optional argument
+		if !querybuilder.IsZeroValue(opts[i].MaxOutputTokens) {
+			q = q.Arg("maxOutputTokens", opts[i].MaxOutputTokens)
+		}
+		// `maxCostUSD` optional argument
+		if !querybuilder.IsZeroValue(opts[i].MaxCostUSD) {
+			q = q.Arg("maxCostUSD", opts[i].MaxCostUSD)
+		}
+	}
+
+	return &LLM{
+		query: q,
+	}
+}
+
+// LLMWithMCPServerOpts contains options for LLM.WithMCPServer
+type LLMWithMCPServerOpts struct {
+	// The MCP service to run and communicate with over stdio, unless port is set
+	Service *Service
+	// Port of the service serving the MCP streamable HTTP transport
+	Port int
+	// Path of the MCP streamable HTTP endpoint of the service
+	//
+	// Default: "/mcp"
+	Path string
+	// URL of a remote MCP server serving the streamable HTTP transport, instead of a service
+	URL string
+	// Secret used to populate the Authorization HTTP header
+	HTTPAuthHeader *Secret
+}
+
 // Add an external MCP server to the LLM
-func (r *LLM) WithMCPServer(name string, service *Service) *LLM {
-	assertNotNil("service", service)
+//
+// The server is either a service spoken to over stdio, a port of a service or a URL serving the MCP streamable HTTP transport.
+func (r *LLM) WithMCPServer(name string, opts ...LLMWithMCPServerOpts) *LLM {
 	q := r.query.Select("withMCPServer")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `service` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Service) {
+			q = q.Arg("service", opts[i].Service)
+		}
+		// `port` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Port) {
+			q = q.Arg("port", opts[i].Port)
+		}
+		// `path` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Path) {
+			q = q.Arg("path", opts[i].Path)
+		}
+		// `url` optional argument
+		if !querybuilder.IsZeroValue(opts[i].URL) {
+			q = q.Arg("url", opts[i].URL)
+		}
+		// `httpAuthHeader` optional argument
+		if !querybuilder.IsZeroValue(opts[i].HTTPAuthHeader) {
+			q = q.Arg("httpAuthHeader", opts[i].HTTPAuthHeader)
+		}
+	}
 	q = q.Arg("name", name)
-	q = q.Arg("service", service)
 
 	return &LLM{
 		query: q,
@@ -10589,6 +11660,33 @@ func (r *LLM) WithModel(model string, opts ...LLMWithModelOpts) *LLM {
 	}
 }
 
+// LLMWithOutputSchemaOpts contains options for LLM.WithOutputSchema
+type LLMWithOutputSchemaOpts struct {
+	// The type of the value to reply with, e.g. an object whose fields the model fills in. Objects referenced by name are resolved through the calling module. Exclusive with schema.
+	TypeDef *TypeDef
+	// A JSON Schema for the value to reply with. Exclusive with typeDef.
+	Schema JSON
+}
+
+// Require the model's final reply to be a JSON value matching a schema, enforced with the provider's native structured output where supported. A reply that doesn't match is sent back to the model to fix, a few times at most before step and loop fail with an LLM_OUTPUT_MISMATCH error. Read the reply with output.
+func (r *LLM) WithOutputSchema(opts ...LLMWithOutputSchemaOpts) *LLM {
+	q := r.query.Select("withOutputSchema")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `typeDef` optional argument
+		if !querybuilder.IsZeroValue(opts[i].TypeDef) {
+			q = q.Arg("typeDef", opts[i].TypeDef)
+		}
+		// `schema` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Schema) {
+			q = q.Arg("schema", opts[i].Schema)
+		}
+	}
+
+	return &LLM{
+		query: q,
+	}
+}
+
 // Queue a user prompt, to be sent to the model on the next step or loop.
 func (r *LLM) WithPrompt(prompt string) *LLM {
 	q := r.query.Select("withPrompt")
@@ -10687,6 +11785,29 @@ func (r *LLM) WithSystemPrompt(prompt string) *LLM {
 	}
 }
 
+// LLMWithToolPolicyOpts contains options for LLM.WithToolPolicy
+type LLMWithToolPolicyOpts struct {
+	// Only apply the policy to read-only tools.
+	ReadOnly bool
+}
+
+// Add a policy approving the model's tool calls. The last policy added that matches a tool applies, unless a [[llm.tools]] policy of the workspace matching it is more restrictive; with none matching, the call is allowed. A refused call is reported to the model as a failed tool call.
+func (r *LLM) WithToolPolicy(tool string, action string, opts ...LLMWithToolPolicyOpts) *LLM {
+	q := r.query.Select("withToolPolicy")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `readOnly` optional argument
+		if !querybuilder.IsZeroValue(opts[i].ReadOnly) {
+			q = q.Arg("readOnly", opts[i].ReadOnly)
+		}
+	}
+	q = q.Arg("tool", tool)
+	q = q.Arg("action", action)
+
+	return &LLM{
+		query: q,
+	}
+}
+
 // Append the result of a tool call to the message history.
 func (r *LLM) WithToolResult(callId string, content string, errored bool) *LLM {
 	q := r.query.Select("withToolResult")
@@ -13232,6 +14353,56 @@ func (r *Query) Changeset() *Changeset {
 	}
 }
 
+// CheckFindingOpts contains options for Query.CheckFinding
+type CheckFindingOpts struct {
+	// How serious the problem is.
+	//
+	// Default: ERROR
+	Level CheckFindingLevel
+	// The identifier of the rule that found the problem.
+	Rule string
+	// The path of the file with the problem, relative to the root of the checked source.
+	Path string
+	// The line of the problem, starting at 1.
+	Line int
+	// The column of the problem, starting at 1.
+	Column int
+}
+
+// Create a finding, for a lint check to report a problem.
+//
+// A check function returning a list of findings fails if any of them is an error.
+func (r *Query) CheckFinding(message string, opts ...CheckFindingOpts) *CheckFinding {
+	q := r.query.Select("checkFinding")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `level` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Level) {
+			q = q.Arg("level", opts[i].Level)
+		}
+		// `rule` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Rule) {
+			q = q.Arg("rule", opts[i].Rule)
+		}
+		// `path` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Path) {
+			q = q.Arg("path", opts[i].Path)
+		}
+		// `line` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Line) {
+			q = q.Arg("line", opts[i].Line)
+		}
+		// `column` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Column) {
+			q = q.Arg("column", opts[i].Column)
+		}
+	}
+	q = q.Arg("message", message)
+
+	return &CheckFinding{
+		query: q,
+	}
+}
+
 // Dagger Cloud configuration and state
 func (r *Query) Cloud() *Cloud {
 	q := r.query.Select("cloud")
@@ -15740,6 +16911,7 @@ type Up struct {
 	query *querybuilder.Selection
 
 	description *string
+	digest      *string
 	id          *ID
 	name        *string
 }
@@ -15771,6 +16943,21 @@ func (r *Up) Description(ctx context.Context) (string, error) {
 	return response, q.Execute(ctx)
 }
 
+// A digest of the service and the host ports it is forwarded on.
+//
+// It changes when the service's inputs change, and the running service is out of date.
+func (r *Up) Digest(ctx context.Context) (string, error) {
+	if r.digest != nil {
+		return *r.digest, nil
+	}
+	q := r.query.Select("digest")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // A unique identifier for this Up.
 func (r *Up) ID(ctx context.Context) (ID, error) {
 	if r.id != nil {
@@ -17124,6 +18311,20 @@ func (r *WorkspaceGit) WithGraphQLQuery(q *querybuilder.Selection) *WorkspaceGit
 	}
 }
 
+// The changes in this workspace since it diverged from the given ref, uncommitted changes included.
+//
+// Like `git diff REF...`, this compares against the common ancestor of the ref and HEAD, so that changes made on the ref since don't count.
+//
+// Files ignored by git are left out.
+func (r *WorkspaceGit) ChangesSince(ref string) *Changeset {
+	q := r.query.Select("changesSince")
+	q = q.Arg("ref", ref)
+
+	return &Changeset{
+		query: q,
+	}
+}
+
 // The checked-out HEAD of this workspace.
 func (r *WorkspaceGit) Head() *GitRef {
 	q := r.query.Select("head")
@@ -18292,6 +19493,134 @@ const (
 	ChangesetsMergeConflictFail ChangesetsMergeConflict = "FAIL"
 )
 
+// How serious a check finding is.
+type CheckFindingLevel string
+
+func (CheckFindingLevel) IsEnum() {}
+
+func (v CheckFindingLevel) Name() string {
+	switch v {
+	case CheckFindingLevelError:
+		return "ERROR"
+	case CheckFindingLevelWarning:
+		return "WARNING"
+	case CheckFindingLevelNote:
+		return "NOTE"
+	default:
+		return ""
+	}
+}
+
+func (v CheckFindingLevel) Value() string {
+	return string(v)
+}
+
+func (v *CheckFindingLevel) MarshalJSON() ([]byte, error) {
+	if *v == "" {
+		return []byte(`""`), nil
+	}
+	name := v.Name()
+	if name == "" {
+		return nil, fmt.Errorf("invalid enum value %q", *v)
+	}
+	return json.Marshal(name)
+}
+
+func (v *CheckFindingLevel) UnmarshalJSON(dt []byte) error {
+	var s string
+	if err := json.Unmarshal(dt, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "":
+		*v = ""
+	case "ERROR":
+		*v = CheckFindingLevelError
+	case "NOTE":
+		*v = CheckFindingLevelNote
+	case "WARNING":
+		*v = CheckFindingLevelWarning
+	default:
+		return fmt.Errorf("invalid enum value %q", s)
+	}
+	return nil
+}
+
+const (
+	// A problem that fails the check.
+	CheckFindingLevelError CheckFindingLevel = "ERROR"
+
+	// A problem that doesn't fail the check.
+	CheckFindingLevelWarning CheckFindingLevel = "WARNING"
+
+	// A suggestion.
+	CheckFindingLevelNote CheckFindingLevel = "NOTE"
+)
+
+// The format of a check report.
+type CheckReportFormat string
+
+func (CheckReportFormat) IsEnum() {}
+
+func (v CheckReportFormat) Name() string {
+	switch v {
+	case CheckReportFormatMarkdown:
+		return "MARKDOWN"
+	case CheckReportFormatJunit:
+		return "JUNIT"
+	case CheckReportFormatSarif:
+		return "SARIF"
+	default:
+		return ""
+	}
+}
+
+func (v CheckReportFormat) Value() string {
+	return string(v)
+}
+
+func (v *CheckReportFormat) MarshalJSON() ([]byte, error) {
+	if *v == "" {
+		return []byte(`""`), nil
+	}
+	name := v.Name()
+	if name == "" {
+		return nil, fmt.Errorf("invalid enum value %q", *v)
+	}
+	return json.Marshal(name)
+}
+
+func (v *CheckReportFormat) UnmarshalJSON(dt []byte) error {
+	var s string
+	if err := json.Unmarshal(dt, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "":
+		*v = ""
+	case "JUNIT":
+		*v = CheckReportFormatJunit
+	case "MARKDOWN":
+		*v = CheckReportFormatMarkdown
+	case "SARIF":
+		*v = CheckReportFormatSarif
+	default:
+		return fmt.Errorf("invalid enum value %q", s)
+	}
+	return nil
+}
+
+const (
+	// A markdown table of the checks and their results.
+	CheckReportFormatMarkdown CheckReportFormat = "MARKDOWN"
+
+	// A JUnit XML report, with a test suite per check.
+	CheckReportFormatJunit CheckReportFormat = "JUNIT"
+
+	// A SARIF log of the findings reported by the checks.
+	CheckReportFormatSarif CheckReportFormat = "SARIF"
+)
+
 // The type of change for a diff stat entry.
 type DiffStatKind string
 
@@ -18702,6 +20031,63 @@ const (
 	ImageMediaTypesDocker           ImageMediaTypes = ImageMediaTypesDockerMediaTypes
 )
 
+// Document format of a software bill of materials attached to an image.
+type ImageSBOMFormat string
+
+func (ImageSBOMFormat) IsEnum() {}
+
+func (v ImageSBOMFormat) Name() string {
+	switch v {
+	case ImageSBOMFormatSpdx:
+		return "SPDX"
+	case ImageSBOMFormatCyclonedx:
+		return "CYCLONEDX"
+	default:
+		return ""
+	}
+}
+
+func (v ImageSBOMFormat) Value() string {
+	return string(v)
+}
+
+func (v *ImageSBOMFormat) MarshalJSON() ([]byte, error) {
+	if *v == "" {
+		return []byte(`""`), nil
+	}
+	name := v.Name()
+	if name == "" {
+		return nil, fmt.Errorf("invalid enum value %q", *v)
+	}
+	return json.Marshal(name)
+}
+
+func (v *ImageSBOMFormat) UnmarshalJSON(dt []byte) error {
+	var s string
+	if err := json.Unmarshal(dt, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "":
+		*v = ""
+	case "CYCLONEDX":
+		*v = ImageSBOMFormatCyclonedx
+	case "SPDX":
+		*v = ImageSBOMFormatSpdx
+	default:
+		return fmt.Errorf("invalid enum value %q", s)
+	}
+	return nil
+}
+
+const (
+	// SPDX 2.3 JSON document.
+	ImageSBOMFormatSpdx ImageSBOMFormat = "SPDX"
+
+	// CycloneDX 1.5 JSON document.
+	ImageSBOMFormatCyclonedx ImageSBOMFormat = "CYCLONEDX"
+)
+
 // The kind of content in a message block.
 type LLMContentBlockKind string
 
@@ -19137,6 +20523,8 @@ func (v ReturnType) Name() string {
 		return "FAILURE"
 	case ReturnTypeAny:
 		return "ANY"
+	case ReturnTypeTimeout:
+		return "TIMEOUT"
 	default:
 		return ""
 	}
@@ -19171,6 +20559,8 @@ func (v *ReturnType) UnmarshalJSON(dt []byte) error {
 		*v = ReturnTypeFailure
 	case "SUCCESS":
 		*v = ReturnTypeSuccess
+	case "TIMEOUT":
+		*v = ReturnTypeTimeout
 	default:
 		return fmt.Errorf("invalid enum value %q", s)
 	}
@@ -19186,6 +20576,9 @@ const (
 
 	// Any execution (exit codes 0-127 and 192-255)
 	ReturnTypeAny ReturnType = "ANY"
+
+	// An execution killed for exceeding its timeout
+	ReturnTypeTimeout ReturnType = "TIMEOUT"
 )
 
 // Distinguishes the different kinds of TypeDefs.
@@ -71,6 +71,32 @@ class ChangesetsMergeConflict(Enum):
     """Fail before attempting merge if file-level conflicts are detected between any changesets"""
 
 
+class CheckFindingLevel(Enum):
+    """How serious a check finding is."""
+
+    ERROR = "ERROR"
+    """A problem that fails the check."""
+
+    NOTE = "NOTE"
+    """A suggestion."""
+
+    WARNING = "WARNING"
+    """A problem that doesn't fail the check."""
+
+
+class CheckReportFormat(Enum):
+    """The format of a check report."""
+
+    JUNIT = "JUNIT"
+    """A JUnit XML report, with a test suite per check."""
+
+    MARKDOWN = "MARKDOWN"
+    """A markdown table of the checks and their results."""
+
+    SARIF = "SARIF"
+    """A SARIF log of the findings reported by the checks."""
+
+
 class DiffStatKind(Enum):
     """The type of change for a diff stat entry."""
 
@@ -158,6 +184,17 @@ class ImageMediaTypes(Enum):
     OCI = "OCIMediaTypes"
 
 
+class ImageSBOMFormat(Enum):
+    """Document format of a software bill of materials attached to an
+    image."""
+
+    CYCLONEDX = "CYCLONEDX"
+    """CycloneDX 1.5 JSON document."""
+
+    SPDX = "SPDX"
+    """SPDX 2.3 JSON document."""
+
+
 class LLMContentBlockKind(Enum):
     """The kind of content in a message block."""
 
@@ -245,6 +282,8 @@ class ReturnType(Enum):
 
     SUCCESS = "SUCCESS"
     """A successful execution (exit code 0)"""
+    TIMEOUT = "TIMEOUT"
+    """An execution killed for exceeding its timeout"""
 
 
 class TypeDefKind(Enum):
@@ -1142,6 +1181,55 @@ class Changeset(Type):
 
 @typecheck
 class Check(Type):
+    async def attempts(self) -> int:
+        """How many times the check ran, counting retries, or 0 if it hasn't run
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("attempts", _args)
+        return await _ctx.execute(int)
+
+    async def changed_inputs(self, changes: Changeset) -> list[str]:
+        """The paths changed by the given changes that the check reads
+
+        Parameters
+        ----------
+        changes:
+            The changes to the workspace, e.g. from WorkspaceGit.changesSince.
+
+        Returns
+        -------
+        list[str]
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args = [
+            Arg("changes", changes),
+        ]
+        _ctx = self._select("changedInputs", _args)
+        return await _ctx.execute(list[str])
+
     async def check_type(self) -> str:
         """The type of check: 'check' for annotated checks, 'generate' for
         generate-as-checks
@@ -1210,6 +1298,33 @@ class Check(Type):
         _ctx = self._select("error", _args)
         return await _ctx.execute_object(Error)
 
+    async def findings(self) -> list["CheckFinding"]:
+        """The findings reported by the check, if its function returns a list of
+        findings
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("findings", _args)
+        return await _ctx.execute_object_list(CheckFinding)
+
+    async def flaky(self) -> bool:
+        """Whether the check failed at first but passed on a retry
+
+        Returns
+        -------
+        bool
+            The `Boolean` scalar type represents `true` or `false`.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("flaky", _args)
+        return await _ctx.execute(bool)
+
     async def id(self) -> str:
         """A unique identifier for this Check.
 
@@ -1238,6 +1353,14 @@ class Check(Type):
         _ctx = self._select("id", _args)
         return await _ctx.execute(str)
 
+    async def inputs(self) -> list["CheckInput"]:
+        """The parts of the workspace the check reads, as far as can be told
+        without running it
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("inputs", _args)
+        return await _ctx.execute_object_list(CheckInput)
+
     async def name(self) -> str:
         """Return the fully qualified name of the check
 
@@ -1326,6 +1449,27 @@ class Check(Type):
         _ctx = self._select("resultEmoji", _args)
         return await _ctx.execute(str)
 
+    async def retries(self) -> int:
+        """How many times the check is retried when it fails, before failing it
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("retries", _args)
+        return await _ctx.execute(int)
+
     def run(self) -> Self:
         """Execute the check"""
         _args: list[Arg] = []
@@ -1340,8 +1484,181 @@ class Check(Type):
         return cb(self)
 
 
+@typecheck
+class CheckFinding(Type):
+    """A problem reported by a lint check."""
+
+    async def column(self) -> int:
+        """The column of the problem, starting at 1, or 0 if unknown.
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("column", _args)
+        return await _ctx.execute(int)
+
+    async def id(self) -> str:
+        """A unique identifier for this CheckFinding.
+
+        Note
+        ----
+        This is lazily evaluated, no operation is actually run.
+
+        Returns
+        -------
+        str
+            The `ID` scalar type represents a unique identifier, often used to
+            refetch an object or as key for a cache. The ID type appears in a
+            JSON response as a String; however, it is not intended to be
+            human-readable. When expected as an input type, any string (such
+            as `"4"`) or integer (such as `4`) input value will be accepted as
+            an ID.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("id", _args)
+        return await _ctx.execute(str)
+
+    async def level(self) -> CheckFindingLevel:
+        """How serious the problem is.
+
+        Returns
+        -------
+        CheckFindingLevel
+            How serious a check finding is.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("level", _args)
+        return await _ctx.execute(CheckFindingLevel)
+
+    async def line(self) -> int:
+        """The line of the problem, starting at 1, or 0 if unknown.
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("line", _args)
+        return await _ctx.execute(int)
+
+    async def message(self) -> str:
+        """A description of the problem.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("message", _args)
+        return await _ctx.execute(str)
+
+    async def path(self) -> str:
+        """The path of the file with the problem, relative to the root of the
+        checked source, if any.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("path", _args)
+        return await _ctx.execute(str)
+
+    async def rule(self) -> str:
+        """The identifier of the rule that found the problem, if any.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("rule", _args)
+        return await _ctx.execute(str)
+
+
 @typecheck
 class CheckGroup(Type):
+    def affected_by(self, changes: Changeset) -> Self:
+        """Return only the checks that the given changes can affect, going by the
+        inputs of each check.
+
+        Parameters
+        ----------
+        changes:
+            The changes to the workspace, e.g. from WorkspaceGit.changesSince.
+        """
+        _args = [
+            Arg("changes", changes),
+        ]
+        _ctx = self._select("affectedBy", _args)
+        return CheckGroup(_ctx)
+
     async def id(self) -> str:
         """A unique identifier for this CheckGroup.
 
@@ -1376,9 +1693,21 @@ class CheckGroup(Type):
         _ctx = self._select("list", _args)
         return await _ctx.execute_object_list(Check)
 
-    def report(self) -> "File":
-        """Generate a markdown report"""
-        _args: list[Arg] = []
+    def report(
+        self,
+        *,
+        format: CheckReportFormat | None = CheckReportFormat.MARKDOWN,
+    ) -> "File":
+        """Generate a report of the checks and their results
+
+        Parameters
+        ----------
+        format:
+            The format of the report.
+        """
+        _args = [
+            Arg("format", format, CheckReportFormat.MARKDOWN),
+        ]
         _ctx = self._select("report", _args)
         return File(_ctx)
 
@@ -1396,12 +1725,142 @@ class CheckGroup(Type):
         _ctx = self._select("run", _args)
         return CheckGroup(_ctx)
 
+    def shard(
+        self,
+        index: int,
+        total: int,
+        *,
+        timings: "File | None" = None,
+    ) -> Self:
+        """Return only the checks in one shard of the group, to split the checks
+        across the jobs of a CI run.
+
+        The split is deterministic: every job computes the same one from the
+        same checks and timings.
+
+        Parameters
+        ----------
+        index:
+            The shard to return, counting from 1.
+        total:
+            The number of shards to split the checks into.
+        timings:
+            A JSON file of how long each check took before, as written by
+            'dagger check --shard-timings', to balance the shards by duration.
+            Without it, the shards get the same number of checks.
+        """
+        _args = [
+            Arg("index", index),
+            Arg("total", total),
+            Arg("timings", timings, None),
+        ]
+        _ctx = self._select("shard", _args)
+        return CheckGroup(_ctx)
+
     def with_(self, cb: Callable[["CheckGroup"], "CheckGroup"]) -> "CheckGroup":
         """Call the provided callable with current CheckGroup.
 
-        This is useful for reusability and readability by not breaking the calling chain.
+        This is useful for reusability and readability by not breaking the calling chain.
+        """
+        return cb(self)
+
+
+@typecheck
+class CheckInput(Type):
+    """A part of the workspace that a check reads."""
+
+    async def exclude(self) -> list[str]:
+        """Patterns of paths under the path that the check doesn't read, from the
+        +ignore of its argument.
+
+        Returns
+        -------
+        list[str]
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("exclude", _args)
+        return await _ctx.execute(list[str])
+
+    async def id(self) -> str:
+        """A unique identifier for this CheckInput.
+
+        Note
+        ----
+        This is lazily evaluated, no operation is actually run.
+
+        Returns
+        -------
+        str
+            The `ID` scalar type represents a unique identifier, often used to
+            refetch an object or as key for a cache. The ID type appears in a
+            JSON response as a String; however, it is not intended to be
+            human-readable. When expected as an input type, any string (such
+            as `"4"`) or integer (such as `4`) input value will be accepted as
+            an ID.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("id", _args)
+        return await _ctx.execute(str)
+
+    async def path(self) -> str:
+        """The path the check reads, relative to the workspace root. A directory
+        includes everything under it.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("path", _args)
+        return await _ctx.execute(str)
+
+    async def reason(self) -> str:
+        """Why the check reads the path.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
         """
-        return cb(self)
+        _args: list[Arg] = []
+        _ctx = self._select("reason", _args)
+        return await _ctx.execute(str)
 
 
 @typecheck
@@ -1504,6 +1963,7 @@ class Container(Type):
         insecure_root_capabilities: bool | None = False,
         expand: bool | None = False,
         no_init: bool | None = False,
+        secret_refresh_command: list[str] | None = None,
     ) -> "Service":
         """Turn the container into a Service.
 
@@ -1534,6 +1994,13 @@ class Container(Type):
             This should only be used if the user requires that their exec
             process be the pid 1 process in the container. Otherwise it may
             result in unexpected behavior.
+        secret_refresh_command:
+            Command to execute in the service when a secret set with
+            withSecretVariable expires (e.g. ["nginx", "-s", "reload"]).
+            Secrets expire when their provider reports a TTL, such as a Vault
+            lease or an AWS Secrets Manager rotation. They are then resolved
+            again and passed to this command in its environment. If empty, the
+            service keeps running with the expired values.
         """
         _args = [
             Arg("args", [] if args is None else args, []),
@@ -1544,6 +2011,11 @@ class Container(Type):
             Arg("insecureRootCapabilities", insecure_root_capabilities, False),
             Arg("expand", expand, False),
             Arg("noInit", no_init, False),
+            Arg(
+                "secretRefreshCommand",
+                [] if secret_refresh_command is None else secret_refresh_command,
+                [],
+            ),
         ]
         _ctx = self._select("asService", _args)
         return Service(_ctx)
@@ -1554,6 +2026,8 @@ class Container(Type):
         platform_variants: "list[Container] | None" = None,
         forced_compression: ImageLayerCompression | None = None,
         media_types: ImageMediaTypes | None = ImageMediaTypes.OCIMediaTypes,
+        sbom: ImageSBOMFormat | None = None,
+        provenance: bool | None = False,
     ) -> "File":
         """Package the container state as an OCI image, and return it as a tar
         archive
@@ -1576,6 +2050,14 @@ class Container(Type):
             Defaults to OCI, which is largely compatible with most recent
             container runtimes, but Docker may be needed for older runtimes
             without OCI support.
+        sbom:
+            Attach a software bill of materials for each platform, generated
+            from the package databases of its root filesystem, in the given
+            format.
+        provenance:
+            Attach a SLSA provenance attestation for each platform, describing
+            the calls that built it and the images, git repositories and HTTP
+            resources it was built from.
         """
         _args = [
             Arg(
@@ -1585,6 +2067,8 @@ class Container(Type):
             ),
             Arg("forcedCompression", forced_compression, None),
             Arg("mediaTypes", media_types, ImageMediaTypes.OCIMediaTypes),
+            Arg("sbom", sbom, None),
+            Arg("provenance", provenance, False),
         ]
         _ctx = self._select("asTarball", _args)
         return File(_ctx)
@@ -1830,6 +2314,8 @@ class Container(Type):
         forced_compression: ImageLayerCompression | None = None,
         media_types: ImageMediaTypes | None = ImageMediaTypes.OCIMediaTypes,
         expand: bool | None = False,
+        sbom: ImageSBOMFormat | None = None,
+        provenance: bool | None = False,
     ) -> str:
         """Writes the container as an OCI tarball to the destination file path on
         the host.
@@ -1861,6 +2347,14 @@ class Container(Type):
             Replace "${VAR}" or "$VAR" in the value of path according to the
             current environment variables defined in the container (e.g.
             "/$VAR/foo").
+        sbom:
+            Attach a software bill of materials for each platform, generated
+            from the package databases of its root filesystem, in the given
+            format.
+        provenance:
+            Attach a SLSA provenance attestation for each platform, describing
+            the calls that built it and the images, git repositories and HTTP
+            resources it was built from.
 
         Returns
         -------
@@ -1886,6 +2380,8 @@ class Container(Type):
             Arg("forcedCompression", forced_compression, None),
             Arg("mediaTypes", media_types, ImageMediaTypes.OCIMediaTypes),
             Arg("expand", expand, False),
+            Arg("sbom", sbom, None),
+            Arg("provenance", provenance, False),
         ]
         _ctx = self._select("export", _args)
         return await _ctx.execute(str)
@@ -1990,6 +2486,8 @@ class Container(Type):
         registry_service: "Service | None" = None,
         protocol: RegistryProtocol | None = None,
         insecure_skip_tls_verify: bool | None = False,
+        signature_key: str | None = "",
+        require_signature: bool | None = False,
     ) -> Self:
         """Download a container image, and apply it to the container state. All
         previous state will be lost.
@@ -2008,12 +2506,23 @@ class Container(Type):
         insecure_skip_tls_verify:
             Allow HTTPS registry communication without verifying the server
             certificate.
+        signature_key:
+            PEM-encoded public key the image must be signed with.
+            Signatures are looked up as cosign signatures attached to the
+            image as OCI referrers, as pushed by "cosign sign" or
+            Container.publish.
+        require_signature:
+            Fail if the image has no signature of its digest.
+            Requires signatureKey, which already makes a signature by that key
+            mandatory.
         """
         _args = [
             Arg("address", address),
             Arg("registryService", registry_service, None),
             Arg("protocol", protocol, None),
             Arg("insecureSkipTLSVerify", insecure_skip_tls_verify, False),
+            Arg("signatureKey", signature_key, ""),
+            Arg("requireSignature", require_signature, False),
         ]
         _ctx = self._select("from", _args)
         return Container(_ctx)
@@ -2240,6 +2749,10 @@ class Container(Type):
         registry_service: "Service | None" = None,
         protocol: RegistryProtocol | None = None,
         insecure_skip_tls_verify: bool | None = False,
+        sbom: ImageSBOMFormat | None = None,
+        provenance: bool | None = False,
+        signing_key: "Secret | None" = None,
+        signing_key_password: "Secret | None" = None,
     ) -> str:
         """Package the container state as an OCI image, and publish it to a
         registry
@@ -2278,6 +2791,22 @@ class Container(Type):
         insecure_skip_tls_verify:
             Allow HTTPS registry communication without verifying the server
             certificate.
+        sbom:
+            Attach a software bill of materials for each platform, generated
+            from the package databases of its root filesystem, in the given
+            format.
+        provenance:
+            Attach a SLSA provenance attestation for each platform, describing
+            the calls that built it and the images, git repositories and HTTP
+            resources it was built from.
+        signing_key:
+            PEM-encoded private key to sign the published image with.
+            The signature is in the cosign format and is pushed as an OCI
+            referrer of the image, so "cosign verify" and Container.from can
+            check it. Encrypted keys generated by "cosign generate-key-pair"
+            are supported.
+        signing_key_password:
+            Password to decrypt the signing key with.
 
         Returns
         -------
@@ -2305,6 +2834,10 @@ class Container(Type):
             Arg("registryService", registry_service, None),
             Arg("protocol", protocol, None),
             Arg("insecureSkipTLSVerify", insecure_skip_tls_verify, False),
+            Arg("sbom", sbom, None),
+            Arg("provenance", provenance, False),
+            Arg("signingKey", signing_key, None),
+            Arg("signingKeyPassword", signing_key_password, None),
         ]
         _ctx = self._select("publish", _args)
         return await _ctx.execute(str)
@@ -2450,6 +2983,7 @@ class Container(Type):
         insecure_root_capabilities: bool | None = False,
         expand: bool | None = False,
         no_init: bool | None = False,
+        secret_refresh_command: list[str] | None = None,
     ) -> Void | None:
         """Starts a Service and creates a tunnel that forwards traffic from the
         caller's network to that service.
@@ -2487,6 +3021,13 @@ class Container(Type):
             This should only be used if the user requires that their exec
             process be the pid 1 process in the container. Otherwise it may
             result in unexpected behavior.
+        secret_refresh_command:
+            Command to execute in the service when a secret set with
+            withSecretVariable expires (e.g. ["nginx", "-s", "reload"]).
+            Secrets expire when their provider reports a TTL, such as a Vault
+            lease or an AWS Secrets Manager rotation. They are then resolved
+            again and passed to this command in its environment. If empty, the
+            service keeps running with the expired values.
 
         Returns
         -------
@@ -2512,6 +3053,11 @@ class Container(Type):
             Arg("insecureRootCapabilities", insecure_root_capabilities, False),
             Arg("expand", expand, False),
             Arg("noInit", no_init, False),
+            Arg(
+                "secretRefreshCommand",
+                [] if secret_refresh_command is None else secret_refresh_command,
+                [],
+            ),
         ]
         _ctx = self._select("up", _args)
         await _ctx.execute()
@@ -2570,6 +3116,21 @@ class Container(Type):
         _ctx = self._select("withDefaultArgs", _args)
         return Container(_ctx)
 
+    def with_default_exec_timeout(self, timeout: str) -> Self:
+        """Set the default timeout for commands executed with withExec.
+
+        Parameters
+        ----------
+        timeout:
+            Maximum duration a command may run before it is killed. Example:
+            "30m". An empty string or "0s" disables the default timeout.
+        """
+        _args = [
+            Arg("timeout", timeout),
+        ]
+        _ctx = self._select("withDefaultExecTimeout", _args)
+        return Container(_ctx)
+
     def with_default_terminal_cmd(
         self,
         args: list[str],
@@ -2707,6 +3268,39 @@ class Container(Type):
         _ctx = self._select("withDockerHealthcheck", _args)
         return Container(_ctx)
 
+    def with_egress_policy(
+        self,
+        *,
+        allow: list[str] | None = None,
+        allow_registries: bool | None = False,
+    ) -> Self:
+        """Restrict the destinations commands and services run in this container
+        can connect to.
+
+        Anything not allowed is denied, so a policy without arguments denies
+        all egress. Services bound to the container stay reachable.
+
+        Replaces any policy set previously. The egress policies of the engine
+        and of the module running the container still apply. Blocked
+        connections are recorded as events of the command's span.
+
+        Parameters
+        ----------
+        allow:
+            Domains, IP addresses and CIDRs that can be reached (e.g.
+            "github.com", "10.0.0.0/8"). Domains are resolved when commands
+            start.
+        allow_registries:
+            Allow reaching well-known public container registries and the
+            registries configured in the engine.
+        """
+        _args = [
+            Arg("allow", [] if allow is None else allow, []),
+            Arg("allowRegistries", allow_registries, False),
+        ]
+        _ctx = self._select("withEgressPolicy", _args)
+        return Container(_ctx)
+
     def with_entrypoint(
         self,
         args: list[str],
@@ -2803,6 +3397,7 @@ class Container(Type):
         insecure_root_capabilities: bool | None = False,
         expand: bool | None = False,
         no_init: bool | None = False,
+        timeout: str | None = "",
     ) -> Self:
         """Execute a command in the container, and return a new snapshot of the
         container state after execution.
@@ -2850,6 +3445,13 @@ class Container(Type):
             Only use this if you specifically need the command to be pid 1 in
             the container. Otherwise it may result in unexpected behavior. If
             you're not sure, you don't need this.
+        timeout:
+            Kill the command and its child processes if it runs longer than
+            this duration. Example: "5m"
+            Defaults to the container's default exec timeout (see
+            "withDefaultExecTimeout"). A timed out command fails with an exec
+            error that includes the output produced so far, unless "expect" is
+            TIMEOUT.
         """
         _args = [
             Arg("args", args),
@@ -2865,6 +3467,7 @@ class Container(Type):
             Arg("insecureRootCapabilities", insecure_root_capabilities, False),
             Arg("expand", expand, False),
             Arg("noInit", no_init, False),
+            Arg("timeout", timeout, ""),
         ]
         _ctx = self._select("withExec", _args)
         return Container(_ctx)
@@ -3323,6 +3926,39 @@ class Container(Type):
         _ctx = self._select("withRegistryAuth", _args)
         return Container(_ctx)
 
+    def with_resource_limits(
+        self,
+        *,
+        cpu: str | None = "",
+        memory: str | None = "",
+        pids: int | None = 0,
+    ) -> Self:
+        """Limit the CPU, memory and process count available to commands and
+        services run in this container.
+
+        Replaces any limits set previously. A command that exceeds its memory
+        limit is killed and fails with an exec error reporting the peak memory
+        it used.
+
+        Parameters
+        ----------
+        cpu:
+            CPU time available, as a number of CPUs (e.g. "1.5") or millicpus
+            (e.g. "500m"). Unlimited if empty.
+        memory:
+            Maximum memory, in bytes or with a unit suffix (e.g. "512m",
+            "2GiB"). Unlimited if empty.
+        pids:
+            Maximum number of processes running at once. Unlimited if zero.
+        """
+        _args = [
+            Arg("cpu", cpu, ""),
+            Arg("memory", memory, ""),
+            Arg("pids", pids, 0),
+        ]
+        _ctx = self._select("withResourceLimits", _args)
+        return Container(_ctx)
+
     def with_rootfs(self, directory: "Directory") -> Self:
         """Change the container's root filesystem. The previous root filesystem
         will be lost.
@@ -3355,6 +3991,53 @@ class Container(Type):
         _ctx = self._select("withSecretVariable", _args)
         return Container(_ctx)
 
+    def with_security_profile(
+        self,
+        *,
+        seccomp_profile: "File | None" = None,
+        cap_add: list[str] | None = None,
+        cap_drop: list[str] | None = None,
+        read_only_rootfs: bool | None = False,
+        no_new_privileges: bool | None = False,
+    ) -> Self:
+        """Restrict what commands and services run in this container are allowed
+        to do.
+
+        Replaces any profile set previously. The engine's default security
+        profile still applies: it can be tightened, but not loosened, and a
+        profile violating the engine's security policy makes commands fail.
+
+        Parameters
+        ----------
+        seccomp_profile:
+            A seccomp profile, in the JSON format used by Docker, layered on
+            top of the default one: syscalls are only allowed if both allow
+            them.
+        cap_add:
+            Capabilities to add to the default set (e.g. "NET_BIND_SERVICE" or
+            "CAP_NET_BIND_SERVICE"). Capabilities outside of the default set,
+            like "SYS_ADMIN", require the engine to allow insecure root
+            capabilities.
+        cap_drop:
+            Capabilities to drop from the default set (e.g. "NET_RAW"), or
+            "ALL" to drop them all.
+        read_only_rootfs:
+            Mount the root filesystem read-only. Mounted directories, files
+            and caches stay writable.
+        no_new_privileges:
+            Prevent processes from gaining privileges, e.g. through setuid
+            binaries.
+        """
+        _args = [
+            Arg("seccompProfile", seccomp_profile, None),
+            Arg("capAdd", [] if cap_add is None else cap_add, []),
+            Arg("capDrop", [] if cap_drop is None else cap_drop, []),
+            Arg("readOnlyRootfs", read_only_rootfs, False),
+            Arg("noNewPrivileges", no_new_privileges, False),
+        ]
+        _ctx = self._select("withSecurityProfile", _args)
+        return Container(_ctx)
+
     def with_service_binding(self, alias: str, service: "Service") -> Self:
         """Establish a runtime dependency from a container to a network service.
 
@@ -5529,6 +6212,49 @@ class EngineCache(Type):
         _ctx = self._select("prune", _args)
         await _ctx.execute()
 
+    def prune_entries(
+        self,
+        *,
+        module: list[str] | None = None,
+        field: list[str] | None = None,
+        tag: list[str] | None = None,
+        older_than: str | None = "",
+        dry_run: bool | None = False,
+    ) -> "EngineCacheEntrySet":
+        """Prune the releasable cache entries matching all of the given filters,
+        or all releasable entries if none are given.
+
+        Returns the entries pruned, or with dryRun the entries that would be
+        pruned, with the disk space they free.
+
+        Parameters
+        ----------
+        module:
+            Only prune the results of function calls to one of these modules,
+            named by name, source ref with or without version (e.g.
+            "github.com/dagger/jest"), or pinned commit.
+        field:
+            Only prune the results of one of these fields, named with their
+            type (e.g. "Container.from") or alone (e.g. "from").
+        tag:
+            Only prune the entries produced by clients with one of these cache
+            tags.
+        older_than:
+            Only prune the entries not used for at least this long (e.g.
+            "72h").
+        dry_run:
+            Report the entries that would be pruned, without pruning them.
+        """
+        _args = [
+            Arg("module", [] if module is None else module, []),
+            Arg("field", [] if field is None else field, []),
+            Arg("tag", [] if tag is None else tag, []),
+            Arg("olderThan", older_than, ""),
+            Arg("dryRun", dry_run, False),
+        ]
+        _ctx = self._select("pruneEntries", _args)
+        return EngineCacheEntrySet(_ctx)
+
     async def reserved_space(self) -> int:
         """The minimum amount of disk space this policy is guaranteed to retain.
 
@@ -5570,6 +6296,21 @@ class EngineCache(Type):
         _args: list[Arg] = []
         _ctx = self._select("targetSpace", _args)
         return await _ctx.execute(int)
+    def why_miss(self, digest: str) -> "EngineCacheMissExplanation":
+        """Explain why a call executed instead of reusing a cached result, by
+        comparing it with the most similar call of the same field cached
+        before it. The call must still be in the cache.
+
+        Parameters
+        ----------
+        digest:
+            The digest of the call, as recorded on its span.
+        """
+        _args = [
+            Arg("digest", digest),
+        ]
+        _ctx = self._select("whyMiss", _args)
+        return EngineCacheMissExplanation(_ctx)
 
 
 @typecheck
@@ -5655,11 +6396,198 @@ class EngineCacheEntry(Type):
             If the API returns an error.
         """
         _args: list[Arg] = []
-        _ctx = self._select("description", _args)
-        return await _ctx.execute(str)
+        _ctx = self._select("description", _args)
+        return await _ctx.execute(str)
+
+    async def disk_space_bytes(self) -> int:
+        """The disk space used by the cache entry.
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("diskSpaceBytes", _args)
+        return await _ctx.execute(int)
+
+    async def id(self) -> str:
+        """A unique identifier for this EngineCacheEntry.
+
+        Note
+        ----
+        This is lazily evaluated, no operation is actually run.
+
+        Returns
+        -------
+        str
+            The `ID` scalar type represents a unique identifier, often used to
+            refetch an object or as key for a cache. The ID type appears in a
+            JSON response as a String; however, it is not intended to be
+            human-readable. When expected as an input type, any string (such
+            as `"4"`) or integer (such as `4`) input value will be accepted as
+            an ID.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("id", _args)
+        return await _ctx.execute(str)
+
+    async def module(self) -> str:
+        """The source ref, or the name of a module without one, of the module
+        whose function call produced this cache entry, if any.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("module", _args)
+        return await _ctx.execute(str)
+
+    async def most_recent_use_time_unix_nano(self) -> int:
+        """The most recent time the cache entry was used, in Unix nanoseconds.
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("mostRecentUseTimeUnixNano", _args)
+        return await _ctx.execute(int)
+
+    async def record_type(self) -> str:
+        """The type of the cache record (e.g. regular, internal, frontend,
+        source.local, source.git.checkout, exec.cachemount).
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("recordType", _args)
+        return await _ctx.execute(str)
+
+    async def record_types(self) -> list[str]:
+        """The storage record types represented by this cache entry.
+
+        Returns
+        -------
+        list[str]
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("recordTypes", _args)
+        return await _ctx.execute(list[str])
+    async def tags(self) -> list[str]:
+        """The cache tags of the client that produced this cache entry.
+
+        Returns
+        -------
+        list[str]
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("tags", _args)
+        return await _ctx.execute(list[str])
+
+
+@typecheck
+class EngineCacheEntrySet(Type):
+    """A set of cache entries returned by a query to a cache"""
+
+    async def disk_space_bytes(self) -> int:
+        """The total disk space used by the cache entries in this set.
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("diskSpaceBytes", _args)
+        return await _ctx.execute(int)
+
+    async def entries(self) -> list[EngineCacheEntry]:
+        """The list of individual cache entries in the set"""
+        _args: list[Arg] = []
+        _ctx = self._select("entries", _args)
+        return await _ctx.execute_object_list(EngineCacheEntry)
 
-    async def disk_space_bytes(self) -> int:
-        """The disk space used by the cache entry.
+    async def entry_count(self) -> int:
+        """The number of cache entries in this set.
 
         Returns
         -------
@@ -5676,11 +6604,11 @@ class EngineCacheEntry(Type):
             If the API returns an error.
         """
         _args: list[Arg] = []
-        _ctx = self._select("diskSpaceBytes", _args)
+        _ctx = self._select("entryCount", _args)
         return await _ctx.execute(int)
 
     async def id(self) -> str:
-        """A unique identifier for this EngineCacheEntry.
+        """A unique identifier for this EngineCacheEntrySet.
 
         Note
         ----
@@ -5707,15 +6635,20 @@ class EngineCacheEntry(Type):
         _ctx = self._select("id", _args)
         return await _ctx.execute(str)
 
-    async def most_recent_use_time_unix_nano(self) -> int:
-        """The most recent time the cache entry was used, in Unix nanoseconds.
+
+@typecheck
+class EngineCacheMissDifference(Type):
+    """An input that differs between a call and the nearest cached one"""
+
+    async def cached(self) -> str:
+        """The input as the nearest cached call had it.
 
         Returns
         -------
-        int
-            The `Int` scalar type represents non-fractional signed whole
-            numeric values. Int can represent values between -(2^31) and 2^31
-            - 1.
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
 
         Raises
         ------
@@ -5725,12 +6658,11 @@ class EngineCacheEntry(Type):
             If the API returns an error.
         """
         _args: list[Arg] = []
-        _ctx = self._select("mostRecentUseTimeUnixNano", _args)
-        return await _ctx.execute(int)
+        _ctx = self._select("cached", _args)
+        return await _ctx.execute(str)
 
-    async def record_type(self) -> str:
-        """The type of the cache record (e.g. regular, internal, frontend,
-        source.local, source.git.checkout, exec.cachemount).
+    async def call(self) -> str:
+        """The call the input belongs to, as called this time.
 
         Returns
         -------
@@ -5747,15 +6679,15 @@ class EngineCacheEntry(Type):
             If the API returns an error.
         """
         _args: list[Arg] = []
-        _ctx = self._select("recordType", _args)
+        _ctx = self._select("call", _args)
         return await _ctx.execute(str)
 
-    async def record_types(self) -> list[str]:
-        """The storage record types represented by this cache entry.
+    async def current(self) -> str:
+        """The input as the explained call had it.
 
         Returns
         -------
-        list[str]
+        str
             The `String` scalar type represents textual data, represented as
             UTF-8 character sequences. The String type is most often used by
             GraphQL to represent free-form human-readable text.
@@ -5768,23 +6700,25 @@ class EngineCacheEntry(Type):
             If the API returns an error.
         """
         _args: list[Arg] = []
-        _ctx = self._select("recordTypes", _args)
-        return await _ctx.execute(list[str])
-
+        _ctx = self._select("current", _args)
+        return await _ctx.execute(str)
 
-@typecheck
-class EngineCacheEntrySet(Type):
-    """A set of cache entries returned by a query to a cache"""
+    async def id(self) -> str:
+        """A unique identifier for this EngineCacheMissDifference.
 
-    async def disk_space_bytes(self) -> int:
-        """The total disk space used by the cache entries in this set.
+        Note
+        ----
+        This is lazily evaluated, no operation is actually run.
 
         Returns
         -------
-        int
-            The `Int` scalar type represents non-fractional signed whole
-            numeric values. Int can represent values between -(2^31) and 2^31
-            - 1.
+        str
+            The `ID` scalar type represents a unique identifier, often used to
+            refetch an object or as key for a cache. The ID type appears in a
+            JSON response as a String; however, it is not intended to be
+            human-readable. When expected as an input type, any string (such
+            as `"4"`) or integer (such as `4`) input value will be accepted as
+            an ID.
 
         Raises
         ------
@@ -5794,24 +6728,46 @@ class EngineCacheEntrySet(Type):
             If the API returns an error.
         """
         _args: list[Arg] = []
-        _ctx = self._select("diskSpaceBytes", _args)
-        return await _ctx.execute(int)
+        _ctx = self._select("id", _args)
+        return await _ctx.execute(str)
 
-    async def entries(self) -> list[EngineCacheEntry]:
-        """The list of individual cache entries in the set"""
+    async def input(self) -> str:
+        """The name of the input: an argument, an implicit input suffixed with "
+        (implicit)", or one of "field", "receiver", "module", "view", "nth"
+        and "content".
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
         _args: list[Arg] = []
-        _ctx = self._select("entries", _args)
-        return await _ctx.execute_object_list(EngineCacheEntry)
+        _ctx = self._select("input", _args)
+        return await _ctx.execute(str)
 
-    async def entry_count(self) -> int:
-        """The number of cache entries in this set.
+
+@typecheck
+class EngineCacheMissExplanation(Type):
+    """Why a call executed instead of reusing a cached result"""
+
+    async def call(self) -> str:
+        """The digest of the explained call.
 
         Returns
         -------
-        int
-            The `Int` scalar type represents non-fractional signed whole
-            numeric values. Int can represent values between -(2^31) and 2^31
-            - 1.
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
 
         Raises
         ------
@@ -5821,11 +6777,21 @@ class EngineCacheEntrySet(Type):
             If the API returns an error.
         """
         _args: list[Arg] = []
-        _ctx = self._select("entryCount", _args)
-        return await _ctx.execute(int)
+        _ctx = self._select("call", _args)
+        return await _ctx.execute(str)
+
+    async def differences(self) -> list[EngineCacheMissDifference]:
+        """The inputs that differ from the nearest cached call. Differing objects
+        are compared in turn, down to the inputs that diverged first. Empty
+        when an identical call was cached but couldn't be reused, because it
+        expired or needs secrets or sockets the client hasn't loaded.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("differences", _args)
+        return await _ctx.execute_object_list(EngineCacheMissDifference)
 
     async def id(self) -> str:
-        """A unique identifier for this EngineCacheEntrySet.
+        """A unique identifier for this EngineCacheMissExplanation.
 
         Note
         ----
@@ -5852,6 +6818,28 @@ class EngineCacheEntrySet(Type):
         _ctx = self._select("id", _args)
         return await _ctx.execute(str)
 
+    async def nearest(self) -> str:
+        """The digest of the most similar call of the same field cached before
+        it, or empty if there was none.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("nearest", _args)
+        return await _ctx.execute(str)
+
 
 @typecheck
 class EnumTypeDef(Type):
@@ -7211,9 +8199,19 @@ class Function(Type):
         _ctx = self._select("withCachePolicy", _args)
         return Function(_ctx)
 
-    def with_check(self) -> Self:
-        """Returns the function with a flag indicating it's a check."""
-        _args: list[Arg] = []
+    def with_check(self, *, retries: int | None = 0) -> Self:
+        """Returns the function with a flag indicating it's a check.
+
+        Parameters
+        ----------
+        retries:
+            How many times to retry the check when it fails, before failing
+            it.
+            A check that passes on a retry is reported as flaky.
+        """
+        _args = [
+            Arg("retries", retries, 0),
+        ]
         _ctx = self._select("withCheck", _args)
         return Function(_ctx)
 
@@ -9921,6 +10919,49 @@ class LLM(Type):
         _ctx = self._select("model", _args)
         return await _ctx.execute(str)
 
+    def output(self) -> JSONValue:
+        """The model's most recent reply, decoded as a JSON value matching the
+        output schema set with withOutputSchema.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("output", _args)
+        return JSONValue(_ctx)
+
+    def parallel(
+        self,
+        prompts: list[str],
+        *,
+        max_steps: int | None = None,
+        max_tokens: int | None = None,
+    ) -> Self:
+        """Run sub-agents concurrently, one per prompt, each looping in its own
+        fork of the conversation with its own copy of the workspace. Their
+        replies, or failures, are then queued as a single prompt for the model
+        to act on, and the changes they made to the bound workspace are merged
+        into it. At most 8 sub-agents loop at once. The tokens they consume,
+        even those that failed, count towards this conversation's usage, and
+        what's left of its budget is split evenly between them. Fails if every
+        sub-agent failed, if their workspace changes conflict, or if they went
+        over the budget.
+
+        Parameters
+        ----------
+        prompts:
+            The prompt to send each sub-agent.
+        max_steps:
+            Cap the number of steps of each sub-agent's loop.
+        max_tokens:
+            Cap the model's output tokens on each step of the sub-agents.
+            Defaults to the model's maximum.
+        """
+        _args = [
+            Arg("prompts", prompts),
+            Arg("maxSteps", max_steps, None),
+            Arg("maxTokens", max_tokens, None),
+        ]
+        _ctx = self._select("parallel", _args)
+        return LLM(_ctx)
+
     async def portable_id(self) -> str:
         """A portable, self-contained ID for the conversation that node() can
         resolve in any session. Unlike id, which may return an engine-local
@@ -10104,19 +11145,76 @@ class LLM(Type):
         _ctx = self._select("transcript", _args)
         return await _ctx.execute(str)
 
-    def with_mcp_server(self, name: str, service: "Service") -> Self:
+    def with_budget(
+        self,
+        *,
+        max_input_tokens: int | None = None,
+        max_output_tokens: int | None = None,
+        max_cost_usd: float | None = None,
+    ) -> Self:
+        """Set a budget for the conversation, counted over all of its API calls
+        as reported by tokenUsage. Once any limit is reached, step and loop
+        fail with an LLM_BUDGET_EXCEEDED error carrying the final transcript,
+        without calling the model again.
+
+        Parameters
+        ----------
+        max_input_tokens:
+            Cap the input tokens, cached or not. Zero means no limit.
+        max_output_tokens:
+            Cap the output tokens. Each step's output is also capped to what's
+            left. Zero means no limit.
+        max_cost_usd:
+            Cap the cost in US dollars, priced from the model catalog at the
+            rates of the current model. Zero means no limit.
+        """
+        _args = [
+            Arg("maxInputTokens", max_input_tokens, None),
+            Arg("maxOutputTokens", max_output_tokens, None),
+            Arg("maxCostUSD", max_cost_usd, None),
+        ]
+        _ctx = self._select("withBudget", _args)
+        return LLM(_ctx)
+
+    def with_mcp_server(
+        self,
+        name: str,
+        *,
+        service: "Service | None" = None,
+        port: int | None = None,
+        path: str | None = "/mcp",
+        url: str | None = None,
+        http_auth_header: "Secret | None" = None,
+    ) -> Self:
         """Add an external MCP server to the LLM
 
+        The server is either a service spoken to over stdio, a port of a
+        service or a URL serving the MCP streamable HTTP transport.
+
         Parameters
         ----------
         name:
             The name of the MCP server
         service:
-            The MCP service to run and communicate with over stdio
+            The MCP service to run and communicate with over stdio, unless
+            port is set
+        port:
+            Port of the service serving the MCP streamable HTTP transport
+        path:
+            Path of the MCP streamable HTTP endpoint of the service
+        url:
+            URL of a remote MCP server serving the streamable HTTP transport,
+            instead of a service
+        http_auth_header:
+            Secret used to populate the Authorization HTTP header
         """
         _args = [
             Arg("name", name),
-            Arg("service", service),
+            Arg("service", service, None),
+            Arg("port", port, None),
+            Arg("path", path, "/mcp"),
+            Arg("url", url, None),
+            Arg("httpAuthHeader", http_auth_header, None),
         ]
         _ctx = self._select("withMCPServer", _args)
         return LLM(_ctx)
@@ -10147,6 +11245,34 @@ class LLM(Type):
         _ctx = self._select("withModel", _args)
         return LLM(_ctx)
 
+    def with_output_schema(
+        self,
+        *,
+        type_def: "TypeDef | None" = None,
+        schema: JSON | None = None,
+    ) -> Self:
+        """Require the model's final reply to be a JSON value matching a schema,
+        enforced with the provider's native structured output where supported.
+        A reply that doesn't match is sent back to the model to fix, a few
+        times at most before step and loop fail with an LLM_OUTPUT_MISMATCH
+        error. Read the reply with output.
+
+        Parameters
+        ----------
+        type_def:
+            The type of the value to reply with, e.g. an object whose fields
+            the model fills in. Objects referenced by name are resolved
+            through the calling module. Exclusive with schema.
+        schema:
+            A JSON Schema for the value to reply with. Exclusive with typeDef.
+        """
+        _args = [
+            Arg("typeDef", type_def, None),
+            Arg("schema", schema, None),
+        ]
+        _ctx = self._select("withOutputSchema", _args)
+        return LLM(_ctx)
+
     def with_prompt(self, prompt: str) -> Self:
         """Queue a user prompt, to be sent to the model on the next step or loop.
 
@@ -10267,6 +11393,39 @@ class LLM(Type):
         _ctx = self._select("withSystemPrompt", _args)
         return LLM(_ctx)
 
+    def with_tool_policy(
+        self,
+        tool: str,
+        action: str,
+        *,
+        read_only: bool | None = False,
+    ) -> Self:
+        """Add a policy approving the model's tool calls. The last policy added
+        that matches a tool applies, unless a [[llm.tools]] policy of the
+        workspace matching it is more restrictive; with none matching, the
+        call is allowed. A refused call is reported to the model as a failed
+        tool call.
+
+        Parameters
+        ----------
+        tool:
+            A glob pattern matched against the tool name, e.g. "withExec" or
+            "publish*". Tools named after a bound object or MCP server match
+            both with and without that prefix.
+        action:
+            "allow" to make the call, "ask" to ask the user first (a client
+            that can't prompt denies it), or "deny" to refuse it.
+        read_only:
+            Only apply the policy to read-only tools.
+        """
+        _args = [
+            Arg("tool", tool),
+            Arg("action", action),
+            Arg("readOnly", read_only, False),
+        ]
+        _ctx = self._select("withToolPolicy", _args)
+        return LLM(_ctx)
+
     def with_tool_result(
         self,
         call_id: str,
@@ -12625,6 +13784,48 @@ class Query(Root):
         _ctx = self._select("changeset", _args)
         return Changeset(_ctx)
 
+    def check_finding(
+        self,
+        message: str,
+        *,
+        level: CheckFindingLevel | None = CheckFindingLevel.ERROR,
+        rule: str | None = "",
+        path: str | None = "",
+        line: int | None = 0,
+        column: int | None = 0,
+    ) -> CheckFinding:
+        """Create a finding, for a lint check to report a problem.
+
+        A check function returning a list of findings fails if any of them is
+        an error.
+
+        Parameters
+        ----------
+        message:
+            A description of the problem.
+        level:
+            How serious the problem is.
+        rule:
+            The identifier of the rule that found the problem.
+        path:
+            The path of the file with the problem, relative to the root of the
+            checked source.
+        line:
+            The line of the problem, starting at 1.
+        column:
+            The column of the problem, starting at 1.
+        """
+        _args = [
+            Arg("message", message),
+            Arg("level", level, CheckFindingLevel.ERROR),
+            Arg("rule", rule, ""),
+            Arg("path", path, ""),
+            Arg("line", line, 0),
+            Arg("column", column, 0),
+        ]
+        _ctx = self._select("checkFinding", _args)
+        return CheckFinding(_ctx)
+
     def cloud(self) -> Cloud:
         """Dagger Cloud configuration and state"""
         _args: list[Arg] = []
@@ -14799,6 +16000,30 @@ class Up(Type):
         _ctx = self._select("description", _args)
         return await _ctx.execute(str)
 
+    async def digest(self) -> str:
+        """A digest of the service and the host ports it is forwarded on.
+
+        It changes when the service's inputs change, and the running service
+        is out of date.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("digest", _args)
+        return await _ctx.execute(str)
+
     async def id(self) -> str:
         """A unique identifier for this Up.
 
@@ -16075,6 +17300,26 @@ class Workspace(Type):
 class WorkspaceGit(Type):
     """Local git state for a workspace."""
 
+    def changes_since(self, ref: str) -> Changeset:
+        """The changes in this workspace since it diverged from the given ref,
+        uncommitted changes included.
+
+        Like `git diff REF...`, this compares against the common ancestor of
+        the ref and HEAD, so that changes made on the ref since don't count.
+
+        Files ignored by git are left out.
+
+        Parameters
+        ----------
+        ref:
+            The ref to compare against, e.g. origin/main.
+        """
+        _args = [
+            Arg("ref", ref),
+        ]
+        _ctx = self._select("changesSince", _args)
+        return Changeset(_ctx)
+
     def head(self) -> GitRef:
         """The checked-out HEAD of this workspace."""
         _args: list[Arg] = []
@@ -16593,7 +17838,11 @@ __all__ = [
     "ChangesetMergeConflict",
     "ChangesetsMergeConflict",
     "Check",
+    "CheckFinding",
+    "CheckFindingLevel",
     "CheckGroup",
+    "CheckInput",
+    "CheckReportFormat",
     "Client",
     "ClientFilesyncMirror",
     "Cloud",
@@ -16609,6 +17858,8 @@ __all__ = [
     "EngineCache",
     "EngineCacheEntry",
     "EngineCacheEntrySet",
+    "EngineCacheMissDifference",
+    "EngineCacheMissExplanation",
     "EnumTypeDef",
     "EnumValueTypeDef",
     "EnvFile",
@@ -16636,6 +17887,7 @@ __all__ = [
     "Host",
     "ImageLayerCompression",
     "ImageMediaTypes",
+    "ImageSBOMFormat",
     "InputTypeDef",
     "InterfaceTypeDef",
     "JSONValue",
@@ -54,6 +54,7 @@ OBJECT_DEF_KEY: typing.Final[str] = "__dagger_object__"
 FIELD_DEF_KEY: typing.Final[str] = "__dagger_field__"
 FUNCTION_DEF_KEY: typing.Final[str] = "__dagger_function__"
 CHECK_DEF_KEY: typing.Final[str] = "__dagger_check__"
+CHECK_RETRIES_DEF_KEY: typing.Final[str] = "__dagger_check_retries__"
 GENERATOR_DEF_KEY: typing.Final[str] = "__dagger_generate__"
 UP_DEF_KEY: typing.Final[str] = "__dagger_up__"
 AGENT_DEF_KEY: typing.Final[str] = "__dagger_agent__"
@@ -210,7 +211,7 @@ class Module:
                 if deprecated := func.deprecated:
                     func_def = func_def.with_deprecated(reason=deprecated)
                 if func.check:
-                    func_def = func_def.with_check()
+                    func_def = func_def.with_check(retries=func.check_retries)
                 if func.generate:
                     func_def = func_def.with_generator()
                 if func.service:
@@ -624,6 +625,8 @@ class Module:
     def check(
         self,
         func: Func[P, R] | None = None,
+        *,
+        retries: int = 0,
     ) -> Func[P, R] | Callable[[Func[P, R]], Func[P, R]]:
         """Mark a function as a check.
 
@@ -640,15 +643,27 @@ class Module:
                 def lint(self) -> str:
                     return "All checks passed"
 
+                @function
+                @check(retries=2)
+                def integration(self) -> str:
+                    return "All checks passed"
+
         Parameters
         ----------
         func:
             The function to mark as a check. Should be an instance method in a
             class decorated with :py:meth:`object_type`.
+        retries:
+            How many times to retry the check when it fails, before failing
+            it. A check that passes on a retry is reported as flaky.
         """
+        if retries < 0:
+            msg = f"Check retries must not be negative, got {retries}"
+            raise BadUsageError(msg)
 
         def wrapper(fn: Func[P, R]) -> Func[P, R]:
             setattr(fn, CHECK_DEF_KEY, True)
+            setattr(fn, CHECK_RETRIES_DEF_KEY, retries)
             return fn
 
         return wrapper(func) if func else wrapper
@@ -794,6 +809,7 @@ class Module:
 
             # Check if function is marked as a check or generator
             check = getattr(func, CHECK_DEF_KEY, False)
+            check_retries = getattr(func, CHECK_RETRIES_DEF_KEY, 0)
             generator = getattr(func, GENERATOR_DEF_KEY, False)
             service = getattr(func, UP_DEF_KEY, False)
             agent = getattr(func, AGENT_DEF_KEY, False)
@@ -804,6 +820,7 @@ class Module:
                 cache=cache,
                 deprecated=deprecated,
                 check=check,
+                check_retries=check_retries,
                 generator=generator,
                 service=service,
                 agent=agent,
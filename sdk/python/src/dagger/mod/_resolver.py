@@ -39,6 +39,7 @@ from dagger.mod._utils import (
 )
 
 CHECK_DEF_KEY: str = "__dagger_check__"
+CHECK_RETRIES_DEF_KEY: str = "__dagger_check_retries__"
 GENERATOR_DEF_KEY: str = "__dagger_generate__"
 UP_DEF_KEY: str = "__dagger_up__"
 AGENT_DEF_KEY: str = "__dagger_agent__"
@@ -106,6 +107,13 @@ class Function(Generic[P, R]):
         # Check both the metadata and the attribute to support either decorator order
         return self.meta.check or getattr(self.wrapped, CHECK_DEF_KEY, False)
 
+    @property
+    def check_retries(self) -> int:
+        """How many times to retry the check when it fails."""
+        return self.meta.check_retries or getattr(
+            self.wrapped, CHECK_RETRIES_DEF_KEY, 0
+        )
+
     @property
     def generate(self) -> bool:
         """Indicates whether the function is configured as a generator."""
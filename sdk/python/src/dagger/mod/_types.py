@@ -23,6 +23,7 @@ class FunctionDefinition:
     cache: str | None = None
     deprecated: str | None = None
     check: bool = False
+    check_retries: int = 0
     generator: bool = False
     service: bool = False
     agent: bool = False
@@ -119,6 +119,40 @@ def test_check_decorator_order():
     assert function_first_fn.check is True
 
 
+def test_check_retries():
+    mod = Module()
+
+    @mod.object_type
+    class Foo:
+        @mod.function
+        @mod.check(retries=2)
+        def flaky(self):
+            """Check retried twice."""
+
+        @mod.check(retries=1)
+        @mod.function
+        def check_first(self):
+            """Check applied before function."""
+
+        @mod.function
+        @mod.check
+        def lint(self):
+            """Check without retries."""
+
+    functions = mod.get_object("Foo").functions
+    assert functions["flaky"].check is True
+    assert functions["flaky"].check_retries == 2
+    assert functions["check_first"].check_retries == 1
+    assert functions["lint"].check_retries == 0
+
+
+def test_check_negative_retries():
+    mod = Module()
+
+    with pytest.raises(BadUsageError, match="must not be negative"):
+        mod.check(retries=-1)
+
+
 def test_function_argument_deprecated_metadata():
     mod = Module()
 
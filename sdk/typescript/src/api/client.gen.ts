@@ -239,6 +239,66 @@ export function ChangesetsMergeConflictNameToValue(
       return name as ChangesetsMergeConflict
   }
 }
+/**
+ * How serious a check finding is.
+ */
+export enum CheckFindingLevel {
+  /**
+   * A problem that fails the check.
+   */
+  Error = "ERROR",
+
+  /**
+   * A suggestion.
+   */
+  Note = "NOTE",
+
+  /**
+   * A problem that doesn't fail the check.
+   */
+  Warning = "WARNING",
+}
+
+/**
+ * Utility function to convert a CheckFindingLevel value to its name so
+ * it can be uses as argument to call a exposed function.
+ */
+export function CheckFindingLevelValueToName(value: CheckFindingLevel): string {
+  switch (value) {
+    case CheckFindingLevel.Error:
+      return "ERROR"
+    case CheckFindingLevel.Note:
+      return "NOTE"
+    case CheckFindingLevel.Warning:
+      return "WARNING"
+    default:
+      return value
+  }
+}
+
+/**
+ * Utility function to convert a CheckFindingLevel name to its value so
+ * it can be properly used inside the module runtime.
+ */
+export function CheckFindingLevelNameToValue(name: string): CheckFindingLevel {
+  switch (name) {
+    case "ERROR":
+      return CheckFindingLevel.Error
+    case "NOTE":
+      return CheckFindingLevel.Note
+    case "WARNING":
+      return CheckFindingLevel.Warning
+    default:
+      return name as CheckFindingLevel
+  }
+}
+export type CheckGroupReportOpts = {
+  /**
+   * The format of the report.
+   */
+  format?: CheckReportFormat
+}
+
 export type CheckGroupRunOpts = {
   /**
    * If true, stop running checks as soon as any check fails.
@@ -246,6 +306,68 @@ export type CheckGroupRunOpts = {
   failFast?: boolean
 }
 
+export type CheckGroupShardOpts = {
+  /**
+   * A JSON file of how long each check took before, as written by 'dagger check --shard-timings', to balance the shards by duration.
+   *
+   * Without it, the shards get the same number of checks.
+   */
+  timings?: File
+}
+
+/**
+ * The format of a check report.
+ */
+export enum CheckReportFormat {
+  /**
+   * A JUnit XML report, with a test suite per check.
+   */
+  Junit = "JUNIT",
+
+  /**
+   * A markdown table of the checks and their results.
+   */
+  Markdown = "MARKDOWN",
+
+  /**
+   * A SARIF log of the findings reported by the checks.
+   */
+  Sarif = "SARIF",
+}
+
+/**
+ * Utility function to convert a CheckReportFormat value to its name so
+ * it can be uses as argument to call a exposed function.
+ */
+export function CheckReportFormatValueToName(value: CheckReportFormat): string {
+  switch (value) {
+    case CheckReportFormat.Junit:
+      return "JUNIT"
+    case CheckReportFormat.Markdown:
+      return "MARKDOWN"
+    case CheckReportFormat.Sarif:
+      return "SARIF"
+    default:
+      return value
+  }
+}
+
+/**
+ * Utility function to convert a CheckReportFormat name to its value so
+ * it can be properly used inside the module runtime.
+ */
+export function CheckReportFormatNameToValue(name: string): CheckReportFormat {
+  switch (name) {
+    case "JUNIT":
+      return CheckReportFormat.Junit
+    case "MARKDOWN":
+      return CheckReportFormat.Markdown
+    case "SARIF":
+      return CheckReportFormat.Sarif
+    default:
+      return name as CheckReportFormat
+  }
+}
 export type ContainerAsServiceOpts = {
   /**
    * Command to run instead of the container's default command (e.g., ["go", "run", "main.go"]).
@@ -280,6 +402,13 @@ export type ContainerAsServiceOpts = {
    * This should only be used if the user requires that their exec process be the pid 1 process in the container. Otherwise it may result in unexpected behavior.
    */
   noInit?: boolean
+
+  /**
+   * Command to execute in the service when a secret set with withSecretVariable expires (e.g. ["nginx", "-s", "reload"]).
+   *
+   * Secrets expire when their provider reports a TTL, such as a Vault lease or an AWS Secrets Manager rotation. They are then resolved again and passed to this command in its environment. If empty, the service keeps running with the expired values.
+   */
+  secretRefreshCommand?: string[]
 }
 
 export type ContainerAsTarballOpts = {
@@ -303,6 +432,16 @@ export type ContainerAsTarballOpts = {
    * Defaults to OCI, which is largely compatible with most recent container runtimes, but Docker may be needed for older runtimes without OCI support.
    */
   mediaTypes?: ImageMediaTypes
+
+  /**
+   * Attach a software bill of materials for each platform, generated from the package databases of its root filesystem, in the given format.
+   */
+  sbom?: ImageSBOMFormat
+
+  /**
+   * Attach a SLSA provenance attestation for each platform, describing the calls that built it and the images, git repositories and HTTP resources it was built from.
+   */
+  provenance?: boolean
 }
 
 export type ContainerDirectoryOpts = {
@@ -355,6 +494,16 @@ export type ContainerExportOpts = {
    * Replace "${VAR}" or "$VAR" in the value of path according to the current environment variables defined in the container (e.g. "/$VAR/foo").
    */
   expand?: boolean
+
+  /**
+   * Attach a software bill of materials for each platform, generated from the package databases of its root filesystem, in the given format.
+   */
+  sbom?: ImageSBOMFormat
+
+  /**
+   * Attach a SLSA provenance attestation for each platform, describing the calls that built it and the images, git repositories and HTTP resources it was built from.
+   */
+  provenance?: boolean
 }
 
 export type ContainerExportImageOpts = {
@@ -406,6 +555,20 @@ export type ContainerFromOpts = {
    * Allow HTTPS registry communication without verifying the server certificate.
    */
   insecureSkipTLSVerify?: boolean
+
+  /**
+   * PEM-encoded public key the image must be signed with.
+   *
+   * Signatures are looked up as cosign signatures attached to the image as OCI referrers, as pushed by "cosign sign" or Container.publish.
+   */
+  signatureKey?: string
+
+  /**
+   * Fail if the image has no signature of its digest.
+   *
+   * Requires signatureKey, which already makes a signature by that key mandatory.
+   */
+  requireSignature?: boolean
 }
 
 export type ContainerImportOpts = {
@@ -483,6 +646,28 @@ export type ContainerPublishOpts = {
    * Allow HTTPS registry communication without verifying the server certificate.
    */
   insecureSkipTLSVerify?: boolean
+
+  /**
+   * Attach a software bill of materials for each platform, generated from the package databases of its root filesystem, in the given format.
+   */
+  sbom?: ImageSBOMFormat
+
+  /**
+   * Attach a SLSA provenance attestation for each platform, describing the calls that built it and the images, git repositories and HTTP resources it was built from.
+   */
+  provenance?: boolean
+
+  /**
+   * PEM-encoded private key to sign the published image with.
+   *
+   * The signature is in the cosign format and is pushed as an OCI referrer of the image, so "cosign verify" and Container.from can check it. Encrypted keys generated by "cosign generate-key-pair" are supported.
+   */
+  signingKey?: Secret
+
+  /**
+   * Password to decrypt the signing key with.
+   */
+  signingKeyPassword?: Secret
 }
 
 export type ContainerStatOpts = {
@@ -555,6 +740,13 @@ export type ContainerUpOpts = {
    * This should only be used if the user requires that their exec process be the pid 1 process in the container. Otherwise it may result in unexpected behavior.
    */
   noInit?: boolean
+
+  /**
+   * Command to execute in the service when a secret set with withSecretVariable expires (e.g. ["nginx", "-s", "reload"]).
+   *
+   * Secrets expire when their provider reports a TTL, such as a Vault lease or an AWS Secrets Manager rotation. They are then resolved again and passed to this command in its environment. If empty, the service keeps running with the expired values.
+   */
+  secretRefreshCommand?: string[]
 }
 
 export type ContainerWithDefaultTerminalCmdOpts = {
@@ -638,6 +830,18 @@ export type ContainerWithDockerHealthcheckOpts = {
   retries?: number
 }
 
+export type ContainerWithEgressPolicyOpts = {
+  /**
+   * Domains, IP addresses and CIDRs that can be reached (e.g. "github.com", "10.0.0.0/8"). Domains are resolved when commands start.
+   */
+  allow?: string[]
+
+  /**
+   * Allow reaching well-known public container registries and the registries configured in the engine.
+   */
+  allowRegistries?: boolean
+}
+
 export type ContainerWithEntrypointOpts = {
   /**
    * Don't reset the default arguments when setting the entrypoint. By default it is reset, since entrypoint and default args are often tightly coupled.
@@ -706,6 +910,13 @@ export type ContainerWithExecOpts = {
    * Only use this if you specifically need the command to be pid 1 in the container. Otherwise it may result in unexpected behavior. If you're not sure, you don't need this.
    */
   noInit?: boolean
+
+  /**
+   * Kill the command and its child processes if it runs longer than this duration. Example: "5m"
+   *
+   * Defaults to the container's default exec timeout (see "withDefaultExecTimeout"). A timed out command fails with an exec error that includes the output produced so far, unless "expect" is TIMEOUT.
+   */
+  timeout?: string
 }
 
 export type ContainerWithExposedPortOpts = {
@@ -935,6 +1146,50 @@ export type ContainerWithNewFileOpts = {
   expand?: boolean
 }
 
+export type ContainerWithResourceLimitsOpts = {
+  /**
+   * CPU time available, as a number of CPUs (e.g. "1.5") or millicpus (e.g. "500m"). Unlimited if empty.
+   */
+  cpu?: string
+
+  /**
+   * Maximum memory, in bytes or with a unit suffix (e.g. "512m", "2GiB"). Unlimited if empty.
+   */
+  memory?: string
+
+  /**
+   * Maximum number of processes running at once. Unlimited if zero.
+   */
+  pids?: number
+}
+
+export type ContainerWithSecurityProfileOpts = {
+  /**
+   * A seccomp profile, in the JSON format used by Docker, layered on top of the default one: syscalls are only allowed if both allow them.
+   */
+  seccompProfile?: File
+
+  /**
+   * Capabilities to add to the default set (e.g. "NET_BIND_SERVICE" or "CAP_NET_BIND_SERVICE"). Capabilities outside of the default set, like "SYS_ADMIN", require the engine to allow insecure root capabilities.
+   */
+  capAdd?: string[]
+
+  /**
+   * Capabilities to drop from the default set (e.g. "NET_RAW"), or "ALL" to drop them all.
+   */
+  capDrop?: string[]
+
+  /**
+   * Mount the root filesystem read-only. Mounted directories, files and caches stay writable.
+   */
+  readOnlyRootfs?: boolean
+
+  /**
+   * Prevent processes from gaining privileges, e.g. through setuid binaries.
+   */
+  noNewPrivileges?: boolean
+}
+
 export type ContainerWithSymlinkOpts = {
   /**
    * Replace "${VAR}" or "$VAR" in the value of path according to the current environment variables defined in the container (e.g. "/$VAR/foo.txt").
@@ -1427,6 +1682,33 @@ export type EngineCachePruneOpts = {
   targetEstimatedBytes?: number
 }
 
+export type EngineCachePruneEntriesOpts = {
+  /**
+   * Only prune the results of function calls to one of these modules, named by name, source ref with or without version (e.g. "github.com/dagger/jest"), or pinned commit.
+   */
+  module?: string[]
+
+  /**
+   * Only prune the results of one of these fields, named with their type (e.g. "Container.from") or alone (e.g. "from").
+   */
+  field?: string[]
+
+  /**
+   * Only prune the entries produced by clients with one of these cache tags.
+   */
+  tag?: string[]
+
+  /**
+   * Only prune the entries not used for at least this long (e.g. "72h").
+   */
+  olderThan?: string
+
+  /**
+   * Report the entries that would be pruned, without pruning them.
+   */
+  dryRun?: boolean
+}
+
 export type EnvFileGetOpts = {
   /**
    * Return the value exactly as written to the file. No quote removal or variable expansion
@@ -1702,6 +1984,15 @@ export type FunctionWithCachePolicyOpts = {
   timeToLive?: string
 }
 
+export type FunctionWithCheckOpts = {
+  /**
+   * How many times to retry the check when it fails, before failing it.
+   *
+   * A check that passes on a retry is reported as flaky.
+   */
+  retries?: number
+}
+
 export type FunctionWithDeprecatedOpts = {
   /**
    * Reason or migration path describing the deprecation.
@@ -2011,6 +2302,50 @@ export function ImageMediaTypesNameToValue(name: string): ImageMediaTypes {
       return name as ImageMediaTypes
   }
 }
+/**
+ * Document format of a software bill of materials attached to an image.
+ */
+export enum ImageSBOMFormat {
+  /**
+   * CycloneDX 1.5 JSON document.
+   */
+  Cyclonedx = "CYCLONEDX",
+
+  /**
+   * SPDX 2.3 JSON document.
+   */
+  Spdx = "SPDX",
+}
+
+/**
+ * Utility function to convert a ImageSBOMFormat value to its name so
+ * it can be uses as argument to call a exposed function.
+ */
+export function ImageSbomFormatValueToName(value: ImageSBOMFormat): string {
+  switch (value) {
+    case ImageSBOMFormat.Cyclonedx:
+      return "CYCLONEDX"
+    case ImageSBOMFormat.Spdx:
+      return "SPDX"
+    default:
+      return value
+  }
+}
+
+/**
+ * Utility function to convert a ImageSBOMFormat name to its value so
+ * it can be properly used inside the module runtime.
+ */
+export function ImageSbomFormatNameToValue(name: string): ImageSBOMFormat {
+  switch (name) {
+    case "CYCLONEDX":
+      return ImageSBOMFormat.Cyclonedx
+    case "SPDX":
+      return ImageSBOMFormat.Spdx
+    default:
+      return name as ImageSBOMFormat
+  }
+}
 /**
  * An arbitrary JSON-encoded value.
  */
@@ -2040,6 +2375,18 @@ export type LLMLoopOpts = {
   maxTokens?: number
 }
 
+export type LLMParallelOpts = {
+  /**
+   * Cap the number of steps of each sub-agent's loop.
+   */
+  maxSteps?: number
+
+  /**
+   * Cap the model's output tokens on each step of the sub-agents. Defaults to the model's maximum.
+   */
+  maxTokens?: number
+}
+
 export type LLMStepOpts = {
   /**
    * Cap the model's output tokens for this step. Defaults to the model's maximum.
@@ -2047,6 +2394,50 @@ export type LLMStepOpts = {
   maxTokens?: number
 }
 
+export type LLMWithBudgetOpts = {
+  /**
+   * Cap the input tokens, cached or not. Zero means no limit.
+   */
+  maxInputTokens?: number
+
+  /**
+   * Cap the output tokens. Each step's output is also capped to what's left. Zero means no limit.
+   */
+  maxOutputTokens?: number
+
+  /**
+   * Cap the cost in US dollars, priced from the model catalog at the rates of the current model. Zero means no limit.
+   */
+  maxCostUSD?: float
+}
+
+export type LLMWithMcpServerOpts = {
+  /**
+   * The MCP service to run and communicate with over stdio, unless port is set
+   */
+  service?: Service
+
+  /**
+   * Port of the service serving the MCP streamable HTTP transport
+   */
+  port?: number
+
+  /**
+   * Path of the MCP streamable HTTP endpoint of the service
+   */
+  path?: string
+
+  /**
+   * URL of a remote MCP server serving the streamable HTTP transport, instead of a service
+   */
+  url?: string
+
+  /**
+   * Secret used to populate the Authorization HTTP header
+   */
+  httpAuthHeader?: Secret
+}
+
 export type LLMWithModelOpts = {
   /**
    * The provider serving the model, e.g. "openai". Overrides the provider otherwise inferred from the model name — useful when the name matches no known pattern (e.g. a fine-tune), or matches the wrong one.
@@ -2054,6 +2445,18 @@ export type LLMWithModelOpts = {
   provider?: string
 }
 
+export type LLMWithOutputSchemaOpts = {
+  /**
+   * The type of the value to reply with, e.g. an object whose fields the model fills in. Objects referenced by name are resolved through the calling module. Exclusive with schema.
+   */
+  typeDef?: TypeDef
+
+  /**
+   * A JSON Schema for the value to reply with. Exclusive with typeDef.
+   */
+  schema?: JSON
+}
+
 export type LLMWithResponseOpts = {
   /**
    * Uncached input tokens sent
@@ -2081,6 +2484,13 @@ export type LLMWithResponseOpts = {
   totalTokens?: number
 }
 
+export type LLMWithToolPolicyOpts = {
+  /**
+   * Only apply the policy to read-only tools.
+   */
+  readOnly?: boolean
+}
+
 export type LLMWithToolsOpts = {
   /**
    * Method names to exclude from the toolset (e.g. constructors, entrypoints).
@@ -2504,28 +2914,55 @@ export type ClientCacheVolumeOpts = {
   owner?: string
 }
 
-export type ClientContainerOpts = {
+export type ClientCheckFindingOpts = {
   /**
-   * Platform to initialize the container with. Defaults to the native platform of the current engine
+   * How serious the problem is.
    */
-  platform?: Platform
-}
+  level?: CheckFindingLevel
 
-export type ClientCurrentTypeDefsOpts = {
   /**
-   * Return the full referenced typedef closure instead of only top-level served typedefs.
+   * The identifier of the rule that found the problem.
    */
-  returnAllTypes?: boolean
+  rule?: string
 
   /**
-   * Strip core API functions from the Query type, leaving only module-sourced functions (constructors, entrypoint proxies, etc.).
-   *
-   * Core types (Container, Directory, etc.) are kept so return types and method chaining still work.
+   * The path of the file with the problem, relative to the root of the checked source.
    */
-  hideCore?: boolean
-}
+  path?: string
 
-export type ClientEngineVolumeOpts = {
+  /**
+   * The line of the problem, starting at 1.
+   */
+  line?: number
+
+  /**
+   * The column of the problem, starting at 1.
+   */
+  column?: number
+}
+
+export type ClientContainerOpts = {
+  /**
+   * Platform to initialize the container with. Defaults to the native platform of the current engine
+   */
+  platform?: Platform
+}
+
+export type ClientCurrentTypeDefsOpts = {
+  /**
+   * Return the full referenced typedef closure instead of only top-level served typedefs.
+   */
+  returnAllTypes?: boolean
+
+  /**
+   * Strip core API functions from the Query type, leaving only module-sourced functions (constructors, entrypoint proxies, etc.).
+   *
+   * Core types (Container, Directory, etc.) are kept so return types and method chaining still work.
+   */
+  hideCore?: boolean
+}
+
+export type ClientEngineVolumeOpts = {
   /**
    * Optional existing subdirectory within the volume payload to mount.
    */
@@ -2736,6 +3173,11 @@ export enum ReturnType {
    * A successful execution (exit code 0)
    */
   Success = "SUCCESS",
+
+  /**
+   * An execution killed for exceeding its timeout
+   */
+  Timeout = "TIMEOUT",
 }
 
 /**
@@ -2750,6 +3192,8 @@ export function ReturnTypeValueToName(value: ReturnType): string {
       return "FAILURE"
     case ReturnType.Success:
       return "SUCCESS"
+    case ReturnType.Timeout:
+      return "TIMEOUT"
     default:
       return value
   }
@@ -2767,6 +3211,8 @@ export function ReturnTypeNameToValue(name: string): ReturnType {
       return ReturnType.Failure
     case "SUCCESS":
       return ReturnType.Success
+    case "TIMEOUT":
+      return ReturnType.Timeout
     default:
       return name as ReturnType
   }
@@ -3921,12 +4367,15 @@ export class Changeset extends BaseClient {
 
 export class Check extends BaseClient {
   private readonly _id?: ID = undefined
+  private readonly _attempts?: number = undefined
   private readonly _checkType?: string = undefined
   private readonly _completed?: boolean = undefined
   private readonly _description?: string = undefined
+  private readonly _flaky?: boolean = undefined
   private readonly _name?: string = undefined
   private readonly _passed?: boolean = undefined
   private readonly _resultEmoji?: string = undefined
+  private readonly _retries?: number = undefined
 
   /**
    * Constructor is used for internal usage only, do not create object from it.
@@ -3934,22 +4383,28 @@ export class Check extends BaseClient {
   constructor(
     ctx?: Context,
     _id?: ID,
+    _attempts?: number,
     _checkType?: string,
     _completed?: boolean,
     _description?: string,
+    _flaky?: boolean,
     _name?: string,
     _passed?: boolean,
     _resultEmoji?: string,
+    _retries?: number,
   ) {
     super(ctx)
 
     this._id = _id
+    this._attempts = _attempts
     this._checkType = _checkType
     this._completed = _completed
     this._description = _description
+    this._flaky = _flaky
     this._name = _name
     this._passed = _passed
     this._resultEmoji = _resultEmoji
+    this._retries = _retries
   }
 
   /**
@@ -3967,6 +4422,33 @@ export class Check extends BaseClient {
     return response
   }
 
+  /**
+   * How many times the check ran, counting retries, or 0 if it hasn't run
+   */
+  attempts = async (): Promise<number> => {
+    if (this._attempts) {
+      return this._attempts
+    }
+
+    const ctx = this._ctx.select("attempts")
+
+    const response: Awaited<number> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * The paths changed by the given changes that the check reads
+   * @param changes The changes to the workspace, e.g. from WorkspaceGit.changesSince.
+   */
+  changedInputs = async (changes: Changeset): Promise<string[]> => {
+    const ctx = this._ctx.select("changedInputs", { changes })
+
+    const response: Awaited<string[]> = await ctx.execute()
+
+    return response
+  }
+
   /**
    * The type of check: 'check' for annotated checks, 'generate' for generate-as-checks
    */
@@ -4026,6 +4508,55 @@ export class Check extends BaseClient {
     return new Error(ctx.copy().selectNode(response, "Error"))
   }
 
+  /**
+   * The findings reported by the check, if its function returns a list of findings
+   */
+  findings = async (): Promise<CheckFinding[]> => {
+    type findings = {
+      id: ID
+    }
+
+    const ctx = this._ctx.select("findings").select("id")
+
+    const response: Awaited<findings[]> = await ctx.execute()
+
+    return response.map(
+      (r) => new CheckFinding(ctx.copy().selectNode(r.id, "CheckFinding")),
+    )
+  }
+
+  /**
+   * Whether the check failed at first but passed on a retry
+   */
+  flaky = async (): Promise<boolean> => {
+    if (this._flaky) {
+      return this._flaky
+    }
+
+    const ctx = this._ctx.select("flaky")
+
+    const response: Awaited<boolean> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * The parts of the workspace the check reads, as far as can be told without running it
+   */
+  inputs = async (): Promise<CheckInput[]> => {
+    type inputs = {
+      id: ID
+    }
+
+    const ctx = this._ctx.select("inputs").select("id")
+
+    const response: Awaited<inputs[]> = await ctx.execute()
+
+    return response.map(
+      (r) => new CheckInput(ctx.copy().selectNode(r.id, "CheckInput")),
+    )
+  }
+
   /**
    * Return the fully qualified name of the check
    */
@@ -4090,6 +4621,21 @@ export class Check extends BaseClient {
     return response
   }
 
+  /**
+   * How many times the check is retried when it fails, before failing it
+   */
+  retries = async (): Promise<number> => {
+    if (this._retries) {
+      return this._retries
+    }
+
+    const ctx = this._ctx.select("retries")
+
+    const response: Awaited<number> = await ctx.execute()
+
+    return response
+  }
+
   /**
    * Execute the check
    */
@@ -4108,6 +4654,148 @@ export class Check extends BaseClient {
   }
 }
 
+/**
+ * A problem reported by a lint check.
+ */
+export class CheckFinding extends BaseClient {
+  private readonly _id?: ID = undefined
+  private readonly _column?: number = undefined
+  private readonly _level?: CheckFindingLevel = undefined
+  private readonly _line?: number = undefined
+  private readonly _message?: string = undefined
+  private readonly _path?: string = undefined
+  private readonly _rule?: string = undefined
+
+  /**
+   * Constructor is used for internal usage only, do not create object from it.
+   */
+  constructor(
+    ctx?: Context,
+    _id?: ID,
+    _column?: number,
+    _level?: CheckFindingLevel,
+    _line?: number,
+    _message?: string,
+    _path?: string,
+    _rule?: string,
+  ) {
+    super(ctx)
+
+    this._id = _id
+    this._column = _column
+    this._level = _level
+    this._line = _line
+    this._message = _message
+    this._path = _path
+    this._rule = _rule
+  }
+
+  /**
+   * A unique identifier for this CheckFinding.
+   */
+  id = async (): Promise<ID> => {
+    if (this._id) {
+      return this._id
+    }
+
+    const ctx = this._ctx.select("id")
+
+    const response: Awaited<ID> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * The column of the problem, starting at 1, or 0 if unknown.
+   */
+  column = async (): Promise<number> => {
+    if (this._column) {
+      return this._column
+    }
+
+    const ctx = this._ctx.select("column")
+
+    const response: Awaited<number> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * How serious the problem is.
+   */
+  level = async (): Promise<CheckFindingLevel> => {
+    if (this._level) {
+      return this._level
+    }
+
+    const ctx = this._ctx.select("level")
+
+    const response: Awaited<CheckFindingLevel> = await ctx.execute()
+
+    return CheckFindingLevelNameToValue(response)
+  }
+
+  /**
+   * The line of the problem, starting at 1, or 0 if unknown.
+   */
+  line = async (): Promise<number> => {
+    if (this._line) {
+      return this._line
+    }
+
+    const ctx = this._ctx.select("line")
+
+    const response: Awaited<number> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * A description of the problem.
+   */
+  message = async (): Promise<string> => {
+    if (this._message) {
+      return this._message
+    }
+
+    const ctx = this._ctx.select("message")
+
+    const response: Awaited<string> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * The path of the file with the problem, relative to the root of the checked source, if any.
+   */
+  path = async (): Promise<string> => {
+    if (this._path) {
+      return this._path
+    }
+
+    const ctx = this._ctx.select("path")
+
+    const response: Awaited<string> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * The identifier of the rule that found the problem, if any.
+   */
+  rule = async (): Promise<string> => {
+    if (this._rule) {
+      return this._rule
+    }
+
+    const ctx = this._ctx.select("rule")
+
+    const response: Awaited<string> = await ctx.execute()
+
+    return response
+  }
+}
+
 export class CheckGroup extends BaseClient {
   private readonly _id?: ID = undefined
 
@@ -4135,6 +4823,15 @@ export class CheckGroup extends BaseClient {
     return response
   }
 
+  /**
+   * Return only the checks that the given changes can affect, going by the inputs of each check.
+   * @param changes The changes to the workspace, e.g. from WorkspaceGit.changesSince.
+   */
+  affectedBy = (changes: Changeset): CheckGroup => {
+    const ctx = this._ctx.select("affectedBy", { changes })
+    return new CheckGroup(ctx)
+  }
+
   /**
    * Return a list of individual checks and their details
    */
@@ -4151,10 +4848,15 @@ export class CheckGroup extends BaseClient {
   }
 
   /**
-   * Generate a markdown report
+   * Generate a report of the checks and their results
+   * @param opts.format The format of the report.
    */
-  report = (): File => {
-    const ctx = this._ctx.select("report")
+  report = (opts?: CheckGroupReportOpts): File => {
+    const metadata = {
+      format: { is_enum: true, value_to_name: CheckReportFormatValueToName },
+    }
+
+    const ctx = this._ctx.select("report", { ...opts, __metadata: metadata })
     return new File(ctx)
   }
 
@@ -4167,6 +4869,25 @@ export class CheckGroup extends BaseClient {
     return new CheckGroup(ctx)
   }
 
+  /**
+   * Return only the checks in one shard of the group, to split the checks across the jobs of a CI run.
+   *
+   * The split is deterministic: every job computes the same one from the same checks and timings.
+   * @param index The shard to return, counting from 1.
+   * @param total The number of shards to split the checks into.
+   * @param opts.timings A JSON file of how long each check took before, as written by 'dagger check --shard-timings', to balance the shards by duration.
+   *
+   * Without it, the shards get the same number of checks.
+   */
+  shard = (
+    index: number,
+    total: number,
+    opts?: CheckGroupShardOpts,
+  ): CheckGroup => {
+    const ctx = this._ctx.select("shard", { index, total, ...opts })
+    return new CheckGroup(ctx)
+  }
+
   /**
    * Call the provided function with current CheckGroup.
    *
@@ -4177,6 +4898,82 @@ export class CheckGroup extends BaseClient {
   }
 }
 
+/**
+ * A part of the workspace that a check reads.
+ */
+export class CheckInput extends BaseClient {
+  private readonly _id?: ID = undefined
+  private readonly _path?: string = undefined
+  private readonly _reason?: string = undefined
+
+  /**
+   * Constructor is used for internal usage only, do not create object from it.
+   */
+  constructor(ctx?: Context, _id?: ID, _path?: string, _reason?: string) {
+    super(ctx)
+
+    this._id = _id
+    this._path = _path
+    this._reason = _reason
+  }
+
+  /**
+   * A unique identifier for this CheckInput.
+   */
+  id = async (): Promise<ID> => {
+    if (this._id) {
+      return this._id
+    }
+
+    const ctx = this._ctx.select("id")
+
+    const response: Awaited<ID> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * Patterns of paths under the path that the check doesn't read, from the +ignore of its argument.
+   */
+  exclude = async (): Promise<string[]> => {
+    const ctx = this._ctx.select("exclude")
+
+    const response: Awaited<string[]> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * The path the check reads, relative to the workspace root. A directory includes everything under it.
+   */
+  path = async (): Promise<string> => {
+    if (this._path) {
+      return this._path
+    }
+
+    const ctx = this._ctx.select("path")
+
+    const response: Awaited<string> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * Why the check reads the path.
+   */
+  reason = async (): Promise<string> => {
+    if (this._reason) {
+      return this._reason
+    }
+
+    const ctx = this._ctx.select("reason")
+
+    const response: Awaited<string> = await ctx.execute()
+
+    return response
+  }
+}
+
 /**
  * An internal persistent filesync mirror.
  */
@@ -4351,6 +5148,9 @@ export class Container extends BaseClient {
    * @param opts.noInit If set, skip the automatic init process injected into containers by default.
    *
    * This should only be used if the user requires that their exec process be the pid 1 process in the container. Otherwise it may result in unexpected behavior.
+   * @param opts.secretRefreshCommand Command to execute in the service when a secret set with withSecretVariable expires (e.g. ["nginx", "-s", "reload"]).
+   *
+   * Secrets expire when their provider reports a TTL, such as a Vault lease or an AWS Secrets Manager rotation. They are then resolved again and passed to this command in its environment. If empty, the service keeps running with the expired values.
    */
   asService = (opts?: ContainerAsServiceOpts): Service => {
     const ctx = this._ctx.select("asService", { ...opts })
@@ -4368,6 +5168,8 @@ export class Container extends BaseClient {
    * @param opts.mediaTypes Use the specified media types for the image's layers.
    *
    * Defaults to OCI, which is largely compatible with most recent container runtimes, but Docker may be needed for older runtimes without OCI support.
+   * @param opts.sbom Attach a software bill of materials for each platform, generated from the package databases of its root filesystem, in the given format.
+   * @param opts.provenance Attach a SLSA provenance attestation for each platform, describing the calls that built it and the images, git repositories and HTTP resources it was built from.
    */
   asTarball = (opts?: ContainerAsTarballOpts): File => {
     const metadata = {
@@ -4376,6 +5178,7 @@ export class Container extends BaseClient {
         value_to_name: ImageLayerCompressionValueToName,
       },
       mediaTypes: { is_enum: true, value_to_name: ImageMediaTypesValueToName },
+      sbom: { is_enum: true, value_to_name: ImageSBOMFormatValueToName },
     }
 
     const ctx = this._ctx.select("asTarball", { ...opts, __metadata: metadata })
@@ -4571,6 +5374,8 @@ export class Container extends BaseClient {
    *
    * Defaults to OCI, which is largely compatible with most recent container runtimes, but Docker may be needed for older runtimes without OCI support.
    * @param opts.expand Replace "${VAR}" or "$VAR" in the value of path according to the current environment variables defined in the container (e.g. "/$VAR/foo").
+   * @param opts.sbom Attach a software bill of materials for each platform, generated from the package databases of its root filesystem, in the given format.
+   * @param opts.provenance Attach a SLSA provenance attestation for each platform, describing the calls that built it and the images, git repositories and HTTP resources it was built from.
    */
   export = async (
     path: string,
@@ -4586,6 +5391,7 @@ export class Container extends BaseClient {
         value_to_name: ImageLayerCompressionValueToName,
       },
       mediaTypes: { is_enum: true, value_to_name: ImageMediaTypesValueToName },
+      sbom: { is_enum: true, value_to_name: ImageSBOMFormatValueToName },
     }
 
     const ctx = this._ctx.select("export", {
@@ -4676,6 +5482,12 @@ export class Container extends BaseClient {
    *
    * Defaults to "HTTPS". Use "HTTP" only for plain HTTP registries.
    * @param opts.insecureSkipTLSVerify Allow HTTPS registry communication without verifying the server certificate.
+   * @param opts.signatureKey PEM-encoded public key the image must be signed with.
+   *
+   * Signatures are looked up as cosign signatures attached to the image as OCI referrers, as pushed by "cosign sign" or Container.publish.
+   * @param opts.requireSignature Fail if the image has no signature of its digest.
+   *
+   * Requires signatureKey, which already makes a signature by that key mandatory.
    */
   from = (address: string, opts?: ContainerFromOpts): Container => {
     const metadata = {
@@ -4836,6 +5648,12 @@ export class Container extends BaseClient {
    *
    * Defaults to "HTTPS". Use "HTTP" only for plain HTTP registries.
    * @param opts.insecureSkipTLSVerify Allow HTTPS registry communication without verifying the server certificate.
+   * @param opts.sbom Attach a software bill of materials for each platform, generated from the package databases of its root filesystem, in the given format.
+   * @param opts.provenance Attach a SLSA provenance attestation for each platform, describing the calls that built it and the images, git repositories and HTTP resources it was built from.
+   * @param opts.signingKey PEM-encoded private key to sign the published image with.
+   *
+   * The signature is in the cosign format and is pushed as an OCI referrer of the image, so "cosign verify" and Container.from can check it. Encrypted keys generated by "cosign generate-key-pair" are supported.
+   * @param opts.signingKeyPassword Password to decrypt the signing key with.
    */
   publish = async (
     address: string,
@@ -4852,6 +5670,7 @@ export class Container extends BaseClient {
       },
       mediaTypes: { is_enum: true, value_to_name: ImageMediaTypesValueToName },
       protocol: { is_enum: true, value_to_name: RegistryProtocolValueToName },
+      sbom: { is_enum: true, value_to_name: ImageSBOMFormatValueToName },
     }
 
     const ctx = this._ctx.select("publish", {
@@ -4968,6 +5787,9 @@ export class Container extends BaseClient {
    * @param opts.noInit If set, skip the automatic init process injected into containers by default.
    *
    * This should only be used if the user requires that their exec process be the pid 1 process in the container. Otherwise it may result in unexpected behavior.
+   * @param opts.secretRefreshCommand Command to execute in the service when a secret set with withSecretVariable expires (e.g. ["nginx", "-s", "reload"]).
+   *
+   * Secrets expire when their provider reports a TTL, such as a Vault lease or an AWS Secrets Manager rotation. They are then resolved again and passed to this command in its environment. If empty, the service keeps running with the expired values.
    */
   up = async (opts?: ContainerUpOpts): Promise<void> => {
     if (this._up) {
@@ -5013,6 +5835,15 @@ export class Container extends BaseClient {
     return new Container(ctx)
   }
 
+  /**
+   * Set the default timeout for commands executed with withExec.
+   * @param timeout Maximum duration a command may run before it is killed. Example: "30m". An empty string or "0s" disables the default timeout.
+   */
+  withDefaultExecTimeout = (timeout: string): Container => {
+    const ctx = this._ctx.select("withDefaultExecTimeout", { timeout })
+    return new Container(ctx)
+  }
+
   /**
    * Set the default command to invoke for the container's terminal API.
    * @param args The args of the command.
@@ -5069,6 +5900,20 @@ export class Container extends BaseClient {
     return new Container(ctx)
   }
 
+  /**
+   * Restrict the destinations commands and services run in this container can connect to.
+   *
+   * Anything not allowed is denied, so a policy without arguments denies all egress. Services bound to the container stay reachable.
+   *
+   * Replaces any policy set previously. The egress policies of the engine and of the module running the container still apply. Blocked connections are recorded as events of the command's span.
+   * @param opts.allow Domains, IP addresses and CIDRs that can be reached (e.g. "github.com", "10.0.0.0/8"). Domains are resolved when commands start.
+   * @param opts.allowRegistries Allow reaching well-known public container registries and the registries configured in the engine.
+   */
+  withEgressPolicy = (opts?: ContainerWithEgressPolicyOpts): Container => {
+    const ctx = this._ctx.select("withEgressPolicy", { ...opts })
+    return new Container(ctx)
+  }
+
   /**
    * Set an OCI-style entrypoint. It will be included in the container's OCI configuration. Note, withExec ignores the entrypoint by default.
    * @param args Arguments of the entrypoint. Example: ["go", "run"].
@@ -5136,6 +5981,9 @@ export class Container extends BaseClient {
    * @param opts.noInit Skip the automatic init process injected into containers by default.
    *
    * Only use this if you specifically need the command to be pid 1 in the container. Otherwise it may result in unexpected behavior. If you're not sure, you don't need this.
+   * @param opts.timeout Kill the command and its child processes if it runs longer than this duration. Example: "5m"
+   *
+   * Defaults to the container's default exec timeout (see "withDefaultExecTimeout"). A timed out command fails with an exec error that includes the output produced so far, unless "expect" is TIMEOUT.
    */
   withExec = (args: string[], opts?: ContainerWithExecOpts): Container => {
     const metadata = {
@@ -5409,6 +6257,19 @@ export class Container extends BaseClient {
     return new Container(ctx)
   }
 
+  /**
+   * Limit the CPU, memory and process count available to commands and services run in this container.
+   *
+   * Replaces any limits set previously. A command that exceeds its memory limit is killed and fails with an exec error reporting the peak memory it used.
+   * @param opts.cpu CPU time available, as a number of CPUs (e.g. "1.5") or millicpus (e.g. "500m"). Unlimited if empty.
+   * @param opts.memory Maximum memory, in bytes or with a unit suffix (e.g. "512m", "2GiB"). Unlimited if empty.
+   * @param opts.pids Maximum number of processes running at once. Unlimited if zero.
+   */
+  withResourceLimits = (opts?: ContainerWithResourceLimitsOpts): Container => {
+    const ctx = this._ctx.select("withResourceLimits", { ...opts })
+    return new Container(ctx)
+  }
+
   /**
    * Change the container's root filesystem. The previous root filesystem will be lost.
    * @param directory The new root filesystem.
@@ -5428,6 +6289,23 @@ export class Container extends BaseClient {
     return new Container(ctx)
   }
 
+  /**
+   * Restrict what commands and services run in this container are allowed to do.
+   *
+   * Replaces any profile set previously. The engine's default security profile still applies: it can be tightened, but not loosened, and a profile violating the engine's security policy makes commands fail.
+   * @param opts.seccompProfile A seccomp profile, in the JSON format used by Docker, layered on top of the default one: syscalls are only allowed if both allow them.
+   * @param opts.capAdd Capabilities to add to the default set (e.g. "NET_BIND_SERVICE" or "CAP_NET_BIND_SERVICE"). Capabilities outside of the default set, like "SYS_ADMIN", require the engine to allow insecure root capabilities.
+   * @param opts.capDrop Capabilities to drop from the default set (e.g. "NET_RAW"), or "ALL" to drop them all.
+   * @param opts.readOnlyRootfs Mount the root filesystem read-only. Mounted directories, files and caches stay writable.
+   * @param opts.noNewPrivileges Prevent processes from gaining privileges, e.g. through setuid binaries.
+   */
+  withSecurityProfile = (
+    opts?: ContainerWithSecurityProfileOpts,
+  ): Container => {
+    const ctx = this._ctx.select("withSecurityProfile", { ...opts })
+    return new Container(ctx)
+  }
+
   /**
    * Establish a runtime dependency from a container to a network service.
    *
@@ -6940,6 +7818,21 @@ export class EngineCache extends BaseClient {
     await ctx.execute()
   }
 
+  /**
+   * Prune the releasable cache entries matching all of the given filters, or all releasable entries if none are given.
+   *
+   * Returns the entries pruned, or with dryRun the entries that would be pruned, with the disk space they free.
+   * @param opts.module Only prune the results of function calls to one of these modules, named by name, source ref with or without version (e.g. "github.com/dagger/jest"), or pinned commit.
+   * @param opts.field Only prune the results of one of these fields, named with their type (e.g. "Container.from") or alone (e.g. "from").
+   * @param opts.tag Only prune the entries produced by clients with one of these cache tags.
+   * @param opts.olderThan Only prune the entries not used for at least this long (e.g. "72h").
+   * @param opts.dryRun Report the entries that would be pruned, without pruning them.
+   */
+  pruneEntries = (opts?: EngineCachePruneEntriesOpts): EngineCacheEntrySet => {
+    const ctx = this._ctx.select("pruneEntries", { ...opts })
+    return new EngineCacheEntrySet(ctx)
+  }
+
   /**
    * The minimum amount of disk space this policy is guaranteed to retain.
    */
@@ -6969,6 +7862,15 @@ export class EngineCache extends BaseClient {
 
     return response
   }
+
+  /**
+   * Explain why a call executed instead of reusing a cached result, by comparing it with the most similar call of the same field cached before it. The call must still be in the cache.
+   * @param digest The digest of the call, as recorded on its span.
+   */
+  whyMiss = (digest: string): EngineCacheMissExplanation => {
+    const ctx = this._ctx.select("whyMiss", { digest })
+    return new EngineCacheMissExplanation(ctx)
+  }
 }
 
 /**
@@ -6981,6 +7883,7 @@ export class EngineCacheEntry extends BaseClient {
   private readonly _dagqlCall?: string = undefined
   private readonly _description?: string = undefined
   private readonly _diskSpaceBytes?: number = undefined
+  private readonly _module?: string = undefined
   private readonly _mostRecentUseTimeUnixNano?: number = undefined
   private readonly _recordType?: string = undefined
 
@@ -6995,6 +7898,7 @@ export class EngineCacheEntry extends BaseClient {
     _dagqlCall?: string,
     _description?: string,
     _diskSpaceBytes?: number,
+    _module?: string,
     _mostRecentUseTimeUnixNano?: number,
     _recordType?: string,
   ) {
@@ -7006,6 +7910,7 @@ export class EngineCacheEntry extends BaseClient {
     this._dagqlCall = _dagqlCall
     this._description = _description
     this._diskSpaceBytes = _diskSpaceBytes
+    this._module = _module
     this._mostRecentUseTimeUnixNano = _mostRecentUseTimeUnixNano
     this._recordType = _recordType
   }
@@ -7100,6 +8005,21 @@ export class EngineCacheEntry extends BaseClient {
     return response
   }
 
+  /**
+   * The source ref, or the name of a module without one, of the module whose function call produced this cache entry, if any.
+   */
+  module_ = async (): Promise<string> => {
+    if (this._module) {
+      return this._module
+    }
+
+    const ctx = this._ctx.select("module")
+
+    const response: Awaited<string> = await ctx.execute()
+
+    return response
+  }
+
   /**
    * The most recent time the cache entry was used, in Unix nanoseconds.
    */
@@ -7140,6 +8060,17 @@ export class EngineCacheEntry extends BaseClient {
 
     return response
   }
+
+  /**
+   * The cache tags of the client that produced this cache entry.
+   */
+  tags = async (): Promise<string[]> => {
+    const ctx = this._ctx.select("tags")
+
+    const response: Awaited<string[]> = await ctx.execute()
+
+    return response
+  }
 }
 
 /**
@@ -7230,6 +8161,197 @@ export class EngineCacheEntrySet extends BaseClient {
   }
 }
 
+/**
+ * An input that differs between a call and the nearest cached one
+ */
+export class EngineCacheMissDifference extends BaseClient {
+  private readonly _id?: ID = undefined
+  private readonly _cached?: string = undefined
+  private readonly _call?: string = undefined
+  private readonly _current?: string = undefined
+  private readonly _input?: string = undefined
+
+  /**
+   * Constructor is used for internal usage only, do not create object from it.
+   */
+  constructor(
+    ctx?: Context,
+    _id?: ID,
+    _cached?: string,
+    _call?: string,
+    _current?: string,
+    _input?: string,
+  ) {
+    super(ctx)
+
+    this._id = _id
+    this._cached = _cached
+    this._call = _call
+    this._current = _current
+    this._input = _input
+  }
+
+  /**
+   * A unique identifier for this EngineCacheMissDifference.
+   */
+  id = async (): Promise<ID> => {
+    if (this._id) {
+      return this._id
+    }
+
+    const ctx = this._ctx.select("id")
+
+    const response: Awaited<ID> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * The input as the nearest cached call had it.
+   */
+  cached = async (): Promise<string> => {
+    if (this._cached) {
+      return this._cached
+    }
+
+    const ctx = this._ctx.select("cached")
+
+    const response: Awaited<string> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * The call the input belongs to, as called this time.
+   */
+  call = async (): Promise<string> => {
+    if (this._call) {
+      return this._call
+    }
+
+    const ctx = this._ctx.select("call")
+
+    const response: Awaited<string> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * The input as the explained call had it.
+   */
+  current = async (): Promise<string> => {
+    if (this._current) {
+      return this._current
+    }
+
+    const ctx = this._ctx.select("current")
+
+    const response: Awaited<string> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * The name of the input: an argument, an implicit input suffixed with " (implicit)", or one of "field", "receiver", "module", "view", "nth" and "content".
+   */
+  input = async (): Promise<string> => {
+    if (this._input) {
+      return this._input
+    }
+
+    const ctx = this._ctx.select("input")
+
+    const response: Awaited<string> = await ctx.execute()
+
+    return response
+  }
+}
+
+/**
+ * Why a call executed instead of reusing a cached result
+ */
+export class EngineCacheMissExplanation extends BaseClient {
+  private readonly _id?: ID = undefined
+  private readonly _call?: string = undefined
+  private readonly _nearest?: string = undefined
+
+  /**
+   * Constructor is used for internal usage only, do not create object from it.
+   */
+  constructor(ctx?: Context, _id?: ID, _call?: string, _nearest?: string) {
+    super(ctx)
+
+    this._id = _id
+    this._call = _call
+    this._nearest = _nearest
+  }
+
+  /**
+   * A unique identifier for this EngineCacheMissExplanation.
+   */
+  id = async (): Promise<ID> => {
+    if (this._id) {
+      return this._id
+    }
+
+    const ctx = this._ctx.select("id")
+
+    const response: Awaited<ID> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * The digest of the explained call.
+   */
+  call = async (): Promise<string> => {
+    if (this._call) {
+      return this._call
+    }
+
+    const ctx = this._ctx.select("call")
+
+    const response: Awaited<string> = await ctx.execute()
+
+    return response
+  }
+
+  /**
+   * The inputs that differ from the nearest cached call. Differing objects are compared in turn, down to the inputs that diverged first. Empty when an identical call was cached but couldn't be reused, because it expired or needs secrets or sockets the client hasn't loaded.
+   */
+  differences = async (): Promise<EngineCacheMissDifference[]> => {
+    type differences = {
+      id: ID
+    }
+
+    const ctx = this._ctx.select("differences").select("id")
+
+    const response: Awaited<differences[]> = await ctx.execute()
+
+    return response.map(
+      (r) =>
+        new EngineCacheMissDifference(
+          ctx.copy().selectNode(r.id, "EngineCacheMissDifference"),
+        ),
+    )
+  }
+
+  /**
+   * The digest of the most similar call of the same field cached before it, or empty if there was none.
+   */
+  nearest = async (): Promise<string> => {
+    if (this._nearest) {
+      return this._nearest
+    }
+
+    const ctx = this._ctx.select("nearest")
+
+    const response: Awaited<string> = await ctx.execute()
+
+    return response
+  }
+}
+
 /**
  * A definition of a custom enum defined in a Module.
  */
@@ -8459,9 +9581,12 @@ export class Function_ extends BaseClient {
 
   /**
    * Returns the function with a flag indicating it's a check.
+   * @param opts.retries How many times to retry the check when it fails, before failing it.
+   *
+   * A check that passes on a retry is reported as flaky.
    */
-  withCheck = (): Function_ => {
-    const ctx = this._ctx.select("withCheck")
+  withCheck = (opts?: FunctionWithCheckOpts): Function_ => {
+    const ctx = this._ctx.select("withCheck", { ...opts })
     return new Function_(ctx)
   }
 
@@ -10721,6 +11846,25 @@ export class LLM extends BaseClient {
     return response
   }
 
+  /**
+   * The model's most recent reply, decoded as a JSON value matching the output schema set with withOutputSchema.
+   */
+  output = (): JSONValue => {
+    const ctx = this._ctx.select("output")
+    return new JSONValue(ctx)
+  }
+
+  /**
+   * Run sub-agents concurrently, one per prompt, each looping in its own fork of the conversation with its own copy of the workspace. Their replies, or failures, are then queued as a single prompt for the model to act on, and the changes they made to the bound workspace are merged into it. At most 8 sub-agents loop at once. The tokens they consume, even those that failed, count towards this conversation's usage, and what's left of its budget is split evenly between them. Fails if every sub-agent failed, if their workspace changes conflict, or if they went over the budget.
+   * @param prompts The prompt to send each sub-agent.
+   * @param opts.maxSteps Cap the number of steps of each sub-agent's loop.
+   * @param opts.maxTokens Cap the model's output tokens on each step of the sub-agents. Defaults to the model's maximum.
+   */
+  parallel = (prompts: string[], opts?: LLMParallelOpts): LLM => {
+    const ctx = this._ctx.select("parallel", { prompts, ...opts })
+    return new LLM(ctx)
+  }
+
   /**
    * A portable, self-contained ID for the conversation that node() can resolve in any session. Unlike id, which may return an engine-local runtime handle valid only within the current session, this returns the recipe form suitable for persisting and later restoring the conversation. The recipe is flattened: bindings superseded during the session (workspace overlays recorded by each mutating tool call, and re-bound toolsets) are dropped, while the current workspace binding — including any pending, un-exported edits — is preserved.
    */
@@ -10852,13 +11996,30 @@ export class LLM extends BaseClient {
     return response
   }
 
+  /**
+   * Set a budget for the conversation, counted over all of its API calls as reported by tokenUsage. Once any limit is reached, step and loop fail with an LLM_BUDGET_EXCEEDED error carrying the final transcript, without calling the model again.
+   * @param opts.maxInputTokens Cap the input tokens, cached or not. Zero means no limit.
+   * @param opts.maxOutputTokens Cap the output tokens. Each step's output is also capped to what's left. Zero means no limit.
+   * @param opts.maxCostUSD Cap the cost in US dollars, priced from the model catalog at the rates of the current model. Zero means no limit.
+   */
+  withBudget = (opts?: LLMWithBudgetOpts): LLM => {
+    const ctx = this._ctx.select("withBudget", { ...opts })
+    return new LLM(ctx)
+  }
+
   /**
    * Add an external MCP server to the LLM
+   *
+   * The server is either a service spoken to over stdio, a port of a service or a URL serving the MCP streamable HTTP transport.
    * @param name The name of the MCP server
-   * @param service The MCP service to run and communicate with over stdio
+   * @param opts.service The MCP service to run and communicate with over stdio, unless port is set
+   * @param opts.port Port of the service serving the MCP streamable HTTP transport
+   * @param opts.path Path of the MCP streamable HTTP endpoint of the service
+   * @param opts.url URL of a remote MCP server serving the streamable HTTP transport, instead of a service
+   * @param opts.httpAuthHeader Secret used to populate the Authorization HTTP header
    */
-  withMCPServer = (name: string, service: Service): LLM => {
-    const ctx = this._ctx.select("withMCPServer", { name, service })
+  withMCPServer = (name: string, opts?: LLMWithMcpServerOpts): LLM => {
+    const ctx = this._ctx.select("withMCPServer", { name, ...opts })
     return new LLM(ctx)
   }
 
@@ -10872,6 +12033,16 @@ export class LLM extends BaseClient {
     return new LLM(ctx)
   }
 
+  /**
+   * Require the model's final reply to be a JSON value matching a schema, enforced with the provider's native structured output where supported. A reply that doesn't match is sent back to the model to fix, a few times at most before step and loop fail with an LLM_OUTPUT_MISMATCH error. Read the reply with output.
+   * @param opts.typeDef The type of the value to reply with, e.g. an object whose fields the model fills in. Objects referenced by name are resolved through the calling module. Exclusive with schema.
+   * @param opts.schema A JSON Schema for the value to reply with. Exclusive with typeDef.
+   */
+  withOutputSchema = (opts?: LLMWithOutputSchemaOpts): LLM => {
+    const ctx = this._ctx.select("withOutputSchema", { ...opts })
+    return new LLM(ctx)
+  }
+
   /**
    * Queue a user prompt, to be sent to the model on the next step or loop.
    * @param prompt The prompt to send
@@ -10934,6 +12105,21 @@ export class LLM extends BaseClient {
     return new LLM(ctx)
   }
 
+  /**
+   * Add a policy approving the model's tool calls. The last policy added that matches a tool applies, unless a [[llm.tools]] policy of the workspace matching it is more restrictive; with none matching, the call is allowed. A refused call is reported to the model as a failed tool call.
+   * @param tool A glob pattern matched against the tool name, e.g. "withExec" or "publish*". Tools named after a bound object or MCP server match both with and without that prefix.
+   * @param action "allow" to make the call, "ask" to ask the user first (a client that can't prompt denies it), or "deny" to refuse it.
+   * @param opts.readOnly Only apply the policy to read-only tools.
+   */
+  withToolPolicy = (
+    tool: string,
+    action: string,
+    opts?: LLMWithToolPolicyOpts,
+  ): LLM => {
+    const ctx = this._ctx.select("withToolPolicy", { tool, action, ...opts })
+    return new LLM(ctx)
+  }
+
   /**
    * Append the result of a tool call to the message history.
    * @param callId The ID of the tool call this result responds to
@@ -13069,6 +14255,33 @@ export class Client extends BaseClient {
     return new Changeset(ctx)
   }
 
+  /**
+   * Create a finding, for a lint check to report a problem.
+   *
+   * A check function returning a list of findings fails if any of them is an error.
+   * @param message A description of the problem.
+   * @param opts.level How serious the problem is.
+   * @param opts.rule The identifier of the rule that found the problem.
+   * @param opts.path The path of the file with the problem, relative to the root of the checked source.
+   * @param opts.line The line of the problem, starting at 1.
+   * @param opts.column The column of the problem, starting at 1.
+   */
+  checkFinding = (
+    message: string,
+    opts?: ClientCheckFindingOpts,
+  ): CheckFinding => {
+    const metadata = {
+      level: { is_enum: true, value_to_name: CheckFindingLevelValueToName },
+    }
+
+    const ctx = this._ctx.select("checkFinding", {
+      message,
+      ...opts,
+      __metadata: metadata,
+    })
+    return new CheckFinding(ctx)
+  }
+
   /**
    * Dagger Cloud configuration and state
    */
@@ -14819,16 +16032,24 @@ export class TypeDef extends BaseClient {
 export class Up extends BaseClient {
   private readonly _id?: ID = undefined
   private readonly _description?: string = undefined
+  private readonly _digest?: string = undefined
   private readonly _name?: string = undefined
 
   /**
    * Constructor is used for internal usage only, do not create object from it.
    */
-  constructor(ctx?: Context, _id?: ID, _description?: string, _name?: string) {
+  constructor(
+    ctx?: Context,
+    _id?: ID,
+    _description?: string,
+    _digest?: string,
+    _name?: string,
+  ) {
     super(ctx)
 
     this._id = _id
     this._description = _description
+    this._digest = _digest
     this._name = _name
   }
 
@@ -14862,6 +16083,23 @@ export class Up extends BaseClient {
     return response
   }
 
+  /**
+   * A digest of the service and the host ports it is forwarded on.
+   *
+   * It changes when the service's inputs change, and the running service is out of date.
+   */
+  digest = async (): Promise<string> => {
+    if (this._digest) {
+      return this._digest
+    }
+
+    const ctx = this._ctx.select("digest")
+
+    const response: Awaited<string> = await ctx.execute()
+
+    return response
+  }
+
   /**
    * Return the fully qualified name of the service
    */
@@ -15704,6 +16942,19 @@ export class WorkspaceGit extends BaseClient {
     return response
   }
 
+  /**
+   * The changes in this workspace since it diverged from the given ref, uncommitted changes included.
+   *
+   * Like `git diff REF...`, this compares against the common ancestor of the ref and HEAD, so that changes made on the ref since don't count.
+   *
+   * Files ignored by git are left out.
+   * @param ref The ref to compare against, e.g. origin/main.
+   */
+  changesSince = (ref: string): Changeset => {
+    const ctx = this._ctx.select("changesSince", { ref })
+    return new Changeset(ctx)
+  }
+
   /**
    * The checked-out HEAD of this workspace.
    */
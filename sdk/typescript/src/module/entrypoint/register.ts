@@ -165,7 +165,7 @@ export class Register {
     }
 
     if ((fct as Method).isCheck) {
-      fnDef = fnDef.withCheck()
+      fnDef = fnDef.withCheck({ retries: (fct as Method).checkRetries })
     }
 
     if ((fct as Method).isGenerator) {
@@ -2,7 +2,7 @@ import ts from "typescript"
 
 import { TypeDefKind } from "../../../api/client.gen.js"
 import { IntrospectionError } from "../../../common/errors/index.js"
-import { FunctionOptions } from "../../registry.js"
+import { CheckOptions, FunctionOptions } from "../../registry.js"
 import { TypeDef } from "../typedef.js"
 import {
   AST,
@@ -32,6 +32,7 @@ export class DaggerFunction extends Locatable {
   public alias: string | undefined
   public cache: string | undefined
   public isCheck: boolean = false
+  public checkRetries: number = 0
   public isGenerator: boolean = false
   public isUp: boolean = false
   public isAgent: boolean = false
@@ -69,6 +70,19 @@ export class DaggerFunction extends Locatable {
     // Parse @check decorator
     if (this.ast.isNodeDecoratedWith(this.node, CHECK_DECORATOR)) {
       this.isCheck = true
+
+      const checkArguments = this.ast.getDecoratorArgument<CheckOptions>(
+        this.node,
+        CHECK_DECORATOR,
+        "object",
+      )
+      const retries = checkArguments?.retries ?? 0
+      if (!Number.isInteger(retries) || retries < 0) {
+        throw new IntrospectionError(
+          `check retries of ${this.name} at ${AST.getNodePosition(this.node)} must be a non-negative integer, got ${retries}.`,
+        )
+      }
+      this.checkRetries = retries
     }
 
     // Parse @generate decorator
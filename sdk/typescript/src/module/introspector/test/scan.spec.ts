@@ -158,6 +158,19 @@ ${jsonResult}
     })
   }
 
+  it("Should read the retries of checks", async function () {
+    this.timeout(60000)
+
+    const files = await listFiles(`${rootDirectory}/decorators`)
+    const result = await scan(files, "decorators")
+    const methods = result.objects["Decorators"].methods
+
+    assert.equal(methods["checkSomething"].isCheck, true)
+    assert.equal(methods["checkSomething"].checkRetries, 0)
+    assert.equal(methods["flakyCheck"].isCheck, true)
+    assert.equal(methods["flakyCheck"].checkRetries, 2)
+  })
+
   describe("Should throw error on invalid module", function () {
     it("Should throw an error when no files are provided", async function () {
       this.timeout(60000)
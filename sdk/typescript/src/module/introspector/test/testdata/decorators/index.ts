@@ -146,6 +146,10 @@ export class Decorators {
   @check()
   checkSomething(): void {}
 
+  @func()
+  @check({ retries: 2 })
+  flakyCheck(): void {}
+
   @func()
   @generate()
   generateSomething(): Changeset {
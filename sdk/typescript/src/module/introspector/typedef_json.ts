@@ -70,6 +70,7 @@ function serializeFunction(fn: DaggerFunction | DaggerInterfaceFunction) {
     description: f.description,
     deprecated: f.deprecated,
     isCheck: f.isCheck === true,
+    checkRetries: f.checkRetries ?? 0,
     isGenerator: f.isGenerator === true,
     isUp: f.isUp === true,
     isAgent: f.isAgent === true,
@@ -64,6 +64,14 @@ export type FunctionOptions = {
   alias?: string
 }
 
+export type CheckOptions = {
+  /**
+   * How many times to retry the check when it fails, before failing it.
+   * A check that passes on a retry is reported as flaky.
+   */
+  retries?: number
+}
+
 /**
  * Registry stores class and method that have the @object decorator.
  *
@@ -137,7 +145,9 @@ export class Registry {
   /**
    * The definition of @check decorator that marks a function as a check.
    */
-  check = (): ((
+  check = (
+    opts?: CheckOptions,
+  ): ((
     target: object,
     propertyKey: string | symbol,
     descriptor?: PropertyDescriptor,
@@ -0,0 +1,306 @@
+// Package checkreport renders the outcome of checks in the formats CI systems
+// consume: JUnit XML for test results, and SARIF for lint findings.
+package checkreport
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Check is the outcome of one check.
+type Check struct {
+	Name      string
+	Completed bool
+	Passed    bool
+	// Flaky indicates the check failed at first but passed on a retry.
+	Flaky bool
+	// Attempts is how many times the check ran, counting retries.
+	Attempts int
+	// Error is why the check failed.
+	Error    string
+	Duration time.Duration
+	// Tests are the individual test cases the check ran, if it reported any.
+	Tests []Test
+	// Findings are the problems reported by a lint check.
+	Findings []Finding
+}
+
+// Test is the outcome of one test case run by a check.
+type Test struct {
+	Suite    string
+	Name     string
+	Duration time.Duration
+	Failed   bool
+	Skipped  bool
+	// Message is why the test failed.
+	Message string
+}
+
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+// Finding is a problem reported by a lint check, located in a source file.
+type Finding struct {
+	Message string
+	// Level is LevelError, LevelWarning or LevelNote.
+	Level string
+	Rule  string
+	// Path is relative to the root of the checked source. Line and Column
+	// start at 1, and are zero if unknown.
+	Path   string
+	Line   int
+	Column int
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	// FlakyFailures are the failed runs of a test case that passed on a
+	// rerun, as reported by Maven Surefire and understood by most CI systems.
+	FlakyFailures []junitFailure `xml:"flakyFailure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Details string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// JUnit renders checks as a JUnit XML report, with one test suite per check.
+//
+// A check's test cases are its tests. A check that reported no tests is a test
+// case of its own, and so is a failed check none of whose tests failed, so the
+// failure is never lost. Checks that didn't complete are skipped. A flaky check
+// passes, with a flaky failure for each of its failed attempts.
+func JUnit(checks []Check) ([]byte, error) {
+	report := junitTestSuites{Name: "dagger check"}
+	var total time.Duration
+	for _, check := range checks {
+		suite := junitTestSuite{
+			Name: check.Name,
+			Time: junitTime(check.Duration),
+		}
+		testFailed := false
+		for _, test := range check.Tests {
+			tc := junitTestCase{
+				Name:      test.Name,
+				ClassName: cmp.Or(test.Suite, check.Name),
+				Time:      junitTime(test.Duration),
+			}
+			switch {
+			case test.Failed:
+				testFailed = true
+				tc.Failure = &junitFailure{
+					Message: firstLine(cmp.Or(test.Message, "test failed")),
+					Details: test.Message,
+				}
+			case test.Skipped:
+				tc.Skipped = &junitSkipped{}
+			}
+			suite.add(tc)
+		}
+		if len(check.Tests) == 0 || (check.Completed && !check.Passed && !testFailed) || check.Flaky {
+			tc := junitTestCase{
+				Name:      check.Name,
+				ClassName: check.Name,
+				Time:      junitTime(check.Duration),
+			}
+			switch {
+			case !check.Completed:
+				tc.Skipped = &junitSkipped{Message: "check did not run"}
+			case !check.Passed:
+				tc.Failure = &junitFailure{
+					Message: firstLine(cmp.Or(check.Error, "check failed")),
+					Details: check.Error,
+				}
+			case check.Flaky:
+				for attempt := 1; attempt < check.Attempts; attempt++ {
+					tc.FlakyFailures = append(tc.FlakyFailures, junitFailure{
+						Message: fmt.Sprintf("attempt %d of %d failed", attempt, check.Attempts),
+					})
+				}
+			}
+			suite.add(tc)
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+		report.Suites = append(report.Suites, suite)
+		total += check.Duration
+	}
+	report.Time = junitTime(total)
+
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode junit report: %w", err)
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+func (suite *junitTestSuite) add(tc junitTestCase) {
+	suite.Tests++
+	switch {
+	case tc.Failure != nil:
+		suite.Failures++
+	case tc.Skipped != nil:
+		suite.Skipped++
+	}
+	suite.Cases = append(suite.Cases, tc)
+}
+
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func firstLine(s string) string {
+	for i, r := range s {
+		if r == '\n' {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// sarifSchema is the JSON schema of the SARIF version we emit.
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// SARIF renders the checks' findings as a SARIF 2.1.0 log. A finding without
+// a rule is reported under the name of its check.
+func SARIF(checks []Check) ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "dagger",
+			InformationURI: "https://dagger.io",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	rules := map[string]struct{}{}
+	for _, check := range checks {
+		for _, finding := range check.Findings {
+			result := sarifResult{
+				RuleID:     cmp.Or(finding.Rule, check.Name),
+				Level:      cmp.Or(finding.Level, LevelError),
+				Message:    sarifMessage{Text: finding.Message},
+				Properties: map[string]string{"check": check.Name},
+			}
+			if finding.Path != "" {
+				loc := sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: finding.Path},
+				}
+				if finding.Line > 0 {
+					loc.Region = &sarifRegion{
+						StartLine:   finding.Line,
+						StartColumn: finding.Column,
+					}
+				}
+				result.Locations = []sarifLocation{{PhysicalLocation: loc}}
+			}
+			if _, ok := rules[result.RuleID]; !ok {
+				rules[result.RuleID] = struct{}{}
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: result.RuleID})
+			}
+			run.Results = append(run.Results, result)
+		}
+	}
+	slices.SortFunc(run.Tool.Driver.Rules, func(a, b sarifRule) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	out, err := json.MarshalIndent(sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode sarif report: %w", err)
+	}
+	return append(out, '\n'), nil
+}
@@ -0,0 +1,102 @@
+package checkreport
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJUnit(t *testing.T) {
+	out, err := JUnit([]Check{
+		{Name: "lint", Completed: true, Passed: true, Duration: 1500 * time.Millisecond},
+		{Name: "build", Completed: true, Error: "exit code 2\nmore output"},
+		{Name: "e2e"},
+		{Name: "test", Completed: true, Tests: []Test{
+			{Suite: "pkg", Name: "TestOK", Duration: time.Second},
+			{Suite: "pkg", Name: "TestBad", Failed: true, Message: "want 1\ngot 2"},
+			{Name: "TestLater", Skipped: true},
+		}},
+		// failed, but none of its tests did: the check's own failure is kept
+		{Name: "vet", Completed: true, Error: "compile error", Tests: []Test{
+			{Name: "TestOK"},
+		}},
+		// passed on its third attempt
+		{Name: "integration", Completed: true, Passed: true, Flaky: true, Attempts: 3, Tests: []Test{
+			{Name: "TestFlaky"},
+		}},
+	})
+	require.NoError(t, err)
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(out, &report))
+	require.Equal(t, 10, report.Tests)
+	require.Equal(t, 3, report.Failures)
+	require.Equal(t, 2, report.Skipped)
+	require.Equal(t, "1.500", report.Time)
+	require.Len(t, report.Suites, 6)
+
+	lint := report.Suites[0]
+	require.Equal(t, "lint", lint.Name)
+	require.Len(t, lint.Cases, 1)
+	require.Nil(t, lint.Cases[0].Failure)
+
+	build := report.Suites[1].Cases[0]
+	require.Equal(t, "exit code 2", build.Failure.Message)
+	require.Equal(t, "exit code 2\nmore output", build.Failure.Details)
+
+	require.NotNil(t, report.Suites[2].Cases[0].Skipped)
+
+	test := report.Suites[3]
+	require.Equal(t, 3, test.Tests)
+	require.Equal(t, 1, test.Failures)
+	require.Equal(t, "pkg", test.Cases[1].ClassName)
+	require.Equal(t, "want 1", test.Cases[1].Failure.Message)
+	require.Equal(t, "test", test.Cases[2].ClassName)
+
+	vet := report.Suites[4]
+	require.Len(t, vet.Cases, 2)
+	require.Equal(t, "compile error", vet.Cases[1].Failure.Message)
+
+	integration := report.Suites[5]
+	require.Equal(t, 0, integration.Failures)
+	require.Len(t, integration.Cases, 2)
+	require.Nil(t, integration.Cases[1].Failure)
+	require.Equal(t, []junitFailure{
+		{Message: "attempt 1 of 3 failed"},
+		{Message: "attempt 2 of 3 failed"},
+	}, integration.Cases[1].FlakyFailures)
+}
+
+func TestSARIF(t *testing.T) {
+	out, err := SARIF([]Check{
+		{Name: "lint", Findings: []Finding{
+			{Message: "unused variable", Level: LevelWarning, Rule: "unused", Path: "main.go", Line: 3, Column: 5},
+			{Message: "missing license"},
+		}},
+		{Name: "test", Completed: true, Passed: true},
+	})
+	require.NoError(t, err)
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(out, &log))
+	require.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	require.Equal(t, []sarifRule{{ID: "lint"}, {ID: "unused"}}, run.Tool.Driver.Rules)
+	require.Len(t, run.Results, 2)
+
+	unused := run.Results[0]
+	require.Equal(t, "unused", unused.RuleID)
+	require.Equal(t, LevelWarning, unused.Level)
+	require.Equal(t, "main.go", unused.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	require.Equal(t, &sarifRegion{StartLine: 3, StartColumn: 5}, unused.Locations[0].PhysicalLocation.Region)
+	require.Equal(t, "lint", unused.Properties["check"])
+
+	license := run.Results[1]
+	require.Equal(t, "lint", license.RuleID)
+	require.Equal(t, LevelError, license.Level)
+	require.Empty(t, license.Locations)
+}
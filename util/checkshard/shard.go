@@ -0,0 +1,141 @@
+// Package checkshard splits checks deterministically across the jobs of a CI
+// run, either evenly by count or balanced by how long each check took before,
+// as recorded in a timing file.
+package checkshard
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// Timings are how long each check took when it last ran, by check name.
+type Timings map[string]time.Duration
+
+// timingsFile is the JSON layout of a timing file, with durations in seconds.
+type timingsFile struct {
+	Checks map[string]float64 `json:"checks"`
+}
+
+// ParseTimings reads a timing file.
+func ParseTimings(data []byte) (Timings, error) {
+	var file timingsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse check timings: %w", err)
+	}
+	timings := make(Timings, len(file.Checks))
+	for name, seconds := range file.Checks {
+		if seconds < 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+			return nil, fmt.Errorf("parse check timings: %s: invalid duration %v", name, seconds)
+		}
+		timings[name] = time.Duration(seconds * float64(time.Second))
+	}
+	return timings, nil
+}
+
+// Marshal renders the timings as a timing file.
+func (t Timings) Marshal() ([]byte, error) {
+	file := timingsFile{Checks: make(map[string]float64, len(t))}
+	for name, d := range t {
+		// milliseconds are plenty, and keep the file readable
+		file.Checks[name] = math.Round(d.Seconds()*1000) / 1000
+	}
+	out, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// ParseShard parses a shard given as "index/total", e.g. "2/5" for the
+// second of five shards.
+func ParseShard(s string) (index, total int, err error) {
+	if _, err := fmt.Sscanf(s, "%d/%d", &index, &total); err != nil || fmt.Sprintf("%d/%d", index, total) != s {
+		return 0, 0, fmt.Errorf("invalid shard %q: expected index/total, e.g. 2/5", s)
+	}
+	if err := Validate(index, total); err != nil {
+		return 0, 0, fmt.Errorf("invalid shard %q: %w", s, err)
+	}
+	return index, total, nil
+}
+
+// Validate checks that index is a shard of total, counting from 1.
+func Validate(index, total int) error {
+	if total < 1 {
+		return fmt.Errorf("total number of shards must be at least 1, got %d", total)
+	}
+	if index < 1 || index > total {
+		return fmt.Errorf("shard index must be between 1 and %d, got %d", total, index)
+	}
+	return nil
+}
+
+// Split assigns each of the named checks to one of total shards, and returns
+// the names in each shard, sorted.
+//
+// Checks are handed out longest first, each to the shard with the least work
+// so far, going by timings. A check missing from timings counts as taking
+// the average of those that aren't, so that new checks spread out too. With
+// no timings at all, every check weighs the same and the shards get the same
+// number of checks, give or take one.
+//
+// The split only depends on the names and timings, so every job of a CI run
+// computes the same one, whatever order it lists the checks in.
+func Split(names []string, total int, timings Timings) [][]string {
+	names = slices.Clone(names)
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	var known time.Duration
+	var nknown int
+	for _, name := range names {
+		if d, ok := timings[name]; ok {
+			known += d
+			nknown++
+		}
+	}
+	fallback := time.Second
+	if nknown > 0 {
+		fallback = known / time.Duration(nknown)
+	}
+	weight := func(name string) time.Duration {
+		if d, ok := timings[name]; ok {
+			return d
+		}
+		return fallback
+	}
+
+	// sorting is stable, so equal weights stay in name order
+	slices.SortStableFunc(names, func(a, b string) int {
+		return cmp.Compare(weight(b), weight(a))
+	})
+
+	shards := make([][]string, total)
+	loads := make([]time.Duration, total)
+	for _, name := range names {
+		least := 0
+		for i := range loads {
+			if loads[i] < loads[least] {
+				least = i
+			}
+		}
+		shards[least] = append(shards[least], name)
+		loads[least] += weight(name)
+	}
+	for _, shard := range shards {
+		slices.Sort(shard)
+	}
+	return shards
+}
+
+// Shard returns the names of the checks in shard index of total, counting
+// from 1, as split by Split.
+func Shard(names []string, index, total int, timings Timings) ([]string, error) {
+	if err := Validate(index, total); err != nil {
+		return nil, err
+	}
+	return Split(names, total, timings)[index-1], nil
+}
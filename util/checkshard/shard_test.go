@@ -0,0 +1,89 @@
+package checkshard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitByCount(t *testing.T) {
+	names := []string{"e", "b", "d", "a", "c"}
+	require.Equal(t, [][]string{
+		{"a", "d"},
+		{"b", "e"},
+		{"c"},
+	}, Split(names, 3, nil))
+
+	// the order the checks are listed in doesn't matter
+	require.Equal(t, Split(names, 3, nil), Split([]string{"a", "b", "c", "d", "e"}, 3, nil))
+
+	// more shards than checks leaves some empty
+	require.Equal(t, [][]string{{"a"}, {"b"}, nil}, Split([]string{"b", "a"}, 3, nil))
+}
+
+func TestSplitByDuration(t *testing.T) {
+	timings := Timings{
+		"slow":   10 * time.Minute,
+		"medium": 4 * time.Minute,
+		"fast1":  3 * time.Minute,
+		"fast2":  2 * time.Minute,
+		"fast3":  time.Minute,
+	}
+	names := []string{"fast1", "fast2", "fast3", "medium", "slow"}
+	require.Equal(t, [][]string{
+		{"slow"},
+		{"fast1", "fast2", "fast3", "medium"},
+	}, Split(names, 2, timings))
+
+	// a new check counts as taking the average (4m) of the known ones
+	require.Equal(t, [][]string{
+		{"fast2", "slow"},
+		{"fast1", "fast3", "medium", "new"},
+	}, Split(append(names, "new"), 2, timings))
+}
+
+func TestShard(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	shard, err := Shard(names, 2, 2, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b"}, shard)
+
+	_, err = Shard(names, 0, 2, nil)
+	require.ErrorContains(t, err, "between 1 and 2")
+	_, err = Shard(names, 3, 2, nil)
+	require.ErrorContains(t, err, "between 1 and 2")
+	_, err = Shard(names, 1, 0, nil)
+	require.ErrorContains(t, err, "at least 1")
+}
+
+func TestParseShard(t *testing.T) {
+	index, total, err := ParseShard("2/5")
+	require.NoError(t, err)
+	require.Equal(t, 2, index)
+	require.Equal(t, 5, total)
+
+	for _, bad := range []string{"", "2", "2/", "/5", "a/b", "2/5/1", " 2/5", "6/5", "0/5"} {
+		_, _, err := ParseShard(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestTimingsRoundTrip(t *testing.T) {
+	timings := Timings{
+		"go:lint": 1500 * time.Millisecond,
+		"go:test": 2 * time.Minute,
+	}
+	out, err := timings.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"checks": {"go:lint": 1.5, "go:test": 120}}`, string(out))
+
+	parsed, err := ParseTimings(out)
+	require.NoError(t, err)
+	require.Equal(t, timings, parsed)
+
+	_, err = ParseTimings([]byte(`{"checks": {"go:lint": -1}}`))
+	require.ErrorContains(t, err, "invalid duration")
+	_, err = ParseTimings([]byte(`not json`))
+	require.Error(t, err)
+}
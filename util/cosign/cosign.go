@@ -0,0 +1,285 @@
+// Package cosign signs and verifies container images with keys, using the
+// signature format of cosign (https://github.com/sigstore/cosign) and storing
+// signatures as OCI referrers of the signed manifest.
+//
+// Only key-based signing is supported: there's no keyless signing and no
+// transparency log, so signatures can be created and verified offline.
+package cosign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/secure-systems-lab/go-securesystemslib/encrypted"
+)
+
+const (
+	// ArtifactTypeSignature is the artifact type of signature manifests.
+	ArtifactTypeSignature = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	// MediaTypeSimpleSigning is the media type of signed payload layers.
+	MediaTypeSimpleSigning = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// AnnotationSignature holds the base64 encoded signature of a payload
+	// layer.
+	AnnotationSignature = "dev.cosignproject.cosign/signature"
+
+	signatureType = "cosign container image signature"
+)
+
+// PEM block types of keys generated by cosign, in addition to the standard
+// PKCS #8, SEC 1 and PKCS #1 types.
+const (
+	pemTypeEncryptedSigstore = "ENCRYPTED SIGSTORE PRIVATE KEY"
+	pemTypeEncryptedCosign   = "ENCRYPTED COSIGN PRIVATE KEY"
+)
+
+// ErrPasswordRequired is returned when loading an encrypted private key
+// without a password.
+var ErrPasswordRequired = errors.New("private key is encrypted, but no password was provided")
+
+// simpleSigning is the signed document, in the "simple signing" format.
+type simpleSigning struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]any `json:"optional"`
+}
+
+// NewPayload returns the payload to sign for the manifest with the given
+// digest in the given repository.
+func NewPayload(repository string, manifest digest.Digest) ([]byte, error) {
+	var p simpleSigning
+	p.Critical.Identity.DockerReference = repository
+	p.Critical.Image.DockerManifestDigest = manifest.String()
+	p.Critical.Type = signatureType
+	return json.Marshal(p)
+}
+
+// CheckPayload checks that a payload is a signature of the manifest with the
+// given digest.
+func CheckPayload(dt []byte, manifest digest.Digest) error {
+	var p simpleSigning
+	if err := json.Unmarshal(dt, &p); err != nil {
+		return fmt.Errorf("decode signature payload: %w", err)
+	}
+	if p.Critical.Type != signatureType {
+		return fmt.Errorf("unexpected signature type %q", p.Critical.Type)
+	}
+	if p.Critical.Image.DockerManifestDigest != manifest.String() {
+		return fmt.Errorf("signature is for %s, not %s", p.Critical.Image.DockerManifestDigest, manifest)
+	}
+	return nil
+}
+
+// LoadPrivateKey parses a PEM encoded private key. Keys generated by cosign
+// are encrypted with a password; unencrypted PKCS #8, EC and RSA keys are
+// also accepted.
+func LoadPrivateKey(pemBytes, password []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM block found in private key")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case pemTypeEncryptedSigstore, pemTypeEncryptedCosign:
+		if len(password) == 0 {
+			return nil, ErrPasswordRequired
+		}
+		var der []byte
+		der, err = encrypted.Decrypt(block.Bytes, password)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt private key: %w", err)
+		}
+		key, err = x509.ParsePKCS8PrivateKey(der)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported private key type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey, *rsa.PrivateKey, ed25519.PrivateKey:
+		return key.(crypto.Signer), nil
+	default:
+		return nil, fmt.Errorf("unsupported private key algorithm %T", key)
+	}
+}
+
+// LoadPublicKey parses a PEM encoded PKIX public key.
+func LoadPublicKey(pemBytes []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM block found in public key")
+	}
+	if block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("unsupported public key type %q", block.Type)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key algorithm %T", key)
+	}
+}
+
+// Sign signs a payload. As in cosign, ECDSA and RSA keys sign the SHA-256
+// digest of the payload, and Ed25519 keys sign the payload itself.
+func Sign(signer crypto.Signer, payload []byte) ([]byte, error) {
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		return signer.Sign(rand.Reader, payload, crypto.Hash(0))
+	}
+	sum := sha256.Sum256(payload)
+	return signer.Sign(rand.Reader, sum[:], crypto.SHA256)
+}
+
+// Verify checks the signature of a payload.
+func Verify(key crypto.PublicKey, payload, signature []byte) error {
+	sum := sha256.Sum256(payload)
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, sum[:], signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], signature); err != nil {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, payload, signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key algorithm %T", key)
+	}
+}
+
+// Artifact is a signature manifest along with the blobs it refers to.
+type Artifact struct {
+	Manifest ocispecs.Descriptor
+	Blobs    map[digest.Digest][]byte
+}
+
+// SignatureArtifact returns a signature manifest referring to subject, which
+// holds the signed payload as its only layer.
+func SignatureArtifact(subject ocispecs.Descriptor, payload, signature []byte) (*Artifact, error) {
+	layer := ocispecs.Descriptor{
+		MediaType: MediaTypeSimpleSigning,
+		Digest:    digest.FromBytes(payload),
+		Size:      int64(len(payload)),
+		Annotations: map[string]string{
+			AnnotationSignature: base64.StdEncoding.EncodeToString(signature),
+		},
+	}
+	manifest := ocispecs.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispecs.MediaTypeImageManifest,
+		ArtifactType: ArtifactTypeSignature,
+		Config: ocispecs.Descriptor{
+			MediaType: ocispecs.MediaTypeEmptyJSON,
+			Digest:    ocispecs.DescriptorEmptyJSON.Digest,
+			Size:      ocispecs.DescriptorEmptyJSON.Size,
+		},
+		Layers: []ocispecs.Descriptor{layer},
+		Subject: &ocispecs.Descriptor{
+			MediaType: subject.MediaType,
+			Digest:    subject.Digest,
+			Size:      subject.Size,
+		},
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("encode signature manifest: %w", err)
+	}
+	return &Artifact{
+		Manifest: ocispecs.Descriptor{
+			MediaType:    ocispecs.MediaTypeImageManifest,
+			ArtifactType: ArtifactTypeSignature,
+			Digest:       digest.FromBytes(manifestJSON),
+			Size:         int64(len(manifestJSON)),
+		},
+		Blobs: map[digest.Digest][]byte{
+			digest.FromBytes(manifestJSON):      manifestJSON,
+			layer.Digest:                        payload,
+			ocispecs.DescriptorEmptyJSON.Digest: ocispecs.DescriptorEmptyJSON.Data,
+		},
+	}, nil
+}
+
+// VerifyArtifact checks that a signature manifest holds a payload signing
+// subject. If key is nil, only the payload is checked, not who signed it.
+func VerifyArtifact(manifestJSON []byte, subject digest.Digest, key crypto.PublicKey, readBlob func(ocispecs.Descriptor) ([]byte, error)) error {
+	var manifest ocispecs.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return fmt.Errorf("decode signature manifest: %w", err)
+	}
+	var errs []error
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != MediaTypeSimpleSigning {
+			continue
+		}
+		if err := verifyLayer(layer, subject, key, readBlob); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return nil
+	}
+	if len(errs) == 0 {
+		return errors.New("no signed payload in signature manifest")
+	}
+	return errors.Join(errs...)
+}
+
+func verifyLayer(layer ocispecs.Descriptor, subject digest.Digest, key crypto.PublicKey, readBlob func(ocispecs.Descriptor) ([]byte, error)) error {
+	payload, err := readBlob(layer)
+	if err != nil {
+		return fmt.Errorf("read signature payload: %w", err)
+	}
+	if digest.FromBytes(payload) != layer.Digest {
+		return fmt.Errorf("signature payload does not match digest %s", layer.Digest)
+	}
+	if err := CheckPayload(payload, subject); err != nil {
+		return err
+	}
+	if key == nil {
+		return nil
+	}
+	signature, err := base64.StdEncoding.DecodeString(layer.Annotations[AnnotationSignature])
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+	return Verify(key, payload, signature)
+}
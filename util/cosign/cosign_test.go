@@ -0,0 +1,134 @@
+package cosign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/secure-systems-lab/go-securesystemslib/encrypted"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePrivateKey(t *testing.T, key crypto.Signer, password []byte) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	if password == nil {
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	}
+	enc, err := encrypted.Encrypt(der, password)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeEncryptedSigstore, Bytes: enc})
+}
+
+func encodePublicKey(t *testing.T, key crypto.PublicKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestSignAndVerify(t *testing.T) {
+	t.Parallel()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	subject := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageIndex,
+		Digest:    digest.FromString("image"),
+		Size:      123,
+	}
+
+	for _, key := range []crypto.Signer{ecKey, rsaKey, edKey} {
+		t.Run(fmt.Sprintf("%T", key), func(t *testing.T) {
+			t.Parallel()
+
+			password := []byte("hunter2")
+			signer, err := LoadPrivateKey(encodePrivateKey(t, key, password), password)
+			require.NoError(t, err)
+
+			payload, err := NewPayload("registry.example.com/app", subject.Digest)
+			require.NoError(t, err)
+			sig, err := Sign(signer, payload)
+			require.NoError(t, err)
+
+			artifact, err := SignatureArtifact(subject, payload, sig)
+			require.NoError(t, err)
+			manifestJSON := artifact.Blobs[artifact.Manifest.Digest]
+			var manifest ocispecs.Manifest
+			require.NoError(t, json.Unmarshal(manifestJSON, &manifest))
+			require.Equal(t, ArtifactTypeSignature, manifest.ArtifactType)
+			require.Equal(t, subject.Digest, manifest.Subject.Digest)
+			for _, desc := range append([]ocispecs.Descriptor{manifest.Config}, manifest.Layers...) {
+				require.Contains(t, artifact.Blobs, desc.Digest)
+			}
+
+			readBlob := func(desc ocispecs.Descriptor) ([]byte, error) {
+				dt, ok := artifact.Blobs[desc.Digest]
+				if !ok {
+					return nil, fmt.Errorf("blob %s not found", desc.Digest)
+				}
+				return dt, nil
+			}
+			pub, err := LoadPublicKey(encodePublicKey(t, key.Public()))
+			require.NoError(t, err)
+			require.NoError(t, VerifyArtifact(manifestJSON, subject.Digest, pub, readBlob))
+			require.NoError(t, VerifyArtifact(manifestJSON, subject.Digest, nil, readBlob))
+
+			err = VerifyArtifact(manifestJSON, digest.FromString("other"), pub, readBlob)
+			require.ErrorContains(t, err, "signature is for "+subject.Digest.String())
+
+			otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			require.NoError(t, err)
+			err = VerifyArtifact(manifestJSON, subject.Digest, otherKey.Public(), readBlob)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLoadPrivateKey(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("unencrypted", func(t *testing.T) {
+		t.Parallel()
+		signer, err := LoadPrivateKey(encodePrivateKey(t, key, nil), nil)
+		require.NoError(t, err)
+		require.True(t, key.Equal(signer))
+	})
+
+	t.Run("missing password", func(t *testing.T) {
+		t.Parallel()
+		_, err := LoadPrivateKey(encodePrivateKey(t, key, []byte("hunter2")), nil)
+		require.ErrorIs(t, err, ErrPasswordRequired)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		t.Parallel()
+		_, err := LoadPrivateKey(encodePrivateKey(t, key, []byte("hunter2")), []byte("hunter3"))
+		require.ErrorContains(t, err, "decrypt private key")
+	})
+
+	t.Run("public key", func(t *testing.T) {
+		t.Parallel()
+		_, err := LoadPrivateKey(encodePublicKey(t, key.Public()), nil)
+		require.ErrorContains(t, err, `unsupported private key type "PUBLIC KEY"`)
+	})
+}
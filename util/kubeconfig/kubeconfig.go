@@ -0,0 +1,256 @@
+// Package kubeconfig loads what's needed to reach a Kubernetes API server from
+// a kubeconfig file, or from the in-cluster service account.
+//
+// Only static credentials are supported: tokens, basic auth and client
+// certificates. Users authenticating with exec or auth-provider plugins are
+// rejected.
+package kubeconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// Cluster is an API server and the credentials to use with it.
+type Cluster struct {
+	// Server is the URL of the API server, without a trailing slash.
+	Server string
+	// Namespace is the namespace of the context, or of the service account
+	// in-cluster. It's empty if the kubeconfig doesn't set one.
+	Namespace string
+
+	TLSConfig *tls.Config
+	Token     string
+	Username  string
+	Password  string
+}
+
+// HTTPClient returns a client for the API server.
+func (c *Cluster) HTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: c.TLSConfig,
+		},
+	}
+}
+
+// Authorize sets the credentials of the cluster on a request.
+func (c *Cluster) Authorize(req *http.Request) {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	} else if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+}
+
+// config is the subset of the kubeconfig format needed to reach a cluster.
+type config struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Clusters []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+			TLSServerName            string `yaml:"tls-server-name"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string    `yaml:"token"`
+			TokenFile             string    `yaml:"tokenFile"`
+			ClientCertificate     string    `yaml:"client-certificate"`
+			ClientCertificateData string    `yaml:"client-certificate-data"`
+			ClientKey             string    `yaml:"client-key"`
+			ClientKeyData         string    `yaml:"client-key-data"`
+			Username              string    `yaml:"username"`
+			Password              string    `yaml:"password"`
+			Exec                  *struct{} `yaml:"exec"`
+			AuthProvider          *struct{} `yaml:"auth-provider"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// Load returns the cluster of the given context of the kubeconfig
+// ($KUBECONFIG or ~/.kube/config), or of its current context if contextName
+// is empty. Without a kubeconfig, the in-cluster service account is used.
+func Load(contextName string) (*Cluster, error) {
+	path := Path()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && contextName == "" && os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+			return inCluster()
+		}
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	var cfg config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig %s: %w", path, err)
+	}
+	// relative file references are relative to the kubeconfig
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(filepath.Dir(path), p)
+	}
+
+	if contextName == "" {
+		contextName = cfg.CurrentContext
+	}
+	if contextName == "" {
+		return nil, fmt.Errorf("kubeconfig %s has no current context", path)
+	}
+	cluster := &Cluster{}
+	var clusterName, userName string
+	var found bool
+	for _, c := range cfg.Contexts {
+		if c.Name == contextName {
+			clusterName, userName, found = c.Context.Cluster, c.Context.User, true
+			cluster.Namespace = c.Context.Namespace
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("context %q not found in kubeconfig %s", contextName, path)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	found = false
+	for _, c := range cfg.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		found = true
+		cluster.Server = strings.TrimSuffix(c.Cluster.Server, "/")
+		tlsConfig.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify //nolint:gosec // explicitly configured by the user
+		tlsConfig.ServerName = c.Cluster.TLSServerName
+		caPEM, err := fileOrData(resolve(c.Cluster.CertificateAuthority), c.Cluster.CertificateAuthorityData)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %q certificate authority: %w", clusterName, err)
+		}
+		if caPEM != nil {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(caPEM) {
+				return nil, fmt.Errorf("cluster %q certificate authority: no certificates found", clusterName)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		break
+	}
+	if !found || cluster.Server == "" {
+		return nil, fmt.Errorf("cluster %q not found in kubeconfig %s", clusterName, path)
+	}
+
+	for _, u := range cfg.Users {
+		if u.Name != userName {
+			continue
+		}
+		if u.User.Exec != nil || u.User.AuthProvider != nil {
+			return nil, fmt.Errorf("user %q: exec and auth-provider credentials are not supported", userName)
+		}
+		cluster.Token = u.User.Token
+		if cluster.Token == "" && u.User.TokenFile != "" {
+			token, err := os.ReadFile(resolve(u.User.TokenFile))
+			if err != nil {
+				return nil, fmt.Errorf("user %q token: %w", userName, err)
+			}
+			cluster.Token = strings.TrimSpace(string(token))
+		}
+		cluster.Username, cluster.Password = u.User.Username, u.User.Password
+		certPEM, err := fileOrData(resolve(u.User.ClientCertificate), u.User.ClientCertificateData)
+		if err != nil {
+			return nil, fmt.Errorf("user %q client certificate: %w", userName, err)
+		}
+		keyPEM, err := fileOrData(resolve(u.User.ClientKey), u.User.ClientKeyData)
+		if err != nil {
+			return nil, fmt.Errorf("user %q client key: %w", userName, err)
+		}
+		if certPEM != nil || keyPEM != nil {
+			cert, err := tls.X509KeyPair(certPEM, keyPEM)
+			if err != nil {
+				return nil, fmt.Errorf("user %q client certificate: %w", userName, err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		break
+	}
+
+	cluster.TLSConfig = tlsConfig
+	return cluster, nil
+}
+
+func inCluster() (*Cluster, error) {
+	token, err := os.ReadFile(filepath.Join(serviceAccountDir, "token"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account token: %w", err)
+	}
+	caPEM, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("service account CA: no certificates found")
+	}
+	// the namespace is optional, callers fall back to a default
+	namespace, _ := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+	host := os.Getenv("KUBERNETES_SERVICE_HOST")
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return &Cluster{
+		Server:    "https://" + host + ":" + os.Getenv("KUBERNETES_SERVICE_PORT"),
+		Namespace: strings.TrimSpace(string(namespace)),
+		Token:     strings.TrimSpace(string(token)),
+		TLSConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    pool,
+		},
+	}, nil
+}
+
+// Path returns the path of the kubeconfig: the first file of $KUBECONFIG,
+// like kubectl, or ~/.kube/config.
+func Path() string {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return filepath.SplitList(env)[0]
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".kube", "config")
+	}
+	return filepath.Join(home, ".kube", "config")
+}
+
+func fileOrData(file, data string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if file != "" {
+		return os.ReadFile(file)
+	}
+	return nil, nil
+}
@@ -0,0 +1,24 @@
+// Package llmenv names the environment variables the CLI exports the LLM
+// providers of its configuration to, for the engine's LLM router to read.
+package llmenv
+
+import "strings"
+
+// Providers lists the names of the named providers, separated by commas.
+const Providers = "DAGGER_LLM_PROVIDERS"
+
+// NamedProvider returns the environment variable holding a setting of a named
+// provider, e.g. DAGGER_LLM_PROVIDER_MY_VLLM_BASE_URL for "my-vllm".
+func NamedProvider(name, setting string) string {
+	key := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	return "DAGGER_LLM_PROVIDER_" + key + "_" + setting
+}
@@ -0,0 +1,13 @@
+package llmenv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamedProvider(t *testing.T) {
+	require.Equal(t, "DAGGER_LLM_PROVIDER_MY_VLLM_BASE_URL", NamedProvider("my-vllm", "BASE_URL"))
+	require.Equal(t, "DAGGER_LLM_PROVIDER_GATEWAY2_API_KEY", NamedProvider("Gateway2", "API_KEY"))
+	require.Equal(t, "DAGGER_LLM_PROVIDER_A_B_MODEL", NamedProvider("a.b", "MODEL"))
+}
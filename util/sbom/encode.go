@@ -0,0 +1,190 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+
+	spdxjson "github.com/spdx/tools-golang/json"
+)
+
+// Format is an SBOM document format.
+type Format string
+
+const (
+	FormatSPDX      Format = "spdx"
+	FormatCycloneDX Format = "cyclonedx"
+)
+
+// In-toto predicate types of each format, as expected by tools that read
+// image attestations.
+const (
+	PredicateTypeSPDX      = "https://spdx.dev/Document"
+	PredicateTypeCycloneDX = "https://cyclonedx.org/bom"
+)
+
+// PredicateType returns the in-toto predicate type for documents of the given
+// format.
+func (format Format) PredicateType() string {
+	switch format {
+	case FormatCycloneDX:
+		return PredicateTypeCycloneDX
+	default:
+		return PredicateTypeSPDX
+	}
+}
+
+// DocumentOpts describes the subject of an SBOM document.
+type DocumentOpts struct {
+	// Name of the artifact described by the document, e.g. an image reference.
+	Name string
+	// Namespace makes the document identity unique; it should be stable for
+	// the same artifact, e.g. a content digest.
+	Namespace string
+	// Creator is the tool that generated the document.
+	Creator string
+	// Created is the document creation time.
+	Created time.Time
+}
+
+// Encode renders the inventory as a document in the given format.
+func (inv *Inventory) Encode(format Format, opts DocumentOpts) ([]byte, error) {
+	switch format {
+	case FormatSPDX:
+		return inv.SPDX(opts)
+	case FormatCycloneDX:
+		return inv.CycloneDX(opts)
+	default:
+		return nil, fmt.Errorf("unsupported SBOM format %q", format)
+	}
+}
+
+// SPDX renders the inventory as an SPDX 2.3 JSON document.
+func (inv *Inventory) SPDX(opts DocumentOpts) ([]byte, error) {
+	doc := &spdx.Document{
+		SPDXVersion:       spdx.Version,
+		DataLicense:       spdx.DataLicense,
+		SPDXIdentifier:    "DOCUMENT",
+		DocumentName:      opts.Name,
+		DocumentNamespace: "https://dagger.io/spdxdocs/" + opts.Namespace,
+		CreationInfo: &spdx.CreationInfo{
+			Creators: []spdx.Creator{{CreatorType: "Tool", Creator: opts.Creator}},
+			Created:  opts.Created.UTC().Format(time.RFC3339),
+		},
+	}
+	for i, pkg := range inv.Packages {
+		id := common.ElementID(fmt.Sprintf("Package-%d", i))
+		spdxPkg := &spdx.Package{
+			PackageName:             pkg.Name,
+			PackageSPDXIdentifier:   id,
+			PackageVersion:          pkg.Version,
+			PackageDownloadLocation: "NOASSERTION",
+			PackageLicenseConcluded: "NOASSERTION",
+			PackageLicenseDeclared:  "NOASSERTION",
+			PackageCopyrightText:    "NOASSERTION",
+			PackageSourceInfo:       fmt.Sprintf("acquired package info from %s database", pkg.Type),
+			PackageExternalReferences: []*spdx.PackageExternalReference{{
+				Category: common.CategoryPackageManager,
+				RefType:  common.TypePackageManagerPURL,
+				Locator:  pkg.PURL(inv.Distro),
+			}},
+		}
+		if pkg.License != "" {
+			// package databases don't always use SPDX license expressions, so
+			// the license is informational only
+			spdxPkg.PackageLicenseComments = pkg.License
+		}
+		if pkg.Supplier != "" {
+			spdxPkg.PackageSupplier = &common.Supplier{SupplierType: "Person", Supplier: pkg.Supplier}
+		}
+		doc.Packages = append(doc.Packages, spdxPkg)
+		doc.Relationships = append(doc.Relationships, &spdx.Relationship{
+			RefA:         common.MakeDocElementID("", "DOCUMENT"),
+			RefB:         common.MakeDocElementID("", string(id)),
+			Relationship: common.TypeRelationshipDescribe,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := spdxjson.Write(doc, &buf); err != nil {
+		return nil, fmt.Errorf("encode SPDX document: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+type cycloneDXBOM struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     cycloneDXMetadata    `json:"metadata"`
+	Components   []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     struct {
+		Components []cycloneDXComponent `json:"components"`
+	} `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXComponent struct {
+	Type      string             `json:"type"`
+	BOMRef    string             `json:"bom-ref,omitempty"`
+	Name      string             `json:"name"`
+	Version   string             `json:"version,omitempty"`
+	Publisher string             `json:"publisher,omitempty"`
+	PURL      string             `json:"purl,omitempty"`
+	Licenses  []cycloneDXLicense `json:"licenses,omitempty"`
+}
+
+type cycloneDXLicense struct {
+	License struct {
+		Name string `json:"name"`
+	} `json:"license"`
+}
+
+// CycloneDX renders the inventory as a CycloneDX 1.5 JSON document.
+func (inv *Inventory) CycloneDX(opts DocumentOpts) ([]byte, error) {
+	bom := cycloneDXBOM{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		// serial numbers must be RFC 4122 UUIDs, so derive one from the
+		// namespace to keep it stable
+		SerialNumber: "urn:uuid:" + uuid.NewSHA1(uuid.NameSpaceURL, []byte(opts.Namespace)).String(),
+		Version:      1,
+		Components:   []cycloneDXComponent{},
+	}
+	bom.Metadata.Timestamp = opts.Created.UTC().Format(time.RFC3339)
+	bom.Metadata.Tools.Components = []cycloneDXComponent{{Type: "application", Name: opts.Creator}}
+	bom.Metadata.Component = cycloneDXComponent{Type: "container", Name: opts.Name}
+	for _, pkg := range inv.Packages {
+		purl := pkg.PURL(inv.Distro)
+		component := cycloneDXComponent{
+			Type:      "library",
+			BOMRef:    purl,
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Publisher: pkg.Supplier,
+			PURL:      purl,
+		}
+		if pkg.License != "" {
+			var license cycloneDXLicense
+			license.License.Name = pkg.License
+			component.Licenses = []cycloneDXLicense{license}
+		}
+		bom.Components = append(bom.Components, component)
+	}
+
+	dt, err := json.Marshal(bom)
+	if err != nil {
+		return nil, fmt.Errorf("encode CycloneDX document: %w", err)
+	}
+	return dt, nil
+}
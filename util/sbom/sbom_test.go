@@ -0,0 +1,168 @@
+package sbom
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const apkInstalled = `C:Q1abc=
+P:musl
+V:1.2.5-r0
+A:x86_64
+S:408517
+L:MIT
+o:musl
+m:Natanael Copa <ncopa@alpinelinux.org>
+
+C:Q1def=
+P:busybox-binsh
+V:1.36.1-r29
+A:x86_64
+L:GPL-2.0-only
+o:busybox
+`
+
+const dpkgStatus = `Package: bash
+Status: install ok installed
+Priority: required
+Architecture: amd64
+Version: 5.2.15-2+b7
+Description: GNU Bourne Again SHell
+ Bash is an sh-compatible command language interpreter.
+
+Package: removed
+Status: deinstall ok config-files
+Architecture: amd64
+Version: 1.0
+
+Package: libc6
+Status: install ok installed
+Architecture: amd64
+Source: glibc (2.36-9)
+Version: 2.36-9+deb12u7
+`
+
+func writeFile(t *testing.T, root, p, contents string) {
+	t.Helper()
+	p = filepath.Join(root, p)
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+	require.NoError(t, os.WriteFile(p, []byte(contents), 0o644))
+}
+
+func TestScanAPK(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, root, "etc/os-release", "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.20.3\nPRETTY_NAME=\"Alpine Linux v3.20\"\n")
+	writeFile(t, root, "lib/apk/db/installed", apkInstalled)
+
+	inv, err := Scan(root)
+	require.NoError(t, err)
+	require.Equal(t, Distro{ID: "alpine", VersionID: "3.20.3", Name: "Alpine Linux v3.20"}, inv.Distro)
+	require.Len(t, inv.Packages, 2)
+	require.Equal(t, "busybox-binsh", inv.Packages[0].Name)
+	require.Equal(t, "pkg:apk/alpine/busybox-binsh@1.36.1-r29?arch=x86_64&distro=alpine-3.20.3&origin=busybox", inv.Packages[0].PURL(inv.Distro))
+	require.Equal(t, Package{
+		Type:     "apk",
+		Name:     "musl",
+		Version:  "1.2.5-r0",
+		Arch:     "x86_64",
+		License:  "MIT",
+		Source:   "musl",
+		Supplier: "Natanael Copa <ncopa@alpinelinux.org>",
+	}, inv.Packages[1])
+}
+
+func TestScanDpkg(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, root, "usr/lib/os-release", "ID=debian\nVERSION_ID=\"12\"\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0o755))
+	require.NoError(t, os.Symlink("../usr/lib/os-release", filepath.Join(root, "etc/os-release")))
+	writeFile(t, root, "var/lib/dpkg/status", dpkgStatus)
+	writeFile(t, root, "var/lib/dpkg/status.d/tzdata", "Package: tzdata\nVersion: 2024a-0+deb12u1\nArchitecture: all\n")
+	writeFile(t, root, "var/lib/dpkg/status.d/tzdata.md5sums", "abc  usr/share/zoneinfo/UTC\n")
+
+	inv, err := Scan(root)
+	require.NoError(t, err)
+	require.Equal(t, "debian", inv.Distro.ID)
+
+	var names []string
+	for _, pkg := range inv.Packages {
+		names = append(names, pkg.Name)
+	}
+	require.Equal(t, []string{"bash", "libc6", "tzdata"}, names)
+	require.Equal(t, "pkg:deb/debian/libc6@2.36-9%2Bdeb12u7?arch=amd64&distro=debian-12&upstream=glibc", inv.Packages[1].PURL(inv.Distro))
+}
+
+func TestScanStaysInRoot(t *testing.T) {
+	t.Parallel()
+
+	outside := t.TempDir()
+	writeFile(t, outside, "installed", apkInstalled)
+
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "lib/apk/db"), 0o755))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "installed"), filepath.Join(root, "lib/apk/db/installed")))
+
+	inv, err := Scan(root)
+	require.NoError(t, err)
+	require.Empty(t, inv.Packages)
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	inv := &Inventory{
+		Distro:   Distro{ID: "alpine", VersionID: "3.20.3"},
+		Packages: []Package{{Type: "apk", Name: "musl", Version: "1.2.5-r0", Arch: "x86_64", License: "MIT"}},
+	}
+	opts := DocumentOpts{
+		Name:      "registry.example.com/app:latest",
+		Namespace: "sha256:abc",
+		Creator:   "dagger",
+		Created:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	dt, err := inv.Encode(FormatSPDX, opts)
+	require.NoError(t, err)
+	var spdxDoc struct {
+		SPDXVersion string `json:"spdxVersion"`
+		Namespace   string `json:"documentNamespace"`
+		Packages    []struct {
+			Name         string `json:"name"`
+			ExternalRefs []struct {
+				Locator string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+	}
+	require.NoError(t, json.Unmarshal(dt, &spdxDoc))
+	require.Equal(t, "SPDX-2.3", spdxDoc.SPDXVersion)
+	require.Equal(t, "https://dagger.io/spdxdocs/sha256:abc", spdxDoc.Namespace)
+	require.Len(t, spdxDoc.Packages, 1)
+	require.Equal(t, "pkg:apk/alpine/musl@1.2.5-r0?arch=x86_64&distro=alpine-3.20.3", spdxDoc.Packages[0].ExternalRefs[0].Locator)
+
+	dt, err = inv.Encode(FormatCycloneDX, opts)
+	require.NoError(t, err)
+	var bom struct {
+		BOMFormat  string `json:"bomFormat"`
+		Serial     string `json:"serialNumber"`
+		Components []struct {
+			PURL string `json:"purl"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(dt, &bom))
+	require.Equal(t, "CycloneDX", bom.BOMFormat)
+	require.Regexp(t, `^urn:uuid:[0-9a-f-]{36}$`, bom.Serial)
+	require.Len(t, bom.Components, 1)
+	require.Equal(t, "pkg:apk/alpine/musl@1.2.5-r0?arch=x86_64&distro=alpine-3.20.3", bom.Components[0].PURL)
+
+	_, err = inv.Encode("nope", opts)
+	require.Error(t, err)
+}
@@ -0,0 +1,271 @@
+// Package sbom generates software bills of materials for container root
+// filesystems by reading the package databases of the distro they're built on.
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/containerd/continuity/fs"
+	packageurl "github.com/package-url/packageurl-go"
+)
+
+// Inventory is the set of packages found in a root filesystem.
+type Inventory struct {
+	Distro   Distro
+	Packages []Package
+}
+
+// Distro identifies the distribution a root filesystem is based on, as
+// described by its os-release file.
+type Distro struct {
+	ID        string
+	VersionID string
+	Name      string
+}
+
+// Package is a single package installed by a system package manager.
+type Package struct {
+	// Type is the package URL type of the package manager, e.g. "apk" or "deb".
+	Type     string
+	Name     string
+	Version  string
+	Arch     string
+	License  string
+	Source   string
+	Supplier string
+}
+
+// PURL returns the package URL of the package.
+func (pkg Package) PURL(distro Distro) string {
+	var qualifiers packageurl.Qualifiers
+	if pkg.Arch != "" {
+		qualifiers = append(qualifiers, packageurl.Qualifier{Key: "arch", Value: pkg.Arch})
+	}
+	if distro.ID != "" {
+		qualifier := distro.ID
+		if distro.VersionID != "" {
+			qualifier += "-" + distro.VersionID
+		}
+		qualifiers = append(qualifiers, packageurl.Qualifier{Key: "distro", Value: qualifier})
+	}
+	if pkg.Source != "" && pkg.Source != pkg.Name {
+		key := "upstream"
+		if pkg.Type == packageurl.TypeApk {
+			key = "origin"
+		}
+		qualifiers = append(qualifiers, packageurl.Qualifier{Key: key, Value: pkg.Source})
+	}
+	return packageurl.NewPackageURL(pkg.Type, distro.ID, pkg.Name, pkg.Version, qualifiers, "").ToString()
+}
+
+const (
+	osReleasePath     = "/etc/os-release"
+	osReleaseFallback = "/usr/lib/os-release"
+	apkInstalledPath  = "/lib/apk/db/installed"
+	dpkgStatusPath    = "/var/lib/dpkg/status"
+	dpkgStatusDir     = "/var/lib/dpkg/status.d"
+)
+
+// Scan reads the package databases of the root filesystem mounted at root.
+// Symlinks are resolved within root, so a hostile image can't point the scan
+// at files outside of it.
+//
+// Alpine (apk) and Debian-based (dpkg) package databases are supported; a
+// root filesystem without either yields an empty inventory.
+func Scan(root string) (*Inventory, error) {
+	inv := &Inventory{}
+
+	for _, p := range []string{osReleasePath, osReleaseFallback} {
+		dt, err := readFile(root, p)
+		if err != nil {
+			return nil, err
+		}
+		if dt != nil {
+			inv.Distro = parseOSRelease(dt)
+			break
+		}
+	}
+
+	dt, err := readFile(root, apkInstalledPath)
+	if err != nil {
+		return nil, err
+	}
+	if dt != nil {
+		inv.Packages = append(inv.Packages, parseAPKInstalled(dt)...)
+	}
+
+	dt, err = readFile(root, dpkgStatusPath)
+	if err != nil {
+		return nil, err
+	}
+	if dt != nil {
+		inv.Packages = append(inv.Packages, parseDpkgStatus(dt)...)
+	}
+
+	// distroless images record each package in its own file
+	statusDir, err := fs.RootPath(root, dpkgStatusDir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(statusDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read %s: %w", dpkgStatusDir, err)
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasSuffix(entry.Name(), ".md5sums") {
+			continue
+		}
+		dt, err := readFile(root, filepath.Join(dpkgStatusDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		inv.Packages = append(inv.Packages, parseDpkgStatus(dt)...)
+	}
+
+	slices.SortFunc(inv.Packages, func(a, b Package) int {
+		return strings.Compare(a.Type+"/"+a.Name+"@"+a.Version, b.Type+"/"+b.Name+"@"+b.Version)
+	})
+	inv.Packages = slices.Compact(inv.Packages)
+	return inv, nil
+}
+
+// readFile reads a file within root, returning nil if it doesn't exist.
+func readFile(root, p string) ([]byte, error) {
+	resolved, err := fs.RootPath(root, p)
+	if err != nil {
+		return nil, err
+	}
+	dt, err := os.ReadFile(resolved)
+	switch {
+	case errors.Is(err, os.ErrNotExist), errors.Is(err, syscall.EISDIR):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("read %s: %w", p, err)
+	}
+	return dt, nil
+}
+
+func parseOSRelease(dt []byte) Distro {
+	var distro Distro
+	for line := range strings.Lines(string(dt)) {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		switch key {
+		case "ID":
+			distro.ID = value
+		case "VERSION_ID":
+			distro.VersionID = value
+		case "PRETTY_NAME":
+			distro.Name = value
+		}
+	}
+	return distro
+}
+
+// parseAPKInstalled parses an apk installed database, which is a list of
+// blank line separated records of single letter keyed fields.
+//
+// See https://wiki.alpinelinux.org/wiki/Apk_spec#Installed_Database_V2
+func parseAPKInstalled(dt []byte) []Package {
+	var pkgs []Package
+	var pkg Package
+	flush := func() {
+		if pkg.Name != "" {
+			pkg.Type = packageurl.TypeApk
+			pkgs = append(pkgs, pkg)
+		}
+		pkg = Package{}
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(dt))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "P":
+			pkg.Name = value
+		case "V":
+			pkg.Version = value
+		case "A":
+			pkg.Arch = value
+		case "L":
+			pkg.License = value
+		case "o":
+			pkg.Source = value
+		case "m":
+			pkg.Supplier = value
+		}
+	}
+	flush()
+	return pkgs
+}
+
+// parseDpkgStatus parses a dpkg status database, which is a list of blank
+// line separated control file paragraphs. Packages that aren't fully
+// installed are skipped.
+func parseDpkgStatus(dt []byte) []Package {
+	var pkgs []Package
+	var pkg Package
+	installed := true
+	flush := func() {
+		if pkg.Name != "" && installed {
+			pkg.Type = packageurl.TypeDebian
+			pkgs = append(pkgs, pkg)
+		}
+		pkg = Package{}
+		installed = true
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(dt))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			// continuation of a multi-line field
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Package":
+			pkg.Name = value
+		case "Status":
+			installed = strings.HasSuffix(value, " installed")
+		case "Version":
+			pkg.Version = value
+		case "Architecture":
+			pkg.Arch = value
+		case "Source":
+			// "Source: name (version)" when the source version differs
+			pkg.Source, _, _ = strings.Cut(value, " ")
+		case "Maintainer":
+			pkg.Supplier = value
+		}
+	}
+	flush()
+	return pkgs
+}